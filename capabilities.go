@@ -0,0 +1,68 @@
+package ssz
+
+// Support reports which operations a codec supports for an SSZ type
+type Support struct {
+	Encode       bool `json:"encode"`
+	Decode       bool `json:"decode"`
+	HashTreeRoot bool `json:"hash_tree_root"`
+}
+
+// CapabilityReport describes the SSZ types and modes supported by flexssz, the reflection codec.
+// genssz generates code for a subset of the same types.
+type CapabilityReport struct {
+	// Types has an entry for every TypeName of the spec, including those that are not supported
+	Types map[TypeName]Support `json:"types"`
+
+	// ProgressiveLists is support for lists without a limit that are merkleized progressively
+	ProgressiveLists bool `json:"progressive_lists"`
+
+	// Modes of the codec
+	StrictDecoding bool `json:"strict_decoding"` // Rejecting non-canonical encodings
+	ZeroCopy       bool `json:"zero_copy"`       // Decoding byte slices that alias the input
+	SizedEncoding  bool `json:"sized_encoding"`  // Encoding into a single buffer sized up front
+	HashCache      bool `json:"hash_cache"`      // Caching the roots of unchanged containers
+	MapEncoding    bool `json:"map_encoding"`    // Encoding maps as sorted key/value lists
+	LazyLists      bool `json:"lazy_lists"`      // Reading single elements of serialized lists
+	JSON           bool `json:"json"`            // The JSON form of the beacon API
+	Snappy         bool `json:"snappy"`          // Snappy framed and block compression
+}
+
+// Capabilities returns what this version of the module supports, so callers can feature-detect
+// at runtime instead of comparing versions. The flexssz tests check the report against the
+// codec, so it changes along with it.
+func Capabilities() CapabilityReport {
+	full := Support{Encode: true, Decode: true, HashTreeRoot: true}
+	return CapabilityReport{
+		Types: map[TypeName]Support{
+			TypeUint8:     full,
+			TypeUint16:    full,
+			TypeUint32:    full,
+			TypeUint64:    full,
+			TypeUint128:   full,
+			TypeUint256:   full,
+			TypeBoolean:   full,
+			TypeContainer: full,
+			TypeVector:    full,
+			TypeList:      full,
+			TypeBitVector: full,
+			TypeBitList:   full,
+
+			// Unions are interfaces with registered members, flexssz.Union values are only hashed
+			TypeUnion: full,
+
+			// EIP-7495 types are only described in schemas
+			TypeStableContainer: {},
+			TypeProfile:         {},
+			TypeOptional:        {},
+		},
+		ProgressiveLists: true,
+		StrictDecoding:   true,
+		ZeroCopy:         true,
+		SizedEncoding:    true,
+		HashCache:        true,
+		MapEncoding:      true,
+		LazyLists:        true,
+		JSON:             true,
+		Snappy:           true,
+	}
+}
@@ -1,4 +1,4 @@
 package penguin
 
-//go:generate go run ../../genssz/cmd/genssz -output generated.go schema.yml identity.yml
+//go:generate go run ../../genssz/cmd/genssz -with-tests -output generated.go schema.yml identity.yml
 
@@ -3,11 +3,13 @@
 package penguin
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"github.com/gfx-labs/ssz"
 	"github.com/gfx-labs/ssz/merkle_tree"
 	"github.com/gfx-labs/ssz/merkle_tree/bufpool"
+	"io"
 )
 
 // Penguin is a fixed-size SSZ container with the following byte layout:
@@ -62,6 +64,28 @@ func (s Penguin) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s Penguin) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s Penguin) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s Penguin) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -133,6 +157,42 @@ func (s Penguin) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s Penguin) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "name":
+		return 8, nil
+	case "species":
+		return 9, nil
+	case "awesomness":
+		return 10, nil
+	case "cuteness":
+		return 11, nil
+	case "identity":
+		return 12, nil
+	default:
+		return 0, fmt.Errorf("Penguin has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s Penguin) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 160)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 5)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(3, int(gindex-8), leaves...)
+}
+
 // Name returns the name field
 // Bytes: 0-31
 func (s Penguin) Name() [32]byte {
@@ -193,6 +253,76 @@ func (s Penguin) SetIdentity(v Identity) {
 	copy(s[37:93], v)
 }
 
+// PenguinBuilder sets the fields of a new Penguin one at a time, checking each value as it is
+// set. The first invalid value is returned by Build, and later values are ignored.
+type PenguinBuilder struct {
+	obj Penguin
+	err error
+}
+
+// NewPenguinBuilder returns a builder of a zero Penguin
+func NewPenguinBuilder() *PenguinBuilder {
+	return &PenguinBuilder{obj: NewPenguin()}
+}
+
+// Name sets the name field
+func (b *PenguinBuilder) Name(v [32]byte) *PenguinBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.obj.SetName(v)
+	return b
+}
+
+// Species sets the species field
+func (b *PenguinBuilder) Species(v [2]byte) *PenguinBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.obj.SetSpecies(v)
+	return b
+}
+
+// Awesomness sets the awesomness field
+func (b *PenguinBuilder) Awesomness(v uint16) *PenguinBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.obj.SetAwesomness(v)
+	return b
+}
+
+// Cuteness sets the cuteness field
+func (b *PenguinBuilder) Cuteness(v uint8) *PenguinBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.obj.SetCuteness(v)
+	return b
+}
+
+// Identity sets the identity field
+func (b *PenguinBuilder) Identity(v Identity) *PenguinBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(v) != 56 {
+		b.err = fmt.Errorf("identity: %w", ssz.NewErrSizeMismatch(56, len(v)))
+		return b
+	}
+	b.obj.SetIdentity(v)
+	return b
+}
+
+// Build returns a copy of the Penguin, or the first invalid value set on the builder. The
+// builder can keep being used, and setters called after Build do not change the values it returned.
+func (b *PenguinBuilder) Build() (Penguin, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return Penguin(bytes.Clone(b.obj)), nil
+}
+
 // Identity is a fixed-size SSZ container with the following byte layout:
 //
 // Byte layout:
@@ -236,6 +366,28 @@ func (s Identity) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s Identity) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s Identity) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s Identity) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -293,6 +445,36 @@ func (s Identity) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s Identity) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "id":
+		return 2, nil
+	case "publicKey":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("Identity has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s Identity) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // Id returns the id field
 // Bytes: 0-7
 func (s Identity) Id() uint64 {
@@ -316,3 +498,42 @@ func (s Identity) SetId(v uint64) {
 func (s Identity) SetPublicKey(v [48]byte) {
 	copy(s[8:56], v[:])
 }
+
+// IdentityBuilder sets the fields of a new Identity one at a time, checking each value as it is
+// set. The first invalid value is returned by Build, and later values are ignored.
+type IdentityBuilder struct {
+	obj Identity
+	err error
+}
+
+// NewIdentityBuilder returns a builder of a zero Identity
+func NewIdentityBuilder() *IdentityBuilder {
+	return &IdentityBuilder{obj: NewIdentity()}
+}
+
+// Id sets the id field
+func (b *IdentityBuilder) Id(v uint64) *IdentityBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.obj.SetId(v)
+	return b
+}
+
+// PublicKey sets the publicKey field
+func (b *IdentityBuilder) PublicKey(v [48]byte) *IdentityBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.obj.SetPublicKey(v)
+	return b
+}
+
+// Build returns a copy of the Identity, or the first invalid value set on the builder. The
+// builder can keep being used, and setters called after Build do not change the values it returned.
+func (b *IdentityBuilder) Build() (Identity, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return Identity(bytes.Clone(b.obj)), nil
+}
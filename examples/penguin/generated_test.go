@@ -0,0 +1,154 @@
+// Code generated by genssz. DO NOT EDIT.
+
+package penguin
+
+import (
+	"bytes"
+	"github.com/gfx-labs/ssz/flexssz"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// testPenguinValue mirrors the layout of Penguin for flexssz
+type testPenguinValue struct {
+	Name       [32]byte
+	Species    []byte `ssz:"bitvector" ssz-size:"16"`
+	Awesomness uint16
+	Cuteness   uint8
+	Identity   testIdentityValue
+}
+
+// randomTestPenguinValue returns a random value of Penguin
+func randomTestPenguinValue(r *rand.Rand) testPenguinValue {
+	var v testPenguinValue
+	for i := range v.Name {
+		v.Name[i] = byte(r.Uint32())
+	}
+	v.Species = make([]byte, 2)
+	for i := range v.Species {
+		v.Species[i] = byte(r.Uint32())
+	}
+	v.Awesomness = uint16(r.Uint32())
+	v.Cuteness = uint8(r.Uint32())
+	v.Identity = randomTestIdentityValue(r)
+	return v
+}
+
+// TestPenguin_Generated checks random values of Penguin against flexssz
+func TestPenguin_Generated(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 64; i++ {
+		value := randomTestPenguinValue(r)
+		data, err := flexssz.Marshal(&value)
+		if err != nil {
+			t.Fatalf("value %d: flexssz.Marshal: %v", i, err)
+		}
+		view := Penguin(data)
+		if view.SizeSSZ() != len(data) {
+			t.Fatalf("value %d: SizeSSZ %d, flexssz encoded %d bytes", i, view.SizeSSZ(), len(data))
+		}
+		if got := view.Name(); got != value.Name {
+			t.Fatalf("value %d: Name %x, expected %x", i, got, value.Name)
+		}
+		if got := view.Species(); !bytes.Equal(got[:], value.Species) {
+			t.Fatalf("value %d: Species %x, expected %x", i, got, value.Species)
+		}
+		if got := view.Awesomness(); got != value.Awesomness {
+			t.Fatalf("value %d: Awesomness %v, expected %v", i, got, value.Awesomness)
+		}
+		if got := view.Cuteness(); got != value.Cuteness {
+			t.Fatalf("value %d: Cuteness %v, expected %v", i, got, value.Cuteness)
+		}
+		encoded, err := view.MarshalSSZ()
+		if err != nil {
+			t.Fatalf("value %d: MarshalSSZ: %v", i, err)
+		}
+		if !bytes.Equal(encoded, data) {
+			t.Fatalf("value %d: MarshalSSZ %x, flexssz encoded %x", i, encoded, data)
+		}
+		var decoded testPenguinValue
+		err = flexssz.UnmarshalStrict(encoded, &decoded)
+		if err != nil {
+			t.Fatalf("value %d: flexssz.UnmarshalStrict: %v", i, err)
+		}
+		if !reflect.DeepEqual(decoded, value) {
+			t.Fatalf("value %d: decoded %+v, expected %+v", i, decoded, value)
+		}
+		root, err := view.HashSSZ()
+		if err != nil {
+			t.Fatalf("value %d: HashSSZ: %v", i, err)
+		}
+		expected, err := flexssz.HashTreeRoot(&value)
+		if err != nil {
+			t.Fatalf("value %d: flexssz.HashTreeRoot: %v", i, err)
+		}
+		if root != expected {
+			t.Fatalf("value %d: HashSSZ %x, flexssz.HashTreeRoot %x", i, root, expected)
+		}
+	}
+}
+
+// testIdentityValue mirrors the layout of Identity for flexssz
+type testIdentityValue struct {
+	Id        uint64
+	PublicKey [48]byte
+}
+
+// randomTestIdentityValue returns a random value of Identity
+func randomTestIdentityValue(r *rand.Rand) testIdentityValue {
+	var v testIdentityValue
+	v.Id = r.Uint64()
+	for i := range v.PublicKey {
+		v.PublicKey[i] = byte(r.Uint32())
+	}
+	return v
+}
+
+// TestIdentity_Generated checks random values of Identity against flexssz
+func TestIdentity_Generated(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 64; i++ {
+		value := randomTestIdentityValue(r)
+		data, err := flexssz.Marshal(&value)
+		if err != nil {
+			t.Fatalf("value %d: flexssz.Marshal: %v", i, err)
+		}
+		view := Identity(data)
+		if view.SizeSSZ() != len(data) {
+			t.Fatalf("value %d: SizeSSZ %d, flexssz encoded %d bytes", i, view.SizeSSZ(), len(data))
+		}
+		if got := view.Id(); got != value.Id {
+			t.Fatalf("value %d: Id %v, expected %v", i, got, value.Id)
+		}
+		if got := view.PublicKey(); got != value.PublicKey {
+			t.Fatalf("value %d: PublicKey %x, expected %x", i, got, value.PublicKey)
+		}
+		encoded, err := view.MarshalSSZ()
+		if err != nil {
+			t.Fatalf("value %d: MarshalSSZ: %v", i, err)
+		}
+		if !bytes.Equal(encoded, data) {
+			t.Fatalf("value %d: MarshalSSZ %x, flexssz encoded %x", i, encoded, data)
+		}
+		var decoded testIdentityValue
+		err = flexssz.UnmarshalStrict(encoded, &decoded)
+		if err != nil {
+			t.Fatalf("value %d: flexssz.UnmarshalStrict: %v", i, err)
+		}
+		if !reflect.DeepEqual(decoded, value) {
+			t.Fatalf("value %d: decoded %+v, expected %+v", i, decoded, value)
+		}
+		root, err := view.HashSSZ()
+		if err != nil {
+			t.Fatalf("value %d: HashSSZ: %v", i, err)
+		}
+		expected, err := flexssz.HashTreeRoot(&value)
+		if err != nil {
+			t.Fatalf("value %d: flexssz.HashTreeRoot: %v", i, err)
+		}
+		if root != expected {
+			t.Fatalf("value %d: HashSSZ %x, flexssz.HashTreeRoot %x", i, root, expected)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package penguin
 
 import (
+	"bytes"
 	"testing"
 	"encoding/hex"
+
+	"github.com/gfx-labs/ssz/merkle_tree"
 )
 
 func TestPenguinHashSSZ(t *testing.T) {
@@ -141,4 +144,110 @@ func TestNewPenguinWithValues(t *testing.T) {
 	if p.Identity().Id() != 12345 {
 		t.Errorf("Identity ID not set correctly")
 	}
-}
\ No newline at end of file
+}
+func TestPenguinProveField(t *testing.T) {
+	p := NewPenguin()
+	p.SetAwesomness(7)
+	p.SetCuteness(3)
+	identity := NewIdentity()
+	identity.SetId(42)
+	p.SetIdentity(identity)
+
+	root, err := p.HashSSZ()
+	if err != nil {
+		t.Fatalf("HashSSZ failed: %v", err)
+	}
+	leaves := make([]byte, 5*32)
+	if err := p.FillHashBuffer(leaves); err != nil {
+		t.Fatalf("FillHashBuffer failed: %v", err)
+	}
+
+	for i, name := range []string{"name", "species", "awesomness", "cuteness", "identity"} {
+		gindex, err := p.FieldGeneralizedIndex(name)
+		if err != nil {
+			t.Fatalf("FieldGeneralizedIndex(%s) failed: %v", name, err)
+		}
+		if gindex != uint64(8+i) {
+			t.Errorf("Expected generalized index %d for %s, got %d", 8+i, name, gindex)
+		}
+		branch, err := p.ProveField(name)
+		if err != nil {
+			t.Fatalf("ProveField(%s) failed: %v", name, err)
+		}
+
+		var node [32]byte
+		copy(node[:], leaves[i*32:])
+		for _, sibling := range branch {
+			if gindex&1 == 1 {
+				node = merkle_tree.Sha256(sibling[:], node[:])
+			} else {
+				node = merkle_tree.Sha256(node[:], sibling[:])
+			}
+			gindex >>= 1
+		}
+		if gindex != 1 || node != root {
+			t.Errorf("Proof of %s does not lead to the root", name)
+		}
+	}
+
+	if _, err := p.ProveField("flippers"); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestPenguinWriteReadSSZ(t *testing.T) {
+	var name [32]byte
+	copy(name[:], "Gentoo")
+	p := NewPenguinWithValues(name, [2]byte{1, 2}, 3, 4, NewIdentityWithValues(5, [48]byte{6}))
+
+	var buf bytes.Buffer
+	n, err := p.WriteSSZTo(&buf)
+	if err != nil || n != 93 {
+		t.Fatalf("WriteSSZTo wrote %d bytes: %v", n, err)
+	}
+	buf.WriteString("next")
+
+	read := NewPenguin()
+	if _, err := read.ReadSSZFrom(&buf, 92); err == nil {
+		t.Error("ReadSSZFrom read past its limit")
+	}
+	n, err = read.ReadSSZFrom(&buf, 0)
+	if err != nil || n != 93 {
+		t.Fatalf("ReadSSZFrom read %d bytes: %v", n, err)
+	}
+	if !bytes.Equal(read, p) || buf.String() != "next" {
+		t.Errorf("read %x, left %q", []byte(read), buf.String())
+	}
+	if _, err := read.ReadSSZFrom(&buf, 0); err == nil {
+		t.Error("ReadSSZFrom read a penguin from 4 bytes")
+	}
+}
+
+func TestPenguinBuilder(t *testing.T) {
+	var name [32]byte
+	copy(name[:], "Gentoo")
+	identity := NewIdentityWithValues(5, [48]byte{6})
+	p, err := NewPenguinBuilder().Name(name).Species([2]byte{1, 2}).Awesomness(3).Cuteness(4).Identity(identity).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !bytes.Equal(p, NewPenguinWithValues(name, [2]byte{1, 2}, 3, 4, identity)) {
+		t.Errorf("built %x", []byte(p))
+	}
+
+	// Setters called after Build do not change the built value
+	builder := NewPenguinBuilder().Cuteness(4)
+	first, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	builder.Cuteness(9)
+	if first.Cuteness() != 4 {
+		t.Errorf("built value changed to cuteness %d by a later setter", first.Cuteness())
+	}
+
+	// A truncated identity is reported by Build instead of being copied in part
+	if _, err := NewPenguinBuilder().Identity(identity[:40]).Cuteness(1).Build(); err == nil {
+		t.Error("Build accepted a truncated identity")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gfx-labs/ssz"
 	"github.com/gfx-labs/ssz/merkle_tree"
 	"github.com/gfx-labs/ssz/merkle_tree/bufpool"
+	"io"
 )
 
 // Checkpoint is a fixed-size SSZ container with the following byte layout:
@@ -53,6 +54,28 @@ func (s Checkpoint) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s Checkpoint) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s Checkpoint) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s Checkpoint) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -103,6 +126,36 @@ func (s Checkpoint) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s Checkpoint) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "epoch":
+		return 2, nil
+	case "root":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("Checkpoint has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s Checkpoint) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // Epoch returns the epoch field
 // Bytes: 0-7
 func (s Checkpoint) Epoch() uint64 {
@@ -173,6 +226,28 @@ func (s Fork) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s Fork) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s Fork) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s Fork) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -232,6 +307,38 @@ func (s Fork) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s Fork) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "previousVersion":
+		return 4, nil
+	case "currentVersion":
+		return 5, nil
+	case "epoch":
+		return 6, nil
+	default:
+		return 0, fmt.Errorf("Fork has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s Fork) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 96)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 3)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(2, int(gindex-4), leaves...)
+}
+
 // PreviousVersion returns the previousVersion field
 // Bytes: 0-3
 func (s Fork) PreviousVersion() [4]byte {
@@ -314,6 +421,28 @@ func (s Eth1Data) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s Eth1Data) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s Eth1Data) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s Eth1Data) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -367,6 +496,38 @@ func (s Eth1Data) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s Eth1Data) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "depositRoot":
+		return 4, nil
+	case "depositCount":
+		return 5, nil
+	case "blockHash":
+		return 6, nil
+	default:
+		return 0, fmt.Errorf("Eth1Data has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s Eth1Data) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 96)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 3)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(2, int(gindex-4), leaves...)
+}
+
 // DepositRoot returns the depositRoot field
 // Bytes: 0-31
 func (s Eth1Data) DepositRoot() [32]byte {
@@ -464,6 +625,28 @@ func (s Validator) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s Validator) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s Validator) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s Validator) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -554,6 +737,48 @@ func (s Validator) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s Validator) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "pubkey":
+		return 8, nil
+	case "withdrawalCredentials":
+		return 9, nil
+	case "effectiveBalance":
+		return 10, nil
+	case "slashed":
+		return 11, nil
+	case "activationEligibilityEpoch":
+		return 12, nil
+	case "activationEpoch":
+		return 13, nil
+	case "exitEpoch":
+		return 14, nil
+	case "withdrawableEpoch":
+		return 15, nil
+	default:
+		return 0, fmt.Errorf("Validator has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s Validator) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 256)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 8)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(3, int(gindex-8), leaves...)
+}
+
 // Pubkey returns the pubkey field
 // Bytes: 0-47
 func (s Validator) Pubkey() [48]byte {
@@ -706,6 +931,28 @@ func (s BeaconBlockHeader) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s BeaconBlockHeader) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s BeaconBlockHeader) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s BeaconBlockHeader) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -768,6 +1015,42 @@ func (s BeaconBlockHeader) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s BeaconBlockHeader) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "slot":
+		return 8, nil
+	case "proposerIndex":
+		return 9, nil
+	case "parentRoot":
+		return 10, nil
+	case "stateRoot":
+		return 11, nil
+	case "bodyRoot":
+		return 12, nil
+	default:
+		return 0, fmt.Errorf("BeaconBlockHeader has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s BeaconBlockHeader) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 160)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 5)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(3, int(gindex-8), leaves...)
+}
+
 // Slot returns the slot field
 // Bytes: 0-7
 func (s BeaconBlockHeader) Slot() uint64 {
@@ -871,6 +1154,28 @@ func (s SyncCommittee) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s SyncCommittee) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s SyncCommittee) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s SyncCommittee) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -925,6 +1230,36 @@ func (s SyncCommittee) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s SyncCommittee) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "pubkeys":
+		return 2, nil
+	case "aggregatePubkey":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("SyncCommittee has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s SyncCommittee) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // Pubkeys returns the pubkeys field
 func (s SyncCommittee) Pubkeys() interface{} {
 	return "TODO: implement vector getter"
@@ -999,6 +1334,28 @@ func (s AttestationData) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s AttestationData) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s AttestationData) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s AttestationData) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -1073,6 +1430,42 @@ func (s AttestationData) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s AttestationData) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "slot":
+		return 8, nil
+	case "index":
+		return 9, nil
+	case "beaconBlockRoot":
+		return 10, nil
+	case "source":
+		return 11, nil
+	case "target":
+		return 12, nil
+	default:
+		return 0, fmt.Errorf("AttestationData has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s AttestationData) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 160)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 5)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(3, int(gindex-8), leaves...)
+}
+
 // Slot returns the slot field
 // Bytes: 0-7
 func (s AttestationData) Slot() uint64 {
@@ -1176,6 +1569,28 @@ func (s SignedBeaconBlockHeader) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s SignedBeaconBlockHeader) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s SignedBeaconBlockHeader) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s SignedBeaconBlockHeader) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -1236,6 +1651,36 @@ func (s SignedBeaconBlockHeader) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s SignedBeaconBlockHeader) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "message":
+		return 2, nil
+	case "signature":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("SignedBeaconBlockHeader has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s SignedBeaconBlockHeader) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // Message returns the message field
 // Bytes: 0-111
 func (s SignedBeaconBlockHeader) Message() BeaconBlockHeader {
@@ -1303,6 +1748,28 @@ func (s ProposerSlashing) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s ProposerSlashing) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s ProposerSlashing) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s ProposerSlashing) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -1362,6 +1829,36 @@ func (s ProposerSlashing) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s ProposerSlashing) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "signedHeader1":
+		return 2, nil
+	case "signedHeader2":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("ProposerSlashing has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s ProposerSlashing) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // SignedHeader1 returns the signedHeader1 field
 // Bytes: 0-207
 func (s ProposerSlashing) SignedHeader1() SignedBeaconBlockHeader {
@@ -1435,6 +1932,28 @@ func (s DepositData) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s DepositData) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s DepositData) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s DepositData) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -1505,6 +2024,40 @@ func (s DepositData) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s DepositData) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "pubkey":
+		return 4, nil
+	case "withdrawalCredentials":
+		return 5, nil
+	case "amount":
+		return 6, nil
+	case "signature":
+		return 7, nil
+	default:
+		return 0, fmt.Errorf("DepositData has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s DepositData) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 128)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 4)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(2, int(gindex-4), leaves...)
+}
+
 // Pubkey returns the pubkey field
 // Bytes: 0-47
 func (s DepositData) Pubkey() [48]byte {
@@ -1596,6 +2149,28 @@ func (s Deposit) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s Deposit) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s Deposit) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s Deposit) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -1649,6 +2224,36 @@ func (s Deposit) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s Deposit) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "proof":
+		return 2, nil
+	case "data":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("Deposit has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s Deposit) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // Proof returns the proof field
 func (s Deposit) Proof() interface{} {
 	return "TODO: implement vector getter"
@@ -1714,6 +2319,28 @@ func (s VoluntaryExit) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s VoluntaryExit) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s VoluntaryExit) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s VoluntaryExit) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -1767,6 +2394,36 @@ func (s VoluntaryExit) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s VoluntaryExit) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "epoch":
+		return 2, nil
+	case "validatorIndex":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("VoluntaryExit has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s VoluntaryExit) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // Epoch returns the epoch field
 // Bytes: 0-7
 func (s VoluntaryExit) Epoch() uint64 {
@@ -1834,6 +2491,28 @@ func (s SignedVoluntaryExit) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s SignedVoluntaryExit) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s SignedVoluntaryExit) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s SignedVoluntaryExit) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -1894,6 +2573,36 @@ func (s SignedVoluntaryExit) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s SignedVoluntaryExit) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "message":
+		return 2, nil
+	case "signature":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("SignedVoluntaryExit has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s SignedVoluntaryExit) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // Message returns the message field
 // Bytes: 0-15
 func (s SignedVoluntaryExit) Message() VoluntaryExit {
@@ -1961,6 +2670,28 @@ func (s SyncAggregate) MarshalSSZ() ([]byte, error) {
 	return s, nil
 }
 
+// WriteSSZTo writes the bytes to w
+func (s SyncAggregate) WriteSSZTo(w io.Writer) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	n, err := w.Write(s)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past
+// them is read. A positive limit is the largest number of bytes that may be read.
+func (s SyncAggregate) ReadSSZFrom(r io.Reader, limit int64) (int64, error) {
+	if len(s) != s.SizeSSZ() {
+		return 0, ssz.NewErrSizeMismatch(s.SizeSSZ(), len(s))
+	}
+	if limit > 0 && int64(len(s)) > limit {
+		return 0, fmt.Errorf("%d bytes exceed the limit of %d bytes", len(s), limit)
+	}
+	n, err := io.ReadFull(r, s)
+	return int64(n), err
+}
+
 // FillHashBuffer fills the provided buffer with hashes of all fields
 func (s SyncAggregate) FillHashBuffer(buf []byte) error {
 	// Ensure buffer is large enough
@@ -2022,6 +2753,36 @@ func (s SyncAggregate) HashSSZ() (hash [32]byte, err error) {
 	return
 }
 
+// FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object
+func (s SyncAggregate) FieldGeneralizedIndex(name string) (uint64, error) {
+	switch name {
+	case "syncCommitteeBits":
+		return 2, nil
+	case "syncCommitteeSignature":
+		return 3, nil
+	default:
+		return 0, fmt.Errorf("SyncAggregate has no field %q", name)
+	}
+}
+
+// ProveField returns the merkle branch proving the hash tree root of the named field against the
+// hash tree root of the object, starting with the sibling of the field
+func (s SyncAggregate) ProveField(name string) ([][32]byte, error) {
+	gindex, err := s.FieldGeneralizedIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	hashBuffer := make([]byte, 64)
+	if err := s.FillHashBuffer(hashBuffer); err != nil {
+		return nil, err
+	}
+	leaves := make([][32]byte, 2)
+	for i := range leaves {
+		copy(leaves[i][:], hashBuffer[i*32:])
+	}
+	return merkle_tree.MerkleProof(1, int(gindex-2), leaves...)
+}
+
 // SyncCommitteeBits returns the syncCommitteeBits field
 // Bytes: 0-63
 func (s SyncAggregate) SyncCommitteeBits() [64]byte {
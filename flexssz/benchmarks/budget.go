@@ -0,0 +1,85 @@
+// Package benchmarks checks the flexssz codec against performance budgets, so regressions in
+// the reflection paths fail a test instead of going unnoticed in benchmark output. The budgets
+// are measured on the beacon state fixtures of the spectests package:
+//
+//	go test ./flexssz/benchmarks -run Budget               # allocations
+//	go test ./flexssz/benchmarks -run Budget -budget.time  # and ns/op, which depends on the machine
+package benchmarks
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Budget is the most an operation may cost. Zero fields are not checked.
+type Budget struct {
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+	// AllocatedBytes bounds flexssz.TypeStats.AllocatedBytes of one Unmarshal, the slices and
+	// strings the decoder allocates for the value
+	AllocatedBytes int64
+}
+
+// Cost is what an operation was measured to cost
+type Cost struct {
+	NsPerOp        int64
+	AllocsPerOp    int64
+	BytesPerOp     int64
+	AllocatedBytes int64
+}
+
+// Measure returns the allocations of one call of op, after a call to warm up caches. With timed
+// set, the ns/op of op is measured as well with testing.Benchmark, which runs it for a second.
+func Measure(op func() error, timed bool) (Cost, error) {
+	if err := op(); err != nil {
+		return Cost{}, err
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	err := op()
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		return Cost{}, err
+	}
+	cost := Cost{
+		AllocsPerOp: int64(after.Mallocs - before.Mallocs),
+		BytesPerOp:  int64(after.TotalAlloc - before.TotalAlloc),
+	}
+	if !timed {
+		return cost, nil
+	}
+	result := testing.Benchmark(func(b *testing.B) {
+		for b.Loop() {
+			if err = op(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	if err != nil {
+		return Cost{}, err
+	}
+	cost.NsPerOp = result.NsPerOp()
+	return cost, nil
+}
+
+// Check returns an error listing every cost over the budget
+func (b Budget) Check(c Cost) error {
+	var over []string
+	check := func(name string, budget, cost int64) {
+		if budget > 0 && cost > budget {
+			over = append(over, fmt.Sprintf("%s %d exceeds budget %d by %.1f%%", name, cost, budget, float64(cost-budget)*100/float64(budget)))
+		}
+	}
+	check("ns/op", b.NsPerOp, c.NsPerOp)
+	check("allocs/op", b.AllocsPerOp, c.AllocsPerOp)
+	check("B/op", b.BytesPerOp, c.BytesPerOp)
+	check("allocated bytes", b.AllocatedBytes, c.AllocatedBytes)
+	if len(over) > 0 {
+		return fmt.Errorf("%s", strings.Join(over, ", "))
+	}
+	return nil
+}
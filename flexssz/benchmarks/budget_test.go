@@ -0,0 +1,109 @@
+//go:build !purego && !tinygo && !wasm
+
+// The budgets are set for the unsafe helpers, the portable ones copy and allocate far more
+
+package benchmarks
+
+import (
+	"compress/gzip"
+	"flag"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/gfx-labs/ssz/flexssz/spectests"
+	"github.com/stretchr/testify/require"
+)
+
+var checkTime = flag.Bool("budget.time", false, "check ns/op budgets as well, which depend on the machine")
+
+// budgets of the operations on each fixture, with about 20% headroom over what they cost when
+// they were set. ns/op was measured on a single core amd64 machine. Lower them along with
+// improvements, so the gain is kept.
+var budgets = map[string]map[string]Budget{
+	"zero_beacon_state_bellatrix": {
+		"Unmarshal":     {NsPerOp: 70_000_000, AllocsPerOp: 545_000, BytesPerOp: 16_000_000, AllocatedBytes: 5_700_000},
+		"Marshal":       {NsPerOp: 34_000_000, AllocsPerOp: 120_000, BytesPerOp: 22_000_000},
+		"MarshalAppend": {NsPerOp: 10_000_000, AllocsPerOp: 70, BytesPerOp: 3_300_000},
+		"HashTreeRoot":  {NsPerOp: 16_000_000, AllocsPerOp: 1_300, BytesPerOp: 3_350_000},
+	},
+	"beacon_state_bellatrix": {
+		"Unmarshal":     {NsPerOp: 2_200_000_000, AllocsPerOp: 17_800_000, BytesPerOp: 700_000_000, AllocatedBytes: 56_600_000},
+		"Marshal":       {NsPerOp: 1_650_000_000, AllocsPerOp: 8_750_000, BytesPerOp: 770_000_000},
+		"MarshalAppend": {NsPerOp: 440_000_000, AllocsPerOp: 70, BytesPerOp: 70_000_000},
+		"HashTreeRoot":  {NsPerOp: 1_350_000_000, AllocsPerOp: 960_000, BytesPerOp: 206_000_000},
+	},
+}
+
+// operations are measured in this order
+var operations = []string{"Unmarshal", "Marshal", "MarshalAppend", "HashTreeRoot"}
+
+// loadFixture reads and decompresses a gzipped fixture of the spectests package
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	file, err := os.Open("../spectests/_fixtures/" + name + ".ssz.gz")
+	require.NoError(t, err)
+	defer file.Close()
+	gzReader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	defer gzReader.Close()
+	data, err := io.ReadAll(gzReader)
+	require.NoError(t, err)
+	return data
+}
+
+func TestBudget(t *testing.T) {
+	for _, fixture := range []string{"zero_beacon_state_bellatrix", "beacon_state_bellatrix"} {
+		t.Run(fixture, func(t *testing.T) {
+			if testing.Short() && fixture == "beacon_state_bellatrix" {
+				t.Skip("the mainnet state takes seconds per operation")
+			}
+			data := loadFixture(t, fixture)
+			state := &spectests.BeaconStateBellatrix{}
+			require.NoError(t, flexssz.Unmarshal(data, state))
+
+			ops := map[string]func() error{
+				"Unmarshal": func() error {
+					return flexssz.Unmarshal(data, &spectests.BeaconStateBellatrix{})
+				},
+				"Marshal": func() error {
+					_, err := flexssz.Marshal(state)
+					return err
+				},
+				"MarshalAppend": func() error {
+					_, err := flexssz.MarshalAppend(nil, state)
+					return err
+				},
+				"HashTreeRoot": func() error {
+					_, err := flexssz.HashTreeRoot(state)
+					return err
+				},
+			}
+			for _, name := range operations {
+				budget := budgets[fixture][name]
+				t.Run(name, func(t *testing.T) {
+					cost, err := Measure(ops[name], *checkTime)
+					require.NoError(t, err)
+					if name == "Unmarshal" {
+						cost.AllocatedBytes = allocatedBytes(t, data)
+					}
+					t.Logf("%+v", cost)
+					if err := budget.Check(cost); err != nil {
+						t.Error(err)
+					}
+				})
+			}
+		})
+	}
+}
+
+// allocatedBytes returns the bytes the decoder allocates for a state, from flexssz.Stats
+func allocatedBytes(t *testing.T, data []byte) int64 {
+	flexssz.SetStats(true)
+	defer flexssz.SetStats(false)
+	flexssz.ResetStats()
+	defer flexssz.ResetStats()
+	require.NoError(t, flexssz.Unmarshal(data, &spectests.BeaconStateBellatrix{}))
+	return int64(flexssz.Stats().Types["spectests.BeaconStateBellatrix"].AllocatedBytes)
+}
@@ -4,9 +4,22 @@ import (
 	"fmt"
 )
 
+// emptyBitList is the serialized form of a bitlist with no bits, which only holds the delimiter bit
+var emptyBitList = []byte{0x01}
+
+// bitlistMaxBytes returns the most bytes a serialized bitlist of at most maxBits bits can have,
+// counting the delimiter bit
+func bitlistMaxBytes(maxBits int) int {
+	return maxBits/8 + 1
+}
+
 // EncodeBitList encodes a bitlist to SSZ format.
 // A bitlist is a []byte where the last byte has a delimiter bit set to indicate the end.
 // The bits are packed into bytes in little-endian order (bit 0 is the LSB of byte 0).
+//
+// Deprecated: bitlist fields hold the serialized form, with the delimiter bit, and are encoded
+// as they are, see ValidateBitlist. EncodeBitList also drops trailing zero bits, so the length
+// of bits is lost.
 func EncodeBitList(bits []byte, maxBits int) ([]byte, error) {
 	if len(bits) == 0 {
 		// Empty bitlist is encoded as a single byte with delimiter bit
@@ -57,6 +70,9 @@ func EncodeBitList(bits []byte, maxBits int) ([]byte, error) {
 
 // DecodeBitList decodes a bitlist from SSZ format.
 // Returns the bitlist as []byte and the number of bits.
+//
+// Deprecated: bitlist fields hold the serialized form, with the delimiter bit, and are decoded
+// as they are, see ValidateBitlist.
 func DecodeBitList(data []byte, maxBits int) ([]byte, int, error) {
 	if len(data) == 0 {
 		return nil, 0, fmt.Errorf("empty data for bitlist")
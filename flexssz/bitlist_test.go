@@ -2,8 +2,12 @@ package flexssz
 
 import (
 	"bytes"
+	"math/bits"
+	"reflect"
 	"testing"
 
+	"github.com/gfx-labs/ssz"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -155,3 +159,119 @@ func TestBitListHelpers(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestBitListFieldSerializedForm(t *testing.T) {
+	type Aggregate struct {
+		Bits []byte `ssz:"bitlist" ssz-max:"16"`
+	}
+
+	// 8 bits with the last four unset, the delimiter bit is in the second byte
+	value := Aggregate{Bits: []byte{0x0A, 0x01}}
+	encoded, err := Marshal(value)
+	require.NoError(t, err)
+	require.Equal(t, []byte{4, 0, 0, 0, 0x0A, 0x01}, encoded)
+
+	var decoded Aggregate
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	require.Equal(t, value.Bits, decoded.Bits, "the length survives the round trip")
+
+	// An unset field is the empty bitlist
+	encoded, err = Marshal(Aggregate{})
+	require.NoError(t, err)
+	require.Equal(t, []byte{4, 0, 0, 0, 0x01}, encoded)
+
+	// Bare bits without a delimiter are rejected
+	_, err = Marshal(Aggregate{Bits: []byte{0x0A, 0x00}})
+	require.Error(t, err)
+}
+
+// The go-bitfield types are recognized by their methods, which these mimic
+type bitfieldTestBitlist []byte
+
+func (b bitfieldTestBitlist) Len() uint64 {
+	if len(b) == 0 || b[len(b)-1] == 0 {
+		return 0
+	}
+	return uint64(8*(len(b)-1) + bits.Len8(b[len(b)-1]) - 1)
+}
+
+func (b bitfieldTestBitlist) BitAt(idx uint64) bool {
+	return idx < b.Len() && b[idx/8]&(1<<(idx%8)) != 0
+}
+
+type bitfieldTestBitvector4 []byte
+
+func (b bitfieldTestBitvector4) Len() uint64 { return 4 }
+
+func (b bitfieldTestBitvector4) BitAt(idx uint64) bool {
+	return idx < 4 && len(b) > 0 && b[0]&(1<<idx) != 0
+}
+
+type bitfieldTestBitvector64 []byte
+
+func (b bitfieldTestBitvector64) Len() uint64 { return 64 }
+
+func (b bitfieldTestBitvector64) BitAt(idx uint64) bool {
+	return idx < 64 && int(idx/8) < len(b) && b[idx/8]&(1<<(idx%8)) != 0
+}
+
+type bitfieldTestTyped struct {
+	Aggregation bitfieldTestBitlist `ssz-max:"2048"`
+	Sync        bitfieldTestBitvector4
+	Subnets     bitfieldTestBitvector64
+	Committees  []bitfieldTestBitlist `ssz-max:"4,64"`
+}
+
+type bitfieldTestTagged struct {
+	Aggregation []byte   `ssz:"bitlist" ssz-max:"2048"`
+	Sync        []byte   `ssz:"bitvector" ssz-size:"4"`
+	Subnets     []byte   `ssz:"bitvector" ssz-size:"64"`
+	Committees  [][]byte `ssz-max:"4,64"`
+}
+
+func TestBitfieldTypes(t *testing.T) {
+	v := &bitfieldTestTyped{
+		Aggregation: bitfieldTestBitlist{0xff, 0x05},
+		Sync:        bitfieldTestBitvector4{0x09},
+		Subnets:     bitfieldTestBitvector64{1, 2, 3, 4, 5, 6, 7, 8},
+		Committees:  []bitfieldTestBitlist{{0x03}, {0x01}},
+	}
+	w := &bitfieldTestTagged{
+		Aggregation: []byte{0xff, 0x05},
+		Sync:        []byte{0x09},
+		Subnets:     []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Committees:  [][]byte{{0x03}, {0x01}},
+	}
+	info, err := GetTypeInfo(reflect.TypeOf(*v), nil)
+	require.NoError(t, err)
+	assert.Equal(t, ssz.TypeBitList, info.Fields[0].Type.Type)
+	assert.Equal(t, ssz.TypeBitVector, info.Fields[1].Type.Type)
+	assert.Equal(t, 64, info.Fields[2].Type.BitLength)
+	assert.Equal(t, ssz.TypeBitList, info.Fields[3].Type.ElementType.Type)
+
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	expected, err := Marshal(w)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	// Committees is a list of bitlists in the typed struct, but of byte lists in the tagged one
+	w.Committees = nil
+	v.Committees = nil
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	expectedRoot, err := HashTreeRoot(w)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	data, err = Marshal(v)
+	require.NoError(t, err)
+	var decoded bitfieldTestTyped
+	require.NoError(t, UnmarshalStrict(data, &decoded))
+	assert.Equal(t, v.Aggregation, decoded.Aggregation)
+	assert.Equal(t, v.Sync, decoded.Sync)
+	assert.True(t, decoded.Sync.BitAt(3))
+
+	// Bits past the length of a bitvector are rejected like for tagged bitvectors
+	data[4] = 0x10
+	assert.Error(t, Unmarshal(data, &decoded))
+}
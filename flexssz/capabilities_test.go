@@ -0,0 +1,144 @@
+package flexssz
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capabilityTestSamples has a container with a field of each SSZ type that has a Go form, and
+// its encoding, which is needed to decode types that cannot be encoded
+var capabilityTestSamples = map[ssz.TypeName]struct {
+	value   any
+	encoded []byte
+}{
+	ssz.TypeUint8:  {&struct{ V uint8 }{1}, nil},
+	ssz.TypeUint16: {&struct{ V uint16 }{1}, nil},
+	ssz.TypeUint32: {&struct{ V uint32 }{1}, nil},
+	ssz.TypeUint64: {&struct{ V uint64 }{1}, nil},
+	ssz.TypeUint128: {&struct {
+		V uint256.Int `ssz:"uint128"`
+	}{*uint256.NewInt(1)}, nil},
+	ssz.TypeUint256:   {&struct{ V uint256.Int }{*uint256.NewInt(1)}, nil},
+	ssz.TypeBoolean:   {&struct{ V bool }{true}, nil},
+	ssz.TypeContainer: {&struct{ V struct{ A, B uint64 } }{}, nil},
+	ssz.TypeVector:    {&struct{ V [4]uint16 }{}, nil},
+	ssz.TypeList: {&struct {
+		V []uint16 `ssz-max:"4"`
+	}{[]uint16{1, 2}}, nil},
+	ssz.TypeBitVector: {&struct {
+		V []byte `ssz:"bitvector" ssz-size:"12"`
+	}{[]byte{1, 2}}, nil},
+	ssz.TypeBitList: {&struct {
+		V []byte `ssz:"bitlist" ssz-max:"12"`
+	}{[]byte{5}}, nil},
+	ssz.TypeUnion: {&struct{ V testPayload }{testBlobCount(5)}, nil},
+}
+
+func TestCapabilities(t *testing.T) {
+	report := ssz.Capabilities()
+
+	// Every type of the spec is reported, and only the schema ref is left out
+	names := []ssz.TypeName{
+		ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeUint128, ssz.TypeUint256,
+		ssz.TypeBoolean, ssz.TypeContainer, ssz.TypeVector, ssz.TypeList, ssz.TypeBitVector, ssz.TypeBitList,
+		ssz.TypeUnion, ssz.TypeStableContainer, ssz.TypeProfile, ssz.TypeOptional,
+	}
+	assert.Len(t, report.Types, len(names))
+
+	for _, name := range names {
+		t.Run(string(name), func(t *testing.T) {
+			support, ok := report.Types[name]
+			require.True(t, ok, "missing from the report")
+
+			sample, ok := capabilityTestSamples[name]
+			if !ok {
+				// Types without a Go form cannot be supported
+				assert.Equal(t, ssz.Support{}, support)
+				return
+			}
+			info, err := GetTypeInfo(reflect.TypeOf(sample.value).Elem(), nil)
+			require.NoError(t, err)
+			require.Equal(t, name, info.Fields[0].Type.Type, "sample has the wrong type")
+
+			encoded, err := Marshal(sample.value)
+			assert.Equal(t, support.Encode, err == nil, "encode: %v", err)
+			if sample.encoded != nil {
+				encoded = sample.encoded
+			}
+
+			decoded := reflect.New(reflect.TypeOf(sample.value).Elem()).Interface()
+			err = UnmarshalStrict(encoded, decoded)
+			assert.Equal(t, support.Decode, err == nil, "decode: %v", err)
+			if support.Encode && support.Decode {
+				assert.Equal(t, sample.value, decoded)
+			}
+
+			_, err = HashTreeRoot(sample.value)
+			assert.Equal(t, support.HashTreeRoot, err == nil, "hash tree root: %v", err)
+		})
+	}
+}
+
+func TestCapabilities_Modes(t *testing.T) {
+	report := ssz.Capabilities()
+	value := &struct {
+		Data   []byte           `ssz-max:"8"`
+		Labels map[uint8][]byte `ssz:"kvlist" ssz-max:"4,8"`
+	}{Data: []byte{1, 2}, Labels: map[uint8][]byte{1: {3}}}
+	encoded, err := Marshal(value)
+	require.NoError(t, err)
+
+	if report.StrictDecoding || report.ZeroCopy {
+		decoded := reflect.New(reflect.TypeOf(value).Elem()).Interface()
+		opts := DecodeOptions{Strict: report.StrictDecoding, ZeroCopy: report.ZeroCopy}
+		assert.NoError(t, UnmarshalWithOptions(encoded, decoded, opts))
+		assert.Equal(t, value, decoded)
+	}
+	if report.SizedEncoding {
+		appended, err := MarshalAppend(nil, value)
+		assert.NoError(t, err)
+		assert.Equal(t, encoded, appended)
+	}
+	if report.HashCache {
+		_, err := HashTreeRootWithCache(value, NewHashCache())
+		assert.NoError(t, err)
+	}
+	if report.LazyLists {
+		info, err := GetTypeInfo(reflect.TypeFor[uint8](), nil)
+		require.NoError(t, err)
+		list, err := NewLazyList(value.Data, info)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, list.Len())
+	}
+	if report.JSON {
+		_, err := MarshalJSON(value)
+		assert.NoError(t, err)
+	}
+	if report.Snappy {
+		_, err := MarshalSnappy(value)
+		assert.NoError(t, err)
+	}
+	assert.True(t, report.MapEncoding, "maps are encoded in the value above")
+	if report.ProgressiveLists {
+		progressive := &struct {
+			Data []byte `ssz:"progressive-list"`
+		}{Data: []byte("progressive lists have no limit")}
+		encoded, err := Marshal(progressive)
+		require.NoError(t, err)
+		decoded := reflect.New(reflect.TypeOf(progressive).Elem()).Interface()
+		require.NoError(t, Unmarshal(encoded, decoded))
+		assert.Equal(t, progressive, decoded)
+
+		// A container with one field has the root of the field
+		listRoot, err := MerkleizeProgressive(PackBytes(progressive.Data))
+		require.NoError(t, err)
+		root, err := HashTreeRoot(progressive)
+		require.NoError(t, err)
+		assert.Equal(t, MixInLength(listRoot, uint64(len(progressive.Data))), root)
+	}
+}
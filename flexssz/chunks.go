@@ -0,0 +1,147 @@
+package flexssz
+
+import (
+	"fmt"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
+)
+
+// The helpers below follow the merkleization section of the SSZ spec, for hashing types flexssz
+// cannot describe with their TypeInfo alone.
+
+// ChunkCount returns chunk_count(type), the number of chunks a value of the type is merkleized
+// into, which is the limit lists are padded to:
+//   - 1 for basic types
+//   - (N + 255) / 256 for bitvectors of N bits and bitlists of at most N bits
+//   - (N * size + 31) / 32 for vectors of N and lists of at most N basic values of size bytes
+//   - N for vectors of N and lists of at most N composite values
+//   - the number of fields for containers
+//
+// Unions, progressive lists and custom types have no chunk count and return 0.
+func ChunkCount(typeInfo *TypeInfo) uint64 {
+	switch typeInfo.Type {
+	case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64,
+		ssz.TypeUint128, ssz.TypeUint256, ssz.TypeBoolean:
+		if typeInfo.Custom {
+			return 0
+		}
+		return 1
+	case ssz.TypeBitVector, ssz.TypeBitList:
+		return uint64((typeInfo.BitLength + 255) / 256)
+	case ssz.TypeList, ssz.TypeVector:
+		if typeInfo.Progressive {
+			return 0
+		}
+		if typeInfo.ByteList {
+			return (uint64(typeInfo.Length) + BYTES_PER_CHUNK - 1) / BYTES_PER_CHUNK
+		}
+		if typeInfo.ElementType != nil && isBasicType(typeInfo.ElementType) {
+			// Basic sizes divide the chunk size, so limits like 2^62 are counted without
+			// multiplying them by the size first
+			perChunk := uint64(BYTES_PER_CHUNK / BasicTypeSize(typeInfo.ElementType))
+			return (uint64(typeInfo.Length) + perChunk - 1) / perChunk
+		}
+		return uint64(typeInfo.Length)
+	case ssz.TypeContainer:
+		return uint64(len(typeInfo.Fields))
+	default:
+		return 0
+	}
+}
+
+// BasicTypeSize returns the size in bytes of a basic type, or 0 for other types
+func BasicTypeSize(typeInfo *TypeInfo) int {
+	switch typeInfo.Type {
+	case ssz.TypeUint8, ssz.TypeBoolean:
+		return 1
+	case ssz.TypeUint16:
+		return 2
+	case ssz.TypeUint32:
+		return 4
+	case ssz.TypeUint64:
+		return 8
+	case ssz.TypeUint128:
+		return 16
+	case ssz.TypeUint256:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// PackBytes implements pack from the SSZ spec on the serialization of basic values: it splits
+// data into chunks, zero padding the last one. Empty data packs into a single zero chunk.
+func PackBytes(data []byte) [][32]byte {
+	numChunks := (len(data) + BYTES_PER_CHUNK - 1) / BYTES_PER_CHUNK
+	if numChunks == 0 {
+		numChunks = 1 // At least one chunk
+	}
+
+	chunks := make([][32]byte, numChunks)
+	copyToChunks(chunks, data)
+
+	return chunks
+}
+
+// Merkleize implements merkleize from the SSZ spec: the root of the chunks padded with zero
+// chunks to limit, rounded up to a power of two. A limit of 0 pads to the number of chunks
+// instead. It returns an error wrapping merkle_tree.ErrTooManyChunks if there are more chunks
+// than the limit. The chunks are not modified.
+func Merkleize(chunks [][32]byte, limit uint64) ([32]byte, error) {
+	if limit == 0 {
+		limit = uint64(len(chunks))
+	} else if uint64(len(chunks)) > limit {
+		return [32]byte{}, fmt.Errorf("merkleize: %w: %d chunks exceed limit %d", merkle_tree.ErrTooManyChunks, len(chunks), limit)
+	}
+	if limit > 1<<63 {
+		// The limit rounds up to 2^64 leaves, past what mathutil.NextPowerOfTwo represents, so the
+		// chunks are the left half of a tree with a zero right half
+		left, err := Merkleize(chunks, 1<<63)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		right := merkle_tree.ZeroHash(63)
+		return merkle_tree.Sha256(left[:], right[:]), nil
+	}
+	if len(chunks) == 0 {
+		return merkle_tree.ZeroHash(mathutil.GetDepth(mathutil.NextPowerOfTwo(limit))), nil
+	}
+	var root [32]byte
+	if err := merkle_tree.ComputeMerkleRootRange(chunkedToSingle(chunks), root[:], mathutil.NextPowerOfTwo(limit), 0); err != nil {
+		return [32]byte{}, fmt.Errorf("merkleize: %w", err)
+	}
+	return root, nil
+}
+
+// MerkleizeProgressive implements merkleize_progressive from EIP-7916, which progressive lists
+// are merkleized with instead of a limit: the first chunk is the right child of the root, the next
+// 4 chunks a subtree in its left child, then 16, 64 and so on, so the tree grows without
+// changing the position of existing chunks. No chunks merkleize to a zero root.
+func MerkleizeProgressive(chunks [][32]byte) ([32]byte, error) {
+	return merkleizeProgressive(chunks, 1)
+}
+
+func merkleizeProgressive(chunks [][32]byte, numLeaves uint64) ([32]byte, error) {
+	if len(chunks) == 0 {
+		return [32]byte{}, nil
+	}
+	n := min(numLeaves, uint64(len(chunks)))
+	right, err := Merkleize(chunks[:n], numLeaves)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	left, err := merkleizeProgressive(chunks[n:], numLeaves*4)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return merkle_tree.Sha256(left[:], right[:]), nil
+}
+
+// MixInLength implements mix_in_length from the SSZ spec, the root of a list from the root of
+// its chunks and its length
+func MixInLength(root [32]byte, length uint64) [32]byte {
+	lengthRoot := merkle_tree.Uint64Root(length)
+	return merkle_tree.Sha256(root[:], lengthRoot[:])
+}
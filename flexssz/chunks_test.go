@@ -0,0 +1,162 @@
+package flexssz
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chunksTestContainer struct {
+	Slot       uint64
+	Balances   []uint64   `ssz-max:"1024"`
+	Roots      [][32]byte `ssz-max:"100"`
+	Bits       []byte     `ssz:"bitlist" ssz-max:"2048"`
+	Flags      [3]uint16
+	Validators []listTestItem `ssz-max:"7"`
+}
+
+func TestChunkCount(t *testing.T) {
+	info, err := GetTypeInfo(reflect.TypeOf(chunksTestContainer{}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), ChunkCount(info))
+
+	counts := map[string]uint64{
+		"Slot":       1,
+		"Balances":   256,
+		"Roots":      100,
+		"Bits":       8,
+		"Flags":      1,
+		"Validators": 7,
+	}
+	for _, field := range info.Fields {
+		assert.Equal(t, counts[field.Name], ChunkCount(field.Type), field.Name)
+	}
+	assert.Equal(t, 8, BasicTypeSize(info.Fields[1].Type.ElementType))
+}
+
+func TestMerkleize(t *testing.T) {
+	value := chunksTestContainer{
+		Slot:       9,
+		Balances:   []uint64{1, 2, 3, 4, 5},
+		Roots:      [][32]byte{{1}, {2}, {3}},
+		Bits:       []byte{0x05},
+		Validators: []listTestItem{{A: 1}},
+	}
+	expected, err := HashTreeRoot(&value)
+	require.NoError(t, err)
+	fields, err := FieldRoots(&value)
+	require.NoError(t, err)
+	info, err := GetTypeInfo(reflect.TypeOf(value), nil)
+	require.NoError(t, err)
+
+	root, err := Merkleize(fields, ChunkCount(info))
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+	root, err = Merkleize(fields, 0)
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+
+	// A list of basic values is the mixed in root of its packed serialization
+	balances := make([]byte, 0, 40)
+	for _, b := range value.Balances {
+		balances = binary.LittleEndian.AppendUint64(balances, b)
+	}
+	root, err = Merkleize(PackBytes(balances), ChunkCount(info.Fields[1].Type))
+	require.NoError(t, err)
+	assert.Equal(t, fields[1], MixInLength(root, uint64(len(value.Balances))))
+
+	// Empty chunks are zero hashes at the depth of the limit
+	root, err = Merkleize(nil, 100)
+	require.NoError(t, err)
+	assert.Equal(t, merkle_tree.ZeroHash(7), root)
+
+	_, err = Merkleize(fields, 4)
+	assert.ErrorContains(t, err, "exceed limit")
+	assert.ErrorIs(t, err, merkle_tree.ErrTooManyChunks)
+
+	// The chunks are left as they were
+	before := append([][32]byte(nil), fields...)
+	_, err = Merkleize(fields, 8)
+	require.NoError(t, err)
+	assert.Equal(t, before, fields)
+}
+
+func TestMerkleize_ExtremeLimits(t *testing.T) {
+	chunk := [32]byte{1}
+
+	root, err := Merkleize([][32]byte{chunk}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, chunk, root)
+
+	_, err = Merkleize([][32]byte{chunk, chunk}, 1)
+	assert.ErrorIs(t, err, merkle_tree.ErrTooManyChunks)
+
+	// 2^63 leaves is a tree of depth 63, anything above rounds up to depth 64
+	atMax := chunk
+	for depth := uint8(0); depth < 63; depth++ {
+		zero := merkle_tree.ZeroHash(depth)
+		atMax = merkle_tree.Sha256(atMax[:], zero[:])
+	}
+	root, err = Merkleize([][32]byte{chunk}, 1<<63)
+	require.NoError(t, err)
+	assert.Equal(t, atMax, root)
+
+	zero := merkle_tree.ZeroHash(63)
+	aboveMax := merkle_tree.Sha256(atMax[:], zero[:])
+	for _, limit := range []uint64{1<<63 + 1, 1<<64 - 1} {
+		root, err = Merkleize([][32]byte{chunk}, limit)
+		require.NoError(t, err)
+		assert.Equal(t, aboveMax, root)
+	}
+	root, err = Merkleize(nil, 1<<64-1)
+	require.NoError(t, err)
+	assert.Equal(t, merkle_tree.ZeroHash(64), root)
+}
+
+func TestPackBytes(t *testing.T) {
+	assert.Equal(t, [][32]byte{{}}, PackBytes(nil))
+	chunks := PackBytes(make([]byte, 33))
+	assert.Len(t, chunks, 2)
+}
+
+// naiveMerkleizeProgressive follows merkleize_progressive of EIP-7916 with a naive merkleize
+func naiveMerkleizeProgressive(chunks [][32]byte, numLeaves int) [32]byte {
+	if len(chunks) == 0 {
+		return [32]byte{}
+	}
+	var merkleize func(chunks [][32]byte, width int) [32]byte
+	merkleize = func(chunks [][32]byte, width int) [32]byte {
+		if width == 1 {
+			if len(chunks) == 0 {
+				return [32]byte{}
+			}
+			return chunks[0]
+		}
+		half := width / 2
+		left, right := merkleize(chunks[:min(half, len(chunks))], half), merkleize(chunks[min(half, len(chunks)):], half)
+		return merkle_tree.Sha256(left[:], right[:])
+	}
+	n := min(numLeaves, len(chunks))
+	right := merkleize(chunks[:n], numLeaves)
+	left := naiveMerkleizeProgressive(chunks[n:], numLeaves*4)
+	return merkle_tree.Sha256(left[:], right[:])
+}
+
+func TestMerkleizeProgressive(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 5, 21, 22, 100} {
+		chunks := make([][32]byte, n)
+		for i := range chunks {
+			chunks[i][0], chunks[i][31] = byte(i), byte(i*7)
+		}
+		root, err := MerkleizeProgressive(chunks)
+		require.NoError(t, err)
+		assert.Equal(t, naiveMerkleizeProgressive(chunks, 1), root, "%d chunks", n)
+	}
+	root, err := MerkleizeProgressive(nil)
+	require.NoError(t, err)
+	assert.Equal(t, [32]byte{}, root)
+}
@@ -0,0 +1,50 @@
+package flexssz
+
+import (
+	"errors"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// ErrorClass is the broad cause of a codec error, e.g. to pick a peer scoring penalty or to
+// label an error metric without parsing error messages
+type ErrorClass string
+
+const (
+	// ErrorClassNone is the class of a nil error
+	ErrorClassNone ErrorClass = ""
+	// ErrorClassMalformed is input that is not a valid encoding of the type, such as a bad
+	// offset, a list over its ssz-max, or a non-canonical encoding in strict mode
+	ErrorClassMalformed ErrorClass = "malformed"
+	// ErrorClassLimitExceeded is input that may be valid, but is larger or more deeply nested
+	// than the DecodeOptions limits allow
+	ErrorClassLimitExceeded ErrorClass = "limit_exceeded"
+	// ErrorClassTruncated is input that ends before the value does
+	ErrorClassTruncated ErrorClass = "truncated"
+	// ErrorClassInternal is an error that is not caused by the input, such as an invalid struct
+	// tag or a value that cannot be encoded
+	ErrorClassInternal ErrorClass = "internal"
+)
+
+// ClassifyError returns the class of an error returned by flexssz. Errors from decoding the
+// input, which are DecodeErrors, are Malformed unless they are truncated input or exceed a
+// limit; any other error is Internal.
+func ClassifyError(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case errors.Is(err, ErrLimitExceeded):
+		return ErrorClassLimitExceeded
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return ErrorClassTruncated
+	case errors.Is(err, ErrNonCanonical), errors.Is(err, ErrIndexOutOfBounds), errors.Is(err, ErrInvalidSeek),
+		errors.Is(err, snappy.ErrCorrupt), errors.Is(err, snappy.ErrTooLarge):
+		return ErrorClassMalformed
+	}
+	var de *DecodeError
+	if errors.As(err, &de) {
+		return ErrorClassMalformed
+	}
+	return ErrorClassInternal
+}
@@ -0,0 +1,42 @@
+package flexssz
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	encoded, err := Marshal(&strictTestStruct{A: 1, B: []byte{1, 2, 3}, C: []uint16{4, 5}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		{"nil", nil, ErrorClassNone},
+		{"truncated", Unmarshal(encoded[:10], &strictTestStruct{}), ErrorClassTruncated},
+		{"bad offset", Unmarshal([]byte{1, 0, 0, 0, 0, 0, 0, 0, 0xff, 0, 0, 0, 16, 0, 0, 0}, &strictTestStruct{}), ErrorClassMalformed},
+		{"list over max", Unmarshal(append(encoded[:16:16], make([]byte, 17)...), &strictTestStruct{}), ErrorClassMalformed},
+		{"non-canonical", UnmarshalStrict(append(encoded, 0), &strictTestStruct{}), ErrorClassMalformed},
+		{"total size", UnmarshalWithOptions(encoded, &strictTestStruct{}, DecodeOptions{MaxTotalSize: 8}), ErrorClassLimitExceeded},
+		{"corrupt snappy", UnmarshalSnappy([]byte{0xff}, &strictTestStruct{}), ErrorClassMalformed},
+		{"snappy length prefix", ReadSnappy(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}), &strictTestStruct{}), ErrorClassLimitExceeded},
+		{"not a pointer", Unmarshal(encoded, strictTestStruct{}), ErrorClassInternal},
+		{"unsupported type", Unmarshal(encoded, &struct{ A int }{}), ErrorClassInternal},
+		{"encode", func() error { _, err := Marshal(&struct{ A []byte }{}); return err }(), ErrorClassInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyError(tt.err))
+			if tt.err != nil {
+				// Wrapping keeps the class
+				assert.Equal(t, tt.expected, ClassifyError(fmt.Errorf("gossip: %w", tt.err)))
+			}
+		})
+	}
+}
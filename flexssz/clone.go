@@ -0,0 +1,242 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// Clone returns a deep copy of v, an SSZ-tagged value or a pointer to one, walking its type
+// information instead of encoding and decoding it. Only the fields that are encoded are copied,
+// so skipped fields are left zero in the copy. Pointers are allocated anew, nil pointers and nil
+// slices stay nil, and custom types are copied through MarshalSSZ and UnmarshalSSZ.
+func Clone[T any](v T) (T, error) {
+	var out T
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.ValueOf(&out).Elem()
+	if src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return out, nil
+		}
+		// Clone the dynamic value of interface types such as any
+		src = src.Elem()
+		concrete := reflect.New(src.Type()).Elem()
+		if err := cloneRoot(concrete, src); err != nil {
+			return out, err
+		}
+		dst.Set(concrete)
+		return out, nil
+	}
+	if err := cloneRoot(dst, src); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+func cloneRoot(dst, src reflect.Value) error {
+	t := src.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	info, err := GetTypeInfo(t, nil)
+	if err != nil {
+		return fmt.Errorf("error getting type info: %w", err)
+	}
+	if err := cloneValue(dst, src, info); err != nil {
+		if ce, ok := err.(*cloneError); ok && ce.path != "" {
+			return fmt.Errorf("%s: %w", ce.path, ce.err)
+		}
+		return err
+	}
+	return nil
+}
+
+// cloneError is an error of cloneValue, with the path of the failing value in the format of
+// DecodeError.Path. Paths are only built on failure, so that copying does not format them.
+type cloneError struct {
+	path string
+	err  error
+}
+
+func (e *cloneError) Error() string { return e.path + ": " + e.err.Error() }
+
+func (e *cloneError) Unwrap() error { return e.err }
+
+// wrapCloneError adds a path segment to err, which is a field name or an index like "[3]"
+func wrapCloneError(err error, segment string) error {
+	ce, ok := err.(*cloneError)
+	if !ok {
+		return &cloneError{path: segment, err: err}
+	}
+	switch {
+	case ce.path == "":
+		ce.path = segment
+	case strings.HasPrefix(ce.path, "["):
+		ce.path = segment + ce.path
+	default:
+		ce.path = segment + "." + ce.path
+	}
+	return ce
+}
+
+// cloneValue copies src into dst, a settable value of the same type
+func cloneValue(dst, src reflect.Value, info *TypeInfo) error {
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		elem := reflect.New(src.Type().Elem())
+		if err := cloneValue(elem.Elem(), src.Elem(), info); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+	if info.Wrapped != nil && src.Kind() == reflect.Struct {
+		return cloneValue(dst.Field(info.Wrapped.Index), src.Field(info.Wrapped.Index), info.Wrapped.Type)
+	}
+
+	if info.Custom {
+		return cloneCustom(dst, src, info)
+	}
+
+	switch info.Type {
+	case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeUint128, ssz.TypeUint256, ssz.TypeBoolean:
+		dst.Set(src)
+
+	case ssz.TypeBitVector, ssz.TypeBitList:
+		cloneFlat(dst, src)
+
+	case ssz.TypeUnion:
+		return cloneUnion(dst, src)
+
+	case ssz.TypeVector, ssz.TypeList:
+		if kv := info.kvlist; kv != nil {
+			return cloneMap(dst, src, kv)
+		}
+		if src.Kind() == reflect.String {
+			dst.Set(src)
+			return nil
+		}
+		if elem := info.ElementType; isBasicType(elem) && !elem.Custom && elem.Wrapped == nil && src.Type().Elem().Kind() != reflect.Ptr {
+			// Lists and vectors of basic values are copied as a whole
+			cloneFlat(dst, src)
+			return nil
+		}
+		if src.Kind() == reflect.Slice {
+			if src.IsNil() {
+				dst.SetZero()
+				return nil
+			}
+			dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		}
+		for i := 0; i < src.Len(); i++ {
+			if err := cloneValue(dst.Index(i), src.Index(i), info.ElementType); err != nil {
+				return wrapCloneError(err, indexSegment(i))
+			}
+		}
+
+	case ssz.TypeContainer:
+		for _, field := range info.Fields {
+			if err := cloneValue(field.value(dst), field.value(src), field.Type); err != nil {
+				return wrapCloneError(err, field.Name)
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported SSZ type for clone: %v", info.Type)
+	}
+	return nil
+}
+
+// cloneFlat copies a slice or array of values without references, keeping nil slices nil
+func cloneFlat(dst, src reflect.Value) {
+	if src.Kind() == reflect.Array {
+		dst.Set(src)
+		return
+	}
+	if src.IsNil() {
+		dst.SetZero()
+		return
+	}
+	out := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+	reflect.Copy(out, src)
+	dst.Set(out)
+}
+
+// cloneCustom copies a type that encodes itself through its SSZ encoding
+func cloneCustom(dst, src reflect.Value, info *TypeInfo) error {
+	data, err := marshalCustom(src, info.Tag)
+	if err != nil {
+		return err
+	}
+	u, ok := customMethods(dst, unmarshalerType)
+	if !ok {
+		return fmt.Errorf("%v does not implement flexssz.Unmarshaler", dst.Type())
+	}
+	return u.(Unmarshaler).UnmarshalSSZ(data)
+}
+
+// cloneUnion copies a Union or an interface field holding a registered union member
+func cloneUnion(dst, src reflect.Value) error {
+	var value reflect.Value
+	if src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			dst.SetZero()
+			return nil
+		}
+		if _, _, _, err := interfaceOption(src); err != nil {
+			return err
+		}
+		value = src.Elem()
+	} else {
+		u := src.Interface().(Union)
+		if u.Value == nil {
+			dst.Set(src)
+			return nil
+		}
+		value = reflect.ValueOf(u.Value)
+	}
+
+	t := value.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	info, err := GetTypeInfo(t, nil)
+	if err != nil {
+		return fmt.Errorf("error getting type info for union value: %w", err)
+	}
+	out := reflect.New(value.Type()).Elem()
+	if err := cloneValue(out, value, info); err != nil {
+		return err
+	}
+	if src.Kind() == reflect.Interface {
+		dst.Set(out)
+	} else {
+		dst.Set(reflect.ValueOf(Union{Selector: src.Interface().(Union).Selector, Value: out.Interface()}))
+	}
+	return nil
+}
+
+// cloneMap copies a kvlist map, copying its values like the Value field of its entries
+func cloneMap(dst, src reflect.Value, kv *kvList) error {
+	if src.IsNil() {
+		dst.SetZero()
+		return nil
+	}
+	valueInfo := kv.info.ElementType.Fields[1].Type
+	out := reflect.MakeMapWithSize(src.Type(), src.Len())
+	iter := src.MapRange()
+	for iter.Next() {
+		value := reflect.New(src.Type().Elem()).Elem()
+		if err := cloneValue(value, iter.Value(), valueInfo); err != nil {
+			return wrapCloneError(err, fmt.Sprintf("[%v]", iter.Key()))
+		}
+		out.SetMapIndex(iter.Key(), value)
+	}
+	dst.Set(out)
+	return nil
+}
@@ -0,0 +1,137 @@
+package flexssz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	a := &diffTestState{
+		Slot:        1,
+		GenesisTime: time.Unix(1606824023, 0),
+		Finalized:   &diffTestCheckpoint{Epoch: 2, Root: [32]byte{1}},
+		Checkpoints: []diffTestCheckpoint{{Epoch: 1}, {Epoch: 2}},
+		Balances:    []uint64{32, 31},
+		Graffiti:    []byte("graffiti"),
+		Bits:        []byte{0b101},
+		Labels:      map[string]uint64{"a": 1},
+		Cache:       "cached",
+	}
+	b, err := Clone(a)
+	require.NoError(t, err)
+	require.NotSame(t, a, b)
+
+	diffs, err := Diff(a, b)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+	assert.Empty(t, b.Cache, "skipped fields are not copied")
+
+	// Nothing is shared with the original
+	b.Finalized.Epoch = 9
+	b.Checkpoints[0].Root[0] = 9
+	b.Balances[0] = 9
+	b.Graffiti[0] = 9
+	b.Bits[0] = 9
+	b.Labels["a"] = 9
+	assert.Equal(t, uint64(2), a.Finalized.Epoch)
+	assert.Equal(t, byte(0), a.Checkpoints[0].Root[0])
+	assert.Equal(t, uint64(32), a.Balances[0])
+	assert.Equal(t, byte('g'), a.Graffiti[0])
+	assert.Equal(t, byte(0b101), a.Bits[0])
+	assert.Equal(t, uint64(1), a.Labels["a"])
+
+	t.Run("nil", func(t *testing.T) {
+		b, err := Clone(&diffTestState{})
+		require.NoError(t, err)
+		assert.Nil(t, b.Finalized)
+		assert.Nil(t, b.Balances)
+		assert.Nil(t, b.Labels)
+
+		var nilState *diffTestState
+		b, err = Clone(nilState)
+		require.NoError(t, err)
+		assert.Nil(t, b)
+	})
+
+	t.Run("value", func(t *testing.T) {
+		b, err := Clone(*a)
+		require.NoError(t, err)
+		assert.Equal(t, a.Balances, b.Balances)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		v := &customTestValidator{
+			Pubkey: customTestPubkey{key: [48]byte{1}},
+			Keys:   []customTestPubkey{{key: [48]byte{2}}},
+			Backup: &customTestPubkey{key: [48]byte{3}},
+			Blob:   &customTestBlob{data: []byte{4}},
+		}
+		c, err := Clone(v)
+		require.NoError(t, err)
+		assert.Equal(t, v, c)
+		c.Blob.data[0] = 9
+		assert.Equal(t, byte(4), v.Blob.data[0])
+	})
+
+	t.Run("union", func(t *testing.T) {
+		v := &testBlocks{Payloads: []testPayload{nil, &testPayloadV2{Number: 1, Transactions: [][]byte{{1}}}, testBlobCount(3)}}
+		c, err := Clone(v)
+		require.NoError(t, err)
+		assert.Equal(t, v, c)
+		c.Payloads[1].(*testPayloadV2).Transactions[0][0] = 9
+		assert.Equal(t, byte(1), v.Payloads[1].(*testPayloadV2).Transactions[0][0])
+	})
+
+	t.Run("any", func(t *testing.T) {
+		var v any = &diffTestCheckpoint{Epoch: 3}
+		c, err := Clone(v)
+		require.NoError(t, err)
+		assert.Equal(t, v, c)
+		assert.NotSame(t, v, c)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Clone(&struct{ C chan int }{})
+		assert.Error(t, err)
+
+		_, err = Clone(&testBlock{Payload: &testPayloadV2{Transactions: [][]byte{{1}}}})
+		require.NoError(t, err)
+		_, err = Clone(&struct {
+			Blocks []testBlock `ssz-max:"4"`
+		}{Blocks: []testBlock{{}, {Payload: (*testPayloadV1)(nil)}}})
+		assert.ErrorContains(t, err, "Blocks[1].Payload: ")
+	})
+}
+
+func BenchmarkClone(b *testing.B) {
+	state := &diffTestState{
+		Slot:        1,
+		Finalized:   &diffTestCheckpoint{},
+		Checkpoints: make([]diffTestCheckpoint, 8),
+		Balances:    make([]uint64, 8),
+		Graffiti:    make([]byte, 32),
+		Bits:        []byte{1},
+	}
+	b.Run("clone", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := Clone(state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("round trip", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			data, err := Marshal(state)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var out diffTestState
+			if err := Unmarshal(data, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
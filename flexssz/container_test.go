@@ -1,6 +1,7 @@
 package flexssz
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -115,4 +116,30 @@ func TestContainerTag(t *testing.T) {
 		// The encoding should handle the variable container properly
 		// with offsets for the variable field
 	})
-}
\ No newline at end of file
+}
+func TestEmptyContainer(t *testing.T) {
+	type Empty struct{}
+	type Hidden struct {
+		hidden  uint64
+		Skipped uint64 `ssz:"-"`
+	}
+	type Outer struct {
+		A     uint64
+		Inner Empty
+	}
+	type OuterList struct {
+		Items []Hidden `ssz-max:"4"`
+	}
+
+	for _, v := range []any{&Empty{}, &Hidden{}, &Outer{}, &OuterList{}} {
+		_, err := GetTypeInfo(reflect.TypeOf(v).Elem(), nil)
+		require.ErrorIs(t, err, ErrEmptyContainer, "%T", v)
+
+		_, err = Marshal(v)
+		assert.ErrorIs(t, err, ErrEmptyContainer, "%T", v)
+		_, err = HashTreeRoot(v)
+		assert.ErrorIs(t, err, ErrEmptyContainer, "%T", v)
+		assert.ErrorIs(t, Unmarshal(make([]byte, 8), v), ErrEmptyContainer, "%T", v)
+		assert.Equal(t, ErrorClassInternal, ClassifyError(err))
+	}
+}
@@ -0,0 +1,143 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Marshaler is implemented by types that write their own SSZ encoding, such as BLS keys or types
+// generated by other SSZ libraries. A type implementing Marshaler or Unmarshaler is opaque to
+// flexssz: its fields are not inspected, and it is fixed-size only when its field is tagged with
+// ssz-size, e.g. `ssz-size:"48"` for a pubkey, or `ssz-size:"?,48"` for a list of pubkeys.
+// Without ssz-size it is variable-size. MarshalSSZ must not call Marshal on its receiver, which
+// would call MarshalSSZ again.
+type Marshaler interface {
+	MarshalSSZ() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that decode their own SSZ encoding, see Marshaler. The
+// buffer holds exactly the bytes of the value, and must not be kept after UnmarshalSSZ returns.
+type Unmarshaler interface {
+	UnmarshalSSZ(buf []byte) error
+}
+
+// Hasher is implemented by types that compute their own hash tree root. It is used for any
+// type implementing it, including types encoded by reflection. HashTreeRoot does not use the
+// Hasher of the value passed to it, so a HashTreeRoot method can call flexssz.HashTreeRoot on
+// its receiver, e.g. to cache the root.
+type Hasher interface {
+	HashTreeRoot() ([32]byte, error)
+}
+
+// isCustomType reports whether t, or what it points to, encodes itself with Marshaler or
+// Unmarshaler. uint256.Int also implements these, but flexssz keeps encoding it itself so that
+// the uint128 tag is honored.
+func isCustomType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == uint256Type || isUnionInterface(t) {
+		return false
+	}
+	if custom, ok := customTypes.Load(t); ok {
+		return custom.(bool)
+	}
+	pt := reflect.PointerTo(t)
+	custom := t.Implements(marshalerType) || pt.Implements(marshalerType) ||
+		t.Implements(unmarshalerType) || pt.Implements(unmarshalerType)
+	customTypes.Store(t, custom)
+	return custom
+}
+
+// customTypes caches isCustomType, since it is checked for every encoded value
+var customTypes sync.Map
+
+// customMethods returns v as iface, using the address of v for pointer receivers. Pointers are
+// followed, and ok is false if v is a nil pointer or does not implement iface.
+func customMethods(v reflect.Value, iface reflect.Type) (any, bool) {
+	for {
+		if v.Type().Implements(iface) {
+			if v.Kind() == reflect.Ptr && v.IsNil() {
+				return nil, false
+			}
+			return v.Interface(), true
+		}
+		if v.Kind() != reflect.Ptr {
+			break
+		}
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if !reflect.PointerTo(v.Type()).Implements(iface) {
+		return nil, false
+	}
+	if !v.CanAddr() {
+		// Pointer methods of an unaddressable value are called on a copy
+		c := reflect.New(v.Type())
+		c.Elem().Set(v)
+		v = c.Elem()
+	}
+	return v.Addr().Interface(), true
+}
+
+// marshalCustom encodes v with its MarshalSSZ method, checking the size of fixed-size values
+func marshalCustom(v reflect.Value, tag *sszTag) ([]byte, error) {
+	m, ok := customMethods(v, marshalerType)
+	if !ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, fmt.Errorf("cannot encode nil pointer")
+		}
+		return nil, fmt.Errorf("%v does not implement flexssz.Marshaler", v.Type())
+	}
+	data, err := m.(Marshaler).MarshalSSZ()
+	if err != nil {
+		return nil, err
+	}
+	if tag != nil && len(tag.Size) > 0 && tag.Size[0] >= 0 && len(data) != tag.Size[0] {
+		return nil, fmt.Errorf("%v encoded to %d bytes, expected %d", v.Type(), len(data), tag.Size[0])
+	}
+	return data, nil
+}
+
+// decodeCustom decodes a value with its UnmarshalSSZ method. Fixed-size values read their
+// FixedSize, variable-size values read the rest of the decoder.
+func decodeCustom(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+	u, ok := customMethods(v, unmarshalerType)
+	if !ok {
+		return fmt.Errorf("%v does not implement flexssz.Unmarshaler", v.Type())
+	}
+
+	var data []byte
+	var err error
+	if fieldInfo.Type.IsVariable {
+		data, err = d.ReadAllMax(0)
+	} else {
+		data, err = d.ReadN(fieldInfo.Type.FixedSize)
+	}
+	if err != nil {
+		return err
+	}
+	return u.(Unmarshaler).UnmarshalSSZ(data)
+}
+
+// customHasher returns the Hasher of v, if it has one
+func customHasher(v reflect.Value) (Hasher, bool) {
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == uint256Type || isUnionInterface(t) {
+		return nil, false
+	}
+	h, ok := customMethods(v, hasherType)
+	if !ok {
+		return nil, false
+	}
+	return h.(Hasher), true
+}
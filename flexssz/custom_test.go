@@ -0,0 +1,229 @@
+package flexssz
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// customTestPubkey is an opaque key, like a BLS pubkey wrapping a library type
+type customTestPubkey struct {
+	key [48]byte
+}
+
+func (p customTestPubkey) MarshalSSZ() ([]byte, error) {
+	return p.key[:], nil
+}
+
+func (p *customTestPubkey) UnmarshalSSZ(buf []byte) error {
+	if len(buf) != 48 {
+		return fmt.Errorf("pubkey must be 48 bytes, got %d", len(buf))
+	}
+	copy(p.key[:], buf)
+	return nil
+}
+
+func (p customTestPubkey) HashTreeRoot() ([32]byte, error) {
+	return HashTreeRoot(p.key)
+}
+
+// customTestBlob is variable-size, and hashed like a byte list of at most 64 bytes
+type customTestBlob struct {
+	data []byte
+}
+
+func (b *customTestBlob) MarshalSSZ() ([]byte, error) {
+	return b.data, nil
+}
+
+func (b *customTestBlob) UnmarshalSSZ(buf []byte) error {
+	b.data = append([]byte(nil), buf...)
+	return nil
+}
+
+func (b *customTestBlob) HashTreeRoot() ([32]byte, error) {
+	return HashTreeRoot(&struct {
+		Data []byte `ssz-max:"64"`
+	}{b.data})
+}
+
+type customTestValidator struct {
+	Pubkey  customTestPubkey `ssz-size:"48"`
+	Balance uint64
+	Keys    []customTestPubkey `ssz-size:"?,48" ssz-max:"8"`
+	Backup  *customTestPubkey  `ssz-size:"48"`
+	Blob    *customTestBlob
+}
+
+type customTestValidatorRaw struct {
+	Pubkey  [48]byte
+	Balance uint64
+	Keys    [][48]byte `ssz-max:"8"`
+	Backup  [48]byte
+	Blob    struct {
+		Data []byte `ssz-max:"64"`
+	}
+}
+
+func TestCustom(t *testing.T) {
+	v := &customTestValidator{
+		Pubkey:  customTestPubkey{key: [48]byte{1}},
+		Balance: 32,
+		Keys:    []customTestPubkey{{key: [48]byte{2}}, {key: [48]byte{3}}},
+		Backup:  &customTestPubkey{key: [48]byte{4}},
+		Blob:    &customTestBlob{data: []byte{5, 6, 7}},
+	}
+	raw := &customTestValidatorRaw{
+		Pubkey:  [48]byte{1},
+		Balance: 32,
+		Keys:    [][48]byte{{2}, {3}},
+		Backup:  [48]byte{4},
+	}
+	raw.Blob.Data = []byte{5, 6, 7}
+
+	// The blob encodes like a byte list field, since its MarshalSSZ writes the bytes of the list
+	encoded, err := Marshal(v)
+	require.NoError(t, err)
+	rawEncoded, err := Marshal(&struct {
+		Pubkey  [48]byte
+		Balance uint64
+		Keys    [][48]byte `ssz-max:"8"`
+		Backup  [48]byte
+		Blob    []byte `ssz-max:"64"`
+	}{raw.Pubkey, raw.Balance, raw.Keys, raw.Backup, raw.Blob.Data})
+	require.NoError(t, err)
+	assert.Equal(t, rawEncoded, encoded)
+
+	var decoded customTestValidator
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	assert.Equal(t, v, &decoded)
+
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	rawRoot, err := HashTreeRoot(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawRoot, root)
+}
+
+func TestCustom_Root(t *testing.T) {
+	pk := customTestPubkey{key: [48]byte{9}}
+	encoded, err := Marshal(pk)
+	require.NoError(t, err)
+	assert.Equal(t, pk.key[:], encoded)
+
+	var decoded customTestPubkey
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	assert.Equal(t, pk, decoded)
+
+	// Without ssz-size the type is variable-size, so any length reaches UnmarshalSSZ
+	err = Unmarshal(encoded[:47], &decoded)
+	assert.ErrorContains(t, err, "pubkey must be 48 bytes, got 47")
+
+	root, err := HashTreeRoot(&pk)
+	require.NoError(t, err)
+	expected, err := pk.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+}
+
+func TestCustom_JSON(t *testing.T) {
+	v := &customTestValidator{
+		Pubkey: customTestPubkey{key: [48]byte{1}},
+		Keys:   []customTestPubkey{},
+		Backup: &customTestPubkey{},
+		Blob:   &customTestBlob{data: []byte{0xab}},
+	}
+	data, err := MarshalJSON(v)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"pubkey":"0x01000000`)
+	assert.Contains(t, string(data), `"blob":"0xab"`)
+
+	var decoded customTestValidator
+	require.NoError(t, UnmarshalJSON(data, &decoded))
+	assert.Equal(t, v, &decoded)
+
+	err = UnmarshalJSON([]byte(`{"pubkey":"0x01"}`), &decoded)
+	assert.ErrorContains(t, err, "expected 48 bytes, got 1")
+}
+
+type customTestFailing struct{}
+
+func (customTestFailing) MarshalSSZ() ([]byte, error) {
+	return nil, errors.New("marshal failed")
+}
+
+type customTestShort struct{}
+
+func (customTestShort) MarshalSSZ() ([]byte, error) {
+	return []byte{1, 2}, nil
+}
+
+func TestCustom_Errors(t *testing.T) {
+	_, err := Marshal(&struct{ A customTestFailing }{})
+	assert.ErrorContains(t, err, "marshal failed")
+
+	_, err = Marshal(&struct {
+		A customTestShort `ssz-size:"4"`
+	}{})
+	assert.ErrorContains(t, err, "encoded to 2 bytes, expected 4")
+
+	_, err = Marshal(&struct {
+		A *customTestPubkey `ssz-size:"48"`
+	}{})
+	assert.ErrorContains(t, err, "cannot encode nil pointer")
+
+	// Marshal-only types cannot be decoded or hashed
+	err = Unmarshal([]byte{1, 2, 3, 4}, &struct {
+		A customTestShort `ssz-size:"4"`
+	}{})
+	assert.ErrorContains(t, err, "does not implement flexssz.Unmarshaler")
+
+	_, err = HashTreeRoot(customTestShort{})
+	assert.ErrorContains(t, err, "must implement flexssz.Hasher")
+}
+
+// customTestCached has a Hasher that calls HashTreeRoot on itself
+type customTestCached struct {
+	A    uint64
+	B    [32]byte
+	root *[32]byte `ssz:"-"`
+}
+
+func (c *customTestCached) HashTreeRoot() ([32]byte, error) {
+	if c.root == nil {
+		root, err := HashTreeRoot(c)
+		if err != nil {
+			return root, err
+		}
+		c.root = &root
+	}
+	return *c.root, nil
+}
+
+func TestCustom_Hasher(t *testing.T) {
+	c := &customTestCached{A: 1, B: [32]byte{2}}
+	root, err := c.HashTreeRoot()
+	require.NoError(t, err)
+	expected, err := HashTreeRoot(&struct {
+		A uint64
+		B [32]byte
+	}{1, [32]byte{2}})
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+
+	// Nested values use the Hasher, so a stale cached root is returned as is
+	c.A = 5
+	nested, err := HashTreeRoot(&struct{ C *customTestCached }{c})
+	require.NoError(t, err)
+	expectedNested, err := HashTreeRoot(&struct{ C [32]byte }{root})
+	require.NoError(t, err)
+	assert.Equal(t, expectedNested, nested)
+
+	// Encoding still uses reflection, since the type has no Marshaler
+	encoded, err := Marshal(c)
+	require.NoError(t, err)
+	assert.Len(t, encoded, 40)
+}
@@ -0,0 +1,38 @@
+package flexssz
+
+// DecodeOptions configures UnmarshalWithOptions
+type DecodeOptions struct {
+	// Strict only accepts the canonical encoding of a value. Offsets must start right after the
+	// fixed part and never decrease, list offsets must be consistent with the element count,
+	// booleans must be 0 or 1, and no bytes may be left over in a field or at the end of the input.
+	// Violations return an error wrapping ErrNonCanonical.
+	Strict bool
+
+	// ZeroCopy makes decoded byte lists, byte vectors and bitfields held in slices alias the
+	// input instead of copying it, so a large value such as a beacon state that is only read from
+	// can be decoded with few allocations. The input must then not be modified or reused while
+	// the value is in use. Byte arrays and strings are always copied. Aliased bytes do not count
+	// against MaxAllocatedBytes.
+	ZeroCopy bool
+
+	// The limits below bound the work done for untrusted input, on top of the limits from struct
+	// tags. Zero means no limit. Exceeding one returns an error wrapping ErrLimitExceeded.
+
+	// MaxTotalSize is the largest input, in bytes, that is decoded at all
+	MaxTotalSize int
+	// MaxListElements is the largest number of elements decoded into any one list
+	MaxListElements int
+	// MaxRecursionDepth is how deeply containers and lists may be nested, the root being depth 1
+	MaxRecursionDepth int
+	// MaxAllocatedBytes is the total size of the slices and strings allocated for the value. Each
+	// field can respect its ssz-max while their combination is still too large to hold in memory.
+	MaxAllocatedBytes int
+
+	// LimitOverrides replaces the ssz-max limits of lists and bitlists for this call, so a type can
+	// be decoded under the limits of another network or preset. Keys are field paths from the
+	// root, field names joined by dots without list indices, e.g. "Body.Attestations", or the
+	// spec constant named by an ssz-max-name tag, e.g. "MAX_ATTESTATIONS". Paths take precedence
+	// over names. Overrides apply to the outer limit of a field, and are checked instead of its
+	// ssz-max, so they can raise it as well as lower it.
+	LimitOverrides map[string]uint64
+}
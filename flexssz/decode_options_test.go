@@ -0,0 +1,357 @@
+package flexssz
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictTestStruct struct {
+	A uint64
+	B []byte   `ssz-max:"16"`
+	C []uint16 `ssz-max:"4"`
+}
+
+// assertStrictOnly checks that data decodes leniently but is rejected by strict decoding
+func assertStrictOnly(t *testing.T, data []byte, v any) {
+	t.Helper()
+	require.NoError(t, Unmarshal(data, v))
+	err := UnmarshalStrict(data, v)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNonCanonical)
+	var decodeErr *DecodeError
+	assert.ErrorAs(t, err, &decodeErr)
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	t.Run("canonical encoding", func(t *testing.T) {
+		encoded, err := Marshal(&strictTestStruct{A: 1, B: []byte{1, 2, 3}, C: []uint16{4, 5}})
+		require.NoError(t, err)
+
+		var decoded strictTestStruct
+		require.NoError(t, UnmarshalStrict(encoded, &decoded))
+		assert.Equal(t, []byte{1, 2, 3}, decoded.B)
+		assert.Equal(t, []uint16{4, 5}, decoded.C)
+	})
+
+	t.Run("gap after fixed part", func(t *testing.T) {
+		// A, then offsets 17 and 19 with a junk byte at 16 before B
+		data := []byte{1, 0, 0, 0, 0, 0, 0, 0, 17, 0, 0, 0, 19, 0, 0, 0, 0xff, 1, 2, 4, 0}
+		assertStrictOnly(t, data, &strictTestStruct{})
+	})
+
+	t.Run("trailing bytes", func(t *testing.T) {
+		type Fixed struct {
+			A uint64
+		}
+		assertStrictOnly(t, []byte{1, 0, 0, 0, 0, 0, 0, 0, 0xff}, &Fixed{})
+	})
+
+	t.Run("non-boolean byte", func(t *testing.T) {
+		type Flag struct {
+			B bool
+		}
+		assertStrictOnly(t, []byte{2}, &Flag{})
+
+		type Flags struct {
+			A    uint8
+			Flag Flag
+		}
+		err := UnmarshalStrict([]byte{1, 0x80}, &Flags{})
+		assert.ErrorContains(t, err, "boolean byte 0x80")
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, "Flag.B", decodeErr.Path)
+		assert.Equal(t, 1, decodeErr.Offset)
+	})
+
+	t.Run("list offsets", func(t *testing.T) {
+		type Lists struct {
+			L [][]byte `ssz-max:"4,8"`
+		}
+		// First list offset 9 is not a multiple of 4, which would read the offsets misaligned
+		data := []byte{4, 0, 0, 0, 9, 0, 0, 0, 10, 0, 0, 0, 1, 2, 3}
+		assert.ErrorContains(t, Unmarshal(data, &Lists{}), "not a multiple of 4")
+		assert.ErrorContains(t, UnmarshalStrict(data, &Lists{}), "not a multiple of 4")
+
+		// A first offset of 0 claims an empty list, but there is data
+		data = []byte{4, 0, 0, 0, 0, 0, 0, 0, 1}
+		assertStrictOnly(t, data, &Lists{})
+
+		// Canonical list of lists
+		encoded, err := Marshal(&Lists{L: [][]byte{{1}, {}, {2, 3}}})
+		require.NoError(t, err)
+		var decoded Lists
+		require.NoError(t, UnmarshalStrict(encoded, &decoded))
+		assert.Equal(t, [][]byte{{1}, {}, {2, 3}}, decoded.L)
+	})
+}
+
+func TestUnmarshalWithOptions_Limits(t *testing.T) {
+	type Inner struct {
+		A uint32
+		B []byte `ssz-max:"8"`
+	}
+	type Outer struct {
+		Values []uint64 `ssz-max:"16"`
+		Items  []Inner  `ssz-max:"16"`
+	}
+	encoded, err := Marshal(&Outer{
+		Values: []uint64{1, 2, 3, 4, 5},
+		Items:  []Inner{{A: 1}, {A: 2, B: []byte{1}}},
+	})
+	require.NoError(t, err)
+
+	assertLimit := func(t *testing.T, opts DecodeOptions) {
+		t.Helper()
+		err := UnmarshalWithOptions(encoded, &Outer{}, opts)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		var decodeErr *DecodeError
+		assert.ErrorAs(t, err, &decodeErr)
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		var decoded Outer
+		require.NoError(t, UnmarshalWithOptions(encoded, &decoded, DecodeOptions{
+			MaxTotalSize:      len(encoded),
+			MaxListElements:   5,
+			MaxRecursionDepth: 3,
+		}))
+		assert.Len(t, decoded.Values, 5)
+		assert.Len(t, decoded.Items, 2)
+	})
+
+	t.Run("total size", func(t *testing.T) {
+		assertLimit(t, DecodeOptions{MaxTotalSize: len(encoded) - 1})
+	})
+
+	t.Run("list elements", func(t *testing.T) {
+		assertLimit(t, DecodeOptions{MaxListElements: 4})
+	})
+
+	t.Run("recursion depth", func(t *testing.T) {
+		// Outer, the Items list and each Inner are one level each
+		assertLimit(t, DecodeOptions{MaxRecursionDepth: 2})
+	})
+
+	t.Run("offset claiming too many elements", func(t *testing.T) {
+		type Lists struct {
+			L [][]byte `ssz-max:"?,8"`
+		}
+		// The first list offset claims 2^30 elements in 8 bytes of data
+		data := []byte{4, 0, 0, 0, 0xfc, 0xff, 0xff, 0xff, 0, 0, 0, 0}
+		err := Unmarshal(data, &Lists{})
+		assert.ErrorContains(t, err, "past the end of the list")
+	})
+}
+
+func TestUnmarshalWithOptions_AllocatedBytes(t *testing.T) {
+	type Budget struct {
+		A []byte   `ssz-max:"64"`
+		B []byte   `ssz-max:"64"`
+		C []uint64 `ssz-max:"16"`
+	}
+	value := &Budget{A: make([]byte, 40), B: make([]byte, 40), C: []uint64{1, 2}}
+	encoded, err := Marshal(value)
+	require.NoError(t, err)
+
+	t.Run("accounting", func(t *testing.T) {
+		var decoded Budget
+		d := NewDecoder(encoded)
+		require.NoError(t, decodeStructFromDecoder(d, reflect.ValueOf(&decoded).Elem()))
+		// Every field is within its own limit, the total is what they allocate together
+		assert.Equal(t, 40+40+2*8, d.Allocated())
+	})
+
+	t.Run("within budget", func(t *testing.T) {
+		var decoded Budget
+		require.NoError(t, UnmarshalWithOptions(encoded, &decoded, DecodeOptions{MaxAllocatedBytes: 96}))
+		assert.Equal(t, value, &decoded)
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		err := UnmarshalWithOptions(encoded, &Budget{}, DecodeOptions{MaxAllocatedBytes: 64})
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		// A fits in the budget, B does not
+		assert.Equal(t, "B", decodeErr.Path)
+	})
+}
+
+func TestUnmarshal_ByteListLimitsBeforeAllocation(t *testing.T) {
+	type Limited struct {
+		Name  string
+		Data  []byte `ssz-max:"4"`
+		Flags []byte `ssz:"bitlist" ssz-max:"16"`
+	}
+	encode := func(name, data, flags []byte) []byte {
+		out := []byte{12, 0, 0, 0}
+		out = binary.LittleEndian.AppendUint32(out, uint32(12+len(name)))
+		out = binary.LittleEndian.AppendUint32(out, uint32(12+len(name)+len(data)))
+		out = append(out, name...)
+		out = append(out, data...)
+		return append(out, flags...)
+	}
+
+	tests := map[string]struct {
+		data      []byte
+		path      string
+		allocated int
+	}{
+		"bytes":   {encode([]byte("ssz"), []byte{1, 2, 3, 4, 5}, []byte{0x01}), "Data", 3},
+		"bitlist": {encode([]byte("ssz"), []byte{1}, []byte{0xff, 0xff, 0xff, 0x01}), "Flags", 3 + 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Unmarshal(tt.data, &Limited{})
+			assert.ErrorContains(t, err, "exceeds limit")
+			var decodeErr *DecodeError
+			require.ErrorAs(t, err, &decodeErr)
+			assert.Equal(t, tt.path, decodeErr.Path)
+
+			// Only the fields before the one over its limit are allocated
+			d := NewDecoder(tt.data)
+			require.Error(t, decodeStructFromDecoder(d, reflect.ValueOf(&Limited{}).Elem()))
+			assert.Equal(t, tt.allocated, d.Allocated())
+		})
+	}
+
+	var decoded Limited
+	require.NoError(t, Unmarshal(encode([]byte("ssz"), []byte{1, 2}, []byte{0x03}), &decoded))
+	assert.Equal(t, Limited{Name: "ssz", Data: []byte{1, 2}, Flags: []byte{0x03}}, decoded)
+}
+
+type zeroCopyTestStruct struct {
+	Root   [32]byte
+	Vector []byte   `ssz-size:"8"`
+	Bits   []byte   `ssz:"bitvector" ssz-size:"12"`
+	List   []byte   `ssz-max:"64"`
+	Agg    []byte   `ssz:"bitlist" ssz-max:"64"`
+	Txs    [][]byte `ssz-max:"4,64"`
+	Name   string
+}
+
+func TestUnmarshalWithOptions_ZeroCopy(t *testing.T) {
+	value := &zeroCopyTestStruct{
+		Root:   [32]byte{1},
+		Vector: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Bits:   []byte{0xff, 0x0f},
+		List:   []byte{9, 10, 11},
+		Agg:    []byte{0x0d},
+		Txs:    [][]byte{{12}, {13, 14}},
+		Name:   "ssz",
+	}
+	encoded, err := Marshal(value)
+	require.NoError(t, err)
+
+	var decoded zeroCopyTestStruct
+	d := NewDecoderWithOptions(encoded, DecodeOptions{ZeroCopy: true})
+	require.NoError(t, decodeStructFromDecoder(d, reflect.ValueOf(&decoded).Elem()))
+	assert.Equal(t, value, &decoded)
+	// Only the outer slice of Txs with its offsets, and the string are allocated
+	assert.Equal(t, 2*24+2*4+3, d.Allocated())
+
+	// Slices point into the input, arrays and strings are copies
+	inInput := func(bs []byte) bool {
+		start := uintptr(unsafe.Pointer(&encoded[0]))
+		p := uintptr(unsafe.Pointer(&bs[0]))
+		return p >= start && p < start+uintptr(len(encoded))
+	}
+	for _, bs := range [][]byte{decoded.Vector, decoded.Bits, decoded.List, decoded.Agg, decoded.Txs[0], decoded.Txs[1]} {
+		assert.True(t, inInput(bs))
+		assert.Equal(t, len(bs), cap(bs))
+	}
+	assert.False(t, inInput(decoded.Root[:]))
+
+	// Appending reallocates instead of writing over the next field
+	list := append(decoded.List, 0xee)
+	assert.False(t, inInput(list))
+	assert.Equal(t, value.Agg, decoded.Agg)
+
+	// Without ZeroCopy nothing points into the input
+	var copied zeroCopyTestStruct
+	require.NoError(t, Unmarshal(encoded, &copied))
+	assert.Equal(t, value, &copied)
+	for _, bs := range [][]byte{copied.Vector, copied.Bits, copied.List, copied.Agg, copied.Txs[0]} {
+		assert.False(t, inInput(bs))
+	}
+}
+
+type limitOverrideAttestation struct {
+	Bits []byte   `ssz:"bitlist" ssz-max:"2048" ssz-max-name:"MAX_VALIDATORS_PER_COMMITTEE"`
+	Data []uint64 `ssz-max:"4"`
+}
+
+type limitOverrideBody struct {
+	Attestations []limitOverrideAttestation `ssz-max:"2" ssz-max-name:"MAX_ATTESTATIONS"`
+	Roots        [][]byte                   `ssz-max:"2,8"`
+}
+
+type limitOverrideBlock struct {
+	Slot uint64
+	Body limitOverrideBody
+}
+
+func TestUnmarshalWithOptions_LimitOverrides(t *testing.T) {
+	block := limitOverrideBlock{
+		Slot: 1,
+		Body: limitOverrideBody{
+			Attestations: []limitOverrideAttestation{
+				{Bits: []byte{0xff, 0x01}, Data: []uint64{1, 2, 3}},
+				{Bits: []byte{0x03}, Data: []uint64{}},
+				{Bits: []byte{0x01}, Data: []uint64{1}},
+			},
+			Roots: [][]byte{{1, 2, 3}, {4}},
+		},
+	}
+	enc, err := Marshal(&block)
+	require.Error(t, err, "three attestations exceed the tag limit")
+	block.Body.Attestations = block.Body.Attestations[:2]
+	enc, err = Marshal(&block)
+	require.NoError(t, err)
+
+	decode := func(overrides map[string]uint64) error {
+		var out limitOverrideBlock
+		err := UnmarshalWithOptions(enc, &out, DecodeOptions{LimitOverrides: overrides})
+		if err == nil {
+			assert.Equal(t, block, out)
+		}
+		return err
+	}
+	require.NoError(t, decode(nil))
+
+	// By field path
+	err = decode(map[string]uint64{"Body.Attestations": 1})
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "Body.Attestations", decodeErr.Path)
+	assert.ErrorContains(t, decode(map[string]uint64{"Body.Attestations.Data": 2}), "exceeds limit 2")
+	require.NoError(t, decode(map[string]uint64{"Body.Attestations.Data": 3, "Body.Attestations": 2}))
+
+	// By spec constant, with paths taking precedence
+	assert.ErrorContains(t, decode(map[string]uint64{"MAX_VALIDATORS_PER_COMMITTEE": 4}), "error decoding bitlist")
+	require.NoError(t, decode(map[string]uint64{"MAX_ATTESTATIONS": 1, "Body.Attestations": 2}))
+	assert.Error(t, decode(map[string]uint64{"MAX_ATTESTATIONS": 1}))
+
+	// Only the outer limit of a list of lists is replaced
+	assert.Error(t, decode(map[string]uint64{"Body.Roots": 1}))
+	require.NoError(t, decode(map[string]uint64{"Body.Roots": 3}))
+
+	// Overrides can raise a limit above the tag
+	var raised limitOverrideBody
+	wide := limitOverrideBody{Attestations: make([]limitOverrideAttestation, 3)}
+	for i := range wide.Attestations {
+		wide.Attestations[i].Bits = []byte{0x01}
+	}
+	enc, err = MarshalList(wide.Attestations, 3)
+	require.NoError(t, err)
+	enc = append([]byte{8, 0, 0, 0, 8 + byte(len(enc)), 0, 0, 0}, enc...)
+	require.Error(t, Unmarshal(enc, &raised))
+	require.NoError(t, UnmarshalWithOptions(enc, &raised, DecodeOptions{LimitOverrides: map[string]uint64{"MAX_ATTESTATIONS": 3}}))
+	assert.Len(t, raised.Attestations, 3)
+}
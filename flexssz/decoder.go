@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 
 	"github.com/holiman/uint256"
@@ -15,14 +16,172 @@ var order = binary.LittleEndian
 type Decoder struct {
 	xs  []byte
 	cur int
+	// base is the position of xs in the original input, so offsets can be reported for sub-decoders
+	base int
+	// depth is the number of containers and lists being decoded, for MaxRecursionDepth
+	depth int
+	// allocated is shared with sub-decoders, so it counts the bytes allocated for the whole value
+	allocated *int
+	opts      DecodeOptions
+	// path and fieldTag are the field being decoded, tracked for LimitOverrides only
+	path     string
+	fieldTag *sszTag
 }
 
 func NewDecoder(xs []byte) *Decoder {
 	return &Decoder{
-		xs: xs,
+		xs:        xs,
+		allocated: new(int),
 	}
 }
 
+// NewDecoderWithOptions creates a decoder that applies opts to everything it decodes
+func NewDecoderWithOptions(xs []byte, opts DecodeOptions) *Decoder {
+	return &Decoder{
+		xs:        xs,
+		allocated: new(int),
+		opts:      opts,
+	}
+}
+
+// sub returns a decoder over xs[start:end] that reports offsets relative to the original input
+func (d *Decoder) sub(start, end int) *Decoder {
+	return &Decoder{
+		xs:        d.xs[start:end],
+		base:      d.base + start,
+		depth:     d.depth,
+		allocated: d.allocated,
+		opts:      d.opts,
+		path:      d.path,
+		fieldTag:  d.fieldTag,
+	}
+}
+
+// Allocated returns the number of bytes allocated for slices and strings so far, by this decoder
+// and the decoders of the fields and elements it contains
+func (d *Decoder) Allocated() int {
+	if d.allocated == nil {
+		return 0
+	}
+	return *d.allocated
+}
+
+// allocate charges n bytes against MaxAllocatedBytes. It is called before the allocation, so an
+// input cannot make the decoder allocate more than the budget.
+func (d *Decoder) allocate(n int) error {
+	if d.allocated == nil {
+		d.allocated = new(int)
+	}
+	*d.allocated += n
+	if max := d.opts.MaxAllocatedBytes; max > 0 && *d.allocated > max {
+		return fmt.Errorf("%w: allocating %d bytes brings the total to %d, more than %d", ErrLimitExceeded, n, *d.allocated, max)
+	}
+	return nil
+}
+
+// allocateBytes charges n bytes that will be read with readBytes, which only allocates them
+// without ZeroCopy
+func (d *Decoder) allocateBytes(n int) error {
+	if d.opts.ZeroCopy {
+		return nil
+	}
+	return d.allocate(n)
+}
+
+// readBytes reads the next n bytes, which alias the input with ZeroCopy and are a copy otherwise
+func (d *Decoder) readBytes(n int) ([]byte, error) {
+	if !d.opts.ZeroCopy {
+		if n == 0 {
+			return []byte{}, nil
+		}
+		return d.ReadN(n)
+	}
+	if len(d.xs)-d.cur < n {
+		return nil, fmt.Errorf("ssz: %w", io.ErrUnexpectedEOF)
+	}
+	// Capping the capacity keeps appends to the slice from writing over the rest of the input
+	bs := d.xs[d.cur : d.cur+n : d.cur+n]
+	d.cur += n
+	return bs, nil
+}
+
+// Offset returns the position of the decoder in the original input
+func (d *Decoder) Offset() int {
+	return d.base + d.cur
+}
+
+// checkConsumed rejects unread bytes in strict mode
+func (d *Decoder) checkConsumed() error {
+	if d.opts.Strict && d.cur != len(d.xs) {
+		return fmt.Errorf("%w: %d trailing bytes", ErrNonCanonical, len(d.xs)-d.cur)
+	}
+	return nil
+}
+
+// enter records that a container or list is being decoded, rejecting nesting deeper than
+// MaxRecursionDepth. Every successful enter is paired with a leave.
+func (d *Decoder) enter() error {
+	if d.opts.MaxRecursionDepth > 0 && d.depth >= d.opts.MaxRecursionDepth {
+		return fmt.Errorf("%w: nesting depth exceeds %d", ErrLimitExceeded, d.opts.MaxRecursionDepth)
+	}
+	d.depth++
+	return nil
+}
+
+func (d *Decoder) leave() {
+	d.depth--
+}
+
+// enterField records that the field name of a container with the given tag is decoded next, for
+// LimitOverrides, and returns a function restoring the previous field
+func (d *Decoder) enterField(name string, tag *sszTag) (restore func()) {
+	if len(d.opts.LimitOverrides) == 0 {
+		return func() {}
+	}
+	path, fieldTag := d.path, d.fieldTag
+	if d.path == "" {
+		d.path = name
+	} else {
+		d.path += "." + name
+	}
+	d.fieldTag = tag
+	return func() {
+		d.path, d.fieldTag = path, fieldTag
+	}
+}
+
+// listLimit returns the limit of a list with tag, from LimitOverrides if it has one for the
+// list, or else from its ssz-max. 0 means no limit.
+func (d *Decoder) listLimit(tag *sszTag) int {
+	if tag == nil {
+		return 0
+	}
+	if len(d.opts.LimitOverrides) > 0 {
+		// Elements of lists of lists have tags of their own, and keep the path of the field
+		if tag == d.fieldTag {
+			if limit, ok := d.opts.LimitOverrides[d.path]; ok {
+				return int(limit)
+			}
+		}
+		if limit, ok := d.opts.LimitOverrides[tag.MaxName]; ok && tag.MaxName != "" {
+			return int(limit)
+		}
+	}
+	return tag.MaxList
+}
+
+// checkListLength applies the ssz-max limit of a list, or its override, and MaxListElements to
+// its length
+func (d *Decoder) checkListLength(n int, tag *sszTag) error {
+	if limit := d.listLimit(tag); limit > 0 && n > limit {
+		return fmt.Errorf("slice length %d exceeds limit %d", n, limit)
+	}
+	if d.opts.MaxListElements > 0 && n > d.opts.MaxListElements {
+		return fmt.Errorf("%w: list of %d elements exceeds %d", ErrLimitExceeded, n, d.opts.MaxListElements)
+	}
+	return nil
+}
+
 // remaining bytes in buffer, similar to calling buffer.Bytes()
 func (d *Decoder) Remaining() []byte {
 	return d.xs[d.cur:]
@@ -111,17 +270,19 @@ func (d *Decoder) ScanUint64(i *uint64) (err error) {
 	err = binary.Read(d, order, i)
 	return
 }
+
+// ScanBool reads a boolean. bytes other than 0 and 1 are read as false, or rejected with an
+// error wrapping ErrNonCanonical when the decoder is strict.
 func (d *Decoder) ScanBool(a *bool) (err error) {
 	ans, err := d.ReadN(1)
 	if err != nil {
 		return err
 	}
-	if ans[0] == 1 {
-		*a = true
-		return nil
+	if ans[0] > 1 && d.opts.Strict {
+		return fmt.Errorf("%w: boolean byte 0x%02x", ErrNonCanonical, ans[0])
 	}
-	*a = false
-	return
+	*a = ans[0] == 1
+	return nil
 }
 func (d *Decoder) ReadBool() (b bool, err error) {
 	err = d.ScanBool(&b)
@@ -147,11 +308,30 @@ func (d *Decoder) ReadUint64() (i uint64, err error) {
 	err = d.ScanBinary(&i)
 	return
 }
+
+// ReadOffset reads a uint32 offset as an int. Offsets that do not fit in an int, from 2^31 on
+// 32-bit platforms, are rejected rather than converted to negative numbers. The offset is not
+// checked against the length of the data, see offsetWithin.
 func (d *Decoder) ReadOffset() (j int, err error) {
 	var i uint32
-	err = d.ScanBinary(&i)
-	j = int(i)
-	return
+	if err = d.ScanBinary(&i); err != nil {
+		return 0, err
+	}
+	if uint64(i) > math.MaxInt {
+		return 0, fmt.Errorf("offset %d does not fit in an int", i)
+	}
+	return int(i), nil
+}
+
+// offsetWithin converts an offset read from the data to an int, reporting whether it is at most
+// limit, the length of the data it points into. The comparison is done in uint64, so offsets
+// close to 2^32 cannot wrap around the int arithmetic of the caller on 32-bit platforms, and
+// offsets that pass fit in an int.
+func offsetWithin(offset uint32, limit int) (int, bool) {
+	if limit < 0 || uint64(offset) > uint64(limit) {
+		return 0, false
+	}
+	return int(offset), true
 }
 
 // ReadAll reads all remaining bytes in the decoder
@@ -171,30 +351,95 @@ func (d *Decoder) ReadAll() ([]byte, error) {
 	return buf, nil
 }
 
-func (d *Decoder) ReadUint128() (*uint256.Int, error) {
-	buf := [32]byte{}
-	_, err := d.Read(buf[:16])
-	if err != nil {
+// ReadAllMax reads all remaining bytes like ReadAll, but fails without reading or allocating
+// anything when more than max bytes remain, so a limit such as an ssz-max is enforced before the
+// bytes are copied. The copy is charged against DecodeOptions.MaxAllocatedBytes. A max of 0 or
+// less does not limit the length.
+func (d *Decoder) ReadAllMax(max int) ([]byte, error) {
+	if err := d.checkRemaining(max); err != nil {
 		return nil, err
 	}
-	val := new(uint256.Int)
-	err = val.UnmarshalSSZ(buf[:])
-	if err != nil {
+	if err := d.allocate(len(d.Remaining())); err != nil {
+		return nil, err
+	}
+	return d.ReadAll()
+}
+
+// readAllMax is ReadAllMax for decoded byte slices, which alias the input with ZeroCopy
+func (d *Decoder) readAllMax(max int) ([]byte, error) {
+	if err := d.checkRemaining(max); err != nil {
+		return nil, err
+	}
+	n := len(d.Remaining())
+	if err := d.allocateBytes(n); err != nil {
 		return nil, err
 	}
+	return d.readBytes(n)
+}
 
-	return val, nil
+// checkRemaining fails when more than max bytes remain, if max is positive
+func (d *Decoder) checkRemaining(max int) error {
+	if n := len(d.xs) - d.cur; max > 0 && n > max {
+		return fmt.Errorf("length %d exceeds limit %d", n, max)
+	}
+	return nil
 }
 
-func (d *Decoder) ReadUint256() (*uint256.Int, error) {
-	bytes, err := d.ReadN(32)
+// next returns the next n bytes of the decoder without copying them, failing like Read
+func (d *Decoder) next(n int) ([]byte, error) {
+	if d.cur == len(d.xs) && n > 0 {
+		return nil, io.EOF
+	}
+	if len(d.xs)-d.cur < n {
+		return nil, fmt.Errorf("ssz: %w", io.ErrUnexpectedEOF)
+	}
+	o := d.xs[d.cur : d.cur+n]
+	d.cur += n
+	return o, nil
+}
+
+// ScanUint128LE reads a 16 byte little-endian uint128 into the lower two limbs of dst, clearing
+// the upper two. It loads the limbs straight from the input, without allocating.
+func (d *Decoder) ScanUint128LE(dst *uint256.Int) error {
+	buf, err := d.next(16)
 	if err != nil {
+		return err
+	}
+	// uint256.Int is [4]uint64 in little-endian order, mirroring EncodeUint128
+	dst[0] = order.Uint64(buf[0:8])
+	dst[1] = order.Uint64(buf[8:16])
+	dst[2], dst[3] = 0, 0
+	return nil
+}
+
+// ScanUint256LE reads a 32 byte little-endian uint256 into dst. It loads the limbs straight
+// from the input, without allocating.
+func (d *Decoder) ScanUint256LE(dst *uint256.Int) error {
+	buf, err := d.next(32)
+	if err != nil {
+		return err
+	}
+	// uint256.Int is [4]uint64 in little-endian order, mirroring EncodeUint256
+	dst[0] = order.Uint64(buf[0:8])
+	dst[1] = order.Uint64(buf[8:16])
+	dst[2] = order.Uint64(buf[16:24])
+	dst[3] = order.Uint64(buf[24:32])
+	return nil
+}
+
+// ReadUint128 reads a 16 byte little-endian uint128 into the lower two limbs of a uint256.Int
+func (d *Decoder) ReadUint128() (*uint256.Int, error) {
+	val := new(uint256.Int)
+	if err := d.ScanUint128LE(val); err != nil {
 		return nil, err
 	}
+	return val, nil
+}
 
+// ReadUint256 reads a 32 byte little-endian uint256
+func (d *Decoder) ReadUint256() (*uint256.Int, error) {
 	val := new(uint256.Int)
-	err = val.UnmarshalSSZ(bytes)
-	if err != nil {
+	if err := d.ScanUint256LE(val); err != nil {
 		return nil, err
 	}
 	return val, nil
@@ -226,6 +471,142 @@ func Container(elements ...ContainerElement) DecodeFunc {
 	}
 }
 
+// FixedContainer creates a ContainerElement for a fixed-size container nested in a container.
+// Its elements are decoded from exactly size bytes, which they must consume completely,
+// e.g: FixedContainer(40, Fixed(readEpoch), Fixed(readRoot))
+func FixedContainer(size int, elements ...ContainerElement) ContainerElement {
+	return Fixed(func(d *Decoder) error {
+		for _, elem := range elements {
+			if elem.Variable != nil {
+				return fmt.Errorf("fixed container cannot have variable elements")
+			}
+		}
+		if size < 0 || len(d.xs)-d.cur < size {
+			return fmt.Errorf("fixed container of %d bytes: %w", size, io.ErrUnexpectedEOF)
+		}
+
+		fieldDecoder := d.sub(d.cur, d.cur+size)
+		if err := fieldDecoder.DecodeContainer(elements...); err != nil {
+			return err
+		}
+		if fieldDecoder.cur != size {
+			return fmt.Errorf("fixed container of %d bytes has %d unread bytes", size, size-fieldDecoder.cur)
+		}
+		d.cur += size
+		return nil
+	})
+}
+
+// FixedList creates a DecodeFunc for a list of at most max fixed-size elements of elemSize
+// bytes, which reads the rest of the decoder. elem returns the function decoding element i,
+// which must consume its elemSize bytes completely,
+// e.g: Variable(FixedList(8, 16, func(i int) DecodeFunc { ... }))
+func FixedList(elemSize, max int, elem func(i int) DecodeFunc) DecodeFunc {
+	return func(d *Decoder) error {
+		n := len(d.Remaining())
+		if elemSize <= 0 || n%elemSize != 0 {
+			return fmt.Errorf("invalid data size for list: %d bytes cannot be divided by element size %d", n, elemSize)
+		}
+		count := n / elemSize
+		if err := d.checkListLength(count, &sszTag{MaxList: max}); err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			offset := d.Offset()
+			elemDecoder := d.sub(d.cur, d.cur+elemSize)
+			err := elem(i)(elemDecoder)
+			if err == nil && elemDecoder.cur != elemSize {
+				err = fmt.Errorf("element of %d bytes has %d unread bytes", elemSize, elemSize-elemDecoder.cur)
+			}
+			if err != nil {
+				return wrapDecodeError(err, indexSegment(i), offset)
+			}
+			d.cur += elemSize
+		}
+		return nil
+	}
+}
+
+// VariableList creates a DecodeFunc for a list of at most max variable-size elements, such as
+// containers with variable fields, which reads the rest of the decoder. elem returns the
+// function decoding element i from its own bytes,
+// e.g: Variable(VariableList(16, func(i int) DecodeFunc { return Container(...) }))
+func VariableList(max int, elem func(i int) DecodeFunc) DecodeFunc {
+	return func(d *Decoder) error {
+		size := len(d.Remaining())
+		if size == 0 {
+			return nil
+		}
+		if size < 4 {
+			return fmt.Errorf("invalid data for variable-size list: less than 4 bytes")
+		}
+		first, err := d.PeekUint32()
+		if err != nil {
+			return err
+		}
+		// The offsets are part of the data, so the first one bounds the number of elements
+		if first < 4 || first%4 != 0 || uint64(first) > uint64(size) {
+			return fmt.Errorf("invalid first offset %d for list of %d bytes", first, size)
+		}
+		count := int(first) / 4
+		if err := d.checkListLength(count, &sszTag{MaxList: max}); err != nil {
+			return err
+		}
+		offsets := make([]uint32, count)
+		for i := range offsets {
+			if offsets[i], err = d.ReadUint32(); err != nil {
+				return err
+			}
+		}
+		if d.opts.Strict {
+			if err := checkListOffsets(offsets, size); err != nil {
+				return err
+			}
+		}
+
+		base := d.cur - 4*count
+		for i := 0; i < count; i++ {
+			// Bounds are checked relative to the list before base is added, see offsetWithin
+			start, startOK := offsetWithin(offsets[i], size)
+			end, endOK := size, true
+			if i+1 < count {
+				end, endOK = offsetWithin(offsets[i+1], size)
+			}
+			if !startOK || !endOK || start < 4*count || start > end {
+				return wrapDecodeError(fmt.Errorf("invalid offset %d", offsets[i]), indexSegment(i), d.Offset())
+			}
+			start, end = base+start, base+end
+			elemDecoder := d.sub(start, end)
+			err := elem(i)(elemDecoder)
+			if err == nil {
+				err = elemDecoder.checkConsumed()
+			}
+			if err != nil {
+				return wrapDecodeError(err, indexSegment(i), elemDecoder.base)
+			}
+			d.cur = end
+		}
+		return nil
+	}
+}
+
+// BitList creates a DecodeFunc that reads the rest of the decoder into dst as a bitlist of at
+// most maxBits bits. Like bitlist fields, dst holds the serialized form, including the delimiter
+// bit, e.g: Variable(BitList(2048, &att.AggregationBits))
+func BitList(maxBits int, dst *[]byte) DecodeFunc {
+	return func(d *Decoder) error {
+		bits, err := d.readAllMax(bitlistMaxBytes(maxBits))
+		if err != nil {
+			return fmt.Errorf("error decoding bitlist: %w", err)
+		}
+		if err := ValidateBitlist(bits, uint64(maxBits)); err != nil {
+			return fmt.Errorf("error decoding bitlist: %w", err)
+		}
+		*dst = bits
+		return nil
+	}
+}
+
 // DecodeContainer decodes a container with mixed fixed and variable fields
 func (d *Decoder) DecodeContainer(elements ...ContainerElement) error {
 	// First pass: read fixed fields and collect offsets
@@ -239,16 +620,27 @@ func (d *Decoder) DecodeContainer(elements ...ContainerElement) error {
 				return err
 			}
 		} else if elem.Variable != nil {
-			// Read offset for variable field
-			offset, err := d.ReadOffset()
+			// Read offset for variable field, which must point into the container
+			raw, err := d.ReadUint32()
 			if err != nil {
 				return err
 			}
+			offset, ok := offsetWithin(raw, len(d.xs))
+			if !ok {
+				return fmt.Errorf("invalid offset %d: past the end of the data (%d)", raw, len(d.xs))
+			}
 			offsets = append(offsets, offset)
 			variableDecoders = append(variableDecoders, elem.Variable)
 		}
 	}
 
+	// Variable fields cannot start in the fixed part. In strict mode the first one must start
+	// right after it.
+	fixedEnd := d.cur
+	if d.opts.Strict && len(offsets) > 0 && offsets[0] != d.cur {
+		return fmt.Errorf("%w: first offset %d does not match end of fixed part %d", ErrNonCanonical, offsets[0], d.cur)
+	}
+
 	// Second pass: decode variable fields
 	for i, decoder := range variableDecoders {
 		// Determine the bounds for this field
@@ -258,17 +650,24 @@ func (d *Decoder) DecodeContainer(elements ...ContainerElement) error {
 			end = offsets[i+1]
 		}
 
-		// Validate bounds
-		if start > len(d.xs) || end > len(d.xs) || start > end {
+		// Validate bounds, offsets are already known to be within the data
+		if start < fixedEnd || start > end {
 			return fmt.Errorf("invalid offset: start=%d, end=%d, len=%d", start, end, len(d.xs))
 		}
 
 		// Create decoder for just this field's data
-		fieldDecoder := NewDecoder(d.xs[start:end])
+		fieldDecoder := d.sub(start, end)
 		if err := decoder(fieldDecoder); err != nil {
 			return err
 		}
+		if err := fieldDecoder.checkConsumed(); err != nil {
+			return err
+		}
 	}
 
+	// The last variable field runs to the end of the container
+	if len(offsets) > 0 {
+		d.cur = len(d.xs)
+	}
 	return nil
 }
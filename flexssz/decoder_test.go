@@ -7,6 +7,7 @@ import (
 	"io"
 	"testing"
 
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -146,6 +147,28 @@ func TestDecoder_ReadN(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestDecoder_ReadAllMax(t *testing.T) {
+	d := NewDecoder([]byte{1, 2, 3, 4, 5})
+	_, err := d.ReadN(1)
+	require.NoError(t, err)
+
+	// Four bytes remain, more than the limit
+	_, err = d.ReadAllMax(3)
+	assert.ErrorContains(t, err, "length 4 exceeds limit 3")
+	assert.Equal(t, 0, d.Allocated())
+	assert.Equal(t, []byte{2, 3, 4, 5}, d.Remaining())
+
+	result, err := d.ReadAllMax(4)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 3, 4, 5}, result)
+	assert.Equal(t, 4, d.Allocated())
+
+	// No limit
+	result, err = NewDecoder([]byte{1, 2, 3}).ReadAllMax(0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, result)
+}
+
 func TestDecoder_ScanUint(t *testing.T) {
 	tests := []struct {
 		name string
@@ -233,6 +256,13 @@ func TestDecoder_ScanBool(t *testing.T) {
 	err = d3.ScanBool(&b3)
 	require.NoError(t, err)
 	assert.False(t, b3)
+
+	// Strict decoders reject it
+	d4 := NewDecoderWithOptions([]byte{2}, DecodeOptions{Strict: true})
+	var b4 bool
+	err = d4.ScanBool(&b4)
+	assert.ErrorIs(t, err, ErrNonCanonical)
+	assert.ErrorContains(t, err, "boolean byte 0x02")
 }
 
 func TestDecoder_ReadUint(t *testing.T) {
@@ -331,6 +361,18 @@ func TestDecoder_ReadOffset(t *testing.T) {
 	assert.Equal(t, int(offset), result)
 }
 
+func TestOffsetWithin(t *testing.T) {
+	n, ok := offsetWithin(16, 16)
+	assert.True(t, ok)
+	assert.Equal(t, 16, n)
+	for _, offset := range []uint32{17, 0x80000000, 0xffffffff} {
+		_, ok := offsetWithin(offset, 16)
+		assert.False(t, ok, "offset %d", offset)
+	}
+	_, ok = offsetWithin(0, -1)
+	assert.False(t, ok)
+}
+
 func TestDecoder_FixedList_UsingJump(t *testing.T) {
 	// Create test data: 4 uint32 values
 	var buf bytes.Buffer
@@ -460,6 +502,66 @@ func TestDecoder_Container_WithDynamicList(t *testing.T) {
 	assert.Equal(t, []uint64{100}, dynamicVals)
 }
 
+type decoderTestAttestation struct {
+	Slot uint64
+	Bits []byte `ssz:"bitlist" ssz-max:"64"`
+}
+
+type decoderTestBlock struct {
+	Slot         uint64
+	Balances     []uint64                 `ssz-max:"8"`
+	Attestations []decoderTestAttestation `ssz-max:"4"`
+}
+
+func TestDecoder_ListCombinators(t *testing.T) {
+	block := &decoderTestBlock{
+		Slot:     7,
+		Balances: []uint64{1, 2, 3},
+		Attestations: []decoderTestAttestation{
+			{Slot: 5, Bits: []byte{0x0b}},
+			{Slot: 6, Bits: []byte{0xff, 0x01}},
+		},
+	}
+	data, err := Marshal(block)
+	require.NoError(t, err)
+
+	decode := func(d *Decoder, maxAttestations int) (*decoderTestBlock, error) {
+		var out decoderTestBlock
+		err := d.DecodeContainer(
+			Fixed(func(d *Decoder) error { return d.ScanUint64(&out.Slot) }),
+			Variable(FixedList(8, 8, func(i int) DecodeFunc {
+				out.Balances = append(out.Balances, 0)
+				return func(d *Decoder) error { return d.ScanUint64(&out.Balances[i]) }
+			})),
+			Variable(VariableList(maxAttestations, func(i int) DecodeFunc {
+				out.Attestations = append(out.Attestations, decoderTestAttestation{})
+				att := &out.Attestations[i]
+				return Container(
+					Fixed(func(d *Decoder) error { return d.ScanUint64(&att.Slot) }),
+					Variable(BitList(64, &att.Bits)),
+				)
+			})),
+		)
+		return &out, err
+	}
+
+	decoded, err := decode(NewDecoderWithOptions(data, DecodeOptions{Strict: true}), 4)
+	require.NoError(t, err)
+	assert.Equal(t, block, decoded)
+
+	_, err = decode(NewDecoder(data), 1)
+	assert.ErrorContains(t, err, "exceeds limit 1")
+
+	// An attestation bitlist without its delimiter bit fails at the element
+	bad := bytes.Clone(data)
+	bad[len(bad)-1] = 0
+	_, err = decode(NewDecoder(bad), 4)
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "[1]", decodeErr.Path)
+	assert.ErrorContains(t, err, "error decoding bitlist")
+}
+
 func TestDecoder_ReadString(t *testing.T) {
 	data := []byte("hello world")
 	d := NewDecoder(data)
@@ -522,3 +624,200 @@ func TestDecoder_ScanBinarySlice(t *testing.T) {
 	assert.Equal(t, uint32(10), val)
 	assert.Equal(t, 4, d.cur)
 }
+
+func TestDecoder_ReadUint128(t *testing.T) {
+	t.Run("limb order", func(t *testing.T) {
+		data := make([]byte, 16)
+		binary.LittleEndian.PutUint64(data[0:8], 0x0102030405060708)
+		binary.LittleEndian.PutUint64(data[8:16], 0x1112131415161718)
+
+		d := NewDecoder(data)
+		val, err := d.ReadUint128()
+		require.NoError(t, err)
+		assert.Equal(t, uint256.Int{0x0102030405060708, 0x1112131415161718, 0, 0}, *val)
+		assert.Equal(t, 16, d.cur)
+	})
+
+	t.Run("short buffer", func(t *testing.T) {
+		d := NewDecoder(make([]byte, 15))
+		_, err := d.ReadUint128()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("cross test against encoder", func(t *testing.T) {
+		values := []string{
+			"0x0",
+			"0x1",
+			"0xFFFFFFFFFFFFFFFF",
+			"0x10000000000000000",
+			"0x123456789ABCDEF0011223344556677",
+			"0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		}
+		for _, hex := range values {
+			var val uint256.Int
+			require.NoError(t, val.SetFromHex(hex))
+
+			buf := new(bytes.Buffer)
+			NewBuilder(buf).EncodeUint128(&val).Finish()
+			require.Equal(t, 16, buf.Len())
+
+			// The wire format must match the reversed big-endian bytes
+			be := val.Bytes32()
+			for i := 0; i < 16; i++ {
+				assert.Equal(t, be[31-i], buf.Bytes()[i], "%s byte %d", hex, i)
+			}
+
+			decoded, err := NewDecoder(buf.Bytes()).ReadUint128()
+			require.NoError(t, err)
+			assert.Equal(t, val, *decoded, hex)
+		}
+	})
+}
+
+func TestDecoder_ReadUint256(t *testing.T) {
+	t.Run("limb order", func(t *testing.T) {
+		data := make([]byte, 32)
+		for i := 0; i < 4; i++ {
+			binary.LittleEndian.PutUint64(data[i*8:], uint64(i+1))
+		}
+
+		d := NewDecoder(data)
+		val, err := d.ReadUint256()
+		require.NoError(t, err)
+		assert.Equal(t, uint256.Int{1, 2, 3, 4}, *val)
+		assert.Equal(t, 32, d.cur)
+	})
+
+	t.Run("short buffer", func(t *testing.T) {
+		d := NewDecoder(make([]byte, 31))
+		_, err := d.ReadUint256()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("cross test against encoder", func(t *testing.T) {
+		values := []string{
+			"0x0",
+			"0x1",
+			"0xFFFFFFFFFFFFFFFF",
+			"0x10000000000000000",
+			"0x123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0",
+			"0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		}
+		for _, hex := range values {
+			var val uint256.Int
+			require.NoError(t, val.SetFromHex(hex))
+
+			buf := new(bytes.Buffer)
+			NewBuilder(buf).EncodeUint256(&val).Finish()
+			require.Equal(t, 32, buf.Len())
+
+			// The wire format must match the reversed big-endian bytes
+			be := val.Bytes32()
+			for i := 0; i < 32; i++ {
+				assert.Equal(t, be[31-i], buf.Bytes()[i], "%s byte %d", hex, i)
+			}
+
+			decoded, err := NewDecoder(buf.Bytes()).ReadUint256()
+			require.NoError(t, err)
+			assert.Equal(t, val, *decoded, hex)
+		}
+	})
+}
+
+func TestDecoder_ScanUint256LE(t *testing.T) {
+	data := make([]byte, 48)
+	for i := 0; i < 6; i++ {
+		binary.LittleEndian.PutUint64(data[i*8:], uint64(i+1))
+	}
+
+	val := uint256.Int{9, 9, 9, 9}
+	d := NewDecoder(data)
+	require.NoError(t, d.ScanUint256LE(&val))
+	assert.Equal(t, uint256.Int{1, 2, 3, 4}, val)
+	require.NoError(t, d.ScanUint128LE(&val))
+	assert.Equal(t, uint256.Int{5, 6, 0, 0}, val, "the upper limbs are cleared")
+	assert.ErrorIs(t, d.ScanUint256LE(&val), io.EOF)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		d := Decoder{xs: data}
+		_ = d.ScanUint256LE(&val)
+	})
+	assert.Zero(t, allocs)
+
+	buf := new(bytes.Buffer)
+	NewBuilder(buf).EncodeUint256(&uint256.Int{1, 2, 3, 4}).EncodeUint128(&uint256.Int{5, 6, 7, 8}).Finish()
+	assert.Equal(t, data, buf.Bytes(), "EncodeUint128 drops the upper limbs")
+}
+
+func TestDecoder_FixedContainer(t *testing.T) {
+	// A container of a uint16 and a nested checkpoint (uint64 epoch, 32 byte root),
+	// followed by a variable field
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint16(7))
+	binary.Write(&buf, binary.LittleEndian, uint64(12))
+	buf.Write(bytes.Repeat([]byte{0xaa}, 32))
+	binary.Write(&buf, binary.LittleEndian, uint32(46))
+	buf.Write([]byte{1, 2, 3})
+
+	var (
+		a     uint16
+		epoch uint64
+		root  [32]byte
+		data  []byte
+	)
+	readEpoch := Fixed(func(d *Decoder) error { return d.ScanUint64(&epoch) })
+	readRoot := Fixed(func(d *Decoder) error {
+		_, err := d.Read(root[:])
+		return err
+	})
+	readData := Variable(func(d *Decoder) error {
+		var err error
+		data, err = d.ReadAll()
+		return err
+	})
+
+	t.Run("decodes nested fields", func(t *testing.T) {
+		d := NewDecoder(buf.Bytes())
+		err := d.DecodeContainer(
+			Fixed(func(d *Decoder) error { return d.ScanUint16(&a) }),
+			FixedContainer(40, readEpoch, readRoot),
+			readData,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(7), a)
+		assert.Equal(t, uint64(12), epoch)
+		assert.Equal(t, byte(0xaa), root[31])
+		assert.Equal(t, []byte{1, 2, 3}, data)
+	})
+
+	t.Run("unread bytes", func(t *testing.T) {
+		d := NewDecoder(buf.Bytes())
+		err := d.DecodeContainer(
+			Fixed(func(d *Decoder) error { return d.ScanUint16(&a) }),
+			FixedContainer(40, readEpoch),
+			readData,
+		)
+		assert.ErrorContains(t, err, "32 unread bytes")
+	})
+
+	t.Run("reading past the container", func(t *testing.T) {
+		d := NewDecoder(buf.Bytes())
+		err := d.DecodeContainer(
+			Fixed(func(d *Decoder) error { return d.ScanUint16(&a) }),
+			FixedContainer(39, readEpoch, readRoot),
+		)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("variable elements", func(t *testing.T) {
+		d := NewDecoder(buf.Bytes())
+		err := d.DecodeContainer(FixedContainer(40, readData))
+		assert.ErrorContains(t, err, "cannot have variable elements")
+	})
+
+	t.Run("short input", func(t *testing.T) {
+		d := NewDecoder(buf.Bytes()[:20])
+		err := d.DecodeContainer(FixedContainer(40, readEpoch, readRoot))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
@@ -0,0 +1,243 @@
+package flexssz
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// FieldDiff is a difference between two values found by Diff
+type FieldDiff struct {
+	// Path of the differing value, in the format of DecodeError.Path, e.g. "Body.Deposits[3]".
+	// Map entries are indexed by key, e.g. "Labels[foo]".
+	Path string
+	// A and B are the differing values. For lists of different lengths, the elements past the
+	// end of the shorter list are reported against nil.
+	A, B any
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %v != %v", d.Path, d.A, d.B)
+}
+
+// Equal reports whether a and b have the same SSZ encoding, without encoding them. See Diff.
+func Equal(a, b any) (bool, error) {
+	d := &differ{max: 1}
+	if err := d.diffRoot(a, b); err != nil {
+		return false, err
+	}
+	return len(d.diffs) == 0, nil
+}
+
+// Diff returns the paths at which a and b, values of the same type, differ in their SSZ
+// encoding. Only the fields that are encoded are compared, so skipped fields are ignored, nil
+// and empty slices are equal, nil pointers equal pointers to zero values, and times are
+// compared in whole seconds. Byte lists and vectors, bitfields and strings are compared as a
+// whole, while other lists and vectors are compared element by element. Custom types are
+// compared by their MarshalSSZ output.
+func Diff(a, b any) ([]FieldDiff, error) {
+	d := &differ{}
+	if err := d.diffRoot(a, b); err != nil {
+		return nil, err
+	}
+	return d.diffs, nil
+}
+
+// differ collects the differences between two values, stopping after max if it is set
+type differ struct {
+	diffs []FieldDiff
+	max   int
+}
+
+func (d *differ) done() bool {
+	return d.max > 0 && len(d.diffs) >= d.max
+}
+
+func (d *differ) add(path string, a, b any) {
+	d.diffs = append(d.diffs, FieldDiff{Path: path, A: a, B: b})
+}
+
+func (d *differ) diffRoot(a, b any) error {
+	if a == nil || b == nil {
+		return fmt.Errorf("cannot compare nil")
+	}
+	va, vb := derefZero(reflect.ValueOf(a)), derefZero(reflect.ValueOf(b))
+	if va.Type() != vb.Type() {
+		return fmt.Errorf("cannot compare %v with %v", va.Type(), vb.Type())
+	}
+	info, err := GetTypeInfo(va.Type(), nil)
+	if err != nil {
+		return fmt.Errorf("error getting type info: %w", err)
+	}
+	return d.diff("", va, vb, info)
+}
+
+// derefZero dereferences v, treating nil pointers as pointers to the zero value
+func derefZero(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.New(v.Type().Elem()).Elem()
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func (d *differ) diff(path string, a, b reflect.Value, info *TypeInfo) error {
+	if d.done() {
+		return nil
+	}
+	a, b = derefZero(a), derefZero(b)
+	if info.Wrapped != nil {
+		var wrapped *TypeInfo
+		a, wrapped = unwrapTransparent(a, info)
+		b, _ = unwrapTransparent(b, info)
+		return d.diff(path, a, b, wrapped)
+	}
+
+	if info.Custom {
+		encA, err := marshalCustom(a, info.Tag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		encB, err := marshalCustom(b, info.Tag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if !bytes.Equal(encA, encB) {
+			d.add(path, a.Interface(), b.Interface())
+		}
+		return nil
+	}
+
+	switch info.Type {
+	case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeUint128, ssz.TypeUint256, ssz.TypeBoolean:
+		if a.Type() == timeType {
+			secA, err := timeToUnix(a.Interface().(time.Time))
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			secB, err := timeToUnix(b.Interface().(time.Time))
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if secA != secB {
+				d.add(path, a.Interface(), b.Interface())
+			}
+		} else if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.add(path, a.Interface(), b.Interface())
+		}
+
+	case ssz.TypeBitVector, ssz.TypeBitList:
+		bitsA, bitsB := a.Bytes(), b.Bytes()
+		if info.Type == ssz.TypeBitList {
+			// An empty bitlist encodes as its delimiter bit alone
+			if len(bitsA) == 0 {
+				bitsA = emptyBitList
+			}
+			if len(bitsB) == 0 {
+				bitsB = emptyBitList
+			}
+		}
+		if !bytes.Equal(bitsA, bitsB) {
+			d.add(path, a.Interface(), b.Interface())
+		}
+
+	case ssz.TypeUnion:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.add(path, a.Interface(), b.Interface())
+		}
+
+	case ssz.TypeVector, ssz.TypeList:
+		if kv := info.kvlist; kv != nil {
+			return d.diffMap(path, a, b, kv)
+		}
+		if a.Kind() == reflect.String {
+			if a.String() != b.String() {
+				d.add(path, a.Interface(), b.Interface())
+			}
+			return nil
+		}
+		if a.Type().Elem().Kind() == reflect.Uint8 && info.ElementType.Type == ssz.TypeUint8 {
+			if !bytes.Equal(valueBytes(a), valueBytes(b)) {
+				d.add(path, a.Interface(), b.Interface())
+			}
+			return nil
+		}
+		for i := 0; i < max(a.Len(), b.Len()) && !d.done(); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				d.add(elemPath, nil, b.Index(i).Interface())
+			case i >= b.Len():
+				d.add(elemPath, a.Index(i).Interface(), nil)
+			default:
+				if err := d.diff(elemPath, a.Index(i), b.Index(i), info.ElementType); err != nil {
+					return err
+				}
+			}
+		}
+
+	case ssz.TypeContainer:
+		for _, field := range info.Fields {
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			if err := d.diff(fieldPath, field.value(a), field.value(b), field.Type); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("%s: unsupported SSZ type for diff: %v", path, info.Type)
+	}
+	return nil
+}
+
+// diffMap compares kvlist maps by key, reporting keys missing from one of them against nil
+func (d *differ) diffMap(path string, a, b reflect.Value, kv *kvList) error {
+	keys := make([]reflect.Value, 0, a.Len()+b.Len())
+	keys = append(keys, a.MapKeys()...)
+	for _, key := range b.MapKeys() {
+		if !a.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return compareKVKeys(keys[i], keys[j]) < 0
+	})
+	valueInfo := kv.info.ElementType.Fields[1].Type
+	for _, key := range keys {
+		if d.done() {
+			break
+		}
+		keyPath := fmt.Sprintf("%s[%v]", path, key)
+		valueA, valueB := a.MapIndex(key), b.MapIndex(key)
+		switch {
+		case !valueA.IsValid():
+			d.add(keyPath, nil, valueB.Interface())
+		case !valueB.IsValid():
+			d.add(keyPath, valueA.Interface(), nil)
+		default:
+			if err := d.diff(keyPath, valueA, valueB, valueInfo); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// valueBytes returns the bytes of a byte slice or array
+func valueBytes(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	out := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(out), v)
+	return out
+}
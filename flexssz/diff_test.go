@@ -0,0 +1,99 @@
+package flexssz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type diffTestCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type diffTestState struct {
+	Slot        uint64
+	GenesisTime time.Time `ssz:"uint64,unix"`
+	Finalized   *diffTestCheckpoint
+	Checkpoints []diffTestCheckpoint `ssz-max:"8"`
+	Balances    []uint64             `ssz-max:"8"`
+	Graffiti    []byte               `ssz-max:"32"`
+	Bits        []byte               `ssz:"bitlist" ssz-max:"16"`
+	Labels      map[string]uint64    `ssz:"kvlist" ssz-max:"4"`
+	Cache       string               `ssz:"-"`
+}
+
+func TestEqual(t *testing.T) {
+	genesis := time.Unix(1606824023, 0)
+	a := &diffTestState{
+		Slot:        1,
+		GenesisTime: genesis,
+		Finalized:   &diffTestCheckpoint{Epoch: 2, Root: [32]byte{1}},
+		Checkpoints: []diffTestCheckpoint{{Epoch: 1}},
+		Labels:      map[string]uint64{"a": 1},
+		Cache:       "a",
+	}
+	b := &diffTestState{
+		Slot:        1,
+		GenesisTime: genesis.Add(time.Millisecond),
+		Finalized:   &diffTestCheckpoint{Epoch: 2, Root: [32]byte{1}},
+		Checkpoints: []diffTestCheckpoint{{Epoch: 1}},
+		Balances:    []uint64{},
+		Graffiti:    []byte{},
+		Bits:        emptyBitList,
+		Labels:      map[string]uint64{"a": 1},
+		Cache:       "b",
+	}
+	equal, err := Equal(a, b)
+	require.NoError(t, err)
+	assert.True(t, equal, "skipped fields, empty slices and sub-second times do not differ")
+
+	b.Checkpoints[0].Root[0] = 1
+	equal, err = Equal(a, b)
+	require.NoError(t, err)
+	assert.False(t, equal)
+
+	// Nil pointers are zero values
+	equal, err = Equal(&diffTestState{}, &diffTestState{Finalized: &diffTestCheckpoint{}})
+	require.NoError(t, err)
+	assert.True(t, equal)
+
+	_, err = Equal(a, diffTestCheckpoint{})
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	a := &diffTestState{
+		Slot:        1,
+		Finalized:   &diffTestCheckpoint{Epoch: 2},
+		Checkpoints: []diffTestCheckpoint{{Epoch: 1}, {Epoch: 2}},
+		Balances:    []uint64{1, 2, 3},
+		Graffiti:    []byte("a"),
+		Labels:      map[string]uint64{"a": 1, "b": 2},
+	}
+	b := &diffTestState{
+		Slot:        1,
+		Finalized:   &diffTestCheckpoint{Epoch: 3},
+		Checkpoints: []diffTestCheckpoint{{Epoch: 1}, {Epoch: 2, Root: [32]byte{9}}},
+		Balances:    []uint64{1, 5},
+		Graffiti:    []byte("b"),
+		Labels:      map[string]uint64{"a": 1, "c": 3},
+	}
+	diffs, err := Diff(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldDiff{
+		{Path: "Finalized.Epoch", A: uint64(2), B: uint64(3)},
+		{Path: "Checkpoints[1].Root", A: [32]byte{}, B: [32]byte{9}},
+		{Path: "Balances[1]", A: uint64(2), B: uint64(5)},
+		{Path: "Balances[2]", A: uint64(3), B: nil},
+		{Path: "Graffiti", A: []byte("a"), B: []byte("b")},
+		{Path: "Labels[b]", A: uint64(2), B: nil},
+		{Path: "Labels[c]", A: nil, B: uint64(3)},
+	}, diffs)
+
+	diffs, err = Diff(a, a)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
@@ -0,0 +1,980 @@
+package flexssz
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/holiman/uint256"
+)
+
+// maxRefDepth bounds the refs followed to resolve a type, like the checks of ssz.Field
+const maxRefDepth = 1000
+
+// maxDynamicNodes bounds the DynamicValues created for the zero value of a vector of composite
+// elements, whose elements are all created up front. Schemas are runtime input, so sizes that
+// pass the checks of ssz.Field can still be far too large to allocate.
+const maxDynamicNodes = 1 << 22
+
+// DynamicValue is a value of an SSZ type described at runtime by an ssz.Field, such as a type of
+// a schema read with ssz.ParseSchemaJSON, for tools like explorers and debuggers that work with
+// types they were not compiled with. Fields and elements are read and written by path, in the
+// format of DecodeError.Path with the names of the schema, e.g. "body.deposits[3].data.amount".
+// The value of a union is at the name of its selected option.
+//
+// Get returns uint64 for uint8 to uint64, *uint256.Int for uint128 and uint256, and bool for
+// booleans. Vectors and lists of uint8, bitvectors and bitlists are []byte, bitlists in their
+// serialized form with the delimiter bit like bitlist fields. Containers, unions and other
+// vectors and lists are DynamicValues of their own. Set takes the same, and any Go integer for
+// unsigned integers. Stable containers, profiles and optionals are not supported.
+type DynamicValue struct {
+	field ssz.Field // With refs resolved
+	refs  map[string]ssz.Field
+	// elem is the element type of vectors and lists, and elemSize its size if it is basic, in
+	// which case the elements are packed in data
+	elem     ssz.Field
+	elemSize int
+	variable bool
+
+	basic    any    // uint64, bool or *uint256.Int
+	data     []byte // Bitfields and the elements of vectors and lists of basic values
+	elems    []*DynamicValue
+	selector uint8
+}
+
+// NewDynamicValue returns the zero value of field, whose refs are resolved from refs
+func NewDynamicValue(field ssz.Field, refs map[string]ssz.Field) (*DynamicValue, error) {
+	if err := field.IsValid(refs); err != nil {
+		return nil, err
+	}
+	return newDynamic(field, refs)
+}
+
+// newDynamic returns the zero value of field, which is valid
+func newDynamic(field ssz.Field, refs map[string]ssz.Field) (*DynamicValue, error) {
+	f, err := resolveRef(field, refs)
+	if err != nil {
+		return nil, err
+	}
+	v := &DynamicValue{field: f, refs: refs}
+	switch f.Type {
+	case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64:
+		v.basic = uint64(0)
+	case ssz.TypeUint128, ssz.TypeUint256:
+		v.basic = new(uint256.Int)
+	case ssz.TypeBoolean:
+		v.basic = false
+	case ssz.TypeBitVector:
+		v.data = make([]byte, (f.Size+7)/8)
+	case ssz.TypeBitList:
+		v.data = []byte{0x01}
+		v.variable = true
+	case ssz.TypeVector, ssz.TypeList:
+		if len(f.Children) != 1 {
+			return nil, fmt.Errorf("%s '%s' must have exactly one child, its element type", f.Type, f.Name)
+		}
+		if v.elem, err = resolveRef(f.Children[0], refs); err != nil {
+			return nil, err
+		}
+		v.elemSize = dynamicBasicSize(v.elem.Type)
+		v.variable = f.Type == ssz.TypeList
+		if f.Type == ssz.TypeList {
+			break
+		}
+		if v.elemSize > 0 {
+			if f.Size > uint64(math.MaxInt/v.elemSize) {
+				return nil, fmt.Errorf("vector '%s' of %d elements is too large", f.Name, f.Size)
+			}
+			v.data = make([]byte, int(f.Size)*v.elemSize)
+			break
+		}
+		if nodes := dynamicNodes(f, refs, 0); nodes > maxDynamicNodes {
+			return nil, fmt.Errorf("vector '%s' of %d elements is too large: its zero value has more than %d nodes", f.Name, f.Size, maxDynamicNodes)
+		}
+		v.elems = make([]*DynamicValue, f.Size)
+		for i := range v.elems {
+			if v.elems[i], err = newDynamic(v.elem, refs); err != nil {
+				return nil, err
+			}
+		}
+		v.variable = v.elems[0].variable
+	case ssz.TypeContainer:
+		v.elems = make([]*DynamicValue, len(f.Children))
+		for i, child := range f.Children {
+			if v.elems[i], err = newDynamic(child, refs); err != nil {
+				return nil, err
+			}
+			v.variable = v.variable || v.elems[i].variable
+		}
+	case ssz.TypeUnion:
+		option, err := newDynamic(f.Children[0], refs)
+		if err != nil {
+			return nil, err
+		}
+		v.elems = []*DynamicValue{option}
+		v.variable = true
+	default:
+		return nil, fmt.Errorf("type '%s' of '%s' is not supported by dynamic values", f.Type, f.Name)
+	}
+	return v, nil
+}
+
+// resolveRef follows the refs of f to the type it names, keeping the name of f
+func resolveRef(f ssz.Field, refs map[string]ssz.Field) (ssz.Field, error) {
+	name := f.Name
+	for i := 0; f.Type == ssz.TypeRef; i++ {
+		if i == maxRefDepth {
+			return ssz.Field{}, fmt.Errorf("max iterations reached while resolving '%s' - possible circular reference", name)
+		}
+		ref, ok := refs[f.Ref]
+		if !ok {
+			return ssz.Field{}, fmt.Errorf("field '%s' references type '%s' which is not found", name, f.Ref)
+		}
+		f = ref
+	}
+	f.Name = name
+	return f, nil
+}
+
+// dynamicNodes returns the number of DynamicValues in the zero value of f, or more than
+// maxDynamicNodes once it exceeds it, without creating them. depth counts the types entered,
+// which is bounded like refs are.
+func dynamicNodes(f ssz.Field, refs map[string]ssz.Field, depth int) uint64 {
+	f, err := resolveRef(f, refs)
+	if err != nil || depth == maxRefDepth {
+		return maxDynamicNodes + 1
+	}
+	nodes := uint64(1)
+	switch f.Type {
+	case ssz.TypeVector:
+		if len(f.Children) != 1 {
+			return nodes
+		}
+		elem, err := resolveRef(f.Children[0], refs)
+		if err != nil || dynamicBasicSize(elem.Type) > 0 {
+			return nodes
+		}
+		n := dynamicNodes(elem, refs, depth+1)
+		if f.Size > 0 && n > maxDynamicNodes/f.Size {
+			return maxDynamicNodes + 1
+		}
+		nodes += f.Size * n
+	case ssz.TypeContainer:
+		for _, child := range f.Children {
+			nodes += dynamicNodes(child, refs, depth+1)
+			if nodes > maxDynamicNodes {
+				return nodes
+			}
+		}
+	case ssz.TypeUnion:
+		if len(f.Children) > 0 {
+			nodes += dynamicNodes(f.Children[0], refs, depth+1)
+		}
+	}
+	return nodes
+}
+
+// dynamicBasicSize returns the size of basic type t, or 0 for other types
+func dynamicBasicSize(t ssz.TypeName) int {
+	return BasicTypeSize(&TypeInfo{Type: t})
+}
+
+// Schema returns the type of v, with its refs resolved
+func (v *DynamicValue) Schema() ssz.Field {
+	return v.field
+}
+
+// Len returns the number of elements of a vector or list, and 0 for other types
+func (v *DynamicValue) Len() int {
+	if v.elemSize > 0 {
+		return len(v.data) / v.elemSize
+	}
+	if v.field.Type == ssz.TypeVector || v.field.Type == ssz.TypeList {
+		return len(v.elems)
+	}
+	return 0
+}
+
+// Selector returns the selected option of a union
+func (v *DynamicValue) Selector() uint8 {
+	return v.selector
+}
+
+// Select switches a union to the option at selector, set to its zero value
+func (v *DynamicValue) Select(selector uint8) error {
+	if v.field.Type != ssz.TypeUnion {
+		return fmt.Errorf("'%s' is a %s, not a union", v.field.Name, v.field.Type)
+	}
+	if selector > maxUnionSelector || int(selector) >= len(v.field.Children) {
+		return fmt.Errorf("union '%s' has no option %d", v.field.Name, selector)
+	}
+	option, err := newDynamic(v.field.Children[selector], v.refs)
+	if err != nil {
+		return err
+	}
+	v.selector, v.elems = selector, []*DynamicValue{option}
+	return nil
+}
+
+// Get returns the value at path, or v itself for an empty path. Byte slices and uint256s are
+// copies, DynamicValues are shared with v.
+func (v *DynamicValue) Get(path string) (any, error) {
+	parent, last, err := v.parent(path)
+	if err != nil {
+		return nil, dynamicPathError(path, err)
+	}
+	if last == "" {
+		return parent.export(), nil
+	}
+	if parent.elemSize > 0 && isIndexSegment(last) {
+		i, err := parseIndexSegment(last, parent.Len())
+		if err != nil {
+			return nil, dynamicPathError(path, err)
+		}
+		return readDynamicBasic(parent.elem.Type, parent.data[i*parent.elemSize:(i+1)*parent.elemSize])
+	}
+	child, err := parent.child(last)
+	if err != nil {
+		return nil, dynamicPathError(path, err)
+	}
+	return child.export(), nil
+}
+
+// Set sets the value at path, or v itself for an empty path. DynamicValues must have the same
+// type as the value they replace, and are copied.
+func (v *DynamicValue) Set(path string, value any) error {
+	parent, last, err := v.parent(path)
+	if err != nil {
+		return dynamicPathError(path, err)
+	}
+	if last == "" {
+		err = parent.set(value)
+	} else if parent.elemSize > 0 && isIndexSegment(last) {
+		var i int
+		if i, err = parseIndexSegment(last, parent.Len()); err == nil {
+			err = parent.setElement(i, value)
+		}
+	} else {
+		var child *DynamicValue
+		if child, err = parent.child(last); err == nil {
+			err = child.set(value)
+		}
+	}
+	if err != nil {
+		return dynamicPathError(path, err)
+	}
+	return nil
+}
+
+// Append appends value to the list at path, see Set
+func (v *DynamicValue) Append(path string, value any) error {
+	list, err := v.node(path)
+	if err == nil {
+		err = list.append(value)
+	}
+	if err != nil {
+		return dynamicPathError(path, err)
+	}
+	return nil
+}
+
+func (v *DynamicValue) append(value any) error {
+	if v.field.Type != ssz.TypeList {
+		return fmt.Errorf("'%s' is a %s, not a list", v.field.Name, v.field.Type)
+	}
+	if uint64(v.Len()) >= v.field.Limit {
+		return fmt.Errorf("list length %d exceeds limit %d", v.Len()+1, v.field.Limit)
+	}
+	if v.elemSize > 0 {
+		v.data = append(v.data, make([]byte, v.elemSize)...)
+		if err := v.setElement(v.Len()-1, value); err != nil {
+			v.data = v.data[:len(v.data)-v.elemSize]
+			return err
+		}
+		return nil
+	}
+	elem, err := newDynamic(v.elem, v.refs)
+	if err != nil {
+		return err
+	}
+	if err := elem.set(value); err != nil {
+		return err
+	}
+	v.elems = append(v.elems, elem)
+	return nil
+}
+
+// parent returns the value holding the last segment of path, and that segment, which is empty
+// for an empty path
+func (v *DynamicValue) parent(path string) (*DynamicValue, string, error) {
+	segments, err := splitDynamicPath(path)
+	if err != nil || len(segments) == 0 {
+		return v, "", err
+	}
+	parent, err := v.walk(segments[:len(segments)-1])
+	return parent, segments[len(segments)-1], err
+}
+
+// node returns the DynamicValue at path
+func (v *DynamicValue) node(path string) (*DynamicValue, error) {
+	segments, err := splitDynamicPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return v.walk(segments)
+}
+
+func (v *DynamicValue) walk(segments []string) (*DynamicValue, error) {
+	for _, segment := range segments {
+		child, err := v.child(segment)
+		if err != nil {
+			return nil, err
+		}
+		v = child
+	}
+	return v, nil
+}
+
+// child returns the field, element or union option named by segment
+func (v *DynamicValue) child(segment string) (*DynamicValue, error) {
+	if isIndexSegment(segment) {
+		if v.field.Type != ssz.TypeVector && v.field.Type != ssz.TypeList {
+			return nil, fmt.Errorf("'%s' is a %s, not a vector or list", v.field.Name, v.field.Type)
+		}
+		if v.elemSize > 0 {
+			return nil, fmt.Errorf("element %s of '%s' is a %s, which has no fields", segment, v.field.Name, v.elem.Type)
+		}
+		i, err := parseIndexSegment(segment, len(v.elems))
+		if err != nil {
+			return nil, err
+		}
+		return v.elems[i], nil
+	}
+	switch v.field.Type {
+	case ssz.TypeContainer:
+		for i, child := range v.field.Children {
+			if child.Name == segment {
+				return v.elems[i], nil
+			}
+		}
+	case ssz.TypeUnion:
+		for i, option := range v.field.Children {
+			if option.Name != segment {
+				continue
+			}
+			if i != int(v.selector) {
+				return nil, fmt.Errorf("union '%s' has option %d selected, not %s", v.field.Name, v.selector, segment)
+			}
+			return v.elems[0], nil
+		}
+	}
+	return nil, fmt.Errorf("%s '%s' has no field %s", v.field.Type, v.field.Name, segment)
+}
+
+// export returns v as Get returns it
+func (v *DynamicValue) export() any {
+	if n, ok := v.basic.(*uint256.Int); ok {
+		return n.Clone()
+	}
+	if v.basic != nil {
+		return v.basic
+	}
+	if v.isBytes() {
+		return bytes.Clone(v.data)
+	}
+	return v
+}
+
+// isBytes reports whether v is set from a []byte
+func (v *DynamicValue) isBytes() bool {
+	return v.field.Type == ssz.TypeBitVector || v.field.Type == ssz.TypeBitList || v.elem.Type == ssz.TypeUint8
+}
+
+// set replaces v with value
+func (v *DynamicValue) set(value any) error {
+	if other, ok := value.(*DynamicValue); ok {
+		if other == nil || !sameDynamicType(v.field, other.field, v.refs, other.refs, make(map[[2]string]bool)) {
+			return fmt.Errorf("cannot set %s '%s' to a value of another type", v.field.Type, v.field.Name)
+		}
+		name := v.field.Name
+		*v = *other.clone()
+		v.field.Name = name
+		return nil
+	}
+	switch {
+	case v.basic != nil:
+		basic, err := dynamicBasic(v.field.Type, value)
+		if err != nil {
+			return err
+		}
+		v.basic = basic
+	case v.isBytes():
+		bs, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("%s '%s' is set from a []byte, got %T", v.field.Type, v.field.Name, value)
+		}
+		if err := v.checkData(bs); err != nil {
+			return err
+		}
+		v.data = bytes.Clone(bs)
+	default:
+		return fmt.Errorf("%s '%s' is set from a *DynamicValue, got %T", v.field.Type, v.field.Name, value)
+	}
+	return nil
+}
+
+// setElement sets element i of a vector or list of basic values
+func (v *DynamicValue) setElement(i int, value any) error {
+	basic, err := dynamicBasic(v.elem.Type, value)
+	if err != nil {
+		return err
+	}
+	putDynamicBasic(v.data[i*v.elemSize:(i+1)*v.elemSize], basic)
+	return nil
+}
+
+func (v *DynamicValue) clone() *DynamicValue {
+	c := *v
+	if n, ok := v.basic.(*uint256.Int); ok {
+		c.basic = n.Clone()
+	}
+	c.data = bytes.Clone(v.data)
+	if v.elems != nil {
+		c.elems = make([]*DynamicValue, len(v.elems))
+		for i, elem := range v.elems {
+			c.elems[i] = elem.clone()
+		}
+	}
+	return &c
+}
+
+// sameDynamicType reports whether a and b describe the same type, with the same field names.
+// seen holds the pairs of refs being compared, so recursive types terminate.
+func sameDynamicType(a, b ssz.Field, aRefs, bRefs map[string]ssz.Field, seen map[[2]string]bool) bool {
+	if a.Type == ssz.TypeRef && b.Type == ssz.TypeRef {
+		key := [2]string{a.Ref, b.Ref}
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+	}
+	a, errA := resolveRef(a, aRefs)
+	b, errB := resolveRef(b, bRefs)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if a.Type != b.Type || a.Size != b.Size || a.Limit != b.Limit || len(a.Children) != len(b.Children) {
+		return false
+	}
+	for i := range a.Children {
+		if a.Type != ssz.TypeVector && a.Type != ssz.TypeList && a.Children[i].Name != b.Children[i].Name {
+			return false
+		}
+		if !sameDynamicType(a.Children[i], b.Children[i], aRefs, bRefs, seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// dynamicBasic converts value to the representation of basic type t
+func dynamicBasic(t ssz.TypeName, value any) (any, error) {
+	switch t {
+	case ssz.TypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("boolean is set from a bool, got %T", value)
+		}
+		return b, nil
+	case ssz.TypeUint128, ssz.TypeUint256:
+		var n *uint256.Int
+		switch value := value.(type) {
+		case *uint256.Int:
+			if value == nil {
+				return nil, fmt.Errorf("%s is set from a nil *uint256.Int", t)
+			}
+			n = value.Clone()
+		case uint256.Int:
+			n = value.Clone()
+		default:
+			u, err := dynamicUint(value)
+			if err != nil {
+				return nil, err
+			}
+			n = uint256.NewInt(u)
+		}
+		if t == ssz.TypeUint128 && n.BitLen() > 128 {
+			return nil, fmt.Errorf("%v does not fit in a uint128", n)
+		}
+		return n, nil
+	default:
+		u, err := dynamicUint(value)
+		if err != nil {
+			return nil, err
+		}
+		if size := dynamicBasicSize(t); size < 8 && u>>(8*size) != 0 {
+			return nil, fmt.Errorf("%d does not fit in a %s", u, t)
+		}
+		return u, nil
+	}
+}
+
+// dynamicUint converts a Go integer to a uint64
+func dynamicUint(value any) (uint64, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			return 0, fmt.Errorf("unsigned integer cannot be set to %d", rv.Int())
+		}
+		return uint64(rv.Int()), nil
+	}
+	return 0, fmt.Errorf("unsigned integer is set from an integer, got %T", value)
+}
+
+// putDynamicBasic writes a basic value to dst, which has the size of its type
+func putDynamicBasic(dst []byte, basic any) {
+	switch basic := basic.(type) {
+	case bool:
+		dst[0] = 0
+		if basic {
+			dst[0] = 1
+		}
+	case *uint256.Int:
+		putUint256LE(dst, basic)
+	case uint64:
+		var buf [8]byte
+		order.PutUint64(buf[:], basic)
+		copy(dst, buf[:len(dst)])
+	}
+}
+
+// readDynamicBasic reads a basic value of type t from src, which has the size of t
+func readDynamicBasic(t ssz.TypeName, src []byte) (any, error) {
+	switch t {
+	case ssz.TypeBoolean:
+		if src[0] > 1 {
+			return nil, fmt.Errorf("%w: boolean byte 0x%02x", ErrNonCanonical, src[0])
+		}
+		return src[0] == 1, nil
+	case ssz.TypeUint128, ssz.TypeUint256:
+		n := new(uint256.Int)
+		for limb := 0; limb*8 < len(src); limb++ {
+			n[limb] = order.Uint64(src[limb*8:])
+		}
+		return n, nil
+	default:
+		var buf [8]byte
+		copy(buf[:], src)
+		return order.Uint64(buf[:]), nil
+	}
+}
+
+// checkData checks the bytes of a bitfield, or the packed elements of a vector or list
+func (v *DynamicValue) checkData(data []byte) error {
+	switch v.field.Type {
+	case ssz.TypeBitVector:
+		if uint64(len(data)) != (v.field.Size+7)/8 {
+			return fmt.Errorf("bitvector of %d bits has %d bytes, expected %d", v.field.Size, len(data), (v.field.Size+7)/8)
+		}
+		if bits := v.field.Size % 8; bits != 0 && data[len(data)-1]>>bits != 0 {
+			return fmt.Errorf("bitvector has bits set past its length %d", v.field.Size)
+		}
+		return nil
+	case ssz.TypeBitList:
+		return ValidateBitlist(data, v.field.Limit)
+	default:
+		if len(data)%v.elemSize != 0 {
+			return fmt.Errorf("%d bytes cannot be divided by element size %d", len(data), v.elemSize)
+		}
+		if v.elem.Type == ssz.TypeBoolean {
+			// Elements are checked like a single boolean, see readDynamicBasic
+			for i, b := range data {
+				if b > 1 {
+					return fmt.Errorf("%w: boolean byte 0x%02x of element %d", ErrNonCanonical, b, i)
+				}
+			}
+		}
+		return v.checkLength(len(data) / v.elemSize)
+	}
+}
+
+// checkLength checks the number of elements of a vector or list
+func (v *DynamicValue) checkLength(n int) error {
+	if v.field.Type == ssz.TypeVector && uint64(n) != v.field.Size {
+		return fmt.Errorf("vector has %d elements, expected %d", n, v.field.Size)
+	}
+	if v.field.Type == ssz.TypeList && uint64(n) > v.field.Limit {
+		return fmt.Errorf("list length %d exceeds limit %d", n, v.field.Limit)
+	}
+	return nil
+}
+
+// fixedSize returns the size of a fixed-size value
+func (v *DynamicValue) fixedSize() int {
+	if v.basic != nil {
+		return dynamicBasicSize(v.field.Type)
+	}
+	if v.elems == nil {
+		return len(v.data)
+	}
+	size := 0
+	for _, elem := range v.elems {
+		size += elem.fixedSize()
+	}
+	return size
+}
+
+// MarshalSSZ returns the serialized form of v
+func (v *DynamicValue) MarshalSSZ() ([]byte, error) {
+	return v.appendSSZ(nil)
+}
+
+func (v *DynamicValue) appendSSZ(dst []byte) ([]byte, error) {
+	switch {
+	case v.basic != nil:
+		var buf [32]byte
+		size := dynamicBasicSize(v.field.Type)
+		putDynamicBasic(buf[:size], v.basic)
+		return append(dst, buf[:size]...), nil
+	case v.field.Type == ssz.TypeUnion:
+		return v.elems[0].appendSSZ(append(dst, v.selector))
+	case v.elems == nil:
+		// Bitfields, vectors and lists of basic values, and empty lists
+		return append(dst, v.data...), nil
+	default:
+		return appendDynamicElements(dst, v.elems)
+	}
+}
+
+// appendDynamicElements appends the fields of a container or the elements of a vector or list:
+// the fixed-size values and the offsets of the variable-size ones, then the variable-size values
+func appendDynamicElements(dst []byte, elems []*DynamicValue) ([]byte, error) {
+	start := len(dst)
+	var offsets []int
+	var err error
+	for _, elem := range elems {
+		if elem.variable {
+			offsets = append(offsets, len(dst))
+			dst = append(dst, 0, 0, 0, 0)
+			continue
+		}
+		if dst, err = elem.appendSSZ(dst); err != nil {
+			return nil, err
+		}
+	}
+	for _, elem := range elems {
+		if !elem.variable {
+			continue
+		}
+		offset := len(dst) - start
+		if uint64(offset) > math.MaxUint32 {
+			return nil, fmt.Errorf("offset %d does not fit in a uint32", offset)
+		}
+		order.PutUint32(dst[offsets[0]:], uint32(offset))
+		offsets = offsets[1:]
+		if dst, err = elem.appendSSZ(dst); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+// UnmarshalSSZ replaces v with the value serialized in data. Errors are DecodeErrors with the
+// path of the failing field.
+func (v *DynamicValue) UnmarshalSSZ(data []byte) error {
+	decoded, err := newDynamic(v.field, v.refs)
+	if err != nil {
+		return err
+	}
+	if err := decoded.decode(data, 0); err != nil {
+		return asDecodeError(err)
+	}
+	*v = *decoded
+	return nil
+}
+
+// decode decodes data, which starts at base in the input, into v, which is a zero value
+func (v *DynamicValue) decode(data []byte, base int) error {
+	switch {
+	case v.basic != nil:
+		if size := dynamicBasicSize(v.field.Type); len(data) != size {
+			return fmt.Errorf("%s has %d bytes, expected %d", v.field.Type, len(data), size)
+		}
+		basic, err := readDynamicBasic(v.field.Type, data)
+		if err != nil {
+			return err
+		}
+		v.basic = basic
+		return nil
+	case v.field.Type == ssz.TypeContainer:
+		return v.decodeFields(data, base)
+	case v.field.Type == ssz.TypeUnion:
+		return v.decodeUnion(data, base)
+	case v.elemSize == 0 && v.field.Type != ssz.TypeBitVector && v.field.Type != ssz.TypeBitList:
+		return v.decodeElements(data, base)
+	default:
+		if err := v.checkData(data); err != nil {
+			return err
+		}
+		v.data = bytes.Clone(data)
+		return nil
+	}
+}
+
+func (v *DynamicValue) decodeFields(data []byte, base int) error {
+	pos := 0
+	var offsets, variable []int
+	for i, field := range v.elems {
+		name := v.field.Children[i].Name
+		if field.variable {
+			if len(data)-pos < 4 {
+				return wrapDecodeError(fmt.Errorf("offset: ssz: %w", io.ErrUnexpectedEOF), name, base+pos)
+			}
+			raw := order.Uint32(data[pos:])
+			offset, ok := offsetWithin(raw, len(data))
+			if !ok {
+				return wrapDecodeError(fmt.Errorf("invalid offset %d: past the end of the data (%d)", raw, len(data)), name, base+pos)
+			}
+			offsets = append(offsets, offset)
+			variable = append(variable, i)
+			pos += 4
+			continue
+		}
+		size := field.fixedSize()
+		if len(data)-pos < size {
+			return wrapDecodeError(fmt.Errorf("ssz: %w", io.ErrUnexpectedEOF), name, base+pos)
+		}
+		if err := field.decode(data[pos:pos+size], base+pos); err != nil {
+			return wrapDecodeError(err, name, base+pos)
+		}
+		pos += size
+	}
+	if len(offsets) == 0 {
+		if pos != len(data) {
+			return fmt.Errorf("%d trailing bytes", len(data)-pos)
+		}
+		return nil
+	}
+	if offsets[0] != pos {
+		return fmt.Errorf("first offset %d does not match end of fixed part %d", offsets[0], pos)
+	}
+	for j, i := range variable {
+		start, end := offsets[j], len(data)
+		if j+1 < len(offsets) {
+			end = offsets[j+1]
+		}
+		name := v.field.Children[i].Name
+		if start > end {
+			return wrapDecodeError(fmt.Errorf("invalid offset: start=%d, end=%d, len=%d", start, end, len(data)), name, base+start)
+		}
+		if err := v.elems[i].decode(data[start:end], base+start); err != nil {
+			return wrapDecodeError(err, name, base+start)
+		}
+	}
+	return nil
+}
+
+func (v *DynamicValue) decodeElements(data []byte, base int) error {
+	first, err := newDynamic(v.elem, v.refs)
+	if err != nil {
+		return err
+	}
+	if !first.variable {
+		size := first.fixedSize()
+		if size == 0 || len(data)%size != 0 {
+			return fmt.Errorf("%d bytes cannot be divided by element size %d", len(data), size)
+		}
+		if err := v.checkLength(len(data) / size); err != nil {
+			return err
+		}
+		v.elems = make([]*DynamicValue, len(data)/size)
+		for i := range v.elems {
+			elem := first
+			if i > 0 {
+				if elem, err = newDynamic(v.elem, v.refs); err != nil {
+					return err
+				}
+			}
+			if err := elem.decode(data[i*size:(i+1)*size], base+i*size); err != nil {
+				return wrapDecodeError(err, indexSegment(i), base+i*size)
+			}
+			v.elems[i] = elem
+		}
+		return nil
+	}
+
+	if len(data) == 0 {
+		v.elems = nil
+		return v.checkLength(0)
+	}
+	if len(data) < 4 {
+		return fmt.Errorf("invalid data for variable-size elements: less than 4 bytes")
+	}
+	// The offsets are part of the data, so the first one bounds the number of elements
+	firstOffset := order.Uint32(data)
+	if firstOffset == 0 || firstOffset%4 != 0 || uint64(firstOffset) > uint64(len(data)) {
+		return fmt.Errorf("invalid first offset %d for %d bytes", firstOffset, len(data))
+	}
+	count := int(firstOffset / 4)
+	if err := v.checkLength(count); err != nil {
+		return err
+	}
+	offsets := make([]int, count+1)
+	for i := 0; i < count; i++ {
+		raw := order.Uint32(data[4*i:])
+		offset, ok := offsetWithin(raw, len(data))
+		if !ok || offset < 4*count || i > 0 && offset < offsets[i-1] {
+			return wrapDecodeError(fmt.Errorf("invalid offset %d", raw), indexSegment(i), base+4*i)
+		}
+		offsets[i] = offset
+	}
+	offsets[count] = len(data)
+	v.elems = make([]*DynamicValue, count)
+	for i := range v.elems {
+		elem := first
+		if i > 0 {
+			if elem, err = newDynamic(v.elem, v.refs); err != nil {
+				return err
+			}
+		}
+		start, end := offsets[i], offsets[i+1]
+		if err := elem.decode(data[start:end], base+start); err != nil {
+			return wrapDecodeError(err, indexSegment(i), base+start)
+		}
+		v.elems[i] = elem
+	}
+	return nil
+}
+
+func (v *DynamicValue) decodeUnion(data []byte, base int) error {
+	if len(data) == 0 {
+		return fmt.Errorf("union selector: ssz: %w", io.ErrUnexpectedEOF)
+	}
+	if err := v.Select(data[0]); err != nil {
+		return err
+	}
+	name := v.field.Children[v.selector].Name
+	if err := v.elems[0].decode(data[1:], base+1); err != nil {
+		return wrapDecodeError(err, name, base+1)
+	}
+	return nil
+}
+
+// HashTreeRoot returns the hash tree root of v
+func (v *DynamicValue) HashTreeRoot() ([32]byte, error) {
+	switch v.field.Type {
+	case ssz.TypeBitVector:
+		return merkle_tree.BitvectorRootWithLimit(v.data, v.field.Size)
+	case ssz.TypeBitList:
+		return merkle_tree.BitlistRootWithLimit(v.data, v.field.Limit)
+	case ssz.TypeUnion:
+		root, err := v.elems[0].HashTreeRoot()
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return mixInSelector(root, v.selector), nil
+	case ssz.TypeVector, ssz.TypeList, ssz.TypeContainer:
+	default:
+		var chunk [32]byte
+		putDynamicBasic(chunk[:dynamicBasicSize(v.field.Type)], v.basic)
+		return chunk, nil
+	}
+
+	limit := uint64(len(v.elems))
+	switch v.field.Type {
+	case ssz.TypeVector:
+		limit = v.field.Size
+	case ssz.TypeList:
+		limit = v.field.Limit
+	}
+	var root [32]byte
+	var err error
+	if v.elemSize > 0 {
+		// Rounded up without adding first, which overflows for limits close to 2^64
+		perChunk := uint64(BYTES_PER_CHUNK / v.elemSize)
+		chunkLimit := limit / perChunk
+		if limit%perChunk != 0 {
+			chunkLimit++
+		}
+		root, err = Merkleize(PackBytes(v.data), chunkLimit)
+	} else {
+		roots := make([][32]byte, len(v.elems))
+		for i, elem := range v.elems {
+			if roots[i], err = elem.HashTreeRoot(); err != nil {
+				return [32]byte{}, err
+			}
+		}
+		root, err = Merkleize(roots, limit)
+	}
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if v.field.Type == ssz.TypeList {
+		return MixInLength(root, uint64(v.Len())), nil
+	}
+	return root, nil
+}
+
+// dynamicPathError adds path to the errors of Get, Set and Append
+func dynamicPathError(path string, err error) error {
+	if path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// splitDynamicPath splits a path like "body.deposits[3].data" into the segments "body",
+// "deposits", "[3]" and "data"
+func splitDynamicPath(path string) ([]string, error) {
+	var segments []string
+	for path != "" {
+		switch path[0] {
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in path")
+			}
+			segments = append(segments, path[:end+1])
+			path = path[end+1:]
+		case '.':
+			if len(segments) == 0 || len(path) == 1 || path[1] == '.' || path[1] == '[' {
+				return nil, fmt.Errorf("empty field name in path")
+			}
+			path = path[1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, path[:end])
+			path = path[end:]
+		}
+	}
+	return segments, nil
+}
+
+// isIndexSegment reports whether segment is an index like "[3]"
+func isIndexSegment(segment string) bool {
+	return strings.HasPrefix(segment, "[")
+}
+
+// parseIndexSegment returns the index of a segment like "[3]", which must be less than n
+func parseIndexSegment(segment string, n int) (int, error) {
+	i, err := strconv.Atoi(segment[1 : len(segment)-1])
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid index %s", segment)
+	}
+	if i >= n {
+		return 0, NewErrIndexOutOfBounds(i, n)
+	}
+	return i, nil
+}
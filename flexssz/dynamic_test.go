@@ -0,0 +1,335 @@
+package flexssz
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dynamicTestSchema = `
+- name: Checkpoint
+  type: container
+  children:
+    - {name: epoch, type: uint64}
+    - {name: root, type: bytevector, size: 32}
+- name: Attestation
+  type: container
+  children:
+    - {name: aggregation_bits, type: bitlist, limit: 2048}
+    - {name: slot, type: uint64}
+    - {name: source, type: ref, ref: Checkpoint}
+    - {name: indices, type: list, limit: 16, children: [{type: uint64}]}
+    - {name: flags, type: bitvector, size: 12}
+    - {name: enabled, type: boolean}
+    - {name: balance, type: uint256}
+    - {name: extra, type: list, limit: 32, children: [{type: uint8}]}
+- name: Block
+  type: container
+  children:
+    - {name: proposer, type: uint32}
+    - {name: attestations, type: list, limit: 8, children: [{type: ref, ref: Attestation}]}
+    - {name: checkpoints, type: vector, size: 2, children: [{type: ref, ref: Checkpoint}]}
+`
+
+type dynamicTestCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type dynamicTestAttestation struct {
+	AggregationBits []byte `ssz:"bitlist" ssz-max:"2048"`
+	Slot            uint64
+	Source          dynamicTestCheckpoint
+	Indices         []uint64 `ssz-max:"16"`
+	Flags           []byte   `ssz:"bitvector" ssz-size:"12"`
+	Enabled         bool
+	Balance         uint256.Int `ssz:"uint256"`
+	Extra           []byte      `ssz-max:"32"`
+}
+
+type dynamicTestBlock struct {
+	Proposer     uint32
+	Attestations []*dynamicTestAttestation `ssz-max:"8"`
+	Checkpoints  [2]dynamicTestCheckpoint
+}
+
+func dynamicTestTypes(t *testing.T) map[string]ssz.Field {
+	types, err := ssz.ParseSchemaYAML([]byte(dynamicTestSchema))
+	require.NoError(t, err)
+	return types
+}
+
+func newDynamicTestBlock() *dynamicTestBlock {
+	return &dynamicTestBlock{
+		Proposer: 7,
+		Attestations: []*dynamicTestAttestation{
+			{
+				AggregationBits: []byte{0x0b},
+				Slot:            12,
+				Source:          dynamicTestCheckpoint{Epoch: 3, Root: [32]byte{1, 2}},
+				Indices:         []uint64{5, 6, 7},
+				Flags:           []byte{0xff, 0x0f},
+				Enabled:         true,
+				Balance:         *uint256.NewInt(1 << 40),
+				Extra:           []byte("ssz"),
+			},
+			{AggregationBits: []byte{0x01}, Flags: []byte{0, 0}, Indices: []uint64{}, Extra: []byte{}},
+		},
+		Checkpoints: [2]dynamicTestCheckpoint{{Epoch: 1}, {Epoch: 2, Root: [32]byte{9}}},
+	}
+}
+
+func TestDynamicValue_RoundTrip(t *testing.T) {
+	types := dynamicTestTypes(t)
+	block := newDynamicTestBlock()
+	encoded, err := Marshal(block)
+	require.NoError(t, err)
+	root, err := HashTreeRoot(block)
+	require.NoError(t, err)
+
+	v, err := NewDynamicValue(types["Block"], types)
+	require.NoError(t, err)
+	require.NoError(t, v.UnmarshalSSZ(encoded))
+
+	reencoded, err := v.MarshalSSZ()
+	require.NoError(t, err)
+	assert.Equal(t, encoded, reencoded)
+	dynamicRoot, err := v.HashTreeRoot()
+	require.NoError(t, err)
+	assert.Equal(t, root, dynamicRoot)
+
+	get := func(path string) any {
+		value, err := v.Get(path)
+		require.NoError(t, err, path)
+		return value
+	}
+	assert.Equal(t, uint64(7), get("proposer"))
+	assert.Equal(t, uint64(3), get("attestations[0].source.epoch"))
+	assert.Equal(t, []byte{1, 2, 0, 0}, get("attestations[0].source.root").([]byte)[:4])
+	assert.Equal(t, uint64(6), get("attestations[0].indices[1]"))
+	assert.Equal(t, []byte{0x0b}, get("attestations[0].aggregation_bits"))
+	assert.Equal(t, true, get("attestations[0].enabled"))
+	assert.Equal(t, uint256.NewInt(1<<40), get("attestations[0].balance"))
+	assert.Equal(t, []byte("ssz"), get("attestations[0].extra"))
+	assert.Equal(t, uint64('s'), get("attestations[0].extra[0]"))
+	assert.Equal(t, uint64(2), get("checkpoints[1].epoch"))
+
+	attestations := get("attestations").(*DynamicValue)
+	assert.Equal(t, 2, attestations.Len())
+	assert.Equal(t, 3, get("attestations[0].indices").(*DynamicValue).Len())
+}
+
+func TestDynamicValue_Set(t *testing.T) {
+	types := dynamicTestTypes(t)
+	v, err := NewDynamicValue(types["Block"], types)
+	require.NoError(t, err)
+
+	// The zero value encodes and hashes like the zero Go value, with empty bitlists
+	zero := &dynamicTestBlock{}
+	encoded, err := v.MarshalSSZ()
+	require.NoError(t, err)
+	expected, err := Marshal(zero)
+	require.NoError(t, err)
+	assert.Equal(t, expected, encoded)
+
+	// Build the test block through paths
+	require.NoError(t, v.Set("proposer", 7))
+	attestation, err := NewDynamicValue(types["Attestation"], types)
+	require.NoError(t, err)
+	require.NoError(t, attestation.Set("aggregation_bits", []byte{0x0b}))
+	require.NoError(t, attestation.Set("slot", uint64(12)))
+	require.NoError(t, attestation.Set("source.epoch", uint8(3)))
+	require.NoError(t, attestation.Set("source.root", append([]byte{1, 2}, make([]byte, 30)...)))
+	for _, index := range []uint64{5, 6, 0} {
+		require.NoError(t, attestation.Append("indices", index))
+	}
+	require.NoError(t, attestation.Set("indices[2]", 7))
+	require.NoError(t, attestation.Set("flags", []byte{0xff, 0x0f}))
+	require.NoError(t, attestation.Set("enabled", true))
+	require.NoError(t, attestation.Set("balance", uint256.NewInt(1<<40)))
+	require.NoError(t, attestation.Set("extra", []byte("ssz")))
+	require.NoError(t, v.Append("attestations", attestation))
+	empty, err := NewDynamicValue(types["Attestation"], types)
+	require.NoError(t, err)
+	require.NoError(t, v.Append("attestations", empty))
+	require.NoError(t, v.Set("checkpoints[0].epoch", 1))
+	require.NoError(t, v.Set("checkpoints[1].epoch", 2))
+	require.NoError(t, v.Set("checkpoints[1].root[0]", 9))
+
+	// Appended values are copies
+	require.NoError(t, attestation.Set("slot", 13))
+
+	block := newDynamicTestBlock()
+	encoded, err = v.MarshalSSZ()
+	require.NoError(t, err)
+	expected, err = Marshal(block)
+	require.NoError(t, err)
+	assert.Equal(t, expected, encoded)
+	root, err := v.HashTreeRoot()
+	require.NoError(t, err)
+	expectedRoot, err := HashTreeRoot(block)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	tests := map[string]struct {
+		path  string
+		value any
+		err   string
+	}{
+		"unknown field":  {"attestations[0].target", 1, "has no field target"},
+		"index":          {"attestations[2].slot", 1, "index out of bounds: 2 (2)"},
+		"bad path":       {"attestations[0]..slot", 1, "empty field name"},
+		"too large":      {"proposer", uint64(1 << 32), "does not fit in a uint32"},
+		"negative":       {"proposer", -1, "cannot be set to -1"},
+		"not an integer": {"proposer", "7", "set from an integer, got string"},
+		"bool":           {"attestations[0].enabled", 1, "set from a bool"},
+		"byte vector":    {"checkpoints[0].root", []byte{1}, "vector has 1 elements, expected 32"},
+		"byte list":      {"attestations[0].extra", make([]byte, 33), "list length 33 exceeds limit 32"},
+		"bitvector":      {"attestations[0].flags", []byte{0, 0x10}, "bits set past its length 12"},
+		"bitlist":        {"attestations[0].aggregation_bits", []byte{0}, "trailing byte is zero"},
+		"other type":     {"checkpoints[0]", attestation, "to a value of another type"},
+		"composite":      {"checkpoints", []byte{1}, "set from a *DynamicValue"},
+		"no fields":      {"proposer.epoch", 1, "has no field epoch"},
+		"basic element":  {"attestations[0].indices[0].epoch", 1, "has no fields"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, v.Set(tt.path, tt.value), tt.err)
+		})
+	}
+
+	for i := 2; i < 8; i++ {
+		require.NoError(t, v.Append("attestations", empty))
+	}
+	assert.ErrorContains(t, v.Append("attestations", empty), "list length 9 exceeds limit 8")
+	assert.ErrorContains(t, v.Append("checkpoints", empty), "not a list")
+}
+
+func TestDynamicValue_UnmarshalErrors(t *testing.T) {
+	types := dynamicTestTypes(t)
+	block := newDynamicTestBlock()
+	encoded, err := Marshal(block)
+	require.NoError(t, err)
+
+	v, err := NewDynamicValue(types["Block"], types)
+	require.NoError(t, err)
+
+	// The bitlist of the second attestation loses its delimiter bit
+	bad := append([]byte{}, encoded...)
+	bad[len(bad)-1] = 0
+	err = v.UnmarshalSSZ(bad)
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "attestations[1].aggregation_bits", decodeErr.Path)
+
+	err = v.UnmarshalSSZ(encoded[:len(encoded)-100])
+	assert.Error(t, err)
+
+	// A failed decode leaves the value as it was
+	assert.Equal(t, 0, mustDynamicGet(t, v, "attestations").(*DynamicValue).Len())
+	require.NoError(t, v.UnmarshalSSZ(encoded))
+	assert.Equal(t, 2, mustDynamicGet(t, v, "attestations").(*DynamicValue).Len())
+}
+
+func TestDynamicValue_LargeLimit(t *testing.T) {
+	// Limits close to 2^64 are rounded up to chunks without overflowing
+	data := ssz.Field{Name: "data", Type: ssz.TypeList, Limit: math.MaxUint64, Children: []ssz.Field{{Name: "byte", Type: ssz.TypeUint8}}}
+	v, err := NewDynamicValue(data, nil)
+	require.NoError(t, err)
+	require.NoError(t, v.UnmarshalSSZ([]byte{1, 2, 3}))
+	root, err := v.HashTreeRoot()
+	require.NoError(t, err)
+	chunksRoot, err := Merkleize(PackBytes([]byte{1, 2, 3}), math.MaxUint64/32+1)
+	require.NoError(t, err)
+	assert.Equal(t, MixInLength(chunksRoot, 3), root)
+}
+
+func TestDynamicValue_BooleanElements(t *testing.T) {
+	// Booleans in vectors and lists are checked like a single boolean
+	flags := ssz.Field{Name: "flags", Type: ssz.TypeVector, Size: 3, Children: []ssz.Field{{Name: "flag", Type: ssz.TypeBoolean}}}
+	v, err := NewDynamicValue(flags, nil)
+	require.NoError(t, err)
+	require.NoError(t, v.UnmarshalSSZ([]byte{1, 0, 1}))
+	assert.ErrorIs(t, v.UnmarshalSSZ([]byte{2, 0, 1}), ErrNonCanonical)
+	var decoded [3]bool
+	assert.Error(t, UnmarshalStrict([]byte{2, 0, 1}, &decoded))
+}
+
+func TestDynamicValue_Union(t *testing.T) {
+	types := dynamicTestTypes(t)
+	field := ssz.Field{Name: "Payload", Type: ssz.TypeUnion, Children: []ssz.Field{
+		{Name: "number", Type: ssz.TypeUint64},
+		{Name: "checkpoint", Type: ssz.TypeRef, Ref: "Checkpoint"},
+	}}
+	v, err := NewDynamicValue(field, types)
+	require.NoError(t, err)
+
+	require.NoError(t, v.Set("number", 5))
+	root, err := v.HashTreeRoot()
+	require.NoError(t, err)
+	expected, err := HashTreeRoot(Union{Selector: 0, Value: uint64(5)})
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+
+	assert.ErrorContains(t, v.Set("checkpoint.epoch", 1), "has option 0 selected")
+	require.NoError(t, v.Select(1))
+	require.NoError(t, v.Set("checkpoint.epoch", 3))
+	root, err = v.HashTreeRoot()
+	require.NoError(t, err)
+	expected, err = HashTreeRoot(Union{Selector: 1, Value: dynamicTestCheckpoint{Epoch: 3}})
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+
+	encoded, err := v.MarshalSSZ()
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), encoded[0])
+	decoded, err := NewDynamicValue(field, types)
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalSSZ(encoded))
+	assert.Equal(t, uint8(1), decoded.Selector())
+	assert.Equal(t, uint64(3), mustDynamicGet(t, decoded, "checkpoint.epoch"))
+
+	assert.ErrorContains(t, decoded.UnmarshalSSZ([]byte{2}), "has no option 2")
+	assert.ErrorContains(t, v.Select(2), "has no option 2")
+}
+
+func TestNewDynamicValue_Unsupported(t *testing.T) {
+	_, err := NewDynamicValue(ssz.Field{Name: "Maybe", Type: ssz.TypeOptional, Children: []ssz.Field{{Name: "value", Type: ssz.TypeUint64}}}, nil)
+	assert.ErrorContains(t, err, "not supported by dynamic values")
+	_, err = NewDynamicValue(ssz.Field{Name: "Missing", Type: ssz.TypeRef, Ref: "Nothing"}, nil)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestNewDynamicValue_LargeVector(t *testing.T) {
+	point := ssz.Field{Name: "Point", Type: ssz.TypeContainer, Children: []ssz.Field{{Name: "x", Type: ssz.TypeUint64}}}
+	refs := map[string]ssz.Field{"Point": point}
+	for _, size := range []uint64{1 << 62, 1 << 30} {
+		vector := ssz.Field{Name: "points", Type: ssz.TypeVector, Size: size, Children: []ssz.Field{{Name: "point", Type: ssz.TypeRef, Ref: "Point"}}}
+		require.NoError(t, vector.IsValid(refs))
+		_, err := NewDynamicValue(vector, refs)
+		assert.ErrorContains(t, err, "too large", "size %d", size)
+	}
+
+	// Nested vectors are bounded by the nodes of the whole value
+	inner := ssz.Field{Name: "inner", Type: ssz.TypeVector, Size: 1 << 12, Children: []ssz.Field{{Name: "point", Type: ssz.TypeRef, Ref: "Point"}}}
+	outer := ssz.Field{Name: "outer", Type: ssz.TypeVector, Size: 1 << 12, Children: []ssz.Field{inner}}
+	_, err := NewDynamicValue(outer, refs)
+	assert.ErrorContains(t, err, "too large")
+
+	// Vectors of basic values are packed, whatever their size
+	roots := ssz.Field{Name: "roots", Type: ssz.TypeVector, Size: 8192, Children: []ssz.Field{{Name: "root", Type: ssz.TypeVector, Size: 32, Children: []ssz.Field{{Name: "byte", Type: ssz.TypeUint8}}}}}
+	v, err := NewDynamicValue(roots, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 8192, v.Len())
+}
+
+func mustDynamicGet(t *testing.T, v *DynamicValue, path string) any {
+	t.Helper()
+	value, err := v.Get(path)
+	require.NoError(t, err)
+	return value
+}
@@ -0,0 +1,68 @@
+package flexssz
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EncodeOptions configures MarshalWithOptions
+type EncodeOptions struct {
+	// Validate checks the output before returning it, by decoding it with UnmarshalStrict,
+	// comparing the decoded value to v with Diff and encoding the decoded value again. Struct
+	// tags that do not describe the value, such as an ssz-size that differs from the length of a
+	// slice the encoder does not check, can produce bytes that decode to a different value or do
+	// not decode at all. Such output is not returned, and the error, which wraps ErrNonCanonical,
+	// names the fields that did not round trip. This roughly triples the cost of encoding, so it
+	// is meant for tests and for values that are expensive to get wrong.
+	Validate bool
+}
+
+// MarshalWithOptions encodes v like Marshal, applying opts
+func MarshalWithOptions(v any, opts EncodeOptions) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil || !opts.Validate {
+		return data, err
+	}
+	if err := validateEncoding(v, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// validateEncoding checks that data is the canonical encoding of v
+func validateEncoding(v any, data []byte) error {
+	rv, err := derefRoot(reflect.ValueOf(v))
+	if err != nil {
+		return err
+	}
+	decoded := reflect.New(rv.Type())
+	if err := UnmarshalStrict(data, decoded.Interface()); err != nil {
+		return fmt.Errorf("%w: output does not decode: %w", ErrNonCanonical, err)
+	}
+	diffs, err := Diff(rv.Interface(), decoded.Interface())
+	if err != nil {
+		return err
+	}
+	if len(diffs) > 0 {
+		paths := make([]string, len(diffs))
+		for i, d := range diffs {
+			paths[i] = d.String()
+		}
+		return fmt.Errorf("%w: output decodes to a different value: %s", ErrNonCanonical, strings.Join(paths, "; "))
+	}
+
+	again, err := Marshal(decoded.Interface())
+	if err != nil {
+		return fmt.Errorf("%w: decoded output does not encode: %w", ErrNonCanonical, err)
+	}
+	if !bytes.Equal(data, again) {
+		offset := 0
+		for offset < min(len(data), len(again)) && data[offset] == again[offset] {
+			offset++
+		}
+		return fmt.Errorf("%w: output of %d bytes re-encodes to %d bytes, differing from offset %d", ErrNonCanonical, len(data), len(again), offset)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package flexssz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encodeOptionsTestPair struct {
+	A []uint64 `ssz-size:"2"`
+	B []uint64 `ssz-size:"2"`
+}
+
+func TestMarshalWithOptions_Validate(t *testing.T) {
+	valid := &encodeOptionsTestPair{A: []uint64{1, 2}, B: []uint64{3, 4}}
+	data, err := MarshalWithOptions(valid, EncodeOptions{Validate: true})
+	require.NoError(t, err)
+	expected, err := Marshal(valid)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	// The slices do not match their ssz-size, but the output has the right total size, so it
+	// decodes to a different value
+	shifted := &encodeOptionsTestPair{A: []uint64{1, 2, 3}, B: []uint64{4}}
+	_, err = Marshal(shifted)
+	require.NoError(t, err, "Marshal does not check the sizes")
+	_, err = MarshalWithOptions(shifted, EncodeOptions{Validate: true})
+	require.ErrorIs(t, err, ErrNonCanonical)
+	assert.Contains(t, err.Error(), "A[2]: 3 != <nil>")
+	assert.Contains(t, err.Error(), "B[0]: 4 != 3")
+
+	// Output that does not decode at all
+	short := &encodeOptionsTestPair{A: []uint64{1, 2}, B: []uint64{3}}
+	_, err = MarshalWithOptions(short, EncodeOptions{Validate: true})
+	require.ErrorIs(t, err, ErrNonCanonical)
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "B", decodeErr.Path)
+
+	// Without Validate, the output is returned as is
+	data, err = MarshalWithOptions(short, EncodeOptions{})
+	require.NoError(t, err)
+	assert.Len(t, data, 24)
+}
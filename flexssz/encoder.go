@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"math/bits"
 
 	"github.com/holiman/uint256"
@@ -157,6 +158,27 @@ func (d *Builder) EncodeBytes(xs []byte) *Builder {
 	return d
 }
 
+// EncodeBytesReader is EncodeBytes for n bytes read from r. The bytes are copied from r to the
+// output by Finish, so they are never held in memory, and r must be readable until then.
+func (d *Builder) EncodeBytesReader(r io.Reader, n int) *Builder {
+	d.appendHeap(n, copyReader(r, n))
+	return d
+}
+
+// copyReader returns an EncodeFunc that copies exactly n bytes from r
+func copyReader(r io.Reader, n int) EncodeFunc {
+	return func(w io.Writer) error {
+		copied, err := io.CopyN(w, r, int64(n))
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return fmt.Errorf("ssz: copied %d of %d bytes from reader: %w", copied, n, err)
+		}
+		return nil
+	}
+}
+
 func (d *Builder) EncodeString(s string) *Builder {
 	//TODO: optimize
 	return d.EncodeBytes([]byte(s))
@@ -199,22 +221,31 @@ func (d *Builder) EncodeUint32(i uint32) *Builder {
 func (d *Builder) EncodeUint64(i uint64) *Builder {
 	return d.EncodeBinary(i)
 }
+
+// EncodeUint128 writes the lower two limbs of i as a 16 byte little-endian uint128
 func (d *Builder) EncodeUint128(i *uint256.Int) *Builder {
-	// uint128 uses the lower 2 uint64s (16 bytes)
-	// uint256.Int is [4]uint64 in little-endian order
-	d.EncodeUint64(i[0])
-	d.EncodeUint64(i[1])
+	var buf [16]byte
+	putUint256LE(buf[:], i)
+	d.Write(buf[:])
 	return d
 }
+
+// EncodeUint256 writes i as a 32 byte little-endian uint256, storing the limbs directly
+// without going through binary.Write
 func (d *Builder) EncodeUint256(i *uint256.Int) *Builder {
-	// uint256 uses all 4 uint64s (32 bytes)
-	// uint256.Int is [4]uint64 in little-endian order
-	d.EncodeUint64(i[0])
-	d.EncodeUint64(i[1])
-	d.EncodeUint64(i[2])
-	d.EncodeUint64(i[3])
+	var buf [32]byte
+	putUint256LE(buf[:], i)
+	d.Write(buf[:])
 	return d
 }
+
+// putUint256LE stores the limbs of i in little-endian order until dst is full. uint256.Int is
+// [4]uint64 in little-endian order, so each limb is stored as is.
+func putUint256LE(dst []byte, i *uint256.Int) {
+	for limb := 0; limb*8 < len(dst); limb++ {
+		order.PutUint64(dst[limb*8:], i[limb])
+	}
+}
 func (d *Builder) EncodeBinary(i any) *Builder {
 	binary.Write(d, order, i)
 	return d
@@ -224,6 +255,77 @@ func (d *Builder) EncodeFixed(val []byte) *Builder {
 	return d
 }
 
+// EncodeFixedReader is EncodeFixed for n bytes read from r, for large fixed payloads such as
+// RandaoMixes that can be streamed from a file or mmap. The bytes are copied from r to the
+// output by Finish, so they are never held in memory, and r must be readable until then.
+func (d *Builder) EncodeFixedReader(r io.Reader, n int) *Builder {
+	d.stack = append(d.stack, word{
+		pointer: -1,
+		dat:     copyReader(r, n),
+	})
+	d.cur = d.cur + uint32(n)
+	return d
+}
+
+// OffsetToken is a 4 byte slot reserved with ReserveOffset, which Finish writes with the value
+// given to PatchOffset
+type OffsetToken struct {
+	slot *offsetSlot
+}
+
+type offsetSlot struct {
+	pos   int // Position of the slot in the fixed part of its builder
+	value uint32
+	set   bool
+}
+
+// Pos returns the position of the slot in the fixed part of the builder that reserved it
+func (t OffsetToken) Pos() int {
+	return t.slot.pos
+}
+
+// ReserveOffset writes a 4 byte slot to the fixed part, whose value is set later with
+// PatchOffset. Unlike the offsets of EncodeBytes and EnterDynamic, which always point into the
+// heap of their builder, the slot can hold any value, such as the length of a chunk that is only
+// known once the chunk is written, for formats that frame SSZ with their own prefixes. Finish
+// fails if a reserved slot was never patched.
+func (d *Builder) ReserveOffset() OffsetToken {
+	slot := &offsetSlot{pos: int(d.cur)}
+	d.stack = append(d.stack, word{
+		pointer: -1,
+		dat: func(w io.Writer) error {
+			if !slot.set {
+				return fmt.Errorf("ssz: offset reserved at %d was never patched", slot.pos)
+			}
+			var buf [PtrSize]byte
+			order.PutUint32(buf[:], slot.value)
+			_, err := w.Write(buf[:])
+			return err
+		},
+	})
+	d.cur = d.cur + PtrSize
+	return OffsetToken{slot: slot}
+}
+
+// PatchOffset sets the value of a slot reserved with ReserveOffset. It can be patched again
+// until Finish writes it.
+func (d *Builder) PatchOffset(tok OffsetToken, value int) error {
+	if tok.slot == nil {
+		return fmt.Errorf("ssz: patching an offset that was not reserved")
+	}
+	if value < 0 || uint64(value) > math.MaxUint32 {
+		return fmt.Errorf("ssz: offset %d does not fit in %d bytes", value, PtrSize)
+	}
+	tok.slot.value = uint32(value)
+	tok.slot.set = true
+	return nil
+}
+
+// Len returns the number of bytes written to the builder so far, in its fixed part and its heap
+func (d *Builder) Len() int {
+	return int(d.cur) + d.hz
+}
+
 // from fastssz
 func ValidateBitlist(buf []byte, bitLimit uint64) error {
 	byteLen := len(buf)
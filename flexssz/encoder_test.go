@@ -3,6 +3,9 @@ package flexssz
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/holiman/uint256"
@@ -134,6 +137,35 @@ func TestBuilder_EncodeFixed(t *testing.T) {
 	assert.Equal(t, data, decoded)
 }
 
+func TestBuilder_EncodeFixedReader(t *testing.T) {
+	// RandaoMixes-like payload, encoded from memory and streamed from a file
+	mixes := bytes.Repeat([]byte{0xab, 0xcd}, 4096)
+	path := filepath.Join(t.TempDir(), "mixes")
+	require.NoError(t, os.WriteFile(path, mixes, 0o644))
+
+	expected := new(bytes.Buffer)
+	NewBuilder(expected).EncodeUint64(7).EncodeFixed(mixes).EncodeBytes([]byte{1, 2}).Finish()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	streamed := new(bytes.Buffer)
+	b := NewBuilder(streamed)
+	result := b.EncodeUint64(7).EncodeFixedReader(f, len(mixes)).EncodeBytesReader(bytes.NewReader([]byte{1, 2}), 2)
+	assert.Equal(t, b, result) // Check method chaining
+	require.NoError(t, b.Finish())
+	assert.Equal(t, expected.Bytes(), streamed.Bytes())
+
+	t.Run("short reader", func(t *testing.T) {
+		b := NewBuilder(new(bytes.Buffer))
+		b.EncodeFixedReader(bytes.NewReader([]byte{1, 2, 3}), 4)
+		err := b.Finish()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.ErrorContains(t, err, "copied 3 of 4 bytes")
+	})
+}
+
 func TestBuilder_EncodeString(t *testing.T) {
 	buf := new(bytes.Buffer)
 	b := NewBuilder(buf)
@@ -710,3 +742,49 @@ func TestEncoderDecoder_RoundTrip(t *testing.T) {
 		assert.Equal(t, uint16(777), fixedVal2)
 	})
 }
+
+func TestBuilder_ReserveOffset(t *testing.T) {
+	t.Run("length prefixed chunks", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		b := NewBuilder(buf)
+		chunks := [][]uint64{{1, 2}, {3}}
+		for _, chunk := range chunks {
+			tok := b.ReserveOffset()
+			for _, v := range chunk {
+				b.EncodeUint64(v)
+			}
+			// The prefix holds the size of the chunk that follows it
+			require.NoError(t, b.PatchOffset(tok, b.Len()-tok.Pos()-PtrSize))
+		}
+		require.NoError(t, b.Finish())
+
+		var expected []byte
+		expected = binary.LittleEndian.AppendUint32(expected, 16)
+		expected = binary.LittleEndian.AppendUint64(expected, 1)
+		expected = binary.LittleEndian.AppendUint64(expected, 2)
+		expected = binary.LittleEndian.AppendUint32(expected, 8)
+		expected = binary.LittleEndian.AppendUint64(expected, 3)
+		assert.Equal(t, expected, buf.Bytes())
+	})
+
+	t.Run("alongside heap offsets", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		b := NewBuilder(buf)
+		tok := b.ReserveOffset()
+		b.EncodeBytes([]byte{7, 8})
+		assert.Equal(t, 0, tok.Pos())
+		assert.Equal(t, 10, b.Len())
+		require.NoError(t, b.PatchOffset(tok, 99))
+		require.NoError(t, b.PatchOffset(tok, 2))
+		require.NoError(t, b.Finish())
+		assert.Equal(t, []byte{2, 0, 0, 0, 8, 0, 0, 0, 7, 8}, buf.Bytes())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		b := NewBuilder(new(bytes.Buffer))
+		tok := b.ReserveOffset()
+		assert.Error(t, b.PatchOffset(tok, -1))
+		assert.Error(t, b.PatchOffset(OffsetToken{}, 0))
+		assert.ErrorContains(t, b.Finish(), "never patched")
+	})
+}
@@ -3,11 +3,39 @@ package flexssz
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 var ErrIndexOutOfBounds = errors.New("index out of bounds")
 var ErrInvalidSeek = errors.New("invalid seek offset")
 
+// ErrNonCanonical is wrapped by the errors strict decoding returns for encodings that decode
+// to a value, but are not the canonical encoding of that value
+var ErrNonCanonical = errors.New("non-canonical encoding")
+
+// ErrEmptyContainer is wrapped by the errors returned for structs without any SSZ fields, since
+// the SSZ spec does not allow containers with no fields. Such structs cannot be encoded, decoded
+// or hashed, as a container or as a field or element of one.
+var ErrEmptyContainer = errors.New("container has no ssz fields")
+
+// ErrNilElement is wrapped by the errors returned when encoding or hashing a list or vector with
+// a nil pointer element, such as a nil *Validator in a []*Validator, since it has no encoding.
+// Decoding allocates every pointer element, so decoded values never hold nil elements.
+var ErrNilElement = errors.New("nil list element")
+
+// ErrLimitExceeded is wrapped by the errors returned for input that is larger or more deeply
+// nested than the limits in DecodeOptions allow
+var ErrLimitExceeded = errors.New("decode limit exceeded")
+
+// ErrListFull is wrapped by the errors AppendToList returns when appending would make a list
+// longer than its limit
+var ErrListFull = errors.New("list limit exceeded")
+
+// ErrUnknownFork is wrapped by the errors a ForkSchedule returns for digests and epochs that
+// none of its forks have
+var ErrUnknownFork = errors.New("unknown fork")
+
 type errIndexOutOfBounds struct {
 	sz  int
 	bad int
@@ -26,3 +54,55 @@ func NewErrIndexOutOfBounds(offset int, length int) error {
 		bad: offset,
 	}
 }
+
+// DecodeError is returned by Unmarshal when decoding fails. It records where in the value and
+// where in the input the failing field starts, e.g. Path "Body.Deposits[3].Data.Signature".
+type DecodeError struct {
+	Path   string // Field path from the root value, empty if the root value itself failed
+	Offset int    // Byte offset of the failing field in the input
+	Err    error  // Underlying cause
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("ssz: decode error at offset %d: %v", e.Offset, e.Err)
+	}
+	return fmt.Sprintf("ssz: decode error at %s (offset %d): %v", e.Path, e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// wrapDecodeError adds a path segment to err, which is a field name or an index like "[3]".
+// Errors that are not yet a DecodeError are wrapped with offset, the start of the failing field.
+func wrapDecodeError(err error, segment string, offset int) error {
+	if de, ok := err.(*DecodeError); ok {
+		switch {
+		case de.Path == "":
+			de.Path = segment
+		case strings.HasPrefix(de.Path, "["):
+			de.Path = segment + de.Path
+		default:
+			de.Path = segment + "." + de.Path
+		}
+		return de
+	}
+	return &DecodeError{Path: segment, Offset: offset, Err: err}
+}
+
+// asDecodeError wraps errors of the root value itself, which have an empty path
+func asDecodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+	return &DecodeError{Err: err}
+}
+
+// indexSegment is the path segment of a list or vector element
+func indexSegment(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
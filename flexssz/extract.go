@@ -0,0 +1,43 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ExtractField returns the serialized bytes of the field at path in data, the serialized form
+// of a typ. path is a dot-separated path of Go field names, e.g. "LatestBlockHeader.StateRoot".
+// Only the offsets along the path are read, so the siblings of the field are not decoded. The
+// returned slice shares memory with data.
+func ExtractField(data []byte, typ reflect.Type, path string) ([]byte, error) {
+	start, end, _, _, err := locatePath(data, typ, path)
+	if err != nil {
+		return nil, err
+	}
+	return data[start:end], nil
+}
+
+// DecodeField decodes the field at path in data, the serialized form of a T, into out, which
+// must be a pointer to the type of the field. See ExtractField.
+func DecodeField[T any](data []byte, path string, out any) error {
+	start, end, rt, typeInfo, err := locatePath(data, reflect.TypeFor[T](), path)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("out must be a non-nil pointer, got %T", out)
+	}
+	elem := rv.Elem()
+	if elem.Type() != rt {
+		return fmt.Errorf("out must point to %v, got %T", rt, out)
+	}
+
+	d := NewDecoder(data[start:end])
+	d.base = start
+	fieldInfo := &FieldInfo{Type: typeInfo, Name: "root"}
+	if err := decodeValue(d, elem, fieldInfo); err != nil {
+		return wrapDecodeError(err, path, start)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package flexssz
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type extractTestHeader struct {
+	Slot      uint64
+	StateRoot [32]byte
+}
+
+type extractTestWrappedHeader struct {
+	Header extractTestHeader `ssz:",transparent"`
+}
+
+type extractTestState struct {
+	Slot              uint64
+	LatestBlockHeader *extractTestHeader
+	Body              *iterTestBody
+	Wrapped           extractTestWrappedHeader
+	Balances          []uint64 `ssz-max:"16"`
+}
+
+func TestExtractField(t *testing.T) {
+	state := &extractTestState{
+		Slot:              10,
+		LatestBlockHeader: &extractTestHeader{Slot: 9, StateRoot: [32]byte{1, 2, 3}},
+		Body:              &iterTestBody{Graffiti: [32]byte{7}, Items: []*listTestItem{{A: 1, B: []byte{1}}, {A: 2}}},
+		Wrapped:           extractTestWrappedHeader{Header: extractTestHeader{Slot: 8, StateRoot: [32]byte{4}}},
+		Balances:          []uint64{5, 6},
+	}
+	data, err := Marshal(state)
+	require.NoError(t, err)
+	typ := reflect.TypeOf(state)
+
+	root, err := ExtractField(data, typ, "LatestBlockHeader.StateRoot")
+	require.NoError(t, err)
+	assert.Equal(t, state.LatestBlockHeader.StateRoot[:], root)
+
+	header, err := ExtractField(data, typ, "LatestBlockHeader")
+	require.NoError(t, err)
+	expected, err := Marshal(state.LatestBlockHeader)
+	require.NoError(t, err)
+	assert.Equal(t, expected, header)
+
+	balances, err := ExtractField(data, typ, "Balances")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{5, 0, 0, 0, 0, 0, 0, 0, 6, 0, 0, 0, 0, 0, 0, 0}, balances)
+
+	t.Run("decode", func(t *testing.T) {
+		var stateRoot [32]byte
+		require.NoError(t, DecodeField[extractTestState](data, "LatestBlockHeader.StateRoot", &stateRoot))
+		assert.Equal(t, state.LatestBlockHeader.StateRoot, stateRoot)
+
+		var body iterTestBody
+		require.NoError(t, DecodeField[*extractTestState](data, "Body", &body))
+		assert.Equal(t, state.Body.Graffiti, body.Graffiti)
+		assert.Len(t, body.Items, 2)
+
+		var items []*listTestItem
+		require.NoError(t, DecodeField[extractTestState](data, "Body.Items", &items))
+		assert.Equal(t, uint32(2), items[1].A)
+
+		// Fields of transparent wrappers are fields of the wrapped value
+		var slot uint64
+		require.NoError(t, DecodeField[extractTestState](data, "Wrapped.Slot", &slot))
+		assert.Equal(t, uint64(8), slot)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ExtractField(data, typ, "LatestBlockHeader.Missing")
+		assert.ErrorContains(t, err, "no field Missing")
+		_, err = ExtractField(data, typ, "Slot.Value")
+		assert.ErrorContains(t, err, "not a container")
+		_, err = ExtractField(data[:20], typ, "Body.Graffiti")
+		var decodeErr *DecodeError
+		assert.ErrorAs(t, err, &decodeErr)
+
+		var wrong uint32
+		assert.ErrorContains(t, DecodeField[extractTestState](data, "Slot", &wrong), "out must point to uint64")
+		var slot uint64
+		assert.ErrorContains(t, DecodeField[extractTestState](data, "Slot", slot), "out must be a non-nil pointer")
+	})
+}
@@ -0,0 +1,175 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// Containers without variable-size fields, such as Validator, have the same layout in every
+// value: each field sits at a fixed offset in a blob of FixedSize bytes. They are decoded by
+// checking the blob is there once and reading each field at its offset, and encoded by writing
+// each field at its offset into a slice of FixedSize bytes. Integer and boolean fields are
+// read and written in place, other fields go through the per-field codec.
+
+// FieldOffsets returns the offset of each field of a container in its fixed part, in the order
+// of Fields. Variable-size fields have the offset of the 4 bytes pointing to their data. The
+// offsets are computed when the type is parsed; the returned slice is a copy. It returns nil
+// for types that are not containers.
+func (t *TypeInfo) FieldOffsets() []int {
+	return slices.Clone(t.fieldOffsets)
+}
+
+// containerFieldOffsets returns the offset of each field in the fixed part of a container
+func containerFieldOffsets(fields []FieldInfo) []int {
+	offsets := make([]int, len(fields))
+	pos := 0
+	for i := range fields {
+		offsets[i] = pos
+		if fields[i].Type.IsVariable {
+			pos += 4
+		} else {
+			pos += fields[i].Type.FixedSize
+		}
+	}
+	return offsets
+}
+
+// decodeFixedContainer decodes the fields of a fixed-size container from the next FixedSize
+// bytes of d, which must be there
+func decodeFixedContainer(d *Decoder, v reflect.Value, typeInfo *TypeInfo) error {
+	start := d.cur
+	blob := d.xs[start : start+typeInfo.FixedSize]
+	for i := range typeInfo.Fields {
+		field := &typeInfo.Fields[i]
+		offset := typeInfo.fieldOffsets[i]
+		fieldValue := field.value(v)
+		if ok, err := readFixedBasic(d, fieldValue, field.Type, blob[offset:]); ok {
+			if err != nil {
+				return wrapDecodeError(err, field.Name, d.base+start+offset)
+			}
+			continue
+		}
+
+		d.cur = start + offset
+		restore := d.enterField(field.Name, field.Type.Tag)
+		err := decodeFixedField(d, fieldValue, field)
+		restore()
+		if err != nil {
+			return wrapDecodeError(err, field.Name, d.base+start+offset)
+		}
+	}
+	d.cur = start + typeInfo.FixedSize
+	return nil
+}
+
+// readFixedBasic sets v to the integer or boolean of type info at the start of data, and
+// reports whether it could. Other types and Go kinds are left to decodeFixedField.
+func readFixedBasic(d *Decoder, v reflect.Value, info *TypeInfo, data []byte) (bool, error) {
+	if info.Custom || info.Wrapped != nil {
+		return false, nil
+	}
+	switch info.Type {
+	case ssz.TypeUint8:
+		if !isUintKind(v.Kind()) {
+			return false, nil
+		}
+		v.SetUint(uint64(data[0]))
+	case ssz.TypeUint16:
+		if !isUintKind(v.Kind()) || v.Kind() == reflect.Uint8 {
+			return false, nil
+		}
+		v.SetUint(uint64(ssz.Uint16FromBytes(data)))
+	case ssz.TypeUint32:
+		switch v.Kind() {
+		case reflect.Uint32, reflect.Uint64, reflect.Uint:
+			v.SetUint(uint64(ssz.Uint32FromBytes(data)))
+		case reflect.Int32:
+			v.SetInt(int64(int32(ssz.Uint32FromBytes(data))))
+		default:
+			return false, nil
+		}
+	case ssz.TypeUint64:
+		switch v.Kind() {
+		case reflect.Uint64, reflect.Uint:
+			v.SetUint(ssz.Uint64FromBytes(data))
+		case reflect.Int64:
+			v.SetInt(int64(ssz.Uint64FromBytes(data)))
+		default:
+			return false, nil
+		}
+	case ssz.TypeBoolean:
+		if v.Kind() != reflect.Bool {
+			return false, nil
+		}
+		if data[0] > 1 && d.opts.Strict {
+			return true, fmt.Errorf("%w: boolean byte 0x%02x", ErrNonCanonical, data[0])
+		}
+		v.SetBool(data[0] == 1)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// isUintKind reports whether k holds unsigned integers
+func isUintKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return true
+	}
+	return false
+}
+
+// putFixedContainer writes the fields of a fixed-size container into dst, which has room for
+// its FixedSize bytes. It reports false if a field does not encode to its size, such as a slice
+// not matching its ssz-size, which the caller then encodes field by field as Marshal always has.
+func putFixedContainer(dst []byte, rv reflect.Value, typeInfo *TypeInfo) (bool, error) {
+	for i := range typeInfo.Fields {
+		field := &typeInfo.Fields[i]
+		offset := typeInfo.fieldOffsets[i]
+		fieldValue := field.value(rv)
+		if putFixedBasic(dst[offset:offset+field.Type.FixedSize], fieldValue, field.Type) {
+			continue
+		}
+
+		// Appending to an empty slice of the field writes it in place
+		size := field.Type.FixedSize
+		out, err := appendFixedField(dst[offset:offset:offset+size], fieldValue, field.Type.Tag)
+		if err != nil {
+			return false, fmt.Errorf("error encoding field %s: %w", field.Name, err)
+		}
+		if len(out) != size {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// putFixedBasic writes the integer or boolean v at the start of dst, by its Go kind like
+// encodeFixedField, and reports whether it could. Other values are left to appendFixedField.
+func putFixedBasic(dst []byte, v reflect.Value, info *TypeInfo) bool {
+	if info.Custom || info.Wrapped != nil || int(v.Type().Size()) != len(dst) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Uint8:
+		dst[0] = uint8(v.Uint())
+	case reflect.Uint16:
+		order.PutUint16(dst, uint16(v.Uint()))
+	case reflect.Uint32, reflect.Int32:
+		order.PutUint32(dst, uint32(integerBits(v)))
+	case reflect.Uint64, reflect.Int64:
+		order.PutUint64(dst, integerBits(v))
+	case reflect.Bool:
+		dst[0] = 0
+		if v.Bool() {
+			dst[0] = 1
+		}
+	default:
+		return false
+	}
+	return true
+}
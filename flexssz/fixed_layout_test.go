@@ -0,0 +1,143 @@
+package flexssz
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type layoutValidator struct {
+	Pubkey                     [48]byte
+	WithdrawalCredentials      [32]byte
+	EffectiveBalance           uint64
+	Slashed                    bool
+	ActivationEligibilityEpoch uint64
+	ActivationEpoch            uint64
+	ExitEpoch                  uint64
+	WithdrawableEpoch          uint64
+}
+
+func TestTypeInfo_FieldOffsets(t *testing.T) {
+	info, err := GetTypeInfo(reflect.TypeOf(layoutValidator{}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 121, info.FixedSize)
+	assert.Equal(t, []int{0, 48, 80, 88, 89, 97, 105, 113}, info.FieldOffsets())
+
+	// Variable-size fields are at the offset of their offset
+	type Mixed struct {
+		A uint16
+		B []byte `ssz-max:"8"`
+		C uint32
+		D []uint64 `ssz-max:"8"`
+	}
+	info, err = GetTypeInfo(reflect.TypeOf(Mixed{}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 6, 10}, info.FieldOffsets())
+
+	// The offsets are a copy
+	info.FieldOffsets()[0] = 5
+	assert.Equal(t, 0, info.FieldOffsets()[0])
+
+	info, err = GetTypeInfo(reflect.TypeOf(uint64(0)), nil)
+	require.NoError(t, err)
+	assert.Nil(t, info.FieldOffsets())
+}
+
+func TestFixedContainer_RoundTrip(t *testing.T) {
+	type Inner struct {
+		Epoch uint64
+		Root  [4]byte
+	}
+	type Fixed struct {
+		A     uint8
+		B     uint16
+		C     uint32
+		D     int32 `ssz:"int32"`
+		E     int64 `ssz:"int64"`
+		F     bool
+		Time  time.Time `ssz:"uint64,unix"`
+		Inner Inner
+		Ptr   *Inner
+		Big   uint256.Int
+		Bits  []byte `ssz:"bitvector" ssz-size:"12"`
+		Words [2]uint16
+	}
+	value := &Fixed{
+		A:     1,
+		B:     0x0203,
+		C:     0x04050607,
+		D:     -2,
+		E:     -3,
+		F:     true,
+		Time:  time.Unix(1700000000, 0).UTC(),
+		Inner: Inner{Epoch: 9, Root: [4]byte{1, 2, 3, 4}},
+		Ptr:   &Inner{Epoch: 10},
+		Big:   *uint256.NewInt(11),
+		Bits:  []byte{0xff, 0x0f},
+		Words: [2]uint16{12, 13},
+	}
+
+	var expected []byte
+	expected = append(expected, 1)
+	expected = binary.LittleEndian.AppendUint16(expected, 0x0203)
+	expected = binary.LittleEndian.AppendUint32(expected, 0x04050607)
+	expected = binary.LittleEndian.AppendUint32(expected, 0xfffffffe)
+	expected = binary.LittleEndian.AppendUint64(expected, 0xfffffffffffffffd)
+	expected = append(expected, 1)
+	expected = binary.LittleEndian.AppendUint64(expected, 1700000000)
+	expected = binary.LittleEndian.AppendUint64(expected, 9)
+	expected = append(expected, 1, 2, 3, 4)
+	expected = binary.LittleEndian.AppendUint64(expected, 10)
+	expected = append(expected, 0, 0, 0, 0)
+	expected = append(expected, 11)
+	expected = append(expected, make([]byte, 31)...)
+	expected = append(expected, 0xff, 0x0f)
+	expected = binary.LittleEndian.AppendUint16(expected, 12)
+	expected = binary.LittleEndian.AppendUint16(expected, 13)
+
+	data, err := Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+	appended, err := MarshalAppend([]byte{0xaa}, value)
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte{0xaa}, expected...), appended)
+
+	var decoded Fixed
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, value, &decoded)
+
+	// Elements of lists are decoded the same way
+	type Registry struct {
+		Validators []layoutValidator `ssz-max:"16"`
+	}
+	registry := &Registry{Validators: []layoutValidator{
+		{Pubkey: [48]byte{1}, EffectiveBalance: 32e9, Slashed: true, ExitEpoch: ^uint64(0)},
+		{Pubkey: [48]byte{2}, ActivationEpoch: 5, WithdrawableEpoch: 6},
+	}}
+	data, err = Marshal(registry)
+	require.NoError(t, err)
+	assert.Len(t, data, 4+2*121)
+	var decodedRegistry Registry
+	require.NoError(t, Unmarshal(data, &decodedRegistry))
+	assert.Equal(t, registry, &decodedRegistry)
+}
+
+func TestFixedContainer_SizeMismatch(t *testing.T) {
+	// Slices not matching their ssz-size are written as they are, like the other containers
+	type Pair struct {
+		A []uint64 `ssz-size:"2"`
+		B uint64
+	}
+	value := &Pair{A: []uint64{1, 2, 3}, B: 4}
+	data, err := Marshal(value)
+	require.NoError(t, err)
+	assert.Len(t, data, 32)
+	appended, err := MarshalAppend(nil, value)
+	require.NoError(t, err)
+	assert.Equal(t, data, appended)
+}
@@ -0,0 +1,122 @@
+package flexssz
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// Fork is a fork of a ForkSchedule: the epoch it activates at, its fork digest and the Go type
+// values are encoded as from then on, e.g. the type of the blocks of the fork
+type Fork struct {
+	Name   string
+	Epoch  uint64
+	Digest [4]byte
+	Type   reflect.Type
+}
+
+// ForkSchedule picks the Go type to decode a value as from the fork it belongs to, known by its
+// fork digest, e.g. from the context bytes of a req/resp message, or by its epoch:
+//
+//	schedule, err := flexssz.NewForkSchedule(
+//		flexssz.Fork{Name: "deneb", Epoch: 269568, Digest: denebDigest, Type: reflect.TypeFor[DenebBlock]()},
+//		flexssz.Fork{Name: "electra", Epoch: 364032, Digest: electraDigest, Type: reflect.TypeFor[ElectraBlock]()},
+//	)
+//	block, err := schedule.DecodeForFork(digest, data) // *DenebBlock or *ElectraBlock
+//
+// A ForkSchedule is not changed after NewForkSchedule, so it is safe for concurrent use.
+type ForkSchedule struct {
+	forks []Fork // Ordered by epoch
+	opts  DecodeOptions
+}
+
+// NewForkSchedule returns the schedule of forks, in any order. Each fork needs a type flexssz
+// can decode, and an epoch and digest of its own.
+func NewForkSchedule(forks ...Fork) (*ForkSchedule, error) {
+	return NewForkScheduleWithOptions(DecodeOptions{}, forks...)
+}
+
+// NewForkScheduleWithOptions is NewForkSchedule for a schedule that decodes with opts
+func NewForkScheduleWithOptions(opts DecodeOptions, forks ...Fork) (*ForkSchedule, error) {
+	sorted := slices.Clone(forks)
+	slices.SortFunc(sorted, func(a, b Fork) int { return cmp.Compare(a.Epoch, b.Epoch) })
+	digests := make(map[[4]byte]string, len(sorted))
+	for i, fork := range sorted {
+		if fork.Type == nil {
+			return nil, fmt.Errorf("fork %s has no type", fork.Name)
+		}
+		if _, err := GetTypeInfo(forkValueType(fork.Type), nil); err != nil {
+			return nil, fmt.Errorf("fork %s: %w", fork.Name, err)
+		}
+		if i > 0 && sorted[i-1].Epoch == fork.Epoch {
+			return nil, fmt.Errorf("forks %s and %s activate at the same epoch %d", sorted[i-1].Name, fork.Name, fork.Epoch)
+		}
+		if name, ok := digests[fork.Digest]; ok {
+			return nil, fmt.Errorf("forks %s and %s have the same digest %x", name, fork.Name, fork.Digest)
+		}
+		digests[fork.Digest] = fork.Name
+	}
+	return &ForkSchedule{forks: sorted, opts: opts}, nil
+}
+
+// Forks returns the forks of the schedule, ordered by epoch
+func (s *ForkSchedule) Forks() []Fork {
+	return slices.Clone(s.forks)
+}
+
+// ForDigest returns the fork with the digest
+func (s *ForkSchedule) ForDigest(digest [4]byte) (Fork, bool) {
+	for _, fork := range s.forks {
+		if fork.Digest == digest {
+			return fork, true
+		}
+	}
+	return Fork{}, false
+}
+
+// AtEpoch returns the fork active at the epoch, the last one activated at or before it
+func (s *ForkSchedule) AtEpoch(epoch uint64) (Fork, bool) {
+	// The number of forks activated at or before the epoch
+	n := sort.Search(len(s.forks), func(i int) bool { return s.forks[i].Epoch > epoch })
+	if n == 0 {
+		return Fork{}, false
+	}
+	return s.forks[n-1], true
+}
+
+// DecodeForFork decodes data as the type of the fork with the digest, returning a pointer to
+// the decoded value. Digests of no fork return an error wrapping ErrUnknownFork.
+func (s *ForkSchedule) DecodeForFork(digest [4]byte, data []byte) (any, error) {
+	fork, ok := s.ForDigest(digest)
+	if !ok {
+		return nil, fmt.Errorf("%w: digest %x", ErrUnknownFork, digest)
+	}
+	return s.decode(fork, data)
+}
+
+// DecodeAtEpoch decodes data as the type of the fork active at the epoch, see DecodeForFork
+func (s *ForkSchedule) DecodeAtEpoch(epoch uint64, data []byte) (any, error) {
+	fork, ok := s.AtEpoch(epoch)
+	if !ok {
+		return nil, fmt.Errorf("%w: no fork is active at epoch %d", ErrUnknownFork, epoch)
+	}
+	return s.decode(fork, data)
+}
+
+func (s *ForkSchedule) decode(fork Fork, data []byte) (any, error) {
+	v := reflect.New(forkValueType(fork.Type))
+	if err := UnmarshalWithOptions(data, v.Interface(), s.opts); err != nil {
+		return nil, fmt.Errorf("fork %s: %w", fork.Name, err)
+	}
+	return v.Interface(), nil
+}
+
+// forkValueType returns the type a fork decodes into, which is pointed to by the decoded value
+func forkValueType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
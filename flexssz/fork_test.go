@@ -0,0 +1,103 @@
+package flexssz
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testForkBlockV1 struct {
+	Slot uint64
+	Root [32]byte
+}
+
+type testForkBlockV2 struct {
+	Slot  uint64
+	Root  [32]byte
+	Blobs [][]byte `ssz-max:"4,32"`
+}
+
+func testForkSchedule(t *testing.T) *ForkSchedule {
+	schedule, err := NewForkSchedule(
+		Fork{Name: "v2", Epoch: 100, Digest: [4]byte{2}, Type: reflect.TypeFor[*testForkBlockV2]()},
+		Fork{Name: "v1", Epoch: 0, Digest: [4]byte{1}, Type: reflect.TypeFor[testForkBlockV1]()},
+	)
+	require.NoError(t, err)
+	return schedule
+}
+
+func TestForkSchedule_Decode(t *testing.T) {
+	schedule := testForkSchedule(t)
+	assert.Equal(t, []string{"v1", "v2"}, []string{schedule.Forks()[0].Name, schedule.Forks()[1].Name})
+
+	v1 := &testForkBlockV1{Slot: 1, Root: [32]byte{1}}
+	data, err := Marshal(v1)
+	require.NoError(t, err)
+	decoded, err := schedule.DecodeForFork([4]byte{1}, data)
+	require.NoError(t, err)
+	assert.Equal(t, v1, decoded)
+
+	v2 := &testForkBlockV2{Slot: 3200, Root: [32]byte{2}, Blobs: [][]byte{{5}}}
+	data, err = Marshal(v2)
+	require.NoError(t, err)
+	decoded, err = schedule.DecodeForFork([4]byte{2}, data)
+	require.NoError(t, err)
+	assert.Equal(t, v2, decoded)
+	decoded, err = schedule.DecodeAtEpoch(100, data)
+	require.NoError(t, err)
+	assert.Equal(t, v2, decoded)
+
+	_, err = schedule.DecodeForFork([4]byte{3}, data)
+	assert.True(t, errors.Is(err, ErrUnknownFork), "%v", err)
+	_, err = schedule.DecodeForFork([4]byte{1}, data[:10])
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr), "%v", err)
+	assert.ErrorContains(t, err, "fork v1")
+}
+
+func TestForkSchedule_AtEpoch(t *testing.T) {
+	schedule := testForkSchedule(t)
+	for epoch, want := range map[uint64]string{0: "v1", 99: "v1", 100: "v2", ^uint64(0): "v2"} {
+		fork, ok := schedule.AtEpoch(epoch)
+		require.True(t, ok, "epoch %d", epoch)
+		assert.Equal(t, want, fork.Name, "epoch %d", epoch)
+	}
+
+	late, err := NewForkSchedule(Fork{Name: "v1", Epoch: 10, Type: reflect.TypeFor[testForkBlockV1]()})
+	require.NoError(t, err)
+	_, ok := late.AtEpoch(9)
+	assert.False(t, ok)
+	_, err = late.DecodeAtEpoch(9, nil)
+	assert.True(t, errors.Is(err, ErrUnknownFork), "%v", err)
+}
+
+func TestForkSchedule_Options(t *testing.T) {
+	schedule, err := NewForkScheduleWithOptions(DecodeOptions{MaxTotalSize: 8},
+		Fork{Name: "v1", Digest: [4]byte{1}, Type: reflect.TypeFor[testForkBlockV1]()})
+	require.NoError(t, err)
+	_, err = schedule.DecodeForFork([4]byte{1}, make([]byte, 40))
+	assert.True(t, errors.Is(err, ErrLimitExceeded), "%v", err)
+}
+
+func TestForkSchedule_Invalid(t *testing.T) {
+	v1 := reflect.TypeFor[testForkBlockV1]()
+	tests := []struct {
+		name  string
+		forks []Fork
+		err   string
+	}{
+		{name: "no type", forks: []Fork{{Name: "v1"}}, err: "fork v1 has no type"},
+		{name: "unsupported type", forks: []Fork{{Name: "v1", Type: reflect.TypeFor[float64]()}}, err: "fork v1: unsupported type"},
+		{name: "same epoch", forks: []Fork{{Name: "a", Digest: [4]byte{1}, Type: v1}, {Name: "b", Digest: [4]byte{2}, Type: v1}}, err: "same epoch 0"},
+		{name: "same digest", forks: []Fork{{Name: "a", Type: v1}, {Name: "b", Epoch: 1, Type: v1}}, err: "same digest 00000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewForkSchedule(tt.forks...)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
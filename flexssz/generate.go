@@ -0,0 +1,247 @@
+package flexssz
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/holiman/uint256"
+)
+
+// DefaultGenMaxLength is the longest list Generate makes unless GenMaxLength says otherwise
+const DefaultGenMaxLength = 16
+
+// GenOption configures Generate
+type GenOption func(*generator)
+
+// GenMaxLength caps the length of generated lists at n elements, below their ssz-max, and of
+// bitlists at 8n bits. Lists of unlimited length, like progressive lists, need a cap.
+func GenMaxLength(n int) GenOption {
+	return func(g *generator) {
+		g.maxLength = n
+	}
+}
+
+// GenFull makes every list and bitlist as long as allowed, min(ssz-max, GenMaxLength), instead
+// of a random length, for values close to the largest encoding
+func GenFull() GenOption {
+	return func(g *generator) {
+		g.full = true
+	}
+}
+
+// generator fills values with random data for Generate
+type generator struct {
+	rand      *rand.Rand
+	maxLength int
+	full      bool
+}
+
+// Generate fills v, a pointer to a tagged struct or any other value flexssz can encode, with
+// random data that is valid SSZ: vectors have their ssz-size, lists are no longer than their
+// ssz-max, bitvectors have no bits set past their length and bitlists end in their delimiter
+// bit, so generated values encode, decode back to equal values and hash. Pointers are allocated,
+// interfaces hold one of their registered union members, flexssz.Union values are set to their
+// None option and times are whole seconds after the unix epoch. Custom
+// types cannot be generated and return an error.
+//
+//	var block BeaconBlock
+//	err := flexssz.Generate(rand.New(rand.NewSource(seed)), &block)
+func Generate(r *rand.Rand, v any, opts ...GenOption) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("generate requires a non-nil pointer, got %T", v)
+	}
+	g := &generator{rand: r, maxLength: DefaultGenMaxLength}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	elem := rv.Elem()
+	info, err := GetTypeInfo(elem.Type(), nil)
+	if err != nil {
+		return fmt.Errorf("error getting type info: %w", err)
+	}
+	return g.generate("", elem, info)
+}
+
+// length returns a random length for a list with the given limit, 0 meaning no limit, capped at
+// maxLength
+func (g *generator) length(limit, maxLength int) int {
+	n := max(maxLength, 0)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	if g.full {
+		return n
+	}
+	return g.rand.Intn(n + 1)
+}
+
+// randomBytes fills b with random bytes
+func (g *generator) randomBytes(b []byte) {
+	for i := range b {
+		b[i] = byte(g.rand.Uint32())
+	}
+}
+
+func (g *generator) generate(path string, v reflect.Value, info *TypeInfo) error {
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type().Elem())
+		if err := g.generate(path, ptr.Elem(), info); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if info.Wrapped != nil {
+		inner, wrapped := unwrapTransparent(v, info)
+		return g.generate(path, inner, wrapped)
+	}
+	if info.Custom {
+		return fmt.Errorf("%s: cannot generate custom type %v", path, v.Type())
+	}
+
+	switch info.Type {
+	case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(time.Unix(int64(g.rand.Uint32())+1, 0)))
+			return nil
+		}
+		bits := g.rand.Uint64() >> (64 - 8*info.FixedSize)
+		switch v.Kind() {
+		case reflect.Int32:
+			v.SetInt(int64(int32(bits)))
+		case reflect.Int64:
+			v.SetInt(int64(bits))
+		default:
+			v.SetUint(bits)
+		}
+
+	case ssz.TypeBoolean:
+		v.SetBool(g.rand.Intn(2) == 1)
+
+	case ssz.TypeUint128, ssz.TypeUint256:
+		var x uint256.Int
+		for i := 0; i < info.FixedSize/8; i++ {
+			x[i] = g.rand.Uint64()
+		}
+		v.Set(reflect.ValueOf(x))
+
+	case ssz.TypeBitVector:
+		bits := g.bytes(v, info.FixedSize)
+		if extra := info.BitLength % 8; extra != 0 {
+			bits[len(bits)-1] &= byte(1<<extra - 1)
+		}
+
+	case ssz.TypeBitList:
+		// The bits are followed by the delimiter bit, so n bits take n/8 + 1 bytes
+		n := g.length(info.BitLength, 8*g.maxLength)
+		bits := g.bytes(v, n/8+1)
+		bits[len(bits)-1] &= byte(1<<(n%8) - 1)
+		bits[len(bits)-1] |= byte(1 << (n % 8))
+
+	case ssz.TypeUnion:
+		if v.Kind() == reflect.Interface {
+			return g.generateMember(path, v)
+		}
+		v.Set(reflect.ValueOf(Union{}))
+
+	case ssz.TypeVector, ssz.TypeList:
+		if kv := info.kvlist; kv != nil {
+			return g.generateMap(path, v, kv)
+		}
+		n := info.Length
+		if info.Type == ssz.TypeList {
+			n = g.length(info.Length, g.maxLength)
+		}
+		if v.Kind() == reflect.String {
+			b := make([]byte, n)
+			g.randomBytes(b)
+			v.SetString(string(b))
+			return nil
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && info.ElementType.Type == ssz.TypeUint8 {
+			g.randomBytes(byteView(v))
+			return nil
+		}
+		for i := 0; i < n; i++ {
+			if err := g.generate(fmt.Sprintf("%s[%d]", path, i), v.Index(i), info.ElementType); err != nil {
+				return err
+			}
+		}
+
+	case ssz.TypeContainer:
+		for _, field := range info.Fields {
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			if err := g.generate(fieldPath, field.value(v), field.Type); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("%s: unsupported SSZ type for generate: %v", path, info.Type)
+	}
+	return nil
+}
+
+// bytes sets v, a byte slice or array, to n random bytes and returns them
+func (g *generator) bytes(v reflect.Value, n int) []byte {
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+	}
+	b := byteView(v)
+	g.randomBytes(b)
+	return b
+}
+
+// byteView returns the bytes of v, a byte slice or addressable byte array, without copying them
+func byteView(v reflect.Value) []byte {
+	if v.Kind() == reflect.Array {
+		return v.Slice(0, v.Len()).Bytes()
+	}
+	return v.Bytes()
+}
+
+// generateMap fills a kvlist map with random entries. Entries with the same key are merged, so
+// the map may have fewer entries than the generated list.
+func (g *generator) generateMap(path string, v reflect.Value, kv *kvList) error {
+	entries := reflect.New(kv.entries).Elem()
+	if err := g.generate(path, entries, kv.info); err != nil {
+		return err
+	}
+	m := reflect.MakeMapWithSize(v.Type(), entries.Len())
+	for i := 0; i < entries.Len(); i++ {
+		m.SetMapIndex(entries.Index(i).Field(0), entries.Index(i).Field(1))
+	}
+	v.Set(m)
+	return nil
+}
+
+// generateMember sets v, an interface field, to a random value of one of its registered union
+// members
+func (g *generator) generateMember(path string, v reflect.Value) error {
+	members := unionMemberTypes(v.Type())
+	if len(members) == 0 {
+		return fmt.Errorf("%s: %v has no registered union members", path, v.Type())
+	}
+	t := members[g.rand.Intn(len(members))]
+	value := reflect.New(t).Elem()
+	info, err := GetTypeInfo(t, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := g.generate(path, value, info); err != nil {
+		return err
+	}
+	v.Set(value)
+	return nil
+}
@@ -0,0 +1,110 @@
+package flexssz
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type generateTestInner struct {
+	A uint16
+	B [4]byte
+}
+
+type generateTestWrapper struct {
+	Value uint32 `ssz:",transparent"`
+}
+
+type generateTestValue struct {
+	Slot        uint64
+	Flag        bool
+	Small       uint256.Int  `ssz:"uint128"`
+	Big         *uint256.Int `ssz:"uint256"`
+	Root        [32]byte
+	Sync        []byte     `ssz:"bitvector" ssz-size:"12"`
+	Aggregation []byte     `ssz:"bitlist" ssz-max:"20"`
+	Balances    []uint64   `ssz-max:"3"`
+	Roots       [][]byte   `ssz-size:"?,32" ssz-max:"100"`
+	Nested      [][]uint16 `ssz-max:"4,5"`
+	Inners      [2]generateTestInner
+	Pointers    []*generateTestInner `ssz-max:"6"`
+	Name        string
+	Labels      map[string]uint64 `ssz:"kvlist" ssz-max:"5"`
+	Progressive []uint32          `ssz:"progressive-list"`
+	Wrapped     generateTestWrapper
+	Time        time.Time `ssz:"uint64,unix"`
+}
+
+func TestGenerate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		var value generateTestValue
+		require.NoError(t, Generate(r, &value))
+
+		assert.Len(t, value.Sync, 2)
+		assert.Zero(t, value.Sync[1]&0xf0, "bits past the bitvector")
+		assert.NotZero(t, value.Aggregation[len(value.Aggregation)-1], "delimiter bit")
+		assert.LessOrEqual(t, len(value.Aggregation), 3)
+		assert.LessOrEqual(t, len(value.Balances), 3)
+		assert.LessOrEqual(t, len(value.Nested), 4)
+		for _, inner := range value.Nested {
+			assert.LessOrEqual(t, len(inner), 5)
+		}
+		assert.LessOrEqual(t, len(value.Roots), DefaultGenMaxLength)
+		assert.LessOrEqual(t, len(value.Progressive), DefaultGenMaxLength)
+		assert.LessOrEqual(t, len(value.Name), DefaultGenMaxLength)
+		assert.LessOrEqual(t, len(value.Labels), 5)
+		assert.Zero(t, value.Small[2]|value.Small[3])
+		assert.NotNil(t, value.Big)
+
+		data, err := Marshal(&value)
+		require.NoError(t, err)
+		var decoded generateTestValue
+		require.NoError(t, UnmarshalStrict(data, &decoded), "value %d", i)
+		diffs, err := Diff(&value, &decoded)
+		require.NoError(t, err)
+		assert.Empty(t, diffs, "value %d", i)
+		_, err = HashTreeRoot(&value)
+		require.NoError(t, err)
+	}
+}
+
+func TestGenerate_Options(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var value generateTestValue
+	require.NoError(t, Generate(r, &value, GenFull(), GenMaxLength(4)))
+	assert.Len(t, value.Balances, 3)
+	assert.Len(t, value.Roots, 4)
+	assert.Len(t, value.Progressive, 4)
+	assert.Len(t, value.Name, 4)
+	// 20 bits and the delimiter
+	assert.Len(t, value.Aggregation, 3)
+	assert.Equal(t, byte(0x10), value.Aggregation[2]&0xf0)
+
+	require.NoError(t, Generate(r, &value, GenMaxLength(0)))
+	assert.Empty(t, value.Balances)
+	assert.Equal(t, []byte{0x01}, value.Aggregation)
+
+	// The same seed generates the same value
+	var a, b generateTestValue
+	require.NoError(t, Generate(rand.New(rand.NewSource(3)), &a))
+	require.NoError(t, Generate(rand.New(rand.NewSource(3)), &b))
+	equal, err := Equal(&a, &b)
+	require.NoError(t, err)
+	assert.True(t, equal)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	var value generateTestValue
+	assert.ErrorContains(t, Generate(r, value), "non-nil pointer")
+
+	var custom struct {
+		Pubkey customTestPubkey `ssz-size:"48"`
+	}
+	assert.ErrorContains(t, Generate(r, &custom), "cannot generate custom type")
+}
@@ -0,0 +1,97 @@
+package flexssz
+
+import (
+	"reflect"
+	"sync"
+)
+
+// HashCache stores the hash tree roots of nested containers for HashTreeRootWithCache, so that
+// hashing a large value again only rehashes what changed, e.g. the few validators of a state
+// that changed since the last slot.
+//
+// Roots are keyed by the address of the container, and are trusted until invalidated: after
+// changing a container, call Invalidate on it and on every nested container holding it. The
+// value passed to HashTreeRootWithCache is never cached, so it needs no invalidation.
+// InvalidateAll drops every root at once by moving to a new generation.
+//
+// A HashCache may be used by multiple goroutines. It keeps the containers it holds roots for
+// from being garbage collected until they are invalidated or the cache is Reset.
+type HashCache struct {
+	mu         sync.RWMutex
+	generation uint64
+	entries    map[any]hashCacheEntry
+}
+
+type hashCacheEntry struct {
+	root       [32]byte
+	generation uint64
+}
+
+// NewHashCache returns an empty HashCache
+func NewHashCache() *HashCache {
+	return &HashCache{entries: make(map[any]hashCacheEntry)}
+}
+
+// Invalidate drops the cached root of the container v points to, e.g. &state.Validators[i]
+func (c *HashCache) Invalidate(v any) {
+	c.mu.Lock()
+	delete(c.entries, v)
+	c.mu.Unlock()
+}
+
+// InvalidateAll drops every cached root. Entries are only marked stale, use Reset to also free
+// them.
+func (c *HashCache) InvalidateAll() {
+	c.mu.Lock()
+	c.generation++
+	c.mu.Unlock()
+}
+
+// Reset drops every cached root and frees the entries
+func (c *HashCache) Reset() {
+	c.mu.Lock()
+	c.generation++
+	c.entries = make(map[any]hashCacheEntry)
+	c.mu.Unlock()
+}
+
+// Generation returns the number of times InvalidateAll and Reset were called
+func (c *HashCache) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// Len returns the number of entries, including stale ones
+func (c *HashCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// hashTreeRoot returns the cached root of the container v, hashing and storing it on a miss
+func (c *HashCache) hashTreeRoot(v reflect.Value, typeInfo *TypeInfo) ([32]byte, error) {
+	// Only addressable containers have an identity, pointers are cached by their element
+	if v.Kind() != reflect.Struct || !v.CanAddr() || !v.CanInterface() {
+		return hashTreeRootValue(v, typeInfo, c)
+	}
+	key := v.Addr().Interface()
+
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok && entry.generation == generation {
+		return entry.root, nil
+	}
+
+	root, err := hashTreeRootValue(v, typeInfo, c)
+	if err != nil {
+		return root, err
+	}
+	c.mu.Lock()
+	// Stored with the generation seen before hashing, so a concurrent InvalidateAll is not lost
+	c.entries[key] = hashCacheEntry{root: root, generation: generation}
+	c.mu.Unlock()
+	return root, nil
+}
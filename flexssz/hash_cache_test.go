@@ -0,0 +1,113 @@
+package flexssz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hashCacheTestValidator struct {
+	Pubkey  [48]byte
+	Balance uint64
+}
+
+type hashCacheTestHeader struct {
+	Slot uint64
+	Root [32]byte
+}
+
+type hashCacheTestState struct {
+	Header     hashCacheTestHeader
+	Validators []hashCacheTestValidator  `ssz-max:"1024"`
+	Pending    []*hashCacheTestValidator `ssz-max:"16"`
+}
+
+func TestHashTreeRootWithCache(t *testing.T) {
+	state := &hashCacheTestState{
+		Header:     hashCacheTestHeader{Slot: 1},
+		Validators: make([]hashCacheTestValidator, 100),
+		Pending:    []*hashCacheTestValidator{{Balance: 7}},
+	}
+	for i := range state.Validators {
+		state.Validators[i].Balance = uint64(i)
+	}
+	cache := NewHashCache()
+
+	hashBoth := func() ([32]byte, [32]byte) {
+		t.Helper()
+		expected, err := HashTreeRoot(state)
+		require.NoError(t, err)
+		cached, err := HashTreeRootWithCache(state, cache)
+		require.NoError(t, err)
+		return expected, cached
+	}
+
+	expected, cached := hashBoth()
+	assert.Equal(t, expected, cached)
+	// The header, the validators and the pending validator, but not the state itself
+	assert.Equal(t, 102, cache.Len())
+
+	// Changes are not seen until invalidated
+	state.Validators[5].Balance = 1000
+	state.Pending[0].Balance = 8
+	stale, err := HashTreeRootWithCache(state, cache)
+	require.NoError(t, err)
+	assert.Equal(t, cached, stale)
+
+	cache.Invalidate(&state.Validators[5])
+	cache.Invalidate(state.Pending[0])
+	expected, cached = hashBoth()
+	assert.Equal(t, expected, cached)
+	assert.NotEqual(t, stale, cached)
+
+	// Fields of the root are always rehashed
+	state.Validators = append(state.Validators, hashCacheTestValidator{Balance: 1})
+	expected, cached = hashBoth()
+	assert.Equal(t, expected, cached)
+
+	state.Header.Slot = 2
+	cache.InvalidateAll()
+	assert.Equal(t, uint64(1), cache.Generation())
+	expected, cached = hashBoth()
+	assert.Equal(t, expected, cached)
+
+	cache.Reset()
+	assert.Equal(t, 0, cache.Len())
+
+	// A nil cache hashes everything
+	root, err := HashTreeRootWithCache(state, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+}
+
+func TestHashTreeRootWithCache_Concurrent(t *testing.T) {
+	state := &hashCacheTestState{Validators: make([]hashCacheTestValidator, 64)}
+	expected, err := HashTreeRoot(state)
+	require.NoError(t, err)
+
+	cache := NewHashCache()
+	done := make(chan [32]byte)
+	for range 8 {
+		go func() {
+			root, _ := HashTreeRootWithCache(state, cache)
+			cache.InvalidateAll()
+			done <- root
+		}()
+	}
+	for range 8 {
+		assert.Equal(t, expected, <-done)
+	}
+}
+
+func BenchmarkHashTreeRootWithCache(b *testing.B) {
+	state := &hashCacheTestState{Validators: make([]hashCacheTestValidator, 1024)}
+	cache := NewHashCache()
+	for i := 0; i < b.N; i++ {
+		state.Validators[i%1024].Balance++
+		cache.Invalidate(&state.Validators[i%1024])
+		if _, err := HashTreeRootWithCache(state, cache); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
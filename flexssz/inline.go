@@ -0,0 +1,128 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Embedded struct values are inlined by default: their fields take the place of the embedded
+// field in the parent container, in order, as Go promotes them, instead of being encoded as a
+// nested container. A common header embedded in several containers then has the wire layout and
+// root of its fields written out in each. ssz:"inline" inlines a named struct field the same way,
+// and ssz:"container" keeps an embedded struct nested. Embedded pointers, time.Time, unions,
+// types that encode themselves and transparent wrappers are never inlined by default.
+
+// inlineByDefault reports whether an embedded field without ssz tags is inlined
+func inlineByDefault(field reflect.StructField) bool {
+	if !field.Anonymous || hasSSZTags(field.Tag) {
+		return false
+	}
+	t := field.Type
+	if t.Kind() != reflect.Struct || t == timeType || t == unionType || isCustomType(t) {
+		return false
+	}
+	return !hasTransparentField(t)
+}
+
+// hasSSZTags reports whether a field has any of the tags parseSSZTags reads
+func hasSSZTags(tag reflect.StructTag) bool {
+	for _, key := range []string{"ssz", "ssz-size", "ssz-max", "ssz-max-name", "ssz-omitzero"} {
+		if _, ok := tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTransparentField reports whether t is a wrapper struct, see sszTag.Transparent
+func hasTransparentField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		_, options, _ := strings.Cut(t.Field(i).Tag.Get("ssz"), ",")
+		for _, option := range strings.Split(options, ",") {
+			if strings.TrimSpace(option) == "transparent" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containerFields returns the fields of struct t in encoding order, with the fields of inlined
+// structs in place of the field holding them, and whether one of them is marked transparent.
+// path is the index sequence of t in the container it is inlined into, nil for the container.
+func containerFields(t reflect.Type, path []int) ([]FieldInfo, bool, error) {
+	var fields []FieldInfo
+	transparent := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Parse field tags
+		fieldTag, err := parseSSZTags(field)
+		if err != nil {
+			return nil, false, err
+		}
+		if fieldTag.Skip {
+			continue
+		}
+		if fieldTag.Inline {
+			// The fields of embedded structs are promoted even when their type is unexported
+			inner, innerTransparent, err := containerFields(field.Type, append(slices.Clip(path), i))
+			if err != nil {
+				return nil, false, err
+			}
+			if innerTransparent {
+				return nil, false, fmt.Errorf("field %s: cannot inline %v, which is a transparent wrapper", field.Name, field.Type)
+			}
+			fields = append(fields, inner...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		transparent = transparent || fieldTag.Transparent
+
+		// Get field type info
+		fieldTypeInfo, err := GetTypeInfo(field.Type, fieldTag)
+		if err != nil {
+			return nil, false, err
+		}
+		fieldInfo := FieldInfo{Index: i, Name: field.Name, Type: fieldTypeInfo}
+		if path != nil {
+			fieldInfo.Index = path[0]
+			fieldInfo.Path = append(slices.Clip(path), i)
+		}
+		fields = append(fields, fieldInfo)
+	}
+	return fields, transparent, nil
+}
+
+// checkFieldNames rejects containers where an inlined field has the name of another field,
+// which Go would hide, but SSZ encodes both of
+func checkFieldNames(t reflect.Type, fields []FieldInfo) error {
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if seen[field.Name] {
+			return fmt.Errorf("struct %v: field %s is inlined from an embedded struct and has the name of another field", t, field.Name)
+		}
+		seen[field.Name] = true
+	}
+	return nil
+}
+
+// value returns the field in v, a value of the struct it belongs to
+func (f *FieldInfo) value(v reflect.Value) reflect.Value {
+	if f.Path != nil {
+		return v.FieldByIndex(f.Path)
+	}
+	return v.Field(f.Index)
+}
+
+// structField returns the Go field in t, the struct it belongs to
+func (f *FieldInfo) structField(t reflect.Type) reflect.StructField {
+	if f.Path != nil {
+		return t.FieldByIndex(f.Path)
+	}
+	return t.Field(f.Index)
+}
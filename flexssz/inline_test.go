@@ -0,0 +1,166 @@
+package flexssz
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inlineTestHeader struct {
+	Slot  uint64
+	Root  [32]byte
+	Extra []byte `ssz-max:"8"`
+	cache uint64
+}
+
+type inlineTestSig struct {
+	A uint32
+	B uint16
+}
+
+type inlineTestBlock struct {
+	inlineTestHeader
+	Body []uint64      `ssz-max:"4"`
+	Sig  inlineTestSig `ssz:"inline"`
+}
+
+type inlineTestBlockFlat struct {
+	Slot  uint64
+	Root  [32]byte
+	Extra []byte   `ssz-max:"8"`
+	Body  []uint64 `ssz-max:"4"`
+	A     uint32
+	B     uint16
+}
+
+// InlineTestPair is exported to be embedded, as unexported embedded structs are only encoded
+// when inlined
+type InlineTestPair = inlineTestSig
+
+type inlineTestNested struct {
+	InlineTestPair `ssz:"container"`
+	Count          uint8
+}
+
+type inlineTestNestedNamed struct {
+	Sig   inlineTestSig
+	Count uint8
+}
+
+func TestInline(t *testing.T) {
+	block := &inlineTestBlock{
+		inlineTestHeader: inlineTestHeader{Slot: 7, Root: [32]byte{1}, Extra: []byte{2, 3}},
+		Body:             []uint64{4, 5},
+		Sig:              inlineTestSig{A: 6, B: 7},
+	}
+	flat := &inlineTestBlockFlat{Slot: 7, Root: [32]byte{1}, Extra: []byte{2, 3}, Body: []uint64{4, 5}, A: 6, B: 7}
+
+	info, err := GetTypeInfo(reflect.TypeOf(*block), nil)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range info.Fields {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"Slot", "Root", "Extra", "Body", "A", "B"}, names)
+	assert.Equal(t, []int{0, 1}, info.Fields[1].Path)
+	assert.Nil(t, info.Fields[3].Path)
+
+	data, err := Marshal(block)
+	require.NoError(t, err)
+	want, err := Marshal(flat)
+	require.NoError(t, err)
+	assert.Equal(t, want, data)
+	buf, err := MarshalAppend(nil, block)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+	size, err := EncodedSize(block)
+	require.NoError(t, err)
+	assert.Equal(t, len(want), size)
+
+	var decoded inlineTestBlock
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, *block, decoded)
+
+	root, err := HashTreeRoot(block)
+	require.NoError(t, err)
+	wantRoot, err := HashTreeRoot(flat)
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, root)
+
+	extracted, err := ExtractField(data, reflect.TypeOf(*block), "Extra")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 3}, extracted)
+
+	jsonData, err := MarshalJSON(block)
+	require.NoError(t, err)
+	wantJSON, err := MarshalJSON(flat)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(jsonData))
+	var fromJSON inlineTestBlock
+	require.NoError(t, UnmarshalJSON(jsonData, &fromJSON))
+	assert.Equal(t, *block, fromJSON)
+
+	other := *block
+	other.Sig.B = 8
+	diffs, err := Diff(block, &other)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, "B", diffs[0].Path)
+
+	var generated inlineTestBlock
+	require.NoError(t, Generate(rand.New(rand.NewSource(1)), &generated))
+	data, err = Marshal(&generated)
+	require.NoError(t, err)
+	var decodedGenerated inlineTestBlock
+	require.NoError(t, Unmarshal(data, &decodedGenerated))
+	assert.Equal(t, generated, decodedGenerated)
+}
+
+func TestInline_Container(t *testing.T) {
+	// ssz:"container" keeps an embedded struct nested
+	nested := &inlineTestNested{InlineTestPair: InlineTestPair{A: 1, B: 2}, Count: 3}
+	named := &inlineTestNestedNamed{Sig: inlineTestSig{A: 1, B: 2}, Count: 3}
+
+	data, err := Marshal(nested)
+	require.NoError(t, err)
+	want, err := Marshal(named)
+	require.NoError(t, err)
+	assert.Equal(t, want, data)
+
+	root, err := HashTreeRoot(nested)
+	require.NoError(t, err)
+	wantRoot, err := HashTreeRoot(named)
+	require.NoError(t, err)
+	assert.Equal(t, wantRoot, root)
+}
+
+func TestInline_Invalid(t *testing.T) {
+	tests := map[string]any{
+		"duplicate name": struct {
+			inlineTestSig
+			A uint32
+		}{},
+		"not a struct": struct {
+			A uint32 `ssz:"inline"`
+		}{},
+		"with size": struct {
+			Sig inlineTestSig `ssz:"inline" ssz-size:"2"`
+		}{},
+		"transparent wrapper": struct {
+			Gwei transparentTestGwei `ssz:"inline"`
+		}{},
+		// Its fields could be encoded but not decoded
+		"unexported named field": struct {
+			sig inlineTestSig `ssz:"inline"`
+		}{},
+	}
+	for name, v := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := GetTypeInfo(reflect.TypeOf(v), nil)
+			assert.Error(t, err)
+		})
+	}
+}
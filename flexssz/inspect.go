@@ -0,0 +1,245 @@
+package flexssz
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// Layout is the position of a value, and of the values it is made of, in serialized data, as
+// found by Inspect
+type Layout struct {
+	// Name is the field name, the index of an element like "[3]", or the Go type of the root
+	Name string
+	// Path from the root in the format of DecodeError.Path, e.g. "Body.Deposits[3]", empty for
+	// the root
+	Path string
+	Type ssz.TypeName
+	// Start and End are the bounds of the value in the data
+	Start, End int
+	// OffsetAt is where the offset of a variable-size value is stored in the data, or -1 for a
+	// value in the fixed part of its parent and for the root. The offset is relative to the
+	// start of the parent.
+	OffsetAt int
+	// Children are the fields of containers and the elements of lists and vectors of composite
+	// types. Basic values, lists and vectors of basic values, bitfields, unions and custom types
+	// have none.
+	Children []*Layout
+
+	data []byte
+}
+
+// Bytes returns the serialized value, which shares memory with the inspected data
+func (l *Layout) Bytes() []byte {
+	return l.data[l.Start:l.End]
+}
+
+// At returns the innermost value holding the byte at pos, or nil if pos is outside of l. Bytes of
+// a container that are not in any field, which are the offsets of its variable-size fields, are
+// held by the container.
+func (l *Layout) At(pos int) *Layout {
+	if pos < l.Start || pos >= l.End {
+		return nil
+	}
+	for _, c := range l.Children {
+		if at := c.At(pos); at != nil {
+			return at
+		}
+	}
+	return l
+}
+
+// Inspect walks data, the serialized form of a value of the type of v, and returns where each
+// field and element is, without decoding any values. Offsets are checked to lie within their
+// parent and to be in order, but no other validation is done, so data that fails to decode can
+// be inspected to find the field at fault. On error, the layout found so far is returned along
+// with a DecodeError.
+func Inspect(data []byte, v any) (*Layout, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot inspect nil")
+	}
+	rt := reflect.TypeOf(v)
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	info, err := GetTypeInfo(rt, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting type info: %w", err)
+	}
+	root := &Layout{Name: rt.String(), Type: info.Type, End: len(data), OffsetAt: -1, data: data}
+	if !info.IsVariable && info.FixedSize != len(data) {
+		return root, &DecodeError{Err: fmt.Errorf("%v is %d bytes, got %d", rt, info.FixedSize, len(data))}
+	}
+	return root, inspect(root, info)
+}
+
+// child adds a child to l
+func (l *Layout) child(name string, info *TypeInfo, start, end, offsetAt int) *Layout {
+	path := name
+	if l.Path != "" && !strings.HasPrefix(name, "[") {
+		path = l.Path + "." + name
+	} else if l.Path != "" {
+		path = l.Path + name
+	}
+	c := &Layout{Name: name, Path: path, Type: info.Type, Start: start, End: end, OffsetAt: offsetAt, data: l.data}
+	l.Children = append(l.Children, c)
+	return c
+}
+
+// inspect adds the children of l, a value of type info
+func inspect(l *Layout, info *TypeInfo) error {
+	if info.Custom {
+		return nil
+	}
+	switch info.Type {
+	case ssz.TypeContainer:
+		return inspectContainer(l, info)
+	case ssz.TypeVector, ssz.TypeList:
+		if info.ElementType == nil || isBasicType(info.ElementType) {
+			return nil
+		}
+		return inspectElements(l, info.ElementType)
+	}
+	return nil
+}
+
+func inspectContainer(l *Layout, info *TypeInfo) error {
+	pos := l.Start
+	var variable []*Layout
+	var variableInfo []*TypeInfo
+	for i := range info.Fields {
+		field := &info.Fields[i]
+		if !field.Type.IsVariable {
+			end := pos + field.Type.FixedSize
+			if end > l.End {
+				return &DecodeError{Path: l.Path, Offset: pos, Err: fmt.Errorf("field %s ends at %d, past the end of the data (%d)", field.Name, end, l.End)}
+			}
+			c := l.child(field.Name, field.Type, pos, end, -1)
+			if err := inspect(c, field.Type); err != nil {
+				return err
+			}
+			pos = end
+			continue
+		}
+		if pos+4 > l.End {
+			return &DecodeError{Path: l.Path, Offset: pos, Err: fmt.Errorf("offset of field %s: unexpected end of data", field.Name)}
+		}
+		start := l.Start + inspectOffset(order.Uint32(l.data[pos:pos+4]), l)
+		variable = append(variable, l.child(field.Name, field.Type, start, l.End, pos))
+		variableInfo = append(variableInfo, field.Type)
+		pos += 4
+	}
+	return inspectVariable(l, pos, variable, variableInfo)
+}
+
+func inspectElements(l *Layout, elem *TypeInfo) error {
+	if !elem.IsVariable {
+		size := elem.FixedSize
+		if size <= 0 || (l.End-l.Start)%size != 0 {
+			return &DecodeError{Path: l.Path, Offset: l.Start, Err: fmt.Errorf("%d bytes cannot be divided by element size %d", l.End-l.Start, size)}
+		}
+		for start := l.Start; start < l.End; start += size {
+			c := l.child(indexSegment(len(l.Children)), elem, start, start+size, -1)
+			if err := inspect(c, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if l.End == l.Start {
+		return nil
+	}
+	if l.End-l.Start < 4 {
+		return &DecodeError{Path: l.Path, Offset: l.Start, Err: fmt.Errorf("list of %d bytes is too short for an offset", l.End-l.Start)}
+	}
+	first, ok := offsetWithin(order.Uint32(l.data[l.Start:]), l.End-l.Start)
+	if !ok || first%4 != 0 {
+		return &DecodeError{Path: l.Path, Offset: l.Start, Err: fmt.Errorf("invalid first offset %d", order.Uint32(l.data[l.Start:]))}
+	}
+	n := first / 4
+	variable := make([]*Layout, n)
+	variableInfo := make([]*TypeInfo, n)
+	for i := range variable {
+		at := l.Start + 4*i
+		variable[i] = l.child(indexSegment(i), elem, l.Start+inspectOffset(order.Uint32(l.data[at:]), l), l.End, at)
+		variableInfo[i] = elem
+	}
+	return inspectVariable(l, l.Start+first, variable, variableInfo)
+}
+
+// inspectOffset converts an offset into l, see offsetWithin. Offsets past the end of l, which
+// might not fit in an int, are clamped to just past it for inspectVariable to report.
+func inspectOffset(offset uint32, l *Layout) int {
+	n, ok := offsetWithin(offset, l.End-l.Start)
+	if !ok {
+		return l.End - l.Start + 1
+	}
+	return n
+}
+
+// inspectVariable bounds the variable-size children of l, which start at their offsets and end
+// at the next one, and inspects them. fixedEnd is the end of the fixed part of l.
+func inspectVariable(l *Layout, fixedEnd int, variable []*Layout, infos []*TypeInfo) error {
+	for _, c := range variable {
+		if c.Start < fixedEnd || c.Start > l.End {
+			// Drop the children from the one with the bad offset, which have no valid bounds
+			for j, child := range l.Children {
+				if child == c {
+					l.Children = l.Children[:j]
+					break
+				}
+			}
+			return &DecodeError{Path: c.Path, Offset: c.OffsetAt, Err: fmt.Errorf("invalid offset %d: start=%d, previous=%d, len=%d", order.Uint32(l.data[c.OffsetAt:]), c.Start, fixedEnd, l.End)}
+		}
+		fixedEnd = c.Start
+	}
+	for i, c := range variable {
+		if i+1 < len(variable) {
+			c.End = variable[i+1].Start
+		}
+	}
+	for i, c := range variable {
+		if err := inspect(c, infos[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dump writes the layout as an annotated hexdump: a line per value with its bounds, name, type
+// and where its offset is stored, with the bytes of values without children below it, 16 per
+// line at their position in the data
+func (l *Layout) Dump(w io.Writer) error {
+	return l.dump(w, 0)
+}
+
+func (l *Layout) dump(w io.Writer, depth int) error {
+	indent := strings.Repeat("  ", depth)
+	line := fmt.Sprintf("%s%08x-%08x %s (%s, %d bytes)", indent, l.Start, l.End, l.Name, l.Type, l.End-l.Start)
+	if l.OffsetAt >= 0 {
+		line += fmt.Sprintf(" offset at %08x", l.OffsetAt)
+	}
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+
+	if len(l.Children) == 0 {
+		for pos := l.Start; pos < l.End; pos += 16 {
+			row := l.data[pos:min(pos+16, l.End)]
+			if _, err := fmt.Fprintf(w, "%s  %08x  % x\n", indent, pos, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, c := range l.Children {
+		if err := c.dump(w, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package flexssz
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inspectTestItem struct {
+	ID   uint16
+	Data []byte `ssz-max:"8"`
+}
+
+type inspectTestValue struct {
+	Slot     uint64
+	Balances []uint64 `ssz-max:"4"`
+	Root     [4]byte
+	Items    []inspectTestItem `ssz-max:"4"`
+}
+
+func TestInspect(t *testing.T) {
+	value := inspectTestValue{
+		Slot:     7,
+		Balances: []uint64{1, 2},
+		Root:     [4]byte{0xaa, 0xbb, 0xcc, 0xdd},
+		Items:    []inspectTestItem{{ID: 1, Data: []byte{9}}, {ID: 2}},
+	}
+	data, err := Marshal(&value)
+	require.NoError(t, err)
+
+	layout, err := Inspect(data, &value)
+	require.NoError(t, err)
+	assert.Equal(t, "flexssz.inspectTestValue", layout.Name)
+	assert.Equal(t, len(data), layout.End)
+	require.Len(t, layout.Children, 4)
+
+	// Fields are where ExtractField finds them
+	for _, c := range layout.Children {
+		extracted, err := ExtractField(data, reflect.TypeOf(value), c.Name)
+		require.NoError(t, err)
+		assert.Equal(t, extracted, c.Bytes(), c.Name)
+	}
+	balances := layout.Children[1]
+	assert.Equal(t, 8, balances.OffsetAt)
+	assert.Empty(t, balances.Children, "lists of basic values have no children")
+	assert.Equal(t, -1, layout.Children[2].OffsetAt)
+
+	items := layout.Children[3]
+	require.Len(t, items.Children, 2)
+	second := items.Children[1]
+	assert.Equal(t, "[1]", second.Name)
+	assert.Equal(t, "Items[1]", second.Path)
+	assert.Equal(t, items.Start+4, second.OffsetAt)
+	require.Len(t, second.Children, 2)
+	assert.Equal(t, "Items[1].Data", second.Children[1].Path)
+	assert.Empty(t, second.Children[1].Bytes())
+	var item inspectTestItem
+	require.NoError(t, Unmarshal(items.Children[0].Bytes(), &item))
+	assert.Equal(t, value.Items[0], item)
+
+	// The innermost value holding a byte, or the container for the bytes of its offsets
+	assert.Equal(t, "Items[0].Data", layout.At(0x32).Path)
+	assert.Equal(t, "Root", layout.At(0x0c).Path)
+	assert.Same(t, layout, layout.At(8))
+	assert.Nil(t, layout.At(len(data)))
+
+	var buf bytes.Buffer
+	require.NoError(t, layout.Children[2].Dump(&buf))
+	assert.Equal(t, "0000000c-00000010 Root (vector, 4 bytes)\n  0000000c  aa bb cc dd\n", buf.String())
+	buf.Reset()
+	require.NoError(t, layout.Dump(&buf))
+	assert.Contains(t, buf.String(), "  00000000-00000008 Slot (uint64, 8 bytes)\n    00000000  07 00 00 00 00 00 00 00\n")
+	assert.Contains(t, buf.String(), "  00000024-00000039 Items (list, 21 bytes) offset at 00000010\n")
+	assert.Contains(t, buf.String(), "    0000002c-00000033 [0] (container, 7 bytes) offset at 00000024\n")
+}
+
+func TestInspect_InvalidOffset(t *testing.T) {
+	value := inspectTestValue{Items: []inspectTestItem{{ID: 1}, {ID: 2}}}
+	data, err := Marshal(&value)
+	require.NoError(t, err)
+
+	// The offset of the second item points before the first
+	items := int(order.Uint32(data[16:]))
+	order.PutUint32(data[items+4:], 2)
+	layout, err := Inspect(data, &value)
+	var de *DecodeError
+	require.True(t, errors.As(err, &de), "%v", err)
+	assert.Equal(t, "Items[1]", de.Path)
+	assert.Equal(t, items+4, de.Offset)
+	// The layout found so far is returned
+	require.Len(t, layout.Children, 4)
+	assert.Len(t, layout.Children[3].Children, 1)
+
+	_, err = Inspect(data[:10], &value)
+	assert.Error(t, err)
+	_, err = Inspect(make([]byte, 3), &inspectTestItem{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,426 @@
+package flexssz
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/holiman/uint256"
+)
+
+// MarshalJSON encodes an SSZ-tagged value as JSON following the beacon API conventions, so the
+// same struct can serve both the SSZ wire format and the REST API:
+//
+//   - unsigned integers, including uint128 and uint256, are decimal strings
+//   - byte vectors, byte lists, bitvectors and bitlists are 0x-prefixed hex
+//   - types implementing Marshaler are the 0x-prefixed hex of their SSZ encoding
+//   - other vectors and lists are arrays, and containers are objects
+//
+// Container fields are named by their json tag, or the snake_case of the Go field name.
+func MarshalJSON(v any) ([]byte, error) {
+	rv, err := derefRoot(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting type info: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := encodeJSON(buf, rv, typeInfo); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes JSON written with the conventions of MarshalJSON into v, which must be a
+// pointer. Integers are also accepted as JSON numbers. Every container field must be present,
+// and lengths are checked against the ssz-size and ssz-max tags.
+func UnmarshalJSON(data []byte, v any) error {
+	rv, err := unmarshalTarget(v)
+	if err != nil {
+		return err
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return fmt.Errorf("error getting type info: %w", err)
+	}
+	return decodeJSON(data, rv, typeInfo)
+}
+
+// jsonFieldName returns the JSON name of a container field
+func jsonFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return snakeCase(field.Name)
+}
+
+// snakeCase converts a Go field name to snake_case, keeping acronyms together,
+// e.g. ParentRoot to parent_root, Eth1Data to eth1_data and BLSToExecutionChanges to
+// bls_to_execution_changes
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// encodeJSON writes the JSON form of v
+func encodeJSON(buf *bytes.Buffer, v reflect.Value, typeInfo *TypeInfo) error {
+	v, typeInfo = unwrapTransparent(v, typeInfo)
+	if typeInfo.Custom {
+		data, err := marshalCustom(v, typeInfo.Tag)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.Quote("0x" + hex.EncodeToString(data)))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			// Tagged uint256 pointers use nil for zero
+			if typeInfo.Tag != nil && typeInfo.Tag.OmitZero && v.Type().Elem() == uint256Type {
+				buf.WriteString(`"0"`)
+				return nil
+			}
+			return fmt.Errorf("cannot encode nil pointer")
+		}
+		v, typeInfo = unwrapTransparent(v.Elem(), typeInfo)
+	}
+
+	if v.Type() == uint256Type {
+		val := v.Interface().(uint256.Int)
+		buf.WriteString(strconv.Quote(val.Dec()))
+		return nil
+	}
+	if v.Type() == timeType {
+		sec, err := timeToUnix(v.Interface().(time.Time))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.Quote(strconv.FormatUint(sec, 10)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.Quote(strconv.FormatUint(v.Uint(), 10)))
+	case reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.Quote(strconv.FormatInt(v.Int(), 10)))
+	case reflect.String:
+		encoded, err := json.Marshal(v.String())
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			bs := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(bs), v)
+			buf.WriteString(`"0x` + hex.EncodeToString(bs) + `"`)
+			return nil
+		}
+		elemType, err := jsonElementType(v.Type(), typeInfo)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSON(buf, v.Index(i), elemType); err != nil {
+				return fmt.Errorf("error encoding element %d: %w", i, err)
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		// Maps are written as the array of their sorted entries
+		if typeInfo.kvlist == nil {
+			return fmt.Errorf("unsupported type for JSON: %v", v.Type())
+		}
+		return encodeJSON(buf, typeInfo.kvlist.sorted(v), typeInfo.kvlist.info)
+	case reflect.Struct:
+		if v.Type() == unionType {
+			return fmt.Errorf("encoding unions is not supported")
+		}
+		buf.WriteByte('{')
+		for i, field := range typeInfo.Fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			name := jsonFieldName(field.structField(v.Type()))
+			buf.WriteString(strconv.Quote(name))
+			buf.WriteByte(':')
+			if err := encodeJSON(buf, field.value(v), field.Type); err != nil {
+				return fmt.Errorf("error encoding field %s: %w", field.Name, err)
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported type for JSON: %v", v.Type())
+	}
+	return nil
+}
+
+// decodeJSON reads the JSON form of a value into v
+func decodeJSON(data []byte, v reflect.Value, typeInfo *TypeInfo) error {
+	v, typeInfo = unwrapTransparent(v, typeInfo)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v, typeInfo = unwrapTransparent(v.Elem(), typeInfo)
+	}
+
+	if typeInfo.Custom {
+		return decodeJSONCustom(data, v, typeInfo)
+	}
+	if v.Type() == uint256Type {
+		s, err := jsonIntegerString(data)
+		if err != nil {
+			return err
+		}
+		var val uint256.Int
+		if err := val.SetFromDecimal(s); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", typeInfo.Type, s, err)
+		}
+		if typeInfo.Type == ssz.TypeUint128 && val.BitLen() > 128 {
+			return fmt.Errorf("value %s overflows uint128", s)
+		}
+		v.Set(reflect.ValueOf(val))
+		return nil
+	}
+	if v.Type() == timeType {
+		s, err := jsonIntegerString(data)
+		if err != nil {
+			return err
+		}
+		sec, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid unix time: %w", err)
+		}
+		t, err := unixToTime(sec)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		var b bool
+		if err := json.Unmarshal(data, &b); err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s, err := jsonIntegerString(data)
+		if err != nil {
+			return err
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %v: %w", v.Kind(), err)
+		}
+		v.SetUint(n)
+	case reflect.Int32, reflect.Int64:
+		s, err := jsonIntegerString(data)
+		if err != nil {
+			return err
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %v: %w", v.Kind(), err)
+		}
+		v.SetInt(n)
+	case reflect.String:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return decodeJSONBytes(data, v, typeInfo)
+		}
+		return decodeJSONSequence(data, v, typeInfo)
+	case reflect.Map:
+		if typeInfo.kvlist == nil {
+			return fmt.Errorf("unsupported type for JSON: %v", v.Type())
+		}
+		entries := reflect.New(typeInfo.kvlist.entries).Elem()
+		if err := decodeJSONSequence(data, entries, typeInfo.kvlist.info); err != nil {
+			return err
+		}
+		return typeInfo.kvlist.setMap(v, entries, false)
+	case reflect.Struct:
+		if v.Type() == unionType {
+			return fmt.Errorf("decoding unions is not supported")
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		for _, field := range typeInfo.Fields {
+			name := jsonFieldName(field.structField(v.Type()))
+			raw, ok := fields[name]
+			if !ok {
+				return fmt.Errorf("missing field %s", name)
+			}
+			if err := decodeJSON(raw, field.value(v), field.Type); err != nil {
+				return fmt.Errorf("error decoding field %s: %w", name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type for JSON: %v", v.Type())
+	}
+	return nil
+}
+
+// decodeJSONBytes reads a 0x-prefixed hex string into a byte vector, byte list or bitfield
+func decodeJSONBytes(data []byte, v reflect.Value, typeInfo *TypeInfo) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("hex string %q must start with 0x", s)
+	}
+	bs, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return fmt.Errorf("invalid hex string: %w", err)
+	}
+
+	switch typeInfo.Type {
+	case ssz.TypeBitList:
+		if err := ValidateBitlist(bs, uint64(typeInfo.Tag.MaxList)); err != nil {
+			return err
+		}
+	case ssz.TypeBitVector:
+		if _, err := DecodeBitVector(bs, typeInfo.BitLength); err != nil {
+			return err
+		}
+	case ssz.TypeVector:
+		if len(bs) != typeInfo.Length {
+			return fmt.Errorf("expected %d bytes, got %d", typeInfo.Length, len(bs))
+		}
+	case ssz.TypeList:
+		if typeInfo.Tag != nil && typeInfo.Tag.MaxList > 0 && len(bs) > typeInfo.Tag.MaxList {
+			return fmt.Errorf("slice length %d exceeds limit %d", len(bs), typeInfo.Tag.MaxList)
+		}
+	}
+
+	if v.Kind() == reflect.Array {
+		if len(bs) != v.Len() {
+			return fmt.Errorf("expected %d bytes, got %d", v.Len(), len(bs))
+		}
+		reflect.Copy(v, reflect.ValueOf(bs))
+		return nil
+	}
+	v.SetBytes(bs)
+	return nil
+}
+
+// decodeJSONCustom reads a 0x-prefixed hex string into a type implementing Unmarshaler
+func decodeJSONCustom(data []byte, v reflect.Value, typeInfo *TypeInfo) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("hex string %q must start with 0x", s)
+	}
+	bs, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return fmt.Errorf("invalid hex string: %w", err)
+	}
+	if !typeInfo.IsVariable && len(bs) != typeInfo.FixedSize {
+		return fmt.Errorf("expected %d bytes, got %d", typeInfo.FixedSize, len(bs))
+	}
+	u, ok := customMethods(v, unmarshalerType)
+	if !ok {
+		return fmt.Errorf("%v does not implement flexssz.Unmarshaler", v.Type())
+	}
+	return u.(Unmarshaler).UnmarshalSSZ(bs)
+}
+
+// decodeJSONSequence reads a JSON array into a vector or list
+func decodeJSONSequence(data []byte, v reflect.Value, typeInfo *TypeInfo) error {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	elemType, err := jsonElementType(v.Type(), typeInfo)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v.Kind() == reflect.Array || typeInfo.Type == ssz.TypeVector:
+		if len(elems) != typeInfo.Length {
+			return fmt.Errorf("expected %d elements, got %d", typeInfo.Length, len(elems))
+		}
+	case typeInfo.Tag != nil && typeInfo.Tag.MaxList > 0 && len(elems) > typeInfo.Tag.MaxList:
+		return fmt.Errorf("slice length %d exceeds limit %d", len(elems), typeInfo.Tag.MaxList)
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+	}
+	for i, raw := range elems {
+		if err := decodeJSON(raw, v.Index(i), elemType); err != nil {
+			return fmt.Errorf("error decoding element %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// jsonElementType returns the type info of the elements of a vector or list
+func jsonElementType(t reflect.Type, typeInfo *TypeInfo) (*TypeInfo, error) {
+	if typeInfo.ElementType != nil {
+		return typeInfo.ElementType, nil
+	}
+	elemType, err := GetTypeInfo(t.Elem(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting element type info: %w", err)
+	}
+	return elemType, nil
+}
+
+// jsonIntegerString returns the digits of an integer written as a JSON string or number
+func jsonIntegerString(data []byte) (string, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", fmt.Errorf("expected an integer, got %s", data)
+	}
+	return n.String(), nil
+}
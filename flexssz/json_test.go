@@ -0,0 +1,100 @@
+package flexssz
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonTestCheckpoint struct {
+	Epoch uint64   `json:"epoch"`
+	Root  [32]byte `json:"root" ssz-size:"32"`
+}
+
+type jsonTestContainer struct {
+	Slot            uint64              `json:"slot"`
+	AggregationBits []byte              `json:"aggregation_bits" ssz:"bitlist" ssz-max:"16"`
+	Source          *jsonTestCheckpoint `json:"source"`
+	Indices         []uint64            `json:"attesting_indices" ssz-max:"8"`
+	Eth1DepositRoot []byte              `ssz-size:"4"`
+	Enabled         bool
+	BaseFee         *uint256.Int `ssz:"uint256"`
+}
+
+func TestMarshalJSON(t *testing.T) {
+	value := &jsonTestContainer{
+		Slot:            1234,
+		AggregationBits: []byte{0x0d},
+		Source:          &jsonTestCheckpoint{Epoch: 5, Root: [32]byte{0xab}},
+		Indices:         []uint64{1, 18446744073709551615},
+		Eth1DepositRoot: []byte{1, 2, 3, 4},
+		Enabled:         true,
+		BaseFee:         uint256.NewInt(7),
+	}
+
+	encoded, err := MarshalJSON(value)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"slot": "1234",
+		"aggregation_bits": "0x0d",
+		"source": {"epoch": "5", "root": "0xab00000000000000000000000000000000000000000000000000000000000000"},
+		"attesting_indices": ["1", "18446744073709551615"],
+		"eth1_deposit_root": "0x01020304",
+		"enabled": true,
+		"base_fee": "7"
+	}`, string(encoded))
+
+	var decoded jsonTestContainer
+	require.NoError(t, UnmarshalJSON(encoded, &decoded))
+	assert.Equal(t, value, &decoded)
+
+	// The JSON form decodes to the same SSZ value
+	want, err := Marshal(value)
+	require.NoError(t, err)
+	got, err := Marshal(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestUnmarshalJSON_Errors(t *testing.T) {
+	valid := `{"epoch": "1", "root": "0x0000000000000000000000000000000000000000000000000000000000000000"}`
+	var checkpoint jsonTestCheckpoint
+	require.NoError(t, UnmarshalJSON([]byte(valid), &checkpoint))
+
+	// Integers may also be JSON numbers
+	require.NoError(t, UnmarshalJSON([]byte(`{"epoch": 2, "root": "0x0000000000000000000000000000000000000000000000000000000000000000"}`), &checkpoint))
+	assert.Equal(t, uint64(2), checkpoint.Epoch)
+
+	tests := []struct {
+		name    string
+		json    string
+		v       any
+		wantErr string
+	}{
+		{"missing field", `{"epoch": "1"}`, &jsonTestCheckpoint{}, "missing field root"},
+		{"no 0x prefix", `{"epoch": "1", "root": "00"}`, &jsonTestCheckpoint{}, "must start with 0x"},
+		{"short vector", `{"epoch": "1", "root": "0x00"}`, &jsonTestCheckpoint{}, "expected 32 bytes"},
+		{"overflow", `{"epoch": "18446744073709551616", "root": "0x00"}`, &jsonTestCheckpoint{}, "invalid uint64"},
+		{"list limit", `{"slot": "1", "aggregation_bits": "0x01", "source": ` + valid + `, "attesting_indices": ["1","2","3","4","5","6","7","8","9"], "eth1_deposit_root": "0x01020304", "enabled": false, "base_fee": "0"}`, &jsonTestContainer{}, "exceeds limit 8"},
+		{"bitlist without delimiter", `{"slot": "1", "aggregation_bits": "0x00", "source": ` + valid + `, "attesting_indices": [], "eth1_deposit_root": "0x01020304", "enabled": false, "base_fee": "0"}`, &jsonTestContainer{}, "aggregation_bits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, UnmarshalJSON([]byte(tt.json), tt.v), tt.wantErr)
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"ParentRoot":            "parent_root",
+		"Eth1Data":              "eth1_data",
+		"BLSToExecutionChanges": "bls_to_execution_changes",
+		"Slot":                  "slot",
+		"BlobKzgCommitments":    "blob_kzg_commitments",
+	} {
+		assert.Equal(t, want, snakeCase(name), name)
+	}
+}
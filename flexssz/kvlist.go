@@ -0,0 +1,180 @@
+package flexssz
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Maps tagged ssz:"kvlist" are encoded as a list of Key/Value containers sorted by key, e.g.
+//
+//	Balances map[[20]byte]uint64 `ssz:"kvlist" ssz-max:"1024"`
+//
+// is encoded and hashed as List[Container{Key: Vector[byte, 20], Value: uint64}, 1024]. The
+// outer ssz-max is the limit on the number of entries, and the other ssz-size and ssz-max
+// dimensions apply to the values, e.g. ssz-max:"1024,32" for a map of byte lists. Keys are
+// unsigned integers, bools, strings or byte arrays, and sort in their natural order, so equal
+// maps always have the same encoding.
+
+// kvList describes how a map type is encoded with a kvlist tag
+type kvList struct {
+	entries reflect.Type // []struct{ Key K; Value V }
+	tag     *sszTag      // Tag of the list of entries
+	info    *TypeInfo    // Type info of the list of entries
+}
+
+type kvListKey struct {
+	t   reflect.Type
+	dim string
+}
+
+// kvListCache caches the kvList of map types by their tag dimensions, since types with a tag
+// are not in the TypeInfo cache
+var kvListCache sync.Map
+
+// kvListOf returns the kvList of map type t with the given kvlist tag
+func kvListOf(t reflect.Type, tag *sszTag) (*kvList, error) {
+	if tag == nil || tag.FieldType != "kvlist" {
+		return nil, fmt.Errorf("map type %v requires ssz tag 'kvlist'", t)
+	}
+	key := kvListKey{t: t, dim: fmt.Sprint(tag.Size, tag.Max)}
+	if kv, ok := kvListCache.Load(key); ok {
+		return kv.(*kvList), nil
+	}
+
+	gen := typeCacheGeneration.Load()
+	if !isKVListKey(t.Key()) {
+		return nil, fmt.Errorf("unsupported kvlist key type %v, must be an unsigned integer, bool, string or byte array", t.Key())
+	}
+	entry := reflect.StructOf([]reflect.StructField{
+		{Name: "Key", Type: t.Key()},
+		{Name: "Value", Type: t.Elem(), Tag: kvValueTag(tag)},
+	})
+	listTag := &sszTag{
+		FieldType:  "list",
+		IsVariable: true,
+		MaxList:    tag.MaxList,
+		Max:        []int{tag.MaxList},
+	}
+	entries := reflect.SliceOf(entry)
+	info, err := GetTypeInfo(entries, listTag)
+	if err != nil {
+		return nil, fmt.Errorf("kvlist %v: %w", t, err)
+	}
+
+	kv := &kvList{entries: entries, tag: listTag, info: info}
+	storeTypeCache(gen, func() {
+		if prev, loaded := kvListCache.LoadOrStore(key, kv); loaded {
+			kv = prev.(*kvList)
+		}
+	})
+	return kv, nil
+}
+
+// isKVListKey reports whether t can be the key of a kvlist map
+func isKVListKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Bool, reflect.String:
+		return true
+	case reflect.Array:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// kvValueTag returns the struct tag of the Value field of an entry, with the inner ssz-size
+// and ssz-max dimensions of the map tag
+func kvValueTag(tag *sszTag) reflect.StructTag {
+	var parts []string
+	if len(tag.Size) > 1 {
+		parts = append(parts, `ssz-size:"`+joinTagDims(tag.Size[1:], -1)+`"`)
+	}
+	if len(tag.Max) > 1 {
+		parts = append(parts, `ssz-max:"`+joinTagDims(tag.Max[1:], 0)+`"`)
+	}
+	return reflect.StructTag(strings.Join(parts, " "))
+}
+
+// joinTagDims formats tag dimensions, writing unset dimensions as "?"
+func joinTagDims(dims []int, unset int) string {
+	parts := make([]string, len(dims))
+	for i, dim := range dims {
+		if dim == unset {
+			parts[i] = "?"
+		} else {
+			parts[i] = strconv.Itoa(dim)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sorted returns the entries of map m sorted by key
+func (kv *kvList) sorted(m reflect.Value) reflect.Value {
+	entries := reflect.MakeSlice(kv.entries, m.Len(), m.Len())
+	iter := m.MapRange()
+	for i := 0; iter.Next(); i++ {
+		entries.Index(i).Field(0).Set(iter.Key())
+		entries.Index(i).Field(1).Set(iter.Value())
+	}
+	sort.Slice(entries.Interface(), func(i, j int) bool {
+		return compareKVKeys(entries.Index(i).Field(0), entries.Index(j).Field(0)) < 0
+	})
+	return entries
+}
+
+// setMap sets map m to the decoded entries. Duplicate keys are always rejected, since the
+// map cannot hold them, and strict decoding also requires the keys to be sorted.
+func (kv *kvList) setMap(m, entries reflect.Value, strict bool) error {
+	out := reflect.MakeMapWithSize(m.Type(), entries.Len())
+	for i := 0; i < entries.Len(); i++ {
+		key := entries.Index(i).Field(0)
+		if i > 0 {
+			c := compareKVKeys(entries.Index(i-1).Field(0), key)
+			if c == 0 || out.MapIndex(key).IsValid() {
+				return fmt.Errorf("%w: duplicate kvlist key %v", ErrNonCanonical, key)
+			}
+			if c > 0 && strict {
+				return fmt.Errorf("%w: kvlist key %v is not sorted", ErrNonCanonical, key)
+			}
+		}
+		out.SetMapIndex(key, entries.Index(i).Field(1))
+	}
+	m.Set(out)
+	return nil
+}
+
+// kvListValue returns the sorted entries of map v, with the tag to encode them with
+func kvListValue(v reflect.Value, tag *sszTag) (reflect.Value, *sszTag, error) {
+	kv, err := kvListOf(v.Type(), tag)
+	if err != nil {
+		return v, nil, err
+	}
+	return kv.sorted(v), kv.tag, nil
+}
+
+// compareKVKeys compares two kvlist keys in their natural order
+func compareKVKeys(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Bool:
+		if a.Bool() == b.Bool() {
+			return 0
+		} else if a.Bool() {
+			return 1
+		}
+		return -1
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := cmp.Compare(a.Index(i).Uint(), b.Index(i).Uint()); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	return cmp.Compare(a.Uint(), b.Uint())
+}
@@ -0,0 +1,149 @@
+package flexssz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type kvListTestStruct struct {
+	Slot     uint64
+	Balances map[[4]byte]uint64  `ssz:"kvlist" ssz-max:"8"`
+	Names    map[string][]byte   `ssz:"kvlist" ssz-max:"8,16"`
+	Roots    map[uint16][][]byte `ssz:"kvlist" ssz-size:"?,?,2" ssz-max:"4,4"`
+}
+
+type kvListTestBalance struct {
+	Key   [4]byte
+	Value uint64
+}
+
+type kvListTestName struct {
+	Key   string
+	Value []byte `ssz-max:"16"`
+}
+
+type kvListTestRoots struct {
+	Key   uint16
+	Value [][]byte `ssz-size:"?,2" ssz-max:"4"`
+}
+
+// kvListTestSlices is kvListTestStruct with the maps written out as sorted lists
+type kvListTestSlices struct {
+	Slot     uint64
+	Balances []kvListTestBalance `ssz-max:"8"`
+	Names    []kvListTestName    `ssz-max:"8"`
+	Roots    []kvListTestRoots   `ssz-max:"4"`
+}
+
+func TestKVList(t *testing.T) {
+	value := &kvListTestStruct{
+		Slot:     7,
+		Balances: map[[4]byte]uint64{{3}: 30, {1}: 10, {2, 1}: 21, {2}: 20},
+		Names:    map[string][]byte{"b": {2}, "a": {1}, "": {}},
+		Roots:    map[uint16][][]byte{300: {{1, 2}}, 5: {{3, 4}, {5, 6}}},
+	}
+	sorted := &kvListTestSlices{
+		Slot:     7,
+		Balances: []kvListTestBalance{{[4]byte{1}, 10}, {[4]byte{2}, 20}, {[4]byte{2, 1}, 21}, {[4]byte{3}, 30}},
+		Names:    []kvListTestName{{"", []byte{}}, {"a", []byte{1}}, {"b", []byte{2}}},
+		Roots:    []kvListTestRoots{{5, [][]byte{{3, 4}, {5, 6}}}, {300, [][]byte{{1, 2}}}},
+	}
+
+	expected, err := Marshal(sorted)
+	require.NoError(t, err)
+	encoded, err := Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, expected, encoded)
+
+	appended, err := MarshalAppend(nil, value)
+	require.NoError(t, err)
+	assert.Equal(t, expected, appended)
+
+	expectedRoot, err := HashTreeRoot(sorted)
+	require.NoError(t, err)
+	root, err := HashTreeRoot(value)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	var decoded kvListTestStruct
+	require.NoError(t, UnmarshalStrict(encoded, &decoded))
+	assert.Equal(t, value, &decoded)
+
+	// JSON has the sorted entries too
+	expectedJSON, err := MarshalJSON(sorted)
+	require.NoError(t, err)
+	encodedJSON, err := MarshalJSON(value)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(encodedJSON))
+
+	decoded = kvListTestStruct{}
+	require.NoError(t, UnmarshalJSON(encodedJSON, &decoded))
+	assert.Equal(t, value, &decoded)
+}
+
+func TestKVList_Decode(t *testing.T) {
+	unsorted, err := Marshal(&kvListTestSlices{
+		Balances: []kvListTestBalance{{[4]byte{2}, 20}, {[4]byte{1}, 10}},
+	})
+	require.NoError(t, err)
+
+	var decoded kvListTestStruct
+	require.NoError(t, Unmarshal(unsorted, &decoded))
+	assert.Equal(t, map[[4]byte]uint64{{1}: 10, {2}: 20}, decoded.Balances)
+
+	err = UnmarshalStrict(unsorted, &decoded)
+	assert.ErrorIs(t, err, ErrNonCanonical)
+	assert.ErrorContains(t, err, "is not sorted")
+
+	// Duplicate keys cannot be decoded into a map
+	duplicate, err := Marshal(&kvListTestSlices{
+		Balances: []kvListTestBalance{{[4]byte{1}, 10}, {[4]byte{2}, 20}, {[4]byte{1}, 11}},
+	})
+	require.NoError(t, err)
+	err = Unmarshal(duplicate, &decoded)
+	assert.ErrorIs(t, err, ErrNonCanonical)
+	assert.ErrorContains(t, err, "duplicate kvlist key")
+
+	// The entries are limited by the outer ssz-max
+	tooMany, err := Marshal(&struct {
+		Slot     uint64
+		Balances []kvListTestBalance `ssz-max:"9"`
+		Names    []kvListTestName    `ssz-max:"8"`
+		Roots    []kvListTestRoots   `ssz-max:"4"`
+	}{Balances: make([]kvListTestBalance, 9)})
+	require.NoError(t, err)
+	assert.ErrorContains(t, Unmarshal(tooMany, &decoded), "exceeds")
+}
+
+func TestKVList_Errors(t *testing.T) {
+	_, err := Marshal(&kvListTestStruct{Balances: map[[4]byte]uint64{{1}: 1, {2}: 2, {3}: 3, {4}: 4, {5}: 5, {6}: 6, {7}: 7, {8}: 8, {9}: 9}})
+	assert.ErrorContains(t, err, "slice length 9 exceeds limit 8")
+	_, err = MarshalAppend(nil, &kvListTestStruct{Names: map[string][]byte{"a": make([]byte, 17)}})
+	assert.ErrorContains(t, err, "slice length 17 exceeds limit 16")
+
+	tests := map[string]any{
+		"ssz tag 'kvlist' requires map type": &struct {
+			A []uint64 `ssz:"kvlist" ssz-max:"4"`
+		}{},
+		"kvlist requires ssz-max tag": &struct {
+			A map[uint64]uint64 `ssz:"kvlist"`
+		}{},
+		"unsupported type map[uint64]uint64": &struct {
+			A map[uint64]uint64 `ssz-max:"4"`
+		}{},
+		"unsupported kvlist key type int": &struct {
+			A map[int]uint64 `ssz:"kvlist" ssz-max:"4"`
+		}{},
+		"kvlist ssz-size must start with '?'": &struct {
+			A map[uint64][]byte `ssz:"kvlist" ssz-size:"4,4"`
+		}{},
+	}
+	for expected, v := range tests {
+		t.Run(expected, func(t *testing.T) {
+			_, err := Marshal(v)
+			assert.ErrorContains(t, err, expected)
+		})
+	}
+}
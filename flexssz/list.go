@@ -0,0 +1,298 @@
+package flexssz
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// Marshal, Unmarshal and HashTreeRoot have no limit for a slice at the root, since there is no
+// struct tag to provide one. The functions below handle values whose root is a List[T, limit]
+// or Vector[T, size], with the limit or size passed explicitly.
+
+// MarshalList encodes a slice as List[T, limit]
+func MarshalList(v any, limit uint64) ([]byte, error) {
+	rv, typeInfo, err := listRoot(reflect.ValueOf(v), limit)
+	if err != nil {
+		return nil, err
+	}
+	return marshalRoot(context.Background(), rv, typeInfo)
+}
+
+// MarshalVector encodes a slice or array as Vector[T, size]
+func MarshalVector(v any, size uint64) ([]byte, error) {
+	rv, typeInfo, err := vectorRoot(reflect.ValueOf(v), size)
+	if err != nil {
+		return nil, err
+	}
+	return marshalRoot(context.Background(), rv, typeInfo)
+}
+
+// UnmarshalList decodes List[T, limit] into out, which must be a pointer to a slice
+func UnmarshalList(data []byte, out any, limit uint64) error {
+	rv, err := unmarshalTarget(out)
+	if err != nil {
+		return err
+	}
+	rv, typeInfo, err := listRoot(rv, limit)
+	if err != nil {
+		return err
+	}
+
+	err = withProfileLabels(context.Background(), rv.Type(), PhaseUnmarshal, func() error {
+		fieldInfo := &FieldInfo{Type: typeInfo}
+		return asDecodeError(decodeList(NewDecoder(data), rv, fieldInfo))
+	})
+	recordStats(rv.Type(), PhaseUnmarshal, len(data), err)
+	return err
+}
+
+// UnmarshalVector decodes Vector[T, size] into out, which must be a pointer to a slice or array
+func UnmarshalVector(data []byte, out any, size uint64) error {
+	rv, err := unmarshalTarget(out)
+	if err != nil {
+		return err
+	}
+	rv, typeInfo, err := vectorRoot(rv, size)
+	if err != nil {
+		return err
+	}
+	if typeInfo.ElementType.IsVariable {
+		return fmt.Errorf("vectors of variable-size elements are not supported")
+	}
+	if len(data) != typeInfo.FixedSize {
+		return &DecodeError{Err: fmt.Errorf("vector of %d elements takes %d bytes, got %d", size, typeInfo.FixedSize, len(data))}
+	}
+
+	err = withProfileLabels(context.Background(), rv.Type(), PhaseUnmarshal, func() error {
+		fieldInfo := &FieldInfo{Type: typeInfo}
+		return asDecodeError(decodeFixedField(NewDecoder(data), rv, fieldInfo))
+	})
+	recordStats(rv.Type(), PhaseUnmarshal, len(data), err)
+	return err
+}
+
+// HashTreeRootList calculates the hash tree root of a slice as List[T, limit]
+func HashTreeRootList(v any, limit uint64) ([32]byte, error) {
+	rv, typeInfo, err := listRoot(reflect.ValueOf(v), limit)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if uint64(rv.Len()) > limit {
+		return [32]byte{}, fmt.Errorf("list length %d exceeds limit %d", rv.Len(), limit)
+	}
+	return hashSequence(rv, typeInfo)
+}
+
+// HashTreeRootVector calculates the hash tree root of a slice or array as Vector[T, size]
+func HashTreeRootVector(v any, size uint64) ([32]byte, error) {
+	rv, typeInfo, err := vectorRoot(reflect.ValueOf(v), size)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if uint64(rv.Len()) != size {
+		return [32]byte{}, fmt.Errorf("vector length %d does not match size %d", rv.Len(), size)
+	}
+	return hashSequence(rv, typeInfo)
+}
+
+// Option gives the SSZ type of a value hashed by HashTreeRootValue, as the struct tags of a field
+// holding it would
+type Option func(*valueTags)
+
+// valueTags are the struct tags set by options
+type valueTags struct {
+	ssz, size, max string
+}
+
+// WithLimit hashes a slice as a list with the given limit, like ssz-max
+func WithLimit(limit uint64) Option {
+	return func(t *valueTags) {
+		t.max = strconv.FormatUint(limit, 10)
+	}
+}
+
+// WithSize hashes a slice as a vector of the given size, like ssz-size
+func WithSize(size uint64) Option {
+	return func(t *valueTags) {
+		t.size = strconv.FormatUint(size, 10)
+	}
+}
+
+// WithTags hashes a value as a field with the given ssz, ssz-size and ssz-max struct tags, e.g.
+// `ssz:"bitlist" ssz-max:"2048"`
+func WithTags(tag reflect.StructTag) Option {
+	return func(t *valueTags) {
+		if v, ok := tag.Lookup("ssz"); ok {
+			t.ssz = v
+		}
+		if v, ok := tag.Lookup("ssz-size"); ok {
+			t.size = v
+		}
+		if v, ok := tag.Lookup("ssz-max"); ok {
+			t.max = v
+		}
+	}
+}
+
+// structTag returns the tags as the struct tag of a field
+func (t *valueTags) structTag() reflect.StructTag {
+	var parts []string
+	if t.ssz != "" {
+		parts = append(parts, fmt.Sprintf("ssz:%q", t.ssz))
+	}
+	if t.size != "" {
+		parts = append(parts, fmt.Sprintf("ssz-size:%q", t.size))
+	}
+	if t.max != "" {
+		parts = append(parts, fmt.Sprintf("ssz-max:%q", t.max))
+	}
+	return reflect.StructTag(strings.Join(parts, " "))
+}
+
+// HashTreeRootValue calculates the hash tree root of any value, not only containers. The limit of
+// a list, the size of a slice vector or the bitfield type of a byte slice are given by options,
+// since there is no struct tag at the root to provide them:
+//
+//	root, err := HashTreeRootValue(balances, WithLimit(1099511627776))
+//	root, err := HashTreeRootValue(bits, WithTags(`ssz:"bitlist" ssz-max:"2048"`))
+//
+// Without options it hashes like HashTreeRoot.
+func HashTreeRootValue(v any, opts ...Option) ([32]byte, error) {
+	rv, err := derefRoot(reflect.ValueOf(v))
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if len(opts) == 0 {
+		return HashTreeRoot(rv.Interface())
+	}
+
+	var tags valueTags
+	for _, opt := range opts {
+		opt(&tags)
+	}
+	tag, err := parseSSZTags(reflect.StructField{Name: "value", Type: rv.Type(), Tag: tags.structTag()})
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if tag.Skip {
+		return [32]byte{}, fmt.Errorf("cannot hash a skipped value")
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), tag)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("error getting type info: %w", err)
+	}
+
+	switch typeInfo.Type {
+	case ssz.TypeList:
+		if typeInfo.Length > 0 && rv.Len() > typeInfo.Length {
+			return [32]byte{}, fmt.Errorf("list length %d exceeds limit %d", rv.Len(), typeInfo.Length)
+		}
+	case ssz.TypeVector:
+		if rv.Kind() == reflect.Slice && rv.Len() != typeInfo.Length {
+			return [32]byte{}, fmt.Errorf("vector length %d does not match size %d", rv.Len(), typeInfo.Length)
+		}
+	}
+	return hashSequence(rv, typeInfo)
+}
+
+// listRoot dereferences v and returns the type info of List[T, limit] for it
+func listRoot(rv reflect.Value, limit uint64) (reflect.Value, *TypeInfo, error) {
+	rv, err := derefRoot(rv)
+	if err != nil {
+		return rv, nil, err
+	}
+	if rv.Kind() != reflect.Slice {
+		return rv, nil, fmt.Errorf("list must be a slice, got %v", rv.Type())
+	}
+	if limit == 0 {
+		return rv, nil, fmt.Errorf("list limit must be greater than zero")
+	}
+	if limit > math.MaxInt {
+		return rv, nil, fmt.Errorf("list limit %d is larger than the largest int", limit)
+	}
+
+	tag := &sszTag{
+		FieldType:  "list",
+		IsVariable: true,
+		MaxList:    int(limit),
+		Max:        []int{int(limit)},
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), tag)
+	if err != nil {
+		return rv, nil, fmt.Errorf("error getting type info: %w", err)
+	}
+	return rv, typeInfo, nil
+}
+
+// vectorRoot dereferences v and returns the type info of Vector[T, size] for it
+func vectorRoot(rv reflect.Value, size uint64) (reflect.Value, *TypeInfo, error) {
+	rv, err := derefRoot(rv)
+	if err != nil {
+		return rv, nil, err
+	}
+	if size == 0 {
+		return rv, nil, fmt.Errorf("vector size must be greater than zero")
+	}
+	if size > math.MaxInt {
+		return rv, nil, fmt.Errorf("vector size %d is larger than the largest int", size)
+	}
+
+	var tag *sszTag
+	switch rv.Kind() {
+	case reflect.Slice:
+		tag = &sszTag{FieldType: "vector", Size: []int{int(size)}}
+	case reflect.Array:
+		if uint64(rv.Len()) != size {
+			return rv, nil, fmt.Errorf("array length %d does not match size %d", rv.Len(), size)
+		}
+	default:
+		return rv, nil, fmt.Errorf("vector must be a slice or array, got %v", rv.Type())
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), tag)
+	if err != nil {
+		return rv, nil, fmt.Errorf("error getting type info: %w", err)
+	}
+	return rv, typeInfo, nil
+}
+
+// derefRoot follows pointers to the root value
+func derefRoot(rv reflect.Value) (reflect.Value, error) {
+	if !rv.IsValid() {
+		return rv, fmt.Errorf("cannot encode nil value")
+	}
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return rv, fmt.Errorf("cannot encode nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	return rv, nil
+}
+
+// unmarshalTarget checks that out is a non-nil pointer and returns the value it points to
+func unmarshalTarget(out any) (reflect.Value, error) {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr {
+		return rv, fmt.Errorf("v must be a pointer, got %v", rv.Kind())
+	}
+	if rv.IsNil() {
+		return rv, fmt.Errorf("v must not be nil")
+	}
+	return rv.Elem(), nil
+}
+
+// hashSequence calculates the hash tree root of a root value with the given type info
+func hashSequence(rv reflect.Value, typeInfo *TypeInfo) (root [32]byte, err error) {
+	err = withProfileLabels(context.Background(), rv.Type(), PhaseHashTreeRoot, func() error {
+		root, err = hashTreeRoot(rv, typeInfo, nil)
+		return err
+	})
+	recordStats(rv.Type(), PhaseHashTreeRoot, 0, err)
+	return root, err
+}
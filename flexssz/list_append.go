@@ -0,0 +1,72 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// AppendToList appends items to list, the list field named fieldName of the container owner
+// points to, e.g. AppendToList(&state.Validators, state, "Validators", v). The limit of the
+// field, from its ssz-max tag or its type, is enforced before anything is appended, so a list
+// that is too long is reported where it grows instead of when the container is encoded. On
+// error the list is left unchanged. The error wraps ErrListFull when the limit would be exceeded.
+func AppendToList[S ~[]T, T any](list *S, owner any, fieldName string, items ...T) error {
+	limit, err := listFieldLimit(reflect.ValueOf(list).Pointer(), owner, fieldName)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(*list)+len(items) > limit {
+		return fmt.Errorf("%s: %w: %d elements, limit %d", fieldName, ErrListFull, len(*list)+len(items), limit)
+	}
+	*list = append(*list, items...)
+	return nil
+}
+
+// AppendToListCached is AppendToList, also invalidating the root of owner in cache after a
+// successful append. Containers holding owner must still be invalidated by the caller, see
+// HashCache.
+func AppendToListCached[S ~[]T, T any](cache *HashCache, list *S, owner any, fieldName string, items ...T) error {
+	if err := AppendToList(list, owner, fieldName, items...); err != nil {
+		return err
+	}
+	cache.Invalidate(owner)
+	return nil
+}
+
+// listFieldLimit returns the limit of the list field named fieldName of the container owner
+// points to, checking that list points to that field. It returns 0 for lists without a limit.
+func listFieldLimit(list uintptr, owner any, fieldName string) (int, error) {
+	rv := reflect.ValueOf(owner)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return 0, fmt.Errorf("owner must be a non-nil pointer to a struct, got %T", owner)
+	}
+	typeInfo, err := GetTypeInfo(rv.Elem().Type(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("error getting type info: %w", err)
+	}
+
+	for i := range typeInfo.Fields {
+		field := &typeInfo.Fields[i]
+		if field.Name != fieldName {
+			continue
+		}
+		fv := field.value(rv.Elem())
+		if fv.Kind() != reflect.Slice || fv.Addr().Pointer() != list {
+			return 0, fmt.Errorf("%s: list is not this field of %T", fieldName, owner)
+		}
+		if field.Type.Type != ssz.TypeList || field.Type.kvlist != nil {
+			return 0, fmt.Errorf("%s: %v is not a list", fieldName, field.Type.Type)
+		}
+		if field.Type.Progressive {
+			return 0, nil
+		}
+		limit := field.Type.Length
+		if limit == 0 {
+			limit = typeDimensions(fv.Type()).max
+		}
+		return limit, nil
+	}
+	return 0, fmt.Errorf("%T has no ssz field %s", owner, fieldName)
+}
@@ -0,0 +1,61 @@
+package flexssz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listAppendTestBody struct {
+	Deposits []uint64         `ssz-max:"2"`
+	Balances tagsTestBalances // Limit carried by the type
+	Root     [32]byte
+}
+
+type listAppendTestBlock struct {
+	Slot uint64
+	Body listAppendTestBody
+}
+
+func TestAppendToList(t *testing.T) {
+	body := &listAppendTestBody{}
+	require.NoError(t, AppendToList(&body.Deposits, body, "Deposits", 1))
+	require.NoError(t, AppendToList(&body.Deposits, body, "Deposits", 2))
+	assert.Equal(t, []uint64{1, 2}, body.Deposits)
+
+	err := AppendToList(&body.Deposits, body, "Deposits", 3)
+	assert.ErrorIs(t, err, ErrListFull)
+	assert.ErrorContains(t, err, "Deposits")
+	assert.Equal(t, []uint64{1, 2}, body.Deposits, "the list is unchanged on error")
+
+	// Limits carried by the type are enforced too
+	require.NoError(t, AppendToList(&body.Balances, body, "Balances", 1, 2, 3, 4))
+	assert.ErrorIs(t, AppendToList(&body.Balances, body, "Balances", 5), ErrListFull)
+
+	t.Run("misuse", func(t *testing.T) {
+		other := &listAppendTestBody{}
+		assert.ErrorContains(t, AppendToList(&other.Deposits, body, "Deposits", 1), "not this field")
+		assert.ErrorContains(t, AppendToList(&body.Deposits, body, "Missing", 1), "no ssz field Missing")
+		assert.ErrorContains(t, AppendToList(&body.Deposits, *body, "Deposits", 1), "non-nil pointer")
+		root := body.Root[:]
+		assert.Error(t, AppendToList(&root, body, "Root", 1))
+	})
+}
+
+func TestAppendToListCached(t *testing.T) {
+	block := &listAppendTestBlock{Slot: 1}
+	cache := NewHashCache()
+	_, err := HashTreeRootWithCache(block, cache)
+	require.NoError(t, err)
+
+	require.NoError(t, AppendToListCached(cache, &block.Body.Deposits, &block.Body, "Deposits", 7))
+	cached, err := HashTreeRootWithCache(block, cache)
+	require.NoError(t, err)
+	expected, err := HashTreeRoot(block)
+	require.NoError(t, err)
+	assert.Equal(t, expected, cached, "the body root was invalidated")
+
+	require.NoError(t, AppendToListCached(cache, &block.Body.Deposits, &block.Body, "Deposits", 8))
+	assert.ErrorIs(t, AppendToListCached(cache, &block.Body.Deposits, &block.Body, "Deposits", 9), ErrListFull)
+}
@@ -0,0 +1,296 @@
+package flexssz
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// ListIter gives random access to the elements of a serialized list or vector without decoding
+// the rest of the value, e.g. to scan the validators of a beacon state one at a time
+type ListIter struct {
+	data     []byte
+	base     int
+	elemType *TypeInfo
+	goType   reflect.Type
+	length   int
+	// offsets of variable-size elements, relative to data
+	offsets []int
+}
+
+// ListIterator locates the list or vector at fieldPath in data, the serialized form of a T.
+// fieldPath is a dot-separated path of Go field names, e.g. "Validators" or "Body.Attestations".
+// Only the offsets along the path are read, so data can be a large value or a memory map.
+func ListIterator[T any](data []byte, fieldPath string) (*ListIter, error) {
+	start, end, rt, typeInfo, err := locatePath(data, reflect.TypeFor[T](), fieldPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if rt.Kind() != reflect.Slice && rt.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%s: %v is not a list or vector", fieldPath, rt)
+	}
+	if typeInfo.Type != ssz.TypeList && typeInfo.Type != ssz.TypeVector {
+		return nil, fmt.Errorf("%s: %s is not a list or vector", fieldPath, typeInfo.Type)
+	}
+
+	it := &ListIter{
+		data:     data[start:end],
+		base:     start,
+		elemType: typeInfo.ElementType,
+		goType:   rt.Elem(),
+	}
+	if err := it.index(typeInfo); err != nil {
+		return nil, &DecodeError{Path: fieldPath, Offset: start, Err: err}
+	}
+	return it, nil
+}
+
+// NewLazyList indexes the offsets of data, a serialized list of elements of type elemInfo, so
+// single elements can be read or decoded without decoding the whole list. elemInfo is the
+// TypeInfo of the Go type passed to DecodeAt, e.g. from GetTypeInfo. The list is not checked
+// against a limit.
+func NewLazyList(data []byte, elemInfo *TypeInfo) (*ListIter, error) {
+	if elemInfo == nil {
+		return nil, fmt.Errorf("element type info is required")
+	}
+	it := &ListIter{
+		data:     data,
+		elemType: elemInfo,
+		goType:   elemInfo.BasicType, // Only known for basic types
+	}
+	if err := it.index(&TypeInfo{Type: ssz.TypeList}); err != nil {
+		return nil, &DecodeError{Err: err}
+	}
+	return it, nil
+}
+
+// locatePath returns the bounds of the field at fieldPath in data, the serialized form of a rt,
+// and the Go type and type info of the field. Pointer types are dereferenced.
+func locatePath(data []byte, rt reflect.Type, fieldPath string) (int, int, reflect.Type, *TypeInfo, error) {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	typeInfo, err := GetTypeInfo(rt, nil)
+	if err != nil {
+		return 0, 0, nil, nil, fmt.Errorf("error getting type info: %w", err)
+	}
+
+	start, end := 0, len(data)
+	for _, name := range strings.Split(fieldPath, ".") {
+		if w := typeInfo.Wrapped; w != nil && rt.Kind() == reflect.Struct {
+			// Fields of a transparent wrapper are fields of the wrapped value
+			rt, typeInfo = rt.Field(w.Index).Type, w.Type
+			for rt.Kind() == reflect.Ptr {
+				rt = rt.Elem()
+			}
+		}
+		if typeInfo.Type != ssz.TypeContainer || typeInfo.Custom {
+			return 0, 0, nil, nil, fmt.Errorf("%s: %v is not a container", fieldPath, rt)
+		}
+		fieldStart, fieldEnd, field, err := locateField(data, start, end, typeInfo, name)
+		if err != nil {
+			return 0, 0, nil, nil, &DecodeError{Path: fieldPath, Offset: start, Err: err}
+		}
+		start, end = fieldStart, fieldEnd
+		rt = field.structField(rt).Type
+		for rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		typeInfo = field.Type
+	}
+	return start, end, rt, typeInfo, nil
+}
+
+// locateField returns the bounds of the named field of the container in data[start:end]
+func locateField(data []byte, start, end int, typeInfo *TypeInfo, name string) (int, int, *FieldInfo, error) {
+	// Position of each field in the fixed part, and the offsets of the variable fields
+	pos := start
+	target := -1
+	var offsets []int
+	var targetOffset int
+	for i := range typeInfo.Fields {
+		field := &typeInfo.Fields[i]
+		if field.Name == name {
+			target = i
+			targetOffset = len(offsets)
+		}
+		if !field.Type.IsVariable {
+			if field.Name == name {
+				fieldEnd := pos + field.Type.FixedSize
+				if fieldEnd > end {
+					return 0, 0, nil, fmt.Errorf("field %s ends at %d, past the end of the data (%d)", name, fieldEnd, end)
+				}
+				return pos, fieldEnd, field, nil
+			}
+			pos += field.Type.FixedSize
+			continue
+		}
+		if pos+4 > end {
+			return 0, 0, nil, fmt.Errorf("offset of field %s: unexpected end of data", field.Name)
+		}
+		offset, ok := offsetWithin(order.Uint32(data[pos:pos+4]), end-start)
+		if !ok {
+			return 0, 0, nil, fmt.Errorf("invalid offset for field %s: past the end of the data (%d)", field.Name, end-start)
+		}
+		offsets = append(offsets, start+offset)
+		pos += 4
+	}
+	if target < 0 {
+		return 0, 0, nil, fmt.Errorf("no field %s", name)
+	}
+
+	fieldStart := offsets[targetOffset]
+	fieldEnd := end
+	if targetOffset+1 < len(offsets) {
+		fieldEnd = offsets[targetOffset+1]
+	}
+	if fieldStart < pos || fieldStart > fieldEnd || fieldEnd > end {
+		return 0, 0, nil, fmt.Errorf("invalid offset for field %s: start=%d, end=%d, len=%d", name, fieldStart, fieldEnd, end)
+	}
+	return fieldStart, fieldEnd, &typeInfo.Fields[target], nil
+}
+
+// index finds the number of elements, and the offsets of variable-size elements
+func (it *ListIter) index(typeInfo *TypeInfo) error {
+	if !it.elemType.IsVariable {
+		size := it.elemType.FixedSize
+		if size <= 0 {
+			return fmt.Errorf("element type has invalid size %d", size)
+		}
+		if len(it.data)%size != 0 {
+			return fmt.Errorf("%d bytes cannot be divided by element size %d", len(it.data), size)
+		}
+		it.length = len(it.data) / size
+		if typeInfo.Type == ssz.TypeVector && it.length != typeInfo.Length {
+			return fmt.Errorf("vector has %d elements, expected %d", it.length, typeInfo.Length)
+		}
+		return nil
+	}
+
+	if len(it.data) == 0 {
+		return nil
+	}
+	if len(it.data) < 4 {
+		return fmt.Errorf("list of %d bytes is too short for an offset", len(it.data))
+	}
+	first, ok := offsetWithin(order.Uint32(it.data), len(it.data))
+	if !ok || first%4 != 0 {
+		return fmt.Errorf("invalid first offset %d", order.Uint32(it.data))
+	}
+	it.length = first / 4
+	it.offsets = make([]int, it.length+1)
+	for i := 0; i < it.length; i++ {
+		offset, ok := offsetWithin(order.Uint32(it.data[4*i:]), len(it.data))
+		if !ok || (i > 0 && offset < it.offsets[i-1]) {
+			return fmt.Errorf("invalid offset %d for element %d", order.Uint32(it.data[4*i:]), i)
+		}
+		it.offsets[i] = offset
+	}
+	it.offsets[it.length] = len(it.data)
+	return nil
+}
+
+// Len returns the number of elements
+func (it *ListIter) Len() int {
+	return it.length
+}
+
+// At returns the serialized element i. The slice shares memory with the data of the iterator.
+func (it *ListIter) At(i int) ([]byte, error) {
+	if i < 0 || i >= it.length {
+		return nil, NewErrIndexOutOfBounds(i, it.length)
+	}
+	if it.offsets != nil {
+		return it.data[it.offsets[i]:it.offsets[i+1]], nil
+	}
+	size := it.elemType.FixedSize
+	return it.data[i*size : (i+1)*size], nil
+}
+
+// DecodeAt decodes element i into into, which must be a pointer to the element type. For a
+// NewLazyList, the element type is the type its TypeInfo was parsed from.
+func (it *ListIter) DecodeAt(i int, into any) error {
+	rv := reflect.ValueOf(into)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("into must be a non-nil pointer, got %T", into)
+	}
+	elem := rv.Elem()
+	if it.goType != nil && elem.Type() != it.goType && !(it.goType.Kind() == reflect.Ptr && elem.Type() == it.goType.Elem()) {
+		return fmt.Errorf("into must point to %v, got %T", it.goType, into)
+	}
+
+	data, err := it.At(i)
+	if err != nil {
+		return err
+	}
+	d := NewDecoder(data)
+	var elemOffset int
+	if it.offsets != nil {
+		elemOffset = it.offsets[i]
+	} else {
+		elemOffset = i * it.elemType.FixedSize
+	}
+	d.base = it.base + elemOffset
+
+	fieldInfo := &FieldInfo{Type: it.elemType, Name: "root"}
+	if err := decodeValue(d, elem, fieldInfo); err != nil {
+		return asDecodeError(wrapDecodeError(err, indexSegment(i), d.base))
+	}
+	return nil
+}
+
+// Search returns the smallest index i for which f returns true for element i, or Len() if there
+// is none, like sort.Search. Elements must be ordered so that f is false and then true, e.g. by a
+// key at a fixed position in each element.
+func (it *ListIter) Search(f func(elem []byte) bool) int {
+	return sort.Search(it.length, func(i int) bool {
+		elem, _ := it.At(i)
+		return f(elem)
+	})
+}
+
+// FindInSerializedList binary searches the fixed-size elements of the list or vector at fieldPath
+// in data, the serialized form of a T, for the element whose bytes at keyOffset to
+// keyOffset+keySize equal key. Elements must be sorted by that key. It returns the index of the
+// first matching element and true, or the index where key would be inserted and false.
+func FindInSerializedList[T any](data []byte, fieldPath string, keyOffset, keySize int, key []byte) (int, bool, error) {
+	it, err := ListIterator[T](data, fieldPath)
+	if err != nil {
+		return 0, false, err
+	}
+	return it.FindKey(keyOffset, keySize, key)
+}
+
+// FindKey binary searches fixed-size elements sorted by the key at keyOffset, see
+// FindInSerializedList
+func (it *ListIter) FindKey(keyOffset, keySize int, key []byte) (int, bool, error) {
+	if it.elemType.IsVariable {
+		return 0, false, fmt.Errorf("cannot search variable-size elements by key")
+	}
+	if len(key) != keySize {
+		return 0, false, fmt.Errorf("key has %d bytes, expected %d", len(key), keySize)
+	}
+	if keyOffset < 0 || keySize <= 0 || keyOffset+keySize > it.elemType.FixedSize {
+		return 0, false, fmt.Errorf("key at offset %d with size %d is outside the element size %d", keyOffset, keySize, it.elemType.FixedSize)
+	}
+
+	keyOf := func(elem []byte) []byte {
+		return elem[keyOffset : keyOffset+keySize]
+	}
+	i := it.Search(func(elem []byte) bool {
+		return bytes.Compare(keyOf(elem), key) >= 0
+	})
+	if i == it.length {
+		return i, false, nil
+	}
+	elem, err := it.At(i)
+	if err != nil {
+		return 0, false, err
+	}
+	return i, bytes.Equal(keyOf(elem), key), nil
+}
@@ -0,0 +1,197 @@
+package flexssz
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type iterTestValidator struct {
+	Pubkey  [48]byte `ssz-size:"48"`
+	Balance uint64
+}
+
+type iterTestBody struct {
+	Graffiti [32]byte        `ssz-size:"32"`
+	Items    []*listTestItem `ssz-max:"8"`
+}
+
+type iterTestState struct {
+	Slot       uint64
+	Validators []iterTestValidator `ssz-max:"1024"`
+	Body       *iterTestBody
+	Mixes      [][32]byte `ssz-size:"4,32"`
+}
+
+func TestListIterator(t *testing.T) {
+	state := &iterTestState{
+		Slot:  10,
+		Body:  &iterTestBody{Items: []*listTestItem{{A: 1, B: []byte{1}}, {A: 2}, {A: 3, B: []byte{3, 3}}}},
+		Mixes: [][32]byte{{1}, {2}, {3}, {4}},
+	}
+	// Validators sorted by pubkey
+	for i := 0; i < 100; i++ {
+		v := iterTestValidator{Balance: uint64(i) * 1000}
+		v.Pubkey[0] = byte(i * 2)
+		state.Validators = append(state.Validators, v)
+	}
+	data, err := Marshal(state)
+	require.NoError(t, err)
+
+	t.Run("fixed elements", func(t *testing.T) {
+		it, err := ListIterator[iterTestState](data, "Validators")
+		require.NoError(t, err)
+		require.Equal(t, 100, it.Len())
+
+		elem, err := it.At(42)
+		require.NoError(t, err)
+		assert.Len(t, elem, 56)
+
+		var v iterTestValidator
+		require.NoError(t, it.DecodeAt(42, &v))
+		assert.Equal(t, state.Validators[42], v)
+
+		_, err = it.At(100)
+		assert.ErrorIs(t, err, ErrIndexOutOfBounds)
+	})
+
+	t.Run("search by pubkey", func(t *testing.T) {
+		it, err := ListIterator[*iterTestState](data, "Validators")
+		require.NoError(t, err)
+
+		key := []byte{84}
+		i := it.Search(func(elem []byte) bool { return bytes.Compare(elem[:1], key) >= 0 })
+		assert.Equal(t, 42, i)
+
+		i = it.Search(func(elem []byte) bool { return bytes.Compare(elem[:1], []byte{255}) >= 0 })
+		assert.Equal(t, it.Len(), i)
+	})
+
+	t.Run("variable elements in a nested container", func(t *testing.T) {
+		it, err := ListIterator[iterTestState](data, "Body.Items")
+		require.NoError(t, err)
+		require.Equal(t, 3, it.Len())
+
+		for i, want := range state.Body.Items {
+			var item listTestItem
+			require.NoError(t, it.DecodeAt(i, &item))
+			assert.Equal(t, want.A, item.A)
+			assert.Equal(t, len(want.B), len(item.B))
+		}
+	})
+
+	t.Run("vector", func(t *testing.T) {
+		it, err := ListIterator[iterTestState](data, "Mixes")
+		require.NoError(t, err)
+		require.Equal(t, 4, it.Len())
+		var mix [32]byte
+		require.NoError(t, it.DecodeAt(3, &mix))
+		assert.Equal(t, [32]byte{4}, mix)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ListIterator[iterTestState](data, "Missing")
+		assert.ErrorContains(t, err, "no field Missing")
+		_, err = ListIterator[iterTestState](data, "Slot")
+		assert.ErrorContains(t, err, "not a list or vector")
+		_, err = ListIterator[iterTestState](data, "Slot.Items")
+		assert.ErrorContains(t, err, "not a container")
+		_, err = ListIterator[iterTestState](data[:20], "Body.Items")
+		var decodeErr *DecodeError
+		assert.ErrorAs(t, err, &decodeErr)
+
+		it, err := ListIterator[iterTestState](data, "Validators")
+		require.NoError(t, err)
+		var wrong uint64
+		assert.ErrorContains(t, it.DecodeAt(0, &wrong), "into must point to")
+	})
+}
+
+func TestNewLazyList(t *testing.T) {
+	items := []listTestItem{{A: 1, B: []byte{1}}, {A: 2}, {A: 3, B: []byte{3, 3}}}
+	data, err := MarshalList(items, 8)
+	require.NoError(t, err)
+	elemInfo, err := GetTypeInfo(reflect.TypeFor[listTestItem](), nil)
+	require.NoError(t, err)
+
+	list, err := NewLazyList(data, elemInfo)
+	require.NoError(t, err)
+	require.Equal(t, 3, list.Len())
+	for i, want := range items {
+		var item listTestItem
+		require.NoError(t, list.DecodeAt(i, &item))
+		assert.Equal(t, want.A, item.A)
+		assert.Equal(t, len(want.B), len(item.B))
+	}
+	elem, err := list.At(2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 0, 0, 0, 8, 0, 0, 0, 3, 3}, elem)
+
+	// Fixed-size elements
+	balances, err := MarshalList([]uint64{10, 20, 30}, 8)
+	require.NoError(t, err)
+	elemInfo, err = GetTypeInfo(reflect.TypeFor[uint64](), nil)
+	require.NoError(t, err)
+	list, err = NewLazyList(balances, elemInfo)
+	require.NoError(t, err)
+	require.Equal(t, 3, list.Len())
+	var balance uint64
+	require.NoError(t, list.DecodeAt(1, &balance))
+	assert.Equal(t, uint64(20), balance)
+	var wrong uint32
+	assert.ErrorContains(t, list.DecodeAt(1, &wrong), "into must point to uint64")
+	_, err = list.At(3)
+	assert.ErrorIs(t, err, ErrIndexOutOfBounds)
+
+	_, err = NewLazyList(balances[:7], elemInfo)
+	assert.ErrorContains(t, err, "cannot be divided by element size 8")
+	_, err = NewLazyList([]byte{5, 0, 0, 0}, &TypeInfo{Type: ssz.TypeList, IsVariable: true, FixedSize: -1})
+	assert.ErrorContains(t, err, "invalid first offset 5")
+}
+
+func TestFindInSerializedList(t *testing.T) {
+	state := &iterTestState{Body: &iterTestBody{}, Mixes: [][32]byte{{1}, {2}, {3}, {4}}}
+	for i := 0; i < 50; i++ {
+		v := iterTestValidator{Balance: uint64(i)}
+		v.Pubkey[0] = byte(i * 2)
+		state.Validators = append(state.Validators, v)
+	}
+	data, err := Marshal(state)
+	require.NoError(t, err)
+
+	key := make([]byte, 4)
+	key[0] = 60
+	i, found, err := FindInSerializedList[iterTestState](data, "Validators", 0, 4, key)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 30, i)
+
+	key[0] = 61
+	i, found, err = FindInSerializedList[iterTestState](data, "Validators", 0, 4, key)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, 31, i)
+
+	key[0] = 255
+	i, found, err = FindInSerializedList[iterTestState](data, "Validators", 0, 4, key)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, 50, i)
+
+	// Search the balance field, which is little endian so only sorted by its first byte here
+	i, found, err = FindInSerializedList[iterTestState](data, "Validators", 48, 1, []byte{7})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 7, i)
+
+	_, _, err = FindInSerializedList[iterTestState](data, "Validators", 50, 8, make([]byte, 8))
+	assert.ErrorContains(t, err, "outside the element size 56")
+	_, _, err = FindInSerializedList[iterTestState](data, "Validators", 0, 4, make([]byte, 3))
+	assert.ErrorContains(t, err, "key has 3 bytes, expected 4")
+	_, _, err = FindInSerializedList[iterTestState](data, "Body.Items", 0, 8, make([]byte, 8))
+	assert.ErrorContains(t, err, "variable-size")
+}
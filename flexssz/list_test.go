@@ -0,0 +1,406 @@
+package flexssz
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listTestItem struct {
+	A uint32
+	B []byte `ssz-max:"8"`
+}
+
+func TestMarshalList(t *testing.T) {
+	t.Run("basic elements", func(t *testing.T) {
+		encoded, err := MarshalList([]uint64{1, 2}, 4)
+		require.NoError(t, err)
+
+		expected := binary.LittleEndian.AppendUint64(nil, 1)
+		expected = binary.LittleEndian.AppendUint64(expected, 2)
+		assert.Equal(t, expected, encoded)
+
+		var decoded []uint64
+		require.NoError(t, UnmarshalList(encoded, &decoded, 4))
+		assert.Equal(t, []uint64{1, 2}, decoded)
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		encoded, err := MarshalList([]byte{1, 2, 3}, 32)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 3}, encoded)
+
+		var decoded []byte
+		require.NoError(t, UnmarshalList(encoded, &decoded, 32))
+		assert.Equal(t, []byte{1, 2, 3}, decoded)
+	})
+
+	t.Run("variable elements match a wrapped list", func(t *testing.T) {
+		type Wrapper struct {
+			Items []*listTestItem `ssz-max:"4"`
+		}
+		items := []*listTestItem{{A: 1, B: []byte{1, 2}}, {A: 2}, {A: 3, B: []byte{3}}}
+
+		encoded, err := MarshalList(items, 4)
+		require.NoError(t, err)
+
+		// The wrapper only adds the offset of its single field
+		wrapped, err := Marshal(&Wrapper{Items: items})
+		require.NoError(t, err)
+		assert.Equal(t, wrapped[4:], encoded)
+
+		var decoded []*listTestItem
+		require.NoError(t, UnmarshalList(encoded, &decoded, 4))
+		require.Len(t, decoded, 3)
+		assert.Equal(t, uint32(3), decoded[2].A)
+		assert.Equal(t, []byte{3}, decoded[2].B)
+
+		// A container with a single field has the root of that field
+		root, err := HashTreeRootList(items, 4)
+		require.NoError(t, err)
+		wrappedRoot, err := HashTreeRoot(&Wrapper{Items: items})
+		require.NoError(t, err)
+		assert.Equal(t, wrappedRoot, root)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		_, err := MarshalList([]uint64{1, 2, 3}, 2)
+		assert.ErrorContains(t, err, "exceeds limit")
+		_, err = HashTreeRootList([]uint64{1, 2, 3}, 2)
+		assert.ErrorContains(t, err, "exceeds limit")
+		_, err = MarshalList([]uint64{}, 0)
+		assert.ErrorContains(t, err, "greater than zero")
+
+		encoded, err := MarshalList([]uint64{1, 2, 3}, 4)
+		require.NoError(t, err)
+		var decoded []uint64
+		err = UnmarshalList(encoded, &decoded, 2)
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.ErrorContains(t, err, "exceeds limit")
+	})
+
+	t.Run("large limits", func(t *testing.T) {
+		// 2^62 uint64 values take 2^60 chunks: the first chunk is hashed with the zero root of
+		// each level up to depth 60, computed here without flexssz
+		var root, zero [32]byte
+		root[0] = 1
+		for range 60 {
+			root = sha256.Sum256(append(root[:], zero[:]...))
+			zero = sha256.Sum256(append(zero[:], zero[:]...))
+		}
+		var length [32]byte
+		length[0] = 1
+		want := sha256.Sum256(append(root[:], length[:]...))
+
+		got, err := HashTreeRootList([]uint64{1}, 1<<62)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		_, err = MarshalList([]uint64{1}, math.MaxUint64)
+		assert.ErrorContains(t, err, "larger than the largest int")
+		_, err = HashTreeRootVector([]uint64{1}, math.MaxUint64)
+		assert.ErrorContains(t, err, "larger than the largest int")
+	})
+
+	t.Run("not a slice", func(t *testing.T) {
+		_, err := MarshalList([2]uint64{}, 2)
+		assert.ErrorContains(t, err, "list must be a slice")
+		var out uint64
+		assert.ErrorContains(t, UnmarshalList(nil, &out, 2), "list must be a slice")
+		assert.ErrorContains(t, UnmarshalList(nil, []uint64{}, 2), "must be a pointer")
+	})
+}
+
+func TestMarshalVector(t *testing.T) {
+	t.Run("slice", func(t *testing.T) {
+		encoded, err := MarshalVector([]uint32{1, 2, 3}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}, encoded)
+
+		var decoded []uint32
+		require.NoError(t, UnmarshalVector(encoded, &decoded, 3))
+		assert.Equal(t, []uint32{1, 2, 3}, decoded)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		encoded, err := MarshalVector([4]uint16{1, 2, 3, 4}, 4)
+		require.NoError(t, err)
+		assert.Len(t, encoded, 8)
+
+		var decoded [4]uint16
+		require.NoError(t, UnmarshalVector(encoded, &decoded, 4))
+		assert.Equal(t, [4]uint16{1, 2, 3, 4}, decoded)
+
+		_, err = MarshalVector([4]uint16{}, 3)
+		assert.ErrorContains(t, err, "does not match size")
+	})
+
+	t.Run("root matches a wrapped vector", func(t *testing.T) {
+		type Wrapper struct {
+			Values []uint64 `ssz-size:"5"`
+		}
+		values := []uint64{1, 2, 3, 4, 5}
+
+		root, err := HashTreeRootVector(values, 5)
+		require.NoError(t, err)
+		wrappedRoot, err := HashTreeRoot(&Wrapper{Values: values})
+		require.NoError(t, err)
+		assert.Equal(t, wrappedRoot, root)
+	})
+
+	t.Run("size", func(t *testing.T) {
+		_, err := MarshalVector([]uint32{1, 2}, 3)
+		assert.ErrorContains(t, err, "does not match size")
+
+		var decoded []uint32
+		err = UnmarshalVector(make([]byte, 8), &decoded, 3)
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.ErrorContains(t, err, "takes 12 bytes, got 8")
+	})
+}
+
+type listTestCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type listTestPointers struct {
+	Items       []*listTestItem       `ssz-max:"4"`
+	Checkpoints []*listTestCheckpoint `ssz-max:"4"`
+	Pair        [2]*listTestCheckpoint
+}
+
+type listTestValues struct {
+	Items       []listTestItem       `ssz-max:"4"`
+	Checkpoints []listTestCheckpoint `ssz-max:"4"`
+	Pair        [2]listTestCheckpoint
+}
+
+func TestPointerElements(t *testing.T) {
+	v := &listTestPointers{
+		Items:       []*listTestItem{{A: 1, B: []byte{2}}, {A: 3}},
+		Checkpoints: []*listTestCheckpoint{{Epoch: 4, Root: [32]byte{5}}},
+		Pair:        [2]*listTestCheckpoint{{Epoch: 6}, {Epoch: 7}},
+	}
+	values := &listTestValues{
+		Items:       []listTestItem{{A: 1, B: []byte{2}}, {A: 3}},
+		Checkpoints: []listTestCheckpoint{{Epoch: 4, Root: [32]byte{5}}},
+		Pair:        [2]listTestCheckpoint{{Epoch: 6}, {Epoch: 7}},
+	}
+
+	// Pointer elements encode and hash like the values they point to
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	expected, err := Marshal(values)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	expectedRoot, err := HashTreeRoot(values)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	// Decoding allocates every element
+	var decoded listTestPointers
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, v.Checkpoints, decoded.Checkpoints)
+	assert.Equal(t, v.Pair, decoded.Pair)
+	require.Len(t, decoded.Items, 2)
+	assert.Equal(t, uint32(3), decoded.Items[1].A)
+
+	for name, nilElement := range map[string]*listTestPointers{
+		"variable list": {Items: []*listTestItem{{A: 1}, nil}},
+		"fixed list":    {Checkpoints: []*listTestCheckpoint{nil}},
+		"vector":        {Pair: [2]*listTestCheckpoint{{}, nil}},
+	} {
+		if name != "vector" {
+			nilElement.Pair = v.Pair
+		}
+		_, err := Marshal(nilElement)
+		assert.ErrorIs(t, err, ErrNilElement, name)
+		_, err = MarshalAppend(nil, nilElement)
+		assert.ErrorIs(t, err, ErrNilElement, name)
+		_, err = EncodedSize(nilElement)
+		if name == "variable list" {
+			assert.ErrorIs(t, err, ErrNilElement, name)
+		}
+		_, err = HashTreeRoot(nilElement)
+		assert.ErrorIs(t, err, ErrNilElement, name)
+	}
+}
+
+func TestHashTreeRootValue(t *testing.T) {
+	// A container with a single field has the root of that field
+	type Wrapper struct {
+		Balances []uint64 `ssz-max:"1099511627776"`
+		Roots    [][]byte `ssz-size:"4,32"`
+		Bits     []byte   `ssz:"bitlist" ssz-max:"2048"`
+		Root     [32]byte
+	}
+	value := Wrapper{
+		Balances: []uint64{32e9, 31e9, 1},
+		Roots:    [][]byte{make([]byte, 32), make([]byte, 32), make([]byte, 32), make([]byte, 32)},
+		Bits:     []byte{0x0d, 0x01},
+		Root:     [32]byte{1, 2, 3},
+	}
+	value.Roots[2][0] = 9
+	fields, err := FieldRoots(&value)
+	require.NoError(t, err)
+
+	root, err := HashTreeRootValue(value.Balances, WithLimit(1099511627776))
+	require.NoError(t, err)
+	assert.Equal(t, fields[0], root)
+
+	root, err = HashTreeRootValue(value.Roots, WithTags(`ssz-size:"4,32"`))
+	require.NoError(t, err)
+	assert.Equal(t, fields[1], root)
+
+	root, err = HashTreeRootValue(value.Bits, WithTags(`ssz:"bitlist" ssz-max:"2048"`))
+	require.NoError(t, err)
+	assert.Equal(t, fields[2], root)
+
+	root, err = HashTreeRootValue(&value.Root)
+	require.NoError(t, err)
+	assert.Equal(t, fields[3], root)
+
+	// The options give the same limits and sizes as the tags
+	_, err = HashTreeRootValue([]uint64{1, 2, 3}, WithLimit(2))
+	assert.ErrorContains(t, err, "exceeds limit")
+	_, err = HashTreeRootValue([]uint64{1, 2, 3}, WithSize(4))
+	assert.ErrorContains(t, err, "does not match size")
+	_, err = HashTreeRootValue([]uint64{1}, WithTags(`ssz-max:"x"`))
+	assert.Error(t, err)
+}
+
+type progressiveTestItem struct {
+	A uint64
+	B []byte `ssz-max:"8"`
+}
+
+type progressiveTestState struct {
+	Slot     uint64
+	Balances []uint64              `ssz:"progressive-list"`
+	Data     []byte                `ssz:"progressive-list"`
+	Items    []progressiveTestItem `ssz:"progressive-list"`
+}
+
+func TestProgressiveList(t *testing.T) {
+	state := progressiveTestState{
+		Slot:     3,
+		Balances: make([]uint64, 70),
+		Data:     []byte("progressive lists grow without a limit, one subtree at a time"),
+		Items:    []progressiveTestItem{{A: 1, B: []byte{1}}, {A: 2, B: []byte{}}},
+	}
+	for i := range state.Balances {
+		state.Balances[i] = uint64(i) * 1e9
+	}
+
+	// Progressive lists are encoded like lists
+	type listState struct {
+		Slot     uint64
+		Balances []uint64              `ssz-max:"1024"`
+		Data     []byte                `ssz-max:"1024"`
+		Items    []progressiveTestItem `ssz-max:"1024"`
+	}
+	enc, err := Marshal(&state)
+	require.NoError(t, err)
+	expected, err := Marshal(&listState{state.Slot, state.Balances, state.Data, state.Items})
+	require.NoError(t, err)
+	assert.Equal(t, expected, enc)
+	appended, err := MarshalAppend(nil, &state)
+	require.NoError(t, err)
+	assert.Equal(t, enc, appended)
+
+	var decoded progressiveTestState
+	require.NoError(t, UnmarshalStrict(enc, &decoded))
+	assert.Equal(t, state, decoded)
+
+	fields, err := FieldRoots(&state)
+	require.NoError(t, err)
+	balances := make([]byte, 0, len(state.Balances)*8)
+	for _, b := range state.Balances {
+		balances = binary.LittleEndian.AppendUint64(balances, b)
+	}
+	assert.Equal(t, MixInLength(naiveMerkleizeProgressive(PackBytes(balances), 1), 70), fields[1])
+	assert.Equal(t, MixInLength(naiveMerkleizeProgressive(PackBytes(state.Data), 1), uint64(len(state.Data))), fields[2])
+	itemRoots := make([][32]byte, len(state.Items))
+	for i := range state.Items {
+		itemRoots[i], err = HashTreeRoot(&state.Items[i])
+		require.NoError(t, err)
+	}
+	assert.Equal(t, MixInLength(naiveMerkleizeProgressive(itemRoots, 1), 2), fields[3])
+
+	// Empty progressive lists mix the length into a zero root
+	root, err := HashTreeRoot(&progressiveTestState{})
+	require.NoError(t, err)
+	empty := MixInLength([32]byte{}, 0)
+	zero := [32]byte{}
+	expectedRoot, err := Merkleize([][32]byte{zero, empty, empty, empty}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	type limited struct {
+		List []uint64 `ssz:"progressive-list" ssz-max:"4"`
+	}
+	_, err = Marshal(&limited{})
+	assert.ErrorContains(t, err, "no limit")
+}
+
+type byteListTestState struct {
+	Slot  uint64
+	Extra []byte `ssz:"bytelist" ssz-max:"100"`
+}
+
+func TestByteList(t *testing.T) {
+	state := byteListTestState{Slot: 3, Extra: []byte("byte lists are packed into chunks")}
+
+	info, err := GetTypeInfo(reflect.TypeOf(state), nil)
+	require.NoError(t, err)
+	assert.True(t, info.Fields[1].Type.ByteList)
+	assert.Equal(t, uint64(4), ChunkCount(info.Fields[1].Type))
+
+	// Byte lists are encoded and hashed like lists of uint8
+	type listState struct {
+		Slot  uint64
+		Extra []byte `ssz-max:"100"`
+	}
+	enc, err := Marshal(&state)
+	require.NoError(t, err)
+	expected, err := Marshal(&listState{state.Slot, state.Extra})
+	require.NoError(t, err)
+	assert.Equal(t, expected, enc)
+
+	expectedRoot, err := HashTreeRoot(&listState{state.Slot, state.Extra})
+	require.NoError(t, err)
+	root, err := HashTreeRoot(&state)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	// Values that are not addressable are packed too
+	root, err = HashTreeRoot(state)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	var decoded byteListTestState
+	require.NoError(t, Unmarshal(enc, &decoded))
+	assert.Equal(t, state, decoded)
+
+	type unlimited struct {
+		Extra []byte `ssz:"bytelist"`
+	}
+	_, err = Marshal(&unlimited{})
+	assert.ErrorContains(t, err, "ssz-max")
+
+	type notBytes struct {
+		Extra []uint16 `ssz:"bytelist" ssz-max:"4"`
+	}
+	_, err = Marshal(&notBytes{})
+	assert.ErrorContains(t, err, "requires []byte")
+}
@@ -0,0 +1,260 @@
+package flexssz
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// nativeLittleEndian reports whether integers are stored in their SSZ byte order
+var nativeLittleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// isByteArray reports whether t is an array of bytes or of byte arrays nested to any depth
+func isByteArray(t reflect.Type) bool {
+	if t.Kind() != reflect.Array {
+		return false
+	}
+	for t.Kind() == reflect.Array {
+		if t == uint256Type || isCustomType(t) {
+			return false
+		}
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Uint8 && !isCustomType(t)
+}
+
+// isByteVector reports whether info is a vector of bytes or of byte vectors nested to any depth,
+// whose Go arrays byteArrayBytes can read and write directly
+func isByteVector(info *TypeInfo) bool {
+	for ; info.Type == ssz.TypeVector && info.Wrapped == nil && !info.Custom; info = info.ElementType {
+		elem := info.ElementType
+		if elem.Type == ssz.TypeUint8 && elem.Wrapped == nil && !elem.Custom {
+			return true
+		}
+	}
+	return false
+}
+
+// boolBytes returns the memory of a slice or addressable array of bools as bytes. Go stores
+// true as 1 and false as 0, so this is also their SSZ encoding, and lists of bools such as
+// participation bits can be encoded, decoded and packed in bulk instead of one value at a time.
+// ok is false for other values.
+func boolBytes(v reflect.Value) ([]byte, bool) {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() != reflect.Bool {
+		return nil, false
+	}
+	return sliceBytes(v)
+}
+
+// basicBytes returns the SSZ encoding of a slice or addressable array of bools or integers,
+// such as a list of balances, without reading the values one by one. On little-endian
+// machines it is the memory of v itself, so it must not be modified. ok is false for other
+// values.
+func basicBytes(v reflect.Value) ([]byte, bool) {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	switch v.Type().Elem().Kind() {
+	case reflect.Bool, reflect.Uint8:
+		return sliceBytes(v)
+	case reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int32, reflect.Int64:
+	default:
+		return nil, false
+	}
+	mem, ok := sliceBytes(v)
+	if !ok || nativeLittleEndian {
+		return mem, ok
+	}
+
+	// Big-endian machines need every value swapped
+	out := make([]byte, len(mem))
+	switch size := int(v.Type().Elem().Size()); size {
+	case 2:
+		for i := 0; i < len(mem); i += size {
+			binary.LittleEndian.PutUint16(out[i:], binary.BigEndian.Uint16(mem[i:]))
+		}
+	case 4:
+		for i := 0; i < len(mem); i += size {
+			binary.LittleEndian.PutUint32(out[i:], binary.BigEndian.Uint32(mem[i:]))
+		}
+	case 8:
+		for i := 0; i < len(mem); i += size {
+			binary.LittleEndian.PutUint64(out[i:], binary.BigEndian.Uint64(mem[i:]))
+		}
+	}
+	return out, true
+}
+
+// encodeBools writes a list or vector of bools in one write, reporting false if v is not one
+func encodeBools(b *Builder, v reflect.Value) bool {
+	bs, ok := boolBytes(v)
+	if !ok {
+		return false
+	}
+	// The builder keeps the bytes until Finish, so they must not alias v
+	b.EncodeFixed(bytes.Clone(bs))
+	return true
+}
+
+// decodeBools reads a list or vector of bools in one read, reporting false if v is not one.
+// v must already have its length.
+func decodeBools(d *Decoder, v reflect.Value) (bool, error) {
+	dst, ok := boolBytes(v)
+	if !ok {
+		return false, nil
+	}
+	if len(dst) == 0 {
+		return true, nil
+	}
+	offset := d.Offset()
+	if _, err := d.Read(dst); err != nil {
+		return true, err
+	}
+	if i, err := checkBools(dst, d.opts.Strict); err != nil {
+		return true, wrapDecodeError(err, indexSegment(i), offset+i)
+	}
+	return true, nil
+}
+
+// boolWordMask has the bits that may be set in 8 packed bools
+const boolWordMask = 0x0101010101010101
+
+// checkBools checks decoded bools 8 at a time. Like decodeBoolean, bytes other than 0 and 1 are
+// false, or an error in strict mode, and are rewritten to 0 so the memory only holds valid bools.
+// The index of an invalid byte is returned with its error.
+func checkBools(bs []byte, strict bool) (int, error) {
+	i := 0
+	for ; i+8 <= len(bs); i += 8 {
+		if binary.LittleEndian.Uint64(bs[i:])&^boolWordMask != 0 {
+			break
+		}
+	}
+	for ; i < len(bs); i++ {
+		if bs[i] <= 1 {
+			continue
+		}
+		if strict {
+			return i, fmt.Errorf("%w: boolean byte 0x%02x", ErrNonCanonical, bs[i])
+		}
+		bs[i] = 0
+	}
+	return 0, nil
+}
+
+// byteVectorSize returns the size of the elements of t, a slice or array of byte vectors such as
+// [][]byte tagged ssz-size:"512,48" or [][96]byte, from their type information. Their encoding is
+// their bytes one after the other, so lists and vectors of pubkeys and signatures are encoded,
+// decoded and hashed as one region instead of element by element. ok is false for other types,
+// including elements that encode themselves.
+func byteVectorSize(t reflect.Type, elemType *TypeInfo) (int, bool) {
+	if elemType == nil || elemType.FixedSize <= 0 || !isByteVector(elemType) {
+		return 0, false
+	}
+	et := t.Elem()
+	if isFlatByteArray(et) {
+		return elemType.FixedSize, et.Len() == elemType.FixedSize
+	}
+	ok := isByteSlice(et) && elemType.ElementType.Type == ssz.TypeUint8
+	return elemType.FixedSize, ok
+}
+
+// byteVectorTagSize is byteVectorSize from the tag of the elements, for the encoders, which have
+// no type information for them
+func byteVectorTagSize(t reflect.Type, elemTag *sszTag) (int, bool) {
+	et := t.Elem()
+	if isFlatByteArray(et) {
+		return et.Len(), true
+	}
+	if !isByteSlice(et) {
+		return 0, false
+	}
+	elemTag = withTypeDimensions(et, elemTag)
+	if elemTag == nil || len(elemTag.Size) != 1 || elemTag.Size[0] <= 0 || elemTag.FieldType == "bitvector" {
+		return 0, false
+	}
+	return elemTag.Size[0], true
+}
+
+// isFlatByteArray reports whether t is an array of bytes that does not encode itself, which unlike
+// isByteArray excludes arrays of arrays
+func isFlatByteArray(t reflect.Type) bool {
+	return t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8 && isByteArray(t)
+}
+
+// isByteSlice reports whether t is a slice of bytes that does not encode itself
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !isCustomType(t) && !isCustomType(t.Elem())
+}
+
+// appendByteVectors appends the elements of v, a slice or array of byte vectors of size bytes,
+// see byteVectorSize. []byte elements must have the size.
+func appendByteVectors(dst []byte, v reflect.Value, size int) ([]byte, error) {
+	if v.Type().Elem().Kind() == reflect.Array {
+		if bs, ok := sliceBytes(v); ok {
+			return append(dst, bs...), nil
+		}
+	}
+	dst = slices.Grow(dst, v.Len()*size)
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Array {
+			dst = appendByteArray(dst, elem)
+			continue
+		}
+		if elem.Len() != size {
+			return dst, fmt.Errorf("error encoding element %d: slice length %d does not match ssz-size %d", i, elem.Len(), size)
+		}
+		dst = append(dst, elem.Bytes()...)
+	}
+	return dst, nil
+}
+
+// encodeByteVectors writes a list or vector of byte vectors of size bytes in one write, see
+// appendByteVectors
+func encodeByteVectors(b *Builder, v reflect.Value, size int) error {
+	// A new buffer, since the builder keeps the bytes until Finish
+	bs, err := appendByteVectors(nil, v, size)
+	if err != nil {
+		return err
+	}
+	b.EncodeFixed(bs)
+	return nil
+}
+
+// decodeByteVectors reads a list or vector of byte vectors in one read, reporting false if v is
+// not one, see byteVectorSize. v must already have its length. []byte elements share one
+// allocation, or the input with ZeroCopy, each capped to its size so appends do not write over the
+// next.
+func decodeByteVectors(d *Decoder, v reflect.Value, elemType *TypeInfo) (bool, error) {
+	size, ok := byteVectorSize(v.Type(), elemType)
+	if !ok {
+		return false, nil
+	}
+	n := v.Len() * size
+	if n == 0 {
+		return true, nil
+	}
+	if v.Type().Elem().Kind() == reflect.Array {
+		dst, ok := sliceBytes(v)
+		if !ok {
+			return false, nil
+		}
+		_, err := d.Read(dst)
+		return true, err
+	}
+	if err := d.allocateBytes(n); err != nil {
+		return true, err
+	}
+	bs, err := d.readBytes(n)
+	if err != nil {
+		return true, err
+	}
+	for i := 0; i < v.Len(); i++ {
+		v.Index(i).SetBytes(bs[i*size : (i+1)*size : (i+1)*size])
+	}
+	return true, nil
+}
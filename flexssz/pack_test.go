@@ -0,0 +1,371 @@
+package flexssz
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type packTestBools struct {
+	List   []bool `ssz-max:"2048"`
+	Vector []bool `ssz-size:"13"`
+	Array  [5]bool
+	Flags  []uint8 `ssz-max:"2048"`
+}
+
+// packTestBoolsRaw has the same encoding as packTestBools, with bools as bytes
+type packTestBoolsRaw struct {
+	List   []uint8 `ssz-max:"2048"`
+	Vector []uint8 `ssz-size:"13"`
+	Array  [5]uint8
+	Flags  []uint8 `ssz-max:"2048"`
+}
+
+func TestPackBools(t *testing.T) {
+	v := &packTestBools{
+		List:   make([]bool, 1000),
+		Vector: make([]bool, 13),
+		Flags:  make([]uint8, 1000),
+	}
+	raw := &packTestBoolsRaw{
+		List:   make([]uint8, 1000),
+		Vector: make([]uint8, 13),
+		Flags:  make([]uint8, 1000),
+	}
+	for i := range v.List {
+		if i%3 == 0 {
+			v.List[i], raw.List[i] = true, 1
+		}
+		v.Flags[i], raw.Flags[i] = uint8(i%8), uint8(i%8)
+	}
+	v.Vector[12], raw.Vector[12] = true, 1
+	v.Array[1], raw.Array[1] = true, 1
+
+	encoded, err := Marshal(v)
+	require.NoError(t, err)
+	rawEncoded, err := Marshal(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawEncoded, encoded)
+
+	var decoded packTestBools
+	require.NoError(t, UnmarshalStrict(encoded, &decoded))
+	assert.Equal(t, v, &decoded)
+
+	// Bools pack like the uint8 values 0 and 1
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	rawRoot, err := HashTreeRoot(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawRoot, root)
+
+	list, err := MarshalList(v.List, 2048)
+	require.NoError(t, err)
+	assert.Equal(t, rawEncoded[len(rawEncoded)-2000:len(rawEncoded)-1000], list)
+}
+
+func TestPackBools_Invalid(t *testing.T) {
+	raw := &packTestBoolsRaw{
+		List:   []uint8{1, 0, 1, 1, 0, 0, 0, 1, 1, 2, 1},
+		Vector: make([]uint8, 13),
+	}
+	encoded, err := Marshal(raw)
+	require.NoError(t, err)
+
+	// Like single bools, only 1 is true unless decoding is strict
+	var decoded packTestBools
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	assert.Equal(t, []bool{true, false, true, true, false, false, false, true, true, false, true}, decoded.List)
+
+	err = UnmarshalStrict(encoded, &decoded)
+	assert.True(t, errors.Is(err, ErrNonCanonical), err)
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "List[9]", decodeErr.Path)
+}
+
+func TestCheckBools(t *testing.T) {
+	bs := []byte{0, 1, 1, 0, 1, 0, 0, 1, 1, 1, 0}
+	i, err := checkBools(bs, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, i)
+
+	bs = []byte{0, 1, 1, 0, 1, 0, 0, 1, 1, 1, 0xff, 1, 0, 1, 1, 0, 7}
+	_, err = checkBools(append([]byte(nil), bs...), true)
+	assert.ErrorContains(t, err, "boolean byte 0xff")
+	i, _ = checkBools(append([]byte(nil), bs...), true)
+	assert.Equal(t, 10, i)
+
+	_, err = checkBools(bs, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 1, 0, 1, 0, 0, 1, 1, 1, 0, 1, 0, 1, 1, 0, 0}, bs)
+}
+
+type packTestGwei uint64
+
+type packTestBasic struct {
+	Balances []packTestGwei `ssz-max:"1099511627776"`
+	Scores   []uint32       `ssz-max:"64"`
+	Small    []uint16       `ssz-max:"64"`
+	Epochs   []uint64       `ssz-size:"5"`
+	Array    [7]uint32
+}
+
+func TestPackBasicVector(t *testing.T) {
+	v := &packTestBasic{
+		Balances: []packTestGwei{32e9, 31e9, 1},
+		Scores:   []uint32{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		Small:    []uint16{0xffff, 1},
+		Epochs:   []uint64{1, 2, 3, 4, 5},
+		Array:    [7]uint32{7, 6, 5, 4, 3, 2, 1},
+	}
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+
+	// Arrays that are not addressable take the per element path
+	u32 := &TypeInfo{Type: ssz.TypeUint32, FixedSize: 4}
+	assert.Equal(t, packBasicVector(reflect.ValueOf(&v.Array).Elem(), 7, u32), packBasicVector(reflect.ValueOf(v.Array), 7, u32))
+
+	// The element loop matches the packed memory
+	loop := func(xs []uint64) [][32]byte {
+		data := make([]byte, 8*len(xs))
+		for i, x := range xs {
+			binary.LittleEndian.PutUint64(data[i*8:], x)
+		}
+		return PackBytes(data)
+	}
+	u64 := &TypeInfo{Type: ssz.TypeUint64, FixedSize: 8}
+	assert.Equal(t, loop(v.Epochs), packBasicVector(reflect.ValueOf(v.Epochs), 5, u64))
+	// Short vectors are padded with zeros
+	assert.Equal(t, loop([]uint64{1, 2, 0, 0, 0, 0}), packBasicVector(reflect.ValueOf([]uint64{1, 2}), 6, u64))
+
+	again, err := HashTreeRoot(*v)
+	require.NoError(t, err)
+	assert.Equal(t, root, again)
+}
+
+func BenchmarkPackBasicVector_Balances(b *testing.B) {
+	v := &packTestBasic{Balances: make([]packTestGwei, 1<<20), Epochs: make([]uint64, 5)}
+	for i := range v.Balances {
+		v.Balances[i] = packTestGwei(32e9 + i)
+	}
+	b.SetBytes(int64(len(v.Balances) * 8))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := HashTreeRoot(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkBoolList(b *testing.B, run func(v *packTestBoolList, encoded []byte) error) {
+	v := &packTestBoolList{Bits: make([]bool, 1<<20), Flags: make([]uint8, 1<<20)}
+	for i := range v.Bits {
+		v.Bits[i] = i%3 == 0
+		v.Flags[i] = uint8(i % 8)
+	}
+	encoded, err := Marshal(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(encoded)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := run(v, encoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type packTestBoolList struct {
+	Bits  []bool  `ssz-max:"1099511627776"`
+	Flags []uint8 `ssz-max:"1099511627776"`
+}
+
+func BenchmarkBoolList_Marshal(b *testing.B) {
+	benchmarkBoolList(b, func(v *packTestBoolList, _ []byte) error {
+		_, err := Marshal(v)
+		return err
+	})
+}
+
+func BenchmarkBoolList_Unmarshal(b *testing.B) {
+	benchmarkBoolList(b, func(_ *packTestBoolList, encoded []byte) error {
+		var v packTestBoolList
+		return Unmarshal(encoded, &v)
+	})
+}
+
+func BenchmarkBoolList_HashTreeRoot(b *testing.B) {
+	benchmarkBoolList(b, func(v *packTestBoolList, _ []byte) error {
+		_, err := HashTreeRoot(v)
+		return err
+	})
+}
+
+type packTestRoot [32]byte
+
+type packTestNestedArrays struct {
+	Roots     [4][32]byte
+	Deep      [2][3][4]byte
+	Named     [2]packTestRoot
+	Bitfields [2][1]byte `ssz-size:"2,1"`
+}
+
+type packTestNestedSlices struct {
+	Roots     [][]byte   `ssz-size:"4,32"`
+	Deep      [][][]byte `ssz-size:"2,3,4"`
+	Named     [][]byte   `ssz-size:"2,32"`
+	Bitfields [][]byte   `ssz-size:"2,1"`
+}
+
+func TestNestedByteArrays(t *testing.T) {
+	info, err := GetTypeInfo(reflect.TypeFor[packTestNestedArrays](), nil)
+	require.NoError(t, err)
+	for _, f := range info.Fields {
+		assert.True(t, isByteVector(f.Type), f.Name)
+	}
+	assert.Equal(t, 4*32+2*3*4+2*32+2, info.FixedSize)
+
+	v := &packTestNestedArrays{}
+	s := &packTestNestedSlices{
+		Roots:     make([][]byte, 4),
+		Deep:      make([][][]byte, 2),
+		Named:     make([][]byte, 2),
+		Bitfields: [][]byte{{5}, {6}},
+	}
+	for i := range v.Roots {
+		v.Roots[i][i] = byte(i + 1)
+		s.Roots[i] = v.Roots[i][:]
+	}
+	for i := range v.Deep {
+		s.Deep[i] = make([][]byte, 3)
+		for j := range v.Deep[i] {
+			v.Deep[i][j] = [4]byte{byte(i), byte(j), 1, 2}
+			s.Deep[i][j] = v.Deep[i][j][:]
+		}
+	}
+	for i := range v.Named {
+		v.Named[i][31] = byte(i + 1)
+		s.Named[i] = v.Named[i][:]
+	}
+	v.Bitfields = [2][1]byte{{5}, {6}}
+
+	// Nested arrays encode, decode and hash like the equivalent sized slices
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	expected, err := Marshal(s)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+	appended, err := MarshalAppend(nil, v)
+	require.NoError(t, err)
+	assert.Equal(t, expected, appended)
+
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	expectedRoot, err := HashTreeRoot(s)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	var decoded packTestNestedArrays
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, *v, decoded)
+
+	// Unaddressable arrays take the element by element path
+	data, err = Marshal(*v)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+}
+
+type packTestKeys struct {
+	Keys       [][]byte   `ssz-size:"?,48" ssz-max:"64"`
+	Signatures [][96]byte `ssz-max:"64"`
+	Committee  [][]byte   `ssz-size:"5,48"`
+	Addresses  [][20]byte `ssz-size:"3"`
+}
+
+// packTestKeysGeneric has the same encoding and root as packTestKeys, with each byte vector in a
+// container of one field, which takes the element by element path
+type packTestKeysGeneric struct {
+	Keys       []packTestKey48 `ssz-max:"64"`
+	Signatures []packTestKey96 `ssz-max:"64"`
+	Committee  [5]packTestKey48
+	Addresses  [3]packTestKey20
+}
+
+type packTestKey48 struct{ K [48]byte }
+type packTestKey96 struct{ K [96]byte }
+type packTestKey20 struct{ K [20]byte }
+
+func TestByteVectors(t *testing.T) {
+	v := &packTestKeys{
+		Keys:       make([][]byte, 7),
+		Signatures: make([][96]byte, 3),
+		Committee:  make([][]byte, 5),
+		Addresses:  make([][20]byte, 3),
+	}
+	g := &packTestKeysGeneric{
+		Keys:       make([]packTestKey48, 7),
+		Signatures: make([]packTestKey96, 3),
+	}
+	for i := range v.Keys {
+		g.Keys[i].K[0], g.Keys[i].K[47] = byte(i), byte(i+1)
+		v.Keys[i] = bytes.Clone(g.Keys[i].K[:])
+	}
+	for i := range v.Signatures {
+		g.Signatures[i].K[95] = byte(i + 1)
+		v.Signatures[i] = g.Signatures[i].K
+	}
+	for i := range v.Committee {
+		g.Committee[i].K[33] = byte(i + 1)
+		v.Committee[i] = bytes.Clone(g.Committee[i].K[:])
+	}
+	for i := range v.Addresses {
+		g.Addresses[i].K[19] = byte(i + 1)
+		v.Addresses[i] = g.Addresses[i].K
+	}
+
+	data, err := Marshal(v)
+	require.NoError(t, err)
+	expected, err := Marshal(g)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+	appended, err := MarshalAppend(nil, v)
+	require.NoError(t, err)
+	assert.Equal(t, expected, appended)
+
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	expectedRoot, err := HashTreeRoot(g)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+	root, err = HashTreeRootValue(v.Keys, WithTags(`ssz-size:"?,48" ssz-max:"64"`))
+	require.NoError(t, err)
+	expectedRoot, err = HashTreeRootValue(g.Keys, WithLimit(64))
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	var decoded packTestKeys
+	require.NoError(t, UnmarshalStrict(data, &decoded))
+	assert.Equal(t, v, &decoded)
+	// The keys share one allocation, capped so appending to one does not write over the next
+	decoded.Keys[0] = append(decoded.Keys[0], 0xff)
+	assert.Equal(t, v.Keys[1], decoded.Keys[1])
+
+	require.NoError(t, UnmarshalWithOptions(data, &decoded, DecodeOptions{ZeroCopy: true}))
+	assert.Equal(t, v, &decoded)
+	assert.Equal(t, 48, cap(decoded.Keys[0]))
+
+	// Keys of the wrong size are rejected
+	v.Keys[2] = v.Keys[2][:47]
+	_, err = Marshal(v)
+	assert.ErrorContains(t, err, "element 2: slice length 47 does not match ssz-size 48")
+	_, err = MarshalAppend(nil, v)
+	assert.ErrorContains(t, err, "element 2: slice length 47 does not match ssz-size 48")
+	assert.Error(t, UnmarshalStrict(data[:len(data)-1], &decoded))
+}
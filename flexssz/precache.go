@@ -0,0 +1,133 @@
+package flexssz
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// precacheMutex serializes PrecacheAll, so the structs shared by the types of concurrent calls
+// are parsed once
+var precacheMutex sync.Mutex
+
+// PrecacheAll precaches the type information of the struct types of values, like a call to
+// PrecacheStructSSZInfo for each of them. The structs they contain are parsed first, once each,
+// and reused by every container holding them, so types sharing nested containers do not parse
+// them again. Instead of stopping at the first invalid type, it returns an error joining the
+// error of every invalid struct. Structs containing an invalid struct are not reported again.
+func PrecacheAll(values ...any) error {
+	precacheMutex.Lock()
+	defer precacheMutex.Unlock()
+
+	var errs []error
+	order := precacheOrder{
+		deps:    make(map[reflect.Type][]reflect.Type),
+		visited: make(map[reflect.Type]bool),
+	}
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			errs = append(errs, fmt.Errorf("PrecacheAll can only be used with struct types, got %v", t))
+			continue
+		}
+		order.visit(t)
+	}
+
+	// Structs come after the structs they contain, so their fields hit the cache
+	failed := make(map[reflect.Type]bool)
+	for _, t := range order.types {
+		if dependsOn(order.deps[t], failed) {
+			failed[t] = true
+			continue
+		}
+		if _, ok := cachedTypeInfo(t); ok {
+			continue
+		}
+		if _, err := GetTypeInfo(t, nil); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", t, err))
+			failed[t] = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// precacheOrder collects struct types after the struct types their fields hold
+type precacheOrder struct {
+	types   []reflect.Type
+	deps    map[reflect.Type][]reflect.Type
+	visited map[reflect.Type]bool
+}
+
+// visit adds struct t after the structs it contains. Recursive types are added once, after the
+// structs reached before the cycle.
+func (o *precacheOrder) visit(t reflect.Type) {
+	if o.visited[t] {
+		return
+	}
+	o.visited[t] = true
+	var deps []reflect.Type
+	o.fieldStructs(t, &deps)
+	for _, dep := range deps {
+		o.visit(dep)
+	}
+	o.deps[t] = deps
+	o.types = append(o.types, t)
+}
+
+// fieldStructs appends the containers held by the fields of struct t to deps, with the fields of
+// inlined structs in place of the field holding them. Fields with invalid tags are skipped, their
+// error is returned when t is parsed.
+func (o *precacheOrder) fieldStructs(t reflect.Type, deps *[]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, err := parseSSZTags(field)
+		if err != nil || tag.Skip {
+			continue
+		}
+		if tag.Inline {
+			o.fieldStructs(field.Type, deps)
+			continue
+		}
+		if field.IsExported() {
+			*deps = appendContainers(*deps, field.Type)
+		}
+	}
+}
+
+// appendContainers appends the struct types encoded as containers that t is or holds, through
+// pointers, slices, arrays and maps
+func appendContainers(deps []reflect.Type, t reflect.Type) []reflect.Type {
+	for {
+		if isCustomType(t) {
+			return deps
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		case reflect.Map:
+			deps = appendContainers(deps, t.Key())
+			t = t.Elem()
+		case reflect.Struct:
+			if t == timeType || t == unionType {
+				return deps
+			}
+			return append(deps, t)
+		default:
+			return deps
+		}
+	}
+}
+
+// dependsOn reports whether any of deps is in failed
+func dependsOn(deps []reflect.Type, failed map[reflect.Type]bool) bool {
+	for _, dep := range deps {
+		if failed[dep] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package flexssz
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type precacheCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type precacheVote struct {
+	Source precacheCheckpoint
+	Target *precacheCheckpoint
+}
+
+type precacheState struct {
+	Finalized precacheCheckpoint
+	Votes     []precacheVote          `ssz-max:"16"`
+	Pending   map[uint64]precacheVote `ssz:"kvlist" ssz-max:"4"`
+	History   [2]*precacheCheckpoint
+}
+
+func TestPrecacheAll(t *testing.T) {
+	require.NoError(t, PrecacheAll(&precacheState{}, precacheVote{}))
+
+	checkpoint, ok := cachedTypeInfo(reflect.TypeOf(precacheCheckpoint{}))
+	require.True(t, ok)
+	vote, ok := cachedTypeInfo(reflect.TypeOf(precacheVote{}))
+	require.True(t, ok)
+	state, ok := cachedTypeInfo(reflect.TypeOf(precacheState{}))
+	require.True(t, ok)
+
+	// Every container holding a checkpoint shares the fields parsed for it
+	assert.Same(t, &checkpoint.Fields[0], &vote.Fields[0].Type.Fields[0])
+	assert.Same(t, &checkpoint.Fields[0], &vote.Fields[1].Type.Fields[0])
+	assert.Same(t, &checkpoint.Fields[0], &state.Fields[0].Type.Fields[0])
+	assert.Same(t, &vote.Fields[0], &state.Fields[1].Type.ElementType.Fields[0])
+
+	value := &precacheState{
+		Finalized: precacheCheckpoint{Epoch: 3},
+		Votes:     []precacheVote{{Target: &precacheCheckpoint{Epoch: 2}}},
+		Pending:   map[uint64]precacheVote{},
+		History:   [2]*precacheCheckpoint{{Epoch: 1}, {Epoch: 2}},
+	}
+	encoded, err := Marshal(value)
+	require.NoError(t, err)
+	var decoded precacheState
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	assert.Equal(t, value, &decoded)
+}
+
+func TestPrecacheAll_Errors(t *testing.T) {
+	type BadTag struct {
+		A uint32 `ssz:"uint64"`
+	}
+	type BadList struct {
+		A []uint64
+	}
+	type HoldsBad struct {
+		Inner BadTag
+	}
+	type Valid struct {
+		A uint64
+	}
+
+	err := PrecacheAll(HoldsBad{}, &BadList{}, Valid{}, 5)
+	require.Error(t, err)
+	msg := err.Error()
+	// One line per invalid type, HoldsBad only fails through BadTag
+	assert.Len(t, strings.Split(msg, "\n"), 3)
+	assert.Contains(t, msg, "BadTag: ")
+	assert.Contains(t, msg, "ssz tag 'uint64' requires Go type uint64")
+	assert.Contains(t, msg, "BadList: ")
+	assert.Contains(t, msg, "slice types must have either ssz-size or ssz-max tag")
+	assert.Contains(t, msg, "can only be used with struct types, got int")
+	assert.NotContains(t, msg, "HoldsBad")
+
+	_, ok := cachedTypeInfo(reflect.TypeOf(Valid{}))
+	assert.True(t, ok)
+	_, ok = cachedTypeInfo(reflect.TypeOf(HoldsBad{}))
+	assert.False(t, ok)
+}
+
+func TestPrecacheAll_Concurrent(t *testing.T) {
+	type Leaf struct {
+		A uint64
+		B []byte `ssz-max:"8"`
+	}
+	type Left struct {
+		Leaves []Leaf `ssz-max:"4"`
+	}
+	type Right struct {
+		Leaf  Leaf
+		Other *Leaf
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, PrecacheAll(Left{}, Right{}))
+		}()
+	}
+	wg.Wait()
+
+	leaf, ok := cachedTypeInfo(reflect.TypeOf(Leaf{}))
+	require.True(t, ok)
+	right, ok := cachedTypeInfo(reflect.TypeOf(Right{}))
+	require.True(t, ok)
+	assert.Same(t, &leaf.Fields[0], &right.Fields[1].Type.Fields[0])
+}
@@ -0,0 +1,59 @@
+package flexssz
+
+import (
+	"context"
+	"reflect"
+	"runtime/pprof"
+	"sync/atomic"
+)
+
+// Codec phases reported in the ssz_phase profile label
+const (
+	PhaseMarshal      = "marshal"
+	PhaseUnmarshal    = "unmarshal"
+	PhaseHashTreeRoot = "hash_tree_root"
+)
+
+var profileLabels atomic.Bool
+
+// SetProfileLabels enables or disables runtime/pprof labels on Marshal, Unmarshal and HashTreeRoot.
+// When enabled, CPU samples taken inside these calls carry an ssz_type label with the Go type
+// being processed and an ssz_phase label with the codec phase, so production profiles can be
+// filtered with e.g. `go tool pprof -tagfocus=ssz_type=BeaconState`.
+// Labels are off by default since setting them costs a few allocations per call.
+//
+// The labels of a goroutine cannot be read back, so calls without a context replace them for the
+// duration of the call and leave the goroutine without labels. Callers that label their goroutines
+// use MarshalContext, UnmarshalContext and HashTreeRootContext, which add to the labels of ctx and
+// restore them on return.
+func SetProfileLabels(enabled bool) {
+	profileLabels.Store(enabled)
+}
+
+// ProfileLabelsEnabled reports whether profile labels are enabled
+func ProfileLabelsEnabled() bool {
+	return profileLabels.Load()
+}
+
+// withProfileLabels runs fn with the pprof labels of ctx and labels for t and phase when labels
+// are enabled. The goroutine is left with the labels of ctx.
+func withProfileLabels(ctx context.Context, t reflect.Type, phase string, fn func() error) (err error) {
+	if !profileLabels.Load() {
+		return fn()
+	}
+	pprof.Do(ctx, pprof.Labels("ssz_type", typeLabel(t), "ssz_phase", phase), func(context.Context) {
+		err = fn()
+	})
+	return err
+}
+
+// typeLabel names t in profile labels and stats. Pointers are named as the type they point to.
+func typeLabel(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
@@ -0,0 +1,105 @@
+package flexssz
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileLabels(t *testing.T) {
+	type Labeled struct {
+		A uint64
+		B []byte `ssz-max:"32"`
+	}
+	v := &Labeled{A: 1, B: []byte{1, 2, 3}}
+
+	assert.False(t, ProfileLabelsEnabled())
+
+	plainEncoded, err := Marshal(v)
+	require.NoError(t, err)
+	plainRoot, err := HashTreeRoot(v)
+	require.NoError(t, err)
+
+	SetProfileLabels(true)
+	defer SetProfileLabels(false)
+	assert.True(t, ProfileLabelsEnabled())
+
+	// Labels must not change the output of any phase
+	encoded, err := Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, plainEncoded, encoded)
+
+	var decoded Labeled
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	assert.Equal(t, *v, decoded)
+
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	assert.Equal(t, plainRoot, root)
+
+	// Errors are passed through
+	_, err = Marshal(&Labeled{B: make([]byte, 33)})
+	assert.Error(t, err)
+}
+
+// profileTestLabels records the pprof labels of the goroutines in hashedLabels when it is hashed
+type profileTestLabels uint64
+
+var hashedLabels []string
+
+func (profileTestLabels) HashTreeRoot() ([32]byte, error) {
+	hashedLabels = goroutineLabels()
+	return [32]byte{}, nil
+}
+
+// goroutineLabels returns the label sets of the goroutines that have labels
+func goroutineLabels() []string {
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	var labels []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if set, ok := strings.CutPrefix(line, "# labels: "); ok {
+			labels = append(labels, set)
+		}
+	}
+	return labels
+}
+
+func TestProfileLabels_Context(t *testing.T) {
+	type Labeled struct {
+		A profileTestLabels
+	}
+	v := &Labeled{}
+
+	SetProfileLabels(true)
+	defer SetProfileLabels(false)
+
+	// The labels of the caller are kept during the call and restored after it
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "profile-test"))
+	pprof.SetGoroutineLabels(ctx)
+	defer pprof.SetGoroutineLabels(context.Background())
+	_, err := HashTreeRootContext(ctx, v)
+	require.NoError(t, err)
+	require.Len(t, hashedLabels, 1)
+	assert.Contains(t, hashedLabels[0], `"request":"profile-test"`)
+	assert.Contains(t, hashedLabels[0], `"ssz_phase":"hash_tree_root"`)
+	assert.Equal(t, []string{`{"request":"profile-test"}`}, goroutineLabels())
+
+	for name, call := range map[string]func() error{
+		"MarshalContext": func() error {
+			_, err := MarshalContext(ctx, &inlineTestSig{A: 1})
+			return err
+		},
+		"UnmarshalContext": func() error {
+			return UnmarshalContext(ctx, make([]byte, 6), &inlineTestSig{})
+		},
+	} {
+		require.NoError(t, call(), name)
+		assert.Equal(t, []string{`{"request":"profile-test"}`}, goroutineLabels(), name)
+	}
+}
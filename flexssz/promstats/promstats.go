@@ -0,0 +1,83 @@
+// Package promstats exports the flexssz usage counters to Prometheus.
+//
+//	flexssz.SetStats(true)
+//	prometheus.MustRegister(promstats.NewCollector("beacon"))
+//
+// Counters are read from flexssz.Stats on every scrape, so registering the collector costs
+// nothing between scrapes.
+package promstats
+
+import (
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector for flexssz.Stats
+type Collector struct {
+	operations  *prometheus.Desc
+	errors      *prometheus.Desc
+	bytes       *prometheus.Desc
+	cacheLookup *prometheus.Desc
+}
+
+// NewCollector creates a collector with metric names under namespace, which may be empty
+func NewCollector(namespace string) *Collector {
+	labels := []string{"type", "op"}
+	return &Collector{
+		operations: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ssz", "operations_total"),
+			"SSZ codec calls by Go type and operation (marshal, unmarshal or hash_tree_root).",
+			labels, nil,
+		),
+		errors: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ssz", "errors_total"),
+			"SSZ codec calls that returned an error, by Go type and operation.",
+			labels, nil,
+		),
+		bytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ssz", "bytes_total"),
+			"Bytes written by marshal and read by unmarshal, by Go type.",
+			labels, nil,
+		),
+		cacheLookup: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ssz", "type_cache_lookups_total"),
+			"Type info cache lookups by result (hit or miss).",
+			[]string{"result"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.operations
+	ch <- c.errors
+	ch <- c.bytes
+	ch <- c.cacheLookup
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := flexssz.Stats()
+	for name, t := range stats.Types {
+		c.collectOp(ch, name, flexssz.PhaseMarshal, t.Encodes, t.EncodeErrors)
+		c.collectOp(ch, name, flexssz.PhaseUnmarshal, t.Decodes, t.DecodeErrors)
+		c.collectOp(ch, name, flexssz.PhaseHashTreeRoot, t.Hashes, t.HashErrors)
+		if t.Encodes > 0 {
+			ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(t.EncodedBytes), name, flexssz.PhaseMarshal)
+		}
+		if t.Decodes > 0 {
+			ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(t.DecodedBytes), name, flexssz.PhaseUnmarshal)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.cacheLookup, prometheus.CounterValue, float64(stats.TypeCacheHits), "hit")
+	ch <- prometheus.MustNewConstMetric(c.cacheLookup, prometheus.CounterValue, float64(stats.TypeCacheMisses), "miss")
+}
+
+// collectOp reports the calls and errors of one operation, skipping operations never used
+func (c *Collector) collectOp(ch chan<- prometheus.Metric, name, op string, calls, errors uint64) {
+	if calls == 0 {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.operations, prometheus.CounterValue, float64(calls), name, op)
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(errors), name, op)
+}
@@ -0,0 +1,47 @@
+package promstats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type message struct {
+	A uint64
+	B []byte `ssz-max:"32"`
+}
+
+func TestCollector(t *testing.T) {
+	flexssz.SetStats(true)
+	defer flexssz.SetStats(false)
+	flexssz.ResetStats()
+	defer flexssz.ResetStats()
+
+	encoded, err := flexssz.Marshal(&message{A: 1, B: []byte{1, 2}})
+	require.NoError(t, err)
+	require.Len(t, encoded, 14)
+	var decoded message
+	require.NoError(t, flexssz.Unmarshal(encoded, &decoded))
+	require.Error(t, flexssz.Unmarshal(encoded[:2], &decoded))
+
+	expected := `
+# HELP test_ssz_bytes_total Bytes written by marshal and read by unmarshal, by Go type.
+# TYPE test_ssz_bytes_total counter
+test_ssz_bytes_total{op="marshal",type="promstats.message"} 14
+test_ssz_bytes_total{op="unmarshal",type="promstats.message"} 16
+# HELP test_ssz_errors_total SSZ codec calls that returned an error, by Go type and operation.
+# TYPE test_ssz_errors_total counter
+test_ssz_errors_total{op="marshal",type="promstats.message"} 0
+test_ssz_errors_total{op="unmarshal",type="promstats.message"} 1
+# HELP test_ssz_operations_total SSZ codec calls by Go type and operation (marshal, unmarshal or hash_tree_root).
+# TYPE test_ssz_operations_total counter
+test_ssz_operations_total{op="marshal",type="promstats.message"} 1
+test_ssz_operations_total{op="unmarshal",type="promstats.message"} 2
+`
+	require.NoError(t, testutil.CollectAndCompare(NewCollector("test"), strings.NewReader(expected),
+		"test_ssz_bytes_total", "test_ssz_errors_total", "test_ssz_operations_total"))
+	require.Equal(t, 2, testutil.CollectAndCount(NewCollector("test"), "test_ssz_type_cache_lookups_total"))
+}
@@ -1,8 +1,10 @@
 package flexssz
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/gfx-labs/ssz"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -243,4 +245,86 @@ func TestRoundTrip(t *testing.T) {
 		assert.Equal(t, original.Val2.String(), decoded.Val2.String())
 		assert.Equal(t, original.Val3.String(), decoded.Val3.String())
 	})
-}
\ No newline at end of file
+
+	t.Run("list of lists", func(t *testing.T) {
+		type WithTransactions struct {
+			Transactions [][]byte `ssz-max:"16,8" ssz-size:"?,?"`
+		}
+
+		original := WithTransactions{
+			Transactions: [][]byte{{1, 2, 3}, {}, {4, 5, 6, 7, 8, 9, 10, 11}},
+		}
+
+		encoded, err := Marshal(original)
+		require.NoError(t, err)
+
+		var decoded WithTransactions
+		require.NoError(t, Unmarshal(encoded, &decoded))
+		assert.Len(t, decoded.Transactions, 3)
+		assert.Equal(t, original.Transactions[0], decoded.Transactions[0])
+		assert.Empty(t, decoded.Transactions[1])
+		assert.Equal(t, original.Transactions[2], decoded.Transactions[2])
+
+		// The inner limit is part of the type
+		info, err := GetTypeInfo(reflect.TypeOf(original), nil)
+		require.NoError(t, err)
+		assert.Equal(t, 16, info.Fields[0].Type.Length)
+		assert.Equal(t, 8, info.Fields[0].Type.ElementType.Length)
+	})
+}
+
+// dynamicDimensions uses "?" in ssz-size the way fastssz does, for dimensions whose length is
+// given by ssz-max instead
+type dynamicDimensions struct {
+	Roots    [][32]byte `ssz-size:"?,32" ssz-max:"8"`
+	Hashes   [][]byte   `ssz-size:"?,32" ssz-max:"8"`
+	Data     []byte     `ssz-size:"?" ssz-max:"16"`
+	Rows     [][]uint64 `ssz-size:"?,2" ssz-max:"4"`
+	Pairs    [][][]byte `ssz-size:"?,2,4" ssz-max:"4"`
+	Branches [][]byte   `ssz-size:"3,?" ssz-max:"?,8"`
+}
+
+func TestRoundTrip_DynamicDimensions(t *testing.T) {
+	info, err := GetTypeInfo(reflect.TypeFor[dynamicDimensions](), nil)
+	require.NoError(t, err)
+	for i, want := range []struct {
+		typ    ssz.TypeName
+		length int
+		elem   ssz.TypeName
+	}{
+		{ssz.TypeList, 8, ssz.TypeVector},
+		{ssz.TypeList, 8, ssz.TypeVector},
+		{ssz.TypeList, 16, ssz.TypeUint8},
+		{ssz.TypeList, 4, ssz.TypeVector},
+		{ssz.TypeList, 4, ssz.TypeVector},
+		{ssz.TypeVector, 3, ssz.TypeList},
+	} {
+		field := info.Fields[i]
+		assert.Equal(t, want.typ, field.Type.Type, field.Name)
+		assert.Equal(t, want.length, field.Type.Length, field.Name)
+		assert.Equal(t, want.elem, field.Type.ElementType.Type, field.Name)
+	}
+
+	original := dynamicDimensions{
+		Roots:    [][32]byte{{1}, {2}},
+		Hashes:   [][]byte{make([]byte, 32)},
+		Data:     []byte{1, 2, 3},
+		Rows:     [][]uint64{{1, 2}, {3, 4}},
+		Pairs:    [][][]byte{{{1, 2, 3, 4}, {5, 6, 7, 8}}},
+		Branches: [][]byte{{1}, {}, {2, 3}},
+	}
+	encoded, err := Marshal(&original)
+	require.NoError(t, err)
+	var decoded dynamicDimensions
+	require.NoError(t, UnmarshalStrict(encoded, &decoded))
+	assert.Equal(t, original, decoded)
+
+	// Branches is a vector of 3 lists, which is serialized like a list but must have 3 elements
+	short := original
+	short.Branches = [][]byte{{1}, {2}}
+	encoded, err = Marshal(&short)
+	require.NoError(t, err)
+	err = Unmarshal(encoded, &decoded)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vector has 2 elements, expected 3")
+}
@@ -0,0 +1,223 @@
+package flexssz
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"reflect"
+
+	"github.com/gfx-labs/ssz"
+)
+
+// SchemaOf returns the schema of the type of v, which must be a struct or a pointer to one, as
+// the container named after the type. Nested named structs are refs to their own containers,
+// which SchemaTypes returns, e.g. a field of type Checkpoint is {Type: ref, Ref: "Checkpoint"}.
+// Fields are named after the Go fields, and list and vector elements are named "element".
+func SchemaOf(v any) (ssz.Field, error) {
+	rt, err := schemaRootType(v)
+	if err != nil {
+		return ssz.Field{}, err
+	}
+	b := &schemaBuilder{types: make(map[string]ssz.Field), goTypes: make(map[string]reflect.Type)}
+	return b.container(rt)
+}
+
+// SchemaTypes returns the schema of the type of v and of every named struct it refers to, keyed
+// by type name, so the refs in them can be resolved, e.g. by ssz.Field.IsValid.
+func SchemaTypes(v any) (map[string]ssz.Field, error) {
+	rt, err := schemaRootType(v)
+	if err != nil {
+		return nil, err
+	}
+	b := &schemaBuilder{types: make(map[string]ssz.Field), goTypes: make(map[string]reflect.Type)}
+	if _, err := b.ref(rt); err != nil {
+		return nil, err
+	}
+	return b.types, nil
+}
+
+// schemaRootType returns the struct type of v
+func schemaRootType(v any) (reflect.Type, error) {
+	rt := reflect.TypeOf(v)
+	if rt == nil {
+		return nil, fmt.Errorf("cannot describe nil")
+	}
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct || rt.Name() == "" {
+		return nil, fmt.Errorf("schema requires a named struct type, got %v", rt)
+	}
+	return rt, nil
+}
+
+// schemaBuilder converts type info to schemas, collecting the containers of named structs
+type schemaBuilder struct {
+	types   map[string]ssz.Field
+	goTypes map[string]reflect.Type // Go type of each entry in types, to detect name clashes
+}
+
+// ref returns a ref to the container of the named struct rt, adding it to the types
+func (b *schemaBuilder) ref(rt reflect.Type) (ssz.Field, error) {
+	name := rt.Name()
+	if prev, ok := b.goTypes[name]; ok {
+		if prev != rt {
+			return ssz.Field{}, fmt.Errorf("types %v and %v are both named %s", prev, rt, name)
+		}
+		return ssz.Field{Type: ssz.TypeRef, Ref: name}, nil
+	}
+	// Registered before its fields, so recursive types end in a ref
+	b.goTypes[name] = rt
+	container, err := b.container(rt)
+	if err != nil {
+		return ssz.Field{}, err
+	}
+	b.types[name] = container
+	return ssz.Field{Type: ssz.TypeRef, Ref: name}, nil
+}
+
+// container returns the container of the named struct rt
+func (b *schemaBuilder) container(rt reflect.Type) (ssz.Field, error) {
+	info, err := GetTypeInfo(rt, nil)
+	if err != nil {
+		return ssz.Field{}, fmt.Errorf("error getting type info: %w", err)
+	}
+	field, err := b.field(rt, info, true)
+	if err != nil {
+		return ssz.Field{}, err
+	}
+	field.Name = rt.Name()
+	return field, nil
+}
+
+// field returns the schema of a value of Go type rt. Named structs are refs unless inline is
+// set, which is only the case for the struct being described.
+func (b *schemaBuilder) field(rt reflect.Type, info *TypeInfo, inline bool) (ssz.Field, error) {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if w := info.Wrapped; w != nil && rt.Kind() == reflect.Struct {
+		// Transparent wrappers are described as their field
+		return b.field(rt.Field(w.Index).Type, w.Type, false)
+	}
+	if info.Custom {
+		return ssz.Field{}, fmt.Errorf("%v encodes itself and has no schema", rt)
+	}
+
+	switch info.Type {
+	case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeUint128, ssz.TypeUint256, ssz.TypeBoolean:
+		return ssz.Field{Type: info.Type}, nil
+	case ssz.TypeBitVector:
+		return ssz.Field{Type: info.Type, Size: uint64(info.BitLength)}, nil
+	case ssz.TypeBitList:
+		return ssz.Field{Type: info.Type, Limit: uint64(info.BitLength)}, nil
+	case ssz.TypeUnion:
+		// Union options are only known from values
+		return ssz.Field{Type: info.Type}, nil
+	case ssz.TypeVector, ssz.TypeList:
+		if info.Progressive {
+			return ssz.Field{}, fmt.Errorf("progressive lists have no schema type")
+		}
+		if kv := info.kvlist; kv != nil {
+			// Maps are described as the list of their entries
+			rt, info = kv.entries, kv.info
+		}
+		elemType := reflect.TypeFor[byte]()
+		if rt.Kind() != reflect.String {
+			elemType = rt.Elem()
+		}
+		elem, err := b.field(elemType, info.ElementType, false)
+		if err != nil {
+			return ssz.Field{}, err
+		}
+		elem.Name = "element"
+		field := ssz.Field{Type: info.Type, Children: []ssz.Field{elem}}
+		if info.Type == ssz.TypeVector {
+			field.Size = uint64(info.Length)
+		} else {
+			field.Limit = uint64(info.Length)
+		}
+		return field, nil
+	case ssz.TypeContainer:
+		if !inline && rt.Name() != "" {
+			return b.ref(rt)
+		}
+		field := ssz.Field{Type: ssz.TypeContainer, Children: make([]ssz.Field, 0, len(info.Fields))}
+		for _, f := range info.Fields {
+			child, err := b.field(f.structField(rt).Type, f.Type, false)
+			if err != nil {
+				return ssz.Field{}, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			child.Name = f.Name
+			field.Children = append(field.Children, child)
+		}
+		return field, nil
+	default:
+		return ssz.Field{}, fmt.Errorf("unsupported SSZ type for schema: %v", info.Type)
+	}
+}
+
+// SchemaHash returns a hash of the layout of the type of v: the SSZ types, sizes, limits and
+// order of its fields, at any depth, as resolved from struct tags and typed limits. Types with
+// the same encoding have the same hash, whatever their Go and field names, so it can be stored
+// with encoded values to detect that the reader of a blob disagrees with its writer. v may be a
+// nil pointer of the type. Custom types are hashed by their size alone, and unions without their
+// options, since those are not known from the type.
+func SchemaHash(v any) ([32]byte, error) {
+	rt := reflect.TypeOf(v)
+	if rt == nil {
+		return [32]byte{}, fmt.Errorf("cannot describe nil")
+	}
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	info, err := GetTypeInfo(rt, nil)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("error getting type info: %w", err)
+	}
+	h := sha256.New()
+	writeSchemaHash(h, info, nil)
+	var out [32]byte
+	h.Sum(out[:0])
+	return out, nil
+}
+
+// writeSchemaHash writes the layout of a type to h. stack holds the types being written, so a
+// type containing itself writes the depth it was first seen at instead of recursing.
+func writeSchemaHash(h hash.Hash, info *TypeInfo, stack []*TypeInfo) {
+	writeUint := func(v int) {
+		h.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+	}
+	for depth, seen := range stack {
+		if seen == info {
+			h.Write([]byte("cycle"))
+			writeUint(depth)
+			return
+		}
+	}
+	stack = append(stack, info)
+
+	writeUint(len(info.Type))
+	h.Write([]byte(info.Type))
+	writeUint(info.FixedSize)
+	if info.Custom {
+		h.Write([]byte("custom"))
+		return
+	}
+	switch info.Type {
+	case ssz.TypeBitVector, ssz.TypeBitList:
+		writeUint(info.BitLength)
+	case ssz.TypeVector, ssz.TypeList:
+		if info.Progressive {
+			h.Write([]byte("progressive"))
+		}
+		writeUint(info.Length)
+		writeSchemaHash(h, info.ElementType, stack)
+	case ssz.TypeContainer:
+		writeUint(len(info.Fields))
+		for _, f := range info.Fields {
+			writeSchemaHash(h, f.Type, stack)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package flexssz
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaTestCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type schemaTestGwei struct {
+	Value uint64 `ssz:",transparent"`
+}
+
+type schemaTestState struct {
+	Slot       uint64
+	Finalized  *schemaTestCheckpoint
+	History    []schemaTestCheckpoint `ssz-max:"8"`
+	Balances   []schemaTestGwei       `ssz-max:"16"`
+	Bits       []byte                 `ssz:"bitlist" ssz-max:"64"`
+	Flags      []byte                 `ssz:"bitvector" ssz-size:"4"`
+	Extra      []byte                 `ssz-max:"32"`
+	Roots      [][]byte               `ssz-size:"2,32"`
+	Labels     map[uint64]string      `ssz:"kvlist" ssz-max:"4"`
+	Inline     struct{ A, B uint32 }
+	Unexported uint64 `ssz:"-"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	field, err := SchemaOf(&schemaTestState{})
+	require.NoError(t, err)
+
+	checkpoint := ssz.Field{Type: ssz.TypeRef, Ref: "schemaTestCheckpoint"}
+	bytes := func(n uint64) ssz.Field {
+		return ssz.Field{Type: ssz.TypeVector, Size: n, Children: []ssz.Field{{Name: "element", Type: ssz.TypeUint8}}}
+	}
+	expected := ssz.Field{Name: "schemaTestState", Type: ssz.TypeContainer, Children: []ssz.Field{
+		{Name: "Slot", Type: ssz.TypeUint64},
+		{Name: "Finalized", Type: ssz.TypeRef, Ref: "schemaTestCheckpoint"},
+		{Name: "History", Type: ssz.TypeList, Limit: 8, Children: []ssz.Field{{Name: "element", Type: checkpoint.Type, Ref: checkpoint.Ref}}},
+		{Name: "Balances", Type: ssz.TypeList, Limit: 16, Children: []ssz.Field{{Name: "element", Type: ssz.TypeUint64}}},
+		{Name: "Bits", Type: ssz.TypeBitList, Limit: 64},
+		{Name: "Flags", Type: ssz.TypeBitVector, Size: 4},
+		{Name: "Extra", Type: ssz.TypeList, Limit: 32, Children: []ssz.Field{{Name: "element", Type: ssz.TypeUint8}}},
+		{Name: "Roots", Type: ssz.TypeVector, Size: 2, Children: []ssz.Field{func() ssz.Field {
+			f := bytes(32)
+			f.Name = "element"
+			return f
+		}()}},
+		{Name: "Labels", Type: ssz.TypeList, Limit: 4, Children: []ssz.Field{{Name: "element", Type: ssz.TypeContainer, Children: []ssz.Field{
+			{Name: "Key", Type: ssz.TypeUint64},
+			{Name: "Value", Type: ssz.TypeList, Children: []ssz.Field{{Name: "element", Type: ssz.TypeUint8}}},
+		}}}},
+		{Name: "Inline", Type: ssz.TypeContainer, Children: []ssz.Field{
+			{Name: "A", Type: ssz.TypeUint32},
+			{Name: "B", Type: ssz.TypeUint32},
+		}},
+	}}
+	assert.Equal(t, expected, field)
+
+	types, err := SchemaTypes(schemaTestState{})
+	require.NoError(t, err)
+	require.Len(t, types, 2)
+	assert.Equal(t, expected, types["schemaTestState"])
+	root := bytes(32)
+	root.Name = "Root"
+	assert.Equal(t, ssz.Field{Name: "schemaTestCheckpoint", Type: ssz.TypeContainer, Children: []ssz.Field{
+		{Name: "Epoch", Type: ssz.TypeUint64},
+		root,
+	}}, types["schemaTestCheckpoint"])
+
+	// The schema agrees with the type info on the layout
+	root0 := types["schemaTestCheckpoint"]
+	variable, err := root0.IsVariable(types)
+	require.NoError(t, err)
+	assert.False(t, variable)
+	variable, err = field.IsVariable(types)
+	require.NoError(t, err)
+	assert.True(t, variable)
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := SchemaOf(uint64(1))
+		assert.ErrorContains(t, err, "named struct")
+		_, err = SchemaOf(nil)
+		assert.Error(t, err)
+		_, err = SchemaOf(&customTestValidator{})
+		assert.ErrorContains(t, err, "has no schema")
+	})
+}
+
+func TestSchemaHash(t *testing.T) {
+	type Checkpoint struct {
+		E uint64
+		R [32]byte
+	}
+	type Renamed struct {
+		S  uint64
+		F  *Checkpoint
+		H  []Checkpoint `ssz-max:"8"`
+		B  []uint64     `ssz-max:"16"`
+		Bi []byte       `ssz:"bitlist" ssz-max:"64"`
+		Fl []byte       `ssz:"bitvector" ssz-size:"4"`
+		Ex []byte       `ssz-max:"32"`
+		Ro [2][32]byte
+		La map[uint64]string `ssz:"kvlist" ssz-max:"4"`
+		In struct{ A, B uint32 }
+	}
+	type Limit struct {
+		Slot     uint64
+		Balances []uint64 `ssz-max:"17"`
+	}
+	type Reordered struct {
+		Balances []uint64 `ssz-max:"16"`
+		Slot     uint64
+	}
+	type Ordered struct {
+		Slot     uint64
+		Balances []uint64 `ssz-max:"16"`
+	}
+
+	hash := func(v any) [32]byte {
+		h, err := SchemaHash(v)
+		require.NoError(t, err)
+		return h
+	}
+	state := hash(&schemaTestState{})
+	assert.Equal(t, state, hash(schemaTestState{}))
+	assert.Equal(t, state, hash((*schemaTestState)(nil)))
+
+	// Names, wrappers and arrays in place of sized slices do not change the layout
+	assert.Equal(t, state, hash(Renamed{}))
+	assert.NotEqual(t, hash(Ordered{}), hash(Limit{}))
+	assert.NotEqual(t, hash(Ordered{}), hash(Reordered{}))
+	assert.NotEqual(t, state, hash(Ordered{}))
+
+	// The hash is stable across releases, changing it breaks stored blobs
+	assert.Equal(t, "4e7db9109d4b0d2fb63fbfc2b859d49166b374a45c436fb5f3a10b283a9324ac", fmt.Sprintf("%x", hash(Ordered{})))
+
+	_, err := SchemaHash(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Signed integers are not part of SSZ, but application protocols carry them. Fields tagged
+// ssz:"int64" or ssz:"int32", and lists and vectors of them, are encoded as the two's complement
+// bits of the value in a uint64 or uint32, little-endian like any other integer, so -1 encodes
+// as ff ff ff ff ff ff ff ff. They hash the same way, as the uint64 or uint32 of the same bits,
+// which makes their roots match a schema that declares the field as unsigned. The tag is
+// required, so a signed Go type is never encoded by accident, and only matches int64 and int32,
+// so unsigned fields cannot be decoded as signed or the other way around.
+
+// signedTagKind returns the Go kind an ssz:"int64" or ssz:"int32" tag requires, or false for
+// other tags
+func signedTagKind(fieldType string) (reflect.Kind, bool) {
+	switch fieldType {
+	case "int64":
+		return reflect.Int64, true
+	case "int32":
+		return reflect.Int32, true
+	}
+	return reflect.Invalid, false
+}
+
+// validateSignedField checks that signed integers, on their own or as the elements of slices and
+// arrays, are tagged with their width, and that the width tags are only used on them
+func validateSignedField(field reflect.StructField, tag *sszTag) error {
+	scalar := field.Type
+	for scalar.Kind() == reflect.Ptr || scalar.Kind() == reflect.Slice || scalar.Kind() == reflect.Array {
+		if isCustomType(scalar) || scalar == uint256Type {
+			return nil
+		}
+		scalar = scalar.Elem()
+	}
+
+	want, tagged := signedTagKind(tag.FieldType)
+	switch scalar.Kind() {
+	case reflect.Int64, reflect.Int32:
+		if !tagged {
+			return fmt.Errorf("field %s: signed type %v requires ssz tag '%s'", field.Name, field.Type, scalar.Kind())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16:
+		return fmt.Errorf("field %s: unsupported signed type %v, only int64 and int32 can be encoded", field.Name, field.Type)
+	}
+	if tagged && scalar.Kind() != want {
+		return fmt.Errorf("field %s: ssz tag '%s' requires Go type %v, got %v", field.Name, tag.FieldType, want, field.Type)
+	}
+	return nil
+}
+
+// integerBits returns an unsigned integer, or the two's complement bits of a signed one
+func integerBits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int64, reflect.Int32:
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
@@ -0,0 +1,528 @@
+package flexssz
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/gfx-labs/ssz"
+	"github.com/holiman/uint256"
+)
+
+// The sized encoder is an alternative to the Builder for values encoded by reflection. The
+// Builder queues a closure for every variable-size field and patches offsets through the
+// heap when Finish walks them. The sized encoder instead computes the size of the encoding
+// from the TypeInfo of the value first, so the output is allocated once, and then appends
+// the encoding to it in a single pass, writing each offset once the data it points to is
+// reached. It writes the same bytes as Marshal.
+
+// MarshalAppend appends the SSZ encoding of v to dst and returns the extended slice. dst is
+// grown once to fit the encoding, so no intermediate buffers are allocated; pass a buffer with
+// enough capacity, e.g. from a pool, to encode without allocating the output either.
+func MarshalAppend(dst []byte, v any) ([]byte, error) {
+	rv, err := derefRoot(reflect.ValueOf(v))
+	if err != nil {
+		return dst, err
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return dst, fmt.Errorf("error getting type info: %w", err)
+	}
+
+	start := len(dst)
+	err = withProfileLabels(context.Background(), rv.Type(), PhaseMarshal, func() error {
+		size, err := sizeRoot(rv, typeInfo)
+		if err != nil {
+			return err
+		}
+		out, err := appendRoot(slices.Grow(dst, size), rv, typeInfo)
+		if err == nil {
+			dst = out
+		}
+		return err
+	})
+	recordStats(rv.Type(), PhaseMarshal, len(dst)-start, err)
+	if err != nil {
+		return dst[:start], err
+	}
+	return dst, nil
+}
+
+// EncodedSize returns the number of bytes Marshal writes for v, without encoding it. Only
+// variable-size types that encode themselves, see Marshaler, are encoded to find their size.
+func EncodedSize(v any) (int, error) {
+	rv, err := derefRoot(reflect.ValueOf(v))
+	if err != nil {
+		return 0, err
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("error getting type info: %w", err)
+	}
+	return sizeRoot(rv, typeInfo)
+}
+
+// sizeRoot returns the size of a value that is not inside a container, see encodeRoot
+func sizeRoot(rv reflect.Value, typeInfo *TypeInfo) (int, error) {
+	rv, typeInfo = unwrapTransparent(rv, typeInfo)
+	if typeInfo.Custom {
+		return sizeCustom(rv, typeInfo)
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() != timeType {
+			return sizeContainer(rv, typeInfo)
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Type() != uint256Type {
+			return sizeElements(rv, typeInfo.ElementType)
+		}
+	case reflect.String:
+		return rv.Len(), nil
+	}
+	return max(typeInfo.FixedSize, 0), nil
+}
+
+// sizeValue returns the size of a value inside a container, list or vector. Fixed-size values
+// take the size of their type, so only variable-size values are walked.
+func sizeValue(v reflect.Value, typeInfo *TypeInfo) (int, error) {
+	if !typeInfo.IsVariable {
+		return max(typeInfo.FixedSize, 0), nil
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0, fmt.Errorf("cannot encode nil pointer")
+		}
+		v = v.Elem()
+	}
+	v, typeInfo = unwrapTransparent(v, typeInfo)
+	if typeInfo.Custom {
+		return sizeCustom(v, typeInfo)
+	}
+	if typeInfo.kvlist != nil {
+		return sizeElements(typeInfo.kvlist.sorted(v), typeInfo.ElementType)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len(), nil
+	case reflect.Slice, reflect.Array:
+		if typeInfo.Tag != nil && typeInfo.Tag.FieldType == "bitlist" && v.Len() == 0 {
+			return len(emptyBitList), nil
+		}
+		return sizeElements(v, typeInfo.ElementType)
+	case reflect.Struct:
+		return sizeContainer(v, typeInfo)
+	case reflect.Interface:
+		return sizeUnion(v)
+	}
+	return max(typeInfo.FixedSize, 0), nil
+}
+
+// sizeElements returns the size of the elements of a list or vector
+func sizeElements(v reflect.Value, elemType *TypeInfo) (int, error) {
+	if elemType == nil {
+		return 0, nil
+	}
+	if !elemType.IsVariable {
+		return v.Len() * max(elemType.FixedSize, 0), nil
+	}
+	size := 0
+	for i := 0; i < v.Len(); i++ {
+		n, err := sizeValue(v.Index(i), elemType)
+		if err != nil {
+			return 0, elementError(v.Index(i), i, err)
+		}
+		size += PtrSize + n
+	}
+	return size, nil
+}
+
+// sizeContainer returns the size of a struct encoded as a container
+func sizeContainer(v reflect.Value, typeInfo *TypeInfo) (int, error) {
+	if v.Type() == unionType {
+		return 0, fmt.Errorf("encoding unions is not supported")
+	}
+	if !typeInfo.IsVariable {
+		return max(typeInfo.FixedSize, 0), nil
+	}
+	size := 0
+	for _, field := range typeInfo.Fields {
+		if !field.Type.IsVariable {
+			size += max(field.Type.FixedSize, 0)
+			continue
+		}
+		n, err := sizeValue(field.value(v), field.Type)
+		if err != nil {
+			return 0, fmt.Errorf("error encoding variable field %s: %w", field.Name, err)
+		}
+		size += PtrSize + n
+	}
+	return size, nil
+}
+
+// sizeCustom returns the size of a type that encodes itself, which is only known without
+// encoding it when it is fixed-size
+func sizeCustom(v reflect.Value, typeInfo *TypeInfo) (int, error) {
+	if !typeInfo.IsVariable {
+		return typeInfo.FixedSize, nil
+	}
+	data, err := marshalCustom(v, typeInfo.Tag)
+	return len(data), err
+}
+
+// appendRoot appends a value that is not inside a container, see encodeRoot
+func appendRoot(dst []byte, rv reflect.Value, typeInfo *TypeInfo) ([]byte, error) {
+	rv, typeInfo = unwrapTransparent(rv, typeInfo)
+	tag := typeInfo.Tag
+	if tag == nil {
+		tag = &sszTag{}
+	}
+	if typeInfo.Custom {
+		data, err := marshalCustom(rv, tag)
+		return append(dst, data...), err
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return appendFixedField(dst, rv, tag)
+		}
+		return appendContainer(dst, rv, typeInfo)
+	case reflect.Slice, reflect.Array:
+		if rv.Type() != uint256Type {
+			return appendSequence(dst, rv, typeInfo, tag)
+		}
+	case reflect.String:
+		return append(dst, rv.String()...), nil
+	}
+	return appendFixedField(dst, rv, tag)
+}
+
+// appendSequence appends the elements of a list or vector root, see encodeSequence
+func appendSequence(dst []byte, rv reflect.Value, typeInfo *TypeInfo, tag *sszTag) ([]byte, error) {
+	if tag.MaxList > 0 && rv.Len() > tag.MaxList {
+		return dst, fmt.Errorf("list length %d exceeds limit %d", rv.Len(), tag.MaxList)
+	}
+	if len(tag.Size) > 0 && tag.Size[0] >= 0 && rv.Len() != tag.Size[0] {
+		return dst, fmt.Errorf("vector length %d does not match size %d", rv.Len(), tag.Size[0])
+	}
+
+	elemType := typeInfo.ElementType
+	if elemType.Type == ssz.TypeUint8 && rv.Type().Elem().Kind() == reflect.Uint8 {
+		// Byte lists and vectors are written as is
+		if bs, ok := sliceBytes(rv); ok {
+			return append(dst, bs...), nil
+		}
+		return appendByteArray(dst, rv), nil
+	}
+	if bs, ok := boolBytes(rv); ok {
+		return append(dst, bs...), nil
+	}
+	if size, ok := byteVectorSize(rv.Type(), elemType); ok {
+		return appendByteVectors(dst, rv, size)
+	}
+
+	elemTag := elemType.Tag
+	if elemTag == nil {
+		elemTag = &sszTag{}
+	}
+	if !elemType.IsVariable {
+		for i := 0; i < rv.Len(); i++ {
+			var err error
+			if dst, err = appendFixedField(dst, rv.Index(i), elemTag); err != nil {
+				return dst, elementError(rv.Index(i), i, err)
+			}
+		}
+		return dst, nil
+	}
+
+	start := len(dst)
+	dst = appendOffsets(dst, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		order.PutUint32(dst[start+i*PtrSize:], uint32(len(dst)-start))
+		var err error
+		if dst, err = appendVariableField(dst, rv.Index(i), elemTag); err != nil {
+			return dst, elementError(rv.Index(i), i, err)
+		}
+	}
+	return dst, nil
+}
+
+// appendContainer appends the fields of a struct in declaration order, see encodeContainer
+func appendContainer(dst []byte, rv reflect.Value, typeInfo *TypeInfo) ([]byte, error) {
+	if rv.Type() == unionType {
+		return dst, fmt.Errorf("encoding unions is not supported")
+	}
+	if !typeInfo.IsVariable {
+		start := len(dst)
+		out := slices.Grow(dst, typeInfo.FixedSize)[:start+typeInfo.FixedSize]
+		if ok, err := putFixedContainer(out[start:], rv, typeInfo); ok || err != nil {
+			if err != nil {
+				return dst, err
+			}
+			return out, nil
+		}
+	}
+
+	// The fixed part, with room for the offset of each variable-size field
+	start := len(dst)
+	var buf [16]int
+	offsets := buf[:0]
+	var err error
+	for _, field := range typeInfo.Fields {
+		if field.Type.IsVariable {
+			offsets = append(offsets, len(dst))
+			dst = appendOffsets(dst, 1)
+			continue
+		}
+		if dst, err = appendFixedField(dst, field.value(rv), field.Type.Tag); err != nil {
+			return dst, fmt.Errorf("error encoding field %s: %w", field.Name, err)
+		}
+	}
+	if !typeInfo.IsVariable {
+		return dst, nil
+	}
+
+	// The variable part, writing each offset once its data is reached
+	for _, field := range typeInfo.Fields {
+		if !field.Type.IsVariable {
+			continue
+		}
+		order.PutUint32(dst[offsets[0]:], uint32(len(dst)-start))
+		offsets = offsets[1:]
+		if dst, err = appendVariableField(dst, field.value(rv), field.Type.Tag); err != nil {
+			return dst, fmt.Errorf("error encoding variable field %s: %w", field.Name, err)
+		}
+	}
+	return dst, nil
+}
+
+// appendFixedField appends a fixed-size field, see encodeFixedField
+func appendFixedField(dst []byte, v reflect.Value, tag *sszTag) ([]byte, error) {
+	tag = withTypeDimensions(v.Type(), tag)
+	if isCustomType(v.Type()) {
+		data, err := marshalCustom(v, tag)
+		return append(dst, data...), err
+	}
+
+	switch v.Kind() {
+	case reflect.Uint8:
+		return append(dst, uint8(v.Uint())), nil
+	case reflect.Uint16:
+		return order.AppendUint16(dst, uint16(v.Uint())), nil
+	case reflect.Uint32, reflect.Int32:
+		return order.AppendUint32(dst, uint32(integerBits(v))), nil
+	case reflect.Uint64, reflect.Int64:
+		return order.AppendUint64(dst, integerBits(v)), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case reflect.Slice:
+		if len(tag.Size) == 0 {
+			return dst, fmt.Errorf("variable slices must be encoded as variable fields")
+		}
+		expectedLen := tag.Size[0]
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if tag.FieldType == "bitvector" {
+				expectedBytes := (expectedLen + 7) / 8
+				if v.Len() != expectedBytes {
+					return dst, fmt.Errorf("bitvector requires %d bytes for %d bits, got %d bytes", expectedBytes, expectedLen, v.Len())
+				}
+				dst = append(dst, v.Bytes()...)
+				// Clear any bits past the size, like EncodeBitVector
+				if extraBits := expectedLen % 8; extraBits > 0 {
+					dst[len(dst)-1] &= byte(1<<extraBits - 1)
+				}
+				return dst, nil
+			}
+			if v.Len() != expectedLen {
+				return dst, fmt.Errorf("slice length %d does not match ssz-size %d", v.Len(), expectedLen)
+			}
+			return append(dst, v.Bytes()...), nil
+		}
+		if bs, ok := boolBytes(v); ok {
+			return append(dst, bs...), nil
+		}
+		// For multi-dimensional arrays, pass down the remaining sizes
+		elemTag := tag.elementTag()
+		if size, ok := byteVectorTagSize(v.Type(), elemTag); ok {
+			return appendByteVectors(dst, v, size)
+		}
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if dst, err = appendFixedField(dst, v.Index(i), elemTag); err != nil {
+				return dst, elementError(v.Index(i), i, err)
+			}
+		}
+		return dst, nil
+	case reflect.Array:
+		if v.Type() == uint256Type {
+			if v.CanAddr() {
+				return appendUint256(dst, v.Addr().Interface().(*uint256.Int), tag), nil
+			}
+			val := v.Interface().(uint256.Int)
+			return appendUint256(dst, &val, tag), nil
+		}
+		if bs, ok := byteArrayBytes(v); ok {
+			return append(dst, bs...), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendByteArray(dst, v), nil
+		}
+		if bs, ok := boolBytes(v); ok {
+			return append(dst, bs...), nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if dst, err = appendFixedField(dst, v.Index(i), tag); err != nil {
+				return dst, elementError(v.Index(i), i, err)
+			}
+		}
+		return dst, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			// Tagged uint256 pointers use nil for zero
+			if tag != nil && tag.OmitZero && v.Type().Elem() == uint256Type {
+				return appendUint256(dst, new(uint256.Int), tag), nil
+			}
+			return dst, fmt.Errorf("cannot encode nil pointer")
+		}
+		if v.Type().Elem() == uint256Type {
+			return appendUint256(dst, v.Interface().(*uint256.Int), tag), nil
+		}
+		return appendFixedField(dst, v.Elem(), tag)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			sec, err := timeToUnix(v.Interface().(time.Time))
+			if err != nil {
+				return dst, err
+			}
+			return order.AppendUint64(dst, sec), nil
+		}
+		if inner, innerTag, ok := transparentField(v); ok {
+			return appendFixedField(dst, inner, innerTag)
+		}
+		typeInfo, err := GetTypeInfo(v.Type(), nil)
+		if err != nil {
+			return dst, fmt.Errorf("error getting type info: %w", err)
+		}
+		return appendContainer(dst, v, typeInfo)
+	}
+	return dst, fmt.Errorf("unsupported type for fixed field: %v", v.Kind())
+}
+
+// appendVariableField appends the data of a variable-size field, see encodeVariableField. The
+// offset of the field is written by the caller.
+func appendVariableField(dst []byte, v reflect.Value, tag *sszTag) ([]byte, error) {
+	tag = withTypeDimensions(v.Type(), tag)
+	if isCustomType(v.Type()) {
+		data, err := marshalCustom(v, tag)
+		return append(dst, data...), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return append(dst, v.String()...), nil
+	case reflect.Slice:
+		if tag.MaxList > 0 && v.Len() > tag.MaxList {
+			return dst, fmt.Errorf("slice length %d exceeds limit %d", v.Len(), tag.MaxList)
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if tag.FieldType != "bitlist" {
+				return append(dst, v.Bytes()...), nil
+			}
+			// Bitlists are kept in their serialized form, including the delimiter bit
+			bits := v.Bytes()
+			if len(bits) == 0 {
+				// An unset bitlist is the empty bitlist
+				bits = emptyBitList
+			}
+			if err := ValidateBitlist(bits, uint64(tag.MaxList)); err != nil {
+				return dst, fmt.Errorf("error encoding bitlist: %w", err)
+			}
+			return append(dst, bits...), nil
+		}
+		if bs, ok := boolBytes(v); ok {
+			return append(dst, bs...), nil
+		}
+
+		// For lists with ssz-size:"?,32" or ssz-max:"1024,32", get the element size and limit from the tag
+		elemTag := tag.elementTag()
+		elemTypeInfo, err := GetTypeInfo(v.Type().Elem(), elemTag)
+		if err != nil {
+			return dst, fmt.Errorf("error getting element type info: %w", err)
+		}
+		if !elemTypeInfo.IsVariable || !typeIsVariable(v.Type().Elem(), elemTag) {
+			if size, ok := byteVectorSize(v.Type(), elemTypeInfo); ok {
+				return appendByteVectors(dst, v, size)
+			}
+			for i := 0; i < v.Len(); i++ {
+				if dst, err = appendFixedField(dst, v.Index(i), elemTag); err != nil {
+					return dst, elementError(v.Index(i), i, err)
+				}
+			}
+			return dst, nil
+		}
+		start := len(dst)
+		dst = appendOffsets(dst, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			order.PutUint32(dst[start+i*PtrSize:], uint32(len(dst)-start))
+			if dst, err = appendVariableField(dst, v.Index(i), elemTag); err != nil {
+				return dst, elementError(v.Index(i), i, err)
+			}
+		}
+		return dst, nil
+	case reflect.Struct:
+		if inner, innerTag, ok := transparentField(v); ok {
+			return appendVariableField(dst, inner, innerTag)
+		}
+		typeInfo, err := GetTypeInfo(v.Type(), nil)
+		if err != nil {
+			return dst, fmt.Errorf("error getting type info: %w", err)
+		}
+		return appendContainer(dst, v, typeInfo)
+	case reflect.Map:
+		entries, entriesTag, err := kvListValue(v, tag)
+		if err != nil {
+			return dst, err
+		}
+		return appendVariableField(dst, entries, entriesTag)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return dst, fmt.Errorf("cannot encode nil pointer")
+		}
+		return appendVariableField(dst, v.Elem(), tag)
+	case reflect.Interface:
+		return appendUnion(dst, v)
+	}
+	return dst, fmt.Errorf("unsupported type for variable field: %v", v.Kind())
+}
+
+// appendOffsets appends room for n offsets, which are written once their data is reached
+func appendOffsets(dst []byte, n int) []byte {
+	return slices.Grow(dst, n*PtrSize)[:len(dst)+n*PtrSize]
+}
+
+// appendByteArray appends a byte array that is not addressable, so its memory cannot be read
+func appendByteArray(dst []byte, v reflect.Value) []byte {
+	for i := 0; i < v.Len(); i++ {
+		dst = append(dst, uint8(v.Index(i).Uint()))
+	}
+	return dst
+}
+
+// appendUint256 appends a uint256, or its lower 128 bits when tagged uint128
+func appendUint256(dst []byte, i *uint256.Int, tag *sszTag) []byte {
+	dst = order.AppendUint64(dst, i[0])
+	dst = order.AppendUint64(dst, i[1])
+	if tag != nil && tag.FieldType == "uint128" {
+		return dst
+	}
+	dst = order.AppendUint64(dst, i[2])
+	return order.AppendUint64(dst, i[3])
+}
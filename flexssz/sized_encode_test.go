@@ -0,0 +1,147 @@
+package flexssz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sizedTestInner struct {
+	A uint16
+	B []uint32 `ssz-max:"8"`
+}
+
+type sizedTestStruct struct {
+	U8      uint8
+	Flag    bool
+	U128    uint256.Int  `ssz:"uint128"`
+	U256    *uint256.Int `ssz-omitzero:"true"`
+	Root    [32]byte
+	Flags   [3]bool
+	Bits    []byte    `ssz:"bitvector" ssz-size:"12"`
+	Agg     []byte    `ssz:"bitlist" ssz-max:"64"`
+	Time    time.Time `ssz:"uint64,unix"`
+	Str     string
+	Bytes   []byte           `ssz-max:"64"`
+	Bools   []bool           `ssz-max:"8"`
+	Roots   [][]byte         `ssz-size:"?,4" ssz-max:"8"`
+	Txs     [][]byte         `ssz-max:"4,16"`
+	Inners  []sizedTestInner `ssz-max:"4"`
+	Inner   *sizedTestInner
+	Balance customTestPubkey `ssz-size:"48"`
+	Blob    *customTestBlob
+	Wrapped []transparentTestGwei `ssz-max:"4"`
+}
+
+func TestMarshalAppend(t *testing.T) {
+	values := map[string]any{
+		"container": &sizedTestStruct{
+			U8:      1,
+			Flag:    true,
+			U128:    *uint256.NewInt(2),
+			U256:    uint256.NewInt(3),
+			Root:    [32]byte{4},
+			Flags:   [3]bool{true, false, true},
+			Bits:    []byte{0xff, 0xff},
+			Agg:     []byte{0x05},
+			Time:    time.Unix(1700000000, 0),
+			Str:     "hello",
+			Bytes:   []byte{5, 6, 7},
+			Bools:   []bool{true, true},
+			Roots:   [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}},
+			Txs:     [][]byte{{1}, {}, {2, 3}},
+			Inners:  []sizedTestInner{{A: 1, B: []uint32{2}}, {A: 3}},
+			Inner:   &sizedTestInner{A: 4, B: []uint32{5, 6}},
+			Balance: customTestPubkey{key: [48]byte{9}},
+			Blob:    &customTestBlob{data: []byte{1, 2}},
+			Wrapped: []transparentTestGwei{{Value: 7}},
+		},
+		"empty":        &sizedTestStruct{Bits: make([]byte, 2), Inner: &sizedTestInner{}, Blob: &customTestBlob{}},
+		"list root":    []sizedTestInner{{A: 1}, {A: 2, B: []uint32{3}}},
+		"vector root":  [4]uint64{1, 2, 3, 4},
+		"bytes root":   []byte{1, 2, 3},
+		"string root":  "abc",
+		"uint64 root":  uint64(5),
+		"custom root":  &customTestBlob{data: []byte{1, 2, 3}},
+		"wrapped root": transparentTestGwei{Value: 8},
+	}
+	for name, v := range values {
+		t.Run(name, func(t *testing.T) {
+			expected, err := Marshal(v)
+			require.NoError(t, err)
+
+			size, err := EncodedSize(v)
+			require.NoError(t, err)
+			assert.Equal(t, len(expected), size)
+
+			encoded, err := MarshalAppend(nil, v)
+			require.NoError(t, err)
+			assert.Equal(t, expected, encoded)
+
+			// Appending keeps the prefix, and offsets stay relative to the value
+			encoded, err = MarshalAppend([]byte{0xaa, 0xbb}, v)
+			require.NoError(t, err)
+			assert.Equal(t, append([]byte{0xaa, 0xbb}, expected...), encoded)
+		})
+	}
+}
+
+func TestMarshalAppend_Errors(t *testing.T) {
+	prefix := []byte{1, 2, 3}
+	tests := map[string]struct {
+		v   any
+		err string
+	}{
+		"nil pointer": {&struct{ Inner *sizedTestInner }{}, "cannot encode nil pointer"},
+		"list limit":  {&sizedTestInner{B: make([]uint32, 9)}, "slice length 9 exceeds limit 8"},
+		"bitvector": {&struct {
+			B []byte `ssz:"bitvector" ssz-size:"12"`
+		}{B: []byte{1}}, "bitvector requires 2 bytes for 12 bits, got 1 bytes"},
+		"bitlist": {&struct {
+			B []byte `ssz:"bitlist" ssz-max:"4"`
+		}{B: []byte{0xff}}, "too many bits"},
+		"custom size": {&struct {
+			A customTestShort `ssz-size:"4"`
+		}{}, "encoded to 2 bytes, expected 4"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Marshal(tt.v)
+			require.ErrorContains(t, err, tt.err)
+
+			out, err := MarshalAppend(prefix, tt.v)
+			assert.ErrorContains(t, err, tt.err)
+			assert.Equal(t, prefix, out, "dst must be returned unchanged on error")
+		})
+	}
+}
+
+func BenchmarkMarshalAppend(b *testing.B) {
+	v := &sizedTestStruct{
+		Bits:   make([]byte, 2),
+		Inner:  &sizedTestInner{A: 1, B: []uint32{1, 2, 3}},
+		Blob:   &customTestBlob{data: make([]byte, 32)},
+		Txs:    [][]byte{make([]byte, 16), make([]byte, 16), make([]byte, 16), make([]byte, 16)},
+		Inners: []sizedTestInner{{A: 1, B: []uint32{1}}, {A: 2, B: []uint32{2}}},
+	}
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := Marshal(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("MarshalAppend", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 1024)
+		for b.Loop() {
+			if _, err := MarshalAppend(buf[:0], v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package flexssz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// Eth2 networking wraps SSZ payloads in snappy. Gossip messages are snappy block compressed,
+// req/resp chunks are the uvarint length of the SSZ payload followed by the payload in the
+// snappy framing format. MaxTotalSize in DecodeOptions bounds the decompressed size, which is
+// checked before anything is decompressed.
+
+// MarshalSnappy encodes v and compresses it in the snappy block format used by gossip
+func MarshalSnappy(v any) ([]byte, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+// UnmarshalSnappy decompresses a snappy block and decodes it into v
+func UnmarshalSnappy(data []byte, v any) error {
+	return UnmarshalSnappyWithOptions(data, v, DecodeOptions{})
+}
+
+// UnmarshalSnappyWithOptions is UnmarshalSnappy with decode options
+func UnmarshalSnappyWithOptions(data []byte, v any, opts DecodeOptions) error {
+	n, err := snappy.DecodedLen(data)
+	if err != nil {
+		return fmt.Errorf("snappy: %w", err)
+	}
+	if err := checkSnappySize(n, opts); err != nil {
+		return err
+	}
+	decoded, err := snappy.Decode(nil, data)
+	if err != nil {
+		return fmt.Errorf("snappy: %w", err)
+	}
+	return UnmarshalWithOptions(decoded, v, opts)
+}
+
+// WriteSnappy encodes v and writes it to w as a req/resp chunk: the uvarint length of the
+// encoding, followed by the encoding in the snappy framing format
+func WriteSnappy(w io.Writer, v any) error {
+	data, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	var prefix [binary.MaxVarintLen64]byte
+	if _, err := w.Write(prefix[:binary.PutUvarint(prefix[:], uint64(len(data)))]); err != nil {
+		return err
+	}
+	sw := snappy.NewBufferedWriter(w)
+	if _, err := sw.Write(data); err != nil {
+		return err
+	}
+	return sw.Close()
+}
+
+// ReadSnappy reads a req/resp chunk written by WriteSnappy from r and decodes it into v.
+// Only the chunk is read, so r can hold further chunks.
+func ReadSnappy(r io.Reader, v any) error {
+	return ReadSnappyWithOptions(r, v, DecodeOptions{})
+}
+
+// ReadSnappyWithOptions is ReadSnappy with decode options
+func ReadSnappyWithOptions(r io.Reader, v any, opts DecodeOptions) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		// Reads byte by byte, so that nothing past the chunk is consumed
+		br = &byteReader{r: r}
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return fmt.Errorf("snappy: reading length prefix: %w", err)
+	}
+	if n > uint64(maxInt) {
+		return fmt.Errorf("snappy: %w: length prefix %d is too large", ErrLimitExceeded, n)
+	}
+	if err := checkSnappySize(int(n), opts); err != nil {
+		return err
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(snappy.NewReader(r), data); err != nil {
+		return fmt.Errorf("snappy: reading %d byte payload: %w", n, err)
+	}
+	return UnmarshalWithOptions(data, v, opts)
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// checkSnappySize applies MaxTotalSize to the decompressed size of a payload
+func checkSnappySize(n int, opts DecodeOptions) error {
+	if opts.MaxTotalSize > 0 && n > opts.MaxTotalSize {
+		return &DecodeError{Err: fmt.Errorf("%w: snappy payload of %d bytes exceeds %d", ErrLimitExceeded, n, opts.MaxTotalSize)}
+	}
+	return nil
+}
+
+// byteReader reads single bytes from a reader without buffering
+type byteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(b.r, b.buf[:]); err != nil {
+		return 0, err
+	}
+	return b.buf[0], nil
+}
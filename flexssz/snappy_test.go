@@ -0,0 +1,76 @@
+package flexssz
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snappyTestStruct struct {
+	Slot uint64
+	Data []byte `ssz-max:"4096"`
+}
+
+func TestMarshalSnappy(t *testing.T) {
+	value := &snappyTestStruct{Slot: 9, Data: bytes.Repeat([]byte{7}, 1000)}
+
+	compressed, err := MarshalSnappy(value)
+	require.NoError(t, err)
+	encoded, err := Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, snappy.Encode(nil, encoded), compressed)
+	assert.Less(t, len(compressed), len(encoded))
+
+	var decoded snappyTestStruct
+	require.NoError(t, UnmarshalSnappy(compressed, &decoded))
+	assert.Equal(t, value, &decoded)
+
+	err = UnmarshalSnappyWithOptions(compressed, &decoded, DecodeOptions{MaxTotalSize: 100})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	assert.ErrorContains(t, UnmarshalSnappy([]byte{0xff}, &decoded), "snappy")
+}
+
+func TestWriteReadSnappy(t *testing.T) {
+	first := &snappyTestStruct{Slot: 1, Data: []byte{1, 2, 3}}
+	second := &snappyTestStruct{Slot: 2, Data: bytes.Repeat([]byte{9}, 2000)}
+
+	// Two chunks back to back, as in a req/resp stream
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteSnappy(buf, first))
+	require.NoError(t, WriteSnappy(buf, second))
+
+	// The chunk starts with the uncompressed length
+	encoded, err := Marshal(first)
+	require.NoError(t, err)
+	n, err := binary.ReadUvarint(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(encoded)), n)
+
+	// A reader without ReadByte must not consume the next chunk either
+	r := io.MultiReader(bytes.NewReader(buf.Bytes()))
+	var decoded snappyTestStruct
+	require.NoError(t, ReadSnappy(r, &decoded))
+	assert.Equal(t, first, &decoded)
+	require.NoError(t, ReadSnappy(r, &decoded))
+	assert.Equal(t, second, &decoded)
+
+	t.Run("size limit", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteSnappy(buf, second))
+		err := ReadSnappyWithOptions(buf, &decoded, DecodeOptions{MaxTotalSize: 1000})
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+	})
+
+	t.Run("truncated payload", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteSnappy(buf, second))
+		truncated := bytes.NewReader(buf.Bytes()[:buf.Len()-4])
+		assert.ErrorContains(t, ReadSnappy(truncated, &decoded), "reading 2012 byte payload")
+	})
+}
@@ -61,48 +61,12 @@ func TestBeaconStateBellatrixRoundtrip(t *testing.T) {
 	// Compare the sizes first
 	if len(originalData) != len(marshaledData) {
 		t.Errorf("Data size mismatch: original=%d, marshaled=%d", len(originalData), len(marshaledData))
-		
-		// Find where they start to differ
-		minLen := len(originalData)
-		if len(marshaledData) < minLen {
-			minLen = len(marshaledData)
-		}
-		
-		for i := 0; i < minLen; i++ {
-			if originalData[i] != marshaledData[i] {
-				t.Logf("First difference at byte %d: original=0x%02x, marshaled=0x%02x", i, originalData[i], marshaledData[i])
-				
-				// Show some context
-				start := i - 10
-				if start < 0 {
-					start = 0
-				}
-				end := i + 10
-				if end > minLen {
-					end = minLen
-				}
-				
-				t.Logf("Context (original): %x", originalData[start:end])
-				t.Logf("Context (marshaled): %x", marshaledData[start:end])
-				break
-			}
-		}
 	}
 	
 	// Compare the actual bytes
 	if !bytes.Equal(originalData, marshaledData) {
 		t.Error("Marshaled data does not match original data")
-		
-		// Additional debugging: check specific offsets that might be problematic
-		t.Run("DebugOffsets", func(t *testing.T) {
-			// The SSZ format has specific offsets for variable-length fields
-			// Let's check the first few offsets
-			if len(originalData) >= 4 && len(marshaledData) >= 4 {
-				origOffset := uint32(originalData[0]) | uint32(originalData[1])<<8 | uint32(originalData[2])<<16 | uint32(originalData[3])<<24
-				marshalOffset := uint32(marshaledData[0]) | uint32(marshaledData[1])<<8 | uint32(marshaledData[2])<<16 | uint32(marshaledData[3])<<24
-				t.Logf("First offset - original: %d, marshaled: %d", origOffset, marshalOffset)
-			}
-		})
+		logFirstDifference(t, originalData, marshaledData)
 	} else {
 		t.Log("✓ Round-trip successful: marshaled data matches original exactly!")
 	}
@@ -123,6 +87,31 @@ func TestBeaconStateBellatrixRoundtrip(t *testing.T) {
 		}
 	})
 	
+	// A mainnet state is canonically encoded, so strict decoding must accept it
+	t.Run("UnmarshalStrict", func(t *testing.T) {
+		if err := flexssz.UnmarshalStrict(originalData, &BeaconStateBellatrix{}); err != nil {
+			t.Fatalf("Failed to strictly unmarshal original data: %v", err)
+		}
+	})
+
+	// The sized encoder must write the same bytes as the Builder
+	t.Run("MarshalAppend", func(t *testing.T) {
+		size, err := flexssz.EncodedSize(state)
+		if err != nil {
+			t.Fatalf("Failed to compute encoded size: %v", err)
+		}
+		if size != len(originalData) {
+			t.Errorf("Encoded size mismatch: original=%d, computed=%d", len(originalData), size)
+		}
+		appended, err := flexssz.MarshalAppend(nil, state)
+		if err != nil {
+			t.Fatalf("Failed to marshal with MarshalAppend: %v", err)
+		}
+		if !bytes.Equal(originalData, appended) {
+			t.Error("MarshalAppend data does not match original data")
+		}
+	})
+
 	// Test hash consistency
 	t.Run("HashConsistency", func(t *testing.T) {
 		// Calculate hash of original unmarshaled state
@@ -148,4 +137,37 @@ func TestBeaconStateBellatrixRoundtrip(t *testing.T) {
 			t.Logf("✓ Hash consistency maintained: 0x%x", hash1)
 		}
 	})
-}
\ No newline at end of file
+}
+// logFirstDifference logs the field holding the first byte at which two serialized beacon states
+// differ, with an annotated hexdump of it in both, or of the bytes around it in large fields
+func logFirstDifference(t *testing.T, original, marshaled []byte) {
+	i := 0
+	for i < len(original) && i < len(marshaled) && original[i] == marshaled[i] {
+		i++
+	}
+	t.Logf("First difference at byte %d", i)
+	for _, data := range []struct {
+		name string
+		data []byte
+	}{{"original", original}, {"marshaled", marshaled}} {
+		layout, err := flexssz.Inspect(data.data, &BeaconStateBellatrix{})
+		if err != nil {
+			t.Logf("Inspecting %s: %v", data.name, err)
+		}
+		if layout == nil {
+			continue
+		}
+		at := layout.At(i)
+		if at == nil {
+			continue
+		}
+		if at.End-at.Start > 256 {
+			start := max(i-16, at.Start)
+			t.Logf("%s %s [%d, %d): %x at %d", data.name, at.Path, at.Start, at.End, data.data[start:min(i+16, at.End)], start)
+			continue
+		}
+		var buf bytes.Buffer
+		at.Dump(&buf)
+		t.Logf("%s %s:\n%s", data.name, at.Path, buf.String())
+	}
+}
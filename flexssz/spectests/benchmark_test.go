@@ -0,0 +1,97 @@
+package spectests
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+)
+
+// loadFixture reads and decompresses a gzipped ssz fixture
+func loadFixture(tb testing.TB, path string) []byte {
+	tb.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		tb.Fatalf("Failed to open fixture file: %v", err)
+	}
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		tb.Fatalf("Failed to create gzip reader: %v", err)
+	}
+	defer gzReader.Close()
+
+	data, err := io.ReadAll(gzReader)
+	if err != nil {
+		tb.Fatalf("Failed to read fixture data: %v", err)
+	}
+	return data
+}
+
+// Run with `make profile` to get cpu and memory profiles labeled by ssz type and phase.
+func BenchmarkBeaconStateBellatrix(b *testing.B) {
+	flexssz.SetProfileLabels(true)
+	defer flexssz.SetProfileLabels(false)
+
+	data := loadFixture(b, "_fixtures/beacon_state_bellatrix.ssz.gz")
+	state := &BeaconStateBellatrix{}
+	if err := flexssz.Unmarshal(data, state); err != nil {
+		b.Fatalf("Failed to unmarshal SSZ data: %v", err)
+	}
+
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := flexssz.Unmarshal(data, &BeaconStateBellatrix{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := flexssz.Marshal(state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("MarshalAppend", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := flexssz.MarshalAppend(nil, state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("HashTreeRoot", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := flexssz.HashTreeRoot(state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkZeroBeaconStateBellatrix(b *testing.B) {
+	data := loadFixture(b, "_fixtures/zero_beacon_state_bellatrix.ssz.gz")
+
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := flexssz.Unmarshal(data, &BeaconStateBellatrix{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
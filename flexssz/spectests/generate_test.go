@@ -0,0 +1,27 @@
+package spectests
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateBeaconStates(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, v := range []any{&BeaconState{}, &BeaconStateAltair{}, &BeaconStateBellatrix{}, &BeaconStateCapella{}, &SignedBeaconBlock{}} {
+		require.NoError(t, flexssz.Generate(r, v, flexssz.GenMaxLength(4)), "%T", v)
+		data, err := flexssz.Marshal(v)
+		require.NoError(t, err, "%T", v)
+		decoded := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		require.NoError(t, flexssz.UnmarshalStrict(data, decoded), "%T", v)
+		equal, err := flexssz.Equal(v, decoded)
+		require.NoError(t, err)
+		assert.True(t, equal, "%T", v)
+		_, err = flexssz.HashTreeRoot(v)
+		require.NoError(t, err, "%T", v)
+	}
+}
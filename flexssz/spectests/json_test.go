@@ -0,0 +1,64 @@
+package spectests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/gfx-labs/ssz/ssztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONRoundTrip checks that every ssz_static vector survives SSZ -> JSON -> SSZ unchanged
+func TestJSONRoundTrip(t *testing.T) {
+	for _, typ := range sszStaticTypes {
+		t.Run(typ.name, func(t *testing.T) {
+			vectors, err := ssztest.LoadVectors(filepath.Join(sszStaticDir, typ.name))
+			require.NoError(t, err)
+
+			for _, vector := range vectors {
+				t.Run(vector.Name, func(t *testing.T) {
+					value := typ.new()
+					require.NoError(t, flexssz.Unmarshal(vector.Serialized, value))
+
+					encoded, err := flexssz.MarshalJSON(value)
+					require.NoError(t, err)
+
+					decoded := typ.new()
+					require.NoError(t, flexssz.UnmarshalJSON(encoded, decoded))
+					serialized, err := flexssz.Marshal(decoded)
+					require.NoError(t, err)
+					assert.Equal(t, vector.Serialized, serialized)
+				})
+			}
+		})
+	}
+}
+
+func TestJSONBeaconAPINames(t *testing.T) {
+	attestation := &Attestation{
+		AggregationBits: []byte{0x03},
+		Data: &AttestationData{
+			Slot:   1,
+			Source: &Checkpoint{Root: make([]byte, 32)},
+			Target: &Checkpoint{Epoch: 2, Root: make([]byte, 32)},
+		},
+	}
+	encoded, err := flexssz.MarshalJSON(attestation)
+	require.NoError(t, err)
+
+	zero := "0x" + strings.Repeat("00", 32)
+	assert.JSONEq(t, `{
+		"aggregation_bits": "0x03",
+		"data": {
+			"slot": "1",
+			"index": "0",
+			"beacon_block_root": "`+zero+`",
+			"source": {"epoch": "0", "root": "`+zero+`"},
+			"target": {"epoch": "2", "root": "`+zero+`"}
+		},
+		"signature": "0x`+strings.Repeat("00", 96)+`"
+	}`, string(encoded))
+}
@@ -0,0 +1,35 @@
+package spectests
+
+import (
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListIteratorBeaconState(t *testing.T) {
+	data := loadFixture(t, "_fixtures/beacon_state_bellatrix.ssz.gz")
+	state := &BeaconStateBellatrix{}
+	require.NoError(t, flexssz.Unmarshal(data, state))
+
+	validators, err := flexssz.ListIterator[BeaconStateBellatrix](data, "Validators")
+	require.NoError(t, err)
+	require.Equal(t, len(state.Validators), validators.Len())
+	for _, i := range []int{0, validators.Len() / 2, validators.Len() - 1} {
+		var v Validator
+		require.NoError(t, validators.DecodeAt(i, &v))
+		assert.Equal(t, state.Validators[i], &v)
+	}
+
+	mixes, err := flexssz.ListIterator[BeaconStateBellatrix](data, "RandaoMixes")
+	require.NoError(t, err)
+	require.Equal(t, 65536, mixes.Len())
+	mix, err := mixes.At(1234)
+	require.NoError(t, err)
+	assert.Equal(t, state.RandaoMixes[1234], mix)
+
+	extraData, err := flexssz.ListIterator[BeaconStateBellatrix](data, "LatestExecutionPayloadHeader.ExtraData")
+	require.NoError(t, err)
+	assert.Equal(t, len(state.LatestExecutionPayloadHeader.ExtraData), extraData.Len())
+}
@@ -0,0 +1,243 @@
+package spectests
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gfx-labs/ssz/ssztest"
+	dynssz "github.com/pk910/dynamic-ssz"
+	"github.com/stretchr/testify/require"
+)
+
+// Regenerate with `go test ./flexssz/spectests -run TestGenerateSszStaticVectors -update-vectors`
+var updateVectors = flag.Bool("update-vectors", false, "regenerate the ssz_static fixtures using dynamic-ssz as the reference")
+
+const sszStaticDir = "_fixtures/ssz_static"
+
+// sszStaticCases is the number of generated cases per type. Case 0 has empty lists, the others are random.
+const sszStaticCases = 3
+
+// sszStaticType describes a type with ssz_static vectors. ref returns the equivalent type dynamic-ssz
+// uses to generate the vectors, which differs from the flexssz type only where dynamic-ssz reads the
+// struct differently (see the reference types below).
+type sszStaticType struct {
+	name string
+	new  func() any
+	ref  func() any
+}
+
+var sszStaticTypes = []sszStaticType{
+	{"SignedBeaconBlockDeneb", func() any { return new(SignedBeaconBlockDeneb) }, func() any { return new(refSignedBeaconBlockDeneb) }},
+	{"BeaconBlockDeneb", func() any { return new(BeaconBlockDeneb) }, func() any { return new(refBeaconBlockDeneb) }},
+	{"BeaconBlockBodyDeneb", func() any { return new(BeaconBlockBodyDeneb) }, func() any { return new(refBeaconBlockBodyDeneb) }},
+	{"Attestation", func() any { return new(Attestation) }, func() any { return new(refAttestation) }},
+	{"SignedAggregateAndProof", func() any { return new(SignedAggregateAndProof) }, func() any { return new(refSignedAggregateAndProof) }},
+	{"SyncCommitteeMessage", func() any { return new(SyncCommitteeMessage) }, func() any { return new(SyncCommitteeMessage) }},
+	{"BlobSidecar", func() any { return new(BlobSidecar) }, func() any { return new(BlobSidecar) }},
+}
+
+func TestSszStaticVectors(t *testing.T) {
+	for _, typ := range sszStaticTypes {
+		t.Run(typ.name, func(t *testing.T) {
+			vectors, err := ssztest.LoadVectors(filepath.Join(sszStaticDir, typ.name))
+			require.NoError(t, err)
+			require.NotEmpty(t, vectors, "no vectors for %s", typ.name)
+
+			for _, vector := range vectors {
+				t.Run(vector.Name, func(t *testing.T) {
+					require.NoError(t, ssztest.CheckVector(reflect.TypeOf(typ.new()).Elem(), vector))
+				})
+			}
+		})
+	}
+}
+
+func TestGenerateSszStaticVectors(t *testing.T) {
+	if !*updateVectors {
+		t.Skip("run with -update-vectors to regenerate fixtures")
+	}
+	ds := dynssz.NewDynSsz(nil)
+	for i, typ := range sszStaticTypes {
+		require.NoError(t, os.RemoveAll(filepath.Join(sszStaticDir, typ.name)))
+		for c := 0; c < sszStaticCases; c++ {
+			f := &vectorFiller{rng: rand.New(rand.NewSource(int64(i*sszStaticCases + c))), empty: c == 0}
+			value := typ.ref()
+			f.fill(reflect.ValueOf(value).Elem(), nil, nil, false)
+
+			data, err := ds.MarshalSSZ(value)
+			require.NoError(t, err, typ.name)
+			root, err := ds.HashTreeRoot(value)
+			require.NoError(t, err, typ.name)
+
+			vector := ssztest.Vector{Name: fmt.Sprintf("case_%d", c), Serialized: data, Root: root}
+			require.NoError(t, ssztest.WriteVector(filepath.Join(sszStaticDir, typ.name), vector))
+		}
+	}
+}
+
+// vectorFiller fills values with deterministic pseudo-random data that respects the ssz tags
+type vectorFiller struct {
+	rng *rand.Rand
+	// empty leaves every list and bitlist empty
+	empty bool
+}
+
+// maxRandomListLen caps random list lengths to keep the fixtures small
+const maxRandomListLen = 3
+
+// maxRandomBytes caps the random prefix of byte vectors, the rest stays zero so fixtures compress
+const maxRandomBytes = 256
+
+func (f *vectorFiller) fill(v reflect.Value, sizes, maxes []string, bitlist bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		f.fill(v.Elem(), sizes, maxes, bitlist)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("ssz") == "-" {
+				continue
+			}
+			f.fill(v.Field(i), splitTag(field.Tag.Get("ssz-size")), splitTag(field.Tag.Get("ssz-max")), field.Type == reflect.TypeOf(refBitlist{}))
+		}
+	case reflect.Bool:
+		v.SetBool(f.rng.Intn(2) == 1)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(f.rng.Uint64())
+	case reflect.Array:
+		for i := 0; i < v.Len() && (v.Type().Elem().Kind() != reflect.Uint8 || i < maxRandomBytes); i++ {
+			f.fill(v.Index(i), tail(sizes), tail(maxes), false)
+		}
+	case reflect.Slice:
+		if bitlist {
+			v.SetBytes(f.bitlist(tagInt(maxes, 0)))
+			return
+		}
+		n := tagInt(sizes, 0)
+		if n < 0 {
+			n = 0
+			if !f.empty {
+				n = f.rng.Intn(min(tagInt(maxes, 0), maxRandomListLen) + 1)
+			}
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n && (v.Type().Elem().Kind() != reflect.Uint8 || i < maxRandomBytes); i++ {
+			f.fill(v.Index(i), tail(sizes), tail(maxes), false)
+		}
+	default:
+		panic(fmt.Sprintf("vectorFiller: unsupported kind %s", v.Kind()))
+	}
+}
+
+// bitlist returns a random bitlist of up to limit bits, including the delimiter bit
+func (f *vectorFiller) bitlist(limit int) []byte {
+	bitLen := 0
+	if !f.empty {
+		bitLen = f.rng.Intn(min(limit, 64) + 1)
+	}
+	out := make([]byte, bitLen/8+1)
+	for i := 0; i < bitLen; i++ {
+		if f.rng.Intn(2) == 1 {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	out[bitLen/8] |= 1 << (bitLen % 8)
+	return out
+}
+
+func splitTag(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+func tail(parts []string) []string {
+	if len(parts) == 0 {
+		return nil
+	}
+	return parts[1:]
+}
+
+// tagInt returns the i-th dimension of a size or max tag, or -1 if it is missing or dynamic
+func tagInt(parts []string, i int) int {
+	if i >= len(parts) {
+		return -1
+	}
+	n, err := strconv.Atoi(parts[i])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// The reference types below mirror the containers dynamic-ssz would otherwise get wrong: it only
+// hashes a byte slice as a bitlist when its type name contains "Bitlist", and it does not skip
+// fields tagged ssz:"-".
+
+type refBitlist []byte
+
+type refAttestation struct {
+	AggregationBits refBitlist `ssz-max:"2048"`
+	Data            *AttestationData
+	Signature       [96]byte `ssz-size:"96"`
+}
+
+type refDepositData struct {
+	Pubkey                [48]byte `ssz-size:"48"`
+	WithdrawalCredentials [32]byte `ssz-size:"32"`
+	Amount                uint64
+	Signature             []byte `ssz-size:"96"`
+}
+
+type refDeposit struct {
+	Proof [][]byte `ssz-size:"33,32"`
+	Data  *refDepositData
+}
+
+type refAggregateAndProof struct {
+	Index          uint64
+	Aggregate      *refAttestation
+	SelectionProof [96]byte `ssz-size:"96"`
+}
+
+type refSignedAggregateAndProof struct {
+	Message   *refAggregateAndProof
+	Signature [96]byte `ssz-size:"96"`
+}
+
+type refSignedBeaconBlockDeneb struct {
+	Block     *refBeaconBlockDeneb
+	Signature [96]byte `ssz-size:"96"`
+}
+
+type refBeaconBlockDeneb struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte `ssz-size:"32"`
+	StateRoot     [32]byte `ssz-size:"32"`
+	Body          *refBeaconBlockBodyDeneb
+}
+
+type refBeaconBlockBodyDeneb struct {
+	RandaoReveal          [96]byte `ssz-size:"96"`
+	Eth1Data              *Eth1Data
+	Graffiti              [32]byte               `ssz-size:"32"`
+	ProposerSlashings     []*ProposerSlashing    `ssz-max:"16"`
+	AttesterSlashings     []*AttesterSlashing    `ssz-max:"2"`
+	Attestations          []*refAttestation      `ssz-max:"128"`
+	Deposits              []*refDeposit          `ssz-max:"16"`
+	VoluntaryExits        []*SignedVoluntaryExit `ssz-max:"16"`
+	SyncAggregate         *SyncAggregate
+	ExecutionPayload      *ExecutionPayloadDeneb
+	BlsToExecutionChanges []*SignedBLSToExecutionChange `ssz-max:"16"`
+	BlobKzgCommitments    [][48]byte                    `ssz-max:"4096" ssz-size:"?,48"`
+}
@@ -484,3 +484,52 @@ type ExecutionPayloadHeaderDeneb struct {
 	BlobGasUsed      uint64    `json:"blob_gas_used"`
 	ExcessBlobGas    uint64    `json:"excess_blob_gas"`
 }
+
+type SignedBeaconBlockDeneb struct {
+	Block     *BeaconBlockDeneb `json:"message"`
+	Signature [96]byte          `json:"signature" ssz-size:"96"`
+}
+
+type BeaconBlockDeneb struct {
+	Slot          uint64                `json:"slot"`
+	ProposerIndex uint64                `json:"proposer_index"`
+	ParentRoot    [32]byte              `json:"parent_root" ssz-size:"32"`
+	StateRoot     [32]byte              `json:"state_root" ssz-size:"32"`
+	Body          *BeaconBlockBodyDeneb `json:"body"`
+}
+
+type BeaconBlockBodyDeneb struct {
+	RandaoReveal          [96]byte                      `json:"randao_reveal" ssz-size:"96"`
+	Eth1Data              *Eth1Data                     `json:"eth1_data"`
+	Graffiti              [32]byte                      `json:"graffiti" ssz-size:"32"`
+	ProposerSlashings     []*ProposerSlashing           `json:"proposer_slashings" ssz-max:"16"`
+	AttesterSlashings     []*AttesterSlashing           `json:"attester_slashings" ssz-max:"2"`
+	Attestations          []*Attestation                `json:"attestations" ssz-max:"128"`
+	Deposits              []*Deposit                    `json:"deposits" ssz-max:"16"`
+	VoluntaryExits        []*SignedVoluntaryExit        `json:"voluntary_exits" ssz-max:"16"`
+	SyncAggregate         *SyncAggregate                `json:"sync_aggregate"`
+	ExecutionPayload      *ExecutionPayloadDeneb        `json:"execution_payload"`
+	BlsToExecutionChanges []*SignedBLSToExecutionChange `json:"bls_to_execution_changes" ssz-max:"16"`
+	BlobKzgCommitments    [][48]byte                    `json:"blob_kzg_commitments" ssz-max:"4096" ssz-size:"?,48"`
+}
+
+type SignedAggregateAndProof struct {
+	Message   *AggregateAndProof `json:"message"`
+	Signature [96]byte           `json:"signature" ssz-size:"96"`
+}
+
+type SyncCommitteeMessage struct {
+	Slot            uint64   `json:"slot"`
+	BeaconBlockRoot [32]byte `json:"beacon_block_root" ssz-size:"32"`
+	ValidatorIndex  uint64   `json:"validator_index"`
+	Signature       [96]byte `json:"signature" ssz-size:"96"`
+}
+
+type BlobSidecar struct {
+	Index                       uint64                   `json:"index"`
+	Blob                        []byte                   `json:"blob" ssz-size:"131072"`
+	KzgCommitment               [48]byte                 `json:"kzg_commitment" ssz-size:"48"`
+	KzgProof                    [48]byte                 `json:"kzg_proof" ssz-size:"48"`
+	SignedBlockHeader           *SignedBeaconBlockHeader `json:"signed_block_header"`
+	KzgCommitmentInclusionProof [][32]byte               `json:"kzg_commitment_inclusion_proof" ssz-size:"17,32"`
+}
@@ -1,6 +1,7 @@
 package spectests
 
 import (
+	"bytes"
 	"testing"
 	
 	"github.com/gfx-labs/ssz/flexssz"
@@ -75,17 +76,19 @@ func TestMarshal(t *testing.T) {
 			t.Fatalf("Failed to marshal: %v", err)
 		}
 		
-		// SSZ encoding of a byte slice includes a length prefix
-		// So we expect 4 bytes (length) + 8 bytes (data) = 12 bytes
-		expectedLen := 4 + len(original)
-		if len(encoded) != expectedLen {
-			t.Errorf("Encoded length mismatch: got %d, want %d", len(encoded), expectedLen)
+		// A byte list at the root is just its bytes, the length comes from the input size
+		if !bytes.Equal(encoded, original) {
+			t.Errorf("Encoded bytes mismatch: got %x, want %x", encoded, original)
 		}
 		
-		// Note: Direct unmarshaling of slices at the root level has limitations
-		// because SSZ lists need length information that's embedded in the encoding
-		// This is expected behavior - for full compatibility, use struct fields
+		var decoded []byte
+		if err := flexssz.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+		if !bytes.Equal(decoded, original) {
+			t.Errorf("Value mismatch: got %x, want %x", decoded, original)
+		}
 		
-		t.Logf("✓ byte slice marshal successful (unmarshal limitations noted)")
+		t.Logf("✓ byte slice marshal/unmarshal successful")
 	})
 }
\ No newline at end of file
@@ -0,0 +1,15 @@
+// Command sszvet checks ssz struct tags, see package sszvet. It runs on its own or as a go vet
+// tool:
+//
+//	sszvet ./...
+//	go vet -vettool=$(which sszvet) ./...
+package main
+
+import (
+	"github.com/gfx-labs/ssz/flexssz/sszvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(sszvet.Analyzer)
+}
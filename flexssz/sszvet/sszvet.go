@@ -0,0 +1,471 @@
+// Package sszvet checks the ssz struct tags read by flexssz at build time, as a go vet analyzer,
+// instead of when PrecacheStructSSZInfo or the first Marshal parses them at runtime.
+//
+// Structs with an ssz, ssz-size, ssz-max, ssz-max-name or ssz-omitzero tag on any field are
+// checked, along with the structs of the same package they refer to. It reports:
+//
+//   - fields of types SSZ cannot encode: floats, complex numbers, channels, functions, unnamed
+//     interfaces such as any, int, int8, int16, uint and uintptr, and maps without ssz:"kvlist".
+//     Named interfaces are unions of the types registered with flexssz.RegisterUnionMember,
+//     which is only known at runtime.
+//   - int64 and int32 fields without their ssz:"int64" or ssz:"int32" tag
+//   - slices without ssz-max or ssz-size, unless their type carries its limit or length
+//   - tags that conflict, such as ssz-size and ssz-max without a "?" dimension, or that do not
+//     apply to the type of their field, such as ssz-max on an array
+//   - ssz-size dimensions that do not match the lengths of arrays, or that the type does not have
+//   - ssz tags with unknown types or options, and ssz-size and ssz-max values that do not parse
+//
+// Run it with go vet:
+//
+//	go build -o sszvet github.com/gfx-labs/ssz/flexssz/sszvet/cmd/sszvet
+//	go vet -vettool=$(pwd)/sszvet ./...
+package sszvet
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer checks ssz struct tags, see the package documentation
+var Analyzer = &analysis.Analyzer{
+	Name: "sszvet",
+	Doc:  "check ssz struct tags for types flexssz cannot encode and tags it rejects",
+	URL:  "https://pkg.go.dev/github.com/gfx-labs/ssz/flexssz/sszvet",
+	Run:  run,
+}
+
+// sszTagKeys are the struct tags that mark a struct as encoded with SSZ
+var sszTagKeys = []string{"ssz", "ssz-size", "ssz-max", "ssz-max-name", "ssz-omitzero"}
+
+// sszTypes are the types an ssz tag may name
+var sszTypes = map[string]bool{
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true, "uint128": true, "uint256": true,
+	"int32": true, "int64": true, "bool": true, "string": true, "list": true, "progressive-list": true,
+	"bytelist": true, "vector": true, "container": true, "union": true, "bitlist": true, "bitvector": true, "kvlist": true,
+	"inline": true,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	c := &checker{pass: pass, checked: make(map[*types.Struct]bool)}
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		if st, ok := tn.Type().Underlying().(*types.Struct); ok && hasSSZTags(st) {
+			c.checkStruct(tn.Name(), st)
+		}
+	}
+	return nil, nil
+}
+
+// hasSSZTags reports whether any field of st has an ssz tag
+func hasSSZTags(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		tag := reflect.StructTag(st.Tag(i))
+		for _, key := range sszTagKeys {
+			if _, ok := tag.Lookup(key); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type checker struct {
+	pass    *analysis.Pass
+	checked map[*types.Struct]bool
+}
+
+// checkStruct checks the fields of st, and then the structs of the package they refer to
+func (c *checker) checkStruct(name string, st *types.Struct) {
+	if c.checked[st] {
+		return
+	}
+	c.checked[st] = true
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		if tag.Get("ssz") == "-" {
+			continue
+		}
+		c.checkField(name, field, tag)
+		c.follow(name+"."+field.Name(), field.Type())
+	}
+}
+
+// follow checks the structs declared in the package that t is made of
+func (c *checker) follow(name string, t types.Type) {
+	switch t := t.(type) {
+	case *types.Pointer:
+		c.follow(name, t.Elem())
+	case *types.Slice:
+		c.follow(name, t.Elem())
+	case *types.Array:
+		c.follow(name, t.Elem())
+	case *types.Map:
+		c.follow(name, t.Key())
+		c.follow(name, t.Elem())
+	case *types.Named:
+		if t.Obj().Pkg() != c.pass.Pkg || isCustom(t) {
+			return
+		}
+		if st, ok := t.Underlying().(*types.Struct); ok {
+			c.checkStruct(t.Obj().Name(), st)
+		}
+	case *types.Struct:
+		c.checkStruct(name, t)
+	}
+}
+
+// fieldTag is the parsed ssz tags of a field, like flexssz.sszTag
+type fieldTag struct {
+	fieldType   string
+	unix        bool
+	size        []int // -1 for a "?" dimension
+	max         []int // 0 for a "?" dimension
+	hasSize     bool  // set even when ssz-size does not parse, which is reported on its own
+	hasMax      bool
+	omitZero    bool
+	variableDim bool // ssz-size has a "?" dimension
+}
+
+func (c *checker) checkField(structName string, field *types.Var, tag reflect.StructTag) {
+	report := func(format string, args ...any) {
+		c.pass.Reportf(field.Pos(), "field %s.%s: %s", structName, field.Name(), fmt.Sprintf(format, args...))
+	}
+
+	var ft fieldTag
+	fieldType, options, _ := strings.Cut(tag.Get("ssz"), ",")
+	ft.fieldType = fieldType
+	if fieldType != "" && !sszTypes[fieldType] {
+		report("unknown ssz type %q", fieldType)
+	}
+	if fieldType == "inline" && !field.Embedded() && !field.Exported() {
+		report("ssz tag 'inline' requires an exported or embedded field")
+	}
+	for _, option := range strings.Split(options, ",") {
+		switch strings.TrimSpace(option) {
+		case "", "transparent":
+		case "unix":
+			ft.unix = true
+		default:
+			report("unknown ssz tag option %q", option)
+		}
+	}
+	var err error
+	ft.hasSize = tag.Get("ssz-size") != ""
+	if ft.size, err = parseDims(tag.Get("ssz-size"), -1); err != nil {
+		report("invalid ssz-size value: %v", err)
+	}
+	for _, size := range ft.size {
+		ft.variableDim = ft.variableDim || size == -1
+	}
+	maxStr, hasMax := tag.Lookup("ssz-max")
+	ft.hasMax = hasMax && maxStr != ""
+	if ft.max, err = parseDims(maxStr, 0); err != nil {
+		report("invalid ssz-max value: %v", err)
+	}
+	if omitStr := tag.Get("ssz-omitzero"); omitStr != "" {
+		if ft.omitZero, err = strconv.ParseBool(omitStr); err != nil {
+			report("invalid ssz-omitzero value: %v", err)
+		}
+	}
+
+	t := field.Type()
+	for _, problem := range c.typeProblems(t, ft) {
+		report("%s", problem)
+	}
+}
+
+// parseDims parses the comma separated dimensions of ssz-size or ssz-max, with "?" as unknown
+func parseDims(s string, unknown int) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	dims := make([]int, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "?" {
+			dims[i] = unknown
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		dims[i] = n
+	}
+	return dims, nil
+}
+
+// typeProblems returns what flexssz would reject about a field of type t with tag ft
+func (c *checker) typeProblems(t types.Type, ft fieldTag) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// time.Time is only encoded as unix seconds
+	isTime := isNamed(deref(t), "time", "Time")
+	if isTime != ft.unix {
+		if isTime {
+			add("time.Time requires ssz tag 'uint64,unix'")
+		} else {
+			add("ssz tag option 'unix' requires time.Time or *time.Time type, got %v", t)
+		}
+	}
+	if isTime && ft.unix && ft.fieldType != "uint64" {
+		add("time.Time requires ssz tag 'uint64,unix', got '%s'", ft.fieldType)
+	}
+
+	problems = append(problems, c.kindProblems(t, t, ft.fieldType)...)
+
+	custom := isCustom(t)
+	under := t.Underlying()
+	_, isSlice := under.(*types.Slice)
+	_, isArray := under.(*types.Array)
+	_, isMap := under.(*types.Map)
+	carriesDims := hasMethod(t, "SSZMax") || hasMethod(t, "SSZSize") || (hasMethod(t, "BitAt") && hasMethod(t, "Len"))
+
+	if len(ft.size) > 0 && len(ft.max) > 0 && ft.max[0] > 0 && !ft.variableDim {
+		add("cannot use both ssz-size and ssz-max tags unless ssz-size contains '?'")
+	}
+	if len(ft.size) > 0 && !isSlice && !isArray && !isMap && !custom {
+		add("ssz-size tag can only be used with array or slice types, got %v", t)
+	}
+	if len(ft.max) > 0 && ft.max[0] > 0 && !isSlice && !isMap {
+		add("ssz-max tag can only be used with slice types, got %v", t)
+	}
+	if isSlice && !custom && !carriesDims && !ft.hasSize && !ft.hasMax && ft.fieldType != "progressive-list" && ft.fieldType != "bitlist" && ft.fieldType != "bytelist" {
+		add("slice types must have either ssz-size or ssz-max tag")
+	}
+	if p, ok := t.(*types.Pointer); ft.omitZero && (!ok || !isUint256(p.Elem())) {
+		add("ssz-omitzero tag can only be used with *uint256.Int, got %v", t)
+	}
+
+	isBytes := false
+	if s, ok := under.(*types.Slice); ok {
+		b, ok := s.Elem().Underlying().(*types.Basic)
+		isBytes = ok && b.Kind() == types.Uint8
+	}
+	switch ft.fieldType {
+	case "bitlist":
+		if !isBytes {
+			add("ssz tag 'bitlist' requires []byte type, got %v", t)
+		} else if !ft.hasMax {
+			add("bitlist requires ssz-max tag")
+		}
+	case "bytelist":
+		if !isBytes {
+			add("ssz tag 'bytelist' requires []byte type, got %v", t)
+		} else if len(ft.size) > 0 {
+			add("bytelist takes no ssz-size tag")
+		} else if !ft.hasMax {
+			add("bytelist requires ssz-max tag")
+		}
+	case "bitvector":
+		if !isBytes {
+			add("ssz tag 'bitvector' requires []byte type, got %v", t)
+		} else if len(ft.size) == 0 {
+			add("bitvector requires ssz-size tag")
+		}
+	case "inline":
+		if _, ok := under.(*types.Struct); !ok {
+			add("ssz tag 'inline' requires struct type, got %v", t)
+		} else if ft.hasSize || ft.hasMax {
+			add("inlined structs take no other ssz tags")
+		}
+	case "list":
+		if !isSlice {
+			add("ssz tag 'list' requires slice type, got %v", t)
+		}
+	case "progressive-list":
+		if !isSlice {
+			add("ssz tag 'progressive-list' requires slice type, got %v", t)
+		} else if len(ft.size) > 0 || (len(ft.max) > 0 && ft.max[0] > 0) {
+			add("progressive-list has no limit and takes no ssz-max or ssz-size tag")
+		}
+	case "vector":
+		if !isArray {
+			add("ssz tag 'vector' requires array type, got %v", t)
+		}
+	case "uint128", "uint256":
+		if !isUint256(deref(t)) {
+			add("ssz tag '%s' requires uint256.Int or *uint256.Int type, got %v", ft.fieldType, t)
+		}
+	case "union":
+		if !isNamed(deref(t), "github.com/gfx-labs/ssz/flexssz", "Union") && !isNamedInterface(t) {
+			add("ssz tag 'union' requires flexssz.Union or interface type, got %v", t)
+		}
+	case "kvlist":
+		if !isMap {
+			add("ssz tag 'kvlist' requires map type, got %v", t)
+		} else if len(ft.max) == 0 || ft.max[0] == 0 {
+			add("kvlist requires ssz-max tag")
+		}
+	case "uint8", "uint16", "uint32", "uint64", "bool", "string":
+		if !isTime && !isBasicKind(t, ft.fieldType) {
+			add("ssz tag '%s' requires Go type %s, got %v", ft.fieldType, ft.fieldType, t)
+		}
+	}
+
+	// The dimensions of ssz-size must match the arrays they describe
+	if (isSlice || isArray) && ft.fieldType != "bitvector" {
+		cur := t
+		for i, size := range ft.size {
+			if isCustom(cur) {
+				if i != len(ft.size)-1 {
+					add("ssz-size has %d dimensions but type only has %d", len(ft.size), i+1)
+				}
+				break
+			}
+			switch u := cur.Underlying().(type) {
+			case *types.Array:
+				if size >= 0 && u.Len() != int64(size) {
+					add("array length %d does not match ssz-size %d at dimension %d", u.Len(), size, i)
+				}
+				cur = u.Elem()
+				continue
+			case *types.Slice:
+				cur = u.Elem()
+				continue
+			}
+			add("ssz-size has %d dimensions but type only has %d", len(ft.size), i)
+			break
+		}
+	}
+	return problems
+}
+
+// kindProblems reports types SSZ cannot encode in t, a field type or part of one, and signed
+// integers without the tag of their width
+func (c *checker) kindProblems(field, t types.Type, fieldType string) []string {
+	if isCustom(t) || isUint256(t) || isNamed(t, "time", "Time") {
+		return nil
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Float32, types.Float64, types.UntypedFloat:
+			return []string{fmt.Sprintf("unsupported type %v: SSZ has no floating point numbers", field)}
+		case types.Complex64, types.Complex128:
+			return []string{fmt.Sprintf("unsupported type %v: SSZ has no complex numbers", field)}
+		case types.Int64, types.Int32:
+			want := "int64"
+			if u.Kind() == types.Int32 {
+				want = "int32"
+			}
+			if fieldType != want {
+				return []string{fmt.Sprintf("signed type %v requires ssz tag '%s'", field, want)}
+			}
+		case types.Int, types.Int8, types.Int16:
+			return []string{fmt.Sprintf("unsupported signed type %v, only int64 and int32 can be encoded", field)}
+		case types.Uint, types.Uintptr, types.UnsafePointer:
+			return []string{fmt.Sprintf("unsupported type %v for SSZ encoding", field)}
+		default:
+			if (fieldType == "int64" || fieldType == "int32") && u.Kind() != types.Int64 && u.Kind() != types.Int32 {
+				return []string{fmt.Sprintf("ssz tag '%s' requires Go type %s, got %v", fieldType, fieldType, field)}
+			}
+		}
+	case *types.Pointer:
+		return c.kindProblems(field, u.Elem(), fieldType)
+	case *types.Slice:
+		return c.kindProblems(field, u.Elem(), fieldType)
+	case *types.Array:
+		return c.kindProblems(field, u.Elem(), fieldType)
+	case *types.Map:
+		if field == t && fieldType != "kvlist" {
+			return []string{fmt.Sprintf("map type %v requires ssz tag 'kvlist'", field)}
+		}
+		return append(c.kindProblems(field, u.Key(), ""), c.kindProblems(field, u.Elem(), "")...)
+	case *types.Interface:
+		if !isNamedInterface(t) {
+			return []string{fmt.Sprintf("unsupported type %v for SSZ encoding", field)}
+		}
+	case *types.Chan, *types.Signature:
+		return []string{fmt.Sprintf("unsupported type %v for SSZ encoding", field)}
+	}
+	return nil
+}
+
+// isNamedInterface reports whether t is a defined interface type, which can have union members
+func isNamedInterface(t types.Type) bool {
+	n, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	_, ok = n.Underlying().(*types.Interface)
+	return ok
+}
+
+// deref returns what t points to, or t
+func deref(t types.Type) types.Type {
+	for {
+		p, ok := t.(*types.Pointer)
+		if !ok {
+			return t
+		}
+		t = p.Elem()
+	}
+}
+
+// isNamed reports whether t is the named type pkg.name
+func isNamed(t types.Type, pkg, name string) bool {
+	n, ok := types.Unalias(t).(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == pkg && n.Obj().Name() == name
+}
+
+func isUint256(t types.Type) bool {
+	return isNamed(t, "github.com/holiman/uint256", "Int")
+}
+
+// isCustom reports whether t encodes itself, like flexssz.isCustomType: it or a pointer to it
+// has a MarshalSSZ or UnmarshalSSZ method, and it is not uint256.Int
+func isCustom(t types.Type) bool {
+	t = deref(t)
+	if isUint256(t) {
+		return false
+	}
+	return hasMethod(t, "MarshalSSZ") || hasMethod(t, "UnmarshalSSZ")
+}
+
+// hasMethod reports whether t or a pointer to it has the method
+func hasMethod(t types.Type, name string) bool {
+	if _, ok := t.Underlying().(*types.Interface); ok {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// isBasicKind reports whether t is the Go type an ssz tag names, or a type defined from it
+func isBasicKind(t types.Type, fieldType string) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	switch fieldType {
+	case "uint8":
+		return b.Kind() == types.Uint8
+	case "uint16":
+		return b.Kind() == types.Uint16
+	case "uint32":
+		return b.Kind() == types.Uint32
+	case "uint64":
+		return b.Kind() == types.Uint64
+	case "bool":
+		return b.Kind() == types.Bool
+	case "string":
+		return b.Kind() == types.String
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package sszvet_test
+
+import (
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz/sszvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), sszvet.Analyzer, "a")
+}
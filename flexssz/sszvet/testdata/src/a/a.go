@@ -0,0 +1,80 @@
+package a
+
+import (
+	"time"
+
+	"github.com/holiman/uint256"
+)
+
+type Pubkey [48]byte
+
+func (p Pubkey) MarshalSSZ() ([]byte, error) { return p[:], nil }
+
+type Validators []uint64
+
+func (Validators) SSZMax() int { return 1024 }
+
+type Valid struct {
+	Slot        uint64
+	Delta       int64 `ssz:"int64"`
+	Root        [32]byte
+	Roots       [][32]byte `ssz-size:"?,32" ssz-max:"8"`
+	Matrix      [][]byte   `ssz-size:"4,32"`
+	Bits        []byte     `ssz:"bitlist" ssz-max:"2048"`
+	Extra       []byte     `ssz:"bytelist" ssz-max:"32"`
+	Sync        []byte     `ssz:"bitvector" ssz-size:"512"`
+	Pubkey      Pubkey     `ssz-size:"48"`
+	Validators  Validators
+	Progressive []uint64          `ssz:"progressive-list"`
+	Labels      map[string]uint64 `ssz:"kvlist" ssz-max:"4"`
+	Time        time.Time         `ssz:"uint64,unix"`
+	Balance     *uint256.Int      `ssz:"uint128" ssz-omitzero:"true"`
+	Inner       Inner
+	Skipped     float64 `ssz:"-"`
+}
+
+// Inner has no tags, but is checked since Valid refers to it
+type Inner struct {
+	Price float64 // want `field Inner.Price: unsupported type float64: SSZ has no floating point numbers`
+}
+
+// Untagged is not checked, since it has no ssz tags
+type Untagged struct {
+	Price float64
+}
+
+type Kinds struct {
+	Slot    uint64            `ssz:"uint64"`
+	Prices  []float32         `ssz-max:"4"` // want `field Kinds.Prices: unsupported type \[\]float32: SSZ has no floating point numbers`
+	Ch      chan int          // want `field Kinds.Ch: unsupported type chan int for SSZ encoding`
+	Any     any               // want `field Kinds.Any: unsupported type any for SSZ encoding`
+	Count   int               // want `field Kinds.Count: unsupported signed type int, only int64 and int32 can be encoded`
+	Delta   int64             // want `field Kinds.Delta: signed type int64 requires ssz tag 'int64'`
+	Offsets []int32           `ssz:"int64" ssz-max:"4"` // want `field Kinds.Offsets: signed type \[\]int32 requires ssz tag 'int32'`
+	Wide    uint64            `ssz:"int64"`             // want `field Kinds.Wide: ssz tag 'int64' requires Go type int64, got uint64`
+	Map     map[string]uint64 `ssz-max:"4"`             // want `field Kinds.Map: map type map\[string\]uint64 requires ssz tag 'kvlist'`
+	Time    time.Time         // want `field Kinds.Time: time.Time requires ssz tag 'uint64,unix'`
+	Payload Payload           // unions of registered members are only known at runtime
+}
+
+// Payload is a union, see flexssz.RegisterUnionMember
+type Payload interface{ isPayload() }
+
+type Tags struct {
+	Unlimited []uint64  // want `field Tags.Unlimited: slice types must have either ssz-size or ssz-max tag`
+	Both      []byte    `ssz-size:"32" ssz-max:"32"` // want `field Tags.Both: cannot use both ssz-size and ssz-max tags unless ssz-size contains '\?'`
+	MaxArray  [4]byte   `ssz-max:"4"`                // want `field Tags.MaxArray: ssz-max tag can only be used with slice types, got \[4\]byte`
+	SizeInt   uint64    `ssz-size:"8"`               // want `field Tags.SizeInt: ssz-size tag can only be used with array or slice types, got uint64`
+	Mismatch  [32]byte  `ssz-size:"48"`              // want `field Tags.Mismatch: array length 32 does not match ssz-size 48 at dimension 0`
+	Inner     [][4]byte `ssz-size:"2,8"`             // want `field Tags.Inner: array length 4 does not match ssz-size 8 at dimension 1`
+	TooDeep   []byte    `ssz-size:"2,8"`             // want `field Tags.TooDeep: ssz-size has 2 dimensions but type only has 1`
+	BadSize   []byte    `ssz-size:"x"`               // want `field Tags.BadSize: invalid ssz-size value`
+	Unknown   uint64    `ssz:"u64"`                  // want `field Tags.Unknown: unknown ssz type "u64"`
+	Option    uint64    `ssz:"uint64,seconds"`       // want `field Tags.Option: unknown ssz tag option "seconds"`
+	Bitlist   []byte    `ssz:"bitlist"`              // want `field Tags.Bitlist: bitlist requires ssz-max tag`
+	Bytelist  []uint16  `ssz:"bytelist" ssz-max:"8"` // want `field Tags.Bytelist: ssz tag 'bytelist' requires \[\]byte type, got \[\]uint16`
+	Omit      *uint64   `ssz-omitzero:"true"`        // want `field Tags.Omit: ssz-omitzero tag can only be used with \*uint256.Int, got \*uint64`
+	Wrong     uint32    `ssz:"uint64"`               // want `field Tags.Wrong: ssz tag 'uint64' requires Go type uint64, got uint32`
+	Flat      uint64    `ssz:"inline"`               // want `field Tags.Flat: ssz tag 'inline' requires struct type, got uint64`
+	hidden    Inner     `ssz:"inline"`               // want `field Tags.hidden: ssz tag 'inline' requires an exported or embedded field`
+}
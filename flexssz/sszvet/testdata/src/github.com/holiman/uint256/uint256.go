@@ -0,0 +1,3 @@
+package uint256
+
+type Int [4]uint64
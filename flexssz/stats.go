@@ -0,0 +1,158 @@
+package flexssz
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+var statsEnabled atomic.Bool
+
+// SetStats enables or disables usage counters on Marshal, Unmarshal and HashTreeRoot, read back
+// with Stats. Counters are kept per Go type, so operators can see which message types dominate
+// codec load. Stats are off by default since counting costs a map lookup per call.
+func SetStats(enabled bool) {
+	statsEnabled.Store(enabled)
+}
+
+// StatsEnabled reports whether usage counters are enabled
+func StatsEnabled() bool {
+	return statsEnabled.Load()
+}
+
+// TypeStats counts the codec calls for one Go type
+type TypeStats struct {
+	Encodes uint64 // Marshal calls
+	Decodes uint64 // Unmarshal calls
+	Hashes  uint64 // HashTreeRoot calls
+
+	EncodedBytes uint64 // bytes written by successful Marshal calls
+	DecodedBytes uint64 // bytes read by Unmarshal calls
+
+	// AllocatedBytes is the size of the slices and strings allocated by Unmarshal calls, as
+	// counted for DecodeOptions.MaxAllocatedBytes
+	AllocatedBytes uint64
+
+	EncodeErrors uint64
+	DecodeErrors uint64
+	HashErrors   uint64
+}
+
+// StatsSnapshot is a copy of the usage counters at one point in time
+type StatsSnapshot struct {
+	// Types holds the counters of each Go type, keyed by its name as in the ssz_type profile label
+	Types map[string]TypeStats
+
+	// Type info cache lookups for untagged types, see GetTypeInfo
+	TypeCacheHits   uint64
+	TypeCacheMisses uint64
+}
+
+// typeCounters is the live form of TypeStats
+type typeCounters struct {
+	encodes, decodes, hashes               atomic.Uint64
+	encodedBytes, decodedBytes             atomic.Uint64
+	allocatedBytes                         atomic.Uint64
+	encodeErrors, decodeErrors, hashErrors atomic.Uint64
+}
+
+var (
+	// typeStats maps type names to *typeCounters
+	typeStats       sync.Map
+	typeCacheHits   atomic.Uint64
+	typeCacheMisses atomic.Uint64
+)
+
+// Stats returns a snapshot of the usage counters. Counters only move while stats are enabled.
+func Stats() StatsSnapshot {
+	snapshot := StatsSnapshot{
+		Types:           make(map[string]TypeStats),
+		TypeCacheHits:   typeCacheHits.Load(),
+		TypeCacheMisses: typeCacheMisses.Load(),
+	}
+	typeStats.Range(func(key, value any) bool {
+		c := value.(*typeCounters)
+		snapshot.Types[key.(string)] = TypeStats{
+			Encodes:        c.encodes.Load(),
+			Decodes:        c.decodes.Load(),
+			Hashes:         c.hashes.Load(),
+			EncodedBytes:   c.encodedBytes.Load(),
+			DecodedBytes:   c.decodedBytes.Load(),
+			AllocatedBytes: c.allocatedBytes.Load(),
+			EncodeErrors:   c.encodeErrors.Load(),
+			DecodeErrors:   c.decodeErrors.Load(),
+			HashErrors:     c.hashErrors.Load(),
+		}
+		return true
+	})
+	return snapshot
+}
+
+// ResetStats sets all usage counters back to zero
+func ResetStats() {
+	typeStats.Range(func(key, _ any) bool {
+		typeStats.Delete(key)
+		return true
+	})
+	typeCacheHits.Store(0)
+	typeCacheMisses.Store(0)
+}
+
+// recordStats counts a call for phase on t that processed n bytes
+func recordStats(t reflect.Type, phase string, n int, err error) {
+	if !statsEnabled.Load() {
+		return
+	}
+	name := typeLabel(t)
+	value, ok := typeStats.Load(name)
+	if !ok {
+		value, _ = typeStats.LoadOrStore(name, new(typeCounters))
+	}
+	c := value.(*typeCounters)
+
+	switch phase {
+	case PhaseMarshal:
+		c.encodes.Add(1)
+		if err != nil {
+			c.encodeErrors.Add(1)
+		} else {
+			c.encodedBytes.Add(uint64(n))
+		}
+	case PhaseUnmarshal:
+		c.decodes.Add(1)
+		c.decodedBytes.Add(uint64(n))
+		if err != nil {
+			c.decodeErrors.Add(1)
+		}
+	case PhaseHashTreeRoot:
+		c.hashes.Add(1)
+		if err != nil {
+			c.hashErrors.Add(1)
+		}
+	}
+}
+
+// recordAllocated counts the n bytes allocated by an Unmarshal call on t
+func recordAllocated(t reflect.Type, n int) {
+	if !statsEnabled.Load() {
+		return
+	}
+	name := typeLabel(t)
+	value, ok := typeStats.Load(name)
+	if !ok {
+		value, _ = typeStats.LoadOrStore(name, new(typeCounters))
+	}
+	value.(*typeCounters).allocatedBytes.Add(uint64(n))
+}
+
+// recordTypeCache counts a type info cache lookup
+func recordTypeCache(hit bool) {
+	if !statsEnabled.Load() {
+		return
+	}
+	if hit {
+		typeCacheHits.Add(1)
+	} else {
+		typeCacheMisses.Add(1)
+	}
+}
@@ -0,0 +1,63 @@
+package flexssz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statsTestMessage struct {
+	A uint64
+	B []byte `ssz-max:"32"`
+}
+
+func TestStats(t *testing.T) {
+	v := &statsTestMessage{A: 1, B: []byte{1, 2, 3}}
+
+	assert.False(t, StatsEnabled())
+	ResetStats()
+	_, err := Marshal(v)
+	require.NoError(t, err)
+	assert.Empty(t, Stats().Types, "nothing is counted while stats are disabled")
+
+	SetStats(true)
+	defer SetStats(false)
+	defer ResetStats()
+	assert.True(t, StatsEnabled())
+
+	encoded, err := Marshal(v)
+	require.NoError(t, err)
+	_, err = Marshal(&statsTestMessage{B: make([]byte, 33)})
+	require.Error(t, err)
+
+	var decoded statsTestMessage
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	require.Error(t, Unmarshal(encoded[:3], &decoded))
+
+	_, err = HashTreeRoot(v)
+	require.NoError(t, err)
+	_, err = HashTreeRootList([]uint64{1, 2}, 4)
+	require.NoError(t, err)
+
+	stats := Stats()
+	assert.Equal(t, TypeStats{
+		Encodes:      2,
+		Decodes:      2,
+		Hashes:       1,
+		EncodedBytes: uint64(len(encoded)),
+		DecodedBytes: uint64(len(encoded) + 3),
+		// The 3 bytes of B, the truncated input fails before allocating
+		AllocatedBytes: 3,
+		EncodeErrors:   1,
+		DecodeErrors:   1,
+	}, stats.Types["flexssz.statsTestMessage"])
+	assert.Equal(t, uint64(1), stats.Types["[]uint64"].Hashes)
+	assert.NotZero(t, stats.TypeCacheHits)
+
+	ResetStats()
+	stats = Stats()
+	assert.Empty(t, stats.Types)
+	assert.Zero(t, stats.TypeCacheHits)
+	assert.Zero(t, stats.TypeCacheMisses)
+}
@@ -0,0 +1,82 @@
+package flexssz
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// WriteSSZTo and ReadSSZFrom move values between memory and a stream holding nothing but their
+// encoding, like a file or a network connection, mirroring the methods genssz generates. The
+// encoding is built in a pooled buffer and written at once, and reading allocates only the
+// bytes of the value.
+
+// streamBuffers holds the buffers WriteSSZTo encodes into. Buffers larger than
+// maxPooledStreamBuffer are left to the garbage collector instead of being kept around.
+var streamBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+const maxPooledStreamBuffer = 1 << 20
+
+// WriteSSZTo encodes v and writes it to w with a single Write, returning the number of bytes
+// written
+func WriteSSZTo(w io.Writer, v any) (int64, error) {
+	buf := streamBuffers.Get().(*[]byte)
+	defer func() {
+		if cap(*buf) <= maxPooledStreamBuffer {
+			streamBuffers.Put(buf)
+		}
+	}()
+	data, err := MarshalAppend((*buf)[:0], v)
+	*buf = data
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ReadSSZFrom reads the encoding of a value of the type v points to from r and decodes it into
+// v, returning the number of bytes read. Fixed-size types read exactly their size, so r can hold
+// more data after them; variable-size types read r to its end. A positive limit is the largest
+// number of bytes that may be read, checked before reading fixed-size types and while reading
+// the others.
+func ReadSSZFrom(r io.Reader, v any, limit int64) (int64, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return 0, fmt.Errorf("v must be a non-nil pointer, got %T", v)
+	}
+	typeInfo, err := GetTypeInfo(rv.Elem().Type(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("error getting type info: %w", err)
+	}
+
+	var data []byte
+	if !typeInfo.IsVariable && typeInfo.FixedSize > 0 {
+		if limit > 0 && int64(typeInfo.FixedSize) > limit {
+			return 0, &DecodeError{Err: fmt.Errorf("%w: %v is %d bytes, more than the limit of %d", ErrLimitExceeded, rv.Elem().Type(), typeInfo.FixedSize, limit)}
+		}
+		data = make([]byte, typeInfo.FixedSize)
+		n, err := io.ReadFull(r, data)
+		if err != nil {
+			return int64(n), fmt.Errorf("reading %d bytes of %v: %w", len(data), rv.Elem().Type(), err)
+		}
+	} else {
+		var buf bytes.Buffer
+		src := r
+		if limit > 0 {
+			// One byte past the limit tells a stream of exactly limit bytes from a longer one
+			src = io.LimitReader(r, limit+1)
+		}
+		n, err := buf.ReadFrom(src)
+		if err != nil {
+			return n, err
+		}
+		if limit > 0 && n > limit {
+			return n, &DecodeError{Err: fmt.Errorf("%w: input exceeds %d bytes", ErrLimitExceeded, limit)}
+		}
+		data = buf.Bytes()
+	}
+	return int64(len(data)), Unmarshal(data, v)
+}
@@ -0,0 +1,77 @@
+package flexssz
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamTestFixed struct {
+	Slot uint64
+	Root [32]byte
+}
+
+type streamTestVariable struct {
+	Slot     uint64
+	Balances []uint64 `ssz-max:"16"`
+}
+
+func TestWriteReadSSZ(t *testing.T) {
+	fixed := &streamTestFixed{Slot: 7, Root: [32]byte{1, 2}}
+	variable := &streamTestVariable{Slot: 9, Balances: []uint64{1, 2, 3}}
+
+	var buf bytes.Buffer
+	n, err := WriteSSZTo(&buf, fixed)
+	require.NoError(t, err)
+	assert.EqualValues(t, 40, n)
+	want, err := Marshal(fixed)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf.Bytes())
+
+	// Fixed-size values leave the rest of the stream unread
+	_, err = WriteSSZTo(&buf, variable)
+	require.NoError(t, err)
+	var gotFixed streamTestFixed
+	n, err = ReadSSZFrom(&buf, &gotFixed, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 40, n)
+	assert.Equal(t, *fixed, gotFixed)
+
+	var gotVariable streamTestVariable
+	n, err = ReadSSZFrom(&buf, &gotVariable, 36)
+	require.NoError(t, err)
+	assert.EqualValues(t, 36, n)
+	assert.Equal(t, *variable, gotVariable)
+	assert.Zero(t, buf.Len())
+}
+
+func TestReadSSZFrom_Limits(t *testing.T) {
+	data, err := Marshal(&streamTestVariable{Balances: []uint64{1, 2, 3}})
+	require.NoError(t, err)
+
+	var v streamTestVariable
+	_, err = ReadSSZFrom(bytes.NewReader(data), &v, int64(len(data)-1))
+	assert.True(t, errors.Is(err, ErrLimitExceeded), "%v", err)
+
+	var fixed streamTestFixed
+	_, err = ReadSSZFrom(bytes.NewReader(make([]byte, 40)), &fixed, 39)
+	assert.True(t, errors.Is(err, ErrLimitExceeded), "%v", err)
+	n, err := ReadSSZFrom(bytes.NewReader(make([]byte, 20)), &fixed, 0)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.EqualValues(t, 20, n)
+
+	_, err = ReadSSZFrom(bytes.NewReader(data), v, 0)
+	assert.Error(t, err)
+}
+
+func TestWriteSSZTo_Error(t *testing.T) {
+	// Encoding errors are returned before anything is written
+	var buf bytes.Buffer
+	_, err := WriteSSZTo(&buf, &streamTestVariable{Balances: make([]uint64, 17)})
+	assert.Error(t, err)
+	assert.Zero(t, buf.Len())
+}
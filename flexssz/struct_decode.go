@@ -1,6 +1,7 @@
 package flexssz
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	
@@ -9,6 +10,25 @@ import (
 
 // Unmarshal decodes SSZ bytes into a value based on its type and struct tags
 func Unmarshal(data []byte, v any) error {
+	return UnmarshalWithOptions(data, v, DecodeOptions{})
+}
+
+// UnmarshalStrict is Unmarshal that only accepts the canonical encoding of a value, see DecodeOptions.Strict
+func UnmarshalStrict(data []byte, v any) error {
+	return UnmarshalWithOptions(data, v, DecodeOptions{Strict: true})
+}
+
+// UnmarshalContext is Unmarshal with the profile labels of ctx, see SetProfileLabels
+func UnmarshalContext(ctx context.Context, data []byte, v any) error {
+	return unmarshalWithOptions(ctx, data, v, DecodeOptions{})
+}
+
+// UnmarshalWithOptions decodes SSZ bytes into a value based on its type and struct tags
+func UnmarshalWithOptions(data []byte, v any, opts DecodeOptions) error {
+	return unmarshalWithOptions(context.Background(), data, v, opts)
+}
+
+func unmarshalWithOptions(ctx context.Context, data []byte, v any, opts DecodeOptions) error {
 	rv := reflect.ValueOf(v)
 
 	// Must be a pointer
@@ -20,8 +40,12 @@ func Unmarshal(data []byte, v any) error {
 		return fmt.Errorf("v must not be nil")
 	}
 
+	if opts.MaxTotalSize > 0 && len(data) > opts.MaxTotalSize {
+		return &DecodeError{Err: fmt.Errorf("%w: input of %d bytes exceeds %d", ErrLimitExceeded, len(data), opts.MaxTotalSize)}
+	}
+
 	elem := rv.Elem()
-	decoder := NewDecoder(data)
+	decoder := NewDecoderWithOptions(data, opts)
 	
 	// Get type info for the target type
 	typeInfo, err := GetTypeInfo(elem.Type(), nil)
@@ -35,12 +59,25 @@ func Unmarshal(data []byte, v any) error {
 		Name: "root",
 	}
 	
-	return decodeValue(decoder, elem, fieldInfo)
+	err = withProfileLabels(ctx, elem.Type(), PhaseUnmarshal, func() error {
+		if err := decodeValue(decoder, elem, fieldInfo); err != nil {
+			return asDecodeError(err)
+		}
+		return asDecodeError(decoder.checkConsumed())
+	})
+	recordStats(elem.Type(), PhaseUnmarshal, len(data), err)
+	recordAllocated(elem.Type(), decoder.Allocated())
+	return err
 }
 
 
 // decodeStructFromDecoder decodes a struct using the provided decoder
 func decodeStructFromDecoder(dec *Decoder, v reflect.Value) error {
+	if err := dec.enter(); err != nil {
+		return err
+	}
+	defer dec.leave()
+
 	rt := v.Type()
 
 	// Get type info
@@ -49,32 +86,43 @@ func decodeStructFromDecoder(dec *Decoder, v reflect.Value) error {
 		return fmt.Errorf("error getting type info: %w", err)
 	}
 
+	// Fixed-size containers are read field by field at their offsets. Truncated input is decoded
+	// below, so the error names the missing field.
+	if !typeInfo.IsVariable && len(dec.Remaining()) >= typeInfo.FixedSize {
+		return decodeFixedContainer(dec, v, typeInfo)
+	}
+
 	// Build container elements
 	elements := make([]ContainerElement, 0, len(typeInfo.Fields))
 
 	for _, field := range typeInfo.Fields {
 		// Capture field info in closure
 		fieldCopy := field
-		fieldIndex := field.Index
 		fieldName := field.Name
 
 		if field.Type.IsVariable {
 			// Variable field
 			elements = append(elements, Variable(func(d *Decoder) error {
-				fieldValue := v.Field(fieldIndex)
+				offset := d.Offset()
+				fieldValue := fieldCopy.value(v)
+				restore := d.enterField(fieldName, fieldCopy.Type.Tag)
 				err := decodeVariableField(d, fieldValue, &fieldCopy)
+				restore()
 				if err != nil {
-					return fmt.Errorf("error decoding variable field %s: %w", fieldName, err)
+					return wrapDecodeError(err, fieldName, offset)
 				}
 				return nil
 			}))
 		} else {
 			// Fixed field
 			elements = append(elements, Fixed(func(d *Decoder) error {
-				fieldValue := v.Field(fieldIndex)
+				offset := d.Offset()
+				fieldValue := fieldCopy.value(v)
+				restore := d.enterField(fieldName, fieldCopy.Type.Tag)
 				err := decodeFixedField(d, fieldValue, &fieldCopy)
+				restore()
 				if err != nil {
-					return fmt.Errorf("error decoding field %s: %w", fieldName, err)
+					return wrapDecodeError(err, fieldName, offset)
 				}
 				return nil
 			}))
@@ -87,6 +135,15 @@ func decodeStructFromDecoder(dec *Decoder, v reflect.Value) error {
 
 // decodeValue decodes a value based on its type
 func decodeValue(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
+	if w := fieldInfo.Type.Wrapped; w != nil && v.Kind() == reflect.Struct {
+		inner := *w
+		inner.Name = fieldInfo.Name
+		return decodeValue(d, v.Field(w.Index), &inner)
+	}
+	if fieldInfo.Type.Custom {
+		return decodeCustom(d, v, fieldInfo)
+	}
+
 	// Special handling for container types when called directly (not as a field)
 	if fieldInfo.Type.Type == ssz.TypeContainer && fieldInfo.Name == "root" {
 		return decodeStructFromDecoder(d, v)
@@ -2,9 +2,11 @@ package flexssz
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 
 	"github.com/gfx-labs/ssz"
+	"github.com/holiman/uint256"
 )
 
 // decodeFixedField decodes a fixed-size field
@@ -18,6 +20,13 @@ func decodeFixedField(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 		return decodeFixedField(d, v.Elem(), fieldInfo)
 	}
 
+	if w := fieldInfo.Type.Wrapped; w != nil && v.Kind() == reflect.Struct {
+		return decodeFixedField(d, v.Field(w.Index), w)
+	}
+	if fieldInfo.Type.Custom {
+		return decodeCustom(d, v, fieldInfo)
+	}
+
 	// Switch on SSZ type
 	switch fieldInfo.Type.Type {
 	case ssz.TypeUint8:
@@ -29,9 +38,9 @@ func decodeFixedField(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 	case ssz.TypeUint64:
 		return decodeUint64(d, v)
 	case ssz.TypeUint128:
-		return decodeUint128(d, v)
+		return decodeUint128(d, v, fieldInfo)
 	case ssz.TypeUint256:
-		return decodeUint256(d, v)
+		return decodeUint256(d, v, fieldInfo)
 	case ssz.TypeBoolean:
 		return decodeBoolean(d, v)
 	case ssz.TypeBitVector:
@@ -88,6 +97,10 @@ func decodeUint32(d *Decoder, v reflect.Value) error {
 	case reflect.Uint32, reflect.Uint64, reflect.Uint:
 		v.SetUint(uint64(val))
 		return nil
+	case reflect.Int32:
+		// Two's complement, see signed.go
+		v.SetInt(int64(int32(val)))
+		return nil
 	default:
 		return fmt.Errorf("cannot decode uint32 into %v", v.Kind())
 	}
@@ -100,63 +113,75 @@ func decodeUint64(d *Decoder, v reflect.Value) error {
 		return err
 	}
 
+	if v.Type() == timeType {
+		t, err := unixToTime(val)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
 	switch v.Kind() {
 	case reflect.Uint64, reflect.Uint:
 		v.SetUint(val)
 		return nil
+	case reflect.Int64:
+		v.SetInt(int64(val))
+		return nil
 	default:
 		return fmt.Errorf("cannot decode uint64 into %v", v.Kind())
 	}
 }
 
 // decodeUint128 decodes a uint128 value
-func decodeUint128(d *Decoder, v reflect.Value) error {
-	val, err := d.ReadUint128()
-	if err != nil {
+func decodeUint128(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
+	var val uint256.Int
+	if err := d.ScanUint128LE(&val); err != nil {
 		return err
 	}
-
-	// Check if it's a uint256.Int type
-	if v.Type() == uint256Type {
-		v.Set(reflect.ValueOf(*val))
-		return nil
-	}
-
-	// Check if it's a pointer to uint256.Int
-	if v.Kind() == reflect.Ptr && v.Type().Elem() == uint256Type {
-		if v.IsNil() {
-			v.Set(reflect.New(uint256Type))
-		}
-		v.Elem().Set(reflect.ValueOf(*val))
-		return nil
+	if !setUint256Value(v, &val, fieldInfo.Type.Tag) {
+		return fmt.Errorf("cannot decode uint128 into %v (expected uint256.Int or *uint256.Int)", v.Type())
 	}
-
-	return fmt.Errorf("cannot decode uint128 into %v (expected uint256.Int or *uint256.Int)", v.Type())
+	return nil
 }
 
 // decodeUint256 decodes a uint256 value
-func decodeUint256(d *Decoder, v reflect.Value) error {
-	val, err := d.ReadUint256()
-	if err != nil {
+func decodeUint256(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
+	var val uint256.Int
+	if err := d.ScanUint256LE(&val); err != nil {
 		return err
 	}
+	if !setUint256Value(v, &val, fieldInfo.Type.Tag) {
+		return fmt.Errorf("cannot decode uint256 into %v (expected uint256.Int or *uint256.Int)", v.Type())
+	}
+	return nil
+}
 
+// setUint256Value stores a decoded uint256 into a uint256.Int or *uint256.Int.
+// Pointers tagged with ssz-omitzero are left nil when the value is zero.
+// It returns false if v is neither type.
+func setUint256Value(v reflect.Value, val *uint256.Int, tag *sszTag) bool {
 	// Check if it's a uint256.Int type
 	if v.Type() == uint256Type {
 		v.Set(reflect.ValueOf(*val))
-		return nil
+		return true
 	}
 
 	// Check if it's a pointer to uint256.Int
 	if v.Kind() == reflect.Ptr && v.Type().Elem() == uint256Type {
+		if tag != nil && tag.OmitZero && val.IsZero() {
+			v.Set(reflect.Zero(v.Type()))
+			return true
+		}
 		if v.IsNil() {
 			v.Set(reflect.New(uint256Type))
 		}
 		v.Elem().Set(reflect.ValueOf(*val))
-		return nil
+		return true
 	}
 
-	return fmt.Errorf("cannot decode uint256 into %v (expected uint256.Int or *uint256.Int)", v.Type())
+	return false
 }
 
 // decodeBoolean decodes a boolean value
@@ -182,7 +207,7 @@ func decodeBitVector(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 	}
 
 	bytesToRead := (fieldInfo.Type.BitLength + 7) / 8
-	bytes, err := d.ReadN(bytesToRead)
+	bytes, err := d.readBytes(bytesToRead)
 	if err != nil {
 		return err
 	}
@@ -192,6 +217,9 @@ func decodeBitVector(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 	if err != nil {
 		return fmt.Errorf("error decoding bitvector: %w", err)
 	}
+	if d.opts.ZeroCopy {
+		decoded = bytes
+	}
 
 	switch v.Kind() {
 	case reflect.Slice:
@@ -222,7 +250,12 @@ func decodeVector(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 		if v.Len() != length {
 			return fmt.Errorf("array length %d does not match vector length %d", v.Len(), length)
 		}
-		// Special case for byte arrays
+		// Byte arrays, nested or not, are read straight into their memory. Truncated input
+		// is decoded element by element below, so the error names the missing element.
+		if bs, ok := byteArrayBytes(v); ok && isByteVector(fieldInfo.Type) && len(d.Remaining()) >= len(bs) {
+			_, err := d.Read(bs)
+			return err
+		}
 		if v.Type().Elem().Kind() == reflect.Uint8 && elemType.Type == ssz.TypeUint8 {
 			bytes, err := d.ReadN(length)
 			if err != nil {
@@ -233,25 +266,34 @@ func decodeVector(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 			}
 			return nil
 		}
+		if ok, err := decodeBools(d, v); ok {
+			return err
+		}
 		// Decode each element
 		for i := 0; i < length; i++ {
 			elemFieldInfo := &FieldInfo{
 				Type: elemType,
 				Name: fmt.Sprintf("%s[%d]", fieldInfo.Name, i),
 			}
+			offset := d.Offset()
 			if err := decodeFixedField(d, v.Index(i), elemFieldInfo); err != nil {
-				return err
+				return wrapDecodeError(err, indexSegment(i), offset)
 			}
 		}
 		return nil
 
 	case reflect.Slice:
-		// Create slice with proper length
-		v.Set(reflect.MakeSlice(v.Type(), length, length))
+		// Check the data is there before allocating for it
+		if size := fieldInfo.Type.FixedSize; size > 0 && len(d.Remaining()) < size {
+			return fmt.Errorf("vector of %d bytes: %w", size, io.ErrUnexpectedEOF)
+		}
 
 		// Special case for byte slices
 		if v.Type().Elem().Kind() == reflect.Uint8 && elemType.Type == ssz.TypeUint8 {
-			bytes, err := d.ReadN(length)
+			if err := d.allocateBytes(length); err != nil {
+				return err
+			}
+			bytes, err := d.readBytes(length)
 			if err != nil {
 				return err
 			}
@@ -259,14 +301,27 @@ func decodeVector(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 			return nil
 		}
 
+		if err := d.allocate(length * int(v.Type().Elem().Size())); err != nil {
+			return err
+		}
+		// Create slice with proper length
+		v.Set(reflect.MakeSlice(v.Type(), length, length))
+		if ok, err := decodeBools(d, v); ok {
+			return err
+		}
+		if ok, err := decodeByteVectors(d, v, elemType); ok {
+			return err
+		}
+
 		// Decode each element
 		for i := 0; i < length; i++ {
 			elemFieldInfo := &FieldInfo{
 				Type: elemType,
 				Name: fmt.Sprintf("%s[%d]", fieldInfo.Name, i),
 			}
+			offset := d.Offset()
 			if err := decodeFixedField(d, v.Index(i), elemFieldInfo); err != nil {
-				return err
+				return wrapDecodeError(err, indexSegment(i), offset)
 			}
 		}
 		return nil
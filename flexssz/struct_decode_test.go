@@ -1,6 +1,8 @@
 package flexssz
 
 import (
+	"io"
+	"reflect"
 	"testing"
 
 	"github.com/holiman/uint256"
@@ -257,6 +259,68 @@ func TestUnmarshal_Uint256Pointers(t *testing.T) {
 		require.NotNil(t, decoded.Uint128Ptr)
 		assert.Equal(t, original.Uint128Ptr.String(), decoded.Uint128Ptr.String())
 	})
+
+	t.Run("nil pointer without omitzero", func(t *testing.T) {
+		type WithPointer struct {
+			Value *uint256.Int `ssz:"uint256"`
+		}
+
+		_, err := Marshal(WithPointer{})
+		assert.ErrorContains(t, err, "nil pointer")
+	})
+
+	t.Run("omitzero round trips nil and zero", func(t *testing.T) {
+		type WithOmitZero struct {
+			Value    *uint256.Int `ssz:"uint256" ssz-omitzero:"true"`
+			Value128 *uint256.Int `ssz:"uint128" ssz-omitzero:"true"`
+			Plain    *uint256.Int `ssz:"uint256"`
+		}
+
+		original := WithOmitZero{Plain: uint256.NewInt(0)}
+		encoded, err := Marshal(original)
+		require.NoError(t, err)
+		assert.Equal(t, make([]byte, 32+16+32), encoded)
+
+		// Nil hashes the same as an explicit zero
+		root, err := HashTreeRoot(original)
+		require.NoError(t, err)
+		zeroRoot, err := HashTreeRoot(WithOmitZero{Value: uint256.NewInt(0), Value128: uint256.NewInt(0), Plain: uint256.NewInt(0)})
+		require.NoError(t, err)
+		assert.Equal(t, zeroRoot, root)
+
+		decoded := WithOmitZero{Value: uint256.NewInt(1), Value128: uint256.NewInt(1)}
+		err = Unmarshal(encoded, &decoded)
+		require.NoError(t, err)
+		assert.Nil(t, decoded.Value)
+		assert.Nil(t, decoded.Value128)
+		require.NotNil(t, decoded.Plain)
+		assert.True(t, decoded.Plain.IsZero())
+	})
+
+	t.Run("omitzero keeps non-zero values", func(t *testing.T) {
+		type WithOmitZero struct {
+			Value *uint256.Int `ssz:"uint256" ssz-omitzero:"true"`
+		}
+
+		original := WithOmitZero{Value: uint256.NewInt(42)}
+		encoded, err := Marshal(original)
+		require.NoError(t, err)
+
+		var decoded WithOmitZero
+		err = Unmarshal(encoded, &decoded)
+		require.NoError(t, err)
+		require.NotNil(t, decoded.Value)
+		assert.Equal(t, uint64(42), decoded.Value.Uint64())
+	})
+
+	t.Run("omitzero on unsupported type", func(t *testing.T) {
+		type BadOmitZero struct {
+			Value uint64 `ssz-omitzero:"true"`
+		}
+
+		_, err := Marshal(BadOmitZero{})
+		assert.ErrorContains(t, err, "ssz-omitzero tag can only be used with *uint256.Int")
+	})
 }
 
 func TestUnmarshal_Errors(t *testing.T) {
@@ -409,4 +473,173 @@ func TestUnmarshal_MixedFixedVariable(t *testing.T) {
 
 	// Compare
 	assert.Equal(t, original, decoded)
-}
\ No newline at end of file
+}
+func TestUnmarshal_DecodeErrorPath(t *testing.T) {
+	t.Run("truncated nested vector", func(t *testing.T) {
+		type Header struct {
+			Slot  uint64
+			Roots [2][4]byte
+		}
+		type Outer struct {
+			A   uint64
+			Hdr Header
+		}
+
+		// 8 (A) + 8 (Slot) + 4 (Roots[0]) + 2 bytes of Roots[1]
+		var decoded Outer
+		err := Unmarshal(make([]byte, 22), &decoded)
+		require.Error(t, err)
+
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, "Hdr.Roots[1]", decodeErr.Path)
+		assert.Equal(t, 20, decodeErr.Offset)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("variable element in nested list", func(t *testing.T) {
+		type LooseItem struct {
+			X     uint64
+			Extra []byte `ssz-max:"64"`
+		}
+		type LooseBody struct {
+			Items []*LooseItem `ssz-max:"4"`
+		}
+		type LooseOuter struct {
+			A    uint64
+			Body *LooseBody
+		}
+		type Item struct {
+			X     uint64
+			Extra []byte `ssz-max:"8"`
+		}
+		type Body struct {
+			Items []*Item `ssz-max:"4"`
+		}
+		type Outer struct {
+			A    uint64
+			Body *Body
+		}
+
+		// Encode with a looser limit, so the second item is too long for Outer
+		encoded, err := Marshal(&LooseOuter{
+			A: 1,
+			Body: &LooseBody{Items: []*LooseItem{
+				{X: 2, Extra: []byte{1, 2}},
+				{X: 3, Extra: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+			}},
+		})
+		require.NoError(t, err)
+
+		var decoded Outer
+		err = Unmarshal(encoded, &decoded)
+		require.Error(t, err)
+
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, "Body.Items[1].Extra", decodeErr.Path)
+		// The failing Extra is the last 9 bytes of the input
+		assert.Equal(t, len(encoded)-9, decodeErr.Offset)
+		assert.ErrorContains(t, err, "exceeds limit")
+		assert.ErrorContains(t, err, "Body.Items[1].Extra")
+	})
+
+	t.Run("root value", func(t *testing.T) {
+		var decoded []uint64
+		err := Unmarshal(make([]byte, 12), &decoded)
+		require.Error(t, err)
+
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, "", decodeErr.Path)
+	})
+}
+
+type offsetTestItem struct {
+	ID   uint16
+	Data []byte `ssz-max:"8"`
+}
+
+type offsetTestValue struct {
+	Slot  uint64
+	Items []offsetTestItem `ssz-max:"4"`
+	Extra []byte           `ssz-max:"8"`
+}
+
+// TestUnmarshal_MalformedOffsets checks that offsets close to 2^31 and 2^32, which wrap around
+// int arithmetic on 32-bit platforms, and offsets out of order are rejected by everything that
+// reads them instead of panicking or decoding the wrong bytes
+func TestUnmarshal_MalformedOffsets(t *testing.T) {
+	value := offsetTestValue{Slot: 1, Items: []offsetTestItem{{ID: 1, Data: []byte{9}}, {ID: 2}}, Extra: []byte{7}}
+	valid, err := Marshal(&value)
+	require.NoError(t, err)
+	// Slot, then the offsets of Items and Extra, then Items with the offsets of its two elements
+	const itemsAt, extraAt, firstItemAt, secondItemAt = 8, 12, 16, 20
+	require.Equal(t, uint32(16), order.Uint32(valid[itemsAt:]))
+
+	tests := []struct {
+		name   string
+		pos    int
+		offset uint32
+	}{
+		{"container offset 2^32-1", itemsAt, 0xffffffff},
+		{"container offset 2^31", itemsAt, 0x80000000},
+		{"container offset into the fixed part", itemsAt, 4},
+		{"last container offset 2^32-16", extraAt, 0xfffffff0},
+		{"last container offset 2^31", extraAt, 0x80000000},
+		{"first list offset 2^32-4", firstItemAt, 0xfffffffc},
+		{"first list offset 2^31", firstItemAt, 0x80000000},
+		{"first list offset 2", firstItemAt, 2},
+		{"first list offset not a multiple of 4", firstItemAt, 6},
+		{"list offset 2^31", secondItemAt, 0x80000000},
+		{"list offset 2^32-1", secondItemAt, 0xffffffff},
+		{"list offset before the previous one", secondItemAt, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte(nil), valid...)
+			order.PutUint32(data[tt.pos:], tt.offset)
+
+			var decoded offsetTestValue
+			assert.Error(t, Unmarshal(data, &decoded))
+			assert.Error(t, UnmarshalStrict(data, &decoded))
+			_, err := Inspect(data, &decoded)
+			assert.Error(t, err)
+
+			if tt.pos == itemsAt || tt.pos == extraAt {
+				field := map[int]string{itemsAt: "Items", extraAt: "Extra"}[tt.pos]
+				_, err := ExtractField(data, reflect.TypeOf(decoded), field)
+				assert.Error(t, err)
+				return
+			}
+			items := data[firstItemAt:order.Uint32(valid[extraAt:])]
+			it, err := ListIterator[offsetTestValue](data, "Items")
+			if err == nil {
+				_, err = it.At(1)
+			}
+			assert.Error(t, err)
+			err = VariableList(4, func(i int) DecodeFunc {
+				return func(d *Decoder) error {
+					_, err := d.ReadAll()
+					return err
+				}
+			})(NewDecoder(items))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestUnmarshal_ShortFirstListOffset checks that a first list offset below 4, which claims no
+// elements while pointing into the offsets, is rejected instead of indexing an empty list
+func TestUnmarshal_ShortFirstListOffset(t *testing.T) {
+	type Inner struct {
+		Data []byte `ssz-max:"8"`
+	}
+	type Outer struct {
+		L []Inner `ssz-max:"4"`
+	}
+	data := []byte{4, 0, 0, 0, 2, 0, 0, 0, 0, 0}
+	var decoded Outer
+	assert.ErrorContains(t, Unmarshal(data, &decoded), "invalid first offset 2")
+	assert.ErrorContains(t, UnmarshalStrict(data, &decoded), "invalid first offset 2")
+}
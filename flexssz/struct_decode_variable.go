@@ -18,14 +18,25 @@ func decodeVariableField(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) erro
 		return decodeVariableField(d, v.Elem(), fieldInfo)
 	}
 
+	if w := fieldInfo.Type.Wrapped; w != nil && v.Kind() == reflect.Struct {
+		return decodeVariableField(d, v.Field(w.Index), w)
+	}
+	if fieldInfo.Type.Custom {
+		return decodeCustom(d, v, fieldInfo)
+	}
+
 	// Switch on SSZ type
 	switch fieldInfo.Type.Type {
 	case ssz.TypeList:
 		return decodeList(d, v, fieldInfo)
+	case ssz.TypeVector:
+		return decodeVariableVector(d, v, fieldInfo)
 	case ssz.TypeBitList:
 		return decodeBitList(d, v, fieldInfo)
 	case ssz.TypeContainer:
 		return decodeVariableContainer(d, v, fieldInfo)
+	case ssz.TypeUnion:
+		return decodeUnion(d, v, fieldInfo)
 	default:
 		return fmt.Errorf("unsupported SSZ type for variable field: %v", fieldInfo.Type.Type)
 	}
@@ -38,6 +49,15 @@ func decodeList(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 		return decodeString(d, v, fieldInfo)
 	}
 
+	// Maps are decoded from the list of their entries
+	if kv := fieldInfo.Type.kvlist; kv != nil && v.Kind() == reflect.Map {
+		entries := reflect.New(kv.entries).Elem()
+		if err := decodeSlice(d, entries, &FieldInfo{Name: fieldInfo.Name, Type: kv.info}); err != nil {
+			return err
+		}
+		return kv.setMap(v, entries, d.opts.Strict)
+	}
+
 	if v.Kind() != reflect.Slice {
 		return fmt.Errorf("cannot decode list into %v", v.Kind())
 	}
@@ -51,14 +71,34 @@ func decodeList(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 	return decodeSlice(d, v, fieldInfo)
 }
 
+// decodeVariableVector decodes a vector of variable-size elements, such as a slice tagged
+// ssz-size:"4,?" ssz-max:"?,32" for 4 lists of up to 32 bytes. It is serialized like a list of
+// its elements, with the length taken from ssz-size instead of from the offsets.
+func decodeVariableVector(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("cannot decode vector of variable-size elements into %v", v.Type())
+	}
+	if err := decodeSlice(d, v, fieldInfo); err != nil {
+		return err
+	}
+	if v.Len() != fieldInfo.Type.Length {
+		return fmt.Errorf("vector has %d elements, expected %d", v.Len(), fieldInfo.Type.Length)
+	}
+	return nil
+}
+
 // decodeString decodes a string (which is a list of bytes in SSZ)
 func decodeString(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 	if v.Kind() != reflect.String {
 		return fmt.Errorf("cannot decode string into %v", v.Kind())
 	}
 
+	// The ssz-max of the string is checked by the read, MaxListElements here
+	if err := d.checkListLength(len(d.Remaining()), nil); err != nil {
+		return err
+	}
 	// Read all remaining bytes and convert to string
-	buf, err := d.ReadAll()
+	buf, err := d.ReadAllMax(d.listLimit(fieldInfo.Type.Tag))
 	if err != nil {
 		return err
 	}
@@ -72,16 +112,13 @@ func decodeByteSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 		return fmt.Errorf("cannot decode byte slice into %v", v.Type())
 	}
 
-	// Read all remaining bytes
-	bytes, err := d.ReadAll()
-	if err != nil {
+	// The ssz-max of the slice is checked by the read, MaxListElements here
+	if err := d.checkListLength(len(d.Remaining()), nil); err != nil {
 		return err
 	}
-
-	// Check limit if specified
-	tag := fieldInfo.Type.Tag
-	if tag != nil && tag.MaxList > 0 && len(bytes) > tag.MaxList {
-		return fmt.Errorf("slice length %d exceeds limit %d", len(bytes), tag.MaxList)
+	bytes, err := d.readAllMax(d.listLimit(fieldInfo.Type.Tag))
+	if err != nil {
+		return err
 	}
 
 	v.SetBytes(bytes)
@@ -94,6 +131,11 @@ func decodeSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 		return fmt.Errorf("cannot decode slice into %v", v.Kind())
 	}
 
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
 	elemType := v.Type().Elem()
 	
 	// For lists defined with ssz-size:"?,32", we need to get the element type info
@@ -135,6 +177,7 @@ func decodeVariableElementSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInf
 	}
 
 	// Read first offset to determine number of elements
+	size := len(remaining)
 	firstOffset, err := d.ReadUint32()
 	if err != nil {
 		return err
@@ -142,11 +185,28 @@ func decodeVariableElementSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInf
 
 	// Handle empty slice (no offsets means no elements)
 	if firstOffset == 0 {
+		if d.opts.Strict {
+			return fmt.Errorf("%w: list with first offset 0 has %d bytes of data", ErrNonCanonical, size)
+		}
 		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
 		return nil
 	}
 
+	// The offsets are part of the data, so the first one bounds the number of elements
+	// before anything is allocated for them, and must end on an offset boundary
+	if firstOffset < 4 || firstOffset%4 != 0 {
+		return fmt.Errorf("invalid first offset %d: not a multiple of 4", firstOffset)
+	}
+	if uint64(firstOffset) > uint64(size) {
+		return fmt.Errorf("first offset %d is past the end of the list (%d)", firstOffset, size)
+	}
 	numElements := int(firstOffset) / 4
+	if err := d.checkListLength(numElements, fieldInfo.Type.Tag); err != nil {
+		return err
+	}
+	if err := d.allocate(numElements * (4 + int(v.Type().Elem().Size()))); err != nil {
+		return err
+	}
 	offsets := make([]uint32, numElements)
 	offsets[0] = firstOffset
 
@@ -158,49 +218,63 @@ func decodeVariableElementSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInf
 		offsets[i] = offset
 	}
 
-	// Check limit
-	tag := fieldInfo.Type.Tag
-	if tag != nil && tag.MaxList > 0 && numElements > tag.MaxList {
-		return fmt.Errorf("slice length %d exceeds limit %d", numElements, tag.MaxList)
+	if d.opts.Strict {
+		if err := checkListOffsets(offsets, size); err != nil {
+			return err
+		}
 	}
 
 	// Create slice
 	slice := reflect.MakeSlice(v.Type(), numElements, numElements)
 
-	// Decode each element
+	// Decode each element from the bytes between its offset and the next one, or the end of the
+	// list for the last one. Offsets are relative to the list, whose offsets end at d.cur.
+	base := d.cur - int(firstOffset)
 	for i := 0; i < numElements; i++ {
-		var size int
+		// Bounds are checked relative to the list before base is added, see offsetWithin
+		elemStart, startOK := offsetWithin(offsets[i], size)
+		elemEnd, endOK := size, true
 		if i < numElements-1 {
-			size = int(offsets[i+1] - offsets[i])
-		} else {
-			// For the last element, we need to calculate from the total remaining data
-			// The total data starts after all offsets (4 * numElements bytes)
-			totalDataSize := len(d.xs) - 4*numElements
-			previousDataSize := int(offsets[i]) - 4*numElements
-			size = totalDataSize - previousDataSize
+			elemEnd, endOK = offsetWithin(offsets[i+1], size)
 		}
-
-		elemData, err := d.ReadN(size)
-		if err != nil {
-			return err
+		if !startOK || !endOK || elemStart < int(firstOffset) || elemStart > elemEnd {
+			return wrapDecodeError(fmt.Errorf("invalid offset %d for list of %d bytes", offsets[i], size), indexSegment(i), d.Offset())
 		}
 
-		elemDecoder := NewDecoder(elemData)
+		elemDecoder := d.sub(base+elemStart, base+elemEnd)
 		// Create a temporary FieldInfo for the element
 		elemFieldInfo := &FieldInfo{
 			Type: elemTypeInfo,
 			Name: fmt.Sprintf("%s[%d]", fieldInfo.Name, i),
 		}
 		err = decodeValue(elemDecoder, slice.Index(i), elemFieldInfo)
+		if err == nil {
+			err = elemDecoder.checkConsumed()
+		}
 		if err != nil {
-			return err
+			return wrapDecodeError(err, indexSegment(i), elemDecoder.base)
 		}
+		d.cur = base + elemEnd
 	}
 
 	v.Set(slice)
 	return nil
 }
 
+// checkListOffsets checks the offsets of a list of variable-size elements increase up to the end
+// of the list. The first offset, where the offsets end, is checked before they are read.
+func checkListOffsets(offsets []uint32, size int) error {
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i] < offsets[i-1] {
+			return fmt.Errorf("%w: list offset %d (%d) is before offset %d (%d)", ErrNonCanonical, i, offsets[i], i-1, offsets[i-1])
+		}
+	}
+	if last := offsets[len(offsets)-1]; uint64(last) > uint64(size) {
+		return fmt.Errorf("%w: list offset %d is past the end of the list (%d)", ErrNonCanonical, last, size)
+	}
+	return nil
+}
+
 // decodeFixedElementSlice decodes a slice with fixed-size elements
 func decodeFixedElementSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInfo, elemTypeInfo *TypeInfo) error {
 	elemSize := elemTypeInfo.FixedSize
@@ -215,14 +289,29 @@ func decodeFixedElementSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInfo,
 		return fmt.Errorf("invalid data size for slice: %d bytes cannot be divided by element size %d", remaining, elemSize)
 	}
 
-	// Check limit
-	tag := fieldInfo.Type.Tag
-	if tag != nil && tag.MaxList > 0 && numElements > tag.MaxList {
-		return fmt.Errorf("slice length %d exceeds limit %d", numElements, tag.MaxList)
+	if err := d.checkListLength(numElements, fieldInfo.Type.Tag); err != nil {
+		return err
+	}
+	if err := d.allocate(numElements * int(v.Type().Elem().Size())); err != nil {
+		return err
 	}
 
 	// Create slice
 	slice := reflect.MakeSlice(v.Type(), numElements, numElements)
+	if ok, err := decodeBools(d, slice); ok {
+		if err != nil {
+			return err
+		}
+		v.Set(slice)
+		return nil
+	}
+	if ok, err := decodeByteVectors(d, slice, elemTypeInfo); ok {
+		if err != nil {
+			return err
+		}
+		v.Set(slice)
+		return nil
+	}
 
 	// Decode each element
 	for i := 0; i < numElements; i++ {
@@ -231,9 +320,10 @@ func decodeFixedElementSlice(d *Decoder, v reflect.Value, fieldInfo *FieldInfo,
 			Type: elemTypeInfo,
 			Name: fmt.Sprintf("%s[%d]", fieldInfo.Name, i),
 		}
+		offset := d.Offset()
 		err := decodeFixedField(d, slice.Index(i), elemFieldInfo)
 		if err != nil {
-			return err
+			return wrapDecodeError(err, indexSegment(i), offset)
 		}
 	}
 
@@ -247,28 +337,19 @@ func decodeBitList(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
 		return fmt.Errorf("cannot decode bitlist into %v (expected []byte)", v.Type())
 	}
 
-	// Read all remaining bytes
-	bytes, err := d.ReadAll()
+	// Bitlists longer than the byte length of maxBits are rejected before they are read
+	maxBits := d.listLimit(fieldInfo.Type.Tag)
+	bytes, err := d.readAllMax(bitlistMaxBytes(maxBits))
 	if err != nil {
-		return err
-	}
-
-	tag := fieldInfo.Type.Tag
-	maxBits := 0
-	if tag != nil {
-		maxBits = tag.MaxList
+		return fmt.Errorf("error decoding bitlist: %w", err)
 	}
 
-	// Decode bitlist (remove delimiter bit)
-	decoded, numBits, err := DecodeBitList(bytes, maxBits)
-	if err != nil {
+	// Bitlists are kept in their serialized form, including the delimiter bit,
+	// so the length survives a round trip even with trailing zero bits
+	if err := ValidateBitlist(bytes, uint64(maxBits)); err != nil {
 		return fmt.Errorf("error decoding bitlist: %w", err)
 	}
-
-	// For now, we store the full bytes including padding
-	// The actual number of bits can be tracked separately if needed
-	_ = numBits
-	v.SetBytes(decoded)
+	v.SetBytes(bytes)
 	return nil
 }
 
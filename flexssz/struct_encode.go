@@ -2,28 +2,56 @@ package flexssz
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"reflect"
+	"time"
 
+	"github.com/gfx-labs/ssz"
 	"github.com/holiman/uint256"
 )
 
-var (
-	// Precalculated types to avoid reflection overhead
-	uint256Type = reflect.TypeOf(uint256.Int{})
-)
-
-// Marshal encodes a value to SSZ bytes based on its type and struct tags
+// Marshal encodes a value to SSZ bytes based on its type and struct tags.
+// Slices and arrays at the root are encoded as a list or vector without a limit; use MarshalList
+// or MarshalVector to check the length against one.
 func Marshal(v any) ([]byte, error) {
-	buf := new(bytes.Buffer)
-	builder := NewBuilder(buf)
+	rv, err := derefRoot(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting type info: %w", err)
+	}
+	return marshalRoot(context.Background(), rv, typeInfo)
+}
 
-	err := encodeValueToBuilder(builder, v)
+// MarshalContext is Marshal with the profile labels of ctx, see SetProfileLabels
+func MarshalContext(ctx context.Context, v any) ([]byte, error) {
+	rv, err := derefRoot(reflect.ValueOf(v))
 	if err != nil {
 		return nil, err
 	}
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting type info: %w", err)
+	}
+	return marshalRoot(ctx, rv, typeInfo)
+}
 
-	err = builder.Finish()
+// marshalRoot encodes rv as the root of the output. Every public encode function funnels
+// through here with the TypeInfo of the root, so they write the same bytes for the same value.
+func marshalRoot(ctx context.Context, rv reflect.Value, typeInfo *TypeInfo) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	builder := NewBuilder(buf)
+
+	err := withProfileLabels(ctx, rv.Type(), PhaseMarshal, func() error {
+		return encodeRoot(builder, rv, typeInfo)
+	})
+	if err == nil {
+		err = builder.Finish()
+	}
+	recordStats(rv.Type(), PhaseMarshal, buf.Len(), err)
 	if err != nil {
 		return nil, err
 	}
@@ -31,34 +59,100 @@ func Marshal(v any) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// encodeRoot encodes a value that is not inside a container, so no offset is written for it
+func encodeRoot(b *Builder, rv reflect.Value, typeInfo *TypeInfo) error {
+	rv, typeInfo = unwrapTransparent(rv, typeInfo)
+	tag := typeInfo.Tag
+	if tag == nil {
+		tag = &sszTag{}
+	}
+	if typeInfo.Custom {
+		data, err := marshalCustom(rv, tag)
+		if err != nil {
+			return err
+		}
+		b.EncodeFixed(data)
+		return nil
+	}
 
-// encodeStructToBuilder encodes a struct using the provided builder
-func encodeStructToBuilder(b *Builder, v any) error {
-	rv := reflect.ValueOf(v)
-
-	// Handle pointers
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return fmt.Errorf("cannot encode nil pointer")
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return encodeFixedField(b, rv, tag)
 		}
-		rv = rv.Elem()
+		return encodeContainer(b, rv, typeInfo)
+	case reflect.Slice, reflect.Array:
+		if rv.Type() != uint256Type {
+			return encodeSequence(b, rv, typeInfo, tag)
+		}
+	case reflect.String:
+		b.EncodeFixed([]byte(rv.String()))
+		return nil
 	}
+	return encodeFixedField(b, rv, tag)
+}
 
-	if rv.Kind() != reflect.Struct {
-		return fmt.Errorf("expected struct, got %v", rv.Kind())
+// encodeSequence encodes the elements of a list or vector root
+func encodeSequence(b *Builder, rv reflect.Value, typeInfo *TypeInfo, tag *sszTag) error {
+	if tag.MaxList > 0 && rv.Len() > tag.MaxList {
+		return fmt.Errorf("list length %d exceeds limit %d", rv.Len(), tag.MaxList)
+	}
+	if len(tag.Size) > 0 && tag.Size[0] >= 0 && rv.Len() != tag.Size[0] {
+		return fmt.Errorf("vector length %d does not match size %d", rv.Len(), tag.Size[0])
 	}
 
-	rt := rv.Type()
+	elemType := typeInfo.ElementType
+	if elemType.Type == ssz.TypeUint8 && rv.Type().Elem().Kind() == reflect.Uint8 {
+		// Byte lists and vectors are written as is
+		bs := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(bs), rv)
+		b.EncodeFixed(bs)
+		return nil
+	}
+	if encodeBools(b, rv) {
+		return nil
+	}
+	if size, ok := byteVectorSize(rv.Type(), elemType); ok {
+		return encodeByteVectors(b, rv, size)
+	}
 
-	// Get type info
-	typeInfo, err := GetTypeInfo(rt, nil)
-	if err != nil {
-		return fmt.Errorf("error getting type info: %w", err)
+	elemTag := elemType.Tag
+	if elemTag == nil {
+		elemTag = &sszTag{}
+	}
+	for i := 0; i < rv.Len(); i++ {
+		var err error
+		if elemType.IsVariable {
+			// Writes the element offset, followed by its data once all offsets are written
+			err = encodeVariableField(b, rv.Index(i), elemTag)
+		} else {
+			err = encodeFixedField(b, rv.Index(i), elemTag)
+		}
+		if err != nil {
+			return elementError(rv.Index(i), i, err)
+		}
+	}
+	return nil
+}
+
+// encodeContainer encodes the fields of a struct in declaration order
+func encodeContainer(b *Builder, rv reflect.Value, typeInfo *TypeInfo) error {
+	if rv.Type() == unionType {
+		return fmt.Errorf("encoding unions is not supported")
+	}
+	if !typeInfo.IsVariable {
+		// Fixed-size containers are written into a slice of their size, see putFixedContainer
+		buf := make([]byte, typeInfo.FixedSize)
+		if ok, err := putFixedContainer(buf, rv, typeInfo); ok || err != nil {
+			if ok {
+				b.EncodeFixed(buf)
+			}
+			return err
+		}
 	}
 
-	// Encode fields in declaration order
 	for _, field := range typeInfo.Fields {
-		fieldValue := rv.Field(field.Index)
+		fieldValue := field.value(rv)
 
 		if field.Type.IsVariable {
 			// For variable-size fields, this will write the offset
@@ -78,19 +172,36 @@ func encodeStructToBuilder(b *Builder, v any) error {
 	return nil
 }
 
-
+// encodeStruct encodes a nested struct as a container
+func encodeStruct(b *Builder, rv reflect.Value) error {
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return fmt.Errorf("error getting type info: %w", err)
+	}
+	return encodeContainer(b, rv, typeInfo)
+}
 
 // encodeFixedField encodes a fixed-size field
 func encodeFixedField(b *Builder, v reflect.Value, tag *sszTag) error {
+	tag = withTypeDimensions(v.Type(), tag)
+	if isCustomType(v.Type()) {
+		data, err := marshalCustom(v, tag)
+		if err != nil {
+			return err
+		}
+		b.EncodeFixed(data)
+		return nil
+	}
+
 	switch v.Kind() {
 	case reflect.Uint8:
 		b.EncodeUint8(uint8(v.Uint()))
 	case reflect.Uint16:
 		b.EncodeUint16(uint16(v.Uint()))
-	case reflect.Uint32:
-		b.EncodeUint32(uint32(v.Uint()))
-	case reflect.Uint64:
-		b.EncodeUint64(v.Uint())
+	case reflect.Uint32, reflect.Int32:
+		b.EncodeUint32(uint32(integerBits(v)))
+	case reflect.Uint64, reflect.Int64:
+		b.EncodeUint64(integerBits(v))
 	case reflect.Bool:
 		b.EncodeBool(v.Bool())
 	case reflect.Slice:
@@ -118,17 +229,17 @@ func encodeFixedField(b *Builder, v reflect.Value, tag *sszTag) error {
 					}
 					b.EncodeFixed(v.Bytes())
 				}
-			} else {
+			} else if size, ok := byteVectorTagSize(v.Type(), tag.elementTag()); ok {
+				// Slices of byte vectors, such as pubkeys, are written in one go
+				return encodeByteVectors(b, v, size)
+			} else if !encodeBools(b, v) {
 				// Other slices - encode each element
+				// For multi-dimensional arrays, pass down the remaining sizes
+				elemTag := tag.elementTag()
 				for i := 0; i < v.Len(); i++ {
-					elemTag := &sszTag{}
-					// For multi-dimensional arrays, pass down the remaining sizes
-					if len(tag.Size) > 1 {
-						elemTag.Size = tag.Size[1:]
-					}
 					err := encodeFixedField(b, v.Index(i), elemTag)
 					if err != nil {
-						return err
+						return elementError(v.Index(i), i, err)
 					}
 				}
 			}
@@ -157,6 +268,9 @@ func encodeFixedField(b *Builder, v reflect.Value, tag *sszTag) error {
 					b.EncodeUint256(&val)
 				}
 			}
+		} else if bs, ok := byteArrayBytes(v); ok {
+			// Byte arrays, nested or not, are written from their memory
+			b.EncodeFixed(bs)
 		} else if v.Type().Elem().Kind() == reflect.Uint8 {
 			// Byte array
 			bytes := make([]byte, v.Len())
@@ -164,18 +278,27 @@ func encodeFixedField(b *Builder, v reflect.Value, tag *sszTag) error {
 				bytes[i] = uint8(v.Index(i).Uint())
 			}
 			b.EncodeFixed(bytes)
-		} else {
+		} else if !encodeBools(b, v) {
 			// Other arrays - encode each element
 			for i := 0; i < v.Len(); i++ {
 				err := encodeFixedField(b, v.Index(i), tag)
 				if err != nil {
-					return err
+					return elementError(v.Index(i), i, err)
 				}
 			}
 		}
 	case reflect.Ptr:
 		// Handle pointer types
 		if v.IsNil() {
+			// Tagged uint256 pointers use nil for zero
+			if tag != nil && tag.OmitZero && v.Type().Elem() == uint256Type {
+				if tag.FieldType == "uint128" {
+					b.EncodeUint128(new(uint256.Int))
+				} else {
+					b.EncodeUint256(new(uint256.Int))
+				}
+				return nil
+			}
 			return fmt.Errorf("cannot encode nil pointer")
 		}
 		// Check if it's a pointer to uint256.Int
@@ -192,8 +315,19 @@ func encodeFixedField(b *Builder, v reflect.Value, tag *sszTag) error {
 			return encodeFixedField(b, v.Elem(), tag)
 		}
 	case reflect.Struct:
+		if v.Type() == timeType {
+			sec, err := timeToUnix(v.Interface().(time.Time))
+			if err != nil {
+				return err
+			}
+			b.EncodeUint64(sec)
+			return nil
+		}
+		if inner, innerTag, ok := transparentField(v); ok {
+			return encodeFixedField(b, inner, innerTag)
+		}
 		// Nested struct
-		return encodeStructToBuilder(b, v.Interface())
+		return encodeStruct(b, v)
 	default:
 		return fmt.Errorf("unsupported type for fixed field: %v", v.Kind())
 	}
@@ -203,6 +337,16 @@ func encodeFixedField(b *Builder, v reflect.Value, tag *sszTag) error {
 
 // encodeVariableField encodes a variable-size field
 func encodeVariableField(b *Builder, v reflect.Value, tag *sszTag) error {
+	tag = withTypeDimensions(v.Type(), tag)
+	if isCustomType(v.Type()) {
+		data, err := marshalCustom(v, tag)
+		if err != nil {
+			return err
+		}
+		b.EncodeBytes(data)
+		return nil
+	}
+
 	switch v.Kind() {
 	case reflect.String:
 		b.EncodeString(v.String())
@@ -215,12 +359,16 @@ func encodeVariableField(b *Builder, v reflect.Value, tag *sszTag) error {
 		if v.Type().Elem().Kind() == reflect.Uint8 {
 			// Byte slice - check if it's a bitlist
 			if tag.FieldType == "bitlist" {
-				// Encode as bitlist with delimiter bit
-				encoded, err := EncodeBitList(v.Bytes(), tag.MaxList)
-				if err != nil {
+				// Bitlists are kept in their serialized form, including the delimiter bit
+				bits := v.Bytes()
+				if len(bits) == 0 {
+					// An unset bitlist is the empty bitlist
+					bits = emptyBitList
+				}
+				if err := ValidateBitlist(bits, uint64(tag.MaxList)); err != nil {
 					return fmt.Errorf("error encoding bitlist: %w", err)
 				}
-				b.EncodeBytes(encoded)
+				b.EncodeBytes(bits)
 			} else {
 				// Regular byte slice
 				b.EncodeBytes(v.Bytes())
@@ -228,21 +376,28 @@ func encodeVariableField(b *Builder, v reflect.Value, tag *sszTag) error {
 		} else {
 			// Other slices - enter variable context
 			dyn := b.EnterDynamic()
+			if encodeBools(dyn, v) {
+				b = dyn.ExitDynamic()
+				return nil
+			}
 			
 			// Get element type info to determine if elements are fixed-size
 			elemType := v.Type().Elem()
-			elemTag := &sszTag{}
 			
-			// For lists with ssz-size:"?,32", get the element size from the tag
-			if tag != nil && len(tag.Size) > 1 {
-				elemTag.Size = tag.Size[1:]
-			}
+			// For lists with ssz-size:"?,32" or ssz-max:"1024,32", get the element size and limit from the tag
+			elemTag := tag.elementTag()
 			
 			elemTypeInfo, err := GetTypeInfo(elemType, elemTag)
 			if err != nil {
 				return fmt.Errorf("error getting element type info: %w", err)
 			}
 			
+			if size, ok := byteVectorSize(v.Type(), elemTypeInfo); ok && !elemTypeInfo.IsVariable {
+				err := encodeByteVectors(dyn, v, size)
+				b = dyn.ExitDynamic()
+				return err
+			}
+
 			// Encode elements based on whether they're fixed or variable
 			for i := 0; i < v.Len(); i++ {
 				var err error
@@ -252,19 +407,29 @@ func encodeVariableField(b *Builder, v reflect.Value, tag *sszTag) error {
 					err = encodeFixedField(dyn, v.Index(i), elemTag)
 				}
 				if err != nil {
-					return err
+					return elementError(v.Index(i), i, err)
 				}
 			}
 			b = dyn.ExitDynamic()
 		}
 	case reflect.Struct:
+		if inner, innerTag, ok := transparentField(v); ok {
+			return encodeVariableField(b, inner, innerTag)
+		}
 		// Variable-size struct - enter variable context
 		dyn := b.EnterDynamic()
-		err := encodeStructToBuilder(dyn, v.Interface())
+		err := encodeStruct(dyn, v)
 		if err != nil {
 			return err
 		}
 		b = dyn.ExitDynamic()
+	case reflect.Map:
+		// Maps are encoded as the list of their sorted entries
+		entries, entriesTag, err := kvListValue(v, tag)
+		if err != nil {
+			return err
+		}
+		return encodeVariableField(b, entries, entriesTag)
 	case reflect.Ptr:
 		// Handle pointer types
 		if v.IsNil() {
@@ -272,6 +437,13 @@ func encodeVariableField(b *Builder, v reflect.Value, tag *sszTag) error {
 		}
 		// For pointers to variable types, encode the pointed value
 		return encodeVariableField(b, v.Elem(), tag)
+	case reflect.Interface:
+		// Interfaces are unions of their registered members
+		data, err := appendUnion(nil, v)
+		if err != nil {
+			return err
+		}
+		b.EncodeBytes(data)
 	default:
 		return fmt.Errorf("unsupported type for variable field: %v", v.Kind())
 	}
@@ -279,6 +451,15 @@ func encodeVariableField(b *Builder, v reflect.Value, tag *sszTag) error {
 	return nil
 }
 
+// elementError wraps the error of encoding element i of a list or vector, reporting nil pointer
+// elements as ErrNilElement
+func elementError(elem reflect.Value, i int, err error) error {
+	if elem.Kind() == reflect.Ptr && elem.IsNil() {
+		return fmt.Errorf("element %d: %w", i, ErrNilElement)
+	}
+	return fmt.Errorf("error encoding element %d: %w", i, err)
+}
+
 // encodeValue encodes a value based on its type
 func encodeValue(b *Builder, v reflect.Value, tag *sszTag) error {
 	// Check if value is variable-size
@@ -287,26 +468,3 @@ func encodeValue(b *Builder, v reflect.Value, tag *sszTag) error {
 	}
 	return encodeFixedField(b, v, tag)
 }
-
-// encodeValueToBuilder encodes any value using the provided builder
-func encodeValueToBuilder(b *Builder, v any) error {
-	rv := reflect.ValueOf(v)
-
-	// Handle pointers by dereferencing
-	if rv.Kind() == reflect.Ptr {
-		if rv.IsNil() {
-			return fmt.Errorf("cannot encode nil pointer")
-		}
-		rv = rv.Elem()
-	}
-
-	// For structs, use the existing struct encoding logic
-	if rv.Kind() == reflect.Struct {
-		return encodeStructToBuilder(b, rv.Interface())
-	}
-
-	// For other types, use the general encoding logic with an empty tag
-	tag := &sszTag{}
-	return encodeValue(b, rv, tag)
-}
-
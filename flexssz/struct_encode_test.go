@@ -495,3 +495,76 @@ func TestAllSlicesMustHaveLimit(t *testing.T) {
 	})
 }
 
+
+func TestMarshal_EntryPointsAgree(t *testing.T) {
+	type Inner struct {
+		A uint16
+		B []byte `ssz-max:"8"`
+	}
+	type Outer struct {
+		X     uint64
+		Inner Inner
+		Ptr   *Inner
+		Items []Inner `ssz-max:"4"`
+	}
+	inner := Inner{A: 7, B: []byte{1, 2}}
+	outer := Outer{X: 1, Inner: inner, Ptr: &inner, Items: []Inner{inner, {A: 9, B: []byte{}}}}
+
+	t.Run("value and pointer", func(t *testing.T) {
+		byValue, err := Marshal(outer)
+		require.NoError(t, err)
+		byPointer, err := Marshal(&outer)
+		require.NoError(t, err)
+		assert.Equal(t, byValue, byPointer)
+
+		var decoded Outer
+		require.NoError(t, UnmarshalStrict(byValue, &decoded))
+		assert.Equal(t, outer, decoded)
+	})
+
+	t.Run("nested struct encodes like the root", func(t *testing.T) {
+		root, err := Marshal(&inner)
+		require.NoError(t, err)
+		list, err := MarshalList([]Inner{inner}, 4)
+		require.NoError(t, err)
+		// A list of one variable element is its offset followed by the element
+		assert.Equal(t, root, list[4:])
+	})
+
+	t.Run("slice roots", func(t *testing.T) {
+		values := []uint64{1, 2, 3}
+		marshaled, err := Marshal(values)
+		require.NoError(t, err)
+		list, err := MarshalList(values, 8)
+		require.NoError(t, err)
+		vector, err := MarshalVector(values, 3)
+		require.NoError(t, err)
+		assert.Equal(t, list, marshaled)
+		assert.Equal(t, vector, marshaled)
+
+		var decoded []uint64
+		require.NoError(t, UnmarshalStrict(marshaled, &decoded))
+		assert.Equal(t, values, decoded)
+
+		items := []Inner{inner, {A: 9}}
+		marshaled, err = Marshal(items)
+		require.NoError(t, err)
+		list, err = MarshalList(items, 4)
+		require.NoError(t, err)
+		assert.Equal(t, list, marshaled)
+	})
+
+	t.Run("array roots", func(t *testing.T) {
+		values := [3]uint32{1, 2, 3}
+		marshaled, err := Marshal(&values)
+		require.NoError(t, err)
+		vector, err := MarshalVector(values, 3)
+		require.NoError(t, err)
+		assert.Equal(t, vector, marshaled)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := Marshal(nil)
+		assert.ErrorContains(t, err, "nil value")
+	})
+}
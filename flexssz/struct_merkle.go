@@ -1,31 +1,37 @@
 package flexssz
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/gfx-labs/ssz"
 	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
 	"github.com/holiman/uint256"
 )
 
 const BYTES_PER_CHUNK = 32
 
-// chunkedToSingle converts a slice of [32]byte to a slice of bytes
-func chunkedToSingle(xs [][32]byte) []byte {
-	if len(xs) == 0 {
-		return nil
-	}
-	result := make([]byte, len(xs)*32)
-	for i, chunk := range xs {
-		copy(result[i*32:], chunk[:])
-	}
-	return result
-}
-
 // HashTreeRoot calculates the merkle root of a value based on its type and struct tags
 func HashTreeRoot(v any) ([32]byte, error) {
+	return HashTreeRootWithCache(v, nil)
+}
+
+// HashTreeRootContext is HashTreeRoot with the profile labels of ctx, see SetProfileLabels
+func HashTreeRootContext(ctx context.Context, v any) ([32]byte, error) {
+	return hashTreeRootWithCache(ctx, v, nil)
+}
+
+// HashTreeRootWithCache is HashTreeRoot that reuses the roots of nested containers stored in
+// cache, see HashCache. A nil cache hashes everything, like HashTreeRoot.
+func HashTreeRootWithCache(v any, cache *HashCache) ([32]byte, error) {
+	return hashTreeRootWithCache(context.Background(), v, cache)
+}
+
+func hashTreeRootWithCache(ctx context.Context, v any, cache *HashCache) ([32]byte, error) {
 	rv := reflect.ValueOf(v)
 
 	// Handle pointer by dereferencing
@@ -43,18 +49,48 @@ func HashTreeRoot(v any) ([32]byte, error) {
 	}
 
 	// Calculate hash tree root for any type
-	return hashTreeRoot(rv, typeInfo)
+	var root [32]byte
+	err = withProfileLabels(ctx, rv.Type(), PhaseHashTreeRoot, func() (err error) {
+		if typeInfo.Custom {
+			root, err = hashTreeRoot(rv, typeInfo, cache)
+		} else {
+			// Skip the Hasher of v, which may be calling us to compute its root
+			root, err = hashTreeRootValue(rv, typeInfo, cache)
+		}
+		return err
+	})
+	recordStats(rv.Type(), PhaseHashTreeRoot, 0, err)
+	return root, err
 }
 
 // hashTreeRoot implements the recursive hash_tree_root function from the SSZ spec
-func hashTreeRoot(v reflect.Value, typeInfo *TypeInfo) (out [32]byte, err error) {
+func hashTreeRoot(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) ([32]byte, error) {
+	if h, ok := customHasher(v); ok {
+		return h.HashTreeRoot()
+	}
+	if cache != nil && typeInfo.Type == ssz.TypeContainer && typeInfo.Wrapped == nil {
+		return cache.hashTreeRoot(v, typeInfo)
+	}
+	return hashTreeRootValue(v, typeInfo, cache)
+}
+
+// hashTreeRootValue computes the hash tree root of v from its type, ignoring its Hasher
+func hashTreeRootValue(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) (out [32]byte, err error) {
 	// Handle pointer types
 	if v.Kind() == reflect.Ptr && v.Type().Elem() != uint256Type {
 		if v.IsNil() {
 			// For nil pointers, return zero hash
 			return [32]byte{}, nil
 		}
-		return hashTreeRoot(v.Elem(), typeInfo)
+		return hashTreeRoot(v.Elem(), typeInfo, cache)
+	}
+	if typeInfo.Wrapped != nil {
+		v, typeInfo = unwrapTransparent(v, typeInfo)
+		return hashTreeRoot(v, typeInfo, cache)
+	}
+
+	if typeInfo.Custom {
+		return [32]byte{}, fmt.Errorf("%v must implement flexssz.Hasher to be hashed", v.Type())
 	}
 
 	switch typeInfo.Type {
@@ -67,7 +103,7 @@ func hashTreeRoot(v reflect.Value, typeInfo *TypeInfo) (out [32]byte, err error)
 		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
 			return [32]byte{}, fmt.Errorf("invalid type for bitvector: %v", v.Type())
 		}
-		chunks := packBytes(v.Bytes())
+		chunks := PackBytes(v.Bytes())
 		err := merkle_tree.MerklizeChunks(chunks, out[:])
 		if err != nil {
 			return [32]byte{}, err
@@ -79,16 +115,30 @@ func hashTreeRoot(v reflect.Value, typeInfo *TypeInfo) (out [32]byte, err error)
 		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
 			return [32]byte{}, fmt.Errorf("invalid type for bitlist: %v", v.Type())
 		}
-		return merkle_tree.BitlistRootWithLimit(v.Bytes(), uint64(typeInfo.BitLength))
+		bits := v.Bytes()
+		if len(bits) == 0 {
+			bits = emptyBitList
+		}
+		return merkle_tree.BitlistRootWithLimit(bits, uint64(typeInfo.BitLength))
 
 	case ssz.TypeVector:
-		return hashTreeRootVector(v, typeInfo)
+		return hashTreeRootVector(v, typeInfo, cache)
 
 	case ssz.TypeList:
-		return hashTreeRootList(v, typeInfo)
+		if kv := typeInfo.kvlist; kv != nil {
+			// Maps are hashed as the list of their sorted entries
+			return hashTreeRootList(kv.sorted(v), kv.info, cache)
+		}
+		if typeInfo.Progressive {
+			return hashTreeRootProgressiveList(v, typeInfo, cache)
+		}
+		return hashTreeRootList(v, typeInfo, cache)
 
 	case ssz.TypeContainer:
-		return hashTreeRootContainer(v, typeInfo)
+		return hashTreeRootContainer(v, typeInfo, cache)
+
+	case ssz.TypeUnion:
+		return hashTreeRootUnion(v, cache)
 
 	default:
 		return [32]byte{}, fmt.Errorf("unsupported SSZ type for merkle root: %v", typeInfo.Type)
@@ -105,23 +155,26 @@ func hashTreeRootBasicValue(v reflect.Value, typeInfo *TypeInfo) ([32]byte, erro
 	case ssz.TypeUint16:
 		binary.LittleEndian.PutUint16(chunk[:2], uint16(v.Uint()))
 	case ssz.TypeUint32:
-		binary.LittleEndian.PutUint32(chunk[:4], uint32(v.Uint()))
+		binary.LittleEndian.PutUint32(chunk[:4], uint32(integerBits(v)))
 	case ssz.TypeUint64:
-		binary.LittleEndian.PutUint64(chunk[:8], v.Uint())
+		if v.Type() == timeType {
+			sec, err := timeToUnix(v.Interface().(time.Time))
+			if err != nil {
+				return chunk, err
+			}
+			binary.LittleEndian.PutUint64(chunk[:8], sec)
+			break
+		}
+		binary.LittleEndian.PutUint64(chunk[:8], integerBits(v))
 	case ssz.TypeUint128, ssz.TypeUint256:
+		// Packed like their little-endian serialization, so uint128 leaves bytes 16-31 zero
+		size := BasicTypeSize(typeInfo)
 		if v.Type() == uint256Type {
 			uint256Val := v.Interface().(uint256.Int)
-			uint256Val.WriteToSlice(chunk[:])
+			putUint256LE(chunk[:size], &uint256Val)
 		} else if v.Kind() == reflect.Ptr && v.Type().Elem() == uint256Type {
 			if !v.IsNil() {
-				uint256Val := v.Elem().Interface().(uint256.Int)
-				uint256Val.WriteToSlice(chunk[:])
-			}
-		}
-		if typeInfo.Type == ssz.TypeUint128 {
-			// For uint128, zero out bytes 16-31
-			for i := 16; i < 32; i++ {
-				chunk[i] = 0
+				putUint256LE(chunk[:size], v.Interface().(*uint256.Int))
 			}
 		}
 	case ssz.TypeBoolean:
@@ -134,97 +187,55 @@ func hashTreeRootBasicValue(v reflect.Value, typeInfo *TypeInfo) ([32]byte, erro
 	return chunk, nil
 }
 
-// packBytes packs bytes into chunks
-func packBytes(data []byte) [][32]byte {
-	// Calculate number of chunks needed
-	numChunks := (len(data) + BYTES_PER_CHUNK - 1) / BYTES_PER_CHUNK
-	if numChunks == 0 {
-		numChunks = 1 // At least one chunk
-	}
-
-	chunks := make([][32]byte, numChunks)
-	for i := 0; i < len(data); i++ {
-		chunks[i/BYTES_PER_CHUNK][i%BYTES_PER_CHUNK] = data[i]
-	}
-
-	return chunks
-}
-
 // packBasicVector packs a vector of basic types into chunks
 func packBasicVector(v reflect.Value, length int, elemType *TypeInfo) [][32]byte {
 	var data []byte
 
+	// Slices and arrays of basic values are packed from their encoding as a whole
+	if bs, ok := basicBytes(v); ok {
+		if size := BasicTypeSize(elemType); len(bs) != length*size {
+			data = make([]byte, length*size)
+			copy(data, bs)
+			bs = data
+		}
+		return PackBytes(bs)
+	}
+
+	elem := func(i int) reflect.Value {
+		e, _ := unwrapTransparent(v.Index(i), elemType)
+		return e
+	}
 	switch elemType.Type {
 	case ssz.TypeUint8:
 		data = make([]byte, length)
 		for i := 0; i < length && i < v.Len(); i++ {
-			data[i] = uint8(v.Index(i).Uint())
+			data[i] = uint8(elem(i).Uint())
 		}
 	case ssz.TypeUint16:
 		data = make([]byte, length*2)
 		for i := 0; i < length && i < v.Len(); i++ {
-			binary.LittleEndian.PutUint16(data[i*2:], uint16(v.Index(i).Uint()))
+			binary.LittleEndian.PutUint16(data[i*2:], uint16(elem(i).Uint()))
 		}
 	case ssz.TypeUint32:
 		data = make([]byte, length*4)
 		for i := 0; i < length && i < v.Len(); i++ {
-			binary.LittleEndian.PutUint32(data[i*4:], uint32(v.Index(i).Uint()))
+			binary.LittleEndian.PutUint32(data[i*4:], uint32(integerBits(elem(i))))
 		}
 	case ssz.TypeUint64:
 		data = make([]byte, length*8)
 		for i := 0; i < length && i < v.Len(); i++ {
-			binary.LittleEndian.PutUint64(data[i*8:], v.Index(i).Uint())
+			binary.LittleEndian.PutUint64(data[i*8:], integerBits(elem(i)))
 		}
 	case ssz.TypeBoolean:
 		data = make([]byte, length)
 		for i := 0; i < length && i < v.Len(); i++ {
-			if v.Index(i).Bool() {
+			if elem(i).Bool() {
 				data[i] = 1
 			}
 		}
 	}
 
-	return packBytes(data)
-}
-
-// mixInLength implements mix_in_length from the SSZ spec
-func mixInLength(root [32]byte, length uint64) [32]byte {
-	lengthRoot := merkle_tree.Uint64Root(length)
-	return merkle_tree.Sha256(root[:], lengthRoot[:])
-}
-
-// chunkCount returns the chunk count for a type (used for limits)
-func chunkCount(typeInfo *TypeInfo) uint64 {
-	switch typeInfo.Type {
-	case ssz.TypeBitVector:
-		// For bitvector, chunk count is based on bits
-		return uint64((typeInfo.BitLength + 255) / 256)
-	case ssz.TypeBitList:
-		// For bitlist, chunk count is based on max bits
-		return uint64((typeInfo.BitLength + 255) / 256)
-	case ssz.TypeList:
-		// For lists, calculate chunk count based on element type
-		if typeInfo.ElementType != nil && isBasicType(typeInfo.ElementType) {
-			// For basic types, calculate based on packed size
-			bytesPerElem := basicTypeSize(typeInfo.ElementType)
-			totalBytes := typeInfo.Length * bytesPerElem
-			return uint64((totalBytes + BYTES_PER_CHUNK - 1) / BYTES_PER_CHUNK)
-		}
-		// For composite types, each element is a chunk
-		return uint64(typeInfo.Length)
-	case ssz.TypeVector:
-		// For vectors, it depends on the element type
-		if isBasicType(typeInfo.ElementType) {
-			// For basic types, calculate based on packed size
-			bytesPerElem := basicTypeSize(typeInfo.ElementType)
-			totalBytes := typeInfo.Length * bytesPerElem
-			return uint64((totalBytes + BYTES_PER_CHUNK - 1) / BYTES_PER_CHUNK)
-		}
-		// For composite types, each element is a chunk
-		return uint64(typeInfo.Length)
-	default:
-		return 0
-	}
+	return PackBytes(data)
 }
 
 // isBasicType returns true if the type is a basic type
@@ -238,38 +249,18 @@ func isBasicType(typeInfo *TypeInfo) bool {
 	}
 }
 
-// basicTypeSize returns the size in bytes of a basic type
-func basicTypeSize(typeInfo *TypeInfo) int {
-	switch typeInfo.Type {
-	case ssz.TypeUint8, ssz.TypeBoolean:
-		return 1
-	case ssz.TypeUint16:
-		return 2
-	case ssz.TypeUint32:
-		return 4
-	case ssz.TypeUint64:
-		return 8
-	case ssz.TypeUint128:
-		return 16
-	case ssz.TypeUint256:
-		return 32
-	default:
-		return 0
-	}
-}
-
 // hashTreeRootVector calculates the hash tree root of a vector
-func hashTreeRootVector(v reflect.Value, typeInfo *TypeInfo) ([32]byte, error) {
+func hashTreeRootVector(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) ([32]byte, error) {
 	length := typeInfo.Length
 	elemType := typeInfo.ElementType
 
 	if isBasicType(elemType) {
 		var chunks [][32]byte
 
-		if elemType.Type == ssz.TypeUint8 && v.CanAddr() {
+		if elemType.Type == ssz.TypeUint8 && elemType.Wrapped == nil && v.CanAddr() {
 			// Special case for byte slices
 			bytes := v.Bytes()
-			chunks = packBytes(bytes)
+			chunks = PackBytes(bytes)
 		} else {
 			// Pack other basic types
 			chunks = packBasicVector(v, length, elemType)
@@ -283,22 +274,14 @@ func hashTreeRootVector(v reflect.Value, typeInfo *TypeInfo) ([32]byte, error) {
 		return chunks[0], nil
 	}
 
-	// Special case for Vector[Vector[uint8, 32], N] - each 32-byte vector is already a chunk
-	if elemType.Type == ssz.TypeVector && elemType.ElementType.Type == ssz.TypeUint8 && elemType.Length == 32 {
-		// Each 32-byte array is already a chunk
-		chunks := make([][32]byte, length)
-
-		for i := 0; i < length && i < v.Len(); i++ {
-			elem := v.Index(i)
-			if elem.Kind() == reflect.Slice && elem.Len() == 32 {
-				copy(chunks[i][:], elem.Bytes())
-			}
-		}
-
-		err := merkle_tree.MerklizeChunks(chunks, chunks[0][:])
+	// Vectors of byte vectors, such as roots or pubkeys, are hashed a layer at a time
+	if chunks, ok, err := byteVectorRoots(v, elemType, length); ok {
 		if err != nil {
 			return [32]byte{}, err
 		}
+		if err := merkle_tree.MerklizeChunks(chunks, chunks[0][:]); err != nil {
+			return [32]byte{}, err
+		}
 		return chunks[0], nil
 	}
 
@@ -308,12 +291,15 @@ func hashTreeRootVector(v reflect.Value, typeInfo *TypeInfo) ([32]byte, error) {
 		var elem reflect.Value
 		if i < v.Len() {
 			elem = v.Index(i)
+			if elem.Kind() == reflect.Ptr && elem.IsNil() {
+				return [32]byte{}, fmt.Errorf("error hashing vector element %d: %w", i, ErrNilElement)
+			}
 		} else {
 			// Pad with zero values if vector is shorter
-			elem = reflect.Zero(v.Type().Elem())
+			elem = derefZero(reflect.Zero(v.Type().Elem()))
 		}
 
-		hash, err := hashTreeRoot(elem, elemType)
+		hash, err := hashTreeRoot(elem, elemType, cache)
 		if err != nil {
 			return [32]byte{}, fmt.Errorf("error hashing vector element %d: %w", i, err)
 		}
@@ -327,110 +313,177 @@ func hashTreeRootVector(v reflect.Value, typeInfo *TypeInfo) ([32]byte, error) {
 	return chunks[0], nil
 }
 
-// hashTreeRootList calculates the hash tree root of a list
-func hashTreeRootList(v reflect.Value, typeInfo *TypeInfo) ([32]byte, error) {
+// hashTreeRootProgressiveList calculates the hash tree root of a progressive list:
+// mix_in_length(merkleize_progressive(pack(value) or element roots), len(value))
+func hashTreeRootProgressiveList(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) ([32]byte, error) {
 	elemType := typeInfo.ElementType
 	length := v.Len()
 
-	// Special case for strings (list of bytes)
-	if v.Kind() == reflect.String {
-		bytes := []byte(v.String())
-		root, err := merkle_tree.BytesRoot(bytes)
-		if err != nil {
-			return [32]byte{}, err
+	var chunks [][32]byte
+	switch {
+	case length == 0:
+	case isBasicType(elemType) && elemType.Type == ssz.TypeUint8 && elemType.Wrapped == nil:
+		chunks = PackBytes(v.Bytes())
+	case isBasicType(elemType):
+		chunks = packBasicVector(v, length, elemType)
+	default:
+		chunks = make([][32]byte, length)
+		for i := range length {
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Ptr && elem.IsNil() {
+				return [32]byte{}, fmt.Errorf("error hashing list element %d: %w", i, ErrNilElement)
+			}
+			hash, err := hashTreeRoot(elem, elemType, cache)
+			if err != nil {
+				return [32]byte{}, fmt.Errorf("error hashing list element %d: %w", i, err)
+			}
+			chunks[i] = hash
 		}
-		return mixInLength(root, uint64(length)), nil
 	}
 
-	// the length 0 can be handled by a special case where we just use zero hash.
-	if length == 0 {
-		if isBasicType(elemType) {
-			size := (typeInfo.Length*elemType.FixedSize + 31) / 32
-			return mixInLength(merkle_tree.ZeroHash(merkle_tree.GetDepth(uint64(size))), uint64(length)), nil
-		}
-		return mixInLength(merkle_tree.ZeroHash(merkle_tree.GetDepth(uint64(typeInfo.Length))), uint64(length)), nil
+	root, err := MerkleizeProgressive(chunks)
+	if err != nil {
+		return [32]byte{}, err
 	}
+	return MixInLength(root, uint64(length)), nil
+}
 
-	// For lists of basic types: mix_in_length(merkleize(pack(value), limit=chunk_count(type)), len(value))
-	if isBasicType(elemType) {
-		var chunks [][32]byte
+// hashTreeRootList calculates the hash tree root of a list:
+// mix_in_length(merkleize(chunks, limit=chunk_count(type)), len(value)). The limit is computed by
+// ChunkCount for every element type, so empty lists hash to the zero root of the depth of their
+// limit whatever their elements are.
+func hashTreeRootList(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) ([32]byte, error) {
+	chunks, err := listChunks(v, typeInfo, cache)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	root, err := Merkleize(chunks, ChunkCount(typeInfo))
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return MixInLength(root, uint64(v.Len())), nil
+}
 
-		if elemType.Type == ssz.TypeUint8 && v.CanAddr() {
+// listChunks returns the chunks a list is merkleized from: its packed values for lists of basic
+// types, and the roots of its elements for lists of composite types
+func listChunks(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) ([][32]byte, error) {
+	elemType := typeInfo.ElementType
+	length := v.Len()
+	switch {
+	case v.Kind() == reflect.String:
+		// Strings are lists of bytes
+		return PackBytes([]byte(v.String())), nil
+	case length == 0:
+		return nil, nil
+	case typeInfo.ByteList:
+		// Byte lists are packed whatever their element type, see TypeInfo.ByteList
+		return PackBytes(v.Bytes()), nil
+	case isBasicType(elemType):
+		if elemType.Type == ssz.TypeUint8 && elemType.Wrapped == nil && v.CanAddr() {
 			// Special case for byte slices
-			bytes := v.Bytes()
-			chunks = packBytes(bytes)
-		} else {
-			// Pack other basic types
-			chunks = packBasicVector(v, length, elemType)
+			return PackBytes(v.Bytes()), nil
 		}
-
-		// Calculate limit based on max capacity (in chunks)
-		limit := chunkCount(typeInfo)
-
-		// Merkleize with limit using ComputeMerkleRootRange
-		var root [32]byte
-		if length == 0 {
-			// For empty list, use zero hash at the appropriate depth
-			depth := merkle_tree.GetDepth(limit)
-			root = merkle_tree.ZeroHash(depth)
-		} else {
-			// Convert chunks to flat byte slice for ComputeMerkleRootRange
-			data := chunkedToSingle(chunks)
-			err := merkle_tree.ComputeMerkleRootRange(data, root[:], limit, 0)
-			if err != nil {
-				return [32]byte{}, err
-			}
-		}
-
-		return mixInLength(root, uint64(length)), nil
+		return packBasicVector(v, length, elemType), nil
+	}
+	if chunks, ok, err := byteVectorRoots(v, elemType, length); ok {
+		return chunks, err
 	}
 
-	// For lists of composite types: mix_in_length(merkleize([hash_tree_root(element) for element in value], limit), len(value))
 	chunks := make([][32]byte, length)
 	for i := range length {
 		elem := v.Index(i)
-		hash, err := hashTreeRoot(elem, elemType)
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			return nil, fmt.Errorf("error hashing list element %d: %w", i, ErrNilElement)
+		}
+		hash, err := hashTreeRoot(elem, elemType, cache)
 		if err != nil {
-			return [32]byte{}, fmt.Errorf("error hashing list element %d: %w", i, err)
+			return nil, fmt.Errorf("error hashing list element %d: %w", i, err)
 		}
 		chunks[i] = hash
 	}
+	return chunks, nil
+}
 
-	// Get the limit for the list type
-	limit := uint64(typeInfo.Length) // This is the max length from ssz-max tag
-
-	// Merkleize with limit using ComputeMerkleRootRange
-	var root [32]byte
-	if length == 0 {
-		// For empty list, use zero hash at the appropriate depth
-		depth := merkle_tree.GetDepth(limit)
-		root = merkle_tree.ZeroHash(depth)
-	} else {
-		// Convert chunks to flat byte slice for ComputeMerkleRootRange
-		data := chunkedToSingle(chunks)
-		err := merkle_tree.ComputeMerkleRootRange(data, root[:], limit, 0)
-		if err != nil {
-			return [32]byte{}, err
+// byteVectorRoots returns the roots of the first n elements of v, a slice or array of byte vectors
+// of any size, see byteVectorSize. Each element takes the chunks of its bytes, rounded up to a
+// power of two, in one buffer, which is hashed in place a layer at a time across all elements
+// until one chunk per element is left. Elements that are missing, or that do not have the size,
+// are hashed as zero vectors. ok is false for other values.
+func byteVectorRoots(v reflect.Value, elemType *TypeInfo, n int) ([][32]byte, bool, error) {
+	size, ok := byteVectorSize(v.Type(), elemType)
+	if !ok || n == 0 {
+		return nil, false, nil
+	}
+	width := int(mathutil.NextPowerOfTwo(uint64((size+31)/32))) * 32
+	chunks := make([][32]byte, n*width/32)
+	buf := chunkedToSingle(chunks)
+	for i := 0; i < n && i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Len() != size {
+			continue
 		}
+		if bs, ok := byteArrayBytes(elem); ok {
+			copy(buf[i*width:], bs)
+		} else if elem.Kind() == reflect.Slice {
+			copy(buf[i*width:], elem.Bytes())
+		} else {
+			reflect.Copy(reflect.ValueOf(buf[i*width:i*width+size]), elem)
+		}
+	}
+	for ; width > 32; width /= 2 {
+		if err := merkle_tree.DefaultHasher.HashPairs(buf, buf); err != nil {
+			return nil, true, err
+		}
+		buf = buf[:len(buf)/2]
 	}
+	// The roots are the first n chunks, unless chunkedToSingle made a copy
+	copyToChunks(chunks, buf)
+	return chunks[:n], true, nil
+}
 
-	return mixInLength(root, uint64(length)), nil
+// FieldRoots returns the hash tree roots of the fields of a container in order, which are the
+// leaves merkleized into its root, e.g. to build a proof for one of its fields
+func FieldRoots(v any) ([][32]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("cannot hash nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting type info: %w", err)
+	}
+	rv, typeInfo = unwrapTransparent(rv, typeInfo)
+	if typeInfo.Type != ssz.TypeContainer || typeInfo.Custom || rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a container", rv.Type())
+	}
+	return containerFieldRoots(rv, typeInfo, nil)
 }
 
-// hashTreeRootContainer calculates the hash tree root of a container
-func hashTreeRootContainer(v reflect.Value, typeInfo *TypeInfo) ([32]byte, error) {
-	// Containers: merkleize([hash_tree_root(element) for element in value])
+// containerFieldRoots returns the hash tree roots of the fields of a container
+func containerFieldRoots(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) ([][32]byte, error) {
 	chunks := make([][32]byte, len(typeInfo.Fields))
-
 	for i, field := range typeInfo.Fields {
-		fieldValue := v.Field(field.Index)
+		fieldValue := field.value(v)
 		var err error
-		chunks[i], err = hashTreeRoot(fieldValue, field.Type)
+		chunks[i], err = hashTreeRoot(fieldValue, field.Type, cache)
 		if err != nil {
-			return [32]byte{}, fmt.Errorf("error hashing field %s: %w", field.Name, err)
+			return nil, fmt.Errorf("error hashing field %s: %w", field.Name, err)
 		}
 	}
-	err := merkle_tree.MerklizeChunks(chunks, chunks[0][:])
+	return chunks, nil
+}
+
+// hashTreeRootContainer calculates the hash tree root of a container
+func hashTreeRootContainer(v reflect.Value, typeInfo *TypeInfo, cache *HashCache) ([32]byte, error) {
+	// Containers: merkleize([hash_tree_root(element) for element in value])
+	chunks, err := containerFieldRoots(v, typeInfo, cache)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	err = merkle_tree.MerklizeChunks(chunks, chunks[0][:])
 	if err != nil {
 		return [32]byte{}, err
 	}
@@ -1,8 +1,10 @@
 package flexssz
 
 import (
+	"crypto/sha256"
 	"testing"
 
+	"github.com/gfx-labs/ssz/merkle_tree"
 	"github.com/holiman/uint256"
 )
 
@@ -112,4 +114,201 @@ func TestHashTreeRootBitfields(t *testing.T) {
 	}
 
 	t.Logf("Bitfield struct hash tree root: %x", root)
-}
\ No newline at end of file
+}
+func TestHashTreeRootByteArrayVector(t *testing.T) {
+	// [N][32]byte elements must hash the same as []byte elements of the same contents
+	type ArrayElems struct {
+		Roots [][32]byte `ssz-size:"17,32"`
+	}
+	type SliceElems struct {
+		Roots [][]byte `ssz-size:"17,32"`
+	}
+
+	arrays := &ArrayElems{Roots: make([][32]byte, 17)}
+	slices := &SliceElems{Roots: make([][]byte, 17)}
+	for i := range arrays.Roots {
+		arrays.Roots[i][0] = byte(i + 1)
+		slices.Roots[i] = make([]byte, 32)
+		slices.Roots[i][0] = byte(i + 1)
+	}
+
+	arrayRoot, err := HashTreeRoot(arrays)
+	if err != nil {
+		t.Fatalf("Failed to calculate hash tree root: %v", err)
+	}
+	sliceRoot, err := HashTreeRoot(slices)
+	if err != nil {
+		t.Fatalf("Failed to calculate hash tree root: %v", err)
+	}
+	if arrayRoot != sliceRoot {
+		t.Errorf("Array elements root %x does not match slice elements root %x", arrayRoot, sliceRoot)
+	}
+
+	zeroRoot, err := HashTreeRoot(&ArrayElems{Roots: make([][32]byte, 17)})
+	if err != nil {
+		t.Fatalf("Failed to calculate hash tree root: %v", err)
+	}
+	if arrayRoot == zeroRoot {
+		t.Error("Array elements were not hashed")
+	}
+}
+
+func TestHashTreeRootUint256LittleEndian(t *testing.T) {
+	// uint128 and uint256 are packed like their serialization, little-endian
+	type Wide struct {
+		A uint256.Int  `ssz:"uint256"`
+		B *uint256.Int `ssz:"uint128"`
+	}
+	value := &Wide{A: uint256.Int{1, 2, 3, 4}, B: &uint256.Int{5, 6, 7, 8}}
+	data, err := Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var a, b [32]byte
+	copy(a[:], data[:32])
+	copy(b[:], data[32:48])
+	fields, err := FieldRoots(value)
+	if err != nil {
+		t.Fatalf("Failed to calculate field roots: %v", err)
+	}
+	if fields[0] != a || fields[1] != b {
+		t.Errorf("Field roots %x, expected %x and %x", fields, a, b)
+	}
+}
+
+type emptyListFixed struct {
+	A uint16
+	B [4]byte
+}
+
+type emptyListVariable struct {
+	A    uint16
+	Data []byte `ssz-max:"7"`
+}
+
+// emptyLists has an empty list of every element kind, with limits that are not powers of two
+type emptyLists struct {
+	Bytes    []byte              `ssz-max:"100"`
+	Uint16s  []uint16            `ssz-max:"5"`
+	Uint32s  []uint32            `ssz-max:"17"`
+	Uint64s  []uint64            `ssz-max:"3"`
+	Uint256s []uint256.Int       `ssz-max:"3"`
+	Bools    []bool              `ssz-max:"33"`
+	Roots    [][32]byte          `ssz-max:"5"`
+	Fixed    []emptyListFixed    `ssz-max:"3"`
+	Variable []emptyListVariable `ssz-max:"6"`
+	Nested   [][]uint64          `ssz-max:"5,9"`
+	Pointers []*emptyListFixed   `ssz-max:"1"`
+	Bitlist  []byte              `ssz:"bitlist" ssz-max:"600"`
+	Labels   map[uint64]uint64   `ssz:"kvlist" ssz-max:"9"`
+}
+
+func TestHashTreeRootEmptyLists(t *testing.T) {
+	// An empty list is mix_in_length(merkleize([], limit=chunk_count(type)), 0), the zero root
+	// of a tree with limit leaves rounded up to a power of two, computed here without flexssz
+	zeroHashes := make([][32]byte, 8)
+	for i := 1; i < len(zeroHashes); i++ {
+		zeroHashes[i] = sha256.Sum256(append(zeroHashes[i-1][:], zeroHashes[i-1][:]...))
+	}
+	emptyRoot := func(depth int) [32]byte {
+		var length [32]byte
+		return sha256.Sum256(append(zeroHashes[depth][:], length[:]...))
+	}
+	depths := []struct {
+		field string
+		depth int
+	}{
+		{"Bytes", 2},    // 100 bytes in 4 chunks
+		{"Uint16s", 0},  // 10 bytes in 1 chunk
+		{"Uint32s", 2},  // 68 bytes in 3 chunks, rounded up to 4
+		{"Uint64s", 0},  // 24 bytes in 1 chunk
+		{"Uint256s", 2}, // 3 chunks, rounded up to 4
+		{"Bools", 1},    // 33 bytes in 2 chunks
+		{"Roots", 3},    // 5 roots, rounded up to 8
+		{"Fixed", 2},    // 3 roots, rounded up to 4
+		{"Variable", 3}, // 6 roots, rounded up to 8
+		{"Nested", 3},   // 5 roots, rounded up to 8
+		{"Pointers", 0}, // 1 root
+		{"Bitlist", 2},  // 600 bits in 3 chunks, rounded up to 4
+		{"Labels", 4},   // 9 entries, rounded up to 16
+	}
+
+	roots, err := FieldRoots(&emptyLists{})
+	if err != nil {
+		t.Fatalf("Failed to calculate field roots: %v", err)
+	}
+	if len(roots) != len(depths) {
+		t.Fatalf("Got %d field roots, expected %d", len(roots), len(depths))
+	}
+	for i, d := range depths {
+		if want := emptyRoot(d.depth); roots[i] != want {
+			t.Errorf("%s: root %x, expected %x", d.field, roots[i], want)
+		}
+	}
+}
+
+func TestHashTreeRootListLimit(t *testing.T) {
+	// A list of composite elements is padded to its limit rounded up to a power of two: 3
+	// elements of a list of at most 5 are merkleized in a tree of 8 leaves
+	value := struct {
+		Items []emptyListFixed `ssz-max:"5"`
+	}{Items: []emptyListFixed{{A: 1}, {A: 2}, {A: 3}}}
+	chunks := make([][32]byte, 3)
+	for i := range value.Items {
+		root, err := HashTreeRoot(&value.Items[i])
+		if err != nil {
+			t.Fatalf("Failed to hash element %d: %v", i, err)
+		}
+		chunks[i] = root
+	}
+	want, err := Merkleize(chunks, 8)
+	if err != nil {
+		t.Fatalf("Failed to merkleize: %v", err)
+	}
+	want = MixInLength(want, 3)
+
+	roots, err := FieldRoots(&value)
+	if err != nil {
+		t.Fatalf("Failed to calculate field roots: %v", err)
+	}
+	if roots[0] != want {
+		t.Errorf("Root %x, expected %x", roots[0], want)
+	}
+
+	value.Items = append(value.Items, emptyListFixed{}, emptyListFixed{}, emptyListFixed{})
+	if _, err := HashTreeRoot(&value); err == nil {
+		t.Error("Expected an error hashing a list longer than its limit")
+	}
+}
+
+func TestFieldRoots(t *testing.T) {
+	type Inner struct {
+		A uint64
+		B []byte `ssz-max:"64"`
+	}
+	v := &Inner{A: 7, B: []byte{1, 2, 3}}
+
+	roots, err := FieldRoots(v)
+	if err != nil {
+		t.Fatalf("Failed to calculate field roots: %v", err)
+	}
+	if len(roots) != 2 || roots[0] != merkle_tree.Uint64Root(7) {
+		t.Fatalf("Unexpected field roots %x", roots)
+	}
+	var expected [32]byte
+	if err := merkle_tree.MerklizeChunks(roots, expected[:]); err != nil {
+		t.Fatal(err)
+	}
+	root, err := HashTreeRoot(v)
+	if err != nil {
+		t.Fatalf("Failed to calculate hash tree root: %v", err)
+	}
+	if root != expected {
+		t.Errorf("Root %x is not the merkle root of the field roots %x", root, expected)
+	}
+
+	if _, err := FieldRoots([]uint64{1}); err == nil {
+		t.Error("Expected an error for a list")
+	}
+}
@@ -6,23 +6,30 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gfx-labs/ssz"
-	"github.com/holiman/uint256"
-)
-
-var (
-	// Precalculated type to avoid reflection overhead
-	uint256TypeTag = reflect.TypeOf(uint256.Int{})
 )
 
 // sszTag represents parsed SSZ struct tag information
 type sszTag struct {
 	Skip       bool   // "-" tag means skip this field
-	FieldType  string // "uint8", "uint16", "uint32", "uint64", "bool", "vector", "list", "container", "string", "bitlist", "bitvector"
+	FieldType  string // "uint8", "uint16", "uint32", "uint64", "int32", "int64", "bool", "vector", "list", "progressive-list", "bytelist", "container", "string", "bitlist", "bitvector", "union", "kvlist"
 	IsVariable bool   // Whether this field is variable-size (strings, slices)
 	MaxList    int    // For variable-size lists: ssz-max:"1024"
+	Max        []int  // All ssz-max dimensions, e.g. "1048576,1073741824" for lists of lists
 	Size       []int  // For fixed-size arrays: ssz-size:"32" or "8192,32" for multi-dimensional
+	MaxName    string // Spec constant of the first ssz-max dimension: ssz-max-name:"VALIDATOR_REGISTRY_LIMIT"
+	OmitZero   bool   // For *uint256.Int: ssz-omitzero:"true" encodes nil as zero and decodes zero as nil
+	Unix       bool   // For time.Time: ssz:"uint64,unix" encodes the time as uint64 unix seconds
+
+	// Transparent marks the only field of a wrapper struct, e.g. ssz:",transparent", so the
+	// struct is encoded and hashed as the field instead of as a container
+	Transparent bool
+
+	// Inline puts the fields of a struct in the parent container instead of nesting it, set by
+	// ssz:"inline" and for embedded structs without tags, see inlineByDefault
+	Inline bool
 }
 
 // TypeInfo represents SSZ type information for any type (not just structs)
@@ -44,6 +51,28 @@ type TypeInfo struct {
 	// For special types
 	BitLength int     // Number of bits for bitvector/bitlist
 	Tag       *sszTag // Original tag information
+
+	// Custom types encode themselves, see Marshaler
+	Custom bool
+
+	// Wrapped is the field of a transparent wrapper struct. The rest of the TypeInfo is copied
+	// from the field, so values must be unwrapped with unwrapTransparent before use.
+	Wrapped *FieldInfo
+
+	// Progressive is set for lists merkleized progressively, which have no limit, see
+	// MerkleizeProgressive
+	Progressive bool
+
+	// ByteList is set for lists tagged ssz:"bytelist", which are always hashed as packed bytes
+	// with a limit of ceil(N/32) chunks, whatever their element type, see listChunks
+	ByteList bool
+
+	// kvlist is set for maps, which are encoded as their sorted entries, see kvList. The
+	// rest of the TypeInfo is copied from the list of entries.
+	kvlist *kvList
+
+	// fieldOffsets is the offset of each field of a container in its fixed part, see FieldOffsets
+	fieldOffsets []int
 }
 
 // FieldInfo represents information about a struct field
@@ -52,12 +81,95 @@ type FieldInfo struct {
 	Name   string    // Field name
 	Type   *TypeInfo // Type information for this field
 	Offset int       // Offset in fixed part (-1 for variable fields)
+
+	// Path is the index sequence of a field inlined from a struct, for reflect.Value.FieldByIndex,
+	// with Index the field holding the struct. It is nil for other fields.
+	Path []int
+}
+
+// unwrapTransparent returns the field of a transparent wrapper struct, see sszTag.Transparent.
+// Other values, including pointers to wrappers, are returned as is.
+func unwrapTransparent(v reflect.Value, info *TypeInfo) (reflect.Value, *TypeInfo) {
+	for info.Wrapped != nil && v.Kind() == reflect.Struct {
+		v, info = v.Field(info.Wrapped.Index), info.Wrapped.Type
+	}
+	return v, info
+}
+
+// transparentField returns the field of v if it is a transparent wrapper struct
+func transparentField(v reflect.Value) (reflect.Value, *sszTag, bool) {
+	info, err := GetTypeInfo(v.Type(), nil)
+	if err != nil || info.Wrapped == nil {
+		return v, nil, false
+	}
+	tag := info.Wrapped.Type.Tag
+	if tag == nil {
+		tag = &sszTag{}
+	}
+	return v.Field(info.Wrapped.Index), tag, true
+}
+
+// elementTag returns the tag for the elements of a multi-dimensional slice,
+// dropping the outer ssz-size and ssz-max dimensions
+func (tag *sszTag) elementTag() *sszTag {
+	elemTag := &sszTag{}
+	if len(tag.Size) > 1 {
+		elemTag.Size = tag.Size[1:]
+	}
+	if len(tag.Max) > 1 {
+		elemTag.Max = tag.Max[1:]
+		elemTag.MaxList = elemTag.Max[0]
+		elemTag.IsVariable = true
+	}
+	return elemTag
 }
 
 // typeInfoCache caches parsed type information
 var typeInfoCache = make(map[reflect.Type]*TypeInfo)
 var typeInfoCacheMutex sync.RWMutex
 
+// typeCacheGeneration counts the calls to ResetTypeCache. Type information parsed in an earlier
+// generation is not cached, since it may have been resolved with the old limits.
+var typeCacheGeneration atomic.Uint64
+
+// ResetTypeCache drops the cached type information of every type, along with the limits and
+// sizes resolved from ListLimiter and VectorSizer types, so they are resolved again on next
+// use. Long-lived processes call it after changing what those return, e.g. when switching
+// between mainnet and minimal presets. TypeInfo values returned before keep the old layout, and
+// roots cached in a HashCache were computed with it, so call InvalidateAll on those as well.
+func ResetTypeCache() {
+	typeInfoCacheMutex.Lock()
+	defer typeInfoCacheMutex.Unlock()
+	typeCacheGeneration.Add(1)
+	typeInfoCache = make(map[reflect.Type]*TypeInfo)
+	typeDimsCache.Clear()
+	kvListCache.Clear()
+}
+
+// TypeCacheGeneration returns the number of times ResetTypeCache was called, so caches derived
+// from type information can tell that it changed
+func TypeCacheGeneration() uint64 {
+	return typeCacheGeneration.Load()
+}
+
+// storeTypeCache caches type information parsed in generation gen, unless the cache was reset
+// since then. store is called with the type info cache lock held.
+func storeTypeCache(gen uint64, store func()) {
+	typeInfoCacheMutex.Lock()
+	defer typeInfoCacheMutex.Unlock()
+	if typeCacheGeneration.Load() == gen {
+		store()
+	}
+}
+
+// cachedTypeInfo returns the cached type information of t, without counting the lookup in Stats
+func cachedTypeInfo(t reflect.Type) (*TypeInfo, bool) {
+	typeInfoCacheMutex.RLock()
+	defer typeInfoCacheMutex.RUnlock()
+	info, ok := typeInfoCache[t]
+	return info, ok
+}
+
 // parseSSZTags parses SSZ-related struct tags
 func parseSSZTags(field reflect.StructField) (*sszTag, error) {
 	tag := &sszTag{}
@@ -67,9 +179,34 @@ func parseSSZTags(field reflect.StructField) (*sszTag, error) {
 	if sszTag == "-" {
 		tag.Skip = true
 		return tag, nil
+	} else if sszTag == "inline" || inlineByDefault(field) {
+		if field.Type.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("field %s: ssz tag 'inline' requires struct type, got %v", field.Name, field.Type)
+		}
+		if hasSSZTags(field.Tag) && sszTag != "inline" || field.Tag.Get("ssz-size") != "" || field.Tag.Get("ssz-max") != "" {
+			return nil, fmt.Errorf("field %s: inlined structs take no other ssz tags", field.Name)
+		}
+		// The fields of unexported named fields cannot be set, only those of embedded structs
+		if !field.Anonymous && !field.IsExported() {
+			return nil, fmt.Errorf("field %s: ssz tag 'inline' requires an exported or embedded field", field.Name)
+		}
+		tag.Inline = true
+		return tag, nil
 	} else if sszTag != "" {
-		// If ssz tag has a value, use it as the field type
-		tag.FieldType = sszTag
+		// If ssz tag has a value, use it as the field type, followed by options such as "uint64,unix"
+		fieldType, options, _ := strings.Cut(sszTag, ",")
+		tag.FieldType = fieldType
+		for _, option := range strings.Split(options, ",") {
+			switch strings.TrimSpace(option) {
+			case "":
+			case "unix":
+				tag.Unix = true
+			case "transparent":
+				tag.Transparent = true
+			default:
+				return nil, fmt.Errorf("field %s: unknown ssz tag option %q", field.Name, option)
+			}
+		}
 	}
 
 	// Parse ssz-size tag for fixed-size arrays/slices
@@ -98,22 +235,48 @@ func parseSSZTags(field reflect.StructField) (*sszTag, error) {
 
 	// Parse ssz-max tag for variable-size lists
 	if maxStr := field.Tag.Get("ssz-max"); maxStr != "" {
-		// Handle special case "?" which means no limit
-		if maxStr == "?" {
-			tag.MaxList = 0 // 0 means no limit in our implementation
-		} else {
-			max, err := strconv.Atoi(maxStr)
+		// Handle multi-dimensional limits like "1048576,1073741824", where "?" means no limit
+		parts := strings.Split(maxStr, ",")
+		maxes := make([]int, len(parts))
+		for i, part := range parts {
+			trimmed := strings.TrimSpace(part)
+			if trimmed == "?" {
+				continue // 0 means no limit in our implementation
+			}
+			max, err := strconv.Atoi(trimmed)
 			if err != nil {
 				return nil, fmt.Errorf("invalid ssz-max value: %v", err)
 			}
-			tag.MaxList = max
+			maxes[i] = max
 		}
+		tag.MaxList = maxes[0]
+		tag.Max = maxes
 		tag.IsVariable = true
 
 		// Don't auto-set field type for slices with ssz-max
 		// They will be handled based on reflection
 	}
 
+	// Name the limit so DecodeOptions.LimitOverrides can replace it
+	tag.MaxName = field.Tag.Get("ssz-max-name")
+
+	// Slice types that carry their limit or length need no ssz-max or ssz-size tag
+	if typed := withTypeDimensions(field.Type, tag); typed != tag {
+		*tag = *typed
+	}
+
+	// Parse ssz-omitzero tag for pointers that use nil to represent zero
+	if omitStr := field.Tag.Get("ssz-omitzero"); omitStr != "" {
+		omitZero, err := strconv.ParseBool(omitStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ssz-omitzero value: %v", err)
+		}
+		if omitZero && (field.Type.Kind() != reflect.Ptr || field.Type.Elem() != uint256Type) {
+			return nil, fmt.Errorf("field %s: ssz-omitzero tag can only be used with *uint256.Int, got %v", field.Name, field.Type)
+		}
+		tag.OmitZero = omitZero
+	}
+
 	// Auto-detect field type based on reflection if not specified
 	if tag.FieldType == "" {
 		tag.FieldType = detectFieldType(field.Type)
@@ -137,27 +300,36 @@ func parseSSZTags(field reflect.StructField) (*sszTag, error) {
 		return nil, fmt.Errorf("field %s: cannot use both ssz-size and ssz-max tags unless ssz-size contains '?'", field.Name)
 	}
 
-	// Validate ssz-size can only be used with arrays or slices
-	if len(tag.Size) > 0 && field.Type.Kind() != reflect.Array && field.Type.Kind() != reflect.Slice {
+	// Validate ssz-size can only be used with arrays or slices, or types that encode themselves,
+	// and kvlist maps, where the inner dimensions apply to the values
+	isMap := field.Type.Kind() == reflect.Map
+	if len(tag.Size) > 0 && field.Type.Kind() != reflect.Array && field.Type.Kind() != reflect.Slice && !isMap && !isCustomType(field.Type) {
 		return nil, fmt.Errorf("field %s: ssz-size tag can only be used with array or slice types, got %v", field.Name, field.Type)
 	}
 
-	// Validate ssz-max can only be used with slices
-	if tag.MaxList > 0 && field.Type.Kind() != reflect.Slice {
+	// Validate ssz-max can only be used with slices and kvlist maps
+	if tag.MaxList > 0 && field.Type.Kind() != reflect.Slice && !isMap {
 		return nil, fmt.Errorf("field %s: ssz-max tag can only be used with slice types, got %v", field.Name, field.Type)
 	}
 
-	// Validate that variable slices must have a limit
+	// Validate that variable slices must have a limit, except progressive lists
 	// Note: MaxList == 0 after parsing "?" means no limit, which is valid
-	if field.Type.Kind() == reflect.Slice && len(tag.Size) == 0 && tag.MaxList == 0 && field.Tag.Get("ssz-max") == "" {
+	if field.Type.Kind() == reflect.Slice && len(tag.Size) == 0 && tag.MaxList == 0 && field.Tag.Get("ssz-max") == "" && !isCustomType(field.Type) && tag.FieldType != "progressive-list" {
 		return nil, fmt.Errorf("field %s: slice types must have either ssz-size or ssz-max tag", field.Name)
 	}
 
-	// Validate multi-dimensional arrays
-	if len(tag.Size) > 1 {
+	// Validate multi-dimensional arrays, kvlist values are validated with their entries
+	if len(tag.Size) > 1 && !isMap {
 		// Check that we have nested slices/arrays
 		t := field.Type
 		for i, size := range tag.Size {
+			if isCustomType(t) {
+				// The last dimension may be the size of a type that encodes itself
+				if i != len(tag.Size)-1 {
+					return nil, fmt.Errorf("field %s: ssz-size has %d dimensions but type only has %d", field.Name, len(tag.Size), i+1)
+				}
+				break
+			}
 			if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
 				return nil, fmt.Errorf("field %s: ssz-size has %d dimensions but type only has %d", field.Name, len(tag.Size), i)
 			}
@@ -200,16 +372,25 @@ func detectFieldType(t reflect.Type) string {
 		return "list"
 	case reflect.Array:
 		// Check if it's a uint256.Int type (which is [4]uint64)
-		if t == uint256TypeTag {
+		if t == uint256Type {
 			// By default, treat as uint256 unless tag specifies otherwise
 			return "uint256"
 		}
 		return "vector"
 	case reflect.Struct:
+		if t == unionType {
+			return "union"
+		}
+		if t == timeType {
+			return "uint64"
+		}
 		return "container"
 	case reflect.Ptr:
 		// For pointers, detect based on the element type
 		return detectFieldType(t.Elem())
+	case reflect.Interface:
+		// Interfaces are unions of their registered members, see RegisterUnionMember
+		return "union"
 	default:
 		return ""
 	}
@@ -223,6 +404,11 @@ func typeIsVariable(t reflect.Type, tag *sszTag) bool {
 		return true
 	}
 
+	// Types that encode themselves are fixed-size only with ssz-size
+	if isCustomType(t) {
+		return tag == nil || len(tag.Size) == 0
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		return true
@@ -241,6 +427,12 @@ func typeIsVariable(t reflect.Type, tag *sszTag) bool {
 	case reflect.Struct:
 		// Check if struct contains any variable-size fields
 		return structHasVariableFields(t)
+	case reflect.Map:
+		// Maps are encoded as lists of their entries
+		return true
+	case reflect.Interface:
+		// Interfaces are unions, which are always variable-size
+		return true
 	case reflect.Ptr:
 		// Pointers are variable if their element is variable
 		return typeIsVariable(t.Elem(), tag)
@@ -254,6 +446,24 @@ func typeIsVariable(t reflect.Type, tag *sszTag) bool {
 func validateFieldType(field reflect.StructField, tag *sszTag) error {
 	t := field.Type
 
+	// time.Time is only encoded as unix seconds, and only time.Time can be
+	isTime := t == timeType || (t.Kind() == reflect.Ptr && t.Elem() == timeType)
+	if isTime != tag.Unix {
+		if isTime {
+			return fmt.Errorf("field %s: time.Time requires ssz tag 'uint64,unix'", field.Name)
+		}
+		return fmt.Errorf("field %s: ssz tag option 'unix' requires time.Time or *time.Time type, got %v", field.Name, t)
+	}
+	if isTime {
+		if tag.FieldType != "uint64" {
+			return fmt.Errorf("field %s: time.Time requires ssz tag 'uint64,unix', got '%s'", field.Name, tag.FieldType)
+		}
+		return nil
+	}
+	if err := validateSignedField(field, tag); err != nil {
+		return err
+	}
+
 	switch tag.FieldType {
 	case "uint8":
 		if t.Kind() != reflect.Uint8 {
@@ -284,6 +494,25 @@ func validateFieldType(field reflect.StructField, tag *sszTag) error {
 		if t.Kind() != reflect.Slice {
 			return fmt.Errorf("field %s: ssz tag 'list' requires slice type, got %v", field.Name, t)
 		}
+	case "progressive-list":
+		// progressive lists grow without a limit, so they take no ssz-max or ssz-size
+		if t.Kind() != reflect.Slice {
+			return fmt.Errorf("field %s: ssz tag 'progressive-list' requires slice type, got %v", field.Name, t)
+		}
+		if tag.MaxList > 0 || len(tag.Size) > 0 {
+			return fmt.Errorf("field %s: progressive-list has no limit and takes no ssz-max or ssz-size tag", field.Name)
+		}
+	case "bytelist":
+		// bytelist must be a []byte type with a limit
+		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("field %s: ssz tag 'bytelist' requires []byte type, got %v", field.Name, t)
+		}
+		if len(tag.Size) > 0 {
+			return fmt.Errorf("field %s: bytelist takes no ssz-size tag", field.Name)
+		}
+		if tag.MaxList == 0 {
+			return fmt.Errorf("field %s: bytelist requires ssz-max tag", field.Name)
+		}
 	case "vector":
 		// vector must be an array type
 		if t.Kind() != reflect.Array {
@@ -291,11 +520,7 @@ func validateFieldType(field reflect.StructField, tag *sszTag) error {
 		}
 	case "uint128", "uint256":
 		// Allow both uint256.Int and *uint256.Int
-		if t == uint256TypeTag {
-			// Direct uint256.Int type
-		} else if t.Kind() == reflect.Ptr && t.Elem() == uint256TypeTag {
-			// Pointer to uint256.Int
-		} else {
+		if !IsUint256Type(t) {
 			return fmt.Errorf("field %s: ssz tag '%s' requires uint256.Int or *uint256.Int type, got %v", field.Name, tag.FieldType, t)
 		}
 	case "container":
@@ -307,6 +532,11 @@ func validateFieldType(field reflect.StructField, tag *sszTag) error {
 		} else if t.Kind() != reflect.Struct {
 			return fmt.Errorf("field %s: ssz tag 'container' requires struct or pointer to struct type, got %v", field.Name, t)
 		}
+	case "union":
+		// union must be a flexssz.Union, a pointer to one or an interface with registered members
+		if t != unionType && (t.Kind() != reflect.Ptr || t.Elem() != unionType) && t.Kind() != reflect.Interface {
+			return fmt.Errorf("field %s: ssz tag 'union' requires flexssz.Union or interface type, got %v", field.Name, t)
+		}
 	case "bitlist":
 		// bitlist must be a []byte type
 		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
@@ -316,6 +546,17 @@ func validateFieldType(field reflect.StructField, tag *sszTag) error {
 		if tag.MaxList == 0 && field.Tag.Get("ssz-max") == "" {
 			return fmt.Errorf("field %s: bitlist requires ssz-max tag", field.Name)
 		}
+	case "kvlist":
+		// kvlist must be a map type with a limit on the number of entries
+		if t.Kind() != reflect.Map {
+			return fmt.Errorf("field %s: ssz tag 'kvlist' requires map type, got %v", field.Name, t)
+		}
+		if len(tag.Size) > 0 && tag.Size[0] != -1 {
+			return fmt.Errorf("field %s: kvlist ssz-size must start with '?', got %d", field.Name, tag.Size[0])
+		}
+		if tag.MaxList == 0 {
+			return fmt.Errorf("field %s: kvlist requires ssz-max tag", field.Name)
+		}
 	case "bitvector":
 		// bitvector must be a []byte type
 		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
@@ -377,15 +618,14 @@ func PrecacheStructSSZInfo(v interface{}) error {
 	}
 
 	// Parse and cache the struct
+	gen := typeCacheGeneration.Load()
 	info, err := parseTypeInfo(t, nil)
 	if err != nil {
 		return err
 	}
 
 	// Cache the result
-	typeInfoCacheMutex.Lock()
-	typeInfoCache[t] = info
-	typeInfoCacheMutex.Unlock()
+	storeTypeCache(gen, func() { typeInfoCache[t] = info })
 
 	return nil
 }
@@ -407,12 +647,14 @@ func GetTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 		info, exists := typeInfoCache[t]
 		typeInfoCacheMutex.RUnlock()
 
+		recordTypeCache(exists)
 		if exists {
 			return info, nil
 		}
 	}
 
 	// Parse type info
+	gen := typeCacheGeneration.Load()
 	info, err := parseTypeInfo(t, tag)
 	if err != nil {
 		return nil, err
@@ -420,9 +662,7 @@ func GetTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 
 	// Cache the result only when tag is nil
 	if tag == nil {
-		typeInfoCacheMutex.Lock()
-		typeInfoCache[t] = info
-		typeInfoCacheMutex.Unlock()
+		storeTypeCache(gen, func() { typeInfoCache[t] = info })
 	}
 
 	return info, nil
@@ -431,6 +671,11 @@ func GetTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 // parseTypeInfo parses type information for any Go type
 // calculateIsVariable recursively calculates the IsVariable field for a TypeInfo
 func calculateIsVariable(info *TypeInfo) {
+	// Custom types get their size from their tag
+	if info.Custom {
+		return
+	}
+
 	// Use the SSZ type methods to determine variability
 	if info.Type.IsAlwaysFixed() {
 		info.IsVariable = false
@@ -471,6 +716,7 @@ func calculateIsVariable(info *TypeInfo) {
 }
 
 func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
+	tag = withTypeDimensions(t, tag)
 	info := &TypeInfo{
 		Tag: tag,
 	}
@@ -486,6 +732,20 @@ func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 		return elemInfo, nil
 	}
 
+	if isCustomType(t) {
+		// Types that encode themselves are opaque, and fixed-size only when tagged with ssz-size
+		info.Type = ssz.TypeContainer
+		info.BasicType = t
+		info.Custom = true
+		if tag != nil && len(tag.Size) > 0 && tag.Size[0] >= 0 {
+			info.FixedSize = tag.Size[0]
+		} else {
+			info.FixedSize = -1
+			info.IsVariable = true
+		}
+		return info, nil
+	}
+
 	switch t.Kind() {
 	case reflect.Uint8:
 		info.Type = ssz.TypeUint8
@@ -507,6 +767,17 @@ func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 		info.BasicType = t
 		info.FixedSize = 8
 
+	case reflect.Int32, reflect.Int64:
+		// Signed integers are encoded and hashed as the unsigned integer of the same width, see
+		// signed.go. Fields are checked for their tag by validateSignedField.
+		info.Type = ssz.TypeUint32
+		info.FixedSize = 4
+		if t.Kind() == reflect.Int64 {
+			info.Type = ssz.TypeUint64
+			info.FixedSize = 8
+		}
+		info.BasicType = t
+
 	case reflect.Bool:
 		info.Type = ssz.TypeBoolean
 		info.BasicType = t
@@ -523,7 +794,7 @@ func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 		}
 
 	case reflect.Array:
-		if t == uint256TypeTag {
+		if t == uint256Type {
 			// Special case for uint256.Int
 			info.BasicType = t
 			if tag != nil && tag.FieldType == "uint128" {
@@ -570,11 +841,7 @@ func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 			info.Length = tag.Size[0]
 
 			// Get element type info
-			elemTag := &sszTag{}
-			if len(tag.Size) > 1 {
-				elemTag.Size = tag.Size[1:]
-			}
-			elemInfo, err := GetTypeInfo(t.Elem(), elemTag)
+			elemInfo, err := GetTypeInfo(t.Elem(), tag.elementTag())
 			if err != nil {
 				return nil, err
 			}
@@ -601,11 +868,7 @@ func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 			}
 
 			// Get element type info with remaining size dimensions
-			elemTag := &sszTag{}
-			if len(tag.Size) > 1 {
-				elemTag.Size = tag.Size[1:]
-			}
-			elemInfo, err := GetTypeInfo(t.Elem(), elemTag)
+			elemInfo, err := GetTypeInfo(t.Elem(), tag.elementTag())
 			if err != nil {
 				return nil, err
 			}
@@ -617,14 +880,20 @@ func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 				info.BitLength = tag.MaxList
 			} else {
 				info.Type = ssz.TypeList
+				info.Progressive = tag != nil && tag.FieldType == "progressive-list"
+				info.ByteList = tag != nil && tag.FieldType == "bytelist"
 			}
 			info.FixedSize = -1
 			if tag != nil {
 				info.Length = tag.MaxList // Max length
 			}
 
-			// Get element type info
-			elemInfo, err := GetTypeInfo(t.Elem(), nil)
+			// Get element type info, keeping inner limits for lists of lists
+			var elemTag *sszTag
+			if tag != nil && len(tag.Max) > 1 {
+				elemTag = tag.elementTag()
+			}
+			elemInfo, err := GetTypeInfo(t.Elem(), elemTag)
 			if err != nil {
 				return nil, err
 			}
@@ -632,59 +901,102 @@ func parseTypeInfo(t reflect.Type, tag *sszTag) (*TypeInfo, error) {
 		}
 
 	case reflect.Struct:
-		info.Type = ssz.TypeContainer
-
-		// Parse struct fields
-		fields := make([]FieldInfo, 0, t.NumField())
-		fixedOffset := 0
-		hasVariable := false
-
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
+		if t == timeType {
+			// time.Time as unix seconds, see timeToUnix
+			info.Type = ssz.TypeUint64
+			info.BasicType = t
+			info.FixedSize = 8
+			break
+		}
+		if t == unionType {
+			// Union options are resolved from the value at runtime
+			info.Type = ssz.TypeUnion
+			info.FixedSize = -1
+			break
+		}
 
-			// Parse field tags
-			fieldTag, err := parseSSZTags(field)
-			if err != nil {
-				return nil, err
+		// The layout of a struct does not depend on the field holding it, so structs nested in
+		// containers reuse the type information cached for them, e.g. by PrecacheAll
+		if tag != nil {
+			if cached, ok := cachedTypeInfo(t); ok {
+				if cached.Wrapped != nil {
+					return cached, nil
+				}
+				nested := *cached
+				nested.Tag = tag
+				return &nested, nil
 			}
+		}
 
-			// Skip ignored fields
-			if fieldTag.Skip || !field.IsExported() {
-				continue
-			}
+		info.Type = ssz.TypeContainer
 
-			// Get field type info
-			fieldTypeInfo, err := GetTypeInfo(field.Type, fieldTag)
-			if err != nil {
-				return nil, err
-			}
+		// Parse struct fields, with the fields of inlined structs in their place
+		fields, transparent, err := containerFields(t, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkFieldNames(t, fields); err != nil {
+			return nil, err
+		}
+		fixedOffset := 0
+		hasVariable := false
 
-			fieldInfo := FieldInfo{
-				Index: i,
-				Name:  field.Name,
-				Type:  fieldTypeInfo,
-			}
+		for i := range fields {
+			fieldInfo := &fields[i]
 
 			// Calculate offset
-			if fieldTypeInfo.IsVariable {
+			if fieldInfo.Type.IsVariable {
 				fieldInfo.Offset = -1
 				fixedOffset += 4 // Offset pointer
 				hasVariable = true
 			} else {
 				fieldInfo.Offset = fixedOffset
-				fixedOffset += fieldTypeInfo.FixedSize
+				fixedOffset += fieldInfo.Type.FixedSize
 			}
+		}
 
-			fields = append(fields, fieldInfo)
+		if transparent {
+			// Wrapper structs take the type of their field
+			if len(fields) != 1 {
+				return nil, fmt.Errorf("struct %v: ssz tag option 'transparent' requires exactly one ssz field, got %d", t, len(fields))
+			}
+			wrapped := *fields[0].Type
+			wrapped.Wrapped = &fields[0]
+			return &wrapped, nil
+		}
+		if len(fields) == 0 {
+			// Unexported and skipped fields are not counted, see ErrEmptyContainer
+			return nil, fmt.Errorf("struct %v: %w", t, ErrEmptyContainer)
 		}
 
 		info.Fields = fields
+		info.fieldOffsets = containerFieldOffsets(fields)
 		if hasVariable {
 			info.FixedSize = -1
 		} else {
 			info.FixedSize = fixedOffset
 		}
 
+	case reflect.Interface:
+		// Interfaces are unions of the types registered for them, resolved from the value
+		if !isUnionInterface(t) {
+			return nil, fmt.Errorf("unsupported type for SSZ: %v has no registered union members, see RegisterUnionMember", t)
+		}
+		info.Type = ssz.TypeUnion
+		info.BasicType = t
+		info.FixedSize = -1
+
+	case reflect.Map:
+		// Maps take the type of the list of their entries
+		kv, err := kvListOf(t, tag)
+		if err != nil {
+			return nil, err
+		}
+		*info = *kv.info
+		info.Tag = tag
+		info.kvlist = kv
+		return info, nil
+
 	default:
 		return nil, fmt.Errorf("unsupported type for SSZ: %v", t)
 	}
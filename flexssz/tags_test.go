@@ -0,0 +1,54 @@
+package flexssz
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagsTestPreset holds the limits of the preset types below, like a spec config switched at runtime
+var tagsTestPreset = struct{ maxBalances, rootsSize int }{4, 2}
+
+type tagsTestBalances []uint64
+
+func (tagsTestBalances) SSZMax() int { return tagsTestPreset.maxBalances }
+
+type tagsTestRoots [][32]byte
+
+func (tagsTestRoots) SSZSize() int { return tagsTestPreset.rootsSize }
+
+type tagsTestState struct {
+	Balances tagsTestBalances
+	Roots    tagsTestRoots
+}
+
+func TestResetTypeCache(t *testing.T) {
+	prev := tagsTestPreset
+	t.Cleanup(func() {
+		tagsTestPreset = prev
+		ResetTypeCache()
+	})
+
+	state := &tagsTestState{Balances: make(tagsTestBalances, 6), Roots: make(tagsTestRoots, 3)}
+	_, err := Marshal(state)
+	require.Error(t, err)
+
+	// Limits are cached until the cache is reset
+	tagsTestPreset.maxBalances, tagsTestPreset.rootsSize = 8, 3
+	_, err = Marshal(state)
+	require.Error(t, err)
+
+	gen := TypeCacheGeneration()
+	ResetTypeCache()
+	assert.Equal(t, gen+1, TypeCacheGeneration())
+	enc, err := Marshal(state)
+	require.NoError(t, err)
+	assert.Len(t, enc, 4+3*32+6*8)
+
+	info, err := GetTypeInfo(reflect.TypeFor[tagsTestState](), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 8, info.Fields[0].Type.Length)
+	assert.Equal(t, 3, info.Fields[1].Type.Length)
+}
@@ -0,0 +1,146 @@
+package flexssz
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type transparentTestGwei struct {
+	Value uint64 `ssz:",transparent"`
+}
+
+func (g transparentTestGwei) Eth() uint64 {
+	return g.Value / 1e9
+}
+
+type transparentTestRoot struct {
+	cached bool
+	Root   [32]byte `ssz:",transparent"`
+}
+
+type transparentTestBits struct {
+	Bits []byte `ssz:"bitlist,transparent" ssz-max:"64"`
+}
+
+type transparentTestCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type transparentTestFinalized struct {
+	Checkpoint transparentTestCheckpoint `ssz:",transparent"`
+}
+
+type transparentTestState struct {
+	Balance    transparentTestGwei
+	Balances   []transparentTestGwei `ssz-max:"16"`
+	Root       transparentTestRoot
+	Roots      []transparentTestRoot `ssz-max:"4"`
+	Bits       transparentTestBits
+	Pending    *transparentTestGwei
+	Finalized  transparentTestFinalized
+	Historical []transparentTestFinalized `ssz-max:"4"`
+}
+
+type transparentTestStateRaw struct {
+	Balance    uint64
+	Balances   []uint64 `ssz-max:"16"`
+	Root       [32]byte
+	Roots      [][32]byte `ssz-max:"4"`
+	Bits       []byte     `ssz:"bitlist" ssz-max:"64"`
+	Pending    uint64
+	Finalized  transparentTestCheckpoint
+	Historical []transparentTestCheckpoint `ssz-max:"4"`
+}
+
+func TestTransparent(t *testing.T) {
+	v := &transparentTestState{
+		Balance:    transparentTestGwei{32e9},
+		Balances:   []transparentTestGwei{{1}, {2}, {3}},
+		Root:       transparentTestRoot{Root: [32]byte{4}},
+		Roots:      []transparentTestRoot{{Root: [32]byte{5}}},
+		Bits:       transparentTestBits{[]byte{0x0b}},
+		Pending:    &transparentTestGwei{6},
+		Finalized:  transparentTestFinalized{transparentTestCheckpoint{Epoch: 7, Root: [32]byte{8}}},
+		Historical: []transparentTestFinalized{{transparentTestCheckpoint{Epoch: 9}}},
+	}
+	raw := &transparentTestStateRaw{
+		Balance:    32e9,
+		Balances:   []uint64{1, 2, 3},
+		Root:       [32]byte{4},
+		Roots:      [][32]byte{{5}},
+		Bits:       []byte{0x0b},
+		Pending:    6,
+		Finalized:  transparentTestCheckpoint{Epoch: 7, Root: [32]byte{8}},
+		Historical: []transparentTestCheckpoint{{Epoch: 9}},
+	}
+
+	encoded, err := Marshal(v)
+	require.NoError(t, err)
+	rawEncoded, err := Marshal(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawEncoded, encoded)
+
+	var decoded transparentTestState
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	assert.Equal(t, v, &decoded)
+
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	rawRoot, err := HashTreeRoot(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawRoot, root)
+
+	data, err := MarshalJSON(v)
+	require.NoError(t, err)
+	rawData, err := MarshalJSON(raw)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(rawData), string(data))
+
+	var fromJSON transparentTestState
+	require.NoError(t, UnmarshalJSON(data, &fromJSON))
+	assert.Equal(t, v, &fromJSON)
+}
+
+func TestTransparent_Root(t *testing.T) {
+	g := transparentTestGwei{5}
+	encoded, err := Marshal(&g)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{5, 0, 0, 0, 0, 0, 0, 0}, encoded)
+
+	var decoded transparentTestGwei
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	assert.Equal(t, g, decoded)
+
+	root, err := HashTreeRoot(&g)
+	require.NoError(t, err)
+	assert.Equal(t, [32]byte{5}, root)
+
+	// A wrapped container is the container itself
+	f := transparentTestFinalized{transparentTestCheckpoint{Epoch: 1, Root: [32]byte{2}}}
+	encoded, err = Marshal(&f)
+	require.NoError(t, err)
+	rawEncoded, err := Marshal(&f.Checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, rawEncoded, encoded)
+
+	var decodedF transparentTestFinalized
+	require.NoError(t, Unmarshal(encoded, &decodedF))
+	assert.Equal(t, f, decodedF)
+}
+
+func TestTransparent_Errors(t *testing.T) {
+	_, err := GetTypeInfo(reflect.TypeFor[struct {
+		A uint64 `ssz:",transparent"`
+		B uint64
+	}](), nil)
+	assert.ErrorContains(t, err, "ssz tag option 'transparent' requires exactly one ssz field, got 2")
+
+	_, err = GetTypeInfo(reflect.TypeFor[struct {
+		A uint64 `ssz:",opaque"`
+	}](), nil)
+	assert.ErrorContains(t, err, `unknown ssz tag option "opaque"`)
+}
@@ -0,0 +1,132 @@
+package flexssz
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ListLimiter is implemented by slice types that carry their list limit, such as sszlist.List,
+// so they are lists without an ssz-max tag. The method is called on the zero value.
+type ListLimiter interface {
+	SSZMax() int
+}
+
+// VectorSizer is implemented by slice types that carry their vector length, such as
+// sszvec.Vector, so they are vectors without an ssz-size tag. The method is called on the zero
+// value.
+type VectorSizer interface {
+	SSZSize() int
+}
+
+// bitfield is the method set of the byte slice types of go-bitfield, such as bitfield.Bitlist
+// and bitfield.Bitvector512, which are recognized without importing the package. Their
+// memory is their SSZ encoding: bitlists hold the delimiter bit like bitlist fields do. The Len
+// of a zero bitvector is its number of bits, and the Len of a zero bitlist is 0.
+type bitfield interface {
+	BitAt(idx uint64) bool
+	Len() uint64
+}
+
+var (
+	listLimiterType = reflect.TypeFor[ListLimiter]()
+	vectorSizerType = reflect.TypeFor[VectorSizer]()
+	bitfieldType    = reflect.TypeFor[bitfield]()
+)
+
+// typeDims are the dimensions carried by a slice type, cached by typeDimensions
+type typeDims struct {
+	max, size int // 0 when the type does not carry them
+	bitvector int // Number of bits of go-bitfield bitvectors
+	bitlist   bool
+}
+
+var typeDimsCache sync.Map // reflect.Type -> typeDims
+
+// typeDimensions returns the list limit or vector length carried by slice type t
+func typeDimensions(t reflect.Type) typeDims {
+	if dims, ok := typeDimsCache.Load(t); ok {
+		return dims.(typeDims)
+	}
+	gen := typeCacheGeneration.Load()
+	var dims typeDims
+	if t.Implements(listLimiterType) {
+		dims.max = reflect.Zero(t).Interface().(ListLimiter).SSZMax()
+	} else if t.Implements(vectorSizerType) {
+		dims.size = reflect.Zero(t).Interface().(VectorSizer).SSZSize()
+	} else if t.Elem().Kind() == reflect.Uint8 && t.Implements(bitfieldType) {
+		if bits := reflect.Zero(t).Interface().(bitfield).Len(); bits > 0 {
+			dims.bitvector = int(bits)
+		} else {
+			dims.bitlist = true
+		}
+	}
+	storeTypeCache(gen, func() { typeDimsCache.Store(t, dims) })
+	return dims
+}
+
+// withTypeDimensions returns tag with its first ssz-max or ssz-size dimension taken from t, for
+// slice types that carry it, and with the bitfield type of go-bitfield types. Dimensions and
+// types set by the tag are kept. The tag is copied before it is
+// changed, since tags are shared with the fields and elements they were split from. Encoders
+// call it for every value, since element tags are split from field tags without looking at the
+// element types.
+func withTypeDimensions(t reflect.Type, tag *sszTag) *sszTag {
+	if t.Kind() != reflect.Slice {
+		return tag
+	}
+	dims := typeDimensions(t)
+	switch {
+	case dims.max > 0:
+		if tag != nil && tag.MaxList > 0 {
+			return tag
+		}
+		tag = copyTag(tag)
+		if len(tag.Max) == 0 {
+			tag.Max = []int{dims.max}
+		} else {
+			tag.Max = append([]int{dims.max}, tag.Max[1:]...)
+		}
+		tag.MaxList = dims.max
+		tag.IsVariable = true
+	case dims.size > 0:
+		if tag != nil && len(tag.Size) > 0 && tag.Size[0] >= 0 {
+			return tag
+		}
+		tag = copyTag(tag)
+		if len(tag.Size) == 0 {
+			tag.Size = []int{dims.size}
+		} else {
+			tag.Size = append([]int{dims.size}, tag.Size[1:]...)
+		}
+	case dims.bitvector > 0:
+		if tag != nil && !untypedSlice(tag) {
+			return tag
+		}
+		tag = copyTag(tag)
+		tag.FieldType = "bitvector"
+		tag.Size = []int{dims.bitvector}
+	case dims.bitlist:
+		if tag != nil && !untypedSlice(tag) {
+			return tag
+		}
+		// The limit still comes from ssz-max, since go-bitfield bitlists do not carry one
+		tag = copyTag(tag)
+		tag.FieldType = "bitlist"
+		tag.IsVariable = true
+	}
+	return tag
+}
+
+// untypedSlice reports whether tag leaves the SSZ type of a slice to be detected
+func untypedSlice(tag *sszTag) bool {
+	return tag.FieldType == "" || tag.FieldType == "list" || tag.FieldType == "vector"
+}
+
+// copyTag returns a copy of tag, or an empty tag for nil
+func copyTag(tag *sszTag) *sszTag {
+	if tag == nil {
+		return &sszTag{}
+	}
+	c := *tag
+	return &c
+}
@@ -0,0 +1,62 @@
+package flexssz
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/holiman/uint256"
+)
+
+// Go types with special SSZ handling. These are precalculated to avoid reflection overhead,
+// and every file in the package must compare against these rather than define its own.
+var (
+	uint256Type = reflect.TypeOf(uint256.Int{})
+	unionType   = reflect.TypeOf(Union{})
+	timeType    = reflect.TypeOf(time.Time{})
+
+	marshalerType   = reflect.TypeFor[Marshaler]()
+	unmarshalerType = reflect.TypeFor[Unmarshaler]()
+	hasherType      = reflect.TypeFor[Hasher]()
+)
+
+// IsUint256Type reports whether t is uint256.Int or *uint256.Int, which flexssz encodes as
+// uint256 (or uint128 with an `ssz:"uint128"` tag) instead of as a vector of four uint64s.
+func IsUint256Type(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == uint256Type
+}
+
+// time.Time fields tagged `ssz:"uint64,unix"` are encoded as uint64 seconds since the unix
+// epoch, so they serialize and hash like the raw uint64. Sub-second precision is dropped, and
+// decoded times are in UTC. The zero time.Time encodes as 0 and 0 decodes to the zero
+// time.Time, so unset timestamps round trip.
+
+// timeToUnix returns the uint64 encoding of t
+func timeToUnix(t time.Time) (uint64, error) {
+	if t.IsZero() {
+		return 0, nil
+	}
+	sec := t.Unix()
+	if sec < 0 {
+		return 0, fmt.Errorf("time %v is before the unix epoch", t)
+	}
+	return uint64(sec), nil
+}
+
+// unixToTime returns the time.Time encoded as sec
+func unixToTime(sec uint64) (time.Time, error) {
+	if sec == 0 {
+		return time.Time{}, nil
+	}
+	if sec > math.MaxInt64 {
+		return time.Time{}, fmt.Errorf("unix time %d overflows time.Time", sec)
+	}
+	return time.Unix(int64(sec), 0).UTC(), nil
+}
@@ -0,0 +1,218 @@
+package flexssz
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsUint256Type(t *testing.T) {
+	assert.True(t, IsUint256Type(reflect.TypeOf(uint256.Int{})))
+	assert.True(t, IsUint256Type(reflect.TypeOf(&uint256.Int{})))
+	assert.False(t, IsUint256Type(reflect.TypeOf([4]uint64{})))
+	assert.False(t, IsUint256Type(reflect.TypeOf(uint64(0))))
+	assert.False(t, IsUint256Type(nil))
+}
+
+type timeTestGenesis struct {
+	GenesisTime time.Time  `ssz:"uint64,unix"`
+	Deadline    *time.Time `ssz:"uint64,unix"`
+	Root        [32]byte
+	Extra       []byte `ssz-max:"8"`
+}
+
+type timeTestRaw struct {
+	GenesisTime uint64
+	Deadline    uint64
+	Root        [32]byte
+	Extra       []byte `ssz-max:"8"`
+}
+
+func TestTimeUnix(t *testing.T) {
+	genesis := time.Date(2020, 12, 1, 12, 0, 23, 0, time.UTC)
+	deadline := time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	v := &timeTestGenesis{GenesisTime: genesis, Deadline: &deadline, Root: [32]byte{1}, Extra: []byte{2}}
+	raw := &timeTestRaw{GenesisTime: uint64(genesis.Unix()), Deadline: uint64(deadline.Unix()), Root: [32]byte{1}, Extra: []byte{2}}
+
+	// Encoding and hash tree root match the raw uint64 seconds
+	encoded, err := Marshal(v)
+	require.NoError(t, err)
+	rawEncoded, err := Marshal(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawEncoded, encoded)
+
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	rawRoot, err := HashTreeRoot(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawRoot, root)
+
+	var decoded timeTestGenesis
+	require.NoError(t, UnmarshalStrict(encoded, &decoded))
+	assert.True(t, genesis.Equal(decoded.GenesisTime))
+	assert.Equal(t, time.UTC, decoded.GenesisTime.Location())
+	require.NotNil(t, decoded.Deadline)
+	assert.True(t, deadline.Equal(*decoded.Deadline))
+
+	jsonData, err := MarshalJSON(v)
+	require.NoError(t, err)
+	assert.Contains(t, string(jsonData), `"genesis_time":"1606824023"`)
+	var fromJSON timeTestGenesis
+	require.NoError(t, UnmarshalJSON(jsonData, &fromJSON))
+	assert.True(t, genesis.Equal(fromJSON.GenesisTime))
+
+	t.Run("sub-second precision is dropped", func(t *testing.T) {
+		v := &timeTestGenesis{GenesisTime: genesis.Add(999 * time.Millisecond), Deadline: &deadline}
+		encoded, err := Marshal(v)
+		require.NoError(t, err)
+		var decoded timeTestGenesis
+		require.NoError(t, Unmarshal(encoded, &decoded))
+		assert.True(t, genesis.Equal(decoded.GenesisTime))
+	})
+
+	t.Run("zero time is zero", func(t *testing.T) {
+		v := &timeTestGenesis{Deadline: &time.Time{}}
+		encoded, err := Marshal(v)
+		require.NoError(t, err)
+		assert.Equal(t, make([]byte, 16), encoded[:16])
+		var decoded timeTestGenesis
+		require.NoError(t, Unmarshal(encoded, &decoded))
+		assert.True(t, decoded.GenesisTime.IsZero())
+		assert.True(t, decoded.Deadline.IsZero())
+	})
+
+	t.Run("times before the epoch are rejected", func(t *testing.T) {
+		before := time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)
+		_, err := Marshal(&timeTestGenesis{GenesisTime: before, Deadline: &deadline})
+		assert.ErrorContains(t, err, "before the unix epoch")
+		_, err = HashTreeRoot(&timeTestGenesis{GenesisTime: before, Deadline: &deadline})
+		assert.ErrorContains(t, err, "before the unix epoch")
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		raw := &timeTestRaw{GenesisTime: math.MaxUint64}
+		encoded, err := Marshal(raw)
+		require.NoError(t, err)
+		var decoded timeTestGenesis
+		assert.ErrorContains(t, Unmarshal(encoded, &decoded), "overflows time.Time")
+	})
+}
+
+func TestTimeUnix_Tags(t *testing.T) {
+	type untagged struct {
+		T time.Time
+	}
+	_, err := Marshal(&untagged{})
+	assert.ErrorContains(t, err, "time.Time requires ssz tag 'uint64,unix'")
+
+	type wrongType struct {
+		T time.Time `ssz:"uint32,unix"`
+	}
+	_, err = Marshal(&wrongType{})
+	assert.ErrorContains(t, err, "time.Time requires ssz tag 'uint64,unix', got 'uint32'")
+
+	type notTime struct {
+		T uint64 `ssz:"uint64,unix"`
+	}
+	_, err = Marshal(&notTime{})
+	assert.ErrorContains(t, err, "ssz tag option 'unix' requires time.Time or *time.Time type")
+
+	type unknownOption struct {
+		T uint64 `ssz:"uint64,seconds"`
+	}
+	_, err = Marshal(&unknownOption{})
+	assert.ErrorContains(t, err, `unknown ssz tag option "seconds"`)
+}
+
+type signedTestValue struct {
+	Delta   int64    `ssz:"int64"`
+	Offset  int32    `ssz:"int32"`
+	Pointer *int64   `ssz:"int64"`
+	Deltas  []int64  `ssz:"int64" ssz-max:"8"`
+	Pair    [2]int32 `ssz:"int32"`
+}
+
+type signedTestRaw struct {
+	Delta   uint64
+	Offset  uint32
+	Pointer uint64
+	Deltas  []uint64 `ssz-max:"8"`
+	Pair    [2]uint32
+}
+
+func TestSignedIntegers(t *testing.T) {
+	pointer := int64(math.MinInt64)
+	v := &signedTestValue{Delta: -1, Offset: -2, Pointer: &pointer, Deltas: []int64{-3, 4}, Pair: [2]int32{math.MinInt32, math.MaxInt32}}
+	raw := &signedTestRaw{Delta: math.MaxUint64, Offset: math.MaxUint32 - 1, Pointer: 1 << 63, Deltas: []uint64{math.MaxUint64 - 2, 4}, Pair: [2]uint32{1 << 31, 1<<31 - 1}}
+
+	// Encoding and hash tree root are those of the unsigned integers of the same bits
+	encoded, err := Marshal(v)
+	require.NoError(t, err)
+	rawEncoded, err := Marshal(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawEncoded, encoded)
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe, 0xff, 0xff, 0xff}, encoded[:12])
+	sized, err := MarshalAppend(nil, v)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, sized)
+
+	root, err := HashTreeRoot(v)
+	require.NoError(t, err)
+	rawRoot, err := HashTreeRoot(raw)
+	require.NoError(t, err)
+	assert.Equal(t, rawRoot, root)
+
+	var decoded signedTestValue
+	require.NoError(t, UnmarshalStrict(encoded, &decoded))
+	assert.Equal(t, v, &decoded)
+
+	jsonData, err := MarshalJSON(v)
+	require.NoError(t, err)
+	assert.Contains(t, string(jsonData), `"delta":"-1"`)
+	var fromJSON signedTestValue
+	require.NoError(t, UnmarshalJSON(jsonData, &fromJSON))
+	assert.Equal(t, v, &fromJSON)
+}
+
+func TestSignedIntegers_Tags(t *testing.T) {
+	type untagged struct {
+		X int64
+	}
+	_, err := Marshal(&untagged{})
+	assert.ErrorContains(t, err, "signed type int64 requires ssz tag 'int64'")
+
+	type untaggedList struct {
+		X []int32 `ssz-max:"4"`
+	}
+	_, err = Marshal(&untaggedList{})
+	assert.ErrorContains(t, err, "signed type []int32 requires ssz tag 'int32'")
+
+	type wrongWidth struct {
+		X int32 `ssz:"int64"`
+	}
+	_, err = Marshal(&wrongWidth{})
+	assert.ErrorContains(t, err, "ssz tag 'int64' requires Go type int64, got int32")
+
+	type unsigned struct {
+		X uint64 `ssz:"int64"`
+	}
+	var decoded unsigned
+	assert.ErrorContains(t, Unmarshal(make([]byte, 8), &decoded), "ssz tag 'int64' requires Go type int64, got uint64")
+
+	type signedAsUnsigned struct {
+		X int64 `ssz:"uint64"`
+	}
+	_, err = Marshal(&signedAsUnsigned{})
+	assert.ErrorContains(t, err, "requires ssz tag 'int64'")
+
+	type plainInt struct {
+		X int `ssz:"int64"`
+	}
+	_, err = Marshal(&plainInt{})
+	assert.ErrorContains(t, err, "unsupported signed type int")
+}
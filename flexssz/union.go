@@ -0,0 +1,83 @@
+package flexssz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/gfx-labs/ssz/merkle_tree"
+)
+
+// Union represents an SSZ Union[...] value. Selector picks the active option and
+// Value holds the value of that option. A nil Value is the None option, which the
+// spec only allows at selector 0.
+//
+// Since the options of a union are only known at runtime, Value must be a type whose
+// SSZ shape can be derived without struct tags (basic types, arrays and structs). Union
+// values are only hashed; fields typed as an interface are unions that are also encoded and
+// decoded, see RegisterUnionMember.
+type Union struct {
+	Selector uint8
+	Value    any
+}
+
+// maxUnionSelector is the largest selector allowed by the spec
+const maxUnionSelector = 127
+
+// mixInSelector implements mix_in_selector from the SSZ spec
+func mixInSelector(root [32]byte, selector uint8) [32]byte {
+	var selectorChunk [32]byte
+	binary.LittleEndian.PutUint64(selectorChunk[:8], uint64(selector))
+	return merkle_tree.Sha256(root[:], selectorChunk[:])
+}
+
+// hashTreeRootUnion calculates mix_in_selector(hash_tree_root(value), selector)
+func hashTreeRootUnion(v reflect.Value, cache *HashCache) ([32]byte, error) {
+	if v.Kind() == reflect.Interface {
+		// Interface fields hold one of their registered members, see RegisterUnionMember
+		selector, value, info, err := interfaceOption(v)
+		if err != nil || !value.IsValid() {
+			return mixInSelector([32]byte{}, 0), err
+		}
+		root, err := hashTreeRoot(value, info, cache)
+		if err != nil {
+			return [32]byte{}, fmt.Errorf("error hashing union value: %w", err)
+		}
+		return mixInSelector(root, selector), nil
+	}
+	if v.Type() != unionType {
+		return [32]byte{}, fmt.Errorf("invalid type for union: %v", v.Type())
+	}
+	u := v.Interface().(Union)
+
+	if u.Selector > maxUnionSelector {
+		return [32]byte{}, fmt.Errorf("union selector %d exceeds maximum %d", u.Selector, maxUnionSelector)
+	}
+
+	// None is represented by a zero chunk and is only valid as the first option
+	if u.Value == nil {
+		if u.Selector != 0 {
+			return [32]byte{}, fmt.Errorf("union selector %d has no value, None is only allowed at selector 0", u.Selector)
+		}
+		return mixInSelector([32]byte{}, 0), nil
+	}
+
+	rv := reflect.ValueOf(u.Value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return [32]byte{}, fmt.Errorf("union selector %d has a nil pointer value", u.Selector)
+		}
+		rv = rv.Elem()
+	}
+
+	typeInfo, err := GetTypeInfo(rv.Type(), nil)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("error getting type info for union value: %w", err)
+	}
+
+	root, err := hashTreeRoot(rv, typeInfo, cache)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("error hashing union value: %w", err)
+	}
+	return mixInSelector(root, u.Selector), nil
+}
@@ -0,0 +1,216 @@
+package flexssz
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Fields typed as an interface are unions of the concrete types registered for the interface
+// with RegisterUnionMember, so one struct can hold any fork of a payload:
+//
+//	type ExecutionPayload interface{ isExecutionPayload() }
+//
+//	func init() {
+//		iface := reflect.TypeOf((*ExecutionPayload)(nil)).Elem()
+//		flexssz.RegisterUnionMember(iface, 1, reflect.TypeOf(&PayloadBellatrix{}))
+//		flexssz.RegisterUnionMember(iface, 2, reflect.TypeOf(&PayloadCapella{}))
+//	}
+//
+// The field is encoded as the selector of the type of its value followed by the encoding of
+// the value, and decoded into a new value of the type registered at the selector. A nil value
+// is the None option, encoded as selector 0 alone, unless a type is registered at selector 0.
+
+// unionMembers are the concrete types registered for an interface
+type unionMembers struct {
+	bySelector map[uint8]reflect.Type
+	byType     map[reflect.Type]uint8
+}
+
+var (
+	unionRegistry      = make(map[reflect.Type]*unionMembers)
+	unionRegistryMutex sync.RWMutex
+)
+
+// RegisterUnionMember registers concrete, which must implement the interface type iface, as the
+// option at selector of the unions that fields typed as iface are encoded as. Registering the
+// same type at the same selector again does nothing. It panics when iface is not an interface,
+// the selector exceeds 127 or is taken by another type, or concrete is registered at another
+// selector, so it is meant to be called from init functions.
+func RegisterUnionMember(iface reflect.Type, selector uint8, concrete reflect.Type) {
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("flexssz: RegisterUnionMember requires an interface type, got %v", iface))
+	}
+	if selector > maxUnionSelector {
+		panic(fmt.Sprintf("flexssz: union selector %d exceeds maximum %d", selector, maxUnionSelector))
+	}
+	if concrete.Kind() == reflect.Interface || !concrete.Implements(iface) {
+		panic(fmt.Sprintf("flexssz: %v is not a concrete type implementing %v", concrete, iface))
+	}
+
+	unionRegistryMutex.Lock()
+	defer unionRegistryMutex.Unlock()
+	members, ok := unionRegistry[iface]
+	if !ok {
+		members = &unionMembers{bySelector: make(map[uint8]reflect.Type), byType: make(map[reflect.Type]uint8)}
+		unionRegistry[iface] = members
+	}
+	if t, ok := members.bySelector[selector]; ok && t != concrete {
+		panic(fmt.Sprintf("flexssz: selector %d of %v is already registered to %v", selector, iface, t))
+	}
+	if s, ok := members.byType[concrete]; ok && s != selector {
+		panic(fmt.Sprintf("flexssz: %v is already registered to %v at selector %d", concrete, iface, s))
+	}
+	members.bySelector[selector] = concrete
+	members.byType[concrete] = selector
+}
+
+// isUnionInterface reports whether t is an interface with registered union members
+func isUnionInterface(t reflect.Type) bool {
+	if t.Kind() != reflect.Interface {
+		return false
+	}
+	unionRegistryMutex.RLock()
+	defer unionRegistryMutex.RUnlock()
+	_, ok := unionRegistry[t]
+	return ok
+}
+
+// unionMember returns the type registered for iface at selector
+func unionMember(iface reflect.Type, selector uint8) (reflect.Type, bool) {
+	unionRegistryMutex.RLock()
+	defer unionRegistryMutex.RUnlock()
+	members, ok := unionRegistry[iface]
+	if !ok {
+		return nil, false
+	}
+	t, ok := members.bySelector[selector]
+	return t, ok
+}
+
+// unionMemberTypes returns the types registered for iface, ordered by selector
+func unionMemberTypes(iface reflect.Type) []reflect.Type {
+	unionRegistryMutex.RLock()
+	defer unionRegistryMutex.RUnlock()
+	members, ok := unionRegistry[iface]
+	if !ok {
+		return nil
+	}
+	selectors := make([]uint8, 0, len(members.bySelector))
+	for selector := range members.bySelector {
+		selectors = append(selectors, selector)
+	}
+	slices.Sort(selectors)
+	types := make([]reflect.Type, len(selectors))
+	for i, selector := range selectors {
+		types[i] = members.bySelector[selector]
+	}
+	return types
+}
+
+// interfaceOption returns the selector of the value of v, an interface field, and the value
+// with its type info. The value is invalid for the None option.
+func interfaceOption(v reflect.Value) (uint8, reflect.Value, *TypeInfo, error) {
+	iface := v.Type()
+	if v.IsNil() {
+		if t, ok := unionMember(iface, 0); ok {
+			return 0, reflect.Value{}, nil, fmt.Errorf("nil %v has no selector, selector 0 is %v and not None", iface, t)
+		}
+		return 0, reflect.Value{}, nil, nil
+	}
+
+	value := v.Elem()
+	unionRegistryMutex.RLock()
+	selector, ok := uint8(0), false
+	if members := unionRegistry[iface]; members != nil {
+		selector, ok = members.byType[value.Type()]
+	}
+	unionRegistryMutex.RUnlock()
+	if !ok {
+		return 0, reflect.Value{}, nil, fmt.Errorf("%v is not a registered union member of %v", value.Type(), iface)
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return 0, reflect.Value{}, nil, fmt.Errorf("union selector %d has a nil pointer value", selector)
+		}
+		value = value.Elem()
+	}
+
+	info, err := GetTypeInfo(value.Type(), nil)
+	if err != nil {
+		return 0, reflect.Value{}, nil, fmt.Errorf("error getting type info for union value: %w", err)
+	}
+	return selector, value, info, nil
+}
+
+// sizeUnion returns the size of the encoding of v, an interface field
+func sizeUnion(v reflect.Value) (int, error) {
+	_, value, info, err := interfaceOption(v)
+	if err != nil || !value.IsValid() {
+		return 1, err
+	}
+	n, err := sizeRoot(value, info)
+	return 1 + n, err
+}
+
+// appendUnion appends the selector of v, an interface field, and the encoding of its value
+func appendUnion(dst []byte, v reflect.Value) ([]byte, error) {
+	selector, value, info, err := interfaceOption(v)
+	if err != nil {
+		return dst, err
+	}
+	dst = append(dst, selector)
+	if !value.IsValid() {
+		return dst, nil
+	}
+	return appendRoot(dst, value, info)
+}
+
+// decodeUnion decodes the rest of the decoder into v, an interface field, as a value of the
+// type registered at the selector it starts with
+func decodeUnion(d *Decoder, v reflect.Value, fieldInfo *FieldInfo) error {
+	if v.Kind() != reflect.Interface {
+		return fmt.Errorf("decoding unions is not supported")
+	}
+	selector, err := d.ReadUint8()
+	if err != nil {
+		return fmt.Errorf("error reading union selector: %w", err)
+	}
+	t, ok := unionMember(v.Type(), selector)
+	if !ok {
+		if selector != 0 {
+			return fmt.Errorf("union selector %d of %v is not registered", selector, v.Type())
+		}
+		if n := len(d.Remaining()); n > 0 {
+			return fmt.Errorf("union None option followed by %d bytes", n)
+		}
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	elemType := t
+	if t.Kind() == reflect.Ptr {
+		elemType = t.Elem()
+	}
+	info, err := GetTypeInfo(elemType, nil)
+	if err != nil {
+		return fmt.Errorf("error getting type info for union value: %w", err)
+	}
+	// Offsets in the value are relative to its start, after the selector
+	sub := d.sub(d.cur, len(d.xs))
+	d.cur = len(d.xs)
+	value := reflect.New(elemType)
+	if err := decodeValue(sub, value.Elem(), &FieldInfo{Name: fieldInfo.Name, Type: info}); err != nil {
+		return err
+	}
+	if n := len(sub.Remaining()); n > 0 {
+		return fmt.Errorf("union value of %v followed by %d bytes", t, n)
+	}
+	if t.Kind() == reflect.Ptr {
+		v.Set(value)
+	} else {
+		v.Set(value.Elem())
+	}
+	return nil
+}
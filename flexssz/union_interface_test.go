@@ -0,0 +1,188 @@
+package flexssz
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload interface{ isTestPayload() }
+
+type testPayloadV1 struct {
+	Number uint64
+	Hash   [32]byte
+}
+
+type testPayloadV2 struct {
+	Number       uint64
+	Transactions [][]byte `ssz-max:"16,64"`
+}
+
+type testBlobCount uint16
+
+func (*testPayloadV1) isTestPayload() {}
+func (*testPayloadV2) isTestPayload() {}
+func (testBlobCount) isTestPayload()  {}
+
+type testBlock struct {
+	Slot    uint64
+	Payload testPayload
+}
+
+type testBlocks struct {
+	Payloads []testPayload `ssz-max:"8"`
+}
+
+type testPayloadNoneless interface{ isTestPayloadNoneless() }
+
+func (*testPayloadV1) isTestPayloadNoneless() {}
+
+var (
+	testPayloadType         = reflect.TypeOf((*testPayload)(nil)).Elem()
+	testPayloadNonelessType = reflect.TypeOf((*testPayloadNoneless)(nil)).Elem()
+)
+
+func init() {
+	RegisterUnionMember(testPayloadType, 1, reflect.TypeOf(&testPayloadV1{}))
+	RegisterUnionMember(testPayloadType, 2, reflect.TypeOf(&testPayloadV2{}))
+	RegisterUnionMember(testPayloadType, 3, reflect.TypeOf(testBlobCount(0)))
+	RegisterUnionMember(testPayloadNonelessType, 0, reflect.TypeOf(&testPayloadV1{}))
+}
+
+func TestUnionInterface_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload testPayload
+		want    []byte
+	}{
+		{name: "none", payload: nil, want: []byte{0}},
+		{name: "fixed-size member", payload: &testPayloadV1{Number: 5, Hash: [32]byte{9}}},
+		{name: "variable-size member", payload: &testPayloadV2{Number: 6, Transactions: [][]byte{{1, 2}, {3}}}},
+		{name: "basic member", payload: testBlobCount(7), want: []byte{3, 7, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := testBlock{Slot: 1, Payload: tt.payload}
+			data, err := Marshal(&block)
+			require.NoError(t, err)
+
+			// The union is the only variable field: its offset is 12, followed by the selector
+			assert.Equal(t, uint32(12), order.Uint32(data[8:12]))
+			if tt.want != nil {
+				assert.Equal(t, tt.want, data[12:])
+			}
+			appended, err := MarshalAppend(nil, &block)
+			require.NoError(t, err)
+			assert.Equal(t, data, appended)
+			size, err := EncodedSize(&block)
+			require.NoError(t, err)
+			assert.Equal(t, len(data), size)
+
+			var decoded testBlock
+			require.NoError(t, UnmarshalStrict(data, &decoded))
+			assert.Equal(t, block, decoded)
+		})
+	}
+}
+
+func TestUnionInterface_HashTreeRoot(t *testing.T) {
+	payload := &testPayloadV1{Number: 5, Hash: [32]byte{9}}
+	root, err := HashTreeRoot(&testBlock{Slot: 1, Payload: payload})
+	require.NoError(t, err)
+	want, err := HashTreeRoot(&struct {
+		Slot    uint64
+		Payload Union
+	}{Slot: 1, Payload: Union{Selector: 1, Value: payload}})
+	require.NoError(t, err)
+	assert.Equal(t, want, root)
+
+	root, err = HashTreeRoot(&testBlock{Slot: 1})
+	require.NoError(t, err)
+	want, err = HashTreeRoot(&struct {
+		Slot    uint64
+		Payload Union
+	}{Slot: 1})
+	require.NoError(t, err)
+	assert.Equal(t, want, root)
+}
+
+func TestUnionInterface_List(t *testing.T) {
+	blocks := testBlocks{Payloads: []testPayload{
+		&testPayloadV1{Number: 1},
+		nil,
+		&testPayloadV2{Number: 2, Transactions: [][]byte{{4}}},
+	}}
+	data, err := Marshal(&blocks)
+	require.NoError(t, err)
+	var decoded testBlocks
+	require.NoError(t, Unmarshal(data, &decoded))
+	assert.Equal(t, blocks, decoded)
+
+	root, err := HashTreeRoot(&blocks)
+	require.NoError(t, err)
+	decodedRoot, err := HashTreeRoot(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, root, decodedRoot)
+}
+
+func TestUnionInterface_Generate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 20 {
+		var block testBlock
+		require.NoError(t, Generate(r, &block))
+		require.NotNil(t, block.Payload)
+		data, err := Marshal(&block)
+		require.NoError(t, err)
+		var decoded testBlock
+		require.NoError(t, Unmarshal(data, &decoded))
+		assert.Equal(t, block, decoded)
+	}
+}
+
+func TestUnionInterface_Errors(t *testing.T) {
+	type unregistered struct {
+		Payload interface{ Unregistered() }
+	}
+	type noneless struct {
+		Payload testPayloadNoneless
+	}
+	type payloadV3 struct {
+		testPayloadV1
+	}
+
+	t.Run("unregistered interface", func(t *testing.T) {
+		_, err := Marshal(&unregistered{})
+		assert.ErrorContains(t, err, "no registered union members")
+	})
+	t.Run("nil without None option", func(t *testing.T) {
+		_, err := Marshal(&noneless{})
+		assert.ErrorContains(t, err, "selector 0 is")
+		_, err = HashTreeRoot(&noneless{})
+		assert.ErrorContains(t, err, "selector 0 is")
+	})
+	t.Run("unregistered member", func(t *testing.T) {
+		_, err := Marshal(&testBlock{Payload: &payloadV3{}})
+		assert.ErrorContains(t, err, "is not a registered union member")
+	})
+	t.Run("unknown selector", func(t *testing.T) {
+		err := Unmarshal([]byte{1, 0, 0, 0, 0, 0, 0, 0, 12, 0, 0, 0, 9}, &testBlock{})
+		assert.ErrorContains(t, err, "union selector 9")
+	})
+	t.Run("trailing bytes", func(t *testing.T) {
+		err := Unmarshal([]byte{1, 0, 0, 0, 0, 0, 0, 0, 12, 0, 0, 0, 3, 7, 0, 1}, &testBlock{})
+		assert.ErrorContains(t, err, "followed by 1 bytes")
+		err = Unmarshal([]byte{1, 0, 0, 0, 0, 0, 0, 0, 12, 0, 0, 0, 0, 1}, &testBlock{})
+		assert.ErrorContains(t, err, "None option followed by 1 bytes")
+	})
+	t.Run("registration", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterUnionMember(reflect.TypeOf(testPayloadV1{}), 1, reflect.TypeOf(&testPayloadV1{})) })
+		assert.Panics(t, func() { RegisterUnionMember(testPayloadType, 128, reflect.TypeOf(&payloadV3{})) })
+		assert.Panics(t, func() { RegisterUnionMember(testPayloadType, 4, reflect.TypeOf(testPayloadV1{})) })
+		assert.Panics(t, func() { RegisterUnionMember(testPayloadType, 1, reflect.TypeOf(&payloadV3{})) })
+		assert.Panics(t, func() { RegisterUnionMember(testPayloadType, 4, reflect.TypeOf(&testPayloadV1{})) })
+		assert.NotPanics(t, func() { RegisterUnionMember(testPayloadType, 1, reflect.TypeOf(&testPayloadV1{})) })
+	})
+}
@@ -0,0 +1,98 @@
+package flexssz
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hashPair(a, b [32]byte) [32]byte {
+	return sha256.Sum256(append(a[:], b[:]...))
+}
+
+func selectorChunk(selector uint8) [32]byte {
+	var chunk [32]byte
+	chunk[0] = selector
+	return chunk
+}
+
+func TestHashTreeRootUnion(t *testing.T) {
+	t.Run("none option", func(t *testing.T) {
+		root, err := HashTreeRoot(Union{Selector: 0})
+		require.NoError(t, err)
+		assert.Equal(t, hashPair([32]byte{}, selectorChunk(0)), root)
+	})
+
+	t.Run("basic value", func(t *testing.T) {
+		root, err := HashTreeRoot(Union{Selector: 1, Value: uint64(0xdeadbeef)})
+		require.NoError(t, err)
+
+		var valueRoot [32]byte
+		binary.LittleEndian.PutUint64(valueRoot[:8], 0xdeadbeef)
+		assert.Equal(t, hashPair(valueRoot, selectorChunk(1)), root)
+	})
+
+	t.Run("container value", func(t *testing.T) {
+		type Option struct {
+			A uint64
+			B [32]byte
+		}
+		value := &Option{A: 7, B: [32]byte{1, 2, 3}}
+
+		valueRoot, err := HashTreeRoot(value)
+		require.NoError(t, err)
+
+		root, err := HashTreeRoot(&Union{Selector: 2, Value: value})
+		require.NoError(t, err)
+		assert.Equal(t, hashPair(valueRoot, selectorChunk(2)), root)
+	})
+
+	t.Run("union field in container", func(t *testing.T) {
+		type WithUnion struct {
+			Slot  uint64
+			Value Union
+		}
+		v := WithUnion{Slot: 3, Value: Union{Selector: 1, Value: uint32(9)}}
+
+		root, err := HashTreeRoot(v)
+		require.NoError(t, err)
+
+		var slotRoot, valueRoot [32]byte
+		binary.LittleEndian.PutUint64(slotRoot[:8], 3)
+		binary.LittleEndian.PutUint32(valueRoot[:4], 9)
+		assert.Equal(t, hashPair(slotRoot, hashPair(valueRoot, selectorChunk(1))), root)
+	})
+
+	t.Run("none at non-zero selector", func(t *testing.T) {
+		_, err := HashTreeRoot(Union{Selector: 1})
+		assert.ErrorContains(t, err, "None is only allowed at selector 0")
+	})
+
+	t.Run("selector out of range", func(t *testing.T) {
+		_, err := HashTreeRoot(Union{Selector: 128, Value: uint8(1)})
+		assert.ErrorContains(t, err, "exceeds maximum")
+	})
+}
+
+func TestUnionTypeInfo(t *testing.T) {
+	type WithUnion struct {
+		Value Union `ssz:"union"`
+	}
+
+	info, err := GetTypeInfo(unionType, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "union", string(info.Type))
+	assert.True(t, info.IsVariable)
+
+	info, err = GetTypeInfo(reflect.TypeOf(WithUnion{}), nil)
+	require.NoError(t, err)
+	require.Len(t, info.Fields, 1)
+	assert.Equal(t, -1, info.Fields[0].Offset)
+
+	_, err = Marshal(&WithUnion{})
+	assert.ErrorContains(t, err, "encoding unions is not supported")
+}
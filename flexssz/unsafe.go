@@ -0,0 +1,55 @@
+//go:build !purego && !tinygo && !wasm
+
+package flexssz
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// sliceBytes returns the memory of a slice or addressable array as bytes
+func sliceBytes(v reflect.Value) ([]byte, bool) {
+	switch v.Kind() {
+	case reflect.Slice:
+	case reflect.Array:
+		if !v.CanAddr() {
+			return nil, false
+		}
+		v = v.Slice(0, v.Len())
+	default:
+		return nil, false
+	}
+	n := v.Len() * int(v.Type().Elem().Size())
+	if n == 0 {
+		return nil, true
+	}
+	return unsafe.Slice((*byte)(v.UnsafePointer()), n), true
+}
+
+// byteArrayBytes returns the memory of an addressable array of bytes, or of byte arrays nested
+// to any depth such as [8192][32]byte, which Go lays out as its SSZ encoding, so it can be copied
+// in one go instead of a byte at a time. ok is false for other values, including arrays of types
+// that encode themselves.
+func byteArrayBytes(v reflect.Value) ([]byte, bool) {
+	if v.Kind() != reflect.Array || !v.CanAddr() || !isByteArray(v.Type()) {
+		return nil, false
+	}
+	n := int(v.Type().Size())
+	if n == 0 {
+		return nil, true
+	}
+	return unsafe.Slice((*byte)(v.Addr().UnsafePointer()), n), true
+}
+
+// chunkedToSingle returns a slice of [32]byte as a slice of bytes, sharing its memory
+func chunkedToSingle(xs [][32]byte) []byte {
+	if len(xs) == 0 {
+		return nil
+	}
+	return unsafe.Slice(&xs[0][0], len(xs)*32)
+}
+
+// copyToChunks copies data to the start of chunks
+func copyToChunks(chunks [][32]byte, data []byte) {
+	copy(chunkedToSingle(chunks), data)
+}
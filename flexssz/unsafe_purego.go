@@ -0,0 +1,36 @@
+//go:build purego || tinygo || wasm
+
+package flexssz
+
+import "reflect"
+
+// Portable versions of the helpers in unsafe.go, for targets without unsafe memory access. The
+// memory of values cannot be read as bytes, so lists of basic types and byte arrays are encoded,
+// decoded and hashed one value at a time.
+
+func sliceBytes(v reflect.Value) ([]byte, bool) {
+	return nil, false
+}
+
+func byteArrayBytes(v reflect.Value) ([]byte, bool) {
+	return nil, false
+}
+
+// chunkedToSingle copies a slice of [32]byte to a slice of bytes
+func chunkedToSingle(xs [][32]byte) []byte {
+	if len(xs) == 0 {
+		return nil
+	}
+	out := make([]byte, 0, len(xs)*32)
+	for i := range xs {
+		out = append(out, xs[i][:]...)
+	}
+	return out
+}
+
+// copyToChunks copies data to the start of chunks
+func copyToChunks(chunks [][32]byte, data []byte) {
+	for i := 0; i < len(chunks) && len(data) > 0; i++ {
+		data = data[copy(chunks[i][:], data):]
+	}
+}
@@ -0,0 +1,38 @@
+//go:build !purego && !tinygo && !wasm
+
+package flexssz
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasicBytes(t *testing.T) {
+	bs, ok := basicBytes(reflect.ValueOf([]uint64{1, 0x0102030405060708}))
+	require.True(t, ok)
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0, 8, 7, 6, 5, 4, 3, 2, 1}, bs)
+
+	bs, ok = basicBytes(reflect.ValueOf([]packTestGwei{0x0a0b}))
+	require.True(t, ok)
+	assert.Equal(t, []byte{0x0b, 0x0a, 0, 0, 0, 0, 0, 0}, bs)
+
+	bs, ok = basicBytes(reflect.ValueOf([]uint16{0x0102, 3}))
+	require.True(t, ok)
+	assert.Equal(t, []byte{2, 1, 3, 0}, bs)
+
+	// Arrays are only read in place when addressable
+	_, ok = basicBytes(reflect.ValueOf([2]uint32{}))
+	assert.False(t, ok)
+	bs, ok = basicBytes(reflect.ValueOf(&[2]uint32{5, 6}).Elem())
+	require.True(t, ok)
+	assert.Equal(t, []byte{5, 0, 0, 0, 6, 0, 0, 0}, bs)
+
+	_, ok = basicBytes(reflect.ValueOf([]uint256.Int{}))
+	assert.False(t, ok)
+	_, ok = basicBytes(reflect.ValueOf(uint64(1)))
+	assert.False(t, ok)
+}
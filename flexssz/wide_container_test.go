@@ -0,0 +1,210 @@
+package flexssz_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	dynssz "github.com/pk910/dynamic-ssz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wideFieldTypes are the field types of generated containers, with their tags: the fixed-size
+// types, then the variable-size ones. Vectors of basic types other than bytes are left out, since
+// dynamic-ssz hashes them without packing; the spectests cover them.
+var wideFieldTypes = []struct {
+	typ reflect.Type
+	tag reflect.StructTag
+}{
+	{reflect.TypeFor[uint8](), ""},
+	{reflect.TypeFor[uint16](), ""},
+	{reflect.TypeFor[uint32](), ""},
+	{reflect.TypeFor[uint64](), ""},
+	{reflect.TypeFor[bool](), ""},
+	{reflect.TypeFor[[32]byte](), ""},
+	{reflect.TypeFor[[4]byte](), ""},
+	{reflect.TypeFor[[]byte](), `ssz-max:"64"`},
+	{reflect.TypeFor[[]uint64](), `ssz-max:"16"`},
+	{reflect.TypeFor[[][]byte](), `ssz-max:"4,16"`},
+	{reflect.TypeFor[[][]byte](), `ssz-size:"?,8" ssz-max:"4"`},
+}
+
+// wideContainerType generates a container with n fields. variable is the chance of a
+// variable-size field, and depth the number of levels of nested containers below it.
+func wideContainerType(rng *rand.Rand, n int, variable float64, depth int) reflect.Type {
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		fields[i].Name = "F" + strconv.Itoa(i)
+		isVariable := rng.Float64() < variable
+		switch {
+		case depth > 0 && rng.Intn(10) == 0:
+			inner := wideContainerType(rng, 1+rng.Intn(12), variable, depth-1)
+			if isVariable {
+				fields[i].Type = reflect.SliceOf(inner)
+				fields[i].Tag = `ssz-max:"4"`
+			} else {
+				fields[i].Type = reflect.PointerTo(inner)
+			}
+		case isVariable:
+			ft := wideFieldTypes[7+rng.Intn(len(wideFieldTypes)-7)]
+			fields[i].Type, fields[i].Tag = ft.typ, ft.tag
+		default:
+			ft := wideFieldTypes[rng.Intn(7)]
+			fields[i].Type, fields[i].Tag = ft.typ, ft.tag
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// fillWide sets the fields of a generated container to random values within their limits
+func fillWide(rng *rand.Rand, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		limits := strings.Split(field.Tag.Get("ssz-max"), ",")
+		fillWideValue(rng, v.Field(i), limits)
+	}
+}
+
+func fillWideValue(rng *rand.Rand, v reflect.Value, limits []string) {
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(rng.Uint64())
+	case reflect.Bool:
+		v.SetBool(rng.Intn(2) == 1)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillWideValue(rng, v.Index(i), nil)
+		}
+	case reflect.Slice:
+		limit, _ := strconv.Atoi(limits[0])
+		n := rng.Intn(limit + 1)
+		if v.Type().Elem().Kind() == reflect.Slice && len(limits) == 1 {
+			// Lists of byte vectors, tagged ssz-size:"?,8"
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+			for i := 0; i < n; i++ {
+				v.Index(i).Set(reflect.ValueOf(randomBytes(rng, 8)))
+			}
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			fillWideValue(rng, v.Index(i), limits[1:])
+		}
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillWide(rng, v.Elem())
+	case reflect.Struct:
+		fillWide(rng, v)
+	}
+}
+
+func randomBytes(rng *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	rng.Read(b)
+	return b
+}
+
+// checkWideOffsets checks the offsets of the variable-size fields of the container in data:
+// the first points past the fixed part, and each is followed by the encoding of its field
+func checkWideOffsets(t *testing.T, data []byte, v reflect.Value, path string) {
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	info, err := flexssz.GetTypeInfo(v.Type(), nil)
+	require.NoError(t, err)
+
+	fixedSize := 0
+	for _, field := range info.Fields {
+		if field.Type.IsVariable {
+			fixedSize += flexssz.PtrSize
+		} else {
+			fixedSize += field.Type.FixedSize
+		}
+	}
+
+	pos, next := 0, fixedSize
+	for _, field := range info.Fields {
+		fv := v.Field(field.Index)
+		name := path + "." + field.Name
+		if !field.Type.IsVariable {
+			if fv.Kind() == reflect.Pointer {
+				checkWideOffsets(t, data[pos:pos+field.Type.FixedSize], fv, name)
+			}
+			pos += field.Type.FixedSize
+			continue
+		}
+		offset := int(binary.LittleEndian.Uint32(data[pos:]))
+		require.Equal(t, next, offset, "offset of %s", name)
+
+		// The size of the field is the size of a container holding only the field, less its offset
+		wrapper := reflect.New(reflect.StructOf([]reflect.StructField{v.Type().Field(field.Index)}))
+		wrapper.Elem().Field(0).Set(fv)
+		size, err := flexssz.EncodedSize(wrapper.Interface())
+		require.NoError(t, err)
+		size -= flexssz.PtrSize
+		if fv.Kind() == reflect.Pointer {
+			checkWideOffsets(t, data[offset:offset+size], fv, name)
+		}
+		next += size
+		pos += flexssz.PtrSize
+	}
+	require.Equal(t, fixedSize, pos, "fixed part of %s", path)
+	require.Equal(t, len(data), next, "size of %s", path)
+}
+
+func TestWideContainers(t *testing.T) {
+	ds := dynssz.NewDynSsz(nil)
+	tests := []struct {
+		fields   int
+		variable float64
+		depth    int
+	}{
+		{fields: 33, variable: 0},
+		{fields: 64, variable: 0.5},
+		{fields: 100, variable: 1},
+		{fields: 257, variable: 0.3, depth: 2},
+		{fields: 500, variable: 0.1, depth: 1},
+		{fields: 513, variable: 0},
+	}
+	for _, tt := range tests {
+		for seed := int64(1); seed <= 3; seed++ {
+			t.Run(fmt.Sprintf("fields=%d,variable=%v,depth=%d,seed=%d", tt.fields, tt.variable, tt.depth, seed), func(t *testing.T) {
+				rng := rand.New(rand.NewSource(seed))
+				typ := wideContainerType(rng, tt.fields, tt.variable, tt.depth)
+				value := reflect.New(typ)
+				fillWide(rng, value.Elem())
+
+				info, err := flexssz.GetTypeInfo(typ, nil)
+				require.NoError(t, err)
+				require.Len(t, info.Fields, tt.fields)
+
+				encoded, err := flexssz.Marshal(value.Interface())
+				require.NoError(t, err)
+				expected, err := ds.MarshalSSZ(value.Interface())
+				require.NoError(t, err)
+				require.Equal(t, expected, encoded)
+				checkWideOffsets(t, encoded, value, typ.Name())
+
+				appended, err := flexssz.MarshalAppend(nil, value.Interface())
+				require.NoError(t, err)
+				assert.Equal(t, encoded, appended)
+
+				root, err := flexssz.HashTreeRoot(value.Interface())
+				require.NoError(t, err)
+				expectedRoot, err := ds.HashTreeRoot(value.Interface())
+				require.NoError(t, err)
+				assert.Equal(t, expectedRoot, root)
+
+				decoded := reflect.New(typ)
+				require.NoError(t, flexssz.UnmarshalStrict(encoded, decoded.Interface()))
+				assert.Equal(t, value.Interface(), decoded.Interface())
+			})
+		}
+	}
+}
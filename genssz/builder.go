@@ -0,0 +1,128 @@
+package genssz
+
+import (
+	"fmt"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/gfx-labs/ssz"
+)
+
+// generateBuilder generates the builder of a type, with a method setting each field that checks
+// the value first, e.g. NewPenguinBuilder().Name(name).Identity(id).Build(). Values that do not
+// fit the field, like refs of the wrong size or bitvectors with bits set past their length, are
+// kept as the error of the builder, which Build returns. Vectors of other types than bytes have
+// no setter and are left zero.
+func generateBuilder(f *jen.File, structDef ssz.Field, schema *Schema, exts map[string]*externalType) error {
+	typeName := structDef.Name
+	builderName := typeName + "Builder"
+	refs := make(map[string]ssz.Field)
+	for _, s := range schema.Structs {
+		refs[s.Name] = s.ToSSZField()
+	}
+
+	f.Comment(fmt.Sprintf("%s sets the fields of a new %s one at a time, checking each value as it is", builderName, typeName))
+	f.Comment("set. The first invalid value is returned by Build, and later values are ignored.")
+	f.Type().Id(builderName).Struct(
+		jen.Id("obj").Id(typeName),
+		jen.Id("err").Error(),
+	)
+	f.Line()
+
+	f.Comment(fmt.Sprintf("New%s returns a builder of a zero %s", builderName, typeName))
+	f.Func().Id("New" + builderName).Params().Op("*").Id(builderName).Block(
+		jen.Return(jen.Op("&").Id(builderName).Values(jen.Dict{
+			jen.Id("obj"): jen.Id("New" + typeName).Call(),
+		})),
+	)
+	f.Line()
+
+	for _, field := range structDef.Children {
+		methodName := capitalizeFirst(field.Name)
+		if methodName == "Build" {
+			return fmt.Errorf("field %s conflicts with the Build method of %s", field.Name, builderName)
+		}
+		setterName := "Set" + methodName
+		fail := func(err jen.Code) jen.Code {
+			return jen.Id("b").Dot("err").Op("=").Qual("fmt", "Errorf").Call(jen.Lit(field.Name+": %w"), err)
+		}
+
+		var paramType jen.Code
+		var body []jen.Code
+		if ext := exts[field.Name]; ext != nil {
+			paramType = ext.typ()
+			if ext.codec == CodecSSZ {
+				body = append(body, jen.If(jen.Err().Op(":=").Id("b").Dot("obj").Dot(setterName).Call(jen.Id("v")), jen.Err().Op("!=").Nil()).Block(
+					fail(jen.Err()),
+					jen.Return(jen.Id("b")),
+				))
+			} else {
+				body = append(body, jen.Id("b").Dot("obj").Dot(setterName).Call(jen.Id("v")))
+			}
+		} else {
+			switch field.Type {
+			case ssz.TypeUint8:
+				paramType = jen.Uint8()
+			case ssz.TypeUint16:
+				paramType = jen.Uint16()
+			case ssz.TypeUint32:
+				paramType = jen.Uint32()
+			case ssz.TypeUint64:
+				paramType = jen.Uint64()
+			case ssz.TypeBoolean:
+				paramType = jen.Bool()
+			case ssz.TypeVector:
+				if len(field.Children) == 0 || field.Children[0].Type != ssz.TypeUint8 {
+					continue
+				}
+				paramType = jen.Index(jen.Lit(int(field.Size))).Byte()
+			case ssz.TypeBitVector:
+				byteSize := int((field.Size + 7) / 8)
+				paramType = jen.Index(jen.Lit(byteSize)).Byte()
+				// Bits past the length of the bitvector must be zero
+				if rem := field.Size % 8; rem != 0 {
+					body = append(body, jen.If(jen.Id("v").Index(jen.Lit(byteSize-1)).Op(">>").Lit(int(rem)).Op("!=").Lit(0)).Block(
+						fail(jen.Qual("fmt", "Errorf").Call(jen.Lit(fmt.Sprintf("bits set past the length of bitvector[%d]", field.Size)))),
+						jen.Return(jen.Id("b")),
+					))
+				}
+			case ssz.TypeRef:
+				size, err := getFieldSize(field, refs)
+				if err != nil {
+					return err
+				}
+				paramType = refType(field.Ref)
+				body = append(body, jen.If(jen.Len(jen.Id("v")).Op("!=").Lit(size)).Block(
+					fail(jen.Qual("github.com/gfx-labs/ssz", "NewErrSizeMismatch").Call(jen.Lit(size), jen.Len(jen.Id("v")))),
+					jen.Return(jen.Id("b")),
+				))
+			default:
+				return fmt.Errorf("unsupported field type %s for builder", field.Type)
+			}
+			body = append(body, jen.Id("b").Dot("obj").Dot(setterName).Call(jen.Id("v")))
+		}
+
+		f.Comment(fmt.Sprintf("%s sets the %s field", methodName, field.Name))
+		f.Func().Params(jen.Id("b").Op("*").Id(builderName)).Id(methodName).Params(jen.Id("v").Add(paramType)).Op("*").Id(builderName).BlockFunc(func(g *jen.Group) {
+			g.If(jen.Id("b").Dot("err").Op("!=").Nil()).Block(
+				jen.Return(jen.Id("b")),
+			)
+			for _, stmt := range body {
+				g.Add(stmt)
+			}
+			g.Return(jen.Id("b"))
+		})
+		f.Line()
+	}
+
+	// The builder keeps its bytes, so the value is copied for later setters not to change it
+	f.Comment(fmt.Sprintf("Build returns a copy of the %s, or the first invalid value set on the builder. The", typeName))
+	f.Comment("builder can keep being used, and setters called after Build do not change the values it returned.")
+	f.Func().Params(jen.Id("b").Op("*").Id(builderName)).Id("Build").Params().Params(jen.Id(typeName), jen.Error()).Block(
+		jen.If(jen.Id("b").Dot("err").Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Id("b").Dot("err")),
+		),
+		jen.Return(jen.Id(typeName).Call(jen.Qual("bytes", "Clone").Call(jen.Id("b").Dot("obj"))), jen.Nil()),
+	)
+	f.Line()
+	return nil
+}
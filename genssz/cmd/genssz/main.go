@@ -4,14 +4,29 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/gfx-labs/ssz/genssz"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		importMain(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "vet" {
+		vetMain(os.Args[2:])
+		return
+	}
+
 	var (
-		output = flag.String("output", "", "Output Go file")
+		output = flag.String("output", "", "Output Go file, or directory with -split")
+		split  = flag.Bool("split", false, "Write one file per type to the -output directory, with a manifest")
+		tests  = flag.Bool("with-tests", false, "Also write tests checking the generated types against flexssz, to the _test.go file of -output")
+		deps   = flag.String("deps", "", "Comma separated schemas of the packages that refs such as common.Checkpoint refer to")
+		unsafe = flag.Bool("unsafe-reads", false, "Read numeric fields with the unsafe readers of the ssz package, disabled by the ssz_purego build tag")
+		build  = flag.Bool("builders", false, "Also generate a builder for each type, checking each value as it is set")
 	)
 	flag.Parse()
 
@@ -19,7 +34,10 @@ func main() {
 	inputFiles := flag.Args()
 	
 	if len(inputFiles) == 0 || *output == "" {
-		fmt.Fprintf(os.Stderr, "Usage: genssz -output generated.go schema1.yml schema2.yml ...\n")
+		fmt.Fprintf(os.Stderr, "Usage: genssz [-with-tests] [-unsafe-reads] [-builders] [-deps common.yml] -output generated.go schema1.yml schema2.yml ...\n")
+		fmt.Fprintf(os.Stderr, "       genssz [-with-tests] -split -output ./generated schema1.yml schema2.yml ...\n")
+		fmt.Fprintf(os.Stderr, "       genssz import -from-go ./types [-types A,B] [-output schema.yml]\n")
+		fmt.Fprintf(os.Stderr, "       genssz vet schema1.yml schema2.yml ...\n")
 		os.Exit(1)
 	}
 
@@ -29,6 +47,19 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Failed to combine schemas: %v\n", err)
 		os.Exit(1)
 	}
+	if *unsafe {
+		combinedSchema.UnsafeReads = true
+	}
+	if *build {
+		combinedSchema.Builders = true
+	}
+	if *deps != "" {
+		combinedSchema, err = linkDeps(combinedSchema, strings.Split(*deps, ","))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to link schemas: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Create world
 	world, err := genssz.ParseSchemaToWorld(combinedSchema)
@@ -37,6 +68,31 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *split {
+		files, err := genssz.GenerateFiles(world, combinedSchema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to generate code: %v\n", err)
+			os.Exit(1)
+		}
+		if *tests {
+			testFiles, err := genssz.GenerateTestFiles(world, combinedSchema)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to generate tests: %v\n", err)
+				os.Exit(1)
+			}
+			files = append(files, testFiles...)
+			sort.Slice(files, func(i, j int) bool {
+				return files[i].Name < files[j].Name
+			})
+		}
+		if err := genssz.WriteSplit(*output, files); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully generated %d files in %s from %s\n", len(files), *output, strings.Join(inputFiles, ", "))
+		return
+	}
+
 	// Generate code
 	code, err := genssz.GenerateCode(world, combinedSchema)
 	if err != nil {
@@ -57,9 +113,107 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *tests {
+		testCode, err := genssz.GenerateTests(world, combinedSchema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to generate tests: %v\n", err)
+			os.Exit(1)
+		}
+		testOutput := strings.TrimSuffix(*output, ".go") + "_test.go"
+		if err := testCode.Save(testOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write tests: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully generated %s\n", testOutput)
+	}
+
 	fmt.Printf("Successfully generated %s from %s\n", *output, strings.Join(inputFiles, ", "))
 }
 
+// importMain writes the schema of the ssz-tagged Go structs in a package
+func importMain(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	var (
+		fromGo = fs.String("from-go", "", "Directory of the Go package to import")
+		types  = fs.String("types", "", "Comma separated structs to import, with the structs they refer to (default all)")
+		output = fs.String("output", "", "Output schema file (default stdout)")
+	)
+	fs.Parse(args)
+
+	if *fromGo == "" {
+		fmt.Fprintf(os.Stderr, "Usage: genssz import -from-go ./types [-types A,B] [-output schema.yml]\n")
+		os.Exit(1)
+	}
+
+	var typeNames []string
+	if *types != "" {
+		typeNames = strings.Split(*types, ",")
+	}
+	schema, err := genssz.ImportGoPackage(*fromGo, typeNames...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to import %s: %v\n", *fromGo, err)
+		os.Exit(1)
+	}
+	data, err := genssz.MarshalSchema(schema)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to marshal schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write output: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Successfully imported %d structs from %s to %s\n", len(schema.Structs), *fromGo, *output)
+}
+
+// vetMain reports the problems in a set of schemas, exiting with status 1 if there are any
+func vetMain(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: genssz vet schema1.yml schema2.yml ...\n")
+		os.Exit(1)
+	}
+
+	files := make([]genssz.SchemaFile, 0, len(args))
+	for _, name := range args {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		files = append(files, genssz.SchemaFile{Name: name, Data: data})
+	}
+
+	diags := genssz.VetSchemas(files...)
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if len(diags) > 0 {
+		os.Exit(1)
+	}
+}
+
+// linkDeps resolves the refs of a schema to other packages against the schemas of those packages
+func linkDeps(schema *genssz.Schema, files []string) (*genssz.Schema, error) {
+	deps := make([]*genssz.Schema, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		dep, err := genssz.ReadSchemaFromBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		deps = append(deps, dep)
+	}
+	return genssz.LinkSchemas(schema, deps...)
+}
+
 // combineSchemas reads multiple schema files and combines them into one
 func combineSchemas(files []string) (*genssz.Schema, error) {
 	var combinedSchema *genssz.Schema
@@ -98,6 +252,20 @@ func combineSchemas(files []string) (*genssz.Schema, error) {
 			combinedSchema = &genssz.Schema{}
 		}
 		
+		// Merge the packages refs refer to
+		for name, path := range schema.Packages {
+			if prev, ok := combinedSchema.Packages[name]; ok && prev != path {
+				return nil, fmt.Errorf("conflicting import paths of package %s: %s vs %s", name, prev, path)
+			}
+			if combinedSchema.Packages == nil {
+				combinedSchema.Packages = make(map[string]string)
+			}
+			combinedSchema.Packages[name] = path
+		}
+
+		combinedSchema.UnsafeReads = combinedSchema.UnsafeReads || schema.UnsafeReads
+		combinedSchema.Builders = combinedSchema.Builders || schema.Builders
+
 		// Append structs
 		combinedSchema.Structs = append(combinedSchema.Structs, schema.Structs...)
 	}
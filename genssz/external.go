@@ -0,0 +1,220 @@
+package genssz
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/gfx-labs/ssz"
+)
+
+// Codecs of external types, set in the codec of a field with a go_type
+const (
+	// CodecConvert converts between the external type and the type genssz would use for the field,
+	// e.g. uint64 for a Slot or [32]byte for a Hash. It is the default.
+	CodecConvert = "convert"
+	// CodecSSZ reads and writes a pointer to the external type with its UnmarshalSSZ and MarshalSSZ
+	// methods, as implemented by uint256.Int and fastssz types. Its getters and setters, and the
+	// WithValues constructor, return an error.
+	CodecSSZ = "ssz"
+)
+
+// externalType is a Go type from outside the schema used by a field
+type externalType struct {
+	path  string
+	name  string
+	codec string
+}
+
+// parseGoType reads a go_type such as github.com/holiman/uint256.Int, or a type of the generated
+// package without an import path, and its codec
+func parseGoType(goType, codec string) (*externalType, error) {
+	ext := &externalType{name: goType, codec: codec}
+	if i := strings.LastIndex(goType, "."); i >= 0 {
+		ext.path, ext.name = goType[:i], goType[i+1:]
+	}
+	if ext.name == "" || (ext.path == "" && strings.Contains(goType, ".")) {
+		return nil, fmt.Errorf("invalid go_type %q, expected import/path.Name", goType)
+	}
+	switch codec {
+	case "":
+		ext.codec = CodecConvert
+	case CodecConvert, CodecSSZ:
+	default:
+		return nil, fmt.Errorf("unknown codec %q for go_type %q", codec, goType)
+	}
+	return ext, nil
+}
+
+// typ returns the type of values passed to and returned by accessors
+func (e *externalType) typ() *jen.Statement {
+	if e.codec == CodecSSZ {
+		return jen.Op("*").Add(e.named())
+	}
+	return e.named()
+}
+
+func (e *externalType) named() *jen.Statement {
+	if e.path == "" {
+		return jen.Id(e.name)
+	}
+	return jen.Qual(e.path, e.name)
+}
+
+func (e *externalType) String() string {
+	if e.path == "" {
+		return e.name
+	}
+	return e.path + "." + e.name
+}
+
+// externalFields returns the external types of the children of a struct, from their go_type or
+// from the go_type of the type they refer to
+func externalFields(structDef Field, schema *Schema) (map[string]*externalType, error) {
+	types := make(map[string]Field, len(schema.Structs))
+	for _, s := range schema.Structs {
+		types[s.Name] = s
+	}
+
+	exts := make(map[string]*externalType)
+	for _, child := range structDef.Children {
+		def := child
+		if child.GoType == "" && child.Type == ssz.TypeRef {
+			def = types[child.Ref]
+		}
+		if def.GoType == "" {
+			continue
+		}
+		ext, err := parseGoType(def.GoType, def.Codec)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", child.Name, err)
+		}
+		if !externalCompatible(def.ToSSZField(), ext.codec) {
+			return nil, fmt.Errorf("field %s: go_type %s cannot be used for %s with codec %s", child.Name, ext, def.Type, ext.codec)
+		}
+		exts[child.Name] = ext
+	}
+	return exts, nil
+}
+
+// externalCompatible reports whether an external type with the codec can be used for the field.
+// Only fields whose bytes are hashed by the generated code can be external, so not containers.
+func externalCompatible(field ssz.Field, codec string) bool {
+	switch field.Type {
+	case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeBoolean, ssz.TypeBitVector:
+		return true
+	case ssz.TypeUint128, ssz.TypeUint256:
+		return codec == CodecSSZ
+	case ssz.TypeVector:
+		return len(field.Children) > 0 && field.Children[0].Type == ssz.TypeUint8
+	default:
+		return false
+	}
+}
+
+// inlineExternalRefs replaces the children of a struct that refer to types with a go_type by the
+// definition of that type, so their layout and hashing are those of the referenced type
+func inlineExternalRefs(structDef ssz.Field, schema *Schema) ssz.Field {
+	external := make(map[string]Field)
+	for _, s := range schema.Structs {
+		if s.GoType != "" {
+			external[s.Name] = s
+		}
+	}
+	children := make([]ssz.Field, len(structDef.Children))
+	for i, child := range structDef.Children {
+		if def, ok := external[child.Ref]; ok && child.Type == ssz.TypeRef {
+			inlined := def.ToSSZField()
+			inlined.Name = child.Name
+			child = inlined
+		}
+		children[i] = child
+	}
+	structDef.Children = children
+	return structDef
+}
+
+// generateExternalGetter generates the getter of a field with an external type
+func generateExternalGetter(f *jen.File, typeName string, field ssz.Field, offset, size int, ext *externalType, schema *Schema) error {
+	methodName := capitalizeFirst(field.Name)
+	f.Comment(fmt.Sprintf("%s returns the %s field", methodName, field.Name))
+	f.Comment(fmt.Sprintf("Bytes: %d-%d", offset, offset+size-1))
+	data := jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset + size))
+
+	if ext.codec == CodecSSZ {
+		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params().Params(ext.typ(), jen.Error()).Block(
+			jen.Id("v").Op(":=").New(ext.named()),
+			jen.If(jen.Err().Op(":=").Id("v").Dot("UnmarshalSSZ").Call(data), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Return(jen.Id("v"), jen.Nil()),
+		)
+		f.Line()
+		return nil
+	}
+
+	var value jen.Code
+	switch field.Type {
+	case ssz.TypeUint8:
+		value = jen.Id("s").Index(jen.Lit(offset))
+	case ssz.TypeBoolean:
+		value = jen.Id("s").Index(jen.Lit(offset)).Op("!=").Lit(0)
+	case ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64:
+		value = readUint(schema, size, data)
+	case ssz.TypeVector, ssz.TypeBitVector:
+		value = data
+	default:
+		return fmt.Errorf("codec %s does not support %s", ext.codec, field.Type)
+	}
+	f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params().Add(ext.typ()).Block(
+		jen.Return(ext.named().Call(value)),
+	)
+	f.Line()
+	return nil
+}
+
+// generateExternalSetter generates the setter of a field with an external type
+func generateExternalSetter(f *jen.File, typeName string, field ssz.Field, offset, size int, ext *externalType) error {
+	methodName := "Set" + capitalizeFirst(field.Name)
+	f.Comment(fmt.Sprintf("%s sets the %s field", methodName, field.Name))
+	f.Comment(fmt.Sprintf("Bytes: %d-%d", offset, offset+size-1))
+	data := jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset + size))
+
+	if ext.codec == CodecSSZ {
+		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params(jen.Id("v").Add(ext.typ())).Error().Block(
+			jen.List(jen.Id("enc"), jen.Err()).Op(":=").Id("v").Dot("MarshalSSZ").Call(),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.If(jen.Len(jen.Id("enc")).Op("!=").Lit(size)).Block(
+				jen.Return(jen.Qual("github.com/gfx-labs/ssz", "NewErrSizeMismatch").Call(jen.Lit(size), jen.Len(jen.Id("enc")))),
+			),
+			jen.Copy(data, jen.Id("enc")),
+			jen.Return(jen.Nil()),
+		)
+		f.Line()
+		return nil
+	}
+
+	var body jen.Code
+	switch field.Type {
+	case ssz.TypeUint8:
+		body = jen.Id("s").Index(jen.Lit(offset)).Op("=").Uint8().Call(jen.Id("v"))
+	case ssz.TypeBoolean:
+		body = jen.If(jen.Id("v")).Block(
+			jen.Id("s").Index(jen.Lit(offset)).Op("=").Lit(1),
+		).Else().Block(
+			jen.Id("s").Index(jen.Lit(offset)).Op("=").Lit(0),
+		)
+	case ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64:
+		bits := fmt.Sprintf("%d", size*8)
+		body = jen.Qual("encoding/binary", "LittleEndian").Dot("PutUint"+bits).Call(data, jen.Id("uint"+bits).Call(jen.Id("v")))
+	case ssz.TypeVector, ssz.TypeBitVector:
+		body = jen.Copy(data, jen.Id("v").Index(jen.Op(":")))
+	default:
+		return fmt.Errorf("codec %s does not support %s", ext.codec, field.Type)
+	}
+	f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params(jen.Id("v").Add(ext.typ())).Block(body)
+	f.Line()
+	return nil
+}
@@ -8,12 +8,23 @@ import (
 )
 
 type Field struct {
-	Name     string        `yaml:"name"`
+	Name     string        `yaml:"name,omitempty"`
 	Type     ssz.TypeName  `yaml:"type"`
 	Size     uint64        `yaml:"size,omitempty"`
 	Limit    uint64        `yaml:"limit,omitempty"`
 	Ref      string        `yaml:"ref,omitempty"`
 	Children []Field       `yaml:"children,omitempty"`
+
+	// GoType is a Go type from outside the schema used for the field, as import/path.Name, e.g.
+	// github.com/holiman/uint256.Int. A top-level type with a GoType is not generated, and fields
+	// that refer to it use the GoType. Codec is how values of GoType are read and written, one of
+	// CodecConvert, the default, and CodecSSZ.
+	GoType string `yaml:"go_type,omitempty" json:"go_type,omitempty"`
+	Codec  string `yaml:"codec,omitempty" json:"codec,omitempty"`
+
+	// pkg is the import path of a top-level type linked from another package by LinkSchemas,
+	// which is not generated
+	pkg string
 }
 
 // ToSSZField converts Field to ssz.Field, handling bytevector alias
@@ -54,8 +65,21 @@ func (f Field) ToSSZField() ssz.Field {
 }
 
 type Schema struct {
-	Package string  `yaml:"package"`
-	Structs []Field `yaml:"structs"`
+	Package string `yaml:"package"`
+	// ImportPath is the import path of the generated package, by which the Packages of other
+	// schemas refer to it
+	ImportPath string `yaml:"import_path,omitempty" json:"import_path,omitempty"`
+	// Packages maps the package names used in refs, e.g. common in common.Checkpoint, to the
+	// import paths of packages generated from other schemas, see LinkSchemas
+	Packages map[string]string `yaml:"packages,omitempty" json:"packages,omitempty"`
+	Structs  []Field           `yaml:"structs"`
+	// UnsafeReads makes the getters of numeric fields read with ssz.Uint64FromBytes and the other
+	// readers of the root package, which load the bytes directly on little endian targets,
+	// instead of encoding/binary. Building with the ssz_purego tag swaps them for portable ones.
+	UnsafeReads bool `yaml:"unsafe_reads,omitempty" json:"unsafe_reads,omitempty"`
+	// Builders also generates a builder for each type, e.g. NewPenguinBuilder().Name(n).Build(),
+	// whose setters check the size of refs and the unused bits of bitvectors
+	Builders bool `yaml:"builders,omitempty" json:"builders,omitempty"`
 }
 
 type World struct {
@@ -10,7 +10,21 @@ import (
 
 // GenerateCode generates Go code from a World and Schema
 func GenerateCode(world *World, schema *Schema) (*jen.File, error) {
-	f := jen.NewFile(schema.Package)
+	f := newGeneratedFile(schema.Package)
+	
+	// Generate code for each type in the world
+	for _, structDef := range schema.Structs {
+		if _, err := generateType(f, structDef, schema); err != nil {
+			return nil, err
+		}
+	}
+	
+	return f, nil
+}
+
+// newGeneratedFile creates a file with the generated code header and imports
+func newGeneratedFile(pkg string) *jen.File {
+	f := jen.NewFile(pkg)
 	
 	// Add generated code comment
 	f.HeaderComment("Code generated by genssz. DO NOT EDIT.")
@@ -21,41 +35,58 @@ func GenerateCode(world *World, schema *Schema) (*jen.File, error) {
 	f.ImportName("github.com/gfx-labs/ssz/merkle_tree", "merkle_tree")
 	f.ImportName("github.com/gfx-labs/ssz/merkle_tree/bufpool", "bufpool")
 	f.ImportName("fmt", "fmt")
+	return f
+}
+
+// generateType generates the type, constructor and methods of a top-level struct, and reports
+// whether anything was generated
+func generateType(f *jen.File, structDef Field, schema *Schema) (bool, error) {
+	// Types with a go_type are provided by the user, and linked types by their package
+	if structDef.GoType != "" || structDef.pkg != "" {
+		return false, nil
+	}
+	exts, err := externalFields(structDef, schema)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve go types of %s: %w", structDef.Name, err)
+	}
+
+	// Convert to ssz.Field
+	sszField := inlineExternalRefs(structDef.ToSSZField(), schema)
 	
-	// Generate code for each type in the world
-	for _, structDef := range schema.Structs {
-		// Convert to ssz.Field
-		sszField := structDef.ToSSZField()
-		
-		// Only generate for fixed-size types
-		isFixed, err := isFixedSize(sszField, schema)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check if %s is fixed size: %w", structDef.Name, err)
-		}
-		
-		if !isFixed {
-			continue // Skip variable-size types
-		}
-		
-		// Generate the type definition with byte layout comment
-		if err := generateTypeComment(f, sszField, schema); err != nil {
-			return nil, fmt.Errorf("failed to generate type comment for %s: %w", structDef.Name, err)
-		}
-		f.Type().Id(structDef.Name).Op("[]").Byte()
-		f.Line()
-		
-		// Generate constructor
-		if err := generateConstructor(f, sszField, schema); err != nil {
-			return nil, fmt.Errorf("failed to generate constructor for %s: %w", structDef.Name, err)
-		}
-		
-		// Generate methods
-		if err := generateMethods(f, sszField, schema); err != nil {
-			return nil, fmt.Errorf("failed to generate methods for %s: %w", structDef.Name, err)
+	// Only generate for fixed-size types
+	isFixed, err := isFixedSize(sszField, schema)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if %s is fixed size: %w", structDef.Name, err)
+	}
+	
+	if !isFixed {
+		return false, nil // Skip variable-size types
+	}
+	
+	// Generate the type definition with byte layout comment
+	if err := generateTypeComment(f, sszField, schema); err != nil {
+		return false, fmt.Errorf("failed to generate type comment for %s: %w", structDef.Name, err)
+	}
+	f.Type().Id(structDef.Name).Op("[]").Byte()
+	f.Line()
+	
+	// Generate constructor
+	if err := generateConstructor(f, sszField, schema, exts); err != nil {
+		return false, fmt.Errorf("failed to generate constructor for %s: %w", structDef.Name, err)
+	}
+	
+	// Generate methods
+	if err := generateMethods(f, sszField, schema, exts); err != nil {
+		return false, fmt.Errorf("failed to generate methods for %s: %w", structDef.Name, err)
+	}
+
+	if schema.Builders {
+		if err := generateBuilder(f, sszField, schema, exts); err != nil {
+			return false, fmt.Errorf("failed to generate builder for %s: %w", structDef.Name, err)
 		}
 	}
 	
-	return f, nil
+	return true, nil
 }
 
 // generateTypeComment generates a detailed comment describing the byte layout
@@ -146,7 +177,7 @@ func isFixedSize(field ssz.Field, schema *Schema) (bool, error) {
 }
 
 // generateConstructor generates a constructor function for a type
-func generateConstructor(f *jen.File, structDef ssz.Field, schema *Schema) error {
+func generateConstructor(f *jen.File, structDef ssz.Field, schema *Schema, exts map[string]*externalType) error {
 	typeName := structDef.Name
 	
 	// Calculate total size
@@ -169,8 +200,15 @@ func generateConstructor(f *jen.File, structDef ssz.Field, schema *Schema) error
 		refs[s.Name] = s.ToSSZField()
 	}
 	
+	fallible := false
 	for _, field := range structDef.Children {
 		paramName := field.Name
+		if ext := exts[field.Name]; ext != nil {
+			params = append(params, jen.Id(paramName).Add(ext.typ()))
+			paramComments = append(paramComments, fmt.Sprintf("%s: %s value", paramName, ext))
+			fallible = fallible || ext.codec == CodecSSZ
+			continue
+		}
 		switch field.Type {
 		case ssz.TypeUint8:
 			params = append(params, jen.Id(paramName).Uint8())
@@ -200,7 +238,7 @@ func generateConstructor(f *jen.File, structDef ssz.Field, schema *Schema) error
 			params = append(params, jen.Id(paramName).Op("[").Lit(byteSize).Op("]").Byte())
 			paramComments = append(paramComments, fmt.Sprintf("%s: %s[%d] value (as %d bytes)", paramName, field.Type, field.Size, byteSize))
 		case ssz.TypeRef:
-			params = append(params, jen.Id(paramName).Id(field.Ref))
+			params = append(params, jen.Id(paramName).Add(refType(field.Ref)))
 			paramComments = append(paramComments, fmt.Sprintf("%s: %s reference", paramName, field.Ref))
 		case ssz.TypeBoolean:
 			params = append(params, jen.Id(paramName).Bool())
@@ -219,17 +257,31 @@ func generateConstructor(f *jen.File, structDef ssz.Field, schema *Schema) error
 		}
 	}
 	
-	// Generate the function
-	f.Func().Id("New" + typeName + "WithValues").Params(params...).Id(typeName).BlockFunc(func(g *jen.Group) {
+	// Generate the function, which returns the errors of setters using CodecSSZ
+	results := jen.Id(typeName)
+	if fallible {
+		results = jen.Params(jen.Id(typeName), jen.Error())
+	}
+	f.Func().Id("New" + typeName + "WithValues").Params(params...).Add(results).BlockFunc(func(g *jen.Group) {
 		// Create the object
 		g.Id("obj").Op(":=").Id("New" + typeName).Call()
 		
 		// Set each field
 		for _, field := range structDef.Children {
 			setterName := "Set" + capitalizeFirst(field.Name)
+			if ext := exts[field.Name]; ext != nil && ext.codec == CodecSSZ {
+				g.If(jen.Err().Op(":=").Id("obj").Dot(setterName).Call(jen.Id(field.Name)), jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Nil(), jen.Err()),
+				)
+				continue
+			}
 			g.Id("obj").Dot(setterName).Call(jen.Id(field.Name))
 		}
 		
+		if fallible {
+			g.Return(jen.Id("obj"), jen.Nil())
+			return
+		}
 		g.Return(jen.Id("obj"))
 	})
 	f.Line()
@@ -238,7 +290,7 @@ func generateConstructor(f *jen.File, structDef ssz.Field, schema *Schema) error
 }
 
 // generateMethods generates all methods for a type
-func generateMethods(f *jen.File, structDef ssz.Field, schema *Schema) error {
+func generateMethods(f *jen.File, structDef ssz.Field, schema *Schema, exts map[string]*externalType) error {
 	typeName := structDef.Name
 	
 	// Calculate offsets for each field
@@ -277,6 +329,42 @@ func generateMethods(f *jen.File, structDef ssz.Field, schema *Schema) error {
 		jen.Return(jen.Id("s"), jen.Nil()),
 	)
 	f.Line()
+
+	// Generate WriteSSZTo and ReadSSZFrom methods, which move the bytes without copying them
+	f.Comment("WriteSSZTo writes the bytes to w")
+	f.Func().Params(jen.Id("s").Id(typeName)).Id("WriteSSZTo").Params(jen.Id("w").Qual("io", "Writer")).Params(jen.Int64(), jen.Error()).Block(
+		jen.If(jen.Len(jen.Id("s")).Op("!=").Id("s").Dot("SizeSSZ").Call()).Block(
+			jen.Return(
+				jen.Lit(0),
+				jen.Qual("github.com/gfx-labs/ssz", "NewErrSizeMismatch").Call(
+					jen.Id("s").Dot("SizeSSZ").Call(),
+					jen.Len(jen.Id("s")),
+				),
+			),
+		),
+		jen.List(jen.Id("n"), jen.Err()).Op(":=").Id("w").Dot("Write").Call(jen.Id("s")),
+		jen.Return(jen.Int64().Call(jen.Id("n")), jen.Err()),
+	)
+	f.Line()
+	f.Comment("ReadSSZFrom reads the bytes from r, which must hold at least SizeSSZ bytes. Nothing past")
+	f.Comment("them is read. A positive limit is the largest number of bytes that may be read.")
+	f.Func().Params(jen.Id("s").Id(typeName)).Id("ReadSSZFrom").Params(jen.Id("r").Qual("io", "Reader"), jen.Id("limit").Int64()).Params(jen.Int64(), jen.Error()).Block(
+		jen.If(jen.Len(jen.Id("s")).Op("!=").Id("s").Dot("SizeSSZ").Call()).Block(
+			jen.Return(
+				jen.Lit(0),
+				jen.Qual("github.com/gfx-labs/ssz", "NewErrSizeMismatch").Call(
+					jen.Id("s").Dot("SizeSSZ").Call(),
+					jen.Len(jen.Id("s")),
+				),
+			),
+		),
+		jen.If(jen.Id("limit").Op(">").Lit(0).Op("&&").Int64().Call(jen.Len(jen.Id("s"))).Op(">").Id("limit")).Block(
+			jen.Return(jen.Lit(0), jen.Qual("fmt", "Errorf").Call(jen.Lit("%d bytes exceed the limit of %d bytes"), jen.Len(jen.Id("s")), jen.Id("limit"))),
+		),
+		jen.List(jen.Id("n"), jen.Err()).Op(":=").Qual("io", "ReadFull").Call(jen.Id("r"), jen.Id("s")),
+		jen.Return(jen.Int64().Call(jen.Id("n")), jen.Err()),
+	)
+	f.Line()
 	
 	// Generate FillHashBuffer method
 	if err := generateFillHashBuffer(f, typeName, structDef, schema); err != nil {
@@ -317,16 +405,38 @@ func generateMethods(f *jen.File, structDef ssz.Field, schema *Schema) error {
 	)
 	f.Line()
 	
+	// Generate FieldGeneralizedIndex and ProveField methods
+	generateProofMethods(f, typeName, structDef)
+
+	refs := make(map[string]ssz.Field)
+	for _, s := range schema.Structs {
+		refs[s.Name] = s.ToSSZField()
+	}
+
 	// Generate getter methods for each field
 	for i, field := range structDef.Children {
-		if err := generateGetter(f, typeName, field, offsets[i], schema); err != nil {
+		var err error
+		if ext := exts[field.Name]; ext != nil {
+			size, _ := getFieldSize(field, refs)
+			err = generateExternalGetter(f, typeName, field, offsets[i], size, ext, schema)
+		} else {
+			err = generateGetter(f, typeName, field, offsets[i], schema)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to generate getter for %s: %w", field.Name, err)
 		}
 	}
 	
 	// Generate setter methods for each field
 	for i, field := range structDef.Children {
-		if err := generateSetter(f, typeName, field, offsets[i], schema); err != nil {
+		var err error
+		if ext := exts[field.Name]; ext != nil {
+			size, _ := getFieldSize(field, refs)
+			err = generateExternalSetter(f, typeName, field, offsets[i], size, ext)
+		} else {
+			err = generateSetter(f, typeName, field, offsets[i], schema)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to generate setter for %s: %w", field.Name, err)
 		}
 	}
@@ -390,6 +500,9 @@ func getFieldSize(field ssz.Field, refs map[string]ssz.Field) (int, error) {
 		if !ok {
 			return 0, fmt.Errorf("ref type %s not found", field.Ref)
 		}
+		if ref.Type != ssz.TypeContainer {
+			return getFieldSize(ref, refs)
+		}
 		return getStructSize(ref, refs)
 	case ssz.TypeContainer:
 		return getStructSize(field, refs)
@@ -411,6 +524,15 @@ func getStructSize(structDef ssz.Field, refs map[string]ssz.Field) (int, error)
 	return totalSize, nil
 }
 
+// readUint returns the expression reading the little endian uint of size bytes in data, with the
+// readers of the root package if the schema asks for UnsafeReads
+func readUint(schema *Schema, size int, data jen.Code) jen.Code {
+	if schema.UnsafeReads {
+		return jen.Qual("github.com/gfx-labs/ssz", fmt.Sprintf("Uint%dFromBytes", size*8)).Call(data)
+	}
+	return jen.Qual("encoding/binary", "LittleEndian").Dot(fmt.Sprintf("Uint%d", size*8)).Call(data)
+}
+
 // generateGetter generates a getter method for a field
 func generateGetter(f *jen.File, typeName string, field ssz.Field, offset int, schema *Schema) error {
 	methodName := capitalizeFirst(field.Name)
@@ -438,27 +560,21 @@ func generateGetter(f *jen.File, typeName string, field ssz.Field, offset int, s
 		f.Comment(fmt.Sprintf("%s returns the %s field", methodName, field.Name))
 		f.Comment(fmt.Sprintf("Bytes: %d-%d", offset, offset+1))
 		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params().Uint16().Block(
-			jen.Return(jen.Qual("encoding/binary", "LittleEndian").Dot("Uint16").Call(
-				jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+2)),
-			)),
+			jen.Return(readUint(schema, 2, jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+2)))),
 		)
 		
 	case ssz.TypeUint32:
 		f.Comment(fmt.Sprintf("%s returns the %s field", methodName, field.Name))
 		f.Comment(fmt.Sprintf("Bytes: %d-%d", offset, offset+3))
 		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params().Uint32().Block(
-			jen.Return(jen.Qual("encoding/binary", "LittleEndian").Dot("Uint32").Call(
-				jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+4)),
-			)),
+			jen.Return(readUint(schema, 4, jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+4)))),
 		)
 		
 	case ssz.TypeUint64:
 		f.Comment(fmt.Sprintf("%s returns the %s field", methodName, field.Name))
 		f.Comment(fmt.Sprintf("Bytes: %d-%d", offset, offset+7))
 		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params().Uint64().Block(
-			jen.Return(jen.Qual("encoding/binary", "LittleEndian").Dot("Uint64").Call(
-				jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+8)),
-			)),
+			jen.Return(readUint(schema, 8, jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+8)))),
 		)
 		
 	case ssz.TypeVector:
@@ -503,8 +619,8 @@ func generateGetter(f *jen.File, typeName string, field ssz.Field, offset int, s
 		
 		f.Comment(fmt.Sprintf("%s returns the %s field", methodName, field.Name))
 		f.Comment(fmt.Sprintf("Bytes: %d-%d", offset, offset+size-1))
-		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params().Id(field.Ref).Block(
-			jen.Return(jen.Id(field.Ref).Call(
+		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params().Add(refType(field.Ref)).Block(
+			jen.Return(refType(field.Ref).Call(
 				jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+size)),
 			)),
 		)
@@ -613,7 +729,7 @@ func generateSetter(f *jen.File, typeName string, field ssz.Field, offset int, s
 		
 		f.Comment(fmt.Sprintf("%s sets the %s field", methodName, field.Name))
 		f.Comment(fmt.Sprintf("Bytes: %d-%d", offset, offset+size-1))
-		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params(jen.Id("v").Id(field.Ref)).Block(
+		f.Func().Params(jen.Id("s").Id(typeName)).Id(methodName).Params(jen.Id("v").Add(refType(field.Ref))).Block(
 			jen.Copy(jen.Id("s").Index(jen.Lit(offset).Op(":").Lit(offset+size)), jen.Id("v")),
 		)
 		
@@ -625,6 +741,56 @@ func generateSetter(f *jen.File, typeName string, field ssz.Field, offset int, s
 	return nil
 }
 
+// generateProofMethods generates the FieldGeneralizedIndex and ProveField methods, which prove
+// the hash tree root of a top-level field against the hash tree root of the object
+func generateProofMethods(f *jen.File, typeName string, structDef ssz.Field) {
+	numFields := len(structDef.Children)
+	depth := 0
+	for 1<<depth < numFields {
+		depth++
+	}
+
+	// The leaves of the tree start at generalized index 2^depth
+	cases := make([]jen.Code, 0, numFields+1)
+	for i, field := range structDef.Children {
+		cases = append(cases, jen.Case(jen.Lit(field.Name)).Block(
+			jen.Return(jen.Lit(1<<depth+i), jen.Nil()),
+		))
+	}
+	cases = append(cases, jen.Default().Block(
+		jen.Return(jen.Lit(0), jen.Qual("fmt", "Errorf").Call(jen.Lit(typeName+" has no field %q"), jen.Id("name"))),
+	))
+
+	f.Comment("FieldGeneralizedIndex returns the generalized index of the named field in the merkle tree of the object")
+	f.Func().Params(jen.Id("s").Id(typeName)).Id("FieldGeneralizedIndex").Params(jen.Id("name").String()).Params(jen.Uint64(), jen.Error()).Block(
+		jen.Switch(jen.Id("name")).Block(cases...),
+	)
+	f.Line()
+
+	f.Comment("ProveField returns the merkle branch proving the hash tree root of the named field against the")
+	f.Comment("hash tree root of the object, starting with the sibling of the field")
+	f.Func().Params(jen.Id("s").Id(typeName)).Id("ProveField").Params(jen.Id("name").String()).Params(jen.Op("[]").Op("[32]").Byte(), jen.Error()).Block(
+		jen.List(jen.Id("gindex"), jen.Err()).Op(":=").Id("s").Dot("FieldGeneralizedIndex").Call(jen.Id("name")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Id("hashBuffer").Op(":=").Make(jen.Op("[]").Byte(), jen.Lit(numFields*32)),
+		jen.If(jen.Err().Op(":=").Id("s").Dot("FillHashBuffer").Call(jen.Id("hashBuffer")), jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Id("leaves").Op(":=").Make(jen.Op("[]").Op("[32]").Byte(), jen.Lit(numFields)),
+		jen.For(jen.Id("i").Op(":=").Range().Id("leaves")).Block(
+			jen.Copy(jen.Id("leaves").Index(jen.Id("i")).Index(jen.Empty(), jen.Empty()), jen.Id("hashBuffer").Index(jen.Id("i").Op("*").Lit(32), jen.Empty())),
+		),
+		jen.Return(jen.Qual("github.com/gfx-labs/ssz/merkle_tree", "MerkleProof").Call(
+			jen.Lit(depth),
+			jen.Int().Parens(jen.Id("gindex").Op("-").Lit(1<<depth)),
+			jen.Id("leaves").Op("..."),
+		)),
+	)
+	f.Line()
+}
+
 // generateFillHashBuffer generates the FillHashBuffer method for a type
 func generateFillHashBuffer(f *jen.File, typeName string, structDef ssz.Field, schema *Schema) error {
 	refs := make(map[string]ssz.Field)
@@ -690,6 +856,14 @@ func generateFieldHashing(structDef ssz.Field, offsets []int, refs map[string]ss
 				jen.Comment(fmt.Sprintf("Field %s (%s)", field.Name, field.Type)),
 				jen.Copy(jen.Id("buf").Index(jen.Lit(bufOffset), jen.Lit(bufOffset+size)), jen.Id("s").Index(jen.Lit(fieldOffset), jen.Lit(fieldOffset+size))),
 			)
+			// Zero padding for the rest of the 32 bytes of a uint128
+			if size < 32 {
+				statements = append(statements,
+					jen.For(jen.Id("j").Op(":=").Lit(bufOffset+size), jen.Id("j").Op("<").Lit(bufOffset+32), jen.Id("j").Op("++")).Block(
+						jen.Id("buf").Index(jen.Id("j")).Op("=").Lit(0),
+					),
+				)
+			}
 			
 		case ssz.TypeBoolean:
 			// For booleans, copy single byte with padding
@@ -744,7 +918,7 @@ func generateFieldHashing(structDef ssz.Field, offsets []int, refs map[string]ss
 			statements = append(statements,
 				jen.Comment(fmt.Sprintf("Field %s (ref to %s)", field.Name, field.Ref)),
 				jen.Block(
-					jen.Id("refData").Op(":=").Id(field.Ref).Call(jen.Id("s").Index(jen.Lit(fieldOffset), jen.Lit(fieldOffset+refSize))),
+					jen.Id("refData").Op(":=").Add(refType(field.Ref)).Call(jen.Id("s").Index(jen.Lit(fieldOffset), jen.Lit(fieldOffset+refSize))),
 					jen.Id("_").Op(",").Err().Op(":=").Id("refData").Dot("HashSSZTo").Call(jen.Id("buf").Index(jen.Lit(bufOffset), jen.Lit(bufOffset+32))),
 					jen.If(jen.Err().Op("!=").Nil()).Block(
 						jen.Return(jen.Err()),
@@ -2,7 +2,10 @@ package genssz
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/gfx-labs/ssz"
 )
 
 func TestGenerateCode(t *testing.T) {
@@ -61,6 +64,10 @@ structs:
 		"func (s Penguin) SetSpecies(v [2]byte)",
 		"func (s Penguin) Awesomness() uint8",
 		"func (s Penguin) SetAwesomness(v uint8)",
+		"func (s Penguin) FieldGeneralizedIndex(name string) (uint64, error)",
+		"case \"species\":\n\t\treturn 5, nil",
+		"func (s Penguin) ProveField(name string) ([][32]byte, error)",
+		"merkle_tree.MerkleProof(2, int(gindex-4), leaves...)",
 	}
 
 	for _, expected := range expectedElements {
@@ -137,3 +144,301 @@ structs:
 
 	t.Logf("Generated code with refs:\n%s", generated)
 }
+
+func TestGenerateCodeWithGoTypes(t *testing.T) {
+	schemaYAML := []byte(`
+package: testpkg
+structs:
+  - name: Hash
+    type: bytevector
+    size: 32
+    go_type: github.com/ethereum/go-ethereum/common.Hash
+  - name: Account
+    type: container
+    children:
+      - name: nonce
+        type: uint64
+        go_type: Nonce
+      - name: balance
+        type: uint256
+        go_type: github.com/holiman/uint256.Int
+        codec: ssz
+      - name: codeHash
+        type: ref
+        ref: Hash
+`)
+
+	schema, err := ReadSchemaFromBytes(schemaYAML)
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	world, err := ParseSchemaToWorld(schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema to world: %v", err)
+	}
+	code, err := GenerateCode(world, schema)
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := code.Render(&buf); err != nil {
+		t.Fatalf("Failed to render code: %v", err)
+	}
+	generated := buf.String()
+
+	expectedElements := []string{
+		"common \"github.com/ethereum/go-ethereum/common\"",
+		"uint256 \"github.com/holiman/uint256\"",
+		"func NewAccountWithValues(nonce Nonce, balance *uint256.Int, codeHash common.Hash) (Account, error)",
+		"func (s Account) SizeSSZ() int {\n\treturn 72",
+		"func (s Account) Nonce() Nonce {\n\treturn Nonce(binary.LittleEndian.Uint64(s[0:8]))",
+		"func (s Account) SetNonce(v Nonce) {\n\tbinary.LittleEndian.PutUint64(s[0:8], uint64(v))",
+		"func (s Account) Balance() (*uint256.Int, error)",
+		"func (s Account) SetBalance(v *uint256.Int) error",
+		"func (s Account) CodeHash() common.Hash {\n\treturn common.Hash(s[40:72])",
+		"func (s Account) SetCodeHash(v common.Hash) {\n\tcopy(s[40:72], v[:])",
+	}
+	for _, expected := range expectedElements {
+		if !bytes.Contains([]byte(generated), []byte(expected)) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+	if bytes.Contains([]byte(generated), []byte("type Hash []byte")) {
+		t.Error("Generated code has a type for Hash, which has a go_type")
+	}
+
+	// Containers cannot be external, since their hashing is generated
+	schema.Structs[0] = Field{Name: "Hash", Type: ssz.TypeContainer, GoType: "example.com/types.Hash", Children: []Field{{Name: "a", Type: ssz.TypeUint64}}}
+	if _, err := GenerateCode(world, schema); err == nil {
+		t.Error("Expected an error for a container with a go_type")
+	}
+}
+
+func TestGenerateCodeWithUnsafeReads(t *testing.T) {
+	schemaYAML := []byte(`
+package: testpkg
+unsafe_reads: true
+structs:
+  - name: Balance
+    type: container
+    children:
+      - name: index
+        type: uint32
+      - name: amount
+        type: uint64
+        go_type: Gwei
+      - name: flags
+        type: uint16
+`)
+
+	if diags := VetSchemas(SchemaFile{Name: "schema.yml", Data: schemaYAML}); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	schema, err := ReadSchemaFromBytes(schemaYAML)
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	if !schema.UnsafeReads {
+		t.Fatal("unsafe_reads was not read")
+	}
+	world, err := ParseSchemaToWorld(schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema to world: %v", err)
+	}
+	code, err := GenerateCode(world, schema)
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := code.Render(&buf); err != nil {
+		t.Fatalf("Failed to render code: %v", err)
+	}
+	generated := buf.String()
+
+	expectedElements := []string{
+		"func (s Balance) Index() uint32 {\n\treturn ssz.Uint32FromBytes(s[0:4])",
+		"func (s Balance) Amount() Gwei {\n\treturn Gwei(ssz.Uint64FromBytes(s[4:12]))",
+		"func (s Balance) Flags() uint16 {\n\treturn ssz.Uint16FromBytes(s[12:14])",
+		// Writes are unchanged
+		"func (s Balance) SetIndex(v uint32) {\n\tbinary.LittleEndian.PutUint32(s[0:4], v)",
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+	if strings.Contains(generated, "binary.LittleEndian.Uint") {
+		t.Error("Generated code reads with encoding/binary")
+	}
+}
+
+func TestGenerateCodeWithBuilders(t *testing.T) {
+	schemaYAML := []byte(`
+package: testpkg
+builders: true
+structs:
+  - name: Validator
+    type: container
+    children:
+      - name: flags
+        type: bitvector
+        size: 12
+      - name: balance
+        type: uint256
+        go_type: github.com/holiman/uint256.Int
+        codec: ssz
+      - name: checkpoint
+        type: ref
+        ref: Checkpoint
+  - name: Checkpoint
+    type: container
+    children:
+      - name: epoch
+        type: uint64
+`)
+
+	if diags := VetSchemas(SchemaFile{Name: "schema.yml", Data: schemaYAML}); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	schema, err := ReadSchemaFromBytes(schemaYAML)
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	world, err := ParseSchemaToWorld(schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema to world: %v", err)
+	}
+	code, err := GenerateCode(world, schema)
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := code.Render(&buf); err != nil {
+		t.Fatalf("Failed to render code: %v", err)
+	}
+	generated := buf.String()
+
+	expectedElements := []string{
+		"type ValidatorBuilder struct {",
+		"func NewValidatorBuilder() *ValidatorBuilder {",
+		// Bits past the length of the bitvector are rejected
+		"if v[1]>>4 != 0 {",
+		// Errors of setters are kept
+		"if err := b.obj.SetBalance(v); err != nil {\n\t\tb.err = fmt.Errorf(\"balance: %w\", err)",
+		// Refs must have the size of the field
+		"if len(v) != 8 {",
+		"func (b *ValidatorBuilder) Build() (Validator, error) {",
+		// Build copies the bytes of the builder
+		"return Validator(bytes.Clone(b.obj)), nil",
+		"func (b *CheckpointBuilder) Epoch(v uint64) *CheckpointBuilder {",
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		schema := &Schema{Package: "testpkg", Builders: true, Structs: []Field{
+			{Name: "Job", Type: ssz.TypeContainer, Children: []Field{{Name: "build", Type: ssz.TypeUint64}}},
+		}}
+		if _, err := GenerateCode(&World{}, schema); err == nil || !strings.Contains(err.Error(), "conflicts with the Build method") {
+			t.Errorf("expected a conflict with Build, got %v", err)
+		}
+	})
+}
+
+func TestGenerateTests(t *testing.T) {
+	schemaYAML := []byte(`
+package: testpkg
+structs:
+  - name: Penguin
+    type: container
+    children:
+      - name: name
+        type: bytevector
+        size: 32
+      - name: species
+        type: bitvector
+        size: 12
+      - name: identity
+        type: ref
+        ref: Identity
+  - name: Identity
+    type: container
+    children:
+      - name: id
+        type: uint64
+      - name: balance
+        type: uint128
+        go_type: github.com/holiman/uint256.Int
+        codec: ssz
+  - name: Scores
+    type: container
+    children:
+      - name: scores
+        type: vector
+        size: 4
+        children:
+          - type: uint64
+`)
+
+	schema, err := ReadSchemaFromBytes(schemaYAML)
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	world, err := ParseSchemaToWorld(schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema to world: %v", err)
+	}
+	code, err := GenerateTests(world, schema)
+	if err != nil {
+		t.Fatalf("Failed to generate tests: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := code.Render(&buf); err != nil {
+		t.Fatalf("Failed to render tests: %v", err)
+	}
+	generated := buf.String()
+
+	expectedElements := []string{
+		"// Code generated by genssz. DO NOT EDIT.",
+		"type testPenguinValue struct",
+		"Species  []byte `ssz:\"bitvector\" ssz-size:\"12\"`",
+		"Identity testIdentityValue",
+		"Balance uint256.Int `ssz:\"uint128\"`",
+		"v.Species[1] &= 15",
+		"v.Identity = randomTestIdentityValue(r)",
+		"func TestPenguin_Generated(t *testing.T)",
+		"if got := view.Name(); got != value.Name {",
+		"if got := view.Id(); got != value.Id {",
+		"root, err := view.HashSSZ()",
+		"expected, err := flexssz.HashTreeRoot(&value)",
+		"err = flexssz.UnmarshalStrict(encoded, &decoded)",
+	}
+	for _, expected := range expectedElements {
+		if !bytes.Contains([]byte(generated), []byte(expected)) {
+			t.Errorf("Generated tests missing expected element: %s", expected)
+		}
+	}
+	// The getter of an external type is checked through the encoding
+	if bytes.Contains([]byte(generated), []byte("view.Balance()")) {
+		t.Error("Generated tests call the getter of an external type")
+	}
+	// Vectors of integers are not hashed by the generated code
+	if bytes.Contains([]byte(generated), []byte("Scores")) {
+		t.Error("Generated tests have a test for Scores, which cannot be hashed")
+	}
+
+	files, err := GenerateTestFiles(world, schema)
+	if err != nil {
+		t.Fatalf("Failed to generate test files: %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	if len(names) != 2 || names[0] != "penguin.gen_test.go" || names[1] != "identity.gen_test.go" {
+		t.Errorf("Unexpected test files %v", names)
+	}
+}
@@ -0,0 +1,542 @@
+package genssz
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gfx-labs/ssz"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ImportGoPackage builds a schema from the Go structs in dir, read from their ssz-size, ssz-max
+// and ssz struct tags as used by fastssz and flexssz. Every struct type is imported, or only the
+// named types and the structs they refer to. Tests and generated files are skipped.
+func ImportGoPackage(dir string, typeNames ...string) (*Schema, error) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &goImporter{types: make(map[string]*ast.TypeSpec)}
+	var pkgName string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		if pkgName != "" && pkgName != file.Name.Name {
+			return nil, fmt.Errorf("conflicting package names: %s vs %s", pkgName, file.Name.Name)
+		}
+		pkgName = file.Name.Name
+		imp.addFile(file)
+	}
+	if pkgName == "" {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+
+	names, err := imp.selectStructs(typeNames)
+	if err != nil {
+		return nil, err
+	}
+	schema := &Schema{Package: pkgName}
+	for _, name := range names {
+		if _, _, ok := imp.wrappedField(name); ok {
+			// Transparent wrappers are inlined where they are used
+			continue
+		}
+		field, err := imp.importStruct(name)
+		if err != nil {
+			return nil, err
+		}
+		schema.Structs = append(schema.Structs, field)
+	}
+	return schema, nil
+}
+
+// MarshalSchema writes a schema as YAML, in the format read by ReadSchemaFromBytes
+func MarshalSchema(schema *Schema) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := yamlv3.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// goImporter converts the type declarations of a package to schema fields
+type goImporter struct {
+	types map[string]*ast.TypeSpec
+	// struct types in declaration order
+	structs []string
+}
+
+func (imp *goImporter) addFile(file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if typeSpec.TypeParams != nil {
+				continue
+			}
+			imp.types[typeSpec.Name.Name] = typeSpec
+			if _, ok := typeSpec.Type.(*ast.StructType); ok {
+				imp.structs = append(imp.structs, typeSpec.Name.Name)
+			}
+		}
+	}
+}
+
+// selectStructs returns the structs to import in declaration order: all of them, or the named
+// structs and every struct they refer to
+func (imp *goImporter) selectStructs(typeNames []string) ([]string, error) {
+	if len(typeNames) == 0 {
+		return imp.structs, nil
+	}
+
+	selected := make(map[string]bool)
+	var visit func(name string) error
+	visit = func(name string) error {
+		if selected[name] {
+			return nil
+		}
+		spec, ok := imp.types[name]
+		if !ok {
+			return fmt.Errorf("type %s not found", name)
+		}
+		if _, ok := spec.Type.(*ast.StructType); !ok {
+			return fmt.Errorf("type %s is not a struct", name)
+		}
+		selected[name] = true
+		for _, ref := range imp.structRefs(spec.Type, make(map[string]bool)) {
+			if err := visit(ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, name := range typeNames {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var names []string
+	for _, name := range imp.structs {
+		if selected[name] {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// structRefs returns the local struct types used by expr
+func (imp *goImporter) structRefs(expr ast.Expr, seen map[string]bool) []string {
+	var refs []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || seen[ident.Name] {
+			return true
+		}
+		spec, ok := imp.types[ident.Name]
+		if !ok {
+			return true
+		}
+		seen[ident.Name] = true
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			refs = append(refs, ident.Name)
+		} else {
+			refs = append(refs, imp.structRefs(spec.Type, seen)...)
+		}
+		return true
+	})
+	return refs
+}
+
+// importStruct converts a struct type to a container
+func (imp *goImporter) importStruct(name string) (Field, error) {
+	children, err := imp.structFields(name, make(map[string]bool))
+	if err != nil {
+		return Field{}, err
+	}
+	return Field{Name: name, Type: ssz.TypeContainer, Children: children}, nil
+}
+
+// structFields returns the fields of a struct type, with the fields of inlined structs in their
+// place. Embedded structs are inlined unless they have ssz tags, like in flexssz; other embedded
+// fields are encoded like any other field, named after their type.
+func (imp *goImporter) structFields(name string, seen map[string]bool) ([]Field, error) {
+	if seen[name] {
+		return nil, fmt.Errorf("recursive type %s", name)
+	}
+	seen[name] = true
+	defer delete(seen, name)
+
+	st := imp.types[name].Type.(*ast.StructType)
+	var fields []Field
+	for _, astField := range st.Fields.List {
+		names := astField.Names
+		embedded := len(names) == 0
+		if embedded {
+			ident := embeddedName(astField.Type)
+			if ident == nil {
+				return nil, fmt.Errorf("%s: unsupported embedded field", name)
+			}
+			names = []*ast.Ident{ident}
+		}
+		var tag goTag
+		tagged := false
+		if astField.Tag != nil {
+			raw, err := strconv.Unquote(astField.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid struct tag: %w", name, err)
+			}
+			if tag, err = parseGoTag(reflect.StructTag(raw)); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, names[0].Name, err)
+			}
+			tagged = hasGoTags(reflect.StructTag(raw))
+		}
+		if tag.skip {
+			continue
+		}
+		if tag.sszType == "inline" || embedded && !tagged && imp.inlinable(astField.Type) {
+			ident, ok := astField.Type.(*ast.Ident)
+			if !ok || !imp.isStruct(ident.Name) {
+				return nil, fmt.Errorf("%s.%s: ssz tag 'inline' requires a struct type", name, names[0].Name)
+			}
+			if _, _, ok := imp.wrappedField(ident.Name); ok {
+				return nil, fmt.Errorf("%s.%s: cannot inline %s, which is a transparent wrapper", name, names[0].Name, ident.Name)
+			}
+			inner, err := imp.structFields(ident.Name, seen)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, inner...)
+			continue
+		}
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+			field, err := imp.importType(astField.Type, tag, 0, make(map[string]bool))
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, ident.Name, err)
+			}
+			field.Name = schemaFieldName(ident.Name)
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// inlinable reports whether an embedded field of type expr is inlined when it has no ssz tags,
+// which is when it is a struct declared in the package and not a transparent wrapper
+func (imp *goImporter) inlinable(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || !imp.isStruct(ident.Name) {
+		return false
+	}
+	_, _, wrapped := imp.wrappedField(ident.Name)
+	return !wrapped
+}
+
+// isStruct reports whether name is a struct type declared in the package
+func (imp *goImporter) isStruct(name string) bool {
+	spec, ok := imp.types[name]
+	if !ok {
+		return false
+	}
+	_, ok = spec.Type.(*ast.StructType)
+	return ok
+}
+
+// hasGoTags reports whether a field has any ssz tags
+func hasGoTags(tag reflect.StructTag) bool {
+	for _, key := range []string{"ssz", "ssz-size", "ssz-max", "ssz-max-name", "ssz-omitzero"} {
+		if _, ok := tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// wrappedField returns the field of a transparent wrapper struct, marked with
+// ssz:",transparent", and its tags
+func (imp *goImporter) wrappedField(name string) (*ast.Field, goTag, bool) {
+	st := imp.types[name].Type.(*ast.StructType)
+	for _, astField := range st.Fields.List {
+		if astField.Tag == nil {
+			continue
+		}
+		raw, err := strconv.Unquote(astField.Tag.Value)
+		if err != nil {
+			continue
+		}
+		// Invalid tags are reported by importStruct
+		if tag, err := parseGoTag(reflect.StructTag(raw)); err == nil && tag.wrapped {
+			return astField, tag, true
+		}
+	}
+	return nil, goTag{}, false
+}
+
+// embeddedName returns the name of an embedded field of type expr
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.Ident:
+		return e
+	}
+	return nil
+}
+
+// goTag holds the ssz struct tags of a field
+type goTag struct {
+	skip     bool
+	sszType  string
+	unix     bool     // time.Time as uint64 unix seconds, from ssz:"uint64,unix"
+	wrapped  bool     // the only field of a wrapper struct, from ssz:",transparent"
+	size     []uint64 // 0 marks a "?" dimension
+	max      []uint64
+	castType string
+}
+
+func parseGoTag(tag reflect.StructTag) (goTag, error) {
+	var t goTag
+	sszType, options, _ := strings.Cut(tag.Get("ssz"), ",")
+	t.sszType = sszType
+	t.skip = t.sszType == "-"
+	for _, option := range strings.Split(options, ",") {
+		switch strings.TrimSpace(option) {
+		case "unix":
+			t.unix = true
+		case "transparent":
+			t.wrapped = true
+		}
+	}
+	t.castType = tag.Get("cast-type")
+	var err error
+	if t.size, err = parseGoTagDims(tag.Get("ssz-size")); err != nil {
+		return t, fmt.Errorf("invalid ssz-size: %w", err)
+	}
+	if t.max, err = parseGoTagDims(tag.Get("ssz-max")); err != nil {
+		return t, fmt.Errorf("invalid ssz-max: %w", err)
+	}
+	return t, nil
+}
+
+// parseGoTagDims parses a comma separated list of dimensions such as "?,32"
+func parseGoTagDims(s string) ([]uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	dims := make([]uint64, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "?" {
+			continue
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		dims[i] = n
+	}
+	return dims, nil
+}
+
+func (t goTag) sizeAt(dim int) uint64 {
+	if dim < len(t.size) {
+		return t.size[dim]
+	}
+	return 0
+}
+
+func (t goTag) maxAt(dim int) uint64 {
+	if dim < len(t.max) {
+		return t.max[dim]
+	}
+	return 0
+}
+
+var bitvectorCastType = regexp.MustCompile(`go-bitfield\.Bitvector(\d+)$`)
+
+// importType converts the type of a field. dim is the slice dimension of expr, which selects
+// the ssz-size and ssz-max values that apply to it.
+func (imp *goImporter) importType(expr ast.Expr, tag goTag, dim int, seen map[string]bool) (Field, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return imp.importType(e.X, tag, dim, seen)
+	case *ast.StarExpr:
+		return imp.importType(e.X, tag, dim, seen)
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok && pkg.Name == "uint256" && e.Sel.Name == "Int" {
+			if tag.sszType == "uint128" {
+				return Field{Type: ssz.TypeUint128}, nil
+			}
+			return Field{Type: ssz.TypeUint256}, nil
+		}
+		if pkg, ok := e.X.(*ast.Ident); ok && pkg.Name == "time" && e.Sel.Name == "Time" && tag.unix {
+			return Field{Type: ssz.TypeUint64}, nil
+		}
+		return Field{}, fmt.Errorf("unsupported type %s.%s from another package", e.X, e.Sel.Name)
+	case *ast.Ident:
+		switch e.Name {
+		case "uint8", "byte":
+			return Field{Type: ssz.TypeUint8}, nil
+		case "uint16":
+			return Field{Type: ssz.TypeUint16}, nil
+		case "uint32":
+			return Field{Type: ssz.TypeUint32}, nil
+		case "uint64":
+			return Field{Type: ssz.TypeUint64}, nil
+		case "bool":
+			return Field{Type: ssz.TypeBoolean}, nil
+		}
+		spec, ok := imp.types[e.Name]
+		if !ok {
+			return Field{}, fmt.Errorf("unsupported type %s", e.Name)
+		}
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			wrapped, wrappedTag, ok := imp.wrappedField(e.Name)
+			if !ok {
+				return Field{Type: ssz.TypeRef, Ref: e.Name}, nil
+			}
+			if seen[e.Name] {
+				return Field{}, fmt.Errorf("recursive type %s", e.Name)
+			}
+			seen[e.Name] = true
+			defer delete(seen, e.Name)
+			// Transparent wrappers are their field, with the tags of the field
+			return imp.importType(wrapped.Type, wrappedTag, 0, seen)
+		}
+		if seen[e.Name] {
+			return Field{}, fmt.Errorf("recursive type %s", e.Name)
+		}
+		seen[e.Name] = true
+		defer delete(seen, e.Name)
+		// Named types such as `type Root [32]byte` take the tags of the field
+		return imp.importType(spec.Type, tag, dim, seen)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return imp.importSlice(e, tag, dim, seen)
+		}
+		lit, ok := e.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return Field{}, fmt.Errorf("array length must be an integer literal")
+		}
+		n, err := strconv.ParseUint(lit.Value, 0, 64)
+		if err != nil {
+			return Field{}, fmt.Errorf("invalid array length %s: %w", lit.Value, err)
+		}
+		return imp.importSequence(e.Elt, ssz.TypeVector, n, tag, dim, seen)
+	default:
+		return Field{}, fmt.Errorf("unsupported type %T", expr)
+	}
+}
+
+// importSlice converts a slice, which is a vector when its dimension has an ssz-size and a
+// list otherwise
+func (imp *goImporter) importSlice(e *ast.ArrayType, tag goTag, dim int, seen map[string]bool) (Field, error) {
+	if dim == 0 && isByteIdent(e.Elt) {
+		switch {
+		case tag.sszType == "bitlist":
+			if tag.maxAt(0) == 0 {
+				return Field{}, fmt.Errorf("bitlist requires ssz-max tag")
+			}
+			return Field{Type: ssz.TypeBitList, Limit: tag.maxAt(0)}, nil
+		case tag.sszType == "bitvector":
+			if tag.sizeAt(0) == 0 {
+				return Field{}, fmt.Errorf("bitvector requires ssz-size tag")
+			}
+			return Field{Type: ssz.TypeBitVector, Size: tag.sizeAt(0)}, nil
+		}
+		// fastssz gives the size of bitvectors in bytes, and the number of bits in the cast type
+		if m := bitvectorCastType.FindStringSubmatch(tag.castType); m != nil {
+			bits, err := strconv.ParseUint(m[1], 10, 64)
+			if err != nil {
+				return Field{}, err
+			}
+			return Field{Type: ssz.TypeBitVector, Size: bits}, nil
+		}
+	}
+
+	if size := tag.sizeAt(dim); size > 0 {
+		return imp.importSequence(e.Elt, ssz.TypeVector, size, tag, dim, seen)
+	}
+	limit := tag.maxAt(dim)
+	if limit == 0 {
+		return Field{}, fmt.Errorf("slice dimension %d needs an ssz-size or ssz-max tag", dim)
+	}
+	return imp.importSequence(e.Elt, ssz.TypeList, limit, tag, dim, seen)
+}
+
+// importSequence converts a vector of n elements or a list limited to n elements
+func (imp *goImporter) importSequence(elt ast.Expr, typ ssz.TypeName, n uint64, tag goTag, dim int, seen map[string]bool) (Field, error) {
+	if typ == ssz.TypeVector && isByteIdent(elt) {
+		return Field{Type: "bytevector", Size: n}, nil
+	}
+	elem, err := imp.importType(elt, tag, dim+1, seen)
+	if err != nil {
+		return Field{}, err
+	}
+	field := Field{Type: typ, Children: []Field{elem}}
+	if typ == ssz.TypeVector {
+		field.Size = n
+	} else {
+		field.Limit = n
+	}
+	return field, nil
+}
+
+func isByteIdent(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && (ident.Name == "byte" || ident.Name == "uint8")
+}
+
+// schemaFieldName converts a Go field name to the lowerCamelCase used in schemas, keeping
+// acronyms together, e.g. ParentRoot to parentRoot and BLSToExecutionChanges to
+// blsToExecutionChanges
+func schemaFieldName(name string) string {
+	runes := []rune(name)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	// Keep the last capital of an acronym when a word follows it
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
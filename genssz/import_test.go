@@ -0,0 +1,221 @@
+package genssz
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gfx-labs/ssz"
+)
+
+const importSource = `package types
+
+import (
+	"time"
+
+	"github.com/holiman/uint256"
+)
+
+type Root [32]byte
+
+type Gwei struct {
+	Value uint64 ` + "`ssz:\",transparent\"`" + `
+}
+
+type Checkpoint struct {
+	Epoch uint64
+	Root  Root
+}
+
+type Attestation struct {
+	AggregationBits []byte      ` + "`ssz:\"bitlist\" ssz-max:\"2048\"`" + `
+	Target          *Checkpoint
+	Signature       []byte      ` + "`ssz-size:\"96\"`" + `
+}
+
+type State struct {
+	Checkpoint
+	BLSChanges        []*Attestation ` + "`ssz-max:\"16\"`" + `
+	BlockRoots        [][]byte       ` + "`ssz-size:\"8,32\"`" + `
+	HistoricalRoots   [][]byte       ` + "`ssz-size:\"?,32\" ssz-max:\"64\"`" + `
+	Transactions      [][]byte       ` + "`ssz-max:\"1024,4096\"`" + `
+	JustificationBits []byte         ` + "`cast-type:\"github.com/prysmaticlabs/go-bitfield.Bitvector4\" ssz-size:\"1\"`" + `
+	SyncBits          []byte         ` + "`ssz:\"bitvector\" ssz-size:\"512\"`" + `
+	Balance           *uint256.Int
+	Small             uint256.Int    ` + "`ssz:\"uint128\"`" + `
+	Flags             [4]bool
+	Genesis           time.Time      ` + "`ssz:\"uint64,unix\"`" + `
+	Balances          []Gwei         ` + "`ssz-max:\"8\"`" + `
+	Cache             []byte ` + "`ssz:\"-\"`" + `
+	hidden            uint64
+}
+
+type Unused struct {
+	A uint16
+	B uint32
+}
+`
+
+func writeImportSource(t *testing.T, source string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "types.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestImportGoPackage(t *testing.T) {
+	dir := writeImportSource(t, importSource)
+
+	schema, err := ImportGoPackage(dir, "State")
+	if err != nil {
+		t.Fatalf("ImportGoPackage failed: %v", err)
+	}
+
+	expected := &Schema{
+		Package: "types",
+		Structs: []Field{
+			{Name: "Checkpoint", Type: ssz.TypeContainer, Children: []Field{
+				{Name: "epoch", Type: ssz.TypeUint64},
+				{Name: "root", Type: "bytevector", Size: 32},
+			}},
+			{Name: "Attestation", Type: ssz.TypeContainer, Children: []Field{
+				{Name: "aggregationBits", Type: ssz.TypeBitList, Limit: 2048},
+				{Name: "target", Type: ssz.TypeRef, Ref: "Checkpoint"},
+				{Name: "signature", Type: "bytevector", Size: 96},
+			}},
+			{Name: "State", Type: ssz.TypeContainer, Children: []Field{
+				// The embedded checkpoint is inlined
+				{Name: "epoch", Type: ssz.TypeUint64},
+				{Name: "root", Type: "bytevector", Size: 32},
+				{Name: "blsChanges", Type: ssz.TypeList, Limit: 16, Children: []Field{
+					{Type: ssz.TypeRef, Ref: "Attestation"},
+				}},
+				{Name: "blockRoots", Type: ssz.TypeVector, Size: 8, Children: []Field{
+					{Type: "bytevector", Size: 32},
+				}},
+				{Name: "historicalRoots", Type: ssz.TypeList, Limit: 64, Children: []Field{
+					{Type: "bytevector", Size: 32},
+				}},
+				{Name: "transactions", Type: ssz.TypeList, Limit: 1024, Children: []Field{
+					{Type: ssz.TypeList, Limit: 4096, Children: []Field{{Type: ssz.TypeUint8}}},
+				}},
+				{Name: "justificationBits", Type: ssz.TypeBitVector, Size: 4},
+				{Name: "syncBits", Type: ssz.TypeBitVector, Size: 512},
+				{Name: "balance", Type: ssz.TypeUint256},
+				{Name: "small", Type: ssz.TypeUint128},
+				{Name: "flags", Type: ssz.TypeVector, Size: 4, Children: []Field{{Type: ssz.TypeBoolean}}},
+				{Name: "genesis", Type: ssz.TypeUint64},
+				{Name: "balances", Type: ssz.TypeList, Limit: 8, Children: []Field{{Type: ssz.TypeUint64}}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("unexpected schema\ngot:      %+v\nexpected: %+v", schema, expected)
+	}
+
+	// The YAML must read back to the same schema
+	data, err := MarshalSchema(schema)
+	if err != nil {
+		t.Fatalf("MarshalSchema failed: %v", err)
+	}
+	read, err := ReadSchemaFromBytes(data)
+	if err != nil {
+		t.Fatalf("ReadSchemaFromBytes failed: %v", err)
+	}
+	if !reflect.DeepEqual(read, schema) {
+		t.Errorf("schema changed after YAML round trip:\n%s", data)
+	}
+}
+
+func TestImportGoPackage_Inline(t *testing.T) {
+	dir := writeImportSource(t, `package types
+
+type header struct {
+	Slot  uint64
+	flags uint8
+}
+
+type Extra struct {
+	Count uint16
+}
+
+type Block struct {
+	header
+	Extra `+"`ssz:\"container\"`"+`
+	Body  Extra `+"`ssz:\"inline\"`"+`
+}
+`)
+
+	schema, err := ImportGoPackage(dir, "Block")
+	if err != nil {
+		t.Fatalf("ImportGoPackage failed: %v", err)
+	}
+	block := schema.Structs[len(schema.Structs)-1]
+	expected := []Field{
+		{Name: "slot", Type: ssz.TypeUint64},
+		{Name: "extra", Type: ssz.TypeRef, Ref: "Extra"},
+		{Name: "count", Type: ssz.TypeUint16},
+	}
+	if block.Name != "Block" || !reflect.DeepEqual(block.Children, expected) {
+		t.Errorf("unexpected fields of %s\ngot:      %+v\nexpected: %+v", block.Name, block.Children, expected)
+	}
+}
+
+func TestImportGoPackage_AllStructs(t *testing.T) {
+	dir := writeImportSource(t, importSource)
+
+	schema, err := ImportGoPackage(dir)
+	if err != nil {
+		t.Fatalf("ImportGoPackage failed: %v", err)
+	}
+	var names []string
+	for _, s := range schema.Structs {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "Checkpoint,Attestation,State,Unused" {
+		t.Errorf("expected structs in declaration order, got %s", got)
+	}
+}
+
+func TestImportGoPackage_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		types  []string
+		err    string
+	}{
+		{"slice without tags", "package p\ntype A struct{ B []uint64 }\n", nil, "A.B: slice dimension 0 needs an ssz-size or ssz-max tag"},
+		{"bitlist without max", "package p\ntype A struct{ B []byte `ssz:\"bitlist\"` }\n", nil, "A.B: bitlist requires ssz-max tag"},
+		{"unsupported type", "package p\ntype A struct{ B int }\n", nil, "A.B: unsupported type int"},
+		{"foreign type", "package p\nimport \"time\"\ntype A struct{ B time.Time }\n", nil, "A.B: unsupported type time.Time from another package"},
+		{"unknown type name", "package p\ntype A struct{ B uint64 }\n", []string{"C"}, "type C not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeImportSource(t, tt.source)
+			_, err := ImportGoPackage(dir, tt.types...)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSchemaFieldName(t *testing.T) {
+	tests := map[string]string{
+		"ParentRoot":            "parentRoot",
+		"Eth1Data":              "eth1Data",
+		"BLSToExecutionChanges": "blsToExecutionChanges",
+		"ID":                    "id",
+		"Slot":                  "slot",
+	}
+	for in, expected := range tests {
+		if got := schemaFieldName(in); got != expected {
+			t.Errorf("schemaFieldName(%s) = %s, expected %s", in, got, expected)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package genssz
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// A schema can refer to containers generated into other packages, named by the packages key:
+//
+//	package: beacon
+//	packages:
+//	  common: github.com/example/types/common
+//	structs:
+//	  - name: Block
+//	    type: container
+//	    children:
+//	      - {name: finalized, type: ref, ref: common.Checkpoint}
+//
+// LinkSchemas reads the layout of common.Checkpoint from the schema of the package, the one with
+// that import_path, and the generated code imports the package to use its type.
+
+// LinkSchemas returns a copy of schema whose refs to the types of other packages are resolved
+// against deps, the schemas of those packages and of the packages they refer to, matched by their
+// ImportPath. The types of deps are added to the copy, qualified with their import path, and are
+// not generated. A schema without Packages is returned as is.
+func LinkSchemas(schema *Schema, deps ...*Schema) (*Schema, error) {
+	if len(schema.Packages) == 0 {
+		return schema, nil
+	}
+	byPath := make(map[string]*Schema, len(deps))
+	for _, dep := range deps {
+		if dep.ImportPath == "" {
+			return nil, fmt.Errorf("schema of package %s has no import_path", dep.Package)
+		}
+		if _, ok := byPath[dep.ImportPath]; ok {
+			return nil, fmt.Errorf("two schemas of package %s", dep.ImportPath)
+		}
+		byPath[dep.ImportPath] = dep
+	}
+
+	l := &linker{byPath: byPath, linked: make(map[string]bool)}
+	structs, err := l.qualify(schema, "")
+	if err != nil {
+		return nil, err
+	}
+	linked := *schema
+	linked.Structs = append(structs, l.imported...)
+	return &linked, nil
+}
+
+// linker collects the types of the packages a schema refers to
+type linker struct {
+	byPath   map[string]*Schema
+	linked   map[string]bool
+	imported []Field
+}
+
+// qualify returns the structs of a schema with refs to other packages resolved to the
+// qualified names of their types, and links those packages. path is the import path the types
+// of the schema are qualified with, empty for the schema being generated.
+func (l *linker) qualify(schema *Schema, path string) ([]Field, error) {
+	structs := make([]Field, len(schema.Structs))
+	for i, s := range schema.Structs {
+		var err error
+		if structs[i], err = l.qualifyField(schema, path, s); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		if path != "" {
+			structs[i].Name = path + "." + s.Name
+			structs[i].pkg = path
+		}
+	}
+	return structs, nil
+}
+
+func (l *linker) qualifyField(schema *Schema, path string, f Field) (Field, error) {
+	if f.Ref != "" {
+		alias, name, ok := strings.Cut(f.Ref, ".")
+		switch {
+		case ok:
+			depPath, known := schema.Packages[alias]
+			if !known {
+				return f, fmt.Errorf("ref %s: unknown package %s", f.Ref, alias)
+			}
+			if err := l.link(depPath); err != nil {
+				return f, err
+			}
+			if _, found := findStruct(l.byPath[depPath], name); !found {
+				return f, fmt.Errorf("ref %s: package %s has no type %s", f.Ref, depPath, name)
+			}
+			f.Ref = depPath + "." + name
+		case path != "":
+			f.Ref = path + "." + f.Ref
+		}
+	}
+	if len(f.Children) > 0 {
+		children := make([]Field, len(f.Children))
+		for i, child := range f.Children {
+			var err error
+			if children[i], err = l.qualifyField(schema, path, child); err != nil {
+				return f, err
+			}
+		}
+		f.Children = children
+	}
+	return f, nil
+}
+
+// link adds the types of the package with the import path, once
+func (l *linker) link(path string) error {
+	if l.linked[path] {
+		return nil
+	}
+	l.linked[path] = true
+	dep, ok := l.byPath[path]
+	if !ok {
+		return fmt.Errorf("no schema for package %s", path)
+	}
+	structs, err := l.qualify(dep, path)
+	if err != nil {
+		return fmt.Errorf("package %s: %w", path, err)
+	}
+	l.imported = append(l.imported, structs...)
+	return nil
+}
+
+// refType returns the Go type of a ref, imported from its package for the types of other
+// packages, which LinkSchemas qualifies with their import path
+func refType(ref string) *jen.Statement {
+	if i := strings.LastIndex(ref, "."); i >= 0 {
+		return jen.Qual(ref[:i], ref[i+1:])
+	}
+	return jen.Id(ref)
+}
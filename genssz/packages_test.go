@@ -0,0 +1,125 @@
+package genssz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const packagesCommonSchema = `
+package: common
+import_path: example.com/types/common
+structs:
+  - name: Root
+    type: bytevector
+    size: 32
+  - name: Checkpoint
+    type: container
+    children:
+      - {name: epoch, type: uint64}
+      - {name: root, type: ref, ref: Root}
+`
+
+const packagesBeaconSchema = `
+package: beacon
+packages:
+  common: example.com/types/common
+structs:
+  - name: Vote
+    type: container
+    children:
+      - {name: slot, type: uint64}
+      - {name: target, type: ref, ref: common.Checkpoint}
+`
+
+func TestLinkSchemas(t *testing.T) {
+	common, err := ReadSchemaFromBytes([]byte(packagesCommonSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	beacon, err := ReadSchemaFromBytes([]byte(packagesBeaconSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked, err := LinkSchemas(beacon, common)
+	if err != nil {
+		t.Fatalf("LinkSchemas failed: %v", err)
+	}
+	if got := linked.Structs[0].Children[1].Ref; got != "example.com/types/common.Checkpoint" {
+		t.Errorf("ref resolved to %s", got)
+	}
+	if len(beacon.Structs) != 1 || beacon.Structs[0].Children[1].Ref != "common.Checkpoint" {
+		t.Error("LinkSchemas changed its argument")
+	}
+
+	world, err := ParseSchemaToWorld(linked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := GenerateCode(world, linked)
+	if err != nil {
+		t.Fatalf("GenerateCode failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := code.Render(&buf); err != nil {
+		t.Fatalf("Failed to render code: %v", err)
+	}
+	generated := buf.String()
+	for _, expected := range []string{
+		`common "example.com/types/common"`,
+		"func NewVoteWithValues(slot uint64, target common.Checkpoint) Vote",
+		"func (s Vote) SizeSSZ() int {\n\treturn 48",
+		"func (s Vote) Target() common.Checkpoint {\n\treturn common.Checkpoint(s[8:48])",
+		"func (s Vote) SetTarget(v common.Checkpoint) {",
+		"refData := common.Checkpoint(s[8:48])",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+	if strings.Contains(generated, "type Checkpoint") {
+		t.Error("Generated code has a type for Checkpoint, which is in another package")
+	}
+
+	// The tests of types referring to other packages cannot reach their mirror structs
+	tests, err := GenerateTests(world, linked)
+	if err != nil {
+		t.Fatalf("GenerateTests failed: %v", err)
+	}
+	buf.Reset()
+	if err := tests.Render(&buf); err != nil {
+		t.Fatalf("Failed to render tests: %v", err)
+	}
+	if strings.Contains(buf.String(), "testVoteValue") {
+		t.Error("Generated a test for Vote")
+	}
+}
+
+func TestLinkSchemas_Errors(t *testing.T) {
+	common, err := ReadSchemaFromBytes([]byte(packagesCommonSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		ref  string
+		deps []*Schema
+		want string
+	}{
+		"unknown package": {ref: "other.Checkpoint", deps: []*Schema{common}, want: "unknown package other"},
+		"no schema":       {ref: "common.Checkpoint", want: "no schema for package example.com/types/common"},
+		"unknown type":    {ref: "common.Header", deps: []*Schema{common}, want: "package example.com/types/common has no type Header"},
+		"no import path":  {ref: "common.Checkpoint", deps: []*Schema{{Package: "common"}}, want: "has no import_path"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema, err := ReadSchemaFromBytes([]byte(strings.Replace(packagesBeaconSchema, "common.Checkpoint", tt.ref, 1)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = LinkSchemas(schema, tt.deps...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package genssz
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// ManifestFile lists the files written by WriteSplit, one name per line, so build systems can
+// track the outputs and stale files can be removed when types go away
+const ManifestFile = "genssz.manifest"
+
+// HelpersFile is the name of the file holding the package-level code shared by the types
+const HelpersFile = "ssz_helpers.gen.go"
+
+// GeneratedFile is one output file of a split generation
+type GeneratedFile struct {
+	Name string
+	File *jen.File
+}
+
+// GenerateFiles generates the same code as GenerateCode, split into one file per top-level
+// container plus HelpersFile. Files are named after their type, e.g. BeaconBlockHeader is
+// written to beacon_block_header.gen.go, and sorted by name.
+func GenerateFiles(world *World, schema *Schema) ([]GeneratedFile, error) {
+	helpers := newGeneratedFile(schema.Package)
+	files := []GeneratedFile{{Name: HelpersFile, File: helpers}}
+	owners := map[string]string{HelpersFile: "shared helpers"}
+
+	var generated []string
+	for _, structDef := range schema.Structs {
+		name := splitFileName(structDef.Name)
+		if owner, ok := owners[name]; ok {
+			return nil, fmt.Errorf("%s and %s would both be written to %s", owner, structDef.Name, name)
+		}
+
+		f := newGeneratedFile(schema.Package)
+		ok, err := generateType(f, structDef, schema)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		owners[name] = structDef.Name
+		files = append(files, GeneratedFile{Name: name, File: f})
+		generated = append(generated, structDef.Name)
+	}
+
+	helpers.Comment("Types generated by genssz, one file per type:")
+	for _, name := range generated {
+		helpers.Comment("  - " + name)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// splitFileName returns the file name for a type, the snake_case of its name
+func splitFileName(typeName string) string {
+	runes := []rune(typeName)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String() + ".gen.go"
+}
+
+// WriteSplit writes files to dir along with ManifestFile. Files listed in the previous manifest
+// that are no longer generated are removed, so the directory only holds the current outputs.
+func WriteSplit(dir string, files []GeneratedFile) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	previous, err := readManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]bool, len(files))
+	manifest := new(bytes.Buffer)
+	for _, file := range files {
+		buf := new(bytes.Buffer)
+		if err := file.File.Render(buf); err != nil {
+			return fmt.Errorf("failed to render %s: %w", file.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file.Name), buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+		current[file.Name] = true
+		fmt.Fprintln(manifest, file.Name)
+	}
+
+	for _, name := range previous {
+		if current[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove stale %s: %w", name, err)
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), manifest.Bytes(), 0o644)
+}
+
+// readManifest returns the file names in a manifest, or nothing if there is none
+func readManifest(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" {
+			continue
+		}
+		// Only ever remove plain file names from dir
+		if filepath.Base(name) != name || !strings.HasSuffix(name, ".go") {
+			return nil, fmt.Errorf("%s: invalid entry %q", path, name)
+		}
+		names = append(names, name)
+	}
+	return names, scanner.Err()
+}
@@ -0,0 +1,151 @@
+package genssz
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const splitSchemaYAML = `
+package: testpkg
+structs:
+  - name: Checkpoint
+    type: container
+    children:
+      - name: epoch
+        type: uint64
+      - name: root
+        type: bytevector
+        size: 32
+  - name: BLSChange
+    type: container
+    children:
+      - name: target
+        type: ref
+        ref: Checkpoint
+  - name: Block
+    type: container
+    children:
+      - name: data
+        type: list
+        limit: 16
+        children:
+          - type: uint8
+`
+
+func generateSplit(t *testing.T, schemaYAML string) []GeneratedFile {
+	t.Helper()
+	schema, err := ReadSchemaFromBytes([]byte(schemaYAML))
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	world, err := ParseSchemaToWorld(schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema to world: %v", err)
+	}
+	files, err := GenerateFiles(world, schema)
+	if err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+	return files
+}
+
+func TestGenerateFiles(t *testing.T) {
+	files := generateSplit(t, splitSchemaYAML)
+
+	// Variable-size Block is skipped, like in GenerateCode
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	if got := strings.Join(names, ","); got != "bls_change.gen.go,checkpoint.gen.go,ssz_helpers.gen.go" {
+		t.Fatalf("unexpected files %s", got)
+	}
+
+	rendered := make(map[string]string)
+	for _, file := range files {
+		var buf bytes.Buffer
+		if err := file.File.Render(&buf); err != nil {
+			t.Fatalf("Failed to render %s: %v", file.Name, err)
+		}
+		rendered[file.Name] = buf.String()
+	}
+	if !strings.Contains(rendered["checkpoint.gen.go"], "type Checkpoint []byte") {
+		t.Error("checkpoint.gen.go should declare Checkpoint")
+	}
+	if strings.Contains(rendered["checkpoint.gen.go"], "BLSChange") {
+		t.Error("checkpoint.gen.go should only hold Checkpoint")
+	}
+	if !strings.Contains(rendered["bls_change.gen.go"], "func (s BLSChange) Target() Checkpoint") {
+		t.Error("bls_change.gen.go should declare the BLSChange getters")
+	}
+	if !strings.Contains(rendered[HelpersFile], "//   - Checkpoint\n//   - BLSChange\n") {
+		t.Errorf("helpers file should list the generated types:\n%s", rendered[HelpersFile])
+	}
+
+	// Output must not depend on anything but the schema
+	again := generateSplit(t, splitSchemaYAML)
+	for i, file := range again {
+		var buf bytes.Buffer
+		if err := file.File.Render(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if file.Name != files[i].Name || buf.String() != rendered[file.Name] {
+			t.Errorf("%s differs between runs", file.Name)
+		}
+	}
+}
+
+func TestGenerateFiles_NameCollision(t *testing.T) {
+	schema := &Schema{Package: "testpkg", Structs: []Field{
+		{Name: "SszHelpers", Type: "container", Children: []Field{{Name: "a", Type: "uint8"}}},
+	}}
+	_, err := GenerateFiles(&World{}, schema)
+	if err == nil || !strings.Contains(err.Error(), "would both be written to ssz_helpers.gen.go") {
+		t.Errorf("expected a file name collision, got %v", err)
+	}
+}
+
+func TestWriteSplit(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteSplit(dir, generateSplit(t, splitSchemaYAML)); err != nil {
+		t.Fatalf("WriteSplit failed: %v", err)
+	}
+	manifest, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(manifest) != "bls_change.gen.go\ncheckpoint.gen.go\nssz_helpers.gen.go\n" {
+		t.Errorf("unexpected manifest:\n%s", manifest)
+	}
+
+	// Files of removed types are deleted, other files in the directory are kept
+	if err := os.WriteFile(filepath.Join(dir, "types.go"), []byte("package testpkg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	checkpointOnly := splitSchemaYAML[:strings.Index(splitSchemaYAML, "  - name: BLSChange")]
+	if err := WriteSplit(dir, generateSplit(t, checkpointOnly)); err != nil {
+		t.Fatalf("WriteSplit failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bls_change.gen.go")); !os.IsNotExist(err) {
+		t.Errorf("stale bls_change.gen.go should be removed, got %v", err)
+	}
+	for _, name := range []string{"checkpoint.gen.go", HelpersFile, "types.go"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+}
+
+func TestWriteSplit_InvalidManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte("../outside.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteSplit(dir, generateSplit(t, splitSchemaYAML))
+	if err == nil || !strings.Contains(err.Error(), `invalid entry "../outside.go"`) {
+		t.Errorf("expected an invalid manifest error, got %v", err)
+	}
+}
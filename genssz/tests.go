@@ -0,0 +1,270 @@
+package genssz
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/gfx-labs/ssz"
+)
+
+// TestIterations is the number of random values each generated test checks
+const TestIterations = 64
+
+// GenerateTests generates tests for the code of GenerateCode. For each generated type, a struct
+// mirroring its layout is filled with random values and encoded with flexssz, and the generated
+// type is checked to read the same fields, marshal the same bytes, decode back to the same value
+// and hash to the same root as flexssz. Types whose fields the generated code cannot hash, like
+// vectors of integers, have no test.
+func GenerateTests(world *World, schema *Schema) (*jen.File, error) {
+	f := newTestFile(schema.Package)
+	for _, structDef := range schema.Structs {
+		if _, err := generateTypeTest(f, structDef, schema); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// GenerateTestFiles generates the tests of GenerateTests split like GenerateFiles, each type's
+// tests in a file named after its code file, e.g. beacon_block_header.gen_test.go
+func GenerateTestFiles(world *World, schema *Schema) ([]GeneratedFile, error) {
+	var files []GeneratedFile
+	for _, structDef := range schema.Structs {
+		f := newTestFile(schema.Package)
+		ok, err := generateTypeTest(f, structDef, schema)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(splitFileName(structDef.Name), ".go") + "_test.go"
+		files = append(files, GeneratedFile{Name: name, File: f})
+	}
+	return files, nil
+}
+
+// newTestFile creates a test file with the generated code header and imports
+func newTestFile(pkg string) *jen.File {
+	f := jen.NewFile(pkg)
+	f.HeaderComment("Code generated by genssz. DO NOT EDIT.")
+	f.ImportName("github.com/gfx-labs/ssz/flexssz", "flexssz")
+	f.ImportName("github.com/holiman/uint256", "uint256")
+	f.ImportName("math/rand/v2", "rand")
+	return f
+}
+
+// generateTypeTest generates the mirror struct, random value function and test of a top-level
+// struct, and reports whether anything was generated
+func generateTypeTest(f *jen.File, structDef Field, schema *Schema) (bool, error) {
+	if structDef.GoType != "" || structDef.pkg != "" {
+		return false, nil
+	}
+	exts, err := externalFields(structDef, schema)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve go types of %s: %w", structDef.Name, err)
+	}
+	sszField := inlineExternalRefs(structDef.ToSSZField(), schema)
+	isFixed, err := isFixedSize(sszField, schema)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if %s is fixed size: %w", structDef.Name, err)
+	}
+	if !isFixed || !testable(sszField, schema, nil) {
+		return false, nil
+	}
+
+	generateTestValue(f, sszField)
+	generateRandomValue(f, sszField)
+	generateTypeTestFunc(f, sszField, exts)
+	return true, nil
+}
+
+// testable reports whether the generated code of a struct can be checked against flexssz, which
+// needs every field and every type it refers to to be hashed by the generated code
+func testable(structDef ssz.Field, schema *Schema, seen map[string]bool) bool {
+	for _, field := range structDef.Children {
+		switch field.Type {
+		case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeBoolean,
+			ssz.TypeUint128, ssz.TypeUint256, ssz.TypeBitVector:
+		case ssz.TypeVector:
+			if len(field.Children) == 0 || field.Children[0].Type != ssz.TypeUint8 {
+				return false
+			}
+		case ssz.TypeRef:
+			if seen[field.Ref] {
+				continue
+			}
+			if seen == nil {
+				seen = make(map[string]bool)
+			}
+			seen[field.Ref] = true
+			// The mirror structs of types of other packages are in their tests, out of reach
+			ref, ok := findStruct(schema, field.Ref)
+			if !ok || ref.pkg != "" || !testable(inlineExternalRefs(ref.ToSSZField(), schema), schema, seen) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// findStruct returns the top-level struct of a schema with the name
+func findStruct(schema *Schema, name string) (Field, bool) {
+	for _, s := range schema.Structs {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Field{}, false
+}
+
+// testValueName is the name of the struct mirroring a generated type for flexssz
+func testValueName(typeName string) string {
+	return "test" + typeName + "Value"
+}
+
+// generateTestValue generates a struct with the layout of a generated type, tagged for flexssz
+func generateTestValue(f *jen.File, structDef ssz.Field) {
+	name := testValueName(structDef.Name)
+	fields := make([]jen.Code, 0, len(structDef.Children))
+	for _, field := range structDef.Children {
+		id := jen.Id(capitalizeFirst(field.Name))
+		switch field.Type {
+		case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64:
+			id.Id(string(field.Type))
+		case ssz.TypeBoolean:
+			id.Bool()
+		case ssz.TypeUint128, ssz.TypeUint256:
+			id.Qual("github.com/holiman/uint256", "Int").Tag(map[string]string{"ssz": string(field.Type)})
+		case ssz.TypeBitVector:
+			id.Op("[]").Byte().Tag(map[string]string{"ssz": "bitvector", "ssz-size": fmt.Sprint(field.Size)})
+		case ssz.TypeVector:
+			id.Op("[").Lit(int(field.Size)).Op("]").Byte()
+		case ssz.TypeRef:
+			id.Id(testValueName(field.Ref))
+		}
+		fields = append(fields, id)
+	}
+	f.Comment(fmt.Sprintf("%s mirrors the layout of %s for flexssz", name, structDef.Name))
+	f.Type().Id(name).Struct(fields...)
+	f.Line()
+}
+
+// generateRandomValue generates a function returning a random value of a mirror struct. Bits
+// past the length of bitvectors are left unset, since they are not valid SSZ.
+func generateRandomValue(f *jen.File, structDef ssz.Field) {
+	name := testValueName(structDef.Name)
+	body := []jen.Code{jen.Var().Id("v").Id(name)}
+	randomBytes := func(dst *jen.Statement) jen.Code {
+		return jen.For(jen.Id("i").Op(":=").Range().Add(dst)).Block(
+			jen.Add(dst).Index(jen.Id("i")).Op("=").Byte().Call(jen.Id("r").Dot("Uint32").Call()),
+		)
+	}
+	for _, field := range structDef.Children {
+		dst := jen.Id("v").Dot(capitalizeFirst(field.Name))
+		switch field.Type {
+		case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32:
+			body = append(body, dst.Op("=").Id(string(field.Type)).Call(jen.Id("r").Dot("Uint32").Call()))
+		case ssz.TypeUint64:
+			body = append(body, dst.Op("=").Id("r").Dot("Uint64").Call())
+		case ssz.TypeBoolean:
+			body = append(body, dst.Op("=").Id("r").Dot("IntN").Call(jen.Lit(2)).Op("==").Lit(1))
+		case ssz.TypeUint128:
+			body = append(body, dst.Op("=").Qual("github.com/holiman/uint256", "Int").Values(
+				jen.Id("r").Dot("Uint64").Call(), jen.Id("r").Dot("Uint64").Call(),
+			))
+		case ssz.TypeUint256:
+			body = append(body, dst.Op("=").Qual("github.com/holiman/uint256", "Int").Values(
+				jen.Id("r").Dot("Uint64").Call(), jen.Id("r").Dot("Uint64").Call(),
+				jen.Id("r").Dot("Uint64").Call(), jen.Id("r").Dot("Uint64").Call(),
+			))
+		case ssz.TypeBitVector:
+			byteSize := int((field.Size + 7) / 8)
+			body = append(body,
+				jen.Id("v").Dot(capitalizeFirst(field.Name)).Op("=").Make(jen.Op("[]").Byte(), jen.Lit(byteSize)),
+				randomBytes(jen.Id("v").Dot(capitalizeFirst(field.Name))),
+			)
+			if bits := field.Size % 8; bits != 0 {
+				body = append(body, jen.Id("v").Dot(capitalizeFirst(field.Name)).Index(jen.Lit(byteSize-1)).Op("&=").Lit(int(1<<bits-1)))
+			}
+		case ssz.TypeVector:
+			body = append(body, randomBytes(dst))
+		case ssz.TypeRef:
+			body = append(body, dst.Op("=").Id("random"+capitalizeFirst(testValueName(field.Ref))).Call(jen.Id("r")))
+		}
+	}
+	body = append(body, jen.Return(jen.Id("v")))
+
+	f.Comment(fmt.Sprintf("random%s returns a random value of %s", capitalizeFirst(name), structDef.Name))
+	f.Func().Id("random" + capitalizeFirst(name)).Params(jen.Id("r").Op("*").Qual("math/rand/v2", "Rand")).Id(name).Block(body...)
+	f.Line()
+}
+
+// generateTypeTestFunc generates the test of a generated type against flexssz
+func generateTypeTestFunc(f *jen.File, structDef ssz.Field, exts map[string]*externalType) {
+	typeName := structDef.Name
+	fatal := func(format string, args ...jen.Code) jen.Code {
+		return jen.Id("t").Dot("Fatalf").Call(append([]jen.Code{jen.Lit("value %d: " + format), jen.Id("i")}, args...)...)
+	}
+	check := func(cond jen.Code, format string, args ...jen.Code) jen.Code {
+		return jen.If(cond).Block(fatal(format, args...))
+	}
+	checkGot := func(got, cond jen.Code, format string, args ...jen.Code) jen.Code {
+		return jen.If(jen.Id("got").Op(":=").Add(got), cond).Block(fatal(format, args...))
+	}
+	checkErr := func(call jen.Code, what string) []jen.Code {
+		return []jen.Code{
+			call,
+			check(jen.Err().Op("!=").Nil(), what+": %v", jen.Err()),
+		}
+	}
+
+	loop := []jen.Code{
+		jen.Id("value").Op(":=").Id("random" + capitalizeFirst(testValueName(typeName))).Call(jen.Id("r")),
+	}
+	loop = append(loop, checkErr(jen.List(jen.Id("data"), jen.Err()).Op(":=").Qual("github.com/gfx-labs/ssz/flexssz", "Marshal").Call(jen.Op("&").Id("value")), "flexssz.Marshal")...)
+	loop = append(loop,
+		jen.Id("view").Op(":=").Id(typeName).Call(jen.Id("data")),
+		check(jen.Id("view").Dot("SizeSSZ").Call().Op("!=").Len(jen.Id("data")), "SizeSSZ %d, flexssz encoded %d bytes", jen.Id("view").Dot("SizeSSZ").Call(), jen.Len(jen.Id("data"))),
+	)
+
+	// Fields read by the getters, except external types which are checked through the encoding
+	for _, field := range structDef.Children {
+		if _, ok := exts[field.Name]; ok {
+			continue
+		}
+		method := capitalizeFirst(field.Name)
+		got := jen.Id("view").Dot(method).Call()
+		want := jen.Id("value").Dot(method)
+		switch field.Type {
+		case ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeBoolean:
+			loop = append(loop, checkGot(got, jen.Id("got").Op("!=").Add(want), method+" %v, expected %v", jen.Id("got"), want))
+		case ssz.TypeVector:
+			loop = append(loop, checkGot(got, jen.Id("got").Op("!=").Add(want), method+" %x, expected %x", jen.Id("got"), want))
+		case ssz.TypeBitVector:
+			loop = append(loop, checkGot(got, jen.Op("!").Qual("bytes", "Equal").Call(jen.Id("got").Index(jen.Op(":")), want), method+" %x, expected %x", jen.Id("got"), want))
+		}
+	}
+
+	loop = append(loop, checkErr(jen.List(jen.Id("encoded"), jen.Err()).Op(":=").Id("view").Dot("MarshalSSZ").Call(), "MarshalSSZ")...)
+	loop = append(loop,
+		check(jen.Op("!").Qual("bytes", "Equal").Call(jen.Id("encoded"), jen.Id("data")), "MarshalSSZ %x, flexssz encoded %x", jen.Id("encoded"), jen.Id("data")),
+		jen.Var().Id("decoded").Id(testValueName(typeName)),
+	)
+	loop = append(loop, checkErr(jen.Err().Op("=").Qual("github.com/gfx-labs/ssz/flexssz", "UnmarshalStrict").Call(jen.Id("encoded"), jen.Op("&").Id("decoded")), "flexssz.UnmarshalStrict")...)
+	loop = append(loop, check(jen.Op("!").Qual("reflect", "DeepEqual").Call(jen.Id("decoded"), jen.Id("value")), "decoded %+v, expected %+v", jen.Id("decoded"), jen.Id("value")))
+	loop = append(loop, checkErr(jen.List(jen.Id("root"), jen.Err()).Op(":=").Id("view").Dot("HashSSZ").Call(), "HashSSZ")...)
+	loop = append(loop, checkErr(jen.List(jen.Id("expected"), jen.Err()).Op(":=").Qual("github.com/gfx-labs/ssz/flexssz", "HashTreeRoot").Call(jen.Op("&").Id("value")), "flexssz.HashTreeRoot")...)
+	loop = append(loop, check(jen.Id("root").Op("!=").Id("expected"), "HashSSZ %x, flexssz.HashTreeRoot %x", jen.Id("root"), jen.Id("expected")))
+
+	testName := "Test" + typeName + "_Generated"
+	f.Comment(fmt.Sprintf("%s checks random values of %s against flexssz", testName, typeName))
+	f.Func().Id(testName).Params(jen.Id("t").Op("*").Qual("testing", "T")).Block(
+		jen.Id("r").Op(":=").Qual("math/rand/v2", "New").Call(jen.Qual("math/rand/v2", "NewPCG").Call(jen.Lit(1), jen.Lit(2))),
+		jen.For(jen.Id("i").Op(":=").Lit(0), jen.Id("i").Op("<").Lit(TestIterations), jen.Id("i").Op("++")).Block(loop...),
+	)
+	f.Line()
+}
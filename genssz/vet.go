@@ -0,0 +1,401 @@
+package genssz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gfx-labs/ssz"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Diagnostic is a problem found in a schema by VetSchemas
+type Diagnostic struct {
+	File   string
+	Line   int
+	Column int
+	// Path of the field, e.g. "BeaconBlock.body.attestations", or empty for the file itself
+	Path    string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	pos := d.File
+	if d.Line > 0 {
+		pos = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	if d.Path == "" {
+		return fmt.Sprintf("%s: %s", pos, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", pos, d.Path, d.Message)
+}
+
+// SchemaFile is the source of a schema, named in diagnostics
+type SchemaFile struct {
+	Name string
+	Data []byte
+}
+
+// schemaKeys are the keys read from a field, and schemaTypes the types a field may have
+var (
+	schemaKeys  = []string{"name", "type", "size", "limit", "ref", "children", "go_type", "codec"}
+	schemaTypes = []ssz.TypeName{
+		ssz.TypeUint8, ssz.TypeUint16, ssz.TypeUint32, ssz.TypeUint64, ssz.TypeUint128, ssz.TypeUint256,
+		ssz.TypeBoolean, ssz.TypeContainer, ssz.TypeVector, ssz.TypeList, ssz.TypeBitVector, ssz.TypeBitList,
+		ssz.TypeUnion, ssz.TypeStableContainer, ssz.TypeProfile, ssz.TypeOptional, ssz.TypeRef, "bytevector",
+	}
+)
+
+// VetSchemas checks the schemas that would be combined for generation, reporting every problem
+// with its position instead of stopping at the first one: YAML that does not parse, unknown keys
+// and types, missing names, sizes and limits, duplicate type names, refs to unknown types and
+// types that refer to themselves. Types without such problems are then checked with
+// ssz.Field.IsValid, and the combined schema with ParseSchemaToWorld. No diagnostics means the
+// schemas can be generated.
+func VetSchemas(files ...SchemaFile) []Diagnostic {
+	v := &vetter{types: make(map[string]*vetType), files: make(map[string]int), packages: make(map[string]bool)}
+	for i, file := range files {
+		v.files[file.Name] = i
+		v.readFile(file)
+	}
+	if v.pkg == "" && v.parsed {
+		v.add(files[0].Name, nil, "", "no package name specified in any schema")
+	}
+	for _, typ := range v.order {
+		v.walkField(typ, typ.node, typ.field, typ.field.Name, false)
+	}
+	v.checkCycles()
+	v.checkValid()
+
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		a, b := v.diags[i], v.diags[j]
+		if a.File != b.File {
+			return v.files[a.File] < v.files[b.File]
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.diags
+}
+
+// vetType is a top-level type of a schema and where it was defined
+type vetType struct {
+	file  string
+	node  *yamlv3.Node
+	field Field
+	// refs made anywhere in the type, with the nodes making them
+	refs     []string
+	refNodes []*yamlv3.Node
+	// bad is set once the type has a diagnostic
+	bad bool
+	// linked is set for types that refer to other packages, which are checked when linked
+	linked bool
+}
+
+type vetter struct {
+	diags []Diagnostic
+	files map[string]int
+	types map[string]*vetType
+	order []*vetType
+	pkg   string
+	// packages are the names of other packages that refs may be qualified with
+	packages map[string]bool
+	// parsed is set once a file has been read as YAML
+	parsed bool
+}
+
+func (v *vetter) add(file string, node *yamlv3.Node, path, format string, args ...any) {
+	d := Diagnostic{File: file, Path: path, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+	}
+	v.diags = append(v.diags, d)
+}
+
+func (v *vetter) readFile(file SchemaFile) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(file.Data, &doc); err != nil {
+		v.add(file.Name, nil, "", "%v", err)
+		return
+	}
+	v.parsed = true
+	if len(doc.Content) == 0 {
+		v.add(file.Name, nil, "", "empty schema")
+		return
+	}
+	root := doc.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		v.add(file.Name, root, "", "schema must be a mapping with package and structs")
+		return
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "package":
+			if v.pkg != "" && value.Value != "" && value.Value != v.pkg {
+				v.add(file.Name, value, "", "package %q conflicts with package %q", value.Value, v.pkg)
+			} else if value.Value != "" {
+				v.pkg = value.Value
+			}
+		case "import_path":
+		case "packages":
+			var packages map[string]string
+			if err := value.Decode(&packages); err != nil {
+				v.add(file.Name, value, "", "packages must map package names to import paths")
+			}
+			for name := range packages {
+				v.packages[name] = true
+			}
+		case "unsafe_reads":
+			var unsafeReads bool
+			if err := value.Decode(&unsafeReads); err != nil {
+				v.add(file.Name, value, "", "unsafe_reads must be a boolean")
+			}
+		case "builders":
+			var builders bool
+			if err := value.Decode(&builders); err != nil {
+				v.add(file.Name, value, "", "builders must be a boolean")
+			}
+		case "structs":
+			v.readTypes(file.Name, value)
+		default:
+			v.add(file.Name, key, "", "unknown key %q", key.Value)
+		}
+	}
+}
+
+func (v *vetter) readTypes(file string, list *yamlv3.Node) {
+	if list.Kind != yamlv3.SequenceNode {
+		v.add(file, list, "", "structs must be a list")
+		return
+	}
+	for _, node := range list.Content {
+		var field Field
+		if err := node.Decode(&field); err != nil {
+			v.add(file, node, "", "%v", err)
+			continue
+		}
+		if field.Name == "" {
+			v.add(file, node, "", "type has no name")
+			continue
+		}
+		if prev, ok := v.types[field.Name]; ok {
+			v.add(file, node, field.Name, "duplicate type, first defined at %s:%d", prev.file, prev.node.Line)
+			prev.bad = true
+			continue
+		}
+		typ := &vetType{file: file, node: node, field: field}
+		v.types[field.Name] = typ
+		v.order = append(v.order, typ)
+	}
+}
+
+// walkField checks a field and its children. Elements of lists and vectors need no name.
+func (v *vetter) walkField(typ *vetType, node *yamlv3.Node, field Field, path string, element bool) {
+	report := func(at *yamlv3.Node, format string, args ...any) {
+		v.add(typ.file, at, path, format, args...)
+		typ.bad = true
+	}
+	if node.Kind != yamlv3.MappingNode {
+		report(node, "field must be a mapping")
+		return
+	}
+
+	var childNodes []*yamlv3.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "children":
+			childNodes = value.Content
+		case "max":
+			report(key, "unknown key %q, did you mean \"limit\"?", key.Value)
+		default:
+			if !contains(schemaKeys, key.Value) {
+				report(key, "unknown key %q", key.Value)
+			}
+		}
+	}
+	typeNode := mappingValue(node, "type")
+	if typeNode == nil {
+		typeNode = node
+	}
+
+	if field.Name == "" && !element {
+		report(node, "field has no name")
+	}
+	switch field.Type {
+	case "":
+		report(node, "field has no type")
+	case ssz.TypeVector, ssz.TypeBitVector, "bytevector":
+		if field.Size == 0 {
+			report(typeNode, "%s has no size", field.Type)
+		}
+	case ssz.TypeList, ssz.TypeBitList:
+		if field.Limit == 0 {
+			report(typeNode, "%s has no limit", field.Type)
+		}
+	case ssz.TypeStableContainer:
+		if field.Limit == 0 {
+			report(typeNode, "%s has no limit (capacity)", field.Type)
+		}
+	case ssz.TypeRef, ssz.TypeProfile:
+		refNode := mappingValue(node, "ref")
+		pkg, _, qualified := strings.Cut(field.Ref, ".")
+		if field.Ref == "" {
+			report(typeNode, "%s has no ref", field.Type)
+		} else if qualified && !v.packages[pkg] {
+			report(refNode, "unknown package %q", pkg)
+		} else if qualified {
+			typ.linked = true
+		} else if _, ok := v.types[field.Ref]; !ok {
+			report(refNode, "unknown type %q", field.Ref)
+		} else {
+			typ.refs = append(typ.refs, field.Ref)
+			typ.refNodes = append(typ.refNodes, refNode)
+		}
+	default:
+		if !contains(schemaTypes, field.Type) {
+			report(typeNode, "unknown type %q", field.Type)
+		}
+	}
+	switch field.Type {
+	case ssz.TypeContainer, ssz.TypeUnion, ssz.TypeStableContainer:
+		if len(field.Children) == 0 {
+			report(typeNode, "%s has no children", field.Type)
+		}
+	}
+
+	if field.GoType != "" {
+		goTypeNode := mappingValue(node, "go_type")
+		if ext, err := parseGoType(field.GoType, field.Codec); err != nil {
+			report(goTypeNode, "%v", err)
+		} else if !externalCompatible(field.ToSSZField(), ext.codec) {
+			report(goTypeNode, "go_type %s cannot be used for %s with codec %s", ext, field.Type, ext.codec)
+		}
+	} else if field.Codec != "" {
+		report(mappingValue(node, "codec"), "codec without a go_type")
+	}
+
+	elements := field.Type == ssz.TypeList || field.Type == ssz.TypeVector
+	for i, child := range field.Children {
+		if i >= len(childNodes) {
+			break
+		}
+		childPath := path + "." + child.Name
+		if child.Name == "" {
+			childPath = fmt.Sprintf("%s[%d]", path, i)
+		}
+		v.walkField(typ, childNodes[i], child, childPath, elements)
+	}
+}
+
+// checkCycles reports types that contain themselves through refs, which have no finite encoding
+func (v *vetter) checkCycles() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		typ := v.types[name]
+		for i, ref := range typ.refs {
+			switch state[ref] {
+			case unvisited:
+				visit(ref)
+			case visiting:
+				start := 0
+				for stack[start] != ref {
+					start++
+				}
+				cycle := append(append([]string(nil), stack[start:]...), ref)
+				v.add(typ.file, typ.refNodes[i], name, "cyclic reference %s", strings.Join(cycle, " -> "))
+				for _, member := range cycle {
+					v.types[member].bad = true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+	for _, typ := range v.order {
+		if state[typ.field.Name] == unvisited {
+			visit(typ.field.Name)
+		}
+	}
+}
+
+// checkValid runs the checks made at generation time on the types that passed the others
+func (v *vetter) checkValid() {
+	refs := make(map[string]ssz.Field, len(v.order))
+	for _, typ := range v.order {
+		refs[typ.field.Name] = nameElements(typ.field.ToSSZField())
+	}
+	bad := false
+	for _, typ := range v.order {
+		if typ.bad {
+			bad = true
+			continue
+		}
+		if typ.linked {
+			continue
+		}
+		field := refs[typ.field.Name]
+		if err := field.IsValid(refs); err != nil {
+			v.add(typ.file, typ.node, typ.field.Name, "%v", err)
+			bad = true
+		}
+	}
+	if bad {
+		return
+	}
+
+	schema := &Schema{Package: v.pkg}
+	for _, typ := range v.order {
+		schema.Structs = append(schema.Structs, typ.field)
+	}
+	if _, err := ParseSchemaToWorld(schema); err != nil {
+		v.add("", nil, "", "%v", err)
+	}
+}
+
+// nameElements names the elements of lists and vectors, which schemas leave unnamed
+func nameElements(f ssz.Field) ssz.Field {
+	if len(f.Children) == 0 {
+		return f
+	}
+	children := make([]ssz.Field, len(f.Children))
+	for i, child := range f.Children {
+		if child.Name == "" && (f.Type == ssz.TypeList || f.Type == ssz.TypeVector) {
+			child.Name = "element"
+		}
+		children[i] = nameElements(child)
+	}
+	f.Children = children
+	return f
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func contains[T comparable](xs []T, x T) bool {
+	for _, y := range xs {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
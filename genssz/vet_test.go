@@ -0,0 +1,145 @@
+package genssz
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const vetSchema = `package: test
+structs:
+  - name: Checkpoint
+    type: container
+    children:
+      - name: epoch
+        type: uint64
+      - name: root
+        type: bytevector
+  - name: Block
+    type: container
+    children:
+      - name: parent
+        type: ref
+        ref: Header
+      - name: attestations
+        type: list
+        max: 128
+        children:
+          - type: ref
+            ref: Checkpoint
+      - name: flags
+        type: uint9
+  - name: Node
+    type: container
+    children:
+      - name: next
+        type: ref
+        ref: Node
+`
+
+const vetSchema2 = `package: test
+structs:
+  - name: Checkpoint
+    type: container
+    children:
+      - name: epoch
+        type: uint64
+`
+
+func TestVetSchemas(t *testing.T) {
+	diags := VetSchemas(
+		SchemaFile{Name: "a.yml", Data: []byte(vetSchema)},
+		SchemaFile{Name: "b.yml", Data: []byte(vetSchema2)},
+	)
+	got := make([]string, len(diags))
+	for i, d := range diags {
+		got[i] = d.String()
+	}
+	want := []string{
+		`a.yml:9:15: Checkpoint.root: bytevector has no size`,
+		`a.yml:15:14: Block.parent: unknown type "Header"`,
+		`a.yml:17:15: Block.attestations: list has no limit`,
+		`a.yml:18:9: Block.attestations: unknown key "max", did you mean "limit"?`,
+		`a.yml:23:15: Block.flags: unknown type "uint9"`,
+		`a.yml:29:14: Node: cyclic reference Node -> Node`,
+		`b.yml:3:5: Checkpoint: duplicate type, first defined at a.yml:3`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	diags = VetSchemas(SchemaFile{Name: "bad.yml", Data: []byte("package: test\nstructs: [")})
+	if len(diags) != 1 || !strings.Contains(diags[0].String(), "bad.yml: yaml: line") {
+		t.Errorf("unexpected diagnostics for invalid yaml: %v", diags)
+	}
+}
+
+func TestVetSchemas_Examples(t *testing.T) {
+	var files []SchemaFile
+	for _, name := range []string{"../examples/penguin/schema.yml", "../examples/penguin/identity.yml"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read schema file: %v", err)
+		}
+		files = append(files, SchemaFile{Name: name, Data: data})
+	}
+	if diags := VetSchemas(files...); len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestVetSchemas_GoTypes(t *testing.T) {
+	schema := `package: test
+structs:
+  - name: Account
+    type: container
+    children:
+      - name: balance
+        type: uint256
+        go_type: github.com/holiman/uint256.Int
+      - name: nonce
+        type: uint64
+        go_type: Nonce
+        codec: json
+      - name: root
+        type: bytevector
+        size: 32
+        codec: ssz
+`
+	diags := VetSchemas(SchemaFile{Name: "a.yml", Data: []byte(schema)})
+	want := []string{
+		`a.yml:8:18: Account.balance: go_type github.com/holiman/uint256.Int cannot be used for uint256 with codec convert`,
+		`a.yml:11:18: Account.nonce: unknown codec "json" for go_type "Nonce"`,
+		`a.yml:16:16: Account.root: codec without a go_type`,
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("diagnostics: %v", diags)
+	}
+	for i, d := range diags {
+		if d.String() != want[i] {
+			t.Errorf("diagnostic %d: %s, want %s", i, d, want[i])
+		}
+	}
+}
+
+func TestVetSchemas_Packages(t *testing.T) {
+	schema := `package: test
+packages:
+  common: example.com/types/common
+structs:
+  - name: Vote
+    type: container
+    children:
+      - name: target
+        type: ref
+        ref: common.Checkpoint
+      - name: source
+        type: ref
+        ref: other.Checkpoint
+`
+	diags := VetSchemas(SchemaFile{Name: "a.yml", Data: []byte(schema)})
+	want := `a.yml:13:14: Vote.source: unknown package "other"`
+	if len(diags) != 1 || diags[0].String() != want {
+		t.Errorf("diagnostics: %v, want %s", diags, want)
+	}
+}
@@ -20,3 +20,29 @@ func TestBytes(t *testing.T) {
 	log.Printf("BytesRoot: %x", x)
 
 }
+
+func TestMerkleizeVectorLimitNotPowerOfTwo(t *testing.T) {
+	// A limit of 3 chunks is a tree of 4 leaves, the same as a limit of 4. MerkleizeVector hashes
+	// in place, so each call gets its own chunks.
+	got, err := MerkleizeVector([][32]byte{{1}, {2}}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := MerkleizeVector([][32]byte{{1}, {2}}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("limit 3: got %x, want %x", got, want)
+	}
+
+	flat := make([]byte, 64)
+	flat[0], flat[32] = 1, 2
+	gotFlat, err := MerkleizeVectorFlat(flat, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotFlat != want {
+		t.Fatalf("flat limit 3: got %x, want %x", gotFlat, want)
+	}
+}
@@ -2,6 +2,7 @@ package merkle_tree
 
 import (
 	"crypto/sha256"
+	"fmt"
 )
 
 // General purpose Sha256
@@ -16,3 +17,46 @@ func Sha256(data []byte, extras ...[]byte) (b [32]byte) {
 	h.Sum(b[:0])
 	return b
 }
+
+// Hasher hashes the nodes of merkle trees
+type Hasher interface {
+	// HashPairs writes the sha256 hash of each 64-byte pair of chunks in src to the 32 bytes at the
+	// same index in dst. dst may start at src, so layers can be hashed in place.
+	HashPairs(dst, src []byte) error
+}
+
+// DefaultHasher hashes every tree that does not have its own Hasher. It is GoHashTreeHasher,
+// which uses the AVX and ARM sha extensions where the CPU has them and falls back to generic
+// code where it does not. Set it before hashing to use another implementation.
+var DefaultHasher Hasher = GoHashTreeHasher{}
+
+// GoHashTreeHasher hashes with github.com/prysmaticlabs/gohashtree on amd64 and arm64. gohashtree
+// has no code for other architectures, or for tinygo, so builds for them, and builds with the
+// purego tag, hash with crypto/sha256 like SHA256Hasher.
+type GoHashTreeHasher struct{}
+
+// SHA256Hasher hashes with crypto/sha256, one pair at a time
+type SHA256Hasher struct{}
+
+func (SHA256Hasher) HashPairs(dst, src []byte) error {
+	if len(src)%64 != 0 {
+		return fmt.Errorf("source length must be a multiple of 64, got %d", len(src))
+	}
+	if len(dst) < len(src)/2 {
+		return fmt.Errorf("destination of %d bytes is too small for %d pairs", len(dst), len(src)/64)
+	}
+	// Each hash is written at or before the pair it was read from, so hashing in place is safe
+	for i := 0; i < len(src)/64; i++ {
+		sum := sha256.Sum256(src[i*64 : i*64+64])
+		copy(dst[i*32:], sum[:])
+	}
+	return nil
+}
+
+// hasherOrDefault returns h, or DefaultHasher if h is nil
+func hasherOrDefault(h Hasher) Hasher {
+	if h == nil {
+		return DefaultHasher
+	}
+	return h
+}
@@ -0,0 +1,9 @@
+//go:build (amd64 || arm64) && !purego && !tinygo
+
+package merkle_tree
+
+import "github.com/prysmaticlabs/gohashtree"
+
+func (GoHashTreeHasher) HashPairs(dst, src []byte) error {
+	return gohashtree.HashByteSlice(dst, src)
+}
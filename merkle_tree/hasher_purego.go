@@ -0,0 +1,7 @@
+//go:build !(amd64 || arm64) || purego || tinygo
+
+package merkle_tree
+
+func (GoHashTreeHasher) HashPairs(dst, src []byte) error {
+	return SHA256Hasher{}.HashPairs(dst, src)
+}
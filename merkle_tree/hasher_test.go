@@ -0,0 +1,78 @@
+package merkle_tree_test
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/stretchr/testify/require"
+)
+
+// countingHasher counts the pairs hashed through it
+type countingHasher struct {
+	merkle_tree.Hasher
+	pairs atomic.Int64
+}
+
+func (c *countingHasher) HashPairs(dst, src []byte) error {
+	c.pairs.Add(int64(len(src) / 64))
+	return c.Hasher.HashPairs(dst, src)
+}
+
+func TestSHA256Hasher(t *testing.T) {
+	src := make([]byte, 64*33)
+	rand.New(rand.NewSource(1)).Read(src)
+
+	expected := make([]byte, len(src)/2)
+	require.NoError(t, merkle_tree.GoHashTreeHasher{}.HashPairs(expected, src))
+	got := make([]byte, len(src)/2)
+	require.NoError(t, merkle_tree.SHA256Hasher{}.HashPairs(got, src))
+	require.Equal(t, expected, got)
+
+	// In place
+	require.NoError(t, merkle_tree.SHA256Hasher{}.HashPairs(src, src))
+	require.Equal(t, expected, src[:len(src)/2])
+
+	require.Error(t, merkle_tree.SHA256Hasher{}.HashPairs(got, src[:63]))
+	require.Error(t, merkle_tree.SHA256Hasher{}.HashPairs(got[:31], src[:64]))
+}
+
+func TestMerkleTreeSetHasher(t *testing.T) {
+	leaves := make([]byte, 37*32)
+	rand.New(rand.NewSource(2)).Read(leaves)
+	expected := getExpectedRoot(leaves)
+
+	hasher := &countingHasher{Hasher: merkle_tree.SHA256Hasher{}}
+	mt := merkle_tree.MerkleTree{}
+	mt.SetHasher(hasher)
+	mt.Initialize(37, 3, func(idx int, out []byte) {
+		copy(out, leaves[idx*32:(idx+1)*32])
+	}, nil)
+	require.Equal(t, expected, mt.ComputeRoot())
+	require.NotZero(t, hasher.pairs.Load())
+
+	// Copies keep the hasher
+	var copied merkle_tree.MerkleTree
+	mt.CopyInto(&copied)
+	before := hasher.pairs.Load()
+	leaves[0] ^= 1
+	copied.MarkLeafAsDirty(0)
+	require.Equal(t, getExpectedRoot(leaves), copied.ComputeRoot())
+	require.Greater(t, hasher.pairs.Load(), before)
+}
+
+func TestDefaultHasher(t *testing.T) {
+	leaves := make([]byte, 9*32)
+	rand.New(rand.NewSource(3)).Read(leaves)
+	expected := getExpectedRootWithLimit(leaves, 16)
+
+	hasher := &countingHasher{Hasher: merkle_tree.SHA256Hasher{}}
+	prev := merkle_tree.DefaultHasher
+	merkle_tree.DefaultHasher = hasher
+	t.Cleanup(func() { merkle_tree.DefaultHasher = prev })
+
+	require.Equal(t, expected, getExpectedRootWithLimit(leaves, 16))
+	// 9 leaves padded to 10, then 5, 3, 2 and 1 pairs
+	require.Equal(t, int64(11), hasher.pairs.Load())
+}
@@ -0,0 +1,22 @@
+package merkle_tree
+
+// LeafSource provides the leaves of a MerkleTree in batches, so they can be paged from disk or
+// from a memory mapped file with read ahead, instead of being computed one at a time. Runs of
+// consecutive dirty leaves are requested together, up to LeafBatchSize leaves at once.
+type LeafSource interface {
+	// Leaves writes the leaves [start, start+count) to out, 32 bytes each
+	Leaves(start, count int, out []byte) error
+}
+
+// LeafBatchSize is the most leaves requested from a LeafSource at once
+const LeafBatchSize = 1024
+
+// LeafFunc is a LeafSource computing each leaf with a function, as passed to Initialize
+type LeafFunc func(idx int, out []byte)
+
+func (f LeafFunc) Leaves(start, count int, out []byte) error {
+	for i := 0; i < count; i++ {
+		f(start+i, out[i*32:(i+1)*32])
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package merkle_tree_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceLeafSource reads leaves from a slice, recording the batches requested
+type sliceLeafSource struct {
+	leaves  []byte
+	batches [][2]int
+	err     error
+}
+
+func (s *sliceLeafSource) Leaves(start, count int, out []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, [2]int{start, count})
+	copy(out, s.leaves[start*32:(start+count)*32])
+	return nil
+}
+
+func TestMerkleTreeLeafSource(t *testing.T) {
+	const leaves = 3001
+	source := &sliceLeafSource{leaves: make([]byte, leaves*32)}
+	rand.New(rand.NewSource(4)).Read(source.leaves)
+
+	mt := merkle_tree.MerkleTree{}
+	mt.InitializeWithSource(leaves, merkle_tree.OptimalMaxTreeCacheDepth, source, nil)
+	require.Equal(t, getExpectedRoot(source.leaves), mt.ComputeRoot())
+	require.Equal(t, [][2]int{{0, 1024}, {1024, 1024}, {2048, 953}}, source.batches)
+
+	// Consecutive dirty nodes are read together
+	source.batches = nil
+	source.leaves[5*32] ^= 1
+	source.leaves[6*32] ^= 1
+	source.leaves[100*32] ^= 1
+	mt.MarkLeafAsDirty(5)
+	mt.MarkLeafAsDirty(6)
+	mt.MarkLeafAsDirty(100)
+	require.Equal(t, getExpectedRoot(source.leaves), mt.ComputeRoot())
+	require.Equal(t, [][2]int{{4, 4}, {100, 2}}, source.batches)
+}
+
+func TestMerkleTreeLeafSourceError(t *testing.T) {
+	for _, leaves := range []int{1, 3, 8, 33} {
+		source := &sliceLeafSource{leaves: make([]byte, leaves*32)}
+		rand.New(rand.NewSource(int64(leaves))).Read(source.leaves)
+		limit := uint64(64)
+
+		mt := merkle_tree.MerkleTree{}
+		mt.InitializeWithSource(leaves, 3, source, &limit)
+		source.err = errors.New("disk on fire")
+		_, err := mt.TryComputeRoot()
+		require.ErrorIs(t, err, source.err)
+		require.Panics(t, func() { mt.ComputeRoot() })
+
+		// Nodes that failed are computed on the next call
+		source.err = nil
+		root, err := mt.TryComputeRoot()
+		require.NoError(t, err)
+		require.Equal(t, getExpectedRootWithLimit(source.leaves, 64), root)
+	}
+}
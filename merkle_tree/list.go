@@ -3,13 +3,13 @@ package merkle_tree
 import (
 	"math/bits"
 
-	"github.com/prysmaticlabs/gohashtree"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
 )
 
 // MerkleizeVector uses our optimized routine to hash a list of 32-byte
-// elements.
+// elements. The tree has length leaves, rounded up to a power of two.
 func MerkleizeVector(elements [][32]byte, length uint64) ([32]byte, error) {
-	depth := GetDepth(length)
+	depth := mathutil.GetDepth(mathutil.NextPowerOfTwo(length))
 	// Return zerohash at depth
 	if len(elements) == 0 {
 		return ZeroHashes[depth], nil
@@ -21,7 +21,7 @@ func MerkleizeVector(elements [][32]byte, length uint64) ([32]byte, error) {
 			elements = append(elements, ZeroHashes[i])
 		}
 		outputLen := len(elements) / 2
-		if err := gohashtree.Hash(elements, elements); err != nil {
+		if err := hashChunkPairs(elements); err != nil {
 			return [32]byte{}, err
 		}
 		elements = elements[:outputLen]
@@ -34,14 +34,14 @@ func MerkleizeVector(elements [][32]byte, length uint64) ([32]byte, error) {
 func MerkleizeVectorFlat(in []byte, limit uint64) (o [32]byte, err error) {
 	elements := make([]byte, len(in))
 	copy(elements, in)
-	for i := uint8(0); i < GetDepth(limit); i++ {
+	for i := uint8(0); i < mathutil.GetDepth(mathutil.NextPowerOfTwo(limit)); i++ {
 		// Sequential
 		layerLen := len(elements)
 		if layerLen%64 == 32 {
 			elements = append(elements, ZeroHashes[i][:]...)
 		}
 		outputLen := len(elements) / 2
-		if err := gohashtree.HashByteSlice(elements, elements); err != nil {
+		if err := DefaultHasher.HashPairs(elements, elements); err != nil {
 			return o, err
 		}
 		elements = elements[:outputLen]
@@ -1,66 +1,31 @@
 package merkle_tree
 
+import "github.com/gfx-labs/ssz/merkle_tree/mathutil"
+
+// IsPowerOf2 reports whether n is a power of 2.
+//
+// Deprecated: use mathutil.IsPowerOf2.
 func IsPowerOf2(n uint64) bool {
-	return n != 0 && (n&(n-1)) == 0
+	return mathutil.IsPowerOf2(n)
 }
 
+// PowerOf2 returns 2^n.
+//
+// Deprecated: use mathutil.PowerOf2.
 func PowerOf2(n uint64) uint64 {
-	if n >= 64 {
-		panic("integer overflow")
-	}
-	return 1 << n
-}
-
-var squareRootTable = map[uint64]uint64{
-	4:       2,
-	16:      4,
-	64:      8,
-	256:     16,
-	1024:    32,
-	4096:    64,
-	16384:   128,
-	65536:   256,
-	262144:  512,
-	1048576: 1024,
-	4194304: 2048,
+	return mathutil.PowerOf2(n)
 }
 
-
+// NextPowerOfTwo returns the smallest power of 2 that is >= n.
+//
+// Deprecated: use mathutil.NextPowerOfTwo.
 func NextPowerOfTwo(n uint64) uint64 {
-	if n == 0 {
-		return 1
-	}
-	n--
-	n |= n >> 1
-	n |= n >> 2
-	n |= n >> 4
-	n |= n >> 8
-	n |= n >> 16
-	n |= n >> 32
-	n++
-
-	return n
+	return mathutil.NextPowerOfTwo(n)
 }
 
-// GetDepth returns the depth of a merkle tree with a given number of nodes.
-// The depth is defined as the number of levels in the tree, with the root
-// node at level 0 and each child node at a level one greater than its parent.
-// If the number of nodes is less than or equal to 1, the depth is 0.
+// GetDepth returns the depth of a merkle tree with v leaves.
+//
+// Deprecated: use mathutil.GetDepth.
 func GetDepth(v uint64) uint8 {
-	// If there are 0 or 1 nodes, the depth is 0.
-	if v <= 1 {
-		return 0
-	}
-
-	// Initialize the depth to 0.
-	depth := uint8(0)
-
-	// Divide the number of nodes by 2 until it is less than or equal to 1.
-	// The number of iterations is the depth of the tree.
-	for v > 1 {
-		v >>= 1
-		depth++
-	}
-
-	return depth
+	return mathutil.GetDepth(v)
 }
@@ -0,0 +1,48 @@
+// Package mathutil holds the bit math used to size merkle trees. Hash tree roots depend on the
+// exact results, including the edge cases documented on each function, so they must not change.
+package mathutil
+
+import "math/bits"
+
+// IsPowerOf2 reports whether n is a power of 2. 0 is not a power of 2.
+func IsPowerOf2(n uint64) bool {
+	return n != 0 && (n&(n-1)) == 0
+}
+
+// PowerOf2 returns 2^n. It panics for n >= 64, which overflows a uint64.
+func PowerOf2(n uint64) uint64 {
+	if n >= 64 {
+		panic("integer overflow")
+	}
+	return 1 << n
+}
+
+// NextPowerOfTwo returns the smallest power of 2 that is >= n, the number of leaves of a tree
+// holding n chunks. NextPowerOfTwo(0) is 1, since even an empty tree has a leaf. Values above
+// 2^63 overflow and return 0.
+func NextPowerOfTwo(n uint64) uint64 {
+	if n == 0 {
+		return 1
+	}
+	n--
+	n |= n >> 1
+	n |= n >> 2
+	n |= n >> 4
+	n |= n >> 8
+	n |= n >> 16
+	n |= n >> 32
+	n++
+
+	return n
+}
+
+// GetDepth returns the depth of a merkle tree with v leaves, the number of levels between the
+// leaves and the root. GetDepth(0) and GetDepth(1) are 0. v is rounded down to a power of 2, so
+// GetDepth(3) is 1: pass the result of NextPowerOfTwo to get the depth of a tree holding v chunks.
+// The largest depth is 63.
+func GetDepth(v uint64) uint8 {
+	if v <= 1 {
+		return 0
+	}
+	return uint8(bits.Len64(v) - 1)
+}
@@ -0,0 +1,69 @@
+package mathutil_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPowerOf2(t *testing.T) {
+	require.False(t, mathutil.IsPowerOf2(0))
+	require.True(t, mathutil.IsPowerOf2(1))
+	require.True(t, mathutil.IsPowerOf2(2))
+	require.False(t, mathutil.IsPowerOf2(3))
+	require.True(t, mathutil.IsPowerOf2(1<<63))
+	require.False(t, mathutil.IsPowerOf2(1<<63+1))
+	require.False(t, mathutil.IsPowerOf2(math.MaxUint64))
+}
+
+func TestPowerOf2(t *testing.T) {
+	require.Equal(t, uint64(1), mathutil.PowerOf2(0))
+	require.Equal(t, uint64(1<<40), mathutil.PowerOf2(40))
+	require.Equal(t, uint64(1<<63), mathutil.PowerOf2(63))
+	require.Panics(t, func() { mathutil.PowerOf2(64) })
+}
+
+func TestNextPowerOfTwo(t *testing.T) {
+	tests := map[uint64]uint64{
+		0:              1,
+		1:              1,
+		2:              2,
+		3:              4,
+		5:              8,
+		1 << 32:        1 << 32,
+		1<<32 + 1:      1 << 33,
+		1 << 62:        1 << 62,
+		1<<62 + 1:      1 << 63,
+		1 << 63:        1 << 63,
+		1<<63 + 1:      0, // overflows
+		math.MaxUint64: 0,
+	}
+	for in, expected := range tests {
+		require.Equal(t, expected, mathutil.NextPowerOfTwo(in), "NextPowerOfTwo(%d)", in)
+	}
+}
+
+func TestGetDepth(t *testing.T) {
+	tests := map[uint64]uint8{
+		0:              0,
+		1:              0,
+		2:              1,
+		3:              1, // rounded down
+		4:              2,
+		1 << 40:        40,
+		1<<62 + 1:      62,
+		1 << 63:        63,
+		math.MaxUint64: 63,
+	}
+	for in, expected := range tests {
+		require.Equal(t, expected, mathutil.GetDepth(in), "GetDepth(%d)", in)
+	}
+
+	// GetDepth inverts PowerOf2, and NextPowerOfTwo gives the depth of a tree holding n chunks
+	for n := uint64(0); n < 64; n++ {
+		require.Equal(t, uint8(n), mathutil.GetDepth(mathutil.PowerOf2(n)))
+	}
+	require.Equal(t, uint8(2), mathutil.GetDepth(mathutil.NextPowerOfTwo(3)))
+}
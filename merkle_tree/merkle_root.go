@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/gfx-labs/ssz/merkle_tree/bufpool"
-	"github.com/prysmaticlabs/gohashtree"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
 )
 
 func MerklizeChunks(chunks [][32]byte, output []byte) (err error) {
@@ -19,21 +19,40 @@ func ComputeMerkleRoot(data []byte, output []byte) (err error) {
 		copy(output, data)
 		return
 	}
-	return ComputeMerkleRootRange(data, output, NextPowerOfTwo(uint64((len(data)+31)/32)), 0)
+	return ComputeMerkleRootRange(data, output, mathutil.NextPowerOfTwo(uint64((len(data)+31)/32)), 0)
 }
 
 func ComputeMerkleRootFromLevel(data []byte, output []byte, dataLength uint64, startLevel uint64) (err error) {
+	return computeMerkleRootFromLevel(DefaultHasher, data, output, dataLength, startLevel)
+}
+
+func computeMerkleRootFromLevel(h Hasher, data []byte, output []byte, dataLength uint64, startLevel uint64) (err error) {
 	if len(data) <= 32 {
 		copy(output, data)
 		return
 	}
-	return ComputeMerkleRootRange(data, output, NextPowerOfTwo(uint64((dataLength+31)/32)), uint64(startLevel))
+	return computeMerkleRootRange(h, data, output, mathutil.NextPowerOfTwo(uint64((dataLength+31)/32)), uint64(startLevel))
 }
 
+// ErrTooManyChunks is returned when there are more chunks than the leaf limit of a tree
+var ErrTooManyChunks = errors.New("too many chunks for leaf limit")
+
+// ComputeMerkleRootRange computes the root of data padded with zero chunks to a tree of
+// leafLimit leaves, hashing from startLevel. leafLimit is rounded down to a power of two, and a
+// leafLimit of 0 is a single leaf, see mathutil.GetDepth. It returns ErrTooManyChunks if data
+// holds more chunks than the tree has leaves.
 func ComputeMerkleRootRange(data []byte, output []byte, leafLimit uint64, startLevel uint64) (err error) {
+	return computeMerkleRootRange(DefaultHasher, data, output, leafLimit, startLevel)
+}
+
+// computeMerkleRootRange is ComputeMerkleRootRange hashing with h
+func computeMerkleRootRange(h Hasher, data []byte, output []byte, leafLimit uint64, startLevel uint64) (err error) {
 	if len(data)%32 != 0 {
 		return errors.New("data length must be a multiple of 32")
 	}
+	if err := checkLeafLimit(len(data)/32, leafLimit); err != nil {
+		return err
+	}
 	// Get buffer from pool for reuse with enough capacity to avoid allocations
 	poolBuffer := bufpool.Get(len(data) + 64)
 	defer bufpool.Put(poolBuffer)
@@ -44,7 +63,7 @@ func ComputeMerkleRootRange(data []byte, output []byte, leafLimit uint64, startL
 	layer := poolBuffer.B[:len(data)] // Set initial length to data size
 	copy(layer, data)
 
-	for i := uint8(startLevel); i < GetDepth(leafLimit); i++ {
+	for i := uint8(startLevel); i < mathutil.GetDepth(leafLimit); i++ {
 		layerLen := len(layer) / 32
 		if layerLen%2 != 0 {
 			// Append zero hash for padding - no allocation since we have capacity
@@ -56,7 +75,7 @@ func ComputeMerkleRootRange(data []byte, output []byte, leafLimit uint64, startL
 		outputSize := (layerLen / 2) * 32
 
 		// Hash in-place since output is always smaller than input
-		if err := gohashtree.HashByteSlice(layer[:outputSize], layer); err != nil {
+		if err := h.HashPairs(layer[:outputSize], layer); err != nil {
 			return err
 		}
 
@@ -67,11 +86,19 @@ func ComputeMerkleRootRange(data []byte, output []byte, leafLimit uint64, startL
 	return
 }
 
+// checkLeafLimit returns ErrTooManyChunks if chunks do not fit in a tree of leafLimit leaves
+func checkLeafLimit(chunks int, leafLimit uint64) error {
+	if leaves := mathutil.PowerOf2(uint64(mathutil.GetDepth(leafLimit))); uint64(chunks) > leaves {
+		return fmt.Errorf("%w: %d chunks, limit %d", ErrTooManyChunks, chunks, leafLimit)
+	}
+	return nil
+}
+
 // Merkle Proof computes the merkle proof for a given schema of objects.
 func MerkleProof(depth, proofIndex int, schema ...[32]byte) ([][32]byte, error) {
 	// Calculate the total number of leaves needed based on the schema length
-	maxDepth := GetDepth(uint64(len(schema)))
-	if PowerOf2(uint64(maxDepth)) != uint64(len(schema)) {
+	maxDepth := mathutil.GetDepth(uint64(len(schema)))
+	if mathutil.PowerOf2(uint64(maxDepth)) != uint64(len(schema)) {
 		maxDepth++
 	}
 
@@ -80,7 +107,7 @@ func MerkleProof(depth, proofIndex int, schema ...[32]byte) ([][32]byte, error)
 	}
 	var err error
 	proof := make([][32]byte, maxDepth)
-	currentSizeDepth := PowerOf2(uint64(maxDepth))
+	currentSizeDepth := mathutil.PowerOf2(uint64(maxDepth))
 	for len(schema) != int(currentSizeDepth) { // Augment the schema to be a power of 2
 		schema = append(schema, [32]byte{})
 	}
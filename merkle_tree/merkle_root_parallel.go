@@ -0,0 +1,88 @@
+package merkle_tree
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/gfx-labs/ssz/merkle_tree/bufpool"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
+)
+
+// ParallelHashThreshold is the number of node pairs a layer must have before
+// ComputeMerkleRootParallel splits it across goroutines. Smaller layers are
+// hashed serially, since the goroutine overhead outweighs the gain.
+var ParallelHashThreshold = 1 << 14
+
+// ComputeMerkleRootParallel computes the same root as ComputeMerkleRootRange, but hashes
+// large layers in parallel across GOMAXPROCS goroutines. Once a layer drops below
+// ParallelHashThreshold pairs the remaining levels are hashed serially.
+func ComputeMerkleRootParallel(data []byte, output []byte, leafLimit uint64, startLevel uint64) (err error) {
+	if len(data)%32 != 0 {
+		return errors.New("data length must be a multiple of 32")
+	}
+	if err := checkLeafLimit(len(data)/32, leafLimit); err != nil {
+		return err
+	}
+	workers := runtime.GOMAXPROCS(0)
+	if workers < 2 || len(data)/64 < ParallelHashThreshold {
+		return ComputeMerkleRootRange(data, output, leafLimit, startLevel)
+	}
+
+	// Workers cannot hash in place without racing on each other's input, so alternate
+	// between two buffers. The extra 32 bytes leave room for padding an odd layer.
+	inBuf := bufpool.Get(len(data) + 32)
+	defer bufpool.Put(inBuf)
+	outBuf := bufpool.Get(len(data)/2 + 32)
+	defer bufpool.Put(outBuf)
+
+	layer := inBuf.B[:len(data)]
+	copy(layer, data)
+	next := outBuf.B
+
+	depth := mathutil.GetDepth(leafLimit)
+	level := uint8(startLevel)
+	for ; level < depth; level++ {
+		layerLen := len(layer) / 32
+		if layerLen%2 != 0 {
+			layer = append(layer, ZeroHashes[level][:]...)
+			layerLen++
+		}
+		pairs := layerLen / 2
+		if pairs < ParallelHashThreshold {
+			break
+		}
+
+		out := next[:pairs*32]
+		if err := hashLayerParallel(out, layer, pairs, workers); err != nil {
+			return err
+		}
+		layer, next = out, layer[:cap(layer)]
+	}
+
+	// Finish the small upper levels serially
+	return ComputeMerkleRootRange(layer, output, leafLimit, uint64(level))
+}
+
+// hashLayerParallel hashes pairs of chunks from layer into out, splitting the work across workers
+func hashLayerParallel(out, layer []byte, pairs, workers int) error {
+	perWorker := (pairs + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for w := 0; w < workers; w++ {
+		start := w * perWorker
+		end := min(start+perWorker, pairs)
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			errs[w] = DefaultHasher.HashPairs(out[start*32:end*32], layer[start*64:end*64])
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -2,6 +2,7 @@ package merkle_tree_test
 
 import (
 	"fmt"
+	"runtime"
 	"testing"
 
 	merkle_erigon "github.com/erigontech/erigon/cl/merkle_tree"
@@ -211,6 +212,40 @@ func TestComputeMerkleRootRange_RandomDataWithErigon(t *testing.T) {
 	}
 }
 
+func TestComputeMerkleRootRange_TooManyChunks(t *testing.T) {
+	output := make([]byte, 32)
+	testCases := []struct {
+		leaves    int
+		leafLimit uint64
+	}{
+		{3, 2},
+		{2, 1},
+		// A limit of 0 is a single leaf
+		{2, 0},
+		// Limits round down, 3 is a tree of 2 leaves
+		{3, 3},
+		{1 << 10, 1<<10 - 1},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("leaves_%d_limit_%d", tc.leaves, tc.leafLimit), func(t *testing.T) {
+			data := make([]byte, tc.leaves*32)
+			err := merkle_tree.ComputeMerkleRootRange(data, output, tc.leafLimit, 0)
+			require.ErrorIs(t, err, merkle_tree.ErrTooManyChunks)
+			err = merkle_tree.ComputeMerkleRootParallel(data, output, tc.leafLimit, 0)
+			require.ErrorIs(t, err, merkle_tree.ErrTooManyChunks)
+		})
+	}
+
+	// One chunk fits any limit, including 0 and the largest depth
+	chunk := make([]byte, 32)
+	chunk[0] = 1
+	for _, leafLimit := range []uint64{0, 1 << 63, 1<<64 - 1} {
+		require.NoError(t, merkle_tree.ComputeMerkleRootRange(chunk, output, leafLimit, 0))
+	}
+	require.NoError(t, merkle_tree.ComputeMerkleRootRange(chunk, output, 1, 0))
+	require.Equal(t, chunk, output)
+}
+
 func BenchmarkComputeMerkleRootRange_VsErigon(b *testing.B) {
 	data := make([]byte, 1024*32)
 	for i := range 1024 {
@@ -231,3 +266,69 @@ func BenchmarkComputeMerkleRootRange_VsErigon(b *testing.B) {
 		}
 	})
 }
+
+func TestComputeMerkleRootParallel_MatchesSerial(t *testing.T) {
+	oldThreshold := merkle_tree.ParallelHashThreshold
+	merkle_tree.ParallelHashThreshold = 4
+	defer func() { merkle_tree.ParallelHashThreshold = oldThreshold }()
+	// Force multiple workers even on single core machines
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	testCases := []struct {
+		leaves     int
+		leafLimit  uint64
+		startLevel uint64
+	}{
+		{1, 1, 0},
+		{7, 8, 0},
+		{8, 8, 0},
+		{33, 64, 0},
+		{257, 1024, 0},
+		{1000, 1 << 20, 0},
+		{1024, 1024, 2},
+		{4099, 1 << 40, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("leaves_%d_limit_%d_start_%d", tc.leaves, tc.leafLimit, tc.startLevel), func(t *testing.T) {
+			data := make([]byte, tc.leaves*32)
+			for i := range data {
+				data[i] = byte(i*31 + 7)
+			}
+
+			serial := make([]byte, 32)
+			parallel := make([]byte, 32)
+			require.NoError(t, merkle_tree.ComputeMerkleRootRange(data, serial, tc.leafLimit, tc.startLevel))
+			require.NoError(t, merkle_tree.ComputeMerkleRootParallel(data, parallel, tc.leafLimit, tc.startLevel))
+			require.Equal(t, serial, parallel)
+		})
+	}
+
+	t.Run("invalid length", func(t *testing.T) {
+		err := merkle_tree.ComputeMerkleRootParallel(make([]byte, 33), make([]byte, 32), 2, 0)
+		require.Error(t, err)
+	})
+}
+
+func BenchmarkComputeMerkleRootParallel(b *testing.B) {
+	// Roughly the size of the validator registry
+	const leaves = 1 << 21
+	data := make([]byte, leaves*32)
+	for i := range leaves {
+		data[i*32] = byte(i % 256)
+	}
+
+	b.Run("serial", func(b *testing.B) {
+		output := make([]byte, 32)
+		for b.Loop() {
+			_ = merkle_tree.ComputeMerkleRootRange(data, output, 1<<40, 0)
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		output := make([]byte, 32)
+		for b.Loop() {
+			_ = merkle_tree.ComputeMerkleRootParallel(data, output, 1<<40, 0)
+		}
+	})
+}
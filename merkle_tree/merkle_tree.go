@@ -2,10 +2,11 @@ package merkle_tree
 
 import (
 	"bytes"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	
-	"github.com/prysmaticlabs/gohashtree"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
 )
 
 func ceil(num, divisor int) int {
@@ -15,13 +16,20 @@ func ceil(num, divisor int) int {
 const OptimalMaxTreeCacheDepth = 12
 
 type MerkleTree struct {
-	computeLeaf func(idx int, out []byte)
-	layers      [][]byte // Flat hash-layers
+	source      LeafSource // computes or reads the leaves, see LeafSource
+	layers      [][]byte   // Flat hash-layers
 	leavesCount int
+	leafBuf     []byte // buffer for the batches of leaves read from source
 
 	hashBuf [64]byte // buffer to store the input for hash(hash1, hash2)
 	limit   *uint64  // Optional limit for the number of leaves (this will enable limit-oriented hashing)
 
+	// hasSubtreeRoots is set by SetSubtreeRoot, whose nodes can be clean above dirty ones
+	hasSubtreeRoots bool
+
+	// hasher hashes the nodes of the tree, or DefaultHasher if it is nil
+	hasher Hasher
+
 	dirtyLeaves []atomic.Bool
 	mu          sync.RWMutex
 }
@@ -34,7 +42,12 @@ type MerkleTree struct {
 
 // Initialize initializes the Merkle tree with the given number of leaves and the maximum depth of the tree cache.
 func (m *MerkleTree) Initialize(leavesCount, maxTreeCacheDepth int, computeLeaf func(idx int, out []byte), limitOptional *uint64) {
-	m.computeLeaf = computeLeaf
+	m.InitializeWithSource(leavesCount, maxTreeCacheDepth, LeafFunc(computeLeaf), limitOptional)
+}
+
+// InitializeWithSource is Initialize with leaves read in batches from source, see LeafSource.
+func (m *MerkleTree) InitializeWithSource(leavesCount, maxTreeCacheDepth int, source LeafSource, limitOptional *uint64) {
+	m.source = source
 	m.layers = make([][]byte, maxTreeCacheDepth)
 	m.leavesCount = leavesCount
 	firstLayerSize := ((leavesCount + 1) / 2) * 32
@@ -47,8 +60,21 @@ func (m *MerkleTree) Initialize(leavesCount, maxTreeCacheDepth int, computeLeaf
 	m.dirtyLeaves = make([]atomic.Bool, leavesCount)
 }
 
+// SetHasher sets the Hasher used for the nodes of the tree, instead of DefaultHasher. Cached nodes
+// are not recomputed, so it should be set before the first ComputeRoot.
+func (m *MerkleTree) SetHasher(h Hasher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hasher = h
+}
+
 func (m *MerkleTree) SetComputeLeafFn(computeLeaf func(idx int, out []byte)) {
-	m.computeLeaf = computeLeaf
+	m.source = LeafFunc(computeLeaf)
+}
+
+// SetLeafSource replaces the source of the leaves, like SetComputeLeafFn.
+func (m *MerkleTree) SetLeafSource(source LeafSource) {
+	m.source = source
 }
 
 func (m *MerkleTree) MarkLeafAsDirty(idx int) {
@@ -57,6 +83,86 @@ func (m *MerkleTree) MarkLeafAsDirty(idx int) {
 	m.dirtyLeaves[idx].Store(true)
 }
 
+// MarkRangeAsDirty marks the leaves in [start, end) as dirty, so that they will be recomputed on the next call to ComputeRoot.
+// Unlike calling MarkLeafAsDirty in a loop, the lock is taken once and the affected nodes of each layer are zeroed in bulk.
+func (m *MerkleTree) MarkRangeAsDirty(start, end int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if start < 0 || end > m.leavesCount || start > end {
+		panic("merkle_tree: dirty range out of bounds")
+	}
+	if start == end {
+		return
+	}
+	for i := 0; i < len(m.layers); i++ {
+		if m.layers[i] == nil {
+			// Missing layers are created zeroed, so they are already dirty
+			continue
+		}
+		fromOffset := (start >> (i + 1)) * 32
+		toOffset := min((((end-1)>>(i+1))+1)*32, len(m.layers[i]))
+		if fromOffset >= toOffset {
+			break
+		}
+		clear(m.layers[i][fromOffset:toOffset])
+	}
+}
+
+// MarkAllDirty invalidates every cached node, so that the whole tree will be recomputed on the next call to ComputeRoot.
+func (m *MerkleTree) MarkAllDirty() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.layers {
+		clear(m.layers[i])
+	}
+	m.hasSubtreeRoots = false
+}
+
+// SetSubtreeRoot sets the node at the given level and index to root, the known root of the leaves below it, e.g. when
+// validator roots are precomputed elsewhere. Level 1 is the first layer above the leaves, up to the max tree cache depth.
+// The ancestors of the node are marked dirty, and the leaves below it are not hashed by ComputeRoot until one of them is
+// marked dirty again, which recomputes the whole subtree. Pending dirty leaves below the node are dropped.
+// A zero root cannot be told apart from a dirty node, so it is recomputed from the leaves.
+func (m *MerkleTree) SetSubtreeRoot(level, index int, root [32]byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if level < 1 || level > len(m.layers) {
+		panic("merkle_tree: subtree level out of bounds")
+	}
+	for i := 1; i < level; i++ {
+		m.ensureLayer(i)
+	}
+	layer := m.layers[level-1]
+	if index < 0 || (index+1)*32 > len(layer) {
+		panic("merkle_tree: subtree index out of bounds")
+	}
+
+	// The nodes below are stale, so they are cleared to be recomputed if a leaf below changes
+	start := index << level
+	end := min((index+1)<<level, m.leavesCount)
+	for i := start; i < end; i++ {
+		m.dirtyLeaves[i].Store(false)
+	}
+	for i := 0; i < level-1; i++ {
+		fromOffset := (start >> (i + 1)) * 32
+		toOffset := min((((end-1)>>(i+1))+1)*32, len(m.layers[i]))
+		if fromOffset < toOffset {
+			clear(m.layers[i][fromOffset:toOffset])
+		}
+	}
+	copy(layer[index*32:], root[:])
+
+	for i := level; i < len(m.layers); i++ {
+		nodeOffset := (index >> (i - level + 1)) * 32
+		if nodeOffset+32 > len(m.layers[i]) {
+			// Missing layers are created zeroed, so they are already dirty
+			break
+		}
+		copy(m.layers[i][nodeOffset:], ZeroHashes[0][:])
+	}
+	m.hasSubtreeRoots = true
+}
+
 // MarkLeafAsDirty resets the leaf at the given index, so that it will be recomputed on the next call to ComputeRoot.
 func (m *MerkleTree) markLeafAsDirty(idx int) {
 	for i := 0; i < len(m.layers); i++ {
@@ -66,11 +172,8 @@ func (m *MerkleTree) markLeafAsDirty(idx int) {
 			break
 		}
 		if m.layers[i] == nil {
-			capacity := (layerSize / 2) * 3
-			if capacity == 0 {
-				capacity = 1024
-			}
-			m.layers[i] = make([]byte, layerSize, capacity)
+			capacity := (layerSize * 32 / 2) * 3
+			m.layers[i] = make([]byte, layerSize*32, capacity)
 		}
 		copy(m.layers[i][(idx/currDivisor)*32:], ZeroHashes[0][:])
 		if layerSize == 1 {
@@ -93,6 +196,48 @@ func (m *MerkleTree) AppendLeaf() {
 	m.dirtyLeaves = append(m.dirtyLeaves, atomic.Bool{})
 }
 
+// TruncateLeaves shrinks the tree to the first newCount leaves, keeping the cached hashes of the remaining leaves.
+// Only the nodes on the path of the new last leaf are marked dirty, since they are the only ones that covered removed leaves.
+// Calling it with newCount >= the current leaf count is a no-op.
+func (m *MerkleTree) TruncateLeaves(newCount int) {
+	if newCount < 0 {
+		panic("merkle_tree: negative leaf count")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if newCount >= m.leavesCount {
+		return
+	}
+	m.leavesCount = newCount
+	m.dirtyLeaves = m.dirtyLeaves[:newCount]
+
+	// Shrink each layer following the same sizing rules as extendLayer
+	prevLayerNodeCount := newCount
+	for i := 0; i < len(m.layers); i++ {
+		layerNodeCount := prevLayerNodeCount / 2
+		if layerNodeCount > 0 && prevLayerNodeCount%2 != 0 {
+			layerNodeCount++
+		}
+		if m.layers[i] != nil {
+			m.layers[i] = m.layers[i][:min(layerNodeCount*32, len(m.layers[i]))]
+		}
+		prevLayerNodeCount = layerNodeCount
+	}
+	if newCount == 0 {
+		return
+	}
+
+	// Mark the ancestors of the new last leaf as dirty
+	lastLeaf := newCount - 1
+	for i := 0; i < len(m.layers); i++ {
+		nodeOffset := (lastLeaf >> (i + 1)) * 32
+		if nodeOffset+32 > len(m.layers[i]) {
+			break
+		}
+		copy(m.layers[i][nodeOffset:], ZeroHashes[0][:])
+	}
+}
+
 // extendLayer extends the layer with the given index by 1.5x, by marking the new leaf as dirty.
 func (m *MerkleTree) extendLayer(layerIdx int) {
 	var prevLayerNodeCount int
@@ -128,13 +273,24 @@ func (m *MerkleTree) extendLayer(layerIdx int) {
 	}
 }
 
-// ComputeRoot computes the root of the Merkle tree.
+// ComputeRoot computes the root of the Merkle tree. It panics if the leaves cannot be read, see
+// TryComputeRoot.
 func (m *MerkleTree) ComputeRoot() [32]byte {
+	root, err := m.TryComputeRoot()
+	if err != nil {
+		panic(err)
+	}
+	return root
+}
+
+// TryComputeRoot computes the root of the Merkle tree, returning the errors of the LeafSource and
+// the Hasher. Nodes that could not be computed stay dirty, so a later call retries them.
+func (m *MerkleTree) TryComputeRoot() ([32]byte, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	var root [32]byte
 	if len(m.layers) == 0 {
-		return ZeroHashes[0]
+		return ZeroHashes[0], nil
 	}
 	for idx := range m.dirtyLeaves {
 		if m.dirtyLeaves[idx].Load() {
@@ -145,54 +301,51 @@ func (m *MerkleTree) ComputeRoot() [32]byte {
 
 	if m.leavesCount == 0 {
 		if m.limit == nil {
-			return ZeroHashes[0]
+			return ZeroHashes[0], nil
 		}
-		return ZeroHashes[GetDepth(*m.limit)]
+		return ZeroHashes[mathutil.GetDepth(*m.limit)], nil
 	}
 
 	if m.leavesCount <= 3 {
-		buf := make([]byte, 0, 3*32)
-		for i := 0; i < m.leavesCount; i++ {
-			m.computeLeaf(i, m.hashBuf[:32])
-			buf = append(buf, m.hashBuf[:32]...)
+		buf := make([]byte, m.leavesCount*32, 3*32)
+		if err := m.readLeaves(0, m.leavesCount, buf); err != nil {
+			return root, err
 		}
 		if m.limit != nil {
-			if err := ComputeMerkleRootRange(buf, root[:], *m.limit, 0); err != nil {
-				panic(err)
-			}
-			return root
-		}
-		if err := ComputeMerkleRootFromLevel(buf, root[:], uint64(m.leavesCount*32), 0); err != nil {
-			panic(err)
+			err := computeMerkleRootRange(hasherOrDefault(m.hasher), buf, root[:], *m.limit, 0)
+			return root, err
 		}
-		return root
+		err := computeMerkleRootFromLevel(hasherOrDefault(m.hasher), buf, root[:], uint64(m.leavesCount*32), 0)
+		return root, err
 	}
 
 	if len(m.layers[0]) == 32 {
 		var node [32]byte
-		m.computeLeaf(0, node[:])
+		if err := m.readLeaves(0, 1, node[:]); err != nil {
+			return root, err
+		}
 		if m.limit != nil {
-			if err := ComputeMerkleRootRange(node[:], root[:], *m.limit, 0); err != nil {
-				panic(err)
-			}
-			return root
+			err := computeMerkleRootRange(hasherOrDefault(m.hasher), node[:], root[:], *m.limit, 0)
+			return root, err
 		}
-		return node
+		return node, nil
 	}
 
 	// Compute the root
 	for i := 0; i < len(m.layers); i++ {
-		m.computeLayer(i)
+		if err := m.computeLayer(i); err != nil {
+			return root, err
+		}
 	}
 	// Find last layer with more than 0 elements
 	for i := 0; i < len(m.layers); i++ {
 		if len(m.layers[i]) == 0 {
-			m.finishHashing(i-1, root[:])
-			return root
+			err := m.finishHashing(i-1, root[:])
+			return root, err
 		}
 	}
-	m.finishHashing(len(m.layers)-1, root[:])
-	return root
+	err := m.finishHashing(len(m.layers)-1, root[:])
+	return root, err
 }
 
 func (m *MerkleTree) CopyInto(other *MerkleTree) {
@@ -202,8 +355,10 @@ func (m *MerkleTree) CopyInto(other *MerkleTree) {
 	defer other.mu.Unlock()
 
 	// Copy primitive fields
-	other.computeLeaf = m.computeLeaf
+	other.source = m.source
 	other.leavesCount = m.leavesCount
+	other.hasSubtreeRoots = m.hasSubtreeRoots
+	other.hasher = m.hasher
 	if m.limit != nil {
 		other.limit = new(uint64) // Shallow copy
 		*other.limit = *m.limit
@@ -244,62 +399,75 @@ func (m *MerkleTree) CopyInto(other *MerkleTree) {
 	}
 }
 
-func (m *MerkleTree) finishHashing(lastLayerIdx int, root []byte) {
+func (m *MerkleTree) finishHashing(lastLayerIdx int, root []byte) error {
 	if m.limit == nil {
-		if err := ComputeMerkleRootFromLevel(m.layers[lastLayerIdx], root, uint64(m.leavesCount*32), uint64(lastLayerIdx+1)); err != nil {
-			panic(err)
-		}
-		return
+		return computeMerkleRootFromLevel(hasherOrDefault(m.hasher), m.layers[lastLayerIdx], root, uint64(m.leavesCount*32), uint64(lastLayerIdx+1))
 	}
+	return computeMerkleRootRange(hasherOrDefault(m.hasher), m.layers[lastLayerIdx], root, *m.limit, uint64(lastLayerIdx+1))
+}
 
-	if err := ComputeMerkleRootRange(m.layers[lastLayerIdx], root, *m.limit, uint64(lastLayerIdx+1)); err != nil {
-		panic(err)
+// ensureLayer allocates the layer with the given index if it is missing, sized from the layer below it
+func (m *MerkleTree) ensureLayer(layerIdx int) {
+	if m.layers[layerIdx] != nil {
+		return
 	}
+	// find previous layer nodes count and round  to the next power of 2
+	prevLayerNodeCount := len(m.layers[layerIdx-1]) / 32
+	newExpectendLayerNodeCount := prevLayerNodeCount / 2
+	if newExpectendLayerNodeCount == 0 {
+		m.layers[layerIdx] = m.layers[layerIdx][:0]
+		return
+	}
+	if prevLayerNodeCount%2 != 0 {
+		newExpectendLayerNodeCount++
+	}
+	newLayerSize := newExpectendLayerNodeCount * 32
+	capacity := (newLayerSize / 2) * 3
+	m.layers[layerIdx] = make([]byte, newLayerSize, capacity)
 }
 
-func (m *MerkleTree) computeLayer(layerIdx int) {
-	currentDivisor := 1 << uint(layerIdx+1)
-	if m.layers[layerIdx] == nil {
-		// find previous layer nodes count and round  to the next power of 2
-		prevLayerNodeCount := len(m.layers[layerIdx-1]) / 32
-		newExpectendLayerNodeCount := prevLayerNodeCount / 2
-		if newExpectendLayerNodeCount == 0 {
-			m.layers[layerIdx] = m.layers[layerIdx][:0]
-			return
+// underCleanNode reports whether a node has a clean ancestor, which only nodes below a subtree root can have
+func (m *MerkleTree) underCleanNode(layerIdx, nodeIdx int) bool {
+	for i := layerIdx + 1; i < len(m.layers); i++ {
+		nodeOffset := (nodeIdx >> (i - layerIdx)) * 32
+		if nodeOffset+32 > len(m.layers[i]) {
+			return false
 		}
-		if prevLayerNodeCount%2 != 0 {
-			newExpectendLayerNodeCount++
+		if !bytes.Equal(m.layers[i][nodeOffset:nodeOffset+32], ZeroHashes[0][:]) {
+			return true
 		}
-		newLayerSize := newExpectendLayerNodeCount * 32
-		capacity := (newLayerSize / 2) * 3
-		m.layers[layerIdx] = make([]byte, newLayerSize, capacity)
 	}
+	return false
+}
+
+// dirtyNode reports whether a node of a layer has to be computed
+func (m *MerkleTree) dirtyNode(layerIdx, nodeIdx int) bool {
+	nodeOffset := nodeIdx * 32
+	if !bytes.Equal(m.layers[layerIdx][nodeOffset:nodeOffset+32], ZeroHashes[0][:]) {
+		return false
+	}
+	return !m.hasSubtreeRoots || !m.underCleanNode(layerIdx, nodeIdx)
+}
+
+func (m *MerkleTree) computeLayer(layerIdx int) error {
+	currentDivisor := 1 << uint(layerIdx+1)
+	m.ensureLayer(layerIdx)
 	if len(m.layers[layerIdx]) == 0 {
-		return
+		return nil
 	}
 
 	iterations := ceil(m.leavesCount, currentDivisor)
+	if layerIdx == 0 {
+		// leaf layer is always dirty
+		return m.computeLeafLayer(iterations)
+	}
 
 	for i := 0; i < iterations; i++ {
-		fromOffset := i * 32
-		toOffset := (i + 1) * 32
-		if !bytes.Equal(m.layers[layerIdx][fromOffset:toOffset], ZeroHashes[0][:]) {
-			continue
-		}
-		if layerIdx == 0 {
-			// leaf layer is always dirty
-			leafIndexBegin := i * 2
-			m.computeLeaf(leafIndexBegin, m.hashBuf[:32])
-			if leafIndexBegin == m.leavesCount-1 {
-				copy(m.hashBuf[32:], ZeroHashes[0][:])
-			} else {
-				m.computeLeaf(leafIndexBegin+1, m.hashBuf[32:])
-			}
-			if err := gohashtree.HashByteSlice(m.layers[layerIdx][fromOffset:toOffset], m.hashBuf[:]); err != nil {
-				panic(err)
-			}
+		if !m.dirtyNode(layerIdx, i) {
 			continue
 		}
+		fromOffset := i * 32
+		toOffset := (i + 1) * 32
 		childFromOffset := (i * 2) * 32
 		childToOffset := (i*2 + 2) * 32
 		if childToOffset > len(m.layers[layerIdx-1]) {
@@ -308,8 +476,49 @@ func (m *MerkleTree) computeLayer(layerIdx int) {
 		} else {
 			copy(m.hashBuf[:], m.layers[layerIdx-1][childFromOffset:childToOffset])
 		}
-		if err := gohashtree.HashByteSlice(m.layers[layerIdx][fromOffset:toOffset], m.hashBuf[:]); err != nil {
-			panic(err)
+		if err := hasherOrDefault(m.hasher).HashPairs(m.layers[layerIdx][fromOffset:toOffset], m.hashBuf[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computeLeafLayer computes the dirty nodes of the first layer from their leaves. The leaves of
+// consecutive dirty nodes are read from the source in batches of up to LeafBatchSize.
+func (m *MerkleTree) computeLeafLayer(nodes int) error {
+	h := hasherOrDefault(m.hasher)
+	for i := 0; i < nodes; {
+		if !m.dirtyNode(0, i) {
+			i++
+			continue
+		}
+		end := i + 1
+		for end < nodes && (end-i)*2 < LeafBatchSize && m.dirtyNode(0, end) {
+			end++
+		}
+		start := i * 2
+		count := min(end*2, m.leavesCount) - start
+		if cap(m.leafBuf) < (end-i)*64 {
+			m.leafBuf = make([]byte, LeafBatchSize*32)
+		}
+		buf := m.leafBuf[:(end-i)*64]
+		if err := m.readLeaves(start, count, buf[:count*32]); err != nil {
+			return err
+		}
+		// The last leaf of an odd count is paired with a zero leaf
+		clear(buf[count*32:])
+		if err := h.HashPairs(m.layers[0][i*32:end*32], buf); err != nil {
+			return err
 		}
+		i = end
+	}
+	return nil
+}
+
+// readLeaves reads count leaves from the source, starting at the leaf start
+func (m *MerkleTree) readLeaves(start, count int, out []byte) error {
+	if err := m.source.Leaves(start, count, out); err != nil {
+		return fmt.Errorf("merkle_tree: reading leaves [%d, %d): %w", start, start+count, err)
 	}
+	return nil
 }
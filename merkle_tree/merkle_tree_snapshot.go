@@ -0,0 +1,224 @@
+package merkle_tree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// snapshotVersion is the current version of the MerkleTree binary snapshot format
+const snapshotVersion = 1
+
+// maxUncachedSnapshotLeaves bounds the leaves count of a snapshot without cached layers, which cannot be
+// cross-checked, so a few bytes cannot make UnmarshalBinary allocate the dirty flags of billions of leaves
+const maxUncachedSnapshotLeaves = 1 << 16
+
+// ErrInvalidSnapshot is returned by UnmarshalBinary for malformed or unsupported snapshots
+var ErrInvalidSnapshot = errors.New("invalid merkle tree snapshot")
+
+// Snapshot layout, all integers little-endian:
+//
+//	version      uint8
+//	hasLimit     uint8 (0 or 1)
+//	limit        uint64
+//	leavesCount  uint64
+//	layersCount  uint32
+//	per layer:
+//	  present    uint8 (0 for a layer that was never allocated)
+//	  length     uint64 (bytes)
+//	  contents   [length]byte
+
+// MarshalBinary snapshots the leaves count, limit and cached intermediate layers so the tree can be
+// restored with UnmarshalBinary without rehashing. Pending dirty leaves are applied to the layers first,
+// so they are recomputed after restoring. The compute leaf function is not part of the snapshot.
+func (m *MerkleTree) MarshalBinary() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for idx := range m.dirtyLeaves {
+		if m.dirtyLeaves[idx].Load() {
+			m.markLeafAsDirty(idx)
+			m.dirtyLeaves[idx].Store(false)
+		}
+	}
+
+	size := 1 + 1 + 8 + 8 + 4
+	for _, layer := range m.layers {
+		size += 1 + 8 + len(layer)
+	}
+	out := make([]byte, 0, size)
+	out = append(out, snapshotVersion)
+	if m.limit != nil {
+		out = append(out, 1)
+		out = binary.LittleEndian.AppendUint64(out, *m.limit)
+	} else {
+		out = append(out, 0)
+		out = binary.LittleEndian.AppendUint64(out, 0)
+	}
+	out = binary.LittleEndian.AppendUint64(out, uint64(m.leavesCount))
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(m.layers)))
+	for _, layer := range m.layers {
+		if layer == nil {
+			out = append(out, 0)
+		} else {
+			out = append(out, 1)
+		}
+		out = binary.LittleEndian.AppendUint64(out, uint64(len(layer)))
+		out = append(out, layer...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary restores a tree from a snapshot produced by MarshalBinary.
+// The compute leaf function is kept if already set, otherwise it must be set with SetComputeLeafFn before computing the root.
+func (m *MerkleTree) UnmarshalBinary(data []byte) error {
+	r := snapshotReader{data: data}
+	version := r.uint8()
+	if r.err == nil && version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+	hasLimit := r.uint8()
+	limit := r.uint64()
+	leavesCount := r.uint64()
+	layersCount := r.uint32()
+	if r.err != nil {
+		return r.err
+	}
+	if hasLimit > 1 {
+		return fmt.Errorf("%w: invalid limit flag %d", ErrInvalidSnapshot, hasLimit)
+	}
+	// Every layer takes at least 9 bytes, which bounds the allocation below
+	if uint64(layersCount)*9 > uint64(r.remaining()) {
+		return fmt.Errorf("%w: layers count %d exceeds snapshot size", ErrInvalidSnapshot, layersCount)
+	}
+
+	layers := make([][]byte, layersCount)
+	for i := range layers {
+		present := r.uint8()
+		length := r.uint64()
+		contents := r.bytes(length)
+		if r.err != nil {
+			return r.err
+		}
+		if length%32 != 0 {
+			return fmt.Errorf("%w: layer %d length %d is not a multiple of 32", ErrInvalidSnapshot, i, length)
+		}
+		if present == 0 {
+			if length != 0 {
+				return fmt.Errorf("%w: unallocated layer %d has contents", ErrInvalidSnapshot, i)
+			}
+			continue
+		}
+		layers[i] = make([]byte, length, length+length/2)
+		copy(layers[i], contents)
+	}
+	if r.remaining() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidSnapshot, r.remaining())
+	}
+	if hasLimit == 1 && leavesCount > limit {
+		return fmt.Errorf("%w: leaves count %d exceeds limit %d", ErrInvalidSnapshot, leavesCount, limit)
+	}
+	if len(layers) == 0 {
+		if leavesCount > maxUncachedSnapshotLeaves {
+			return fmt.Errorf("%w: leaves count %d too large", ErrInvalidSnapshot, leavesCount)
+		}
+	} else if err := checkSnapshotLayers(layers, leavesCount); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.layers = layers
+	m.leavesCount = int(leavesCount)
+	m.dirtyLeaves = make([]atomic.Bool, leavesCount)
+	if hasLimit == 1 {
+		m.limit = new(uint64)
+		*m.limit = limit
+	} else {
+		m.limit = nil
+	}
+	return nil
+}
+
+// checkSnapshotLayers checks that the layers have the sizes the tree gives them for leavesCount
+// leaves, see extendLayer, so they can be indexed by leaf. The layers are allocated from the first
+// one up, and the first layer holds a node per pair of leaves, which bounds the leaves count by the
+// size of the snapshot.
+func checkSnapshotLayers(layers [][]byte, leavesCount uint64) error {
+	if layers[0] == nil {
+		return fmt.Errorf("%w: unallocated first layer", ErrInvalidSnapshot)
+	}
+	firstLayerNodes := uint64(len(layers[0]) / 32)
+	// Initialize gives a single leaf a first layer node, AppendLeaf and TruncateLeaves do not
+	if firstLayerNodes != parentNodeCount(leavesCount) && (leavesCount != 1 || firstLayerNodes != 1) {
+		return fmt.Errorf("%w: leaves count %d does not match first layer of %d nodes", ErrInvalidSnapshot, leavesCount, firstLayerNodes)
+	}
+	for i := 1; i < len(layers); i++ {
+		if layers[i] == nil {
+			continue
+		}
+		if layers[i-1] == nil {
+			return fmt.Errorf("%w: layer %d is allocated above an unallocated layer", ErrInvalidSnapshot, i)
+		}
+		nodes := uint64(len(layers[i]) / 32)
+		if want := parentNodeCount(uint64(len(layers[i-1]) / 32)); nodes != want {
+			return fmt.Errorf("%w: layer %d has %d nodes, want %d", ErrInvalidSnapshot, i, nodes, want)
+		}
+	}
+	return nil
+}
+
+// parentNodeCount returns the number of nodes of the layer above one of n nodes, none above a single node
+func parentNodeCount(n uint64) uint64 {
+	if n < 2 {
+		return 0
+	}
+	return (n + 1) / 2
+}
+
+// snapshotReader reads little-endian values from a snapshot, recording the first error
+type snapshotReader struct {
+	data []byte
+	err  error
+}
+
+func (r *snapshotReader) remaining() int {
+	return len(r.data)
+}
+
+func (r *snapshotReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.data)) < n {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidSnapshot)
+		return nil
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *snapshotReader) uint8() uint8 {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *snapshotReader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *snapshotReader) uint64() uint64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
@@ -1,6 +1,7 @@
 package merkle_tree_test
 
 import (
+	"encoding/binary"
 	"testing"
 
 	"github.com/gfx-labs/ssz/merkle_tree"
@@ -140,3 +141,308 @@ func TestMerkleTreeAppendLeafWithLowMaxDepthAndLimitAndTestWR(t *testing.T) {
 	expectedRoot := getExpectedRootWithLimit(testBuffer, int(lm))
 	require.Equal(t, expectedRoot, mt.ComputeRoot())
 }
+
+func TestMerkleTreeTruncateLeaves(t *testing.T) {
+	limit := uint64(1 << 12)
+	for _, maxDepth := range []int{2, 6, merkle_tree.OptimalMaxTreeCacheDepth} {
+		for _, withLimit := range []bool{false, true} {
+			for _, initial := range []int{1, 2, 5, 17, 64, 100} {
+				for newCount := 0; newCount <= initial; newCount++ {
+					testBuffer := make([]byte, initial*32)
+					for i := range initial {
+						testBuffer[i*32] = byte(i + 1)
+						testBuffer[i*32+31] = byte(i * 7)
+					}
+					var limitPtr *uint64
+					if withLimit {
+						limitPtr = &limit
+					}
+					mt := merkle_tree.MerkleTree{}
+					mt.Initialize(initial, maxDepth, func(idx int, out []byte) {
+						copy(out, testBuffer[idx*32:(idx+1)*32])
+					}, limitPtr)
+					mt.ComputeRoot()
+
+					mt.TruncateLeaves(newCount)
+					truncated := testBuffer[:newCount*32]
+					var expected [32]byte
+					if withLimit && newCount == 0 {
+						expected = merkle_tree.ZeroHashes[merkle_tree.GetDepth(limit)]
+					} else if withLimit {
+						expected = getExpectedRootWithLimit(truncated, int(limit))
+					} else {
+						expected = getExpectedRoot(truncated)
+					}
+					require.Equal(t, expected, mt.ComputeRoot(), "depth %d limit %v initial %d truncated to %d", maxDepth, withLimit, initial, newCount)
+
+					// The tree must keep working when growing again
+					mt.AppendLeaf()
+					mt.AppendLeaf()
+					grown := append(append([]byte{}, truncated...), make([]byte, 64)...)
+					grown[newCount*32] = 0xaa
+					grown[newCount*32+32] = 0xbb
+					testBuffer = grown
+					if withLimit {
+						expected = getExpectedRootWithLimit(grown, int(limit))
+					} else {
+						expected = getExpectedRoot(grown)
+					}
+					require.Equal(t, expected, mt.ComputeRoot(), "depth %d limit %v initial %d regrown from %d", maxDepth, withLimit, initial, newCount)
+				}
+			}
+		}
+	}
+}
+
+func TestMerkleTreeTruncateLeavesNoop(t *testing.T) {
+	mt := merkle_tree.MerkleTree{}
+	testBuffer := make([]byte, 4*32)
+	testBuffer[0] = 1
+	testBuffer[96] = 9
+	mt.Initialize(4, 6, func(idx int, out []byte) {
+		copy(out, testBuffer[idx*32:(idx+1)*32])
+	}, nil)
+	expectedRoot := getExpectedRoot(testBuffer)
+	require.Equal(t, expectedRoot, mt.ComputeRoot())
+	mt.TruncateLeaves(4)
+	mt.TruncateLeaves(10)
+	require.Equal(t, expectedRoot, mt.ComputeRoot())
+	require.Panics(t, func() { mt.TruncateLeaves(-1) })
+}
+
+func TestMerkleTreeMarkRangeAsDirty(t *testing.T) {
+	for _, maxDepth := range []int{2, merkle_tree.OptimalMaxTreeCacheDepth} {
+		const leaves = 37
+		testBuffer := make([]byte, leaves*32)
+		for i := range leaves {
+			testBuffer[i*32] = byte(i + 1)
+		}
+		mt := merkle_tree.MerkleTree{}
+		mt.Initialize(leaves, maxDepth, func(idx int, out []byte) {
+			copy(out, testBuffer[idx*32:(idx+1)*32])
+		}, nil)
+		require.Equal(t, getExpectedRoot(testBuffer), mt.ComputeRoot())
+
+		for _, r := range [][2]int{{0, 1}, {3, 4}, {5, 17}, {16, 32}, {30, 37}, {0, 37}, {10, 10}} {
+			for i := r[0]; i < r[1]; i++ {
+				testBuffer[i*32+1]++
+			}
+			mt.MarkRangeAsDirty(r[0], r[1])
+			require.Equal(t, getExpectedRoot(testBuffer), mt.ComputeRoot(), "depth %d range %v", maxDepth, r)
+		}
+
+		// Leaves outside the range must keep their cached hashes
+		testBuffer[0] = 0xff
+		mt.MarkRangeAsDirty(2, leaves)
+		require.NotEqual(t, getExpectedRoot(testBuffer), mt.ComputeRoot())
+
+		mt.MarkAllDirty()
+		require.Equal(t, getExpectedRoot(testBuffer), mt.ComputeRoot())
+
+		require.Panics(t, func() { mt.MarkRangeAsDirty(0, leaves+1) })
+		require.Panics(t, func() { mt.MarkRangeAsDirty(5, 4) })
+	}
+}
+
+func TestMerkleTreeSetSubtreeRoot(t *testing.T) {
+	const leaves = 37
+	testBuffer := make([]byte, leaves*32)
+	for i := range leaves {
+		testBuffer[i*32] = byte(i + 1)
+	}
+	computed := 0
+	computeLeaf := func(idx int, out []byte) {
+		computed++
+		copy(out, testBuffer[idx*32:(idx+1)*32])
+	}
+	// subtreeRoot is the root of the 2^level leaves below a node, computed outside the tree
+	subtreeRoot := func(level, index int) [32]byte {
+		var root [32]byte
+		from, to := (index<<level)*32, min((index+1)<<level, leaves)*32
+		require.NoError(t, merkle_tree.ComputeMerkleRootRange(testBuffer[from:to], root[:], 1<<level, 0))
+		return root
+	}
+
+	mt := merkle_tree.MerkleTree{}
+	mt.Initialize(leaves, merkle_tree.OptimalMaxTreeCacheDepth, computeLeaf, nil)
+	require.Equal(t, getExpectedRoot(testBuffer), mt.ComputeRoot())
+
+	// Leaves changed behind the tree are not hashed again when their subtree root is set
+	for i := 8; i < 16; i++ {
+		testBuffer[i*32+1] = 0xaa
+	}
+	mt.MarkLeafAsDirty(9)
+	mt.SetSubtreeRoot(3, 1, subtreeRoot(3, 1))
+	computed = 0
+	require.Equal(t, getExpectedRoot(testBuffer), mt.ComputeRoot())
+	require.Equal(t, 0, computed)
+
+	// A dirty leaf below the subtree root recomputes the whole subtree
+	testBuffer[10*32+2] = 0xbb
+	mt.MarkLeafAsDirty(10)
+	computed = 0
+	require.Equal(t, getExpectedRoot(testBuffer), mt.ComputeRoot())
+	require.Equal(t, 8, computed)
+
+	// Subtree roots can be set before the first ComputeRoot, including the partial last one
+	fresh := merkle_tree.MerkleTree{}
+	fresh.Initialize(leaves, 4, computeLeaf, nil)
+	fresh.SetSubtreeRoot(2, 2, subtreeRoot(2, 2))
+	fresh.SetSubtreeRoot(1, 18, subtreeRoot(1, 18))
+	computed = 0
+	require.Equal(t, getExpectedRoot(testBuffer), fresh.ComputeRoot())
+	require.Equal(t, leaves-5, computed)
+
+	// Appending a leaf below the last subtree root recomputes it
+	testBuffer = append(testBuffer, make([]byte, 32)...)
+	testBuffer[leaves*32] = 0xcc
+	fresh.AppendLeaf()
+	require.Equal(t, getExpectedRoot(testBuffer), fresh.ComputeRoot())
+
+	fresh.MarkAllDirty()
+	computed = 0
+	require.Equal(t, getExpectedRoot(testBuffer), fresh.ComputeRoot())
+	require.Equal(t, leaves+1, computed)
+
+	require.Panics(t, func() { mt.SetSubtreeRoot(0, 0, [32]byte{1}) })
+	require.Panics(t, func() { mt.SetSubtreeRoot(merkle_tree.OptimalMaxTreeCacheDepth+1, 0, [32]byte{1}) })
+	require.Panics(t, func() { mt.SetSubtreeRoot(1, 19, [32]byte{1}) })
+}
+
+func BenchmarkMerkleTreeMarkDirty(b *testing.B) {
+	const leaves = 100_000
+	mt := merkle_tree.MerkleTree{}
+	mt.Initialize(leaves, merkle_tree.OptimalMaxTreeCacheDepth, func(idx int, out []byte) {
+		out[0] = byte(idx)
+	}, nil)
+	mt.ComputeRoot()
+
+	b.Run("MarkLeafAsDirty", func(b *testing.B) {
+		for b.Loop() {
+			for i := range leaves {
+				mt.MarkLeafAsDirty(i)
+			}
+		}
+	})
+
+	b.Run("MarkRangeAsDirty", func(b *testing.B) {
+		for b.Loop() {
+			mt.MarkRangeAsDirty(0, leaves)
+		}
+	})
+}
+
+func TestMerkleTreeSnapshot(t *testing.T) {
+	limit := uint64(1 << 12)
+	for _, limitPtr := range []*uint64{nil, &limit} {
+		const leaves = 45
+		testBuffer := make([]byte, leaves*32)
+		for i := range leaves {
+			testBuffer[i*32] = byte(i + 1)
+		}
+		computeLeaf := func(idx int, out []byte) {
+			copy(out, testBuffer[idx*32:(idx+1)*32])
+		}
+		mt := merkle_tree.MerkleTree{}
+		mt.Initialize(leaves, merkle_tree.OptimalMaxTreeCacheDepth, computeLeaf, limitPtr)
+		expectedRoot := mt.ComputeRoot()
+
+		// A pending dirty leaf must survive the snapshot
+		testBuffer[7*32] = 0xff
+		mt.MarkLeafAsDirty(7)
+
+		snapshot, err := mt.MarshalBinary()
+		require.NoError(t, err)
+
+		restored := merkle_tree.MerkleTree{}
+		require.NoError(t, restored.UnmarshalBinary(snapshot))
+		restored.SetComputeLeafFn(computeLeaf)
+		require.Equal(t, mt.ComputeRoot(), restored.ComputeRoot())
+		require.NotEqual(t, expectedRoot, restored.ComputeRoot())
+
+		// Cached hashes are reused: leaves that are not dirty are never recomputed
+		computed := 0
+		restored.SetComputeLeafFn(func(idx int, out []byte) {
+			computed++
+			computeLeaf(idx, out)
+		})
+		testBuffer[20*32] = 0xee
+		restored.MarkLeafAsDirty(20)
+		mt.MarkLeafAsDirty(20)
+		require.Equal(t, mt.ComputeRoot(), restored.ComputeRoot())
+		require.Equal(t, 2, computed)
+
+		// The restored tree keeps growing like the original
+		testBuffer = append(testBuffer, make([]byte, 32)...)
+		testBuffer[leaves*32] = 0xdd
+		restored.AppendLeaf()
+		mt.AppendLeaf()
+		require.Equal(t, mt.ComputeRoot(), restored.ComputeRoot())
+	}
+}
+
+func TestMerkleTreeSnapshotInvalid(t *testing.T) {
+	mt := merkle_tree.MerkleTree{}
+	mt.Initialize(8, 4, func(idx int, out []byte) { out[0] = byte(idx) }, nil)
+	mt.ComputeRoot()
+	snapshot, err := mt.MarshalBinary()
+	require.NoError(t, err)
+
+	var restored merkle_tree.MerkleTree
+	require.ErrorIs(t, restored.UnmarshalBinary(nil), merkle_tree.ErrInvalidSnapshot)
+	require.ErrorIs(t, restored.UnmarshalBinary(snapshot[:len(snapshot)-1]), merkle_tree.ErrInvalidSnapshot)
+	require.ErrorIs(t, restored.UnmarshalBinary(append(snapshot, 0)), merkle_tree.ErrInvalidSnapshot)
+
+	badVersion := append([]byte{}, snapshot...)
+	badVersion[0] = 99
+	require.ErrorContains(t, restored.UnmarshalBinary(badVersion), "unsupported version")
+
+	badLeaves := append([]byte{}, snapshot...)
+	badLeaves[10] = 200
+	require.ErrorContains(t, restored.UnmarshalBinary(badLeaves), "does not match first layer")
+
+	// The second layer of the 8 leaves tree holds 2 nodes, at offset 22+(1+8+4*32)+1
+	badLayer := append([]byte{}, snapshot[:160]...)
+	badLayer = binary.LittleEndian.AppendUint64(badLayer, 32)
+	badLayer = append(badLayer, snapshot[168:168+32]...)
+	badLayer = append(badLayer, snapshot[168+64:]...)
+	require.ErrorContains(t, restored.UnmarshalBinary(badLayer), "layer 1 has 1 nodes, want 2")
+
+	limit := uint64(4)
+	mt.Initialize(8, 4, func(idx int, out []byte) { out[0] = byte(idx) }, &limit)
+	overLimit, err := mt.MarshalBinary()
+	require.NoError(t, err)
+	require.ErrorContains(t, restored.UnmarshalBinary(overLimit), "exceeds limit")
+
+	// A snapshot without layers cannot back the dirty flags of billions of leaves
+	uncached := []byte{1, 0}
+	uncached = binary.LittleEndian.AppendUint64(uncached, 0)
+	uncached = binary.LittleEndian.AppendUint64(uncached, 1<<32)
+	uncached = binary.LittleEndian.AppendUint32(uncached, 0)
+	require.ErrorContains(t, restored.UnmarshalBinary(uncached), "too large")
+}
+
+func TestMerkleTreeSnapshotLeavesCounts(t *testing.T) {
+	// Snapshots of trees grown and truncated to any size are accepted
+	computeLeaf := func(idx int, out []byte) { out[0] = byte(idx + 1) }
+	for leaves := 0; leaves <= 20; leaves++ {
+		var initialized, appended, truncated merkle_tree.MerkleTree
+		initialized.Initialize(leaves, merkle_tree.OptimalMaxTreeCacheDepth, computeLeaf, nil)
+		appended.Initialize(0, merkle_tree.OptimalMaxTreeCacheDepth, computeLeaf, nil)
+		truncated.Initialize(40, merkle_tree.OptimalMaxTreeCacheDepth, computeLeaf, nil)
+		truncated.ComputeRoot()
+		for range leaves {
+			appended.AppendLeaf()
+		}
+		truncated.TruncateLeaves(leaves)
+		for _, mt := range []*merkle_tree.MerkleTree{&initialized, &appended, &truncated} {
+			want := mt.ComputeRoot()
+			snapshot, err := mt.MarshalBinary()
+			require.NoError(t, err)
+			var restored merkle_tree.MerkleTree
+			require.NoError(t, restored.UnmarshalBinary(snapshot), "%d leaves", leaves)
+			restored.SetComputeLeafFn(computeLeaf)
+			require.Equal(t, want, restored.ComputeRoot(), "%d leaves", leaves)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package merkle_tree
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
+)
+
+// Multiproofs prove several nodes of a tree at once, as described in the consensus specs
+// (ssz/merkle-proofs.md). Nodes are named by their generalized index: the root is 1, and the
+// children of node i are 2i and 2i+1, so leaf j of a tree of depth d is 2^d + j. The proof holds
+// the helper nodes, which are the siblings of the nodes on the paths to the root that cannot be
+// computed from the proven nodes, ordered by descending generalized index. A helper shared by
+// several paths is only included once.
+
+// ErrInvalidMultiProof is returned for multiproofs whose sizes do not match their indices
+var ErrInvalidMultiProof = errors.New("invalid multiproof")
+
+// MultiProofHelperIndices returns the generalized indices of the helper nodes needed to prove
+// the nodes at indices, in the order their hashes appear in a multiproof
+func MultiProofHelperIndices(indices []int) []int {
+	helpers := make(map[int]bool)
+	paths := make(map[int]bool)
+	for _, index := range indices {
+		for i := index; i > 1; i /= 2 {
+			helpers[i^1] = true
+			paths[i] = true
+		}
+	}
+	// The proven nodes and everything above them are computed, not provided
+	for index := range paths {
+		delete(helpers, index)
+	}
+	for _, index := range indices {
+		delete(helpers, index)
+	}
+	sorted := make([]int, 0, len(helpers))
+	for index := range helpers {
+		sorted = append(sorted, index)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	return sorted
+}
+
+// MerkleMultiProof returns the multiproof of the nodes at the generalized indices in the tree
+// with the given leaves, padded with zero chunks to the next power of 2
+func MerkleMultiProof(leaves [][32]byte, indices []int) ([][32]byte, error) {
+	width := int(mathutil.NextPowerOfTwo(uint64(len(leaves))))
+	for _, index := range indices {
+		if index < 1 || index >= 2*width {
+			return nil, fmt.Errorf("generalized index %d is not in a tree of %d leaves", index, width)
+		}
+	}
+
+	// nodes[i] is the node at generalized index i
+	nodes := make([][32]byte, 2*width)
+	copy(nodes[width:], leaves)
+	for i := width - 1; i >= 1; i-- {
+		nodes[i] = Sha256(nodes[2*i][:], nodes[2*i+1][:])
+	}
+
+	helpers := MultiProofHelperIndices(indices)
+	proof := make([][32]byte, len(helpers))
+	for i, index := range helpers {
+		proof[i] = nodes[index]
+	}
+	return proof, nil
+}
+
+// MultiProofRoot returns the root computed from the nodes at the generalized indices and the
+// helper nodes of their multiproof
+func MultiProofRoot(leaves [][32]byte, indices []int, proof [][32]byte) ([32]byte, error) {
+	if len(leaves) != len(indices) {
+		return [32]byte{}, fmt.Errorf("%w: %d leaves for %d indices", ErrInvalidMultiProof, len(leaves), len(indices))
+	}
+	helpers := MultiProofHelperIndices(indices)
+	if len(proof) != len(helpers) {
+		return [32]byte{}, fmt.Errorf("%w: %d proof hashes, expected %d", ErrInvalidMultiProof, len(proof), len(helpers))
+	}
+
+	nodes := make(map[int][32]byte, len(indices)+len(helpers))
+	keys := make([]int, 0, len(indices)+len(helpers))
+	for i, index := range indices {
+		if index < 1 {
+			return [32]byte{}, fmt.Errorf("%w: generalized index %d", ErrInvalidMultiProof, index)
+		}
+		nodes[index] = leaves[i]
+		keys = append(keys, index)
+	}
+	for i, index := range helpers {
+		nodes[index] = proof[i]
+		keys = append(keys, index)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+
+	// Parents are appended to the keys as they are computed, so they are visited in turn and
+	// their own parents computed once their siblings are known
+	for pos := 0; pos < len(keys); pos++ {
+		k := keys[pos]
+		_, hasSibling := nodes[k^1]
+		_, hasParent := nodes[k/2]
+		if k > 1 && hasSibling && !hasParent {
+			left, right := nodes[k&^1], nodes[k|1]
+			nodes[k/2] = Sha256(left[:], right[:])
+			keys = append(keys, k/2)
+		}
+	}
+	root, ok := nodes[1]
+	if !ok {
+		return [32]byte{}, fmt.Errorf("%w: the proof does not reach the root", ErrInvalidMultiProof)
+	}
+	return root, nil
+}
+
+// VerifyMultiProof reports whether the multiproof shows that the leaves are the nodes at the
+// generalized indices of the tree with the given root
+func VerifyMultiProof(root [32]byte, leaves [][32]byte, indices []int, proof [][32]byte) bool {
+	computed, err := MultiProofRoot(leaves, indices, proof)
+	return err == nil && computed == root
+}
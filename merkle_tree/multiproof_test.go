@@ -0,0 +1,75 @@
+package merkle_tree_test
+
+import (
+	"testing"
+
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiProofHelperIndices(t *testing.T) {
+	// From the examples in the consensus specs: proving 9 and 14 of a tree of depth 3
+	assert.Equal(t, []int{15, 8, 6, 5}, merkle_tree.MultiProofHelperIndices([]int{9, 14}))
+	assert.Equal(t, []int{9, 5, 3}, merkle_tree.MultiProofHelperIndices([]int{8}))
+	// Siblings of each other need no helpers at their level
+	assert.Equal(t, []int{5, 3}, merkle_tree.MultiProofHelperIndices([]int{8, 9}))
+	// A node and its descendants share the path above the node
+	assert.Equal(t, []int{9, 5, 3}, merkle_tree.MultiProofHelperIndices([]int{8, 4}))
+	assert.Empty(t, merkle_tree.MultiProofHelperIndices([]int{1}))
+}
+
+func TestMerkleMultiProof(t *testing.T) {
+	leaves := make([][32]byte, 11)
+	for i := range leaves {
+		leaves[i][0] = byte(i + 1)
+	}
+	var root [32]byte
+	require.NoError(t, merkle_tree.MerklizeChunks(leaves, root[:]))
+
+	// Leaf i of the 16 leaf tree is at generalized index 16+i
+	for _, indices := range [][]int{{16}, {16, 17}, {18, 26}, {16, 20, 21, 26, 31}, {4, 26}, {2, 3}, {1}} {
+		proof, err := merkle_tree.MerkleMultiProof(leaves, indices)
+		require.NoError(t, err)
+		assert.Len(t, proof, len(merkle_tree.MultiProofHelperIndices(indices)))
+
+		nodes := make([][32]byte, len(indices))
+		for i, index := range indices {
+			nodes[i] = node(leaves, index)
+		}
+		assert.True(t, merkle_tree.VerifyMultiProof(root, nodes, indices, proof), "%v", indices)
+
+		nodes[0][1] ^= 1
+		assert.False(t, merkle_tree.VerifyMultiProof(root, nodes, indices, proof), "%v", indices)
+		nodes[0][1] ^= 1
+		if len(proof) > 0 {
+			proof[len(proof)-1][1] ^= 1
+			assert.False(t, merkle_tree.VerifyMultiProof(root, nodes, indices, proof), "%v", indices)
+			assert.False(t, merkle_tree.VerifyMultiProof(root, nodes, indices, proof[1:]), "%v", indices)
+		}
+	}
+
+	// A single leaf is proven by the same branch as MerkleProof
+	branch, err := merkle_tree.MerkleProof(4, 5, leaves...)
+	require.NoError(t, err)
+	proof, err := merkle_tree.MerkleMultiProof(leaves, []int{21})
+	require.NoError(t, err)
+	assert.Equal(t, branch, proof)
+
+	_, err = merkle_tree.MerkleMultiProof(leaves, []int{32})
+	assert.Error(t, err)
+	_, err = merkle_tree.MultiProofRoot(leaves[:1], []int{16, 17}, nil)
+	assert.ErrorIs(t, err, merkle_tree.ErrInvalidMultiProof)
+}
+
+// node computes the node at a generalized index of the 16 leaf tree by hashing its leaves
+func node(leaves [][32]byte, index int) [32]byte {
+	if index >= 16 {
+		if index-16 < len(leaves) {
+			return leaves[index-16]
+		}
+		return [32]byte{}
+	}
+	left, right := node(leaves, 2*index), node(leaves, 2*index+1)
+	return merkle_tree.Sha256(left[:], right[:])
+}
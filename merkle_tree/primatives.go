@@ -2,6 +2,8 @@ package merkle_tree
 
 import (
 	"encoding/binary"
+
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
 )
 
 // Uint64Root retrieves the root hash of a uint64 value by converting it to a byte array and returning it as a hash.
@@ -11,7 +13,7 @@ func Uint64Root(val uint64) (root [32]byte) {
 }
 
 func BytesRoot(b []byte) (out [32]byte, err error) {
-	leafCount := NextPowerOfTwo(uint64((len(b) + 31) / 32))
+	leafCount := mathutil.NextPowerOfTwo(uint64((len(b) + 31) / 32))
 	leaves := make([]byte, leafCount*32)
 	copy(leaves, b)
 	if err = ComputeMerkleRoot(leaves, leaves); err != nil {
@@ -0,0 +1,153 @@
+package merkle_tree
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
+)
+
+// RootBuilder computes the merkle root of chunks written one at a time, so a root can be
+// computed while the chunks are produced, e.g. while serializing, without holding all of them.
+// It keeps one node per level of the tree: the root of the complete subtree waiting for its
+// right sibling. Pairs are hashed as soon as both are known, with crypto/sha256.
+type RootBuilder struct {
+	limit uint64
+	depth uint8
+	count uint64 // Complete chunks written
+	// nodes[i] is the pending left node at level i, set when bit i of count is, and
+	// nodes[depth] the root once the tree is full
+	nodes [65][32]byte
+	// partial holds the first n bytes of a chunk written by WriteBytes
+	partial [32]byte
+	n       int
+}
+
+// NewRootBuilder returns a builder for a tree of up to limit chunks, rounded up to a power of
+// two like MerkleizeVector
+func NewRootBuilder(limit uint64) *RootBuilder {
+	if limit > 1<<63 {
+		// The limit rounds up to 2^64 leaves, past what mathutil.NextPowerOfTwo represents
+		return &RootBuilder{limit: limit, depth: 64}
+	}
+	return &RootBuilder{limit: limit, depth: mathutil.GetDepth(mathutil.NextPowerOfTwo(limit))}
+}
+
+// Count returns the number of chunks written, including a partial chunk from WriteBytes
+func (b *RootBuilder) Count() uint64 {
+	if b.n > 0 {
+		return b.count + 1
+	}
+	return b.count
+}
+
+// WriteChunk adds a chunk after the ones written so far. It fails when the tree is full. A
+// partial chunk left by WriteBytes is completed with zeros first.
+func (b *RootBuilder) WriteChunk(chunk [32]byte) error {
+	if b.n > 0 {
+		if err := b.flushPartial(); err != nil {
+			return err
+		}
+	}
+	return b.add(chunk)
+}
+
+// WriteBytes packs p into chunks after the ones written so far. Consecutive calls fill the same
+// chunk, and the last chunk is padded with zeros. Nothing is written when p does not fit.
+func (b *RootBuilder) WriteBytes(p []byte) error {
+	chunks := (uint64(b.n) + uint64(len(p)) + 31) / 32
+	if b.count+chunks < b.count || b.count+chunks > b.limit {
+		return fmt.Errorf("%d bytes do not fit in a tree of %d chunks with %d written", len(p), b.limit, b.Count())
+	}
+	if b.n > 0 {
+		copied := copy(b.partial[b.n:], p)
+		b.n += copied
+		p = p[copied:]
+		if b.n < 32 {
+			return nil
+		}
+		if err := b.flushPartial(); err != nil {
+			return err
+		}
+	}
+	for ; len(p) >= 32; p = p[32:] {
+		if err := b.add([32]byte(p)); err != nil {
+			return err
+		}
+	}
+	b.n = copy(b.partial[:], p)
+	return nil
+}
+
+// Sum returns the root of the chunks written so far, with the remaining leaves zero. The builder
+// is not changed, so more chunks can be written after it.
+func (b *RootBuilder) Sum() [32]byte {
+	nodes, count := b.nodes, b.count
+	if b.n > 0 {
+		var last [32]byte
+		copy(last[:], b.partial[:b.n])
+		push(&nodes, count, last)
+		count++
+	}
+	if count == 0 {
+		return ZeroHashes[b.depth]
+	}
+	if count == 1<<b.depth {
+		// A full tree is the node its last chunk completed
+		return nodes[b.depth]
+	}
+
+	// Fold the pending nodes from the bottom, with zero subtrees right of the last chunk
+	var node [32]byte
+	pending := false
+	for i := uint8(0); i < b.depth; i++ {
+		switch {
+		case count&(1<<i) != 0 && pending:
+			node = hashPair(nodes[i], node)
+		case count&(1<<i) != 0:
+			node, pending = hashPair(nodes[i], ZeroHashes[i]), true
+		case pending:
+			node = hashPair(node, ZeroHashes[i])
+		}
+	}
+	return node
+}
+
+// Reset clears the builder, for a tree of the same limit
+func (b *RootBuilder) Reset() {
+	*b = RootBuilder{limit: b.limit, depth: b.depth}
+}
+
+// flushPartial adds the partial chunk, padded with zeros
+func (b *RootBuilder) flushPartial() error {
+	clear(b.partial[b.n:])
+	b.n = 0
+	return b.add(b.partial)
+}
+
+// add adds a complete chunk, hashing it with the pending nodes it completes
+func (b *RootBuilder) add(chunk [32]byte) error {
+	if b.count >= b.limit {
+		return fmt.Errorf("tree of %d chunks is full", b.limit)
+	}
+	push(&b.nodes, b.count, chunk)
+	b.count++
+	return nil
+}
+
+// push adds the chunk after count chunks to the pending nodes
+func push(nodes *[65][32]byte, count uint64, chunk [32]byte) {
+	node := chunk
+	i := 0
+	for ; count&(1<<i) != 0; i++ {
+		node = hashPair(nodes[i], node)
+	}
+	nodes[i] = node
+}
+
+func hashPair(left, right [32]byte) [32]byte {
+	var pair [64]byte
+	copy(pair[:32], left[:])
+	copy(pair[32:], right[:])
+	return sha256.Sum256(pair[:])
+}
@@ -0,0 +1,84 @@
+package merkle_tree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootBuilder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, limit := range []uint64{0, 1, 2, 3, 5, 8, 13, 64} {
+		for count := uint64(0); count <= limit; count++ {
+			chunks := make([][32]byte, count)
+			for i := range chunks {
+				r.Read(chunks[i][:])
+			}
+			want, err := MerkleizeVector(append([][32]byte(nil), chunks...), limit)
+			require.NoError(t, err)
+
+			b := NewRootBuilder(limit)
+			for _, chunk := range chunks {
+				require.NoError(t, b.WriteChunk(chunk))
+			}
+			assert.Equal(t, want, b.Sum(), "limit %d, %d chunks", limit, count)
+			assert.Equal(t, want, b.Sum(), "Sum changed the builder")
+			if count == limit {
+				assert.Error(t, b.WriteChunk([32]byte{}), "limit %d", limit)
+			}
+		}
+	}
+}
+
+func TestRootBuilder_LargeLimit(t *testing.T) {
+	chunks := [][32]byte{{1}, {2}, {3}}
+	want, err := MerkleizeVector(append([][32]byte(nil), chunks...), 1<<40)
+	require.NoError(t, err)
+	b := NewRootBuilder(1 << 40)
+	for _, chunk := range chunks {
+		require.NoError(t, b.WriteChunk(chunk))
+	}
+	assert.Equal(t, want, b.Sum())
+
+	// Limits past 2^63 round up to 2^64 leaves, the chunks are the left half of the tree
+	left, err := MerkleizeVector(append([][32]byte(nil), chunks[:2]...), 1<<63)
+	require.NoError(t, err)
+	for _, limit := range []uint64{1<<63 + 1, math.MaxUint64} {
+		b := NewRootBuilder(limit)
+		require.NoError(t, b.WriteChunk(chunks[0]))
+		require.NoError(t, b.WriteChunk(chunks[1]))
+		assert.Equal(t, Sha256(left[:], ZeroHashes[63][:]), b.Sum(), "limit %d", limit)
+	}
+	assert.Equal(t, ZeroHashes[64], NewRootBuilder(math.MaxUint64).Sum())
+}
+
+func TestRootBuilder_WriteBytes(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	data := make([]byte, 200)
+	r.Read(data)
+	want, err := MerkleizeVectorFlat(append(data, make([]byte, 24)...), 16)
+	require.NoError(t, err)
+
+	// Bytes split across calls fill the same chunks, and the last one is padded with zeros
+	b := NewRootBuilder(16)
+	for _, n := range []int{5, 27, 32, 1, 100, 35} {
+		require.NoError(t, b.WriteBytes(data[:n]))
+		data = data[n:]
+	}
+	assert.EqualValues(t, 7, b.Count())
+	assert.Equal(t, want, b.Sum())
+
+	// A chunk after a partial one starts a new chunk
+	b.Reset()
+	require.NoError(t, b.WriteBytes([]byte{1}))
+	require.NoError(t, b.WriteChunk([32]byte{2}))
+	want, err = MerkleizeVector([][32]byte{{1}, {2}}, 16)
+	require.NoError(t, err)
+	assert.Equal(t, want, b.Sum())
+
+	assert.Error(t, NewRootBuilder(2).WriteBytes(make([]byte, 65)))
+	assert.NoError(t, NewRootBuilder(2).WriteBytes(make([]byte, 64)))
+}
@@ -0,0 +1,200 @@
+// Package soak runs randomized sequences of operations on a merkle_tree.MerkleTree and checks
+// every root against a naive recomputation from all of its leaves. The incremental layer
+// bookkeeping behind AppendLeaf, MarkLeafAsDirty, TruncateLeaves and CopyInto is easy to get
+// subtly wrong, so downstream CI can run Run with its own seeds and tree shapes.
+package soak
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
+)
+
+// Config selects the tree and the operations to run. Zero fields take their defaults.
+type Config struct {
+	// Seed of the random sequence, so a failure can be replayed
+	Seed int64
+	// Operations is the number of operations to run, 1000 by default
+	Operations int
+	// InitialLeaves is the number of leaves the tree is initialized with
+	InitialLeaves int
+	// MaxLeaves bounds the number of leaves, 256 by default
+	MaxLeaves int
+	// MaxTreeCacheDepth is passed to Initialize, merkle_tree.OptimalMaxTreeCacheDepth by default
+	MaxTreeCacheDepth int
+	// Limit is the optional leaf limit passed to Initialize. It must be a power of 2 no smaller
+	// than MaxLeaves.
+	Limit *uint64
+}
+
+// Op is an operation run on the tree
+type Op string
+
+const (
+	OpAppend      Op = "append"       // Append a new leaf
+	OpMarkDirty   Op = "mark_dirty"   // Change a leaf and mark it dirty
+	OpMarkRange   Op = "mark_range"   // Change a range of leaves and mark them dirty
+	OpMarkAll     Op = "mark_all"     // Change every leaf and mark the tree dirty
+	OpTruncate    Op = "truncate"     // Truncate to fewer leaves
+	OpCopyInto    Op = "copy_into"    // Copy the tree into another tree and continue with the copy
+	OpSubtreeRoot Op = "subtree_root" // Set a known subtree root with SetSubtreeRoot
+	OpNoop        Op = "noop"         // Change nothing, so cached nodes must be reused as is
+)
+
+var ops = []Op{OpAppend, OpAppend, OpMarkDirty, OpMarkDirty, OpMarkRange, OpMarkAll, OpTruncate, OpCopyInto, OpSubtreeRoot, OpNoop}
+
+// Failure is the error returned when the tree computes a wrong root
+type Failure struct {
+	Seed     int64
+	Step     int      // Index of the failing operation
+	History  []string // Operations up to and including the failing one
+	Leaves   int      // Number of leaves after the failing operation
+	Expected [32]byte
+	Got      [32]byte
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("soak: seed %d step %d with %d leaves: root %x, expected %x\n%s",
+		f.Seed, f.Step, f.Leaves, f.Got, f.Expected, strings.Join(f.History, "\n"))
+}
+
+// Run runs cfg.Operations random operations on a tree, checking its root after each one. It
+// returns a *Failure for the first wrong root, and an error for an invalid Config.
+func Run(cfg Config) error {
+	if cfg.Operations == 0 {
+		cfg.Operations = 1000
+	}
+	if cfg.MaxLeaves == 0 {
+		cfg.MaxLeaves = 256
+	}
+	if cfg.MaxTreeCacheDepth == 0 {
+		cfg.MaxTreeCacheDepth = merkle_tree.OptimalMaxTreeCacheDepth
+	}
+	if cfg.InitialLeaves < 0 || cfg.InitialLeaves > cfg.MaxLeaves {
+		return fmt.Errorf("soak: initial leaves %d not in [0, %d]", cfg.InitialLeaves, cfg.MaxLeaves)
+	}
+	if cfg.Limit != nil && (!mathutil.IsPowerOf2(*cfg.Limit) || *cfg.Limit < uint64(cfg.MaxLeaves)) {
+		return fmt.Errorf("soak: limit %d must be a power of 2 of at least %d", *cfg.Limit, cfg.MaxLeaves)
+	}
+
+	s := &state{rng: rand.New(rand.NewSource(cfg.Seed)), cfg: cfg}
+	s.leaves = make([][32]byte, cfg.InitialLeaves)
+	for i := range s.leaves {
+		s.leaves[i] = s.randomLeaf()
+	}
+	s.tree = new(merkle_tree.MerkleTree)
+	s.tree.Initialize(len(s.leaves), cfg.MaxTreeCacheDepth, s.computeLeaf, cfg.Limit)
+	s.spare = new(merkle_tree.MerkleTree)
+
+	var history []string
+	for step := 0; step < cfg.Operations; step++ {
+		history = append(history, s.run(ops[s.rng.Intn(len(ops))]))
+		expected := naiveRoot(s.leaves, cfg.Limit)
+		if got := s.tree.ComputeRoot(); got != expected {
+			return &Failure{Seed: cfg.Seed, Step: step, History: history, Leaves: len(s.leaves), Expected: expected, Got: got}
+		}
+	}
+	return nil
+}
+
+// state holds the tree under test and the leaves it should hash
+type state struct {
+	rng    *rand.Rand
+	cfg    Config
+	leaves [][32]byte
+	tree   *merkle_tree.MerkleTree
+	spare  *merkle_tree.MerkleTree // Reused by CopyInto, so copies into trees with old layers are covered
+}
+
+func (s *state) computeLeaf(idx int, out []byte) {
+	copy(out, s.leaves[idx][:])
+}
+
+// randomLeaf returns a random leaf, which is zero now and then, since zero nodes are special
+// to the tree
+func (s *state) randomLeaf() (leaf [32]byte) {
+	if s.rng.Intn(8) != 0 {
+		s.rng.Read(leaf[:])
+	}
+	return leaf
+}
+
+// run runs op and describes what it did
+func (s *state) run(op Op) string {
+	n := len(s.leaves)
+	switch {
+	case op == OpAppend && n < s.cfg.MaxLeaves:
+		s.leaves = append(s.leaves, s.randomLeaf())
+		s.tree.AppendLeaf()
+		return fmt.Sprintf("%s -> %d", op, len(s.leaves))
+	case op == OpMarkDirty && n > 0:
+		i := s.rng.Intn(n)
+		s.leaves[i] = s.randomLeaf()
+		s.tree.MarkLeafAsDirty(i)
+		return fmt.Sprintf("%s %d", op, i)
+	case op == OpMarkRange && n > 0:
+		start := s.rng.Intn(n)
+		end := start + s.rng.Intn(n-start+1)
+		for i := start; i < end; i++ {
+			s.leaves[i] = s.randomLeaf()
+		}
+		s.tree.MarkRangeAsDirty(start, end)
+		return fmt.Sprintf("%s [%d, %d)", op, start, end)
+	case op == OpMarkAll:
+		for i := range s.leaves {
+			s.leaves[i] = s.randomLeaf()
+		}
+		s.tree.MarkAllDirty()
+		return string(op)
+	case op == OpTruncate && n > 0:
+		newCount := s.rng.Intn(n)
+		s.leaves = s.leaves[:newCount]
+		s.tree.TruncateLeaves(newCount)
+		return fmt.Sprintf("%s -> %d", op, newCount)
+	case op == OpCopyInto:
+		// The original must keep working after being copied from
+		s.tree.CopyInto(s.spare)
+		s.tree, s.spare = s.spare, s.tree
+		return string(op)
+	case op == OpSubtreeRoot && n > 1:
+		maxLevel := min(s.cfg.MaxTreeCacheDepth, int(mathutil.GetDepth(mathutil.NextPowerOfTwo(uint64(n)))))
+		level := 1 + s.rng.Intn(maxLevel)
+		index := s.rng.Intn((n + (1 << level) - 1) >> level)
+		start := index << level
+		end := min(start+1<<level, n)
+		s.tree.SetSubtreeRoot(level, index, naiveSubtreeRoot(s.leaves[start:end], level))
+		return fmt.Sprintf("%s level %d index %d", op, level, index)
+	}
+	return string(OpNoop)
+}
+
+// naiveRoot hashes the leaves padded with zero chunks to the next power of 2, or to limit
+func naiveRoot(leaves [][32]byte, limit *uint64) [32]byte {
+	count := uint64(len(leaves))
+	if limit != nil {
+		count = *limit
+	}
+	return naiveSubtreeRoot(leaves, int(mathutil.GetDepth(mathutil.NextPowerOfTwo(count))))
+}
+
+// naiveSubtreeRoot hashes the leaves as the subtree of the given depth, padded with zero chunks
+func naiveSubtreeRoot(leaves [][32]byte, depth int) [32]byte {
+	layer := append([][32]byte(nil), leaves...)
+	for level := 0; level < depth; level++ {
+		if len(layer)%2 != 0 {
+			layer = append(layer, merkle_tree.ZeroHash(uint8(level)))
+		}
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = merkle_tree.Sha256(layer[2*i][:], layer[2*i+1][:])
+		}
+		layer = next
+	}
+	if len(layer) == 0 {
+		return merkle_tree.ZeroHash(uint8(depth))
+	}
+	return layer[0]
+}
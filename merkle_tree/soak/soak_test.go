@@ -0,0 +1,42 @@
+package soak
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	limit := uint64(1024)
+	configs := map[string]Config{
+		"default":     {},
+		"limit":       {Limit: &limit, InitialLeaves: 100},
+		"shallow":     {MaxTreeCacheDepth: 2, InitialLeaves: 10},
+		"small":       {MaxLeaves: 5},
+		"many leaves": {MaxLeaves: 2000, InitialLeaves: 1500},
+	}
+	seeds := 20
+	if testing.Short() {
+		seeds = 2
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			for seed := range seeds {
+				cfg.Seed = int64(seed)
+				err := Run(cfg)
+				var failure *Failure
+				if errors.As(err, &failure) {
+					t.Fatalf("%v", failure)
+				}
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	limit := uint64(100)
+	require.ErrorContains(t, Run(Config{Limit: &limit}), "power of 2")
+	require.ErrorContains(t, Run(Config{InitialLeaves: 300}), "initial leaves")
+}
@@ -1,3 +1,5 @@
+//go:build !purego && !tinygo && !wasm
+
 package merkle_tree
 
 import "unsafe"
@@ -22,3 +24,9 @@ func chunkedToSingle(xs [][32]byte) []byte {
 	// then we move over the values
 	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(xs))), len(xs)<<5)
 }
+
+// hashChunkPairs hashes the pairs of chunks in xs in place, into its first half
+func hashChunkPairs(xs [][32]byte) error {
+	data := chunkedToSingle(xs)
+	return DefaultHasher.HashPairs(data, data)
+}
@@ -0,0 +1,41 @@
+//go:build purego || tinygo || wasm
+
+package merkle_tree
+
+// singleToChunked copies a slice of bytes to a slice of [32]byte, for targets without unsafe
+// memory access
+func singleToChunked(xs []byte) [][32]byte {
+	if len(xs) == 0 {
+		return nil
+	}
+	out := make([][32]byte, len(xs)>>5)
+	for i := range out {
+		copy(out[i][:], xs[i<<5:])
+	}
+	return out
+}
+
+// chunkedToSingle copies a slice of [32]byte to a slice of bytes, for targets without unsafe
+// memory access
+func chunkedToSingle(xs [][32]byte) []byte {
+	if len(xs) == 0 {
+		return nil
+	}
+	out := make([]byte, 0, len(xs)<<5)
+	for i := range xs {
+		out = append(out, xs[i][:]...)
+	}
+	return out
+}
+
+// hashChunkPairs hashes the pairs of chunks in xs in place, into its first half
+func hashChunkPairs(xs [][32]byte) error {
+	data := chunkedToSingle(xs)
+	if err := DefaultHasher.HashPairs(data, data); err != nil {
+		return err
+	}
+	for i := 0; i < len(xs)/2; i++ {
+		copy(xs[i][:], data[i<<5:])
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package ssz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/yaml"
+)
+
+// A schema is a list of named types, the structs of a genssz schema, in JSON or YAML:
+//
+//	- name: Checkpoint
+//	  type: container
+//	  children:
+//	    - {name: epoch, type: uint64}
+//	    - {name: root, type: bytevector, size: 32}
+//
+// Types refer to each other with fields of type ref. "bytevector" is read as a vector of uint8,
+// which is how it is written back, and the elements of lists and vectors, which schemas may
+// leave unnamed, are named "element".
+
+// typeByteVector is the schema shorthand for a vector of uint8, expanded when a schema is parsed
+const typeByteVector TypeName = "bytevector"
+
+// ParseSchemaJSON reads a schema from JSON and returns its types by name. Unknown keys, unnamed
+// or repeated types and types that are not valid, see Field.IsValid, are errors.
+func ParseSchemaJSON(data []byte) (map[string]Field, error) {
+	var fields []Field
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	return schemaTypes(fields)
+}
+
+// ParseSchemaYAML reads a schema from YAML, see ParseSchemaJSON
+func ParseSchemaYAML(data []byte) (map[string]Field, error) {
+	var fields []Field
+	if err := yaml.UnmarshalStrict(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	return schemaTypes(fields)
+}
+
+// MarshalSchema validates types and writes them as a schema, in JSON sorted by name, which
+// ParseSchemaJSON and ParseSchemaYAML read back to the same types
+func MarshalSchema(types map[string]Field) ([]byte, error) {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]Field, len(names))
+	for i, name := range names {
+		field := normalizeField(types[name])
+		if field.Name != name {
+			return nil, fmt.Errorf("type '%s' is named '%s'", name, field.Name)
+		}
+		fields[i] = field
+	}
+	if _, err := schemaTypes(fields); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(fields, "", "  ")
+}
+
+// schemaTypes indexes fields by name and validates them
+func schemaTypes(fields []Field) (map[string]Field, error) {
+	types := make(map[string]Field, len(fields))
+	for i, field := range fields {
+		if field.Name == "" {
+			return nil, fmt.Errorf("type %d has no name", i)
+		}
+		if _, ok := types[field.Name]; ok {
+			return nil, fmt.Errorf("type '%s' is defined twice", field.Name)
+		}
+		types[field.Name] = normalizeField(field)
+	}
+	for _, field := range fields {
+		field := types[field.Name]
+		if err := field.IsValid(types); err != nil {
+			return nil, err
+		}
+	}
+	return types, nil
+}
+
+// normalizeField replaces the bytevector shorthand in f and its children and names their
+// elements
+func normalizeField(f Field) Field {
+	if f.Type == typeByteVector {
+		f.Type = TypeVector
+		f.Children = []Field{{Name: "element", Type: TypeUint8}}
+		return f
+	}
+	if len(f.Children) > 0 {
+		children := make([]Field, len(f.Children))
+		for i, child := range f.Children {
+			if child.Name == "" && (f.Type == TypeList || f.Type == TypeVector) {
+				child.Name = "element"
+			}
+			children[i] = normalizeField(child)
+		}
+		f.Children = children
+	}
+	return f
+}
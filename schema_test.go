@@ -0,0 +1,71 @@
+package ssz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const schemaTestYAML = `
+- name: Checkpoint
+  type: container
+  children:
+    - {name: epoch, type: uint64}
+    - {name: root, type: bytevector, size: 32}
+- name: State
+  type: container
+  children:
+    - {name: finalized, type: ref, ref: Checkpoint}
+    - name: balances
+      type: list
+      limit: 16
+      children:
+        - {type: uint64}
+`
+
+func TestParseSchema(t *testing.T) {
+	types, err := ParseSchemaYAML([]byte(schemaTestYAML))
+	require.NoError(t, err)
+	require.Len(t, types, 2)
+	assert.Equal(t, Field{Name: "root", Type: TypeVector, Size: 32, Children: []Field{{Name: "element", Type: TypeUint8}}}, types["Checkpoint"].Children[1])
+	assert.Equal(t, "element", types["State"].Children[1].Children[0].Name)
+
+	// JSON and YAML read back to the same types
+	data, err := MarshalSchema(types)
+	require.NoError(t, err)
+	fromJSON, err := ParseSchemaJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, types, fromJSON)
+	fromYAML, err := ParseSchemaYAML(data)
+	require.NoError(t, err)
+	assert.Equal(t, types, fromYAML)
+	again, err := MarshalSchema(fromJSON)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":  `[{"name": "A", "type": "uint64", "limt": 4}]`,
+		"no name":      `[{"type": "uint64"}]`,
+		"twice":        `[{"name": "A", "type": "uint64"}, {"name": "A", "type": "uint8"}]`,
+		"missing ref":  `[{"name": "A", "type": "container", "children": [{"name": "b", "type": "ref", "ref": "B"}]}]`,
+		"no limit":     `[{"name": "A", "type": "list", "children": [{"type": "uint8"}]}]`,
+		"unknown type": `[{"name": "A", "type": "uint42"}]`,
+		"not a list":   `{"name": "A", "type": "uint64"}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSchemaJSON([]byte(data))
+			assert.Error(t, err, "json")
+			_, err = ParseSchemaYAML([]byte(data))
+			assert.Error(t, err, "yaml")
+		})
+	}
+
+	_, err := MarshalSchema(map[string]Field{"A": {Name: "B", Type: TypeUint64}})
+	assert.Error(t, err)
+	_, err = MarshalSchema(map[string]Field{"A": {Name: "A", Type: TypeVector}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+// Package sszapi is the minimal, stable interface to the common SSZ workflow: encoding and
+// decoding tagged Go structs, computing their hash tree roots and proving their fields. It is
+// built on flexssz and merkle_tree, whose APIs may change between versions, so code that only
+// needs these operations should depend on this package instead.
+package sszapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/gfx-labs/ssz/merkle_tree"
+	"github.com/gfx-labs/ssz/merkle_tree/mathutil"
+)
+
+// Marshal returns the SSZ encoding of v, a struct or pointer to one tagged as described by
+// flexssz
+func Marshal(v any) ([]byte, error) {
+	return flexssz.Marshal(v)
+}
+
+// Unmarshal decodes data into v, which must be a pointer
+func Unmarshal(data []byte, v any) error {
+	return flexssz.Unmarshal(data, v)
+}
+
+// UnmarshalStrict is Unmarshal that rejects encodings that are not canonical
+func UnmarshalStrict(data []byte, v any) error {
+	return flexssz.UnmarshalStrict(data, v)
+}
+
+// HashTreeRoot returns the hash tree root of v
+func HashTreeRoot(v any) ([32]byte, error) {
+	return flexssz.HashTreeRoot(v)
+}
+
+// Proof is a merkle proof that Leaf is the node at GeneralizedIndex in the tree of a value
+type Proof struct {
+	GeneralizedIndex uint64
+	Leaf             [32]byte
+	// Branch holds the sibling of each node on the path from the leaf to the root, leaf first
+	Branch [][32]byte
+}
+
+// ProveField returns a proof of the hash tree root of a field of v, a struct or pointer to one.
+// path is a dot-separated path of Go field names, e.g. "Body.ExecutionPayload", which may only
+// go through fields that are containers.
+func ProveField(v any, path string) (*Proof, error) {
+	proof := &Proof{GeneralizedIndex: 1}
+	rv := reflect.ValueOf(v)
+	for _, name := range strings.Split(path, ".") {
+		var info *flexssz.TypeInfo
+		for info == nil || info.Wrapped != nil {
+			if info != nil {
+				// Fields of transparent wrappers are fields of the wrapped value
+				rv = rv.Field(info.Wrapped.Index)
+			}
+			for rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					return nil, fmt.Errorf("%s: cannot prove a field of a nil pointer", path)
+				}
+				rv = rv.Elem()
+			}
+			var err error
+			if info, err = flexssz.GetTypeInfo(rv.Type(), nil); err != nil {
+				return nil, fmt.Errorf("error getting type info: %w", err)
+			}
+		}
+		index := -1
+		for i, field := range info.Fields {
+			if field.Name == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("%s: %v has no field %s", path, rv.Type(), name)
+		}
+
+		roots, err := flexssz.FieldRoots(rv.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		proof.Leaf = roots[index]
+		depth := int(mathutil.GetDepth(mathutil.NextPowerOfTwo(uint64(len(roots)))))
+		branch, err := merkle_tree.MerkleProof(depth, index, roots...)
+		if err != nil {
+			return nil, err
+		}
+
+		// Nested proofs are deeper in the tree, so their branches come first
+		proof.Branch = append(branch, proof.Branch...)
+		proof.GeneralizedIndex = proof.GeneralizedIndex<<depth | uint64(index)
+		if field := info.Fields[index]; field.Path != nil {
+			rv = rv.FieldByIndex(field.Path)
+		} else {
+			rv = rv.Field(field.Index)
+		}
+	}
+	return proof, nil
+}
+
+// Verify reports whether the proof is valid for a value with the given hash tree root
+func (p *Proof) Verify(root [32]byte) bool {
+	if p.GeneralizedIndex == 0 || len(p.Branch) != int(mathutil.GetDepth(p.GeneralizedIndex)) {
+		return false
+	}
+	node := p.Leaf
+	index := p.GeneralizedIndex
+	for _, sibling := range p.Branch {
+		if index&1 == 1 {
+			node = merkle_tree.Sha256(sibling[:], node[:])
+		} else {
+			node = merkle_tree.Sha256(node[:], sibling[:])
+		}
+		index >>= 1
+	}
+	return node == root
+}
@@ -0,0 +1,82 @@
+package sszapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type testWrapper struct {
+	Checkpoint testCheckpoint `ssz:",transparent"`
+}
+
+type testState struct {
+	Slot       uint64
+	Finalized  *testCheckpoint
+	Justified  testWrapper
+	Balances   []uint64 `ssz-max:"16"`
+	ParentRoot [32]byte
+}
+
+func TestRoundTrip(t *testing.T) {
+	state := &testState{
+		Slot:       3,
+		Finalized:  &testCheckpoint{Epoch: 1, Root: [32]byte{0xaa}},
+		Justified:  testWrapper{testCheckpoint{Epoch: 2, Root: [32]byte{0xbb}}},
+		Balances:   []uint64{32, 31},
+		ParentRoot: [32]byte{9},
+	}
+	data, err := Marshal(state)
+	require.NoError(t, err)
+
+	var decoded testState
+	require.NoError(t, UnmarshalStrict(data, &decoded))
+	assert.Equal(t, state, &decoded)
+	require.NoError(t, Unmarshal(data, &decoded))
+
+	root, err := HashTreeRoot(state)
+	require.NoError(t, err)
+
+	for path, gindex := range map[string]uint64{
+		"Slot":            8,
+		"Balances":        11,
+		"ParentRoot":      12,
+		"Finalized.Root":  19,
+		"Justified.Epoch": 20,
+		"Finalized":       9,
+	} {
+		proof, err := ProveField(state, path)
+		require.NoError(t, err, path)
+		assert.Equal(t, gindex, proof.GeneralizedIndex, path)
+		assert.True(t, proof.Verify(root), path)
+
+		// Tampering with any part of the proof invalidates it
+		proof.Leaf[0] ^= 1
+		assert.False(t, proof.Verify(root), path)
+		proof.Leaf[0] ^= 1
+		proof.Branch[0][0] ^= 1
+		assert.False(t, proof.Verify(root), path)
+		proof.Branch[0][0] ^= 1
+		proof.GeneralizedIndex ^= 1
+		assert.False(t, proof.Verify(root), path)
+	}
+
+	finalizedRoot, err := HashTreeRoot(state.Finalized)
+	require.NoError(t, err)
+	proof, err := ProveField(state, "Finalized")
+	require.NoError(t, err)
+	assert.Equal(t, finalizedRoot, proof.Leaf)
+
+	_, err = ProveField(state, "Missing")
+	assert.ErrorContains(t, err, "has no field Missing")
+	_, err = ProveField(state, "Slot.Value")
+	assert.Error(t, err)
+	_, err = ProveField(&testState{}, "Finalized.Root")
+	assert.ErrorContains(t, err, "nil pointer")
+}
@@ -0,0 +1,53 @@
+// Package sszlist provides List, a slice type that carries its SSZ list limit in its type, so
+// flexssz encodes, decodes and hashes it without an ssz-max tag:
+//
+//	type MaxValidators struct{}
+//
+//	func (MaxValidators) Limit() int { return 1 << 40 }
+//
+//	type BeaconState struct {
+//		Validators sszlist.List[*Validator, MaxValidators]
+//	}
+package sszlist
+
+import "fmt"
+
+// Limit is implemented by the types naming the limit of a List. Limit is called on the zero
+// value, so limit types are usually empty structs.
+type Limit interface {
+	Limit() int
+}
+
+// List is a list of at most N elements of type T
+type List[T any, N Limit] []T
+
+// New returns a list holding xs, or an error if there are more than N of them
+func New[T any, N Limit](xs ...T) (List[T, N], error) {
+	l := List[T, N](xs)
+	if err := l.Check(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// SSZMax returns the limit of the list, which flexssz uses as its ssz-max
+func (List[T, N]) SSZMax() int {
+	var n N
+	return n.Limit()
+}
+
+// Check returns an error if the list holds more elements than its limit
+func (l List[T, N]) Check() error {
+	if limit := l.SSZMax(); len(l) > limit {
+		return fmt.Errorf("list of %d elements exceeds limit %d", len(l), limit)
+	}
+	return nil
+}
+
+// Append returns the list with xs appended, or an error if it would exceed its limit
+func (l List[T, N]) Append(xs ...T) (List[T, N], error) {
+	if limit := l.SSZMax(); len(l)+len(xs) > limit {
+		return l, fmt.Errorf("list of %d elements exceeds limit %d", len(l)+len(xs), limit)
+	}
+	return append(l, xs...), nil
+}
@@ -0,0 +1,109 @@
+package sszlist_test
+
+import (
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/gfx-labs/ssz/sszlist"
+	"github.com/gfx-labs/ssz/sszvec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type max4 struct{}
+
+func (max4) Limit() int { return 4 }
+
+type max16 struct{}
+
+func (max16) Limit() int { return 16 }
+
+type size2 struct{}
+
+func (size2) Size() int { return 2 }
+
+type checkpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type typed struct {
+	Balances    sszlist.List[uint64, max16]
+	Graffiti    sszlist.List[byte, max16]
+	Checkpoints sszlist.List[*checkpoint, max4]
+	Nested      sszlist.List[sszlist.List[byte, max16], max4]
+	Roots       sszvec.Vector[[32]byte, size2]
+	Pairs       sszvec.Vector[sszvec.Vector[uint16, size2], size2]
+}
+
+type tagged struct {
+	Balances    []uint64      `ssz-max:"16"`
+	Graffiti    []byte        `ssz-max:"16"`
+	Checkpoints []*checkpoint `ssz-max:"4"`
+	Nested      [][]byte      `ssz-max:"4,16"`
+	Roots       [][32]byte    `ssz-size:"2"`
+	Pairs       [][]uint16    `ssz-size:"2,2"`
+}
+
+func TestTypedLimits(t *testing.T) {
+	v := &typed{
+		Balances:    sszlist.List[uint64, max16]{1, 2, 3},
+		Graffiti:    sszlist.List[byte, max16]("hi"),
+		Checkpoints: sszlist.List[*checkpoint, max4]{{Epoch: 1}},
+		Nested:      sszlist.List[sszlist.List[byte, max16], max4]{{1}, {2, 3}},
+		Roots:       sszvec.Vector[[32]byte, size2]{{1}, {2}},
+		Pairs:       sszvec.Vector[sszvec.Vector[uint16, size2], size2]{{1, 2}, {3, 4}},
+	}
+	w := &tagged{
+		Balances:    []uint64{1, 2, 3},
+		Graffiti:    []byte("hi"),
+		Checkpoints: []*checkpoint{{Epoch: 1}},
+		Nested:      [][]byte{{1}, {2, 3}},
+		Roots:       [][32]byte{{1}, {2}},
+		Pairs:       [][]uint16{{1, 2}, {3, 4}},
+	}
+
+	data, err := flexssz.Marshal(v)
+	require.NoError(t, err)
+	expected, err := flexssz.Marshal(w)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	root, err := flexssz.HashTreeRoot(v)
+	require.NoError(t, err)
+	expectedRoot, err := flexssz.HashTreeRoot(w)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+
+	var decoded typed
+	require.NoError(t, flexssz.UnmarshalStrict(data, &decoded))
+	assert.Equal(t, v, &decoded)
+
+	// The limits are enforced like tagged ones
+	v.Checkpoints = append(v.Checkpoints, nil, nil, nil, nil)
+	_, err = flexssz.Marshal(v)
+	assert.Error(t, err)
+
+	// Lists can be encoded on their own
+	balances := sszlist.List[uint64, max16]{5, 6}
+	data, err = flexssz.Marshal(balances)
+	require.NoError(t, err)
+	assert.Len(t, data, 16)
+	root, err = flexssz.HashTreeRoot(balances)
+	require.NoError(t, err)
+	expectedRoot, err = flexssz.HashTreeRootList([]uint64(balances), 16)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRoot, root)
+}
+
+func TestList(t *testing.T) {
+	l, err := sszlist.New[uint64, max4](1, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, l.SSZMax())
+	l, err = l.Append(4)
+	require.NoError(t, err)
+	_, err = l.Append(5)
+	assert.Error(t, err)
+	_, err = sszlist.New[uint64, max4](1, 2, 3, 4, 5)
+	assert.Error(t, err)
+}
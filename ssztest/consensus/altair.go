@@ -0,0 +1,96 @@
+package consensus
+
+// Containers added or changed in altair
+
+type SyncAggregate struct {
+	SyncCommitteeBits      []byte `ssz:"bitvector" ssz-size:"512"`
+	SyncCommitteeSignature [96]byte
+}
+
+type SyncCommittee struct {
+	Pubkeys         [512][48]byte
+	AggregatePubkey [48]byte
+}
+
+type SyncCommitteeMessage struct {
+	Slot            uint64
+	BeaconBlockRoot [32]byte
+	ValidatorIndex  uint64
+	Signature       [96]byte
+}
+
+type SyncCommitteeContribution struct {
+	Slot              uint64
+	BeaconBlockRoot   [32]byte
+	SubcommitteeIndex uint64
+	AggregationBits   []byte `ssz:"bitvector" ssz-size:"128"`
+	Signature         [96]byte
+}
+
+type ContributionAndProof struct {
+	AggregatorIndex uint64
+	Contribution    SyncCommitteeContribution
+	SelectionProof  [96]byte
+}
+
+type SignedContributionAndProof struct {
+	Message   ContributionAndProof
+	Signature [96]byte
+}
+
+type SyncAggregatorSelectionData struct {
+	Slot              uint64
+	SubcommitteeIndex uint64
+}
+
+type BeaconBlockBodyAltair struct {
+	RandaoReveal      [96]byte
+	Eth1Data          Eth1Data
+	Graffiti          [32]byte
+	ProposerSlashings []ProposerSlashing    `ssz-max:"16"`
+	AttesterSlashings []AttesterSlashing    `ssz-max:"2"`
+	Attestations      []Attestation         `ssz-max:"128"`
+	Deposits          []Deposit             `ssz-max:"16"`
+	VoluntaryExits    []SignedVoluntaryExit `ssz-max:"16"`
+	SyncAggregate     SyncAggregate
+}
+
+type BeaconBlockAltair struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte
+	StateRoot     [32]byte
+	Body          BeaconBlockBodyAltair
+}
+
+type SignedBeaconBlockAltair struct {
+	Message   BeaconBlockAltair
+	Signature [96]byte
+}
+
+type BeaconStateAltair struct {
+	GenesisTime                 uint64
+	GenesisValidatorsRoot       [32]byte
+	Slot                        uint64
+	Fork                        Fork
+	LatestBlockHeader           BeaconBlockHeader
+	BlockRoots                  [8192][32]byte
+	StateRoots                  [8192][32]byte
+	HistoricalRoots             [][32]byte `ssz-max:"16777216"`
+	Eth1Data                    Eth1Data
+	Eth1DataVotes               []Eth1Data `ssz-max:"2048"`
+	Eth1DepositIndex            uint64
+	Validators                  []Validator `ssz-max:"1099511627776"`
+	Balances                    []uint64    `ssz-max:"1099511627776"`
+	RandaoMixes                 [65536][32]byte
+	Slashings                   [8192]uint64
+	PreviousEpochParticipation  []uint8 `ssz-max:"1099511627776"`
+	CurrentEpochParticipation   []uint8 `ssz-max:"1099511627776"`
+	JustificationBits           []byte  `ssz:"bitvector" ssz-size:"4"`
+	PreviousJustifiedCheckpoint Checkpoint
+	CurrentJustifiedCheckpoint  Checkpoint
+	FinalizedCheckpoint         Checkpoint
+	InactivityScores            []uint64 `ssz-max:"1099511627776"`
+	CurrentSyncCommittee        SyncCommittee
+	NextSyncCommittee           SyncCommittee
+}
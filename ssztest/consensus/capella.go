@@ -0,0 +1,122 @@
+package consensus
+
+import "github.com/holiman/uint256"
+
+// Containers added or changed in capella
+
+type Withdrawal struct {
+	Index          uint64
+	ValidatorIndex uint64
+	Address        [20]byte
+	Amount         uint64
+}
+
+type BLSToExecutionChange struct {
+	ValidatorIndex     uint64
+	FromBLSPubkey      [48]byte
+	ToExecutionAddress [20]byte
+}
+
+type SignedBLSToExecutionChange struct {
+	Message   BLSToExecutionChange
+	Signature [96]byte
+}
+
+type HistoricalSummary struct {
+	BlockSummaryRoot [32]byte
+	StateSummaryRoot [32]byte
+}
+
+type ExecutionPayloadCapella struct {
+	ParentHash    [32]byte
+	FeeRecipient  [20]byte
+	StateRoot     [32]byte
+	ReceiptsRoot  [32]byte
+	LogsBloom     [256]byte
+	PrevRandao    [32]byte
+	BlockNumber   uint64
+	GasLimit      uint64
+	GasUsed       uint64
+	Timestamp     uint64
+	ExtraData     []byte `ssz-max:"32"`
+	BaseFeePerGas uint256.Int
+	BlockHash     [32]byte
+	Transactions  [][]byte     `ssz-max:"1048576,1073741824"`
+	Withdrawals   []Withdrawal `ssz-max:"16"`
+}
+
+type ExecutionPayloadHeaderCapella struct {
+	ParentHash       [32]byte
+	FeeRecipient     [20]byte
+	StateRoot        [32]byte
+	ReceiptsRoot     [32]byte
+	LogsBloom        [256]byte
+	PrevRandao       [32]byte
+	BlockNumber      uint64
+	GasLimit         uint64
+	GasUsed          uint64
+	Timestamp        uint64
+	ExtraData        []byte `ssz-max:"32"`
+	BaseFeePerGas    uint256.Int
+	BlockHash        [32]byte
+	TransactionsRoot [32]byte
+	WithdrawalsRoot  [32]byte
+}
+
+type BeaconBlockBodyCapella struct {
+	RandaoReveal          [96]byte
+	Eth1Data              Eth1Data
+	Graffiti              [32]byte
+	ProposerSlashings     []ProposerSlashing    `ssz-max:"16"`
+	AttesterSlashings     []AttesterSlashing    `ssz-max:"2"`
+	Attestations          []Attestation         `ssz-max:"128"`
+	Deposits              []Deposit             `ssz-max:"16"`
+	VoluntaryExits        []SignedVoluntaryExit `ssz-max:"16"`
+	SyncAggregate         SyncAggregate
+	ExecutionPayload      ExecutionPayloadCapella
+	BLSToExecutionChanges []SignedBLSToExecutionChange `ssz-max:"16"`
+}
+
+type BeaconBlockCapella struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte
+	StateRoot     [32]byte
+	Body          BeaconBlockBodyCapella
+}
+
+type SignedBeaconBlockCapella struct {
+	Message   BeaconBlockCapella
+	Signature [96]byte
+}
+
+type BeaconStateCapella struct {
+	GenesisTime                  uint64
+	GenesisValidatorsRoot        [32]byte
+	Slot                         uint64
+	Fork                         Fork
+	LatestBlockHeader            BeaconBlockHeader
+	BlockRoots                   [8192][32]byte
+	StateRoots                   [8192][32]byte
+	HistoricalRoots              [][32]byte `ssz-max:"16777216"`
+	Eth1Data                     Eth1Data
+	Eth1DataVotes                []Eth1Data `ssz-max:"2048"`
+	Eth1DepositIndex             uint64
+	Validators                   []Validator `ssz-max:"1099511627776"`
+	Balances                     []uint64    `ssz-max:"1099511627776"`
+	RandaoMixes                  [65536][32]byte
+	Slashings                    [8192]uint64
+	PreviousEpochParticipation   []uint8 `ssz-max:"1099511627776"`
+	CurrentEpochParticipation    []uint8 `ssz-max:"1099511627776"`
+	JustificationBits            []byte  `ssz:"bitvector" ssz-size:"4"`
+	PreviousJustifiedCheckpoint  Checkpoint
+	CurrentJustifiedCheckpoint   Checkpoint
+	FinalizedCheckpoint          Checkpoint
+	InactivityScores             []uint64 `ssz-max:"1099511627776"`
+	CurrentSyncCommittee         SyncCommittee
+	NextSyncCommittee            SyncCommittee
+	LatestExecutionPayloadHeader ExecutionPayloadHeaderCapella
+	NextWithdrawalIndex          uint64
+	NextWithdrawalValidatorIndex uint64
+	HistoricalSummaries          []HistoricalSummary `ssz-max:"16777216"`
+}
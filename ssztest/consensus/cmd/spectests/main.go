@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gfx-labs/ssz/ssztest/consensus"
+)
+
+func main() {
+	var (
+		dir     = flag.String("dir", "", "Extracted consensus-spec-tests directory, downloaded into when -version is set")
+		version = flag.String("version", "", "consensus-spec-tests release to download, e.g. v1.5.0")
+		forks   = flag.String("fork", "", "Comma separated forks to run, all by default")
+		verbose = flag.Bool("v", false, "Also print passing and skipped containers")
+	)
+	flag.Parse()
+
+	if *dir == "" {
+		fmt.Fprintf(os.Stderr, "Usage: spectests -dir consensus-spec-tests [-version v1.5.0] [-fork deneb,electra] [-v]\n")
+		os.Exit(1)
+	}
+	if *version != "" {
+		if _, err := consensus.Download(context.Background(), *version, *dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to download tests: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	selected := consensus.Forks
+	if *forks != "" {
+		selected = strings.Split(*forks, ",")
+	}
+
+	var passed, failed, skipped int
+	for _, fork := range selected {
+		results, err := consensus.Check(*dir, fork)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to run %s: %v\n", fork, err)
+			os.Exit(1)
+		}
+		for _, result := range results {
+			switch {
+			case result.Skipped:
+				skipped++
+			case result.Failed > 0:
+				failed++
+			default:
+				passed++
+			}
+			if *verbose || result.Failed > 0 {
+				fmt.Println(result)
+			}
+		}
+	}
+
+	fmt.Printf("%d containers passed, %d failed, %d skipped\n", passed, failed, skipped)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
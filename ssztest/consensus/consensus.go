@@ -0,0 +1,270 @@
+// Package consensus runs the ssz_static suites of the ethereum/consensus-spec-tests against the
+// consensus containers of every fork from phase0 to electra, decoded, encoded and hashed
+// through flexssz.
+package consensus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gfx-labs/ssz/ssztest"
+)
+
+// Preset is the consensus-specs preset the containers of this package are sized for
+const Preset = "mainnet"
+
+// Forks lists the forks with containers in this package, oldest first
+var Forks = []string{"phase0", "altair", "bellatrix", "capella", "deneb", "electra"}
+
+var forkTypes = map[string]map[string]reflect.Type{}
+
+func init() {
+	phase0 := map[string]reflect.Type{
+		"Fork":                    reflect.TypeFor[Fork](),
+		"ForkData":                reflect.TypeFor[ForkData](),
+		"Checkpoint":              reflect.TypeFor[Checkpoint](),
+		"Validator":               reflect.TypeFor[Validator](),
+		"AttestationData":         reflect.TypeFor[AttestationData](),
+		"IndexedAttestation":      reflect.TypeFor[IndexedAttestation](),
+		"PendingAttestation":      reflect.TypeFor[PendingAttestation](),
+		"Eth1Data":                reflect.TypeFor[Eth1Data](),
+		"HistoricalBatch":         reflect.TypeFor[HistoricalBatch](),
+		"DepositMessage":          reflect.TypeFor[DepositMessage](),
+		"DepositData":             reflect.TypeFor[DepositData](),
+		"BeaconBlockHeader":       reflect.TypeFor[BeaconBlockHeader](),
+		"SigningData":             reflect.TypeFor[SigningData](),
+		"Eth1Block":               reflect.TypeFor[Eth1Block](),
+		"ProposerSlashing":        reflect.TypeFor[ProposerSlashing](),
+		"AttesterSlashing":        reflect.TypeFor[AttesterSlashing](),
+		"Attestation":             reflect.TypeFor[Attestation](),
+		"Deposit":                 reflect.TypeFor[Deposit](),
+		"VoluntaryExit":           reflect.TypeFor[VoluntaryExit](),
+		"SignedVoluntaryExit":     reflect.TypeFor[SignedVoluntaryExit](),
+		"SignedBeaconBlockHeader": reflect.TypeFor[SignedBeaconBlockHeader](),
+		"AggregateAndProof":       reflect.TypeFor[AggregateAndProof](),
+		"SignedAggregateAndProof": reflect.TypeFor[SignedAggregateAndProof](),
+		"BeaconBlockBody":         reflect.TypeFor[BeaconBlockBody](),
+		"BeaconBlock":             reflect.TypeFor[BeaconBlock](),
+		"SignedBeaconBlock":       reflect.TypeFor[SignedBeaconBlock](),
+		"BeaconState":             reflect.TypeFor[BeaconState](),
+	}
+	altair := extend(phase0, map[string]reflect.Type{
+		"SyncAggregate":               reflect.TypeFor[SyncAggregate](),
+		"SyncCommittee":               reflect.TypeFor[SyncCommittee](),
+		"SyncCommitteeMessage":        reflect.TypeFor[SyncCommitteeMessage](),
+		"SyncCommitteeContribution":   reflect.TypeFor[SyncCommitteeContribution](),
+		"ContributionAndProof":        reflect.TypeFor[ContributionAndProof](),
+		"SignedContributionAndProof":  reflect.TypeFor[SignedContributionAndProof](),
+		"SyncAggregatorSelectionData": reflect.TypeFor[SyncAggregatorSelectionData](),
+		"BeaconBlockBody":             reflect.TypeFor[BeaconBlockBodyAltair](),
+		"BeaconBlock":                 reflect.TypeFor[BeaconBlockAltair](),
+		"SignedBeaconBlock":           reflect.TypeFor[SignedBeaconBlockAltair](),
+		"BeaconState":                 reflect.TypeFor[BeaconStateAltair](),
+	})
+	bellatrix := extend(altair, map[string]reflect.Type{
+		"ExecutionPayload":       reflect.TypeFor[ExecutionPayload](),
+		"ExecutionPayloadHeader": reflect.TypeFor[ExecutionPayloadHeader](),
+		"PowBlock":               reflect.TypeFor[PowBlock](),
+		"BeaconBlockBody":        reflect.TypeFor[BeaconBlockBodyBellatrix](),
+		"BeaconBlock":            reflect.TypeFor[BeaconBlockBellatrix](),
+		"SignedBeaconBlock":      reflect.TypeFor[SignedBeaconBlockBellatrix](),
+		"BeaconState":            reflect.TypeFor[BeaconStateBellatrix](),
+	})
+	capella := extend(bellatrix, map[string]reflect.Type{
+		"Withdrawal":                 reflect.TypeFor[Withdrawal](),
+		"BLSToExecutionChange":       reflect.TypeFor[BLSToExecutionChange](),
+		"SignedBLSToExecutionChange": reflect.TypeFor[SignedBLSToExecutionChange](),
+		"HistoricalSummary":          reflect.TypeFor[HistoricalSummary](),
+		"ExecutionPayload":           reflect.TypeFor[ExecutionPayloadCapella](),
+		"ExecutionPayloadHeader":     reflect.TypeFor[ExecutionPayloadHeaderCapella](),
+		"BeaconBlockBody":            reflect.TypeFor[BeaconBlockBodyCapella](),
+		"BeaconBlock":                reflect.TypeFor[BeaconBlockCapella](),
+		"SignedBeaconBlock":          reflect.TypeFor[SignedBeaconBlockCapella](),
+		"BeaconState":                reflect.TypeFor[BeaconStateCapella](),
+	})
+	deneb := extend(capella, map[string]reflect.Type{
+		"BlobIdentifier":         reflect.TypeFor[BlobIdentifier](),
+		"BlobSidecar":            reflect.TypeFor[BlobSidecar](),
+		"ExecutionPayload":       reflect.TypeFor[ExecutionPayloadDeneb](),
+		"ExecutionPayloadHeader": reflect.TypeFor[ExecutionPayloadHeaderDeneb](),
+		"BeaconBlockBody":        reflect.TypeFor[BeaconBlockBodyDeneb](),
+		"BeaconBlock":            reflect.TypeFor[BeaconBlockDeneb](),
+		"SignedBeaconBlock":      reflect.TypeFor[SignedBeaconBlockDeneb](),
+		"BeaconState":            reflect.TypeFor[BeaconStateDeneb](),
+	})
+	electra := extend(deneb, map[string]reflect.Type{
+		"Attestation":              reflect.TypeFor[AttestationElectra](),
+		"IndexedAttestation":       reflect.TypeFor[IndexedAttestationElectra](),
+		"AttesterSlashing":         reflect.TypeFor[AttesterSlashingElectra](),
+		"AggregateAndProof":        reflect.TypeFor[AggregateAndProofElectra](),
+		"SignedAggregateAndProof":  reflect.TypeFor[SignedAggregateAndProofElectra](),
+		"SingleAttestation":        reflect.TypeFor[SingleAttestation](),
+		"DepositRequest":           reflect.TypeFor[DepositRequest](),
+		"WithdrawalRequest":        reflect.TypeFor[WithdrawalRequest](),
+		"ConsolidationRequest":     reflect.TypeFor[ConsolidationRequest](),
+		"ExecutionRequests":        reflect.TypeFor[ExecutionRequests](),
+		"PendingDeposit":           reflect.TypeFor[PendingDeposit](),
+		"PendingPartialWithdrawal": reflect.TypeFor[PendingPartialWithdrawal](),
+		"PendingConsolidation":     reflect.TypeFor[PendingConsolidation](),
+		"BeaconBlockBody":          reflect.TypeFor[BeaconBlockBodyElectra](),
+		"BeaconBlock":              reflect.TypeFor[BeaconBlockElectra](),
+		"SignedBeaconBlock":        reflect.TypeFor[SignedBeaconBlockElectra](),
+		"BeaconState":              reflect.TypeFor[BeaconStateElectra](),
+	})
+
+	forkTypes["phase0"] = phase0
+	forkTypes["altair"] = altair
+	forkTypes["bellatrix"] = bellatrix
+	forkTypes["capella"] = capella
+	forkTypes["deneb"] = deneb
+	forkTypes["electra"] = electra
+}
+
+// extend returns the containers of base with the changes of a later fork applied
+func extend(base, changes map[string]reflect.Type) map[string]reflect.Type {
+	out := make(map[string]reflect.Type, len(base)+len(changes))
+	for name, typ := range base {
+		out[name] = typ
+	}
+	for name, typ := range changes {
+		out[name] = typ
+	}
+	return out
+}
+
+// Types returns the containers of fork by their consensus-specs name, including the ones
+// carried over unchanged from earlier forks. It returns nil for an unknown fork.
+func Types(fork string) map[string]reflect.Type {
+	types, ok := forkTypes[fork]
+	if !ok {
+		return nil
+	}
+	return extend(types, nil)
+}
+
+// Dir returns the ssz_static directory of fork under root, the top of an extracted
+// consensus-spec-tests archive
+func Dir(root, fork string) string {
+	return filepath.Join(root, "tests", Preset, fork, "ssz_static")
+}
+
+// Result is the outcome of the cases of one container
+type Result struct {
+	Fork      string
+	Container string
+	Cases     int
+	Failed    int
+	// Skipped is set for containers without a type in this package, e.g. the light client ones
+	Skipped bool
+	// Err is the first failure
+	Err error
+}
+
+func (r Result) String() string {
+	switch {
+	case r.Skipped:
+		return fmt.Sprintf("%s/%s: skipped", r.Fork, r.Container)
+	case r.Failed > 0:
+		return fmt.Sprintf("%s/%s: FAIL %d/%d cases: %v", r.Fork, r.Container, r.Failed, r.Cases, r.Err)
+	default:
+		return fmt.Sprintf("%s/%s: ok %d cases", r.Fork, r.Container, r.Cases)
+	}
+}
+
+// Check runs every ssz_static case of fork under root and returns a result per container,
+// sorted by container name
+func Check(root, fork string) ([]Result, error) {
+	types := Types(fork)
+	if types == nil {
+		return nil, fmt.Errorf("consensus: unknown fork %q", fork)
+	}
+	dir := Dir(root, fork)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		result := Result{Fork: fork, Container: entry.Name()}
+		typ, ok := types[entry.Name()]
+		if !ok {
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+		cases, err := findCases(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cases {
+			result.Cases++
+			if err := ssztest.CheckCase(c, typ); err != nil {
+				result.Failed++
+				if result.Err == nil {
+					result.Err = fmt.Errorf("%s: %w", filepath.Base(c), err)
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Container < results[j].Container })
+	return results, nil
+}
+
+// Run runs the ssz_static cases of forks under root with a subtest per fork and container.
+// Without forks it runs all of Forks. Containers without a type in this package are skipped.
+func Run(t *testing.T, root string, forks ...string) {
+	t.Helper()
+	if len(forks) == 0 {
+		forks = Forks
+	}
+	for _, fork := range forks {
+		t.Run(fork, func(t *testing.T) {
+			types := Types(fork)
+			if types == nil {
+				t.Fatalf("consensus: unknown fork %q", fork)
+			}
+			dir := Dir(root, fork)
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					continue
+				}
+				t.Run(entry.Name(), func(t *testing.T) {
+					typ, ok := types[entry.Name()]
+					if !ok {
+						t.Skipf("no %s container for %s", entry.Name(), fork)
+					}
+					ssztest.RunCases(t, filepath.Join(dir, entry.Name()), typ)
+				})
+			}
+		})
+	}
+}
+
+// findCases returns the case directories under dir, any directory holding a serialized.ssz_snappy
+func findCases(dir string) ([]string, error) {
+	var cases []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == ssztest.SerializedFile {
+			cases = append(cases, filepath.Dir(path))
+		}
+		return nil
+	})
+	sort.Strings(cases)
+	return cases, err
+}
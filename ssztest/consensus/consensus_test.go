@@ -0,0 +1,205 @@
+package consensus
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/gfx-labs/ssz/ssztest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample returns a valid value of typ: the zero value with bitvectors sized and bitlists
+// holding their length bit
+func sample(typ reflect.Type) any {
+	v := reflect.New(typ)
+	fillBits(v.Elem())
+	return v.Interface()
+}
+
+func fillBits(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			switch field.Tag.Get("ssz") {
+			case "bitvector":
+				bits, _ := strconv.Atoi(field.Tag.Get("ssz-size"))
+				v.Field(i).SetBytes(make([]byte, (bits+7)/8))
+			case "bitlist":
+				v.Field(i).SetBytes([]byte{1})
+			default:
+				fillBits(v.Field(i))
+			}
+		}
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			for i := 0; i < v.Len(); i++ {
+				fillBits(v.Index(i))
+			}
+		}
+	}
+}
+
+// writeSuite writes a case of every container of fork in the consensus-spec-tests layout
+func writeSuite(t *testing.T, root, fork string) {
+	t.Helper()
+	for name, typ := range Types(fork) {
+		value := sample(typ)
+		serialized, err := flexssz.Marshal(value)
+		require.NoError(t, err, name)
+		hashRoot, err := flexssz.HashTreeRoot(value)
+		require.NoError(t, err, name)
+		valueJSON, err := flexssz.MarshalJSON(value)
+		require.NoError(t, err, name)
+
+		dir := filepath.Join(Dir(root, fork), name, "ssz_random")
+		require.NoError(t, ssztest.WriteVector(dir, ssztest.Vector{Name: "case_0", Serialized: serialized, Root: hashRoot}))
+		// JSON is valid YAML
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "case_0", ssztest.ValueFile), valueJSON, 0o644))
+	}
+}
+
+func TestTypes(t *testing.T) {
+	for _, fork := range Forks {
+		types := Types(fork)
+		require.NotEmpty(t, types, fork)
+		var values []any
+		for _, typ := range types {
+			values = append(values, reflect.New(typ).Interface())
+		}
+		assert.NoError(t, flexssz.PrecacheAll(values...), fork)
+	}
+	assert.Nil(t, Types("frontier"))
+
+	// Containers changed by a fork replace the ones of earlier forks
+	assert.Equal(t, reflect.TypeFor[BeaconState](), Types("phase0")["BeaconState"])
+	assert.Equal(t, reflect.TypeFor[BeaconStateElectra](), Types("electra")["BeaconState"])
+	assert.Equal(t, reflect.TypeFor[Validator](), Types("electra")["Validator"])
+}
+
+func TestCheck(t *testing.T) {
+	root := t.TempDir()
+	for _, fork := range Forks {
+		writeSuite(t, root, fork)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(Dir(root, "deneb"), "LightClientHeader", "ssz_random"), 0o755))
+
+	for _, fork := range Forks {
+		results, err := Check(root, fork)
+		require.NoError(t, err)
+		require.Len(t, results, len(Types(fork))+boolInt(fork == "deneb"))
+		assert.True(t, sort.SliceIsSorted(results, func(i, j int) bool { return results[i].Container < results[j].Container }))
+		for _, result := range results {
+			if result.Container == "LightClientHeader" {
+				assert.True(t, result.Skipped)
+				continue
+			}
+			assert.Equal(t, 1, result.Cases, result.String())
+			assert.Zero(t, result.Failed, result.String())
+		}
+	}
+
+	t.Run("root mismatch", func(t *testing.T) {
+		dir := filepath.Join(Dir(root, "phase0"), "Checkpoint", "ssz_random")
+		require.NoError(t, ssztest.WriteVector(dir, ssztest.Vector{Name: "case_0", Serialized: make([]byte, 40), Root: [32]byte{1}}))
+		results, err := Check(root, "phase0")
+		require.NoError(t, err)
+		for _, result := range results {
+			if result.Container == "Checkpoint" {
+				assert.Equal(t, 1, result.Failed)
+				assert.ErrorContains(t, result.Err, "case_0")
+				assert.Contains(t, result.String(), "FAIL")
+			}
+		}
+	})
+
+	t.Run("unknown fork", func(t *testing.T) {
+		_, err := Check(root, "frontier")
+		assert.ErrorContains(t, err, "unknown fork")
+	})
+
+	t.Run("run", func(t *testing.T) {
+		writeSuite(t, root, "phase0")
+		Run(t, root)
+	})
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// testArchive returns a gzipped tar archive of files
+func testArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	archive := testArchive(t, map[string]string{
+		"tests/mainnet/deneb/ssz_static/Checkpoint/ssz_random/case_0/roots.yaml": "root: '0x00'\n",
+		"tests/mainnet/deneb/operations/deposit/pyspec_tests/case_0/pre.ssz":     "skipped",
+	})
+
+	dir := t.TempDir()
+	require.NoError(t, Extract(bytes.NewReader(archive), dir))
+	data, err := os.ReadFile(filepath.Join(Dir(dir, "deneb"), "Checkpoint", "ssz_random", "case_0", "roots.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "root: '0x00'\n", string(data))
+	assert.NoDirExists(t, filepath.Join(dir, "tests", "mainnet", "deneb", "operations"))
+	entries, err := os.ReadDir(filepath.Join(dir, "tests"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "mainnet", entries[0].Name())
+
+	// Extracting again replaces the tests
+	require.NoError(t, Extract(bytes.NewReader(archive), dir))
+	assert.DirExists(t, filepath.Join(Dir(dir, "deneb"), "Checkpoint"))
+}
+
+func TestExtract_Interrupted(t *testing.T) {
+	archive := testArchive(t, map[string]string{
+		"tests/mainnet/deneb/ssz_static/Checkpoint/ssz_random/case_0/roots.yaml": "root: '0x00'\n",
+		"tests/mainnet/deneb/ssz_static/Fork/ssz_random/case_0/roots.yaml":       "root: '0x01'\n",
+	})
+
+	// A truncated archive leaves nothing that Download would take as extracted
+	dir := t.TempDir()
+	require.Error(t, Extract(bytes.NewReader(archive[:len(archive)-40]), dir))
+	entries, err := os.ReadDir(filepath.Join(dir, "tests"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestSpecTests runs the official vectors when CONSENSUS_SPEC_TESTS points at an extracted
+// consensus-spec-tests release
+func TestSpecTests(t *testing.T) {
+	root := os.Getenv("CONSENSUS_SPEC_TESTS")
+	if root == "" {
+		t.Skip("CONSENSUS_SPEC_TESTS not set")
+	}
+	Run(t, root)
+}
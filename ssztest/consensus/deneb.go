@@ -0,0 +1,118 @@
+package consensus
+
+import "github.com/holiman/uint256"
+
+// Containers added or changed in deneb
+
+type BlobIdentifier struct {
+	BlockRoot [32]byte
+	Index     uint64
+}
+
+type BlobSidecar struct {
+	Index                       uint64
+	Blob                        [131072]byte
+	KZGCommitment               [48]byte
+	KZGProof                    [48]byte
+	SignedBlockHeader           SignedBeaconBlockHeader
+	KZGCommitmentInclusionProof [17][32]byte
+}
+
+type ExecutionPayloadDeneb struct {
+	ParentHash    [32]byte
+	FeeRecipient  [20]byte
+	StateRoot     [32]byte
+	ReceiptsRoot  [32]byte
+	LogsBloom     [256]byte
+	PrevRandao    [32]byte
+	BlockNumber   uint64
+	GasLimit      uint64
+	GasUsed       uint64
+	Timestamp     uint64
+	ExtraData     []byte `ssz-max:"32"`
+	BaseFeePerGas uint256.Int
+	BlockHash     [32]byte
+	Transactions  [][]byte     `ssz-max:"1048576,1073741824"`
+	Withdrawals   []Withdrawal `ssz-max:"16"`
+	BlobGasUsed   uint64
+	ExcessBlobGas uint64
+}
+
+type ExecutionPayloadHeaderDeneb struct {
+	ParentHash       [32]byte
+	FeeRecipient     [20]byte
+	StateRoot        [32]byte
+	ReceiptsRoot     [32]byte
+	LogsBloom        [256]byte
+	PrevRandao       [32]byte
+	BlockNumber      uint64
+	GasLimit         uint64
+	GasUsed          uint64
+	Timestamp        uint64
+	ExtraData        []byte `ssz-max:"32"`
+	BaseFeePerGas    uint256.Int
+	BlockHash        [32]byte
+	TransactionsRoot [32]byte
+	WithdrawalsRoot  [32]byte
+	BlobGasUsed      uint64
+	ExcessBlobGas    uint64
+}
+
+type BeaconBlockBodyDeneb struct {
+	RandaoReveal          [96]byte
+	Eth1Data              Eth1Data
+	Graffiti              [32]byte
+	ProposerSlashings     []ProposerSlashing    `ssz-max:"16"`
+	AttesterSlashings     []AttesterSlashing    `ssz-max:"2"`
+	Attestations          []Attestation         `ssz-max:"128"`
+	Deposits              []Deposit             `ssz-max:"16"`
+	VoluntaryExits        []SignedVoluntaryExit `ssz-max:"16"`
+	SyncAggregate         SyncAggregate
+	ExecutionPayload      ExecutionPayloadDeneb
+	BLSToExecutionChanges []SignedBLSToExecutionChange `ssz-max:"16"`
+	BlobKZGCommitments    [][48]byte                   `ssz-max:"4096"`
+}
+
+type BeaconBlockDeneb struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte
+	StateRoot     [32]byte
+	Body          BeaconBlockBodyDeneb
+}
+
+type SignedBeaconBlockDeneb struct {
+	Message   BeaconBlockDeneb
+	Signature [96]byte
+}
+
+type BeaconStateDeneb struct {
+	GenesisTime                  uint64
+	GenesisValidatorsRoot        [32]byte
+	Slot                         uint64
+	Fork                         Fork
+	LatestBlockHeader            BeaconBlockHeader
+	BlockRoots                   [8192][32]byte
+	StateRoots                   [8192][32]byte
+	HistoricalRoots              [][32]byte `ssz-max:"16777216"`
+	Eth1Data                     Eth1Data
+	Eth1DataVotes                []Eth1Data `ssz-max:"2048"`
+	Eth1DepositIndex             uint64
+	Validators                   []Validator `ssz-max:"1099511627776"`
+	Balances                     []uint64    `ssz-max:"1099511627776"`
+	RandaoMixes                  [65536][32]byte
+	Slashings                    [8192]uint64
+	PreviousEpochParticipation   []uint8 `ssz-max:"1099511627776"`
+	CurrentEpochParticipation    []uint8 `ssz-max:"1099511627776"`
+	JustificationBits            []byte  `ssz:"bitvector" ssz-size:"4"`
+	PreviousJustifiedCheckpoint  Checkpoint
+	CurrentJustifiedCheckpoint   Checkpoint
+	FinalizedCheckpoint          Checkpoint
+	InactivityScores             []uint64 `ssz-max:"1099511627776"`
+	CurrentSyncCommittee         SyncCommittee
+	NextSyncCommittee            SyncCommittee
+	LatestExecutionPayloadHeader ExecutionPayloadHeaderDeneb
+	NextWithdrawalIndex          uint64
+	NextWithdrawalValidatorIndex uint64
+	HistoricalSummaries          []HistoricalSummary `ssz-max:"16777216"`
+}
@@ -0,0 +1,126 @@
+package consensus
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ReleaseURL is the download location of a consensus-spec-tests release archive, formatted
+// with the release version and the preset
+const ReleaseURL = "https://github.com/ethereum/consensus-spec-tests/releases/download/%s/%s.tar.gz"
+
+// Download fetches the consensus-spec-tests release version, e.g. v1.5.0, and extracts its
+// ssz_static suites into dir, which can then be passed to Check or Run. Nothing is fetched
+// when dir already holds the tests of the preset.
+func Download(ctx context.Context, version, dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "tests", Preset)); err == nil {
+		return dir, nil
+	}
+
+	url := fmt.Sprintf(ReleaseURL, version, Preset)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("consensus: download %s: %s", url, resp.Status)
+	}
+	if err := Extract(resp.Body, dir); err != nil {
+		return "", fmt.Errorf("consensus: extract %s: %w", url, err)
+	}
+	return dir, nil
+}
+
+// Extract reads a gzipped consensus-spec-tests archive from r and writes its ssz_static cases
+// into dir. The rest of the archive is skipped. The cases are written to a temporary directory
+// and moved into place once the whole archive is read, so an interrupted extraction leaves no
+// partial tree for Download to take as complete.
+func Extract(r io.Reader, dir string) error {
+	// The temporary directory is on the same filesystem as the tests, so they can be renamed
+	tests := filepath.Join(dir, "tests")
+	if err := os.MkdirAll(tests, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(tests, ".extract-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extract(r, tmp); err != nil {
+		return err
+	}
+	extracted := filepath.Join(tmp, "tests", Preset)
+	if _, err := os.Stat(extracted); err != nil {
+		return fmt.Errorf("archive has no ssz_static cases for preset %s", Preset)
+	}
+	target := filepath.Join(tests, Preset)
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	return os.Rename(extracted, target)
+}
+
+// extract writes the ssz_static cases of a gzipped archive into dir
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if !isSSZStatic(name) {
+			continue
+		}
+		if strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return fmt.Errorf("invalid path %q", hdr.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+}
+
+// isSSZStatic reports whether an archive path is under tests/<preset>/<fork>/ssz_static
+func isSSZStatic(name string) bool {
+	parts := strings.Split(name, "/")
+	return len(parts) > 4 && parts[0] == "tests" && parts[1] == Preset && parts[3] == "ssz_static"
+}
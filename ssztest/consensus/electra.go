@@ -0,0 +1,153 @@
+package consensus
+
+// Containers added or changed in electra
+
+type AttestationElectra struct {
+	AggregationBits []byte `ssz:"bitlist" ssz-max:"131072"`
+	Data            AttestationData
+	Signature       [96]byte
+	CommitteeBits   []byte `ssz:"bitvector" ssz-size:"64"`
+}
+
+type IndexedAttestationElectra struct {
+	AttestingIndices []uint64 `ssz-max:"131072"`
+	Data             AttestationData
+	Signature        [96]byte
+}
+
+type AttesterSlashingElectra struct {
+	Attestation1 IndexedAttestationElectra `json:"attestation_1"`
+	Attestation2 IndexedAttestationElectra `json:"attestation_2"`
+}
+
+type AggregateAndProofElectra struct {
+	AggregatorIndex uint64
+	Aggregate       AttestationElectra
+	SelectionProof  [96]byte
+}
+
+type SignedAggregateAndProofElectra struct {
+	Message   AggregateAndProofElectra
+	Signature [96]byte
+}
+
+type SingleAttestation struct {
+	CommitteeIndex uint64
+	AttesterIndex  uint64
+	Data           AttestationData
+	Signature      [96]byte
+}
+
+type DepositRequest struct {
+	Pubkey                [48]byte
+	WithdrawalCredentials [32]byte
+	Amount                uint64
+	Signature             [96]byte
+	Index                 uint64
+}
+
+type WithdrawalRequest struct {
+	SourceAddress   [20]byte
+	ValidatorPubkey [48]byte
+	Amount          uint64
+}
+
+type ConsolidationRequest struct {
+	SourceAddress [20]byte
+	SourcePubkey  [48]byte
+	TargetPubkey  [48]byte
+}
+
+type ExecutionRequests struct {
+	Deposits       []DepositRequest       `ssz-max:"8192"`
+	Withdrawals    []WithdrawalRequest    `ssz-max:"16"`
+	Consolidations []ConsolidationRequest `ssz-max:"2"`
+}
+
+type PendingDeposit struct {
+	Pubkey                [48]byte
+	WithdrawalCredentials [32]byte
+	Amount                uint64
+	Signature             [96]byte
+	Slot                  uint64
+}
+
+type PendingPartialWithdrawal struct {
+	ValidatorIndex    uint64
+	Amount            uint64
+	WithdrawableEpoch uint64
+}
+
+type PendingConsolidation struct {
+	SourceIndex uint64
+	TargetIndex uint64
+}
+
+type BeaconBlockBodyElectra struct {
+	RandaoReveal          [96]byte
+	Eth1Data              Eth1Data
+	Graffiti              [32]byte
+	ProposerSlashings     []ProposerSlashing        `ssz-max:"16"`
+	AttesterSlashings     []AttesterSlashingElectra `ssz-max:"1"`
+	Attestations          []AttestationElectra      `ssz-max:"8"`
+	Deposits              []Deposit                 `ssz-max:"16"`
+	VoluntaryExits        []SignedVoluntaryExit     `ssz-max:"16"`
+	SyncAggregate         SyncAggregate
+	ExecutionPayload      ExecutionPayloadDeneb
+	BLSToExecutionChanges []SignedBLSToExecutionChange `ssz-max:"16"`
+	BlobKZGCommitments    [][48]byte                   `ssz-max:"4096"`
+	ExecutionRequests     ExecutionRequests
+}
+
+type BeaconBlockElectra struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte
+	StateRoot     [32]byte
+	Body          BeaconBlockBodyElectra
+}
+
+type SignedBeaconBlockElectra struct {
+	Message   BeaconBlockElectra
+	Signature [96]byte
+}
+
+type BeaconStateElectra struct {
+	GenesisTime                   uint64
+	GenesisValidatorsRoot         [32]byte
+	Slot                          uint64
+	Fork                          Fork
+	LatestBlockHeader             BeaconBlockHeader
+	BlockRoots                    [8192][32]byte
+	StateRoots                    [8192][32]byte
+	HistoricalRoots               [][32]byte `ssz-max:"16777216"`
+	Eth1Data                      Eth1Data
+	Eth1DataVotes                 []Eth1Data `ssz-max:"2048"`
+	Eth1DepositIndex              uint64
+	Validators                    []Validator `ssz-max:"1099511627776"`
+	Balances                      []uint64    `ssz-max:"1099511627776"`
+	RandaoMixes                   [65536][32]byte
+	Slashings                     [8192]uint64
+	PreviousEpochParticipation    []uint8 `ssz-max:"1099511627776"`
+	CurrentEpochParticipation     []uint8 `ssz-max:"1099511627776"`
+	JustificationBits             []byte  `ssz:"bitvector" ssz-size:"4"`
+	PreviousJustifiedCheckpoint   Checkpoint
+	CurrentJustifiedCheckpoint    Checkpoint
+	FinalizedCheckpoint           Checkpoint
+	InactivityScores              []uint64 `ssz-max:"1099511627776"`
+	CurrentSyncCommittee          SyncCommittee
+	NextSyncCommittee             SyncCommittee
+	LatestExecutionPayloadHeader  ExecutionPayloadHeaderDeneb
+	NextWithdrawalIndex           uint64
+	NextWithdrawalValidatorIndex  uint64
+	HistoricalSummaries           []HistoricalSummary `ssz-max:"16777216"`
+	DepositRequestsStartIndex     uint64
+	DepositBalanceToConsume       uint64
+	ExitBalanceToConsume          uint64
+	EarliestExitEpoch             uint64
+	ConsolidationBalanceToConsume uint64
+	EarliestConsolidationEpoch    uint64
+	PendingDeposits               []PendingDeposit           `ssz-max:"134217728"`
+	PendingPartialWithdrawals     []PendingPartialWithdrawal `ssz-max:"134217728"`
+	PendingConsolidations         []PendingConsolidation     `ssz-max:"262144"`
+}
@@ -0,0 +1,189 @@
+package consensus
+
+// Containers of phase0, with the sizes of the mainnet preset
+
+type Fork struct {
+	PreviousVersion [4]byte
+	CurrentVersion  [4]byte
+	Epoch           uint64
+}
+
+type ForkData struct {
+	CurrentVersion        [4]byte
+	GenesisValidatorsRoot [32]byte
+}
+
+type Checkpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type Validator struct {
+	Pubkey                     [48]byte
+	WithdrawalCredentials      [32]byte
+	EffectiveBalance           uint64
+	Slashed                    bool
+	ActivationEligibilityEpoch uint64
+	ActivationEpoch            uint64
+	ExitEpoch                  uint64
+	WithdrawableEpoch          uint64
+}
+
+type AttestationData struct {
+	Slot            uint64
+	Index           uint64
+	BeaconBlockRoot [32]byte
+	Source          Checkpoint
+	Target          Checkpoint
+}
+
+type IndexedAttestation struct {
+	AttestingIndices []uint64 `ssz-max:"2048"`
+	Data             AttestationData
+	Signature        [96]byte
+}
+
+type PendingAttestation struct {
+	AggregationBits []byte `ssz:"bitlist" ssz-max:"2048"`
+	Data            AttestationData
+	InclusionDelay  uint64
+	ProposerIndex   uint64
+}
+
+type Eth1Data struct {
+	DepositRoot  [32]byte
+	DepositCount uint64
+	BlockHash    [32]byte
+}
+
+type HistoricalBatch struct {
+	BlockRoots [8192][32]byte
+	StateRoots [8192][32]byte
+}
+
+type DepositMessage struct {
+	Pubkey                [48]byte
+	WithdrawalCredentials [32]byte
+	Amount                uint64
+}
+
+type DepositData struct {
+	Pubkey                [48]byte
+	WithdrawalCredentials [32]byte
+	Amount                uint64
+	Signature             [96]byte
+}
+
+type BeaconBlockHeader struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte
+	StateRoot     [32]byte
+	BodyRoot      [32]byte
+}
+
+type SigningData struct {
+	ObjectRoot [32]byte
+	Domain     [32]byte
+}
+
+type Eth1Block struct {
+	Timestamp    uint64
+	DepositRoot  [32]byte
+	DepositCount uint64
+}
+
+type ProposerSlashing struct {
+	SignedHeader1 SignedBeaconBlockHeader `json:"signed_header_1"`
+	SignedHeader2 SignedBeaconBlockHeader `json:"signed_header_2"`
+}
+
+type AttesterSlashing struct {
+	Attestation1 IndexedAttestation `json:"attestation_1"`
+	Attestation2 IndexedAttestation `json:"attestation_2"`
+}
+
+type Attestation struct {
+	AggregationBits []byte `ssz:"bitlist" ssz-max:"2048"`
+	Data            AttestationData
+	Signature       [96]byte
+}
+
+type Deposit struct {
+	Proof [33][32]byte
+	Data  DepositData
+}
+
+type VoluntaryExit struct {
+	Epoch          uint64
+	ValidatorIndex uint64
+}
+
+type SignedVoluntaryExit struct {
+	Message   VoluntaryExit
+	Signature [96]byte
+}
+
+type SignedBeaconBlockHeader struct {
+	Message   BeaconBlockHeader
+	Signature [96]byte
+}
+
+type AggregateAndProof struct {
+	AggregatorIndex uint64
+	Aggregate       Attestation
+	SelectionProof  [96]byte
+}
+
+type SignedAggregateAndProof struct {
+	Message   AggregateAndProof
+	Signature [96]byte
+}
+
+type BeaconBlockBody struct {
+	RandaoReveal      [96]byte
+	Eth1Data          Eth1Data
+	Graffiti          [32]byte
+	ProposerSlashings []ProposerSlashing    `ssz-max:"16"`
+	AttesterSlashings []AttesterSlashing    `ssz-max:"2"`
+	Attestations      []Attestation         `ssz-max:"128"`
+	Deposits          []Deposit             `ssz-max:"16"`
+	VoluntaryExits    []SignedVoluntaryExit `ssz-max:"16"`
+}
+
+type BeaconBlock struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte
+	StateRoot     [32]byte
+	Body          BeaconBlockBody
+}
+
+type SignedBeaconBlock struct {
+	Message   BeaconBlock
+	Signature [96]byte
+}
+
+type BeaconState struct {
+	GenesisTime                 uint64
+	GenesisValidatorsRoot       [32]byte
+	Slot                        uint64
+	Fork                        Fork
+	LatestBlockHeader           BeaconBlockHeader
+	BlockRoots                  [8192][32]byte
+	StateRoots                  [8192][32]byte
+	HistoricalRoots             [][32]byte `ssz-max:"16777216"`
+	Eth1Data                    Eth1Data
+	Eth1DataVotes               []Eth1Data `ssz-max:"2048"`
+	Eth1DepositIndex            uint64
+	Validators                  []Validator `ssz-max:"1099511627776"`
+	Balances                    []uint64    `ssz-max:"1099511627776"`
+	RandaoMixes                 [65536][32]byte
+	Slashings                   [8192]uint64
+	PreviousEpochAttestations   []PendingAttestation `ssz-max:"4096"`
+	CurrentEpochAttestations    []PendingAttestation `ssz-max:"4096"`
+	JustificationBits           []byte               `ssz:"bitvector" ssz-size:"4"`
+	PreviousJustifiedCheckpoint Checkpoint
+	CurrentJustifiedCheckpoint  Checkpoint
+	FinalizedCheckpoint         Checkpoint
+}
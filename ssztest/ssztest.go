@@ -0,0 +1,226 @@
+// Package ssztest checks user types against known SSZ vectors.
+//
+// Vectors are attached to a type with Register and checked with RunVectors, usually from a test:
+//
+//	func init() {
+//		ssztest.Register[MyContainer](ssztest.Vector{Name: "case_0", Serialized: data, Root: root}).
+//			WithGenerated(func(b []byte) ssztest.Generated { return generated.MyContainer(b) })
+//	}
+//
+//	func TestVectors(t *testing.T) {
+//		ssztest.RunVectors(t)
+//	}
+//
+// Every vector is strictly decoded and re-encoded with flexssz, and its hash tree root compared to the
+// expected root. Types with a genssz view are checked against the same vector as well.
+package ssztest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+)
+
+// Vector is a serialized value and its expected hash tree root
+type Vector struct {
+	Name       string
+	Serialized []byte
+	Root       [32]byte
+}
+
+// Generated is implemented by the byte-backed views generated by genssz
+type Generated interface {
+	MarshalSSZ() ([]byte, error)
+	HashSSZ() ([32]byte, error)
+}
+
+// Entry holds the vectors registered for a type
+type Entry struct {
+	mu        sync.Mutex
+	typ       reflect.Type
+	vectors   []Vector
+	generated func([]byte) Generated
+}
+
+// Registry is a set of types with vectors. Most users only need the default registry used by
+// Register and RunVectors.
+type Registry struct {
+	mu      sync.Mutex
+	entries []*Entry
+	byType  map[reflect.Type]*Entry
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{byType: make(map[reflect.Type]*Entry)}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register attaches vectors to T in the default registry. T is the flexssz struct type,
+// registering the same type again appends to its vectors.
+func Register[T any](vectors ...Vector) *Entry {
+	return RegisterIn[T](defaultRegistry, vectors...)
+}
+
+// RegisterIn attaches vectors to T in r
+func RegisterIn[T any](r *Registry, vectors ...Vector) *Entry {
+	typ := reflect.TypeFor[T]()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	r.mu.Lock()
+	e, ok := r.byType[typ]
+	if !ok {
+		e = &Entry{typ: typ}
+		r.byType[typ] = e
+		r.entries = append(r.entries, e)
+	}
+	r.mu.Unlock()
+
+	return e.Add(vectors...)
+}
+
+// Add appends vectors to the entry
+func (e *Entry) Add(vectors ...Vector) *Entry {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.vectors = append(e.vectors, vectors...)
+	return e
+}
+
+// WithGenerated also checks the vectors against generated code. fn wraps the serialized bytes
+// in the generated view, e.g. func(b []byte) ssztest.Generated { return spectest.Checkpoint(b) }.
+func (e *Entry) WithGenerated(fn func([]byte) Generated) *Entry {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.generated = fn
+	return e
+}
+
+// RunVectors checks every vector in the default registry, with a subtest per type and vector
+func RunVectors(t *testing.T) {
+	t.Helper()
+	defaultRegistry.RunVectors(t)
+}
+
+// RunVectors checks every vector in r, with a subtest per type and vector
+func (r *Registry) RunVectors(t *testing.T) {
+	t.Helper()
+	r.mu.Lock()
+	entries := append([]*Entry(nil), r.entries...)
+	r.mu.Unlock()
+
+	if len(entries) == 0 {
+		t.Log("ssztest: no vectors registered")
+	}
+	for _, e := range entries {
+		e.mu.Lock()
+		vectors := append([]Vector(nil), e.vectors...)
+		generated := e.generated
+		e.mu.Unlock()
+
+		t.Run(e.typ.Name(), func(t *testing.T) {
+			for i, vector := range vectors {
+				name := vector.Name
+				if name == "" {
+					name = fmt.Sprintf("vector_%d", i)
+				}
+				t.Run(name, func(t *testing.T) {
+					if err := CheckVector(e.typ, vector); err != nil {
+						t.Error(err)
+					}
+					if generated != nil {
+						if err := CheckGenerated(generated, vector); err != nil {
+							t.Error(err)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+// CheckVector strictly decodes the vector into a new value of typ with flexssz, and checks that it
+// re-encodes to the same bytes with both Marshal and MarshalAppend, and hashes to the expected root
+func CheckVector(typ reflect.Type, vector Vector) error {
+	value := reflect.New(typ).Interface()
+	if err := flexssz.UnmarshalStrict(vector.Serialized, value); err != nil {
+		return fmt.Errorf("flexssz: unmarshal: %w", err)
+	}
+
+	encoded, err := flexssz.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("flexssz: marshal: %w", err)
+	}
+	if !bytes.Equal(encoded, vector.Serialized) {
+		return fmt.Errorf("flexssz: round trip mismatch: %s", describeMismatch(vector.Serialized, encoded))
+	}
+	size, err := flexssz.EncodedSize(value)
+	if err != nil {
+		return fmt.Errorf("flexssz: encoded size: %w", err)
+	}
+	if size != len(vector.Serialized) {
+		return fmt.Errorf("flexssz: encoded size is %d, expected %d", size, len(vector.Serialized))
+	}
+	appended, err := flexssz.MarshalAppend(nil, value)
+	if err != nil {
+		return fmt.Errorf("flexssz: marshal append: %w", err)
+	}
+	if !bytes.Equal(appended, vector.Serialized) {
+		return fmt.Errorf("flexssz: marshal append mismatch: %s", describeMismatch(vector.Serialized, appended))
+	}
+
+	root, err := flexssz.HashTreeRoot(value)
+	if err != nil {
+		return fmt.Errorf("flexssz: hash tree root: %w", err)
+	}
+	if root != vector.Root {
+		return fmt.Errorf("flexssz: root mismatch: expected 0x%x, got 0x%x", vector.Root, root)
+	}
+	return nil
+}
+
+// CheckGenerated checks the vector against the generated view returned by fn
+func CheckGenerated(fn func([]byte) Generated, vector Vector) error {
+	// The view may keep the slice, so give it its own copy
+	view := fn(bytes.Clone(vector.Serialized))
+
+	encoded, err := view.MarshalSSZ()
+	if err != nil {
+		return fmt.Errorf("generated: marshal: %w", err)
+	}
+	if !bytes.Equal(encoded, vector.Serialized) {
+		return fmt.Errorf("generated: round trip mismatch: %s", describeMismatch(vector.Serialized, encoded))
+	}
+
+	root, err := view.HashSSZ()
+	if err != nil {
+		return fmt.Errorf("generated: hash tree root: %w", err)
+	}
+	if root != vector.Root {
+		return fmt.Errorf("generated: root mismatch: expected 0x%x, got 0x%x", vector.Root, root)
+	}
+	return nil
+}
+
+// describeMismatch reports where two encodings first differ
+func describeMismatch(expected, got []byte) string {
+	if len(expected) != len(got) {
+		return fmt.Sprintf("expected %d bytes, got %d bytes", len(expected), len(got))
+	}
+	for i := range expected {
+		if expected[i] != got[i] {
+			end := min(i+8, len(expected))
+			return fmt.Sprintf("first difference at byte %d: expected %s, got %s",
+				i, hex.EncodeToString(expected[i:end]), hex.EncodeToString(got[i:end]))
+		}
+	}
+	return "encodings are equal"
+}
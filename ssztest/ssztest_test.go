@@ -0,0 +1,103 @@
+package ssztest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/gfx-labs/ssz/examples/spectest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type checkpoint struct {
+	Epoch uint64
+	Root  [32]byte `ssz-size:"32"`
+}
+
+// checkpointVector builds a vector by hand: the serialization is epoch || root and the
+// hash tree root is sha256(epoch chunk || root)
+func checkpointVector(name string, epoch uint64, root [32]byte) Vector {
+	serialized := binary.LittleEndian.AppendUint64(nil, epoch)
+	serialized = append(serialized, root[:]...)
+
+	var epochChunk [32]byte
+	binary.LittleEndian.PutUint64(epochChunk[:], epoch)
+	return Vector{
+		Name:       name,
+		Serialized: serialized,
+		Root:       sha256.Sum256(append(epochChunk[:], root[:]...)),
+	}
+}
+
+func generatedCheckpoint(b []byte) Generated {
+	return spectest.Checkpoint(b)
+}
+
+func TestRegistryRunVectors(t *testing.T) {
+	r := NewRegistry()
+	RegisterIn[checkpoint](r, checkpointVector("zero", 0, [32]byte{})).
+		WithGenerated(generatedCheckpoint)
+	// Registering a pointer type appends to the same entry
+	RegisterIn[*checkpoint](r, checkpointVector("filled", 12345, [32]byte{1, 2, 3}))
+
+	require.Len(t, r.entries, 1)
+	assert.Len(t, r.entries[0].vectors, 2)
+
+	r.RunVectors(t)
+}
+
+func TestCheckVector(t *testing.T) {
+	typ := reflect.TypeFor[checkpoint]()
+	vector := checkpointVector("filled", 7, [32]byte{0xaa})
+	require.NoError(t, CheckVector(typ, vector))
+	require.NoError(t, CheckGenerated(generatedCheckpoint, vector))
+
+	t.Run("wrong root", func(t *testing.T) {
+		bad := vector
+		bad.Root[0] ^= 1
+		assert.ErrorContains(t, CheckVector(typ, bad), "flexssz: root mismatch")
+		assert.ErrorContains(t, CheckGenerated(generatedCheckpoint, bad), "generated: root mismatch")
+	})
+
+	t.Run("short input", func(t *testing.T) {
+		bad := vector
+		bad.Serialized = vector.Serialized[:10]
+		assert.ErrorContains(t, CheckVector(typ, bad), "flexssz: unmarshal")
+	})
+}
+
+func TestWriteLoadVectors(t *testing.T) {
+	dir := t.TempDir()
+	vectors := []Vector{
+		checkpointVector("case_1", 1, [32]byte{1}),
+		checkpointVector("case_0", 0, [32]byte{}),
+	}
+	for _, v := range vectors {
+		require.NoError(t, WriteVector(dir, v))
+	}
+
+	loaded, err := LoadVectors(dir)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	// Sorted by name
+	assert.Equal(t, vectors[1], loaded[0])
+	assert.Equal(t, vectors[0], loaded[1])
+
+	for _, v := range loaded {
+		require.NoError(t, CheckVector(reflect.TypeFor[checkpoint](), v))
+	}
+}
+
+func TestParseRoot(t *testing.T) {
+	root, err := ParseRoot("0x0100000000000000000000000000000000000000000000000000000000000002")
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), root[0])
+	assert.Equal(t, byte(2), root[31])
+
+	_, err = ParseRoot("0x01")
+	assert.Error(t, err)
+	_, err = ParseRoot("0xzz")
+	assert.Error(t, err)
+}
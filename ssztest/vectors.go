@@ -0,0 +1,104 @@
+package ssztest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/snappy"
+	"sigs.k8s.io/yaml"
+)
+
+// Vector files, in the layout of the consensus-specs ssz_static tests
+const (
+	SerializedFile = "serialized.ssz_snappy"
+	RootsFile      = "roots.yaml"
+)
+
+type roots struct {
+	Root string `json:"root"`
+}
+
+// LoadVectors loads the vectors in dir, which holds one directory per case with a
+// snappy-compressed serialized.ssz_snappy and a roots.yaml of the form `root: '0x...'`.
+// Vectors are named after their case directory and sorted by name.
+func LoadVectors(dir string) ([]Vector, error) {
+	cases, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([]Vector, 0, len(cases))
+	for _, c := range cases {
+		if !c.IsDir() {
+			continue
+		}
+		vector, err := LoadVector(filepath.Join(dir, c.Name()))
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vector)
+	}
+	sort.Slice(vectors, func(i, j int) bool { return vectors[i].Name < vectors[j].Name })
+	return vectors, nil
+}
+
+// LoadVector loads a single case directory
+func LoadVector(dir string) (Vector, error) {
+	compressed, err := os.ReadFile(filepath.Join(dir, SerializedFile))
+	if err != nil {
+		return Vector{}, err
+	}
+	serialized, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return Vector{}, fmt.Errorf("%s: %w", filepath.Join(dir, SerializedFile), err)
+	}
+
+	rootsYaml, err := os.ReadFile(filepath.Join(dir, RootsFile))
+	if err != nil {
+		return Vector{}, err
+	}
+	var r roots
+	if err := yaml.Unmarshal(rootsYaml, &r); err != nil {
+		return Vector{}, fmt.Errorf("%s: %w", filepath.Join(dir, RootsFile), err)
+	}
+	root, err := ParseRoot(r.Root)
+	if err != nil {
+		return Vector{}, fmt.Errorf("%s: %w", filepath.Join(dir, RootsFile), err)
+	}
+
+	return Vector{
+		Name:       filepath.Base(dir),
+		Serialized: serialized,
+		Root:       root,
+	}, nil
+}
+
+// WriteVector writes a vector as a case directory under dir, named after the vector
+func WriteVector(dir string, vector Vector) error {
+	caseDir := filepath.Join(dir, vector.Name)
+	if err := os.MkdirAll(caseDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(caseDir, SerializedFile), snappy.Encode(nil, vector.Serialized), 0o644); err != nil {
+		return err
+	}
+	rootsYaml := fmt.Sprintf("root: '0x%s'\n", hex.EncodeToString(vector.Root[:]))
+	return os.WriteFile(filepath.Join(caseDir, RootsFile), []byte(rootsYaml), 0o644)
+}
+
+// ParseRoot parses a 0x-prefixed hex root
+func ParseRoot(s string) ([32]byte, error) {
+	var root [32]byte
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return root, fmt.Errorf("invalid root %q: %w", s, err)
+	}
+	if len(b) != len(root) {
+		return root, fmt.Errorf("invalid root %q: expected 32 bytes, got %d", s, len(b))
+	}
+	copy(root[:], b)
+	return root, nil
+}
@@ -0,0 +1,198 @@
+package ssztest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"gopkg.in/yaml.v3"
+)
+
+// ValueFile is the YAML form of the value in a consensus-specs test case
+const ValueFile = "value.yaml"
+
+// LoadValue reads the value.yaml of a case directory into v, which must be a pointer to an
+// SSZ-tagged struct. Field names and formats follow the consensus specs, see flexssz.UnmarshalJSON.
+func LoadValue(dir string, v any) error {
+	path := filepath.Join(dir, ValueFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	jsonData, err := YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := flexssz.UnmarshalJSON(jsonData, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// CheckCase checks a consensus-specs case directory against typ: the value in value.yaml must
+// serialize to serialized.ssz_snappy and hash to the root in roots.yaml, and the serialized
+// bytes must pass CheckVector.
+func CheckCase(dir string, typ reflect.Type) error {
+	vector, err := LoadVector(dir)
+	if err != nil {
+		return err
+	}
+	if err := CheckVector(typ, vector); err != nil {
+		return err
+	}
+
+	value := reflect.New(typ).Interface()
+	if err := LoadValue(dir, value); err != nil {
+		return err
+	}
+	encoded, err := flexssz.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("yaml: marshal: %w", err)
+	}
+	if !bytes.Equal(encoded, vector.Serialized) {
+		return fmt.Errorf("yaml: serialization mismatch: %s", describeMismatch(vector.Serialized, encoded))
+	}
+	root, err := flexssz.HashTreeRoot(value)
+	if err != nil {
+		return fmt.Errorf("yaml: hash tree root: %w", err)
+	}
+	if root != vector.Root {
+		return fmt.Errorf("yaml: root mismatch: expected 0x%x, got 0x%x", vector.Root, root)
+	}
+	return nil
+}
+
+// RunCases runs CheckCase for every case under dir, with a subtest per case. A case is any
+// directory holding a serialized.ssz_snappy, so dir can be a single type from the consensus
+// specs, e.g. tests/mainnet/deneb/ssz_static/BeaconBlock, or one of its handlers.
+func RunCases(t *testing.T, dir string, typ reflect.Type) {
+	t.Helper()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var cases []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == SerializedFile {
+			cases = append(cases, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("ssztest: no cases found in %s", dir)
+	}
+	sort.Strings(cases)
+
+	for _, c := range cases {
+		name, err := filepath.Rel(dir, c)
+		if err != nil || name == "." {
+			name = filepath.Base(c)
+		}
+		t.Run(filepath.ToSlash(name), func(t *testing.T) {
+			if err := CheckCase(c, typ); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// YAMLToJSON converts a YAML document to JSON. Integers are copied digit for digit, so values
+// such as uint256 keep their full precision.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := yamlNodeToJSON(buf, &doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func yamlNodeToJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return yamlNodeToJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeToJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := yamlNodeToJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, elem := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := yamlNodeToJSON(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		return yamlScalarToJSON(buf, node)
+	default:
+		return fmt.Errorf("line %d: unsupported YAML node", node.Line)
+	}
+	return nil
+}
+
+func yamlScalarToJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!int":
+		// Integers are written as strings, which flexssz.UnmarshalJSON reads as decimal
+		value := strings.ReplaceAll(node.Value, "_", "")
+		if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0o") || strings.HasPrefix(value, "-") {
+			return fmt.Errorf("line %d: unsupported integer %s", node.Line, node.Value)
+		}
+		buf.WriteString(`"` + value + `"`)
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return err
+		}
+		fmt.Fprint(buf, b)
+	case "!!null":
+		buf.WriteString("null")
+	default:
+		s, err := json.Marshal(node.Value)
+		if err != nil {
+			return err
+		}
+		buf.Write(s)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package ssztest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gfx-labs/ssz/flexssz"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCase writes a case directory in the consensus-specs layout
+func writeCase(t *testing.T, dir string, vector Vector, valueYaml string) string {
+	t.Helper()
+	require.NoError(t, WriteVector(dir, vector))
+	caseDir := filepath.Join(dir, vector.Name)
+	require.NoError(t, os.WriteFile(filepath.Join(caseDir, ValueFile), []byte(valueYaml), 0o644))
+	return caseDir
+}
+
+func TestCheckCase(t *testing.T) {
+	dir := t.TempDir()
+	typ := reflect.TypeFor[checkpoint]()
+	caseDir := writeCase(t, dir, checkpointVector("case_0", 12345, [32]byte{0xab}),
+		"{epoch: 12345, root: '0xab00000000000000000000000000000000000000000000000000000000000000'}\n")
+	require.NoError(t, CheckCase(caseDir, typ))
+
+	var value checkpoint
+	require.NoError(t, LoadValue(caseDir, &value))
+	assert.Equal(t, uint64(12345), value.Epoch)
+
+	t.Run("value mismatch", func(t *testing.T) {
+		badDir := writeCase(t, t.TempDir(), checkpointVector("case_0", 12345, [32]byte{0xab}),
+			"{epoch: 1, root: '0xab00000000000000000000000000000000000000000000000000000000000000'}\n")
+		assert.ErrorContains(t, CheckCase(badDir, typ), "yaml: serialization mismatch")
+	})
+
+	t.Run("run cases", func(t *testing.T) {
+		// Cases are found at any depth, like the handler directories of the consensus specs
+		handlerDir := filepath.Join(dir, "ssz_random")
+		writeCase(t, handlerDir, checkpointVector("case_1", 7, [32]byte{1}),
+			"epoch: 7\nroot: '0x0100000000000000000000000000000000000000000000000000000000000000'\n")
+		RunCases(t, dir, reflect.TypeFor[*checkpoint]())
+	})
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	type Payload struct {
+		GasLimit      uint64       `json:"gas_limit"`
+		BaseFeePerGas *uint256.Int `json:"base_fee_per_gas" ssz:"uint256"`
+		Transactions  [][]byte     `json:"transactions" ssz-max:"4,64"`
+		Enabled       bool         `json:"enabled"`
+	}
+	data := []byte(`gas_limit: 18446744073709551615
+base_fee_per_gas: 115792089237316195423570985008687907853269984665640564039457584007913129639935
+transactions: ['0x01', '0x0203']
+enabled: true
+`)
+	jsonData, err := YAMLToJSON(data)
+	require.NoError(t, err)
+
+	var payload Payload
+	require.NoError(t, flexssz.UnmarshalJSON(jsonData, &payload))
+	assert.Equal(t, uint64(18446744073709551615), payload.GasLimit)
+	// The largest uint256 survives without going through a float
+	assert.Equal(t, new(uint256.Int).SetAllOne(), payload.BaseFeePerGas)
+	assert.Equal(t, [][]byte{{1}, {2, 3}}, payload.Transactions)
+	assert.True(t, payload.Enabled)
+
+	_, err = YAMLToJSON([]byte("slot: -1\n"))
+	assert.ErrorContains(t, err, "unsupported integer")
+}
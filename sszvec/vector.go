@@ -0,0 +1,51 @@
+// Package sszvec provides Vector, a slice type that carries its SSZ vector length in its type,
+// so flexssz encodes, decodes and hashes it without an ssz-size tag:
+//
+//	type SlotsPerHistoricalRoot struct{}
+//
+//	func (SlotsPerHistoricalRoot) Size() int { return 8192 }
+//
+//	type BeaconState struct {
+//		BlockRoots sszvec.Vector[[32]byte, SlotsPerHistoricalRoot]
+//	}
+package sszvec
+
+import "fmt"
+
+// Size is implemented by the types naming the length of a Vector. Size is called on the zero
+// value, so size types are usually empty structs.
+type Size interface {
+	Size() int
+}
+
+// Vector is a vector of exactly N elements of type T
+type Vector[T any, N Size] []T
+
+// New returns a vector of N zero elements
+func New[T any, N Size]() Vector[T, N] {
+	var v Vector[T, N]
+	return make(Vector[T, N], v.SSZSize())
+}
+
+// From returns a vector holding xs, or an error if there are not exactly N of them
+func From[T any, N Size](xs ...T) (Vector[T, N], error) {
+	v := Vector[T, N](xs)
+	if err := v.Check(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SSZSize returns the length of the vector, which flexssz uses as its ssz-size
+func (Vector[T, N]) SSZSize() int {
+	var n N
+	return n.Size()
+}
+
+// Check returns an error if the vector does not hold exactly its length of elements
+func (v Vector[T, N]) Check() error {
+	if size := v.SSZSize(); len(v) != size {
+		return fmt.Errorf("vector of %d elements does not match length %d", len(v), size)
+	}
+	return nil
+}
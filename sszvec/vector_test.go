@@ -0,0 +1,26 @@
+package sszvec_test
+
+import (
+	"testing"
+
+	"github.com/gfx-labs/ssz/sszvec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type size4 struct{}
+
+func (size4) Size() int { return 4 }
+
+func TestVector(t *testing.T) {
+	v := sszvec.New[[32]byte, size4]()
+	assert.Len(t, v, 4)
+	assert.NoError(t, v.Check())
+
+	u, err := sszvec.From[uint64, size4](1, 2, 3, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, u.SSZSize())
+	assert.Error(t, u[:3].Check())
+	_, err = sszvec.From[uint64, size4](1, 2, 3)
+	assert.Error(t, err)
+}
@@ -24,6 +24,12 @@ const (
 
 	TypeUnion TypeName = "union"
 
+	// EIP-7495 types. A stable container carries its capacity (maximum number of fields) in Limit,
+	// a profile refers to the stable container it narrows via Ref, and an optional wraps exactly one child.
+	TypeStableContainer TypeName = "stable_container"
+	TypeProfile         TypeName = "profile"
+	TypeOptional        TypeName = "optional"
+
 	// This is a special type that is not an ssz type, but rather a ref to another type in the schema
 	TypeRef TypeName = "ref"
 )
@@ -34,7 +40,7 @@ func (t TypeName) String() string {
 
 func (t TypeName) IsSometimesVariable() bool {
 	switch t {
-	case TypeVector, TypeContainer, TypeUnion, TypeRef:
+	case TypeVector, TypeContainer, TypeUnion, TypeRef, TypeProfile:
 		return true
 	default:
 		return false
@@ -43,7 +49,7 @@ func (t TypeName) IsSometimesVariable() bool {
 
 func (t TypeName) IsAlwaysVariable() bool {
 	switch t {
-	case TypeList, TypeBitList:
+	case TypeList, TypeBitList, TypeStableContainer, TypeOptional:
 		return true
 	default:
 		return false
@@ -83,8 +89,19 @@ func isVariable(f *Field, refs map[string]Field, iterations, maxIterations int)
 	}
 
 	switch f.Type {
-	case TypeList, TypeBitList, TypeUnion:
+	case TypeList, TypeBitList, TypeUnion, TypeStableContainer, TypeOptional:
 		return true, nil
+	case TypeProfile:
+		// A profile is variable if any of its fields is optional or variable
+		for _, child := range f.Children {
+			isVar, err := isVariable(&child, refs, iterations+1, maxIterations)
+			if err != nil {
+				return false, err
+			}
+			if isVar {
+				return true, nil
+			}
+		}
 	case TypeContainer, TypeVector, TypeBitVector:
 		for _, child := range f.Children {
 			isVar, err := isVariable(&child, refs, iterations+1, maxIterations)
@@ -187,6 +204,59 @@ func isValid(f *Field, refs map[string]Field, iterations, maxIterations int) err
 		}
 		return nil
 
+	case TypeStableContainer:
+		// Stable containers must have a capacity and fit their children into it
+		if f.Limit == 0 {
+			return fmt.Errorf("field '%s' of type '%s' must have non-zero limit (capacity)", f.Name, f.Type)
+		}
+		if len(f.Children) == 0 {
+			return fmt.Errorf("field '%s' of type '%s' must have children", f.Name, f.Type)
+		}
+		if uint64(len(f.Children)) > f.Limit {
+			return fmt.Errorf("field '%s' has %d children which exceeds its capacity of %d", f.Name, len(f.Children), f.Limit)
+		}
+		for i, child := range f.Children {
+			if err := isValid(&child, refs, iterations+1, maxIterations); err != nil {
+				return fmt.Errorf("field '%s' child[%d]: %w", f.Name, i, err)
+			}
+		}
+		return nil
+
+	case TypeProfile:
+		// Profiles narrow a stable container, so the base must exist and contain every field
+		if f.Ref == "" {
+			return fmt.Errorf("field '%s' of type 'profile' must reference a stable container", f.Name)
+		}
+		base, err := resolveStableContainer(f.Ref, refs, iterations+1, maxIterations)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", f.Name, err)
+		}
+		if uint64(len(f.Children)) > base.Limit {
+			return fmt.Errorf("field '%s' has %d children which exceeds the capacity %d of '%s'", f.Name, len(f.Children), base.Limit, f.Ref)
+		}
+		for i, child := range f.Children {
+			if !hasChild(base, child.Name) {
+				return fmt.Errorf("field '%s' child[%d]: '%s' is not a field of '%s'", f.Name, i, child.Name, f.Ref)
+			}
+			if err := isValid(&child, refs, iterations+1, maxIterations); err != nil {
+				return fmt.Errorf("field '%s' child[%d]: %w", f.Name, i, err)
+			}
+		}
+		return nil
+
+	case TypeOptional:
+		// Optionals wrap exactly one non-optional type
+		if len(f.Children) != 1 {
+			return fmt.Errorf("field '%s' of type 'optional' must have exactly one child, got %d", f.Name, len(f.Children))
+		}
+		if f.Children[0].Type == TypeOptional {
+			return fmt.Errorf("field '%s' of type 'optional' cannot wrap another optional", f.Name)
+		}
+		if err := isValid(&f.Children[0], refs, iterations+1, maxIterations); err != nil {
+			return fmt.Errorf("field '%s' child[0]: %w", f.Name, err)
+		}
+		return nil
+
 	case TypeRef:
 		// Refs must have a reference
 		if f.Ref == "" {
@@ -204,3 +274,32 @@ func isValid(f *Field, refs map[string]Field, iterations, maxIterations int) err
 		return fmt.Errorf("field '%s' has unknown type '%s'", f.Name, f.Type)
 	}
 }
+
+// resolveStableContainer follows refs until it reaches the stable container named by ref
+func resolveStableContainer(ref string, refs map[string]Field, iterations, maxIterations int) (*Field, error) {
+	for ; iterations < maxIterations; iterations++ {
+		refField, ok := refs[ref]
+		if !ok {
+			return nil, fmt.Errorf("references type '%s' which is not found", ref)
+		}
+		switch refField.Type {
+		case TypeStableContainer:
+			return &refField, nil
+		case TypeRef:
+			ref = refField.Ref
+		default:
+			return nil, fmt.Errorf("references type '%s' which is a '%s', not a stable container", ref, refField.Type)
+		}
+	}
+	return nil, fmt.Errorf("max iterations reached while resolving '%s' - possible circular reference", ref)
+}
+
+// hasChild reports whether f has a direct child with the given name
+func hasChild(f *Field, name string) bool {
+	for _, child := range f.Children {
+		if child.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -537,6 +537,161 @@ func TestIsValid_RefType(t *testing.T) {
 	}
 }
 
+func TestIsValid_StableContainerTypes(t *testing.T) {
+	base := Field{
+		Name:  "Shape",
+		Type:  TypeStableContainer,
+		Limit: 4,
+		Children: []Field{
+			{Name: "side", Type: TypeOptional, Children: []Field{{Name: "value", Type: TypeUint16}}},
+			{Name: "color", Type: TypeOptional, Children: []Field{{Name: "value", Type: TypeUint8}}},
+			{Name: "radius", Type: TypeOptional, Children: []Field{{Name: "value", Type: TypeUint16}}},
+		},
+	}
+	refs := map[string]Field{
+		"Shape":     base,
+		"ShapeRef":  {Name: "ShapeRef", Type: TypeRef, Ref: "Shape"},
+		"NotStable": {Name: "NotStable", Type: TypeContainer, Children: []Field{{Name: "a", Type: TypeUint8}}},
+	}
+
+	tests := []struct {
+		name    string
+		field   Field
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid stable container",
+			field:   base,
+			wantErr: false,
+		},
+		{
+			name: "stable container without capacity",
+			field: Field{
+				Name:     "myStable",
+				Type:     TypeStableContainer,
+				Children: []Field{{Name: "a", Type: TypeUint8}},
+			},
+			wantErr: true,
+			errMsg:  "must have non-zero limit",
+		},
+		{
+			name: "stable container without children",
+			field: Field{
+				Name:  "myStable",
+				Type:  TypeStableContainer,
+				Limit: 4,
+			},
+			wantErr: true,
+			errMsg:  "must have children",
+		},
+		{
+			name: "stable container exceeding capacity",
+			field: Field{
+				Name:  "myStable",
+				Type:  TypeStableContainer,
+				Limit: 1,
+				Children: []Field{
+					{Name: "a", Type: TypeUint8},
+					{Name: "b", Type: TypeUint8},
+				},
+			},
+			wantErr: true,
+			errMsg:  "exceeds its capacity",
+		},
+		{
+			name: "valid profile",
+			field: Field{
+				Name: "Square",
+				Type: TypeProfile,
+				Ref:  "Shape",
+				Children: []Field{
+					{Name: "side", Type: TypeUint16},
+					{Name: "color", Type: TypeOptional, Children: []Field{{Name: "value", Type: TypeUint8}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid profile through ref",
+			field: Field{
+				Name:     "Square",
+				Type:     TypeProfile,
+				Ref:      "ShapeRef",
+				Children: []Field{{Name: "side", Type: TypeUint16}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "profile without reference",
+			field: Field{
+				Name:     "Square",
+				Type:     TypeProfile,
+				Children: []Field{{Name: "side", Type: TypeUint16}},
+			},
+			wantErr: true,
+			errMsg:  "must reference a stable container",
+		},
+		{
+			name: "profile of non stable container",
+			field: Field{
+				Name:     "Square",
+				Type:     TypeProfile,
+				Ref:      "NotStable",
+				Children: []Field{{Name: "a", Type: TypeUint8}},
+			},
+			wantErr: true,
+			errMsg:  "not a stable container",
+		},
+		{
+			name: "profile with unknown field",
+			field: Field{
+				Name:     "Square",
+				Type:     TypeProfile,
+				Ref:      "Shape",
+				Children: []Field{{Name: "depth", Type: TypeUint16}},
+			},
+			wantErr: true,
+			errMsg:  "is not a field of 'Shape'",
+		},
+		{
+			name: "optional without child",
+			field: Field{
+				Name: "myOptional",
+				Type: TypeOptional,
+			},
+			wantErr: true,
+			errMsg:  "exactly one child",
+		},
+		{
+			name: "nested optional",
+			field: Field{
+				Name: "myOptional",
+				Type: TypeOptional,
+				Children: []Field{
+					{Name: "inner", Type: TypeOptional, Children: []Field{{Name: "value", Type: TypeUint8}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "cannot wrap another optional",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.IsValid(refs)
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestIsVariable(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -558,6 +713,48 @@ func TestIsVariable(t *testing.T) {
 			want:    true,
 			wantErr: false,
 		},
+		{
+			name: "stable container is variable",
+			field: Field{
+				Name:     "myStable",
+				Type:     TypeStableContainer,
+				Limit:    8,
+				Children: []Field{{Name: "a", Type: TypeUint8}},
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name:    "optional is variable",
+			field:   Field{Name: "myOptional", Type: TypeOptional, Children: []Field{{Name: "a", Type: TypeUint8}}},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "profile with required fixed fields is fixed",
+			field: Field{
+				Name:     "myProfile",
+				Type:     TypeProfile,
+				Ref:      "Base",
+				Children: []Field{{Name: "a", Type: TypeUint8}},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "profile with optional field is variable",
+			field: Field{
+				Name: "myProfile",
+				Type: TypeProfile,
+				Ref:  "Base",
+				Children: []Field{
+					{Name: "a", Type: TypeUint8},
+					{Name: "b", Type: TypeOptional, Children: []Field{{Name: "value", Type: TypeUint8}}},
+				},
+			},
+			want:    true,
+			wantErr: false,
+		},
 		{
 			name:    "bytelist is variable",
 			field:   Field{Name: "myByteList", Type: TypeList, Limit: 100, Children: []Field{{Name: "byte", Type: TypeUint8}}},
@@ -814,12 +1011,19 @@ func TestTypeNameConstants(t *testing.T) {
 		TypeBitList:   true,
 		TypeUnion:     true,
 		TypeRef:       true,
+
+		TypeStableContainer: true,
+		TypeProfile:         true,
+		TypeOptional:        true,
 	}
 
 	// Test string values
 	assert.Equal(t, TypeName("uint8"), TypeUint8)
 	assert.Equal(t, TypeName("container"), TypeContainer)
 	assert.Equal(t, TypeName("ref"), TypeRef)
+	assert.Equal(t, TypeName("stable_container"), TypeStableContainer)
+	assert.Equal(t, TypeName("profile"), TypeProfile)
+	assert.Equal(t, TypeName("optional"), TypeOptional)
 
 	// Ensure all expected types are present
 	for typeName := range expectedTypes {
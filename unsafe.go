@@ -1,3 +1,5 @@
+//go:build !purego && !ssz_purego && !tinygo && !wasm
+
 package ssz
 
 import (
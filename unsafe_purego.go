@@ -0,0 +1,21 @@
+//go:build purego || ssz_purego || tinygo || wasm
+
+package ssz
+
+import "encoding/binary"
+
+// Portable versions of the helpers in unsafe.go, for targets without unsafe memory access. The
+// ssz_purego tag selects them for this package alone, e.g. for code generated by genssz with
+// unsafe_reads.
+
+func Uint64FromBytes(v []byte) uint64 {
+	return binary.LittleEndian.Uint64(v[:8])
+}
+
+func Uint32FromBytes(v []byte) uint32 {
+	return binary.LittleEndian.Uint32(v[:4])
+}
+
+func Uint16FromBytes(v []byte) uint16 {
+	return binary.LittleEndian.Uint16(v[:2])
+}